@@ -0,0 +1,235 @@
+package batch
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"friday/internal/models"
+)
+
+// TestCancelRacesCompletion cancels batches just as they complete: whichever
+// gets there first wins, and the batch ends with that status and that event only.
+func TestCancelRacesCompletion(t *testing.T) {
+	e := newTestEnv(t)
+
+	for i := range 20 {
+		run := e.queueBatch(t, "Hello", "15550000001")
+		// The first tick starts the batch and the second sends its only message;
+		// the next one completes it
+		e.tick()
+		e.tick()
+
+		var cancelled bool
+		var cancelErr error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			e.tick()
+		}()
+		go func() {
+			defer wg.Done()
+			cancelled, cancelErr = e.worker.CancelBatch(run.ID)
+		}()
+		wg.Wait()
+		if cancelErr != nil {
+			t.Fatalf("round %d: CancelBatch: %v", i, cancelErr)
+		}
+
+		// CancelBatch only gives up on a batch that is already final
+		finished := e.getBatch(t, run.ID)
+		want, wantEvent, otherEvent := models.BatchStatusCompleted, models.BatchEventCompleted, models.BatchEventCancelled
+		if cancelled {
+			want, wantEvent, otherEvent = models.BatchStatusCancelled, models.BatchEventCancelled, models.BatchEventCompleted
+		}
+		if finished.Status != want {
+			t.Fatalf("round %d: CancelBatch = %t but status %q, want %q", i, cancelled, finished.Status, want)
+		}
+		if got := e.countEvents(t, run.ID, wantEvent); got != 1 {
+			t.Errorf("round %d: %d %s events, want 1", i, got, wantEvent)
+		}
+		if got := e.countEvents(t, run.ID, otherEvent); got != 0 {
+			t.Errorf("round %d: %d %s events for a %s batch, want 0", i, got, otherEvent, finished.Status)
+		}
+		if ids := e.worker.GetActiveBatchIDs(); len(ids) != 0 {
+			t.Fatalf("round %d: batches still running: %v", i, ids)
+		}
+	}
+}
+
+// TestTransitionRace moves a running batch to completed and to cancelled at
+// once; exactly one of the two may succeed.
+func TestTransitionRace(t *testing.T) {
+	e := newTestEnv(t)
+
+	for i := range 20 {
+		run := e.queueBatch(t, "Hello", "15550000001")
+		if ok, err := e.batchRepo.Transition(run.ID, models.BatchStatusQueued, models.BatchStatusRunning); err != nil || !ok {
+			t.Fatalf("round %d: failed to start batch: %t, %v", i, ok, err)
+		}
+
+		targets := []models.BatchRunStatus{models.BatchStatusCompleted, models.BatchStatusCancelled}
+		won := make([]bool, len(targets))
+		var wg sync.WaitGroup
+		for j, to := range targets {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ok, err := e.batchRepo.Transition(run.ID, models.BatchStatusRunning, to)
+				if err != nil {
+					t.Errorf("round %d: Transition to %s: %v", i, to, err)
+				}
+				won[j] = ok
+			}()
+		}
+		wg.Wait()
+
+		if won[0] == won[1] {
+			t.Fatalf("round %d: completed %t, cancelled %t; want exactly one", i, won[0], won[1])
+		}
+		want := targets[0]
+		if won[1] {
+			want = targets[1]
+		}
+		if status := e.getBatch(t, run.ID).Status; status != want {
+			t.Errorf("round %d: status %q, want %q from the transition that won", i, status, want)
+		}
+	}
+}
+
+// TestCancelDuringDelay cancels a batch while the running worker waits out
+// the delay after its first message; nothing more may reach WhatsApp.
+func TestCancelDuringDelay(t *testing.T) {
+	e := newTestEnv(t)
+	for key, value := range map[string]string{
+		models.SettingBatchMinDelaySeconds: "2",
+		models.SettingBatchMaxDelaySeconds: "2",
+	} {
+		if err := e.settingsRepo.Set(key, value, "test"); err != nil {
+			t.Fatalf("failed to set %s: %v", key, err)
+		}
+	}
+	e.worker.SetSendJitter(0)
+	run := e.queueBatch(t, "Hello", "15550000001", "15550000002", "15550000003")
+
+	go e.worker.Run()
+	waitFor(t, "the first message", func() bool { return len(e.fake.Sent()) == 1 })
+
+	if cancelled, err := e.worker.CancelBatch(run.ID); err != nil || !cancelled {
+		t.Fatalf("CancelBatch = %t, %v; want true", cancelled, err)
+	}
+	// Past the end of the delay, when the second message would have gone
+	time.Sleep(3 * time.Second)
+
+	if attempts := e.fake.Attempts(); attempts != 1 {
+		t.Errorf("%d send attempts, want none after the cancel", attempts)
+	}
+	pending := 0
+	for _, msg := range e.messages(t, run.ID) {
+		if msg.Status == models.MessageStatusPending {
+			pending++
+		}
+	}
+	if pending != 2 {
+		t.Errorf("%d messages left pending, want 2", pending)
+	}
+}
+
+// TestCancelDuringSend cancels a batch while a send is on its way: the send is
+// aborted, its message fails as cancelled since it may have been delivered, and
+// nothing else is sent.
+func TestCancelDuringSend(t *testing.T) {
+	e := newTestEnv(t)
+	e.fake.SetLatency(time.Minute)
+	run := e.queueBatch(t, "Hello", "15550000001", "15550000002")
+
+	e.tick()
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		e.tick()
+	}()
+	waitFor(t, "the send to start", func() bool { return e.fake.Attempts() == 1 })
+
+	if cancelled, err := e.worker.CancelBatch(run.ID); err != nil || !cancelled {
+		t.Fatalf("CancelBatch = %t, %v; want true", cancelled, err)
+	}
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("send still under way 5s after the cancel")
+	}
+	for range 5 {
+		e.tick()
+	}
+
+	if attempts := e.fake.Attempts(); attempts != 1 {
+		t.Errorf("%d send attempts, want none after the cancel", attempts)
+	}
+	for _, msg := range e.messages(t, run.ID) {
+		switch msg.JID {
+		case "15550000001@s.whatsapp.net":
+			if msg.Status != models.MessageStatusFailed || msg.ErrorCode == nil || *msg.ErrorCode != string(FailureCancelled) {
+				t.Errorf("aborted message: status %q, code %v; want failed with %q", msg.Status, msg.ErrorCode, FailureCancelled)
+			}
+		default:
+			if msg.Status != models.MessageStatusPending {
+				t.Errorf("message to %s: status %q, want pending", msg.JID, msg.Status)
+			}
+		}
+	}
+	if status := e.getBatch(t, run.ID).Status; status != models.BatchStatusCancelled {
+		t.Errorf("status = %q, want %q", status, models.BatchStatusCancelled)
+	}
+}
+
+// TestSendTimeout sends to a WhatsApp that never answers: the send gives up
+// after batch.send_timeout_seconds and its message fails as a timeout, without
+// holding up the batch.
+func TestSendTimeout(t *testing.T) {
+	e := newTestEnv(t)
+	if err := e.settingsRepo.Set(models.SettingSendTimeoutSeconds, "1", "test"); err != nil {
+		t.Fatalf("failed to set the send timeout: %v", err)
+	}
+	e.fake.SetLatency(time.Hour)
+	run := e.queueBatch(t, "Hello", "15550000001", "15550000002")
+
+	e.tick()
+	start := time.Now()
+	e.tick()
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 5*time.Second {
+		t.Errorf("send returned after %s, want about the 1s timeout", elapsed)
+	}
+
+	if sent := e.fake.Sent(); len(sent) != 0 {
+		t.Errorf("fake accepted %+v, want nothing", sent)
+	}
+	for _, msg := range e.messages(t, run.ID) {
+		switch msg.JID {
+		case "15550000001@s.whatsapp.net":
+			if msg.Status != models.MessageStatusFailed || msg.ErrorCode == nil || *msg.ErrorCode != string(FailureTimeout) {
+				t.Errorf("timed out message: status %q, code %v; want failed with %q", msg.Status, msg.ErrorCode, FailureTimeout)
+			}
+		default:
+			if msg.Status != models.MessageStatusPending {
+				t.Errorf("message to %s: status %q, want pending", msg.JID, msg.Status)
+			}
+		}
+	}
+	if status := e.getBatch(t, run.ID).Status; status != models.BatchStatusRunning {
+		t.Errorf("status = %q, want the batch still %q", status, models.BatchStatusRunning)
+	}
+}
+
+// waitFor polls cond for up to 10s, failing the test with what it was waiting for.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,126 @@
+package batch
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"friday/internal/models"
+	"friday/internal/whatsapp"
+)
+
+const (
+	// deliveryCheckInterval is how often overdue messages are marked undelivered.
+	deliveryCheckInterval = time.Minute
+	// receiptRetryWindow is how long receipts that matched no batch message are
+	// retried, in case one overtook the worker recording the send.
+	receiptRetryWindow = 10 * time.Minute
+)
+
+// DeliveryTracker follows sent batch messages to the recipient's phone. Receipts
+// mark them delivered; those still without one after the
+// batch.undelivered_after_hours setting are marked undelivered. A contact
+// that blocked this number never sends receipts, so repeated undelivered
+// messages suggest a block, see models.BatchMessageRepository.GetSuspectedBlocked.
+type DeliveryTracker struct {
+	msgRepo      *models.BatchMessageRepository
+	settingsRepo *models.SettingsRepository
+
+	mu        sync.Mutex
+	unmatched map[string]time.Time // Receipt message IDs not found yet -> when the receipt came
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewDeliveryTracker creates a delivery tracker; pass HandleReceipt to the
+// WhatsApp client and call Run to start marking overdue messages.
+func NewDeliveryTracker(msgRepo *models.BatchMessageRepository, settingsRepo *models.SettingsRepository) *DeliveryTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DeliveryTracker{
+		msgRepo:      msgRepo,
+		settingsRepo: settingsRepo,
+		unmatched:    make(map[string]time.Time),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// HandleReceipt marks the acknowledged messages delivered, including ones
+// already marked undelivered.
+func (t *DeliveryTracker) HandleReceipt(receipt whatsapp.Receipt) {
+	matched, err := t.msgRepo.MarkDelivered(receipt.MessageIDs, receipt.Timestamp)
+	if err != nil {
+		slog.Error("Failed to record delivery receipt", "message_ids", receipt.MessageIDs, "error", err)
+	}
+	if err == nil && matched == len(receipt.MessageIDs) {
+		return
+	}
+
+	// Most receipts are for messages sent outside batches; they expire unmatched
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range receipt.MessageIDs {
+		if _, ok := t.unmatched[id]; !ok {
+			t.unmatched[id] = receipt.Timestamp
+		}
+	}
+}
+
+// Run marks overdue messages undelivered every minute until Shutdown.
+func (t *DeliveryTracker) Run() {
+	ticker := time.NewTicker(deliveryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+			t.retryUnmatched(time.Now())
+			t.markOverdue(time.Now())
+		}
+	}
+}
+
+// Shutdown stops marking overdue messages.
+func (t *DeliveryTracker) Shutdown() {
+	t.cancel()
+}
+
+func (t *DeliveryTracker) retryUnmatched(now time.Time) {
+	t.mu.Lock()
+	pending := t.unmatched
+	t.unmatched = make(map[string]time.Time)
+	t.mu.Unlock()
+
+	for id, at := range pending {
+		matched, err := t.msgRepo.MarkDelivered([]string{id}, at)
+		if err != nil {
+			slog.Error("Failed to record delivery receipt", "message_id", id, "error", err)
+		}
+		if matched == 0 && now.Sub(at) < receiptRetryWindow {
+			t.mu.Lock()
+			t.unmatched[id] = at
+			t.mu.Unlock()
+		}
+	}
+}
+
+func (t *DeliveryTracker) markOverdue(now time.Time) {
+	hours, err := t.settingsRepo.GetInt(models.SettingUndeliveredAfterHours, models.DefaultUndeliveredAfterHours)
+	if err != nil {
+		slog.Warn("Failed to read undelivered window", "error", err)
+		return
+	}
+
+	marked, err := t.msgRepo.MarkUndelivered(now.Add(-time.Duration(hours) * time.Hour))
+	if err != nil {
+		slog.Error("Failed to mark undelivered messages", "error", err)
+		return
+	}
+	if marked > 0 {
+		slog.Info("Messages without a delivery receipt marked undelivered", "count", marked, "after_hours", hours)
+	}
+}
@@ -0,0 +1,148 @@
+package batch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// documentTypes are the file types batches may send as documents, by
+// lowercased extension.
+var documentTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".txt":  "text/plain",
+	".csv":  "text/csv",
+	".doc":  "application/msword",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xls":  "application/vnd.ms-excel",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ErrDocumentsDisabled is returned when no documents directory is configured.
+var ErrDocumentsDisabled = errors.New("documents are disabled: set FRIDAY_DOCUMENTS_DIR")
+
+// Document is a file loaded for sending.
+type Document struct {
+	Data     []byte
+	FileName string
+	MimeType string
+}
+
+// Documents reads the files batches attach per recipient. Each recipient names
+// its file in an attribute, as a path relative to the documents directory;
+// paths that lead outside it, including through symlinks, are refused.
+type Documents struct {
+	root *os.Root
+}
+
+// NewDocuments opens dir as the documents directory.
+func NewDocuments(dir string) (*Documents, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open documents directory: %w", err)
+	}
+	return &Documents{root: root}, nil
+}
+
+// Dir is the documents directory.
+func (d *Documents) Dir() string {
+	return d.root.Name()
+}
+
+// Check reports why ref cannot be sent, without reading the file: nil when it
+// exists, is at most maxBytes and has an allowed type.
+func (d *Documents) Check(ref string, maxBytes int64) error {
+	name, _, err := documentName(ref)
+	if err != nil {
+		return err
+	}
+	info, err := d.root.Stat(name)
+	if err != nil {
+		return documentOpenError(ref, err)
+	}
+	return checkDocumentSize(ref, info, maxBytes)
+}
+
+// Load reads ref for sending, with the same checks as Check. A .pdf must also
+// start like one.
+func (d *Documents) Load(ref string, maxBytes int64) (*Document, error) {
+	name, mimeType, err := documentName(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := d.root.Open(name)
+	if err != nil {
+		return nil, documentOpenError(ref, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document %q: %w", ref, err)
+	}
+	if err := checkDocumentSize(ref, info, maxBytes); err != nil {
+		return nil, err
+	}
+
+	// The size was checked above, but the file may grow while it is read
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document %q: %w", ref, err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("document %q is larger than %d bytes", ref, maxBytes)
+	}
+	if mimeType == "application/pdf" && !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, fmt.Errorf("document %q is not a PDF", ref)
+	}
+
+	return &Document{Data: data, FileName: path.Base(name), MimeType: mimeType}, nil
+}
+
+// documentName validates a document reference and returns it as a path inside
+// the documents directory, with its MIME type.
+func documentName(ref string) (string, string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", "", errors.New("no document given")
+	}
+	if strings.Contains(ref, "://") {
+		return "", "", fmt.Errorf("document %q is a URL; give a path inside the documents directory", ref)
+	}
+	name := path.Clean(strings.ReplaceAll(ref, `\`, "/"))
+	if !fs.ValidPath(name) || name == "." {
+		return "", "", fmt.Errorf("document %q is not a path inside the documents directory", ref)
+	}
+
+	mimeType, ok := documentTypes[strings.ToLower(path.Ext(name))]
+	if !ok {
+		return "", "", fmt.Errorf("document %q is not an allowed file type", ref)
+	}
+	return name, mimeType, nil
+}
+
+func documentOpenError(ref string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("document %q not found", ref)
+	}
+	return fmt.Errorf("failed to open document %q: %w", ref, err)
+}
+
+func checkDocumentSize(ref string, info fs.FileInfo, maxBytes int64) error {
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("document %q is not a file", ref)
+	}
+	if info.Size() > maxBytes {
+		return fmt.Errorf("document %q is %d bytes, larger than the %d allowed", ref, info.Size(), maxBytes)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+
+	"friday/internal/whatsapp"
+)
+
+// FailureCode classifies why a batch message failed, so failures can be
+// counted and filtered. The raw error text is stored next to it for debugging.
+type FailureCode string
+
+const (
+	FailureRecipientInvalid FailureCode = "recipient_invalid" // Malformed JID, unsupported server or a hidden number without a phone
+	FailureNotOnWhatsApp    FailureCode = "not_on_whatsapp"   // Found unregistered by validation
+	FailureRateLimited      FailureCode = "rate_limited"      // WhatsApp refused the send as too frequent
+	FailureDisconnected     FailureCode = "disconnected"      // Connection lost or logged out during the send
+	FailureTimeout          FailureCode = "timeout"           // No answer from WhatsApp in time; delivery unknown
+	FailureTemplate         FailureCode = "template"          // Placeholder values could not be loaded
+	FailureInterrupted      FailureCode = "interrupted"       // Server stopped mid-send and the batch was cancelled
+	FailureCancelled        FailureCode = "cancelled"         // The batch was cancelled mid-send; delivery unknown
+	FailureDocument         FailureCode = "document"          // The recipient's document is missing, too large or not an allowed type
+	FailureBlocked          FailureCode = "blocked"           // The linked account blocked the recipient after the batch was created
+	FailureUnknown          FailureCode = "unknown"
+)
+
+// ClassifySendError maps an error from Messenger.SendMessage to a failure code.
+func ClassifySendError(err error) FailureCode {
+	var disconnected *whatsmeow.DisconnectedError
+	switch {
+	case err == nil:
+		return ""
+	case whatsapp.IsPermanentSendError(err):
+		return FailureRecipientInvalid
+	case errors.Is(err, whatsmeow.ErrIQRateOverLimit), isServerErrorCode(err, "429"):
+		return FailureRateLimited
+	case errors.Is(err, whatsapp.ErrNotConnected), errors.Is(err, whatsmeow.ErrNotConnected), errors.Is(err, whatsmeow.ErrNotLoggedIn), errors.As(err, &disconnected):
+		return FailureDisconnected
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, whatsmeow.ErrMessageTimedOut), errors.Is(err, whatsmeow.ErrIQTimedOut):
+		return FailureTimeout
+	}
+	return FailureUnknown
+}
+
+// isServerErrorCode reports whether err is whatsmeow's ErrServerReturnedError,
+// which carries the server's error code only in its text, e.g. "server returned error 429".
+func isServerErrorCode(err error, code string) bool {
+	return errors.Is(err, whatsmeow.ErrServerReturnedError) && strings.HasSuffix(err.Error(), " "+code)
+}
@@ -0,0 +1,241 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"friday/internal/database"
+	"friday/internal/models"
+)
+
+// maintenanceCheckInterval is how often the maintainer checks whether the
+// nightly pass is due.
+const maintenanceCheckInterval = time.Minute
+
+var (
+	// ErrMaintenanceRunning is returned when a pass is requested while one is under way.
+	ErrMaintenanceRunning = errors.New("maintenance is already running")
+	// ErrMaintenanceBatchRunning is returned when a pass is requested while a batch is sending.
+	ErrMaintenanceBatchRunning = errors.New("a batch is sending")
+)
+
+// Maintainer keeps the database small and fast: once a night it deletes
+// finished batches and outbox messages past the retention setting, refreshes
+// the query planner's statistics and, when deletions left enough of the file
+// free, rebuilds it with VACUUM. It waits while a batch is sending, since
+// VACUUM blocks every other query until it is done.
+type Maintainer struct {
+	db              *database.DB
+	batchRepo       *models.BatchRunRepository
+	outboxRepo      *models.OutboxRepository
+	maintenanceRepo *models.MaintenanceRepository
+	settingsRepo    *models.SettingsRepository
+	worker          *Worker
+	defaultTimezone string // FRIDAY_TIMEZONE; the batch.timezone setting takes precedence
+
+	running  sync.Mutex // Held for the length of a pass
+	deferred bool       // A due pass is waiting for batches to finish; logged once
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewMaintainer creates a maintainer; call Run to start the nightly schedule.
+func NewMaintainer(
+	db *database.DB,
+	batchRepo *models.BatchRunRepository,
+	outboxRepo *models.OutboxRepository,
+	maintenanceRepo *models.MaintenanceRepository,
+	settingsRepo *models.SettingsRepository,
+	worker *Worker,
+	defaultTimezone string,
+) *Maintainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Maintainer{
+		db:              db,
+		batchRepo:       batchRepo,
+		outboxRepo:      outboxRepo,
+		maintenanceRepo: maintenanceRepo,
+		settingsRepo:    settingsRepo,
+		worker:          worker,
+		defaultTimezone: defaultTimezone,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Run runs the nightly pass at the maintenance.hour setting until Shutdown.
+func (m *Maintainer) Run() {
+	ticker := time.NewTicker(maintenanceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.runIfDue(time.Now())
+		}
+	}
+}
+
+// Shutdown stops the schedule. A pass in progress finishes first.
+func (m *Maintainer) Shutdown() {
+	m.cancel()
+}
+
+func (m *Maintainer) runIfDue(now time.Time) {
+	due, err := m.due(now)
+	if err != nil {
+		slog.Warn("Failed to check maintenance schedule", "error", err)
+		return
+	}
+	if !due {
+		m.deferred = false
+		return
+	}
+
+	if _, err := m.RunNow(models.MaintenanceTriggerScheduled); errors.Is(err, ErrMaintenanceBatchRunning) {
+		if !m.deferred {
+			slog.Info("Database maintenance deferred until no batch is sending")
+			m.deferred = true
+		}
+		return
+	}
+	m.deferred = false
+}
+
+// due reports whether today's pass is outstanding: its hour has come and no
+// pass, scheduled or manual, started since. A pass deferred by a long batch
+// still runs later the same day.
+func (m *Maintainer) due(now time.Time) (bool, error) {
+	loc, err := m.location()
+	if err != nil {
+		return false, err
+	}
+	hour, err := m.settingsRepo.GetInt(models.SettingMaintenanceHour, models.DefaultMaintenanceHour)
+	if err != nil {
+		return false, err
+	}
+
+	local := now.In(loc)
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), hour, 0, 0, 0, loc)
+	if local.Before(scheduled) {
+		return false, nil
+	}
+
+	last, err := m.maintenanceRepo.Last()
+	if err != nil {
+		return false, err
+	}
+	return last == nil || last.StartedAt.Before(scheduled), nil
+}
+
+func (m *Maintainer) location() (*time.Location, error) {
+	tz, err := m.settingsRepo.GetString(models.SettingTimezone, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timezone setting: %w", err)
+	}
+	if tz == "" {
+		tz = m.defaultTimezone
+	}
+	if tz == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	return loc, nil
+}
+
+// RunNow runs a maintenance pass and records it, even when a step fails. It
+// refuses to start while a batch is sending or another pass is running.
+func (m *Maintainer) RunNow(trigger string) (*models.MaintenanceRun, error) {
+	if !m.running.TryLock() {
+		return nil, ErrMaintenanceRunning
+	}
+	defer m.running.Unlock()
+
+	// A batch started during the pass only waits for the database
+	if len(m.worker.GetActiveBatchIDs()) > 0 {
+		return nil, ErrMaintenanceBatchRunning
+	}
+
+	run := &models.MaintenanceRun{Trigger: trigger, StartedAt: time.Now()}
+	err := m.maintain(run)
+	run.FinishedAt = time.Now()
+	if err != nil {
+		msg := err.Error()
+		run.ErrorMessage = &msg
+	}
+
+	if recordErr := m.maintenanceRepo.Create(run); recordErr != nil {
+		slog.Error("Failed to record maintenance run", "error", recordErr)
+	}
+
+	logArgs := []any{
+		"trigger", trigger,
+		"batch_runs_pruned", run.BatchRunsPruned,
+		"outbox_pruned", run.OutboxPruned,
+		"free_percent", fmt.Sprintf("%.1f", run.FreePercent),
+		"vacuumed", run.Vacuumed,
+		"bytes_freed", run.BytesFreed,
+		"duration", run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond),
+	}
+	if err != nil {
+		slog.Error("Database maintenance failed", append(logArgs, "error", err)...)
+		return run, err
+	}
+	slog.Info("Database maintenance finished", logArgs...)
+	return run, nil
+}
+
+func (m *Maintainer) maintain(run *models.MaintenanceRun) error {
+	days, err := m.settingsRepo.GetInt(models.SettingMaintenanceRetentionDays, models.DefaultMaintenanceRetentionDays)
+	if err != nil {
+		return fmt.Errorf("failed to read retention setting: %w", err)
+	}
+	if days > 0 {
+		cutoff := time.Now().AddDate(0, 0, -days)
+		if run.BatchRunsPruned, err = m.batchRepo.DeleteFinishedBefore(cutoff); err != nil {
+			return err
+		}
+		if run.OutboxPruned, err = m.outboxRepo.DeleteFinishedBefore(cutoff); err != nil {
+			return err
+		}
+	}
+
+	if err := m.db.Analyze(); err != nil {
+		return err
+	}
+
+	threshold, err := m.settingsRepo.GetInt(models.SettingMaintenanceVacuumPercent, models.DefaultMaintenanceVacuumPercent)
+	if err != nil {
+		return fmt.Errorf("failed to read vacuum threshold setting: %w", err)
+	}
+	before, err := m.db.SpaceUsage()
+	if err != nil {
+		return err
+	}
+	run.FreePercent = math.Round(before.FreePercent()*10) / 10
+	if run.FreePercent < float64(threshold) {
+		return nil
+	}
+
+	if err := m.db.Vacuum(); err != nil {
+		return err
+	}
+	run.Vacuumed = true
+	after, err := m.db.SpaceUsage()
+	if err != nil {
+		return err
+	}
+	run.BytesFreed = before.SizeBytes - after.SizeBytes
+	return nil
+}
@@ -0,0 +1,162 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"friday/internal/models"
+	"friday/internal/whatsapp"
+)
+
+// CompletionSummary is sent to notification targets when a batch finishes or fails.
+type CompletionSummary struct {
+	BatchID               int64     `json:"batch_id"`
+	Status                string    `json:"status"`
+	DraftTitle            string    `json:"draft_title"`
+	GroupName             string    `json:"group_name"`
+	Label                 string    `json:"label"`
+	TotalCount            int       `json:"total_count"`
+	SentCount             int       `json:"sent_count"`
+	FailedCount           int       `json:"failed_count"`
+	ValidationFailedCount int       `json:"validation_failed_count"`
+	SkippedCount          int       `json:"skipped_count"`
+	ErrorMessage          string    `json:"error_message,omitempty"`
+	StartedAt             time.Time `json:"started_at,omitempty"`
+	CompletedAt           time.Time `json:"completed_at,omitempty"`
+	DurationSeconds       int       `json:"duration_seconds"`
+	DetailPath            string    `json:"detail_path"` // Web UI path, e.g. /batch-runs/12
+}
+
+// Notifier delivers batch completion summaries to the targets configured in settings.
+// Delivery errors are logged only; they never change the batch's recorded status.
+type Notifier struct {
+	settingsRepo *models.SettingsRepository
+	waClient     whatsapp.Sender
+	httpClient   *http.Client
+}
+
+// NewNotifier creates a notifier that reads its targets from settings on every use.
+func NewNotifier(settingsRepo *models.SettingsRepository, waClient whatsapp.Sender) *Notifier {
+	return &Notifier{
+		settingsRepo: settingsRepo,
+		waClient:     waClient,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewCompletionSummary builds the summary for a finished batch run.
+func NewCompletionSummary(run *models.BatchRun) *CompletionSummary {
+	summary := &CompletionSummary{
+		BatchID:               run.ID,
+		Status:                string(run.Status),
+		DraftTitle:            run.DraftTitle,
+		GroupName:             run.GroupName,
+		Label:                 run.Label,
+		TotalCount:            run.TotalCount,
+		SentCount:             run.SentCount,
+		FailedCount:           run.FailedCount,
+		ValidationFailedCount: run.ValidationFailedCount,
+		SkippedCount:          run.SkippedCount,
+		DetailPath:            fmt.Sprintf("/batch-runs/%d", run.ID),
+	}
+	if run.ErrorMessage != nil {
+		summary.ErrorMessage = *run.ErrorMessage
+	}
+	if run.StartedAt != nil {
+		summary.StartedAt = *run.StartedAt
+	}
+	if run.CompletedAt != nil {
+		summary.CompletedAt = *run.CompletedAt
+	}
+	if run.StartedAt != nil && run.CompletedAt != nil {
+		summary.DurationSeconds = int(run.CompletedAt.Sub(*run.StartedAt).Seconds())
+	}
+	return summary
+}
+
+// Notify sends the summary to every enabled target.
+func (n *Notifier) Notify(run *models.BatchRun) {
+	summary := NewCompletionSummary(run)
+
+	selfMessage, err := n.settingsRepo.GetBool(models.SettingNotifySelfMessage, false)
+	if err != nil {
+		slog.Error("Notification settings unavailable", "batch_id", run.ID, "error", err)
+		return
+	}
+	if selfMessage {
+		if err := n.sendSelfMessage(summary); err != nil {
+			slog.Error("Failed to send completion message", "batch_id", run.ID, "error", err)
+		}
+	}
+
+	webhookURL, err := n.settingsRepo.GetString(models.SettingNotifyWebhookURL, "")
+	if err != nil {
+		slog.Error("Notification settings unavailable", "batch_id", run.ID, "error", err)
+		return
+	}
+	if webhookURL != "" {
+		if err := n.postWebhook(webhookURL, summary); err != nil {
+			slog.Error("Failed to post completion webhook", "batch_id", run.ID, "error", err)
+		}
+	}
+}
+
+func (n *Notifier) sendSelfMessage(summary *CompletionSummary) error {
+	jid, err := n.settingsRepo.GetString(models.SettingNotifySelfJID, "")
+	if err != nil {
+		return err
+	}
+	if jid == "" {
+		jid = n.waClient.OwnJID()
+	}
+	if jid == "" {
+		return fmt.Errorf("no recipient: not logged in and no notify JID configured")
+	}
+
+	text := fmt.Sprintf("Batch #%d %s\n", summary.BatchID, summary.Status)
+	if summary.Label != models.DefaultBatchLabel(summary.DraftTitle, summary.GroupName) {
+		text += "Label: " + summary.Label + "\n"
+	}
+	text += fmt.Sprintf("Draft: %s\nGroup: %s\nSent: %d / %d, failed: %d",
+		summary.DraftTitle, summary.GroupName,
+		summary.SentCount, summary.TotalCount, summary.FailedCount)
+	if summary.SkippedCount > 0 {
+		text += fmt.Sprintf(", skipped: %d", summary.SkippedCount)
+	}
+	if summary.DurationSeconds > 0 {
+		text += fmt.Sprintf("\nDuration: %s", (time.Duration(summary.DurationSeconds) * time.Second).String())
+	}
+	if summary.ErrorMessage != "" {
+		text += "\nError: " + summary.ErrorMessage
+	}
+	text += "\n" + summary.DetailPath
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err = n.waClient.SendMessage(ctx, jid, text)
+	return err
+}
+
+func (n *Notifier) postWebhook(url string, summary *CompletionSummary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+
+	resp, err := n.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"friday/internal/whatsapp"
+)
+
+// outboxExpiryInterval is how often queued outbox messages past their TTL are
+// marked expired. Expired ones are never sent, so this only delays the status.
+const outboxExpiryInterval = 30 * time.Second
+
+// outboxWaiting expires stale outbox messages when due and reports whether any
+// are left to send.
+func (w *Worker) outboxWaiting() bool {
+	if time.Since(w.lastOutboxExpiry) >= outboxExpiryInterval {
+		w.lastOutboxExpiry = time.Now()
+		if n, err := w.outboxRepo.Expire(); err != nil {
+			slog.Error("Failed to expire outbox messages", "error", err)
+		} else if n > 0 {
+			slog.Info("Outbox messages expired unsent", "count", n)
+		}
+	}
+
+	item, err := w.outboxRepo.NextQueued()
+	if err != nil {
+		slog.Error("Failed to check outbox", "error", err)
+		return false
+	}
+	return item != nil
+}
+
+// sendOutboxItem sends the oldest queued outbox message and schedules the next
+// send like a batch message, so both share the same pacing.
+func (w *Worker) sendOutboxItem() {
+	item, err := w.outboxRepo.NextQueued()
+	if err != nil || item == nil {
+		if err != nil {
+			slog.Error("Failed to get next outbox message", "error", err)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err = w.waClient.SendMessage(ctx, item.JID, item.Body)
+	if errors.Is(err, whatsapp.ErrReadOnly) {
+		// Read-only mode started mid-send: the item stays queued for later
+		return
+	}
+	if err != nil {
+		slog.Warn("Failed to send outbox message", "outbox_id", item.ID, "jid", item.JID, "error", err)
+		if err := w.outboxRepo.MarkFailed(item.ID, fmt.Sprintf("Send failed: %v", err)); err != nil {
+			slog.Error("Failed to mark outbox message failed", "outbox_id", item.ID, "error", err)
+		}
+		w.scheduleNextMessage(nil)
+		return
+	}
+
+	slog.Info("Outbox message sent", "outbox_id", item.ID, "jid", item.JID)
+	if err := w.outboxRepo.MarkSent(item.ID); err != nil {
+		slog.Error("Failed to mark outbox message sent", "outbox_id", item.ID, "error", err)
+	}
+	w.scheduleNextMessage(nil)
+}
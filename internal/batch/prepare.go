@@ -0,0 +1,137 @@
+package batch
+
+import (
+	"fmt"
+	"log/slog"
+
+	"friday/internal/models"
+)
+
+// PrepareChunkSize is how many messages a preparing batch gets per transaction.
+// Batches with more messages are created preparing and filled in by Prepare,
+// so creating one does not hold the request until every row is written.
+const PrepareChunkSize = 500
+
+// PreparingInterruptedError is recorded on batches the server stopped before
+// their messages were all created.
+const PreparingInterruptedError = "Server stopped while the batch was being prepared"
+
+// Prepare creates the messages and recipient snapshot of a batch run created
+// in models.BatchStatusPreparing in the background, PrepareChunkSize at a time
+// with a "preparing" progress event after each chunk, then queues it. With
+// validate set, recipients are checked against WhatsApp before it is queued,
+// as batch creation does for smaller batches.
+func (w *Worker) Prepare(run *models.BatchRun, messages []models.BatchMessage, recipients []models.BatchRecipient, validate bool) {
+	w.mu.Lock()
+	w.preparing[run.ID] = 0
+	w.mu.Unlock()
+
+	go w.prepare(run, messages, recipients, validate)
+}
+
+func (w *Worker) prepare(run *models.BatchRun, messages []models.BatchMessage, recipients []models.BatchRecipient, validate bool) {
+	defer func() {
+		w.mu.Lock()
+		delete(w.preparing, run.ID)
+		w.mu.Unlock()
+	}()
+
+	slog.Info("Preparing batch messages", "batch_id", run.ID, "total", len(messages))
+	for start := 0; start < max(len(messages), len(recipients)); start += PrepareChunkSize {
+		select {
+		case <-w.ctx.Done():
+			// Left preparing; cleaned up as interrupted on the next start
+			slog.Info("Batch preparation stopped by shutdown", "batch_id", run.ID)
+			return
+		default:
+		}
+
+		chunk := messages[min(start, len(messages)):min(start+PrepareChunkSize, len(messages))]
+		snapshot := recipients[min(start, len(recipients)):min(start+PrepareChunkSize, len(recipients))]
+		added, err := w.batchRepo.AddMessages(run.ID, chunk, snapshot)
+		if err != nil {
+			slog.Error("Failed to prepare batch messages", "batch_id", run.ID, "error", err)
+			w.discardPrepared(run.ID)
+			w.failBatch(run, "Failed to create batch messages", fmt.Sprintf("Failed to create batch messages: %v", err))
+			return
+		}
+		if !added {
+			// Cancelled; CancelBatch removed what was created
+			slog.Info("Batch preparation stopped", "batch_id", run.ID)
+			return
+		}
+
+		prepared := min(start+PrepareChunkSize, len(messages))
+		w.mu.Lock()
+		w.preparing[run.ID] = prepared
+		w.mu.Unlock()
+		w.broadcastEvent(run.ID, &ProgressEvent{
+			Type:          "preparing",
+			BatchID:       run.ID,
+			Status:        string(models.BatchStatusPreparing),
+			Label:         run.Label,
+			TotalCount:    len(messages),
+			PreparedCount: prepared,
+		})
+	}
+
+	if _, err := w.FailUnresolvedLIDs(run.ID); err != nil {
+		slog.Warn("Failed to check hidden numbers", "batch_id", run.ID, "error", err)
+	}
+	if validate {
+		if _, err := w.ValidateRecipients(run.ID); err != nil {
+			slog.Warn("Recipient validation failed", "batch_id", run.ID, "error", err)
+		}
+	}
+
+	queued, err := w.batchRepo.Transition(run.ID, models.BatchStatusPreparing, models.BatchStatusQueued)
+	if err != nil {
+		slog.Error("Failed to queue prepared batch", "batch_id", run.ID, "error", err)
+		return
+	}
+	if !queued {
+		slog.Info("Batch changed status before it could be queued", "batch_id", run.ID)
+		return
+	}
+
+	slog.Info("Batch prepared", "batch_id", run.ID, "messages", len(messages))
+	w.recordEvent(run.ID, models.BatchEventPrepared, "", fmt.Sprintf("%d messages created", len(messages)))
+	w.broadcastEvent(run.ID, &ProgressEvent{
+		Type:       "prepared",
+		BatchID:    run.ID,
+		Status:     string(models.BatchStatusQueued),
+		Label:      run.Label,
+		TotalCount: len(messages),
+	})
+
+	w.checkQueue()
+}
+
+// discardPrepared removes the messages and recipient snapshot a preparing
+// batch got so far, as when it is cancelled or its preparation fails.
+func (w *Worker) discardPrepared(batchID int64) {
+	deleted, err := w.batchRepo.DeleteMessages(batchID)
+	if err != nil {
+		slog.Error("Failed to remove prepared batch messages", "batch_id", batchID, "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("Removed prepared batch messages", "batch_id", batchID, "messages", deleted)
+	}
+}
+
+// failInterruptedPreparations fails the batches whose preparation the server
+// stopped during, removing what they got; their requests may be repeated.
+func (w *Worker) failInterruptedPreparations() {
+	preparing, err := w.batchRepo.GetPreparing()
+	if err != nil {
+		slog.Error("Error checking for preparing batches", "error", err)
+		return
+	}
+	for i := range preparing {
+		run := &preparing[i]
+		slog.Warn("Failing batch interrupted while preparing", "batch_id", run.ID)
+		w.discardPrepared(run.ID)
+		w.failBatch(run, PreparingInterruptedError, "")
+	}
+}
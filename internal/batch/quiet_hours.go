@@ -0,0 +1,86 @@
+package batch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuietHours is a daily window during which batch messages are not sent.
+// The window may wrap midnight (e.g. 21:00-09:00).
+type QuietHours struct {
+	Start    time.Duration // Offset from midnight
+	End      time.Duration // Offset from midnight
+	Location *time.Location
+}
+
+// ParseQuietHours parses a window like "21:00-09:00" in the named timezone.
+// An empty timezone uses the server's local zone.
+func ParseQuietHours(spec, timezone string) (*QuietHours, error) {
+	parts := strings.Split(strings.TrimSpace(spec), "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("quiet hours must look like HH:MM-HH:MM, got %q", spec)
+	}
+
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("quiet hours start and end must differ")
+	}
+
+	loc := time.Local
+	if timezone != "" {
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+	}
+
+	return &QuietHours{Start: start, End: end, Location: loc}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (use HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Active reports whether now falls inside the window and, if so, when it ends.
+func (q *QuietHours) Active(now time.Time) (bool, time.Time) {
+	local := now.In(q.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, q.Location)
+	offset := local.Sub(midnight)
+
+	if q.Start < q.End {
+		// Same-day window, e.g. 12:00-14:00
+		if offset >= q.Start && offset < q.End {
+			return true, midnight.Add(q.End)
+		}
+		return false, time.Time{}
+	}
+
+	// Window wraps midnight, e.g. 21:00-09:00
+	if offset >= q.Start {
+		return true, midnight.AddDate(0, 0, 1).Add(q.End)
+	}
+	if offset < q.End {
+		return true, midnight.Add(q.End)
+	}
+	return false, time.Time{}
+}
+
+// String formats the window as HH:MM-HH:MM followed by the zone name.
+func (q *QuietHours) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%s-%s %s", clock(q.Start), clock(q.End), q.Location)
+}
@@ -0,0 +1,63 @@
+package batch
+
+import (
+	"fmt"
+	"time"
+
+	"friday/internal/models"
+)
+
+// SkipRecipientCap is the error_code of a message skipped because its
+// recipient had already been sent the batch.recipient_cap number of messages.
+const SkipRecipientCap = "recipient_cap"
+
+// RecipientCap limits how many messages one contact is sent within a window
+// of days, counting sent batch messages and outbox messages.
+type RecipientCap struct {
+	Limit int // 0 means no cap
+	Days  int
+}
+
+// LoadRecipientCap reads the batch.recipient_cap and batch.recipient_cap_days settings.
+func LoadRecipientCap(settings *models.SettingsRepository) (RecipientCap, error) {
+	limit, err := settings.GetInt(models.SettingRecipientCap, models.DefaultRecipientCap)
+	if err != nil {
+		return RecipientCap{}, fmt.Errorf("failed to read recipient cap: %w", err)
+	}
+	days, err := settings.GetInt(models.SettingRecipientCapDays, models.DefaultRecipientCapDays)
+	if err != nil {
+		return RecipientCap{}, fmt.Errorf("failed to read recipient cap window: %w", err)
+	}
+	if days < 1 {
+		days = models.DefaultRecipientCapDays
+	}
+	return RecipientCap{Limit: max(limit, 0), Days: days}, nil
+}
+
+// Enabled reports whether the cap is on.
+func (c RecipientCap) Enabled() bool {
+	return c.Limit > 0
+}
+
+// Reached returns the send counts of the jids that may not be sent another
+// message now, or nil when the cap is off.
+func (c RecipientCap) Reached(msgRepo *models.BatchMessageRepository, jids []string) (map[string]int, error) {
+	if !c.Enabled() || len(jids) == 0 {
+		return nil, nil
+	}
+	counts, err := msgRepo.SentCounts(jids, time.Now().AddDate(0, 0, -c.Days))
+	if err != nil {
+		return nil, err
+	}
+	for jid, count := range counts {
+		if count < c.Limit {
+			delete(counts, jid)
+		}
+	}
+	return counts, nil
+}
+
+// Reason is the error_message stored with a message skipped after count sends.
+func (c RecipientCap) Reason(count int) string {
+	return fmt.Sprintf("Recipient cap reached: %d messages sent in the last %d days, the cap is %d", count, c.Days, c.Limit)
+}
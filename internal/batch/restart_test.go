@@ -0,0 +1,103 @@
+package batch
+
+import (
+	"testing"
+
+	"friday/internal/models"
+)
+
+// startSending queues a batch of three and sends its first message, leaving
+// the batch running with two messages pending.
+func (e *testEnv) startSending(t *testing.T) *models.BatchRun {
+	t.Helper()
+	run := e.queueBatch(t, "Hello", "15550000001", "15550000002", "15550000003")
+	e.tick()
+	e.tick()
+	if run = e.getBatch(t, run.ID); run.Status != models.BatchStatusRunning || run.SentCount != 1 {
+		t.Fatalf("batch %s with %d sent before the restart, want running with 1", run.Status, run.SentCount)
+	}
+	return run
+}
+
+// TestRestartResumesRunningBatch restarts the worker with batch.auto_resume on,
+// the default: the batch carries on where it stopped.
+func TestRestartResumesRunningBatch(t *testing.T) {
+	e := newTestEnv(t)
+	run := e.startSending(t)
+
+	e.restart(t)
+	if ids := e.worker.GetActiveBatchIDs(); len(ids) != 1 || ids[0] != run.ID {
+		t.Fatalf("running after the restart: %v, want [%d]", ids, run.ID)
+	}
+
+	finished := e.runUntilFinished(t, run.ID)
+	if finished.Status != models.BatchStatusCompleted || finished.SentCount != 3 {
+		t.Errorf("batch %s with %d sent, want completed with 3", finished.Status, finished.SentCount)
+	}
+	if sent := e.fake.Sent(); len(sent) != 3 {
+		t.Errorf("fake got %d messages, want 3: none sent twice", len(sent))
+	}
+}
+
+// TestRestartHoldsInterruptedBatch restarts the worker with batch.auto_resume
+// off: the running batch is held as interrupted, sends nothing, and can then
+// be resumed or cancelled.
+func TestRestartHoldsInterruptedBatch(t *testing.T) {
+	for _, then := range []string{"resume", "cancel"} {
+		t.Run(then, func(t *testing.T) {
+			e := newTestEnv(t)
+			if err := e.settingsRepo.Set(models.SettingAutoResumeBatches, "false", "test"); err != nil {
+				t.Fatalf("failed to turn off auto-resume: %v", err)
+			}
+			run := e.startSending(t)
+
+			e.restart(t)
+			for range 3 {
+				e.tick()
+			}
+			if status := e.getBatch(t, run.ID).Status; status != models.BatchStatusInterrupted {
+				t.Fatalf("status after the restart = %q, want %q", status, models.BatchStatusInterrupted)
+			}
+			if ids := e.worker.GetActiveBatchIDs(); len(ids) != 0 {
+				t.Errorf("running after the restart: %v, want none", ids)
+			}
+			if got := e.countEvents(t, run.ID, models.BatchEventInterrupted); got != 1 {
+				t.Errorf("%d interrupted events, want 1", got)
+			}
+			if attempts := e.fake.Attempts(); attempts != 1 {
+				t.Fatalf("%d send attempts, want none while interrupted", attempts)
+			}
+
+			switch then {
+			case "resume":
+				if resumed, err := e.worker.ResumeBatch(run.ID); err != nil || !resumed {
+					t.Fatalf("ResumeBatch = %t, %v; want true", resumed, err)
+				}
+				finished := e.runUntilFinished(t, run.ID)
+				if finished.Status != models.BatchStatusCompleted || finished.SentCount != 3 {
+					t.Errorf("batch %s with %d sent, want completed with 3", finished.Status, finished.SentCount)
+				}
+				if sent := e.fake.Sent(); len(sent) != 3 {
+					t.Errorf("fake got %d messages, want 3: none sent twice", len(sent))
+				}
+
+			case "cancel":
+				if cancelled, err := e.worker.CancelBatch(run.ID); err != nil || !cancelled {
+					t.Fatalf("CancelBatch = %t, %v; want true", cancelled, err)
+				}
+				for range 3 {
+					e.tick()
+				}
+				if status := e.getBatch(t, run.ID).Status; status != models.BatchStatusCancelled {
+					t.Errorf("status = %q, want %q", status, models.BatchStatusCancelled)
+				}
+				if attempts := e.fake.Attempts(); attempts != 1 {
+					t.Errorf("%d send attempts, want none after the cancel", attempts)
+				}
+				if resumed, err := e.worker.ResumeBatch(run.ID); err != nil || resumed {
+					t.Errorf("ResumeBatch after the cancel = %t, %v; want false", resumed, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package batch
+
+import (
+	"math"
+	"time"
+)
+
+// throughputWindow is how many recent gaps between send attempts the rolling
+// messages-per-minute figure is averaged over.
+const throughputWindow = 10
+
+// throughput tracks the gaps between consecutive send attempts of the running
+// batch. Gaps that span a pause are never recorded, so a long disconnect or
+// quiet hours window does not drag the average down.
+type throughput struct {
+	lastAttemptAt time.Time
+	gaps          []time.Duration
+}
+
+// record notes a send attempt (sent or failed) at t.
+func (tp *throughput) record(t time.Time) {
+	if !tp.lastAttemptAt.IsZero() {
+		tp.gaps = append(tp.gaps, t.Sub(tp.lastAttemptAt))
+		if len(tp.gaps) > throughputWindow {
+			tp.gaps = tp.gaps[len(tp.gaps)-throughputWindow:]
+		}
+	}
+	tp.lastAttemptAt = t
+}
+
+// interrupt discards the running gap; the next attempt starts a new one.
+func (tp *throughput) interrupt() {
+	tp.lastAttemptAt = time.Time{}
+}
+
+// averageGap returns the mean gap in the window, or 0 when there is none yet.
+func (tp *throughput) averageGap() time.Duration {
+	if len(tp.gaps) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, gap := range tp.gaps {
+		total += gap
+	}
+	return total / time.Duration(len(tp.gaps))
+}
+
+// perMinute converts an average gap between messages to messages per minute,
+// rounded to two decimals.
+func perMinute(gap time.Duration) float64 {
+	if gap <= 0 {
+		return 0
+	}
+	return math.Round(float64(time.Minute)/float64(gap)*100) / 100
+}
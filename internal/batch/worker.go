@@ -2,12 +2,16 @@ package batch
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/rand"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"friday/internal/events"
 	"friday/internal/models"
 	"friday/internal/template"
 	"friday/internal/whatsapp"
@@ -16,14 +20,34 @@ import (
 type Worker struct {
 	batchRepo   *models.BatchRunRepository
 	msgRepo     *models.BatchMessageRepository
+	eventRepo   *models.BatchEventRepository
 	memberRepo  *models.GroupMemberRepository
 	draftRepo   *models.DraftRepository
 	attrRepo    *models.AttributeRepository
-	waClient    *whatsapp.Client
+	groupAttrRepo *models.GroupAttributeRepository
+	validationRepo *models.ValidationRepository
+	quarantineRepo *models.QuarantineRepository
+	settingsRepo *models.SettingsRepository
+	outboxRepo  *models.OutboxRepository
+	waClient    whatsapp.Messenger
+	hub         *events.Hub
 
 	mu          sync.RWMutex
-	currentRun  *ActiveBatchState
-	nextSendAt  time.Time
+	active      map[int64]*ActiveBatchState // Running batches by ID
+	order       []int64                     // Running batch IDs in start order; sends rotate through them
+	turn        int                         // Index into order of the batch that sends next
+	nextSendAt  time.Time                   // Shared by all running batches so the delay floor holds across them
+	preparing   map[int64]int               // Messages created so far of the batches Prepare is filling in
+	quietHours  *QuietHours // From the environment; the batch.quiet_hours setting takes precedence
+	notifier    *Notifier
+	documents   *Documents // Nil unless FRIDAY_DOCUMENTS_DIR is set
+	jitter      atomic.Int64 // Bound of the random extra delay per send, see SetSendJitter
+
+	// Parsed batch.quiet_hours setting, re-parsed only when the setting changes
+	quietSpec   string
+	quietParsed *QuietHours
+
+	lastOutboxExpiry time.Time // Queued outbox messages are expired at most every outboxExpiryInterval
 
 	subscribers     map[int64][]chan *ProgressEvent
 	subscriberMutex sync.RWMutex
@@ -32,24 +56,94 @@ type Worker struct {
 	cancel context.CancelFunc
 }
 
+const (
+	// validationCacheTTL is how long a "registered on WhatsApp" check is trusted.
+	validationCacheTTL = 7 * 24 * time.Hour
+	// validationChunkSize bounds the number of phones sent per IsOnWhatsApp query.
+	validationChunkSize = 50
+	// maxSendJitter bounds the random delay added on top of the configured delay
+	// range per message, unless SetSendJitter changes it.
+	maxSendJitter = 3 * time.Second
+	// documentUploadTimeout is added to the send timeout for documents, which are uploaded first.
+	documentUploadTimeout = 90 * time.Second
+	// contactLookupTimeout bounds a WhatsApp contact store lookup for placeholders.
+	contactLookupTimeout = 5 * time.Second
+	// NotOnWhatsAppError is recorded on messages pre-failed by validation.
+	NotOnWhatsAppError = "not on WhatsApp"
+	// UnresolvedLIDError is recorded on messages pre-failed because the recipient is
+	// a hidden number (LID) that WhatsApp has not given a phone number for.
+	UnresolvedLIDError = "hidden number (LID) with no known phone number"
+	// StatusWaitingQuietHours is reported in progress events while sending is paused for quiet hours.
+	StatusWaitingQuietHours = "waiting_quiet_hours"
+	// StatusPausedReadOnly is reported in progress events while the server is in read-only mode.
+	StatusPausedReadOnly = "paused_read_only"
+)
+
 type ActiveBatchState struct {
 	BatchID       int64
-	DraftContent  string
+	GroupID       int64 // Its placeholder defaults apply to every recipient
+	Delimiters    template.Delimiters // Of the draft at creation; the messages hold the content
+	SpinSeed      int64
+	MinDelay      time.Duration // Bounds of the random delay after its sends, stored when the batch first started
+	MaxDelay      time.Duration
+	PauseReason   string // Non-empty while sending is paused: "read_only", "disconnected" or "quiet_hours"
 	CurrentJID    string
 	CurrentName   string
+
+	DocumentAttribute string // Non-empty to send each recipient the file named by this attribute, with the message as caption
+
+	rate throughput // Gaps between recent send attempts, excluding paused time
+
+	// The run's label and counts, kept here while it runs so progress needs no
+	// database read; countMessages updates both. Guarded by Worker.mu.
+	label  string
+	counts batchCounts
+
+	// Closed when the batch leaves the running set, e.g. on cancel, so a send
+	// that has not reached WhatsApp yet is dropped
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// batchCounts mirrors the count columns of a running batch.
+type batchCounts struct {
+	total, sent, failed, validationFailed, skipped int
+}
+
+// stop closes done; safe to call more than once.
+func (s *ActiveBatchState) stop() {
+	s.doneOnce.Do(func() { close(s.done) })
+}
+
+// stopped reports whether the batch has left the running set.
+func (s *ActiveBatchState) stopped() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
 }
 
 type ProgressEvent struct {
 	Type              string          `json:"type"`
 	BatchID           int64           `json:"batch_id"`
 	Status            string          `json:"status"`
+	Label             string          `json:"label,omitempty"` // Set on progress, started and per-message events
 	TotalCount        int             `json:"total_count"`
 	SentCount         int             `json:"sent_count"`
 	FailedCount       int             `json:"failed_count"`
+	ValidationFailedCount int         `json:"validation_failed_count"`
+	SkippedCount      int             `json:"skipped_count"`
 	CurrentContact    string          `json:"current_contact,omitempty"`
 	NextSendInSeconds int             `json:"next_send_in_seconds"`
+	PreparedCount     int             `json:"prepared_count,omitempty"` // Messages created so far while the batch is preparing
 	LastMessage       *MessageInfo    `json:"last_message,omitempty"`
 	ErrorMessage      string          `json:"error_message,omitempty"`
+
+	// Rolling average over the last sends of the running batch, 0 until two attempts were made
+	MessagesPerMinute     float64    `json:"messages_per_minute"`
+	EstimatedCompletionAt *time.Time `json:"estimated_completion_at,omitempty"`
 }
 
 type MessageInfo struct {
@@ -59,34 +153,53 @@ type MessageInfo struct {
 	SentAt      string `json:"sent_at"`
 	Status      string `json:"status"`
 	Error       string `json:"error,omitempty"`
+	ErrorCode   string `json:"error_code,omitempty"` // See FailureCode, or SkipRecipientCap for a skipped message
 }
 
 func NewWorker(
 	batchRepo *models.BatchRunRepository,
 	msgRepo *models.BatchMessageRepository,
+	eventRepo *models.BatchEventRepository,
 	memberRepo *models.GroupMemberRepository,
 	draftRepo *models.DraftRepository,
 	attrRepo *models.AttributeRepository,
-	waClient *whatsapp.Client,
+	groupAttrRepo *models.GroupAttributeRepository,
+	validationRepo *models.ValidationRepository,
+	quarantineRepo *models.QuarantineRepository,
+	settingsRepo *models.SettingsRepository,
+	outboxRepo *models.OutboxRepository,
+	waClient whatsapp.Messenger,
+	hub *events.Hub,
 ) *Worker {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Worker{
+	w := &Worker{
 		batchRepo:   batchRepo,
 		msgRepo:     msgRepo,
+		eventRepo:   eventRepo,
 		memberRepo:  memberRepo,
 		draftRepo:   draftRepo,
 		attrRepo:    attrRepo,
+		groupAttrRepo: groupAttrRepo,
+		validationRepo: validationRepo,
+		quarantineRepo: quarantineRepo,
+		settingsRepo: settingsRepo,
+		outboxRepo:  outboxRepo,
 		waClient:    waClient,
+		hub:         hub,
+		active:      make(map[int64]*ActiveBatchState),
+		preparing:   make(map[int64]int),
 		subscribers: make(map[int64][]chan *ProgressEvent),
 		ctx:         ctx,
 		cancel:      cancel,
 	}
+	w.jitter.Store(int64(maxSendJitter))
+	return w
 }
 
 // Run starts the worker's main processing loop. Call in a goroutine: go worker.Run()
 func (w *Worker) Run() {
-	log.Println("Batch worker started")
+	slog.Info("Batch worker started")
 
 	w.resumeIncompleteRuns()
 
@@ -96,7 +209,7 @@ func (w *Worker) Run() {
 	for {
 		select {
 		case <-w.ctx.Done():
-			log.Println("Batch worker shutting down")
+			slog.Info("Batch worker shutting down")
 			return
 		case <-ticker.C:
 			w.processNextMessage()
@@ -104,113 +217,438 @@ func (w *Worker) Run() {
 	}
 }
 
+// Shutdown stops the send loop. Running batches stay running in the database and
+// resume on the next start, or are held with batch.auto_resume off; a send
+// already in progress is aborted and fails as interrupted.
 func (w *Worker) Shutdown() {
-	log.Println("Batch worker shutdown requested")
+	if ids := w.GetActiveBatchIDs(); len(ids) > 0 {
+		slog.Info("Batch worker shutdown requested", "running", ids)
+	} else {
+		slog.Info("Batch worker shutdown requested")
+	}
 	w.cancel()
 }
 
+// resumeIncompleteRuns deals with the batches that were running when the server
+// stopped: they resume, or with batch.auto_resume off are held as interrupted
+// until resumed through the API. Batches still preparing fail.
 func (w *Worker) resumeIncompleteRuns() {
-	active, err := w.batchRepo.GetActive()
+	w.failInterruptedPreparations()
+
+	running, err := w.batchRepo.GetRunning()
 	if err != nil {
-		log.Printf("Error checking for active batch: %v", err)
+		slog.Error("Error checking for active batch", "error", err)
 		return
 	}
 
-	if active != nil {
-		log.Printf("Resuming batch run %d (was running)", active.ID)
-		w.startBatch(active)
-		return
+	autoResume, err := w.settingsRepo.GetBool(models.SettingAutoResumeBatches, models.DefaultAutoResumeBatches)
+	if err != nil {
+		slog.Error("Failed to read auto-resume setting, resuming", "error", err)
+		autoResume = true
+	}
+
+	if len(running) == 0 {
+		slog.Info("No batches were running at shutdown")
+	} else {
+		ids := make([]int64, len(running))
+		for i := range running {
+			ids[i] = running[i].ID
+		}
+		slog.Info("Found batches running at shutdown", "batch_ids", ids, "auto_resume", autoResume)
+	}
+
+	// Everything that was running resumes, even if batch.max_concurrent was lowered since
+	for i := range running {
+		run := &running[i]
+		if autoResume {
+			slog.Info("Resuming batch run (was running)", "batch_id", run.ID, "sent", run.SentCount, "failed", run.FailedCount, "total", run.TotalCount)
+			w.startBatch(run)
+			continue
+		}
+
+		if _, err := w.batchRepo.Interrupt(run.ID); err != nil {
+			slog.Error("Failed to hold interrupted batch", "batch_id", run.ID, "error", err)
+			continue
+		}
+		w.recordEvent(run.ID, models.BatchEventInterrupted, "", "Server restarted; waiting to be resumed")
+		slog.Warn("Holding interrupted batch until resumed", "batch_id", run.ID, "sent", run.SentCount, "failed", run.FailedCount, "total", run.TotalCount)
+	}
+
+	if interrupted, err := w.batchRepo.GetInterrupted(); err != nil {
+		slog.Error("Error checking for interrupted batches", "error", err)
+	} else if len(interrupted) > 0 {
+		ids := make([]int64, len(interrupted))
+		for i := range interrupted {
+			ids[i] = interrupted[i].ID
+		}
+		slog.Warn("Interrupted batches wait for POST /api/batch-runs/{id}/resume or cancel", "batch_ids", ids)
 	}
 
 	w.checkQueue()
 }
 
-func (w *Worker) checkQueue() {
-	w.mu.RLock()
-	hasActive := w.currentRun != nil
-	w.mu.RUnlock()
+// ResumeBatch puts an interrupted batch back in the queue, ahead of batches
+// created after it. It returns false if the batch was not interrupted.
+func (w *Worker) ResumeBatch(batchID int64) (bool, error) {
+	ok, err := w.batchRepo.Requeue(batchID)
+	if err != nil || !ok {
+		return false, err
+	}
 
-	if hasActive {
+	slog.Info("Interrupted batch resumed", "batch_id", batchID)
+	w.recordEvent(batchID, models.BatchEventResumed, "", "Resumed after interruption")
+	w.broadcastEvent(batchID, &ProgressEvent{
+		Type:    "resumed",
+		BatchID: batchID,
+		Status:  string(models.BatchStatusQueued),
+	})
+
+	go w.checkQueue()
+
+	return true, nil
+}
+
+// checkQueue starts queued batches, oldest first, while fewer than
+// batch.max_concurrent are running.
+func (w *Worker) checkQueue() {
+	// Queued batches wait for read-only mode to end before they start
+	if w.waClient.IsReadOnly() {
 		return
 	}
 
-	queued, err := w.batchRepo.GetNextQueued()
+	limit := w.MaxConcurrent()
+	for {
+		w.mu.RLock()
+		running := len(w.active)
+		w.mu.RUnlock()
+		if running >= limit {
+			return
+		}
+
+		queued, err := w.batchRepo.GetNextQueued()
+		if err != nil {
+			slog.Error("Error checking batch queue", "error", err)
+			return
+		}
+		if queued == nil {
+			return
+		}
+
+		slog.Info("Starting queued batch run", "batch_id", queued.ID)
+		if !w.startBatch(queued) {
+			return
+		}
+	}
+}
+
+// startBatch adds a batch to the running set. It returns false if the batch
+// could not be moved out of the queue, so checkQueue does not pick it again.
+func (w *Worker) startBatch(run *models.BatchRun) bool {
+	// Batches created before creation became a single transaction may have
+	// lost their messages to a failed insert; they would never finish
+	pending, sending, sent, failed, skipped, err := w.msgRepo.GetStats(run.ID)
 	if err != nil {
-		log.Printf("Error checking batch queue: %v", err)
-		return
+		slog.Error("Failed to count batch messages", "batch_id", run.ID, "error", err)
+		return false
+	}
+	if pending+sending+sent+failed+skipped == 0 && run.TotalCount > 0 {
+		slog.Error("Batch has no messages", "batch_id", run.ID, "total_count", run.TotalCount)
+		w.failBatch(run, "Batch has no messages; its creation was interrupted", "")
+		return true
 	}
 
-	if queued != nil {
-		log.Printf("Starting queued batch run %d", queued.ID)
-		w.startBatch(queued)
+	// Every message snapshotted its content, variant chosen, at creation and is
+	// sent as it was, however the draft changed since. Only batches created
+	// before the delimiters were recorded read them from the draft
+	delimiters := template.DefaultDelimiters
+	if run.Delimiters != nil {
+		delimiters = *run.Delimiters
+	} else {
+		draft, err := w.draftRepo.GetByID(run.DraftID)
+		if err != nil {
+			slog.Error("Failed to get draft for batch", "batch_id", run.ID, "error", err)
+			w.failBatch(run, "Failed to load draft", fmt.Sprintf("Failed to load draft: %v", err))
+			return true
+		}
+		if draft != nil {
+			delimiters = draft.Delimiters
+		} else {
+			slog.Warn("Draft no longer exists, reading message snapshots with the default delimiters", "batch_id", run.ID, "draft_id", run.DraftID)
+		}
 	}
-}
 
-func (w *Worker) startBatch(run *models.BatchRun) {
-	draft, err := w.draftRepo.GetByID(run.DraftID)
-	if err != nil || draft == nil {
-		log.Printf("Failed to get draft for batch %d: %v", run.ID, err)
-		w.batchRepo.Fail(run.ID, "Draft not found")
-		return
+	// Batches keep the delay settings they first started with, also when resumed
+	minSec, maxSec := w.delaySettings()
+	if run.MinDelaySeconds > 0 && run.MaxDelaySeconds >= run.MinDelaySeconds {
+		minSec, maxSec = run.MinDelaySeconds, run.MaxDelaySeconds
 	}
 
-	if err := w.batchRepo.Start(run.ID); err != nil {
-		log.Printf("Failed to start batch %d: %v", run.ID, err)
-		return
+	started, err := w.batchRepo.Start(run.ID, run.Status, minSec, maxSec)
+	if err != nil {
+		slog.Error("Failed to start batch", "batch_id", run.ID, "error", err)
+		return false
+	}
+	if !started {
+		// Cancelled after it was picked from the queue; it is out of the queue either way
+		slog.Info("Batch changed status before it started", "batch_id", run.ID)
+		return true
+	}
+	// The log keeps the order the messages go out in, with the seed of a shuffle
+	order := "Send order: " + run.Sort
+	if run.Sort == models.BatchSortRandom {
+		order += fmt.Sprintf(" (seed %d)", run.SortSeed)
+	}
+	w.recordEvent(run.ID, models.BatchEventStarted, "", order)
+
+	// Batches created before hidden numbers were checked may still hold some
+	if failed, err := w.FailUnresolvedLIDs(run.ID); err != nil {
+		slog.Warn("Failed to check hidden numbers", "batch_id", run.ID, "error", err)
+	} else if failed > 0 {
+		slog.Info("Skipped hidden numbers without a phone number", "batch_id", run.ID, "skipped", failed)
+	}
+
+	if w.waClient.IsConnected() {
+		invalid, err := w.ValidateRecipients(run.ID)
+		if err != nil {
+			slog.Warn("Recipient validation failed, sending to all", "batch_id", run.ID, "error", err)
+		} else if invalid > 0 {
+			slog.Info("Skipped recipients not on WhatsApp", "batch_id", run.ID, "skipped", invalid)
+		}
 	}
 
+	// The checks above changed the counts; from here on the state keeps them. A
+	// cancel holds w.mu while it changes the status, so once the batch is seen
+	// running here, a cancel finds it in the running set
 	w.mu.Lock()
-	w.currentRun = &ActiveBatchState{
+	fresh, err := w.batchRepo.GetByID(run.ID)
+	if err != nil || fresh == nil {
+		slog.Warn("Failed to reload batch counts", "batch_id", run.ID, "error", err)
+	} else if fresh.Status != models.BatchStatusRunning {
+		w.mu.Unlock()
+		slog.Info("Batch changed status before it started", "batch_id", run.ID, "status", fresh.Status)
+		return true
+	} else {
+		run = fresh
+	}
+	state := &ActiveBatchState{
 		BatchID:      run.ID,
-		DraftContent: draft.Content,
+		GroupID:      run.GroupID,
+		Delimiters:   delimiters,
+		SpinSeed:     run.SpinSeed,
+		MinDelay:     time.Duration(minSec) * time.Second,
+		MaxDelay:     time.Duration(maxSec) * time.Second,
+		DocumentAttribute: run.DocumentAttribute,
+		label:        run.Label,
+		counts: batchCounts{
+			total:            run.TotalCount,
+			sent:             run.SentCount,
+			failed:           run.FailedCount,
+			validationFailed: run.ValidationFailedCount,
+			skipped:          run.SkippedCount,
+		},
+		done:         make(chan struct{}),
 	}
+	w.active[run.ID] = state
+	w.order = append(w.order, run.ID)
+	first := len(w.order) == 1
 	w.mu.Unlock()
 
-	w.scheduleNextMessage()
+	// The first running batch sets the pace; later ones join the schedule as it is.
+	// A batch that sent before a restart or interruption first waits out the rest
+	// of that delay, so a crash loop cannot speed it up.
+	switch {
+	case run.LastSentAt != nil:
+		w.deferNextSend(run.LastSentAt.Add(w.randomDelay(state)))
+	case first:
+		w.scheduleNextMessage(state)
+	}
+
+	slog.Info("Batch started", "batch_id", run.ID, "recipients", run.TotalCount)
 
-	log.Printf("Batch %d started, first message in ~10-15 seconds, sending to %d contacts", run.ID, run.TotalCount)
+	w.hub.Publish(events.TopicBatch, &ProgressEvent{
+		Type:       "started",
+		BatchID:    run.ID,
+		Status:     string(models.BatchStatusRunning),
+		Label:      run.Label,
+		TotalCount: run.TotalCount,
+	})
 
 	w.broadcastProgress(run.ID)
+	return true
 }
 
+// failBatch marks a batch that could not start as failed. detail is recorded in
+// the batch's event log; empty means the same as message.
+func (w *Worker) failBatch(run *models.BatchRun, message, detail string) {
+	batchID := run.ID
+	if detail == "" {
+		detail = message
+	}
+	failed, err := w.batchRepo.Fail(batchID, run.Status, message)
+	if err != nil {
+		slog.Error("Failed to mark batch failed", "batch_id", batchID, "error", err)
+		return
+	}
+	if !failed {
+		slog.Info("Batch changed status before it could be marked failed", "batch_id", batchID)
+		return
+	}
+	w.broadcastEvent(batchID, &ProgressEvent{
+		Type:         "failed",
+		BatchID:      batchID,
+		Status:       string(models.BatchStatusFailed),
+		ErrorMessage: message,
+	})
+	w.recordEvent(batchID, models.BatchEventFailed, "", detail)
+	w.notifyFinished(batchID)
+}
+
+// processNextMessage runs once per tick. Pauses apply to every running batch;
+// when the shared schedule is due, the batch whose turn it is sends one message.
 func (w *Worker) processNextMessage() {
 	w.mu.RLock()
-	current := w.currentRun
+	running := w.runningLocked()
 	nextSend := w.nextSendAt
 	w.mu.RUnlock()
 
-	if current == nil {
+	outbox := w.outboxWaiting()
+	if len(running) < w.MaxConcurrent() {
 		w.checkQueue()
+		if len(running) == 0 && !outbox {
+			return
+		}
+	}
+
+	if w.waClient.IsReadOnly() {
+		for _, current := range running {
+			if w.setPaused(current, "read_only", "Server is in read-only mode") {
+				slog.Warn("Read-only mode, pausing batch", "batch_id", current.BatchID)
+			}
+			w.broadcastProgress(current.BatchID)
+		}
 		return
 	}
 
 	if time.Now().Before(nextSend) {
-		w.broadcastProgress(current.BatchID)
+		for _, current := range running {
+			w.broadcastProgress(current.BatchID)
+		}
+		return
+	}
+
+	if quiet, resumeAt := w.inQuietHours(time.Now()); quiet {
+		w.mu.Lock()
+		w.nextSendAt = resumeAt
+		w.mu.Unlock()
+		for _, current := range running {
+			slog.Info("Quiet hours, batch paused", "batch_id", current.BatchID, "resume_at", resumeAt.Format(time.RFC3339))
+			w.setPaused(current, "quiet_hours", "Quiet hours until "+resumeAt.Format(time.RFC3339))
+			w.broadcastProgress(current.BatchID)
+		}
 		return
 	}
 
 	if !w.waClient.IsConnected() {
-		log.Printf("WhatsApp disconnected, pausing batch %d", current.BatchID)
-		w.broadcastEvent(current.BatchID, &ProgressEvent{
-			Type:         "error",
-			BatchID:      current.BatchID,
-			ErrorMessage: "WhatsApp disconnected - waiting for reconnection",
-		})
+		for _, current := range running {
+			if w.setPaused(current, "disconnected", "WhatsApp disconnected") {
+				slog.Warn("WhatsApp disconnected, pausing batch", "batch_id", current.BatchID)
+			}
+			w.broadcastEvent(current.BatchID, &ProgressEvent{
+				Type:         "error",
+				BatchID:      current.BatchID,
+				ErrorMessage: "WhatsApp disconnected - waiting for reconnection",
+			})
+		}
 		return
 	}
 
-	msg, err := w.msgRepo.GetNextPending(current.BatchID)
-	if err != nil {
-		log.Printf("Error getting next message: %v", err)
+	for _, current := range running {
+		w.setPaused(current, "", "")
+	}
+
+	// Single sends queued while disconnected go before batch messages, one per slot
+	if outbox {
+		w.sendOutboxItem()
 		return
 	}
 
-	if msg == nil {
-		w.completeBatch(current.BatchID)
+	// Batches with nothing left complete and hand the turn to the next one
+	for range running {
+		current := w.takeTurn()
+		if current == nil {
+			return
+		}
+
+		msg, err := w.msgRepo.GetNextPending(current.BatchID)
+		if err != nil {
+			slog.Error("Error getting next message", "batch_id", current.BatchID, "error", err)
+			return
+		}
+
+		if msg == nil {
+			w.completeBatch(current.BatchID)
+			continue
+		}
+
+		w.sendMessage(current, msg)
 		return
 	}
+}
+
+// runningLocked returns the running batches in start order. Callers hold w.mu.
+func (w *Worker) runningLocked() []*ActiveBatchState {
+	states := make([]*ActiveBatchState, 0, len(w.order))
+	for _, id := range w.order {
+		states = append(states, w.active[id])
+	}
+	return states
+}
+
+// takeTurn returns the running batch that sends next and passes the turn on.
+func (w *Worker) takeTurn() *ActiveBatchState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.order) == 0 {
+		return nil
+	}
+	if w.turn >= len(w.order) {
+		w.turn = 0
+	}
+	state := w.active[w.order[w.turn]]
+	w.turn++
+	return state
+}
 
-	w.sendMessage(current, msg)
+// turnsBeforeLocked is how many sends of other batches come before batchID's next one.
+func (w *Worker) turnsBeforeLocked(batchID int64) int {
+	n := len(w.order)
+	for i, id := range w.order {
+		if id == batchID {
+			return ((i-w.turn)%n + n) % n
+		}
+	}
+	return 0
+}
+
+// removeActiveLocked drops a batch from the running set, keeping the turn on
+// the batch that was due next. Callers hold w.mu.
+func (w *Worker) removeActiveLocked(batchID int64) bool {
+	state, ok := w.active[batchID]
+	if !ok {
+		return false
+	}
+	state.stop()
+	delete(w.active, batchID)
+	for i, id := range w.order {
+		if id == batchID {
+			w.order = append(w.order[:i], w.order[i+1:]...)
+			if i < w.turn {
+				w.turn--
+			}
+			break
+		}
+	}
+	return true
 }
 
 func (w *Worker) sendMessage(state *ActiveBatchState, msg *models.BatchMessage) {
@@ -226,128 +664,359 @@ func (w *Worker) sendMessage(state *ActiveBatchState, msg *models.BatchMessage)
 	state.CurrentName = contactName
 	w.mu.Unlock()
 
-	w.msgRepo.MarkSending(msg.ID)
+	// Other batches and single sends may have reached the recipient cap since
+	// the batch was created; nothing is sent, so the next tick goes on without a delay
+	if reason := w.recipientCapReason(msg.JID); reason != "" {
+		if _, err := w.skipWithReason(state.BatchID, msg, SkipRecipientCap, reason); err != nil {
+			slog.Error("Failed to skip capped message", "batch_id", state.BatchID, "message_id", msg.ID, "error", err)
+		}
+		return
+	}
+
+	// Skipped since it was picked: the turn passes and the next tick sends the next one
+	if ok, err := w.msgRepo.MarkSending(msg.ID); err != nil || !ok {
+		if err != nil {
+			slog.Error("Failed to mark message as sending", "batch_id", state.BatchID, "message_id", msg.ID, "error", err)
+		}
+		return
+	}
 	w.broadcastProgress(state.BatchID)
 
-	values, err := w.getPlaceholderValues(msg.JID)
+	if w.isBlocked(msg.JID) {
+		slog.Warn("Recipient is blocked, not sending", "batch_id", state.BatchID, "jid", msg.JID)
+		w.markMessageFailed(state.BatchID, msg, FailureBlocked, "Contact is blocked by the linked account")
+		w.scheduleNextMessage(state)
+		return
+	}
+
+	values, err := w.getPlaceholderValues(msg.JID, state.GroupID)
 	if err != nil {
-		log.Printf("Error getting placeholders for %s: %v", msg.JID, err)
-		w.markMessageFailed(state.BatchID, msg, fmt.Sprintf("Failed to get placeholder values: %v", err))
-		w.scheduleNextMessage()
+		slog.Error("Error getting placeholders", "batch_id", state.BatchID, "jid", msg.JID, "error", err)
+		w.markMessageFailed(state.BatchID, msg, FailureTemplate, fmt.Sprintf("Failed to get placeholder values: %v", err))
+		w.scheduleNextMessage(state)
 		return
 	}
 
-	sentContent, _ := template.FillPlaceholders(state.DraftContent, values)
+	sentContent, _ := state.Delimiters.FillPlaceholders(msg.TemplateContent, values, template.SpinSeed(state.SpinSeed, msg.JID))
+
+	var document *Document
+	if state.DocumentAttribute != "" {
+		document, err = w.loadDocument(values[state.DocumentAttribute])
+		if err != nil {
+			slog.Warn("Document not sendable", "batch_id", state.BatchID, "jid", msg.JID, "error", err)
+			w.markMessageFailed(state.BatchID, msg, FailureDocument, fmt.Sprintf("Document unavailable: %v", err))
+			w.scheduleNextMessage(state)
+			return
+		}
+	}
+
+	// Cancelled since its turn came up: the message has not left, so it stays pending.
+	// A send already under way is aborted below and fails, as it may have been delivered.
+	if state.stopped() {
+		if err := w.msgRepo.MarkPending(msg.ID); err != nil {
+			slog.Error("Failed to requeue message", "batch_id", state.BatchID, "message_id", msg.ID, "error", err)
+		}
+		slog.Info("Batch stopped before send, message left pending", "batch_id", state.BatchID, "jid", msg.JID)
+		return
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := w.sendContext(state, document != nil)
 	defer cancel()
 
-	err = w.waClient.SendMessage(ctx, msg.JID, sentContent)
+	var waMessageID string
+	if document != nil {
+		waMessageID, err = w.waClient.SendDocument(ctx, msg.JID, document.Data, document.FileName, document.MimeType, sentContent)
+	} else {
+		waMessageID, err = w.waClient.SendMessage(ctx, msg.JID, sentContent)
+	}
+	// Failed attempts count too: they reached WhatsApp and are followed by the same delay
+	if !errors.Is(err, whatsapp.ErrReadOnly) {
+		if err := w.batchRepo.SetLastSentAt(state.BatchID, time.Now()); err != nil {
+			slog.Warn("Failed to record last send", "batch_id", state.BatchID, "error", err)
+		}
+	}
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		// Aborted by cancel or shutdown rather than a WhatsApp error
+		code, reason := FailureCancelled, "Batch cancelled while sending; delivery unknown"
+		if !state.stopped() {
+			code, reason = FailureInterrupted, models.InterruptedSendError
+		}
+		slog.Warn("Send aborted", "batch_id", state.BatchID, "jid", msg.JID, "code", code)
+		w.markMessageFailed(state.BatchID, msg, code, reason)
+		w.scheduleNextMessage(state)
+		return
+	}
+	if errors.Is(err, whatsapp.ErrReadOnly) {
+		// Read-only mode started mid-send: put the message back, the next tick pauses
+		if err := w.msgRepo.MarkPending(msg.ID); err != nil {
+			slog.Error("Failed to requeue message", "batch_id", state.BatchID, "message_id", msg.ID, "error", err)
+		}
+		return
+	}
 	if err != nil {
-		log.Printf("Failed to send message to %s: %v", msg.JID, err)
-		w.markMessageFailed(state.BatchID, msg, fmt.Sprintf("Send failed: %v", err))
-		w.scheduleNextMessage()
+		slog.Warn("Failed to send message", "batch_id", state.BatchID, "jid", msg.JID, "error", err)
+		w.markMessageFailed(state.BatchID, msg, ClassifySendError(err), fmt.Sprintf("Send failed: %v", err))
+		if whatsapp.IsPermanentSendError(err) {
+			w.recordPermanentFailure(state.BatchID, msg.JID, err.Error())
+		}
+		w.scheduleNextMessage(state)
 		return
 	}
 
-	w.markMessageSent(state.BatchID, msg, sentContent, contactName)
-	w.scheduleNextMessage()
+	w.markMessageSent(state.BatchID, msg, sentContent, contactName, waMessageID)
+	w.scheduleNextMessage(state)
 }
 
-func (w *Worker) markMessageSent(batchID int64, msg *models.BatchMessage, sentContent, contactName string) {
-	w.msgRepo.MarkSent(msg.ID, sentContent)
-	w.batchRepo.IncrementSentCount(batchID)
+func (w *Worker) markMessageSent(batchID int64, msg *models.BatchMessage, sentContent, contactName, waMessageID string) {
+	w.msgRepo.MarkSent(msg.ID, sentContent, waMessageID)
+	w.countMessages(batchID, 1, 0, 0)
+	w.recordAttempt(batchID)
 
-	log.Printf("Message sent to %s", msg.JID)
+	slog.Debug("Message sent", "batch_id", batchID, "jid", msg.JID)
+	w.recordEvent(batchID, models.BatchEventMessageSent, msg.JID, "")
+	if _, err := w.quarantineRepo.Clear(msg.JID); err != nil {
+		slog.Error("Failed to reset failure counter", "jid", msg.JID, "error", err)
+	}
+
+	w.broadcastEvent(batchID, w.messageEvent(batchID, "message_sent", &MessageInfo{
+		JID:         msg.JID,
+		ContactName: contactName,
+		SentContent: sentContent,
+		SentAt:      time.Now().Format(time.RFC3339),
+		Status:      "sent",
+	}))
+}
 
-	run, _ := w.batchRepo.GetByID(batchID)
-	var totalCount, sentCount, failedCount int
-	var status string
-	if run != nil {
-		totalCount = run.TotalCount
-		sentCount = run.SentCount
-		failedCount = run.FailedCount
-		status = string(run.Status)
+// isBlocked reports whether the linked account has blocked jid. The client
+// caches the blocklist, so this is cheap; when it cannot be fetched the send goes ahead.
+func (w *Worker) isBlocked(jid string) bool {
+	blocked, err := w.waClient.BlockedJIDs(context.Background())
+	if err != nil {
+		slog.Debug("Failed to get blocklist", "error", err)
+		return false
 	}
+	return blocked[jid]
+}
 
-	w.broadcastEvent(batchID, &ProgressEvent{
-		Type:        "message_sent",
-		BatchID:     batchID,
-		Status:      status,
-		TotalCount:  totalCount,
-		SentCount:   sentCount,
-		FailedCount: failedCount,
-		LastMessage: &MessageInfo{
-			JID:         msg.JID,
-			ContactName: contactName,
-			SentContent: sentContent,
-			SentAt:      time.Now().Format(time.RFC3339),
-			Status:      "sent",
-		},
-	})
+// recipientCapReason returns why jid may not be sent another message under
+// the recipient cap, or "" when it may. When the count cannot be read the send goes ahead.
+func (w *Worker) recipientCapReason(jid string) string {
+	recipientCap, err := LoadRecipientCap(w.settingsRepo)
+	if err != nil {
+		slog.Warn("Failed to read recipient cap", "error", err)
+		return ""
+	}
+	reached, err := recipientCap.Reached(w.msgRepo, []string{jid})
+	if err != nil {
+		slog.Warn("Failed to count recent sends", "jid", jid, "error", err)
+		return ""
+	}
+	if count, ok := reached[jid]; ok {
+		return recipientCap.Reason(count)
+	}
+	return ""
 }
 
-func (w *Worker) markMessageFailed(batchID int64, msg *models.BatchMessage, errorMessage string) {
-	w.msgRepo.MarkFailed(msg.ID, errorMessage)
-	w.batchRepo.IncrementFailedCount(batchID)
+func (w *Worker) markMessageFailed(batchID int64, msg *models.BatchMessage, code FailureCode, errorMessage string) {
+	w.msgRepo.MarkFailed(msg.ID, string(code), errorMessage)
+	w.countMessages(batchID, 0, 1, 0)
+	w.recordAttempt(batchID)
+	w.recordEvent(batchID, models.BatchEventMessageFailed, msg.JID, errorMessage)
 
 	contactName := ""
 	if msg.ContactName != nil {
 		contactName = *msg.ContactName
 	}
 
-	run, _ := w.batchRepo.GetByID(batchID)
-	var totalCount, sentCount, failedCount int
-	var status string
-	if run != nil {
-		totalCount = run.TotalCount
-		sentCount = run.SentCount
-		failedCount = run.FailedCount
-		status = string(run.Status)
+	w.broadcastEvent(batchID, w.messageEvent(batchID, "message_failed", &MessageInfo{
+		JID:         msg.JID,
+		ContactName: contactName,
+		SentAt:      time.Now().Format(time.RFC3339),
+		Status:      "failed",
+		Error:       errorMessage,
+		ErrorCode:   string(code),
+	}))
+}
+
+// countMessages adds to a batch's counts, in the database and, while the batch
+// runs, in its state. Every count change but skips goes through here; skips are
+// counted in the same transaction as the message, see SkipMessage.
+func (w *Worker) countMessages(batchID int64, sent, failed, validationFailed int) {
+	if err := w.batchRepo.AddCounts(batchID, sent, failed, validationFailed); err != nil {
+		slog.Error("Failed to update batch counts", "batch_id", batchID, "error", err)
+		return
 	}
 
-	w.broadcastEvent(batchID, &ProgressEvent{
-		Type:        "message_failed",
-		BatchID:     batchID,
-		Status:      status,
-		TotalCount:  totalCount,
-		SentCount:   sentCount,
-		FailedCount: failedCount,
-		LastMessage: &MessageInfo{
-			JID:         msg.JID,
-			ContactName: contactName,
-			SentAt:      time.Now().Format(time.RFC3339),
-			Status:      "failed",
-			Error:       errorMessage,
-		},
-	})
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if state := w.active[batchID]; state != nil {
+		state.counts.sent += sent
+		state.counts.failed += failed
+		state.counts.validationFailed += validationFailed
+	}
 }
 
-// scheduleNextMessage sets the time for the next message with random 10-15s delay.
-func (w *Worker) scheduleNextMessage() {
-	minDelay := 10 * time.Second
-	maxDelay := 15 * time.Second
-	delta := maxDelay - minDelay
+// messageEvent is the batch's progress as an event of eventType about one message.
+func (w *Worker) messageEvent(batchID int64, eventType string, last *MessageInfo) *ProgressEvent {
+	event, err := w.GetProgress(batchID)
+	if err != nil {
+		event = &ProgressEvent{BatchID: batchID}
+	}
+	event.Type = eventType
+	event.LastMessage = last
+	return event
+}
 
-	randomMs := rand.Int63n(int64(delta / time.Millisecond))
-	delay := minDelay + time.Duration(randomMs)*time.Millisecond
+// SkipMessage marks a pending message of a batch as skipped so it is never
+// sent. It returns false when the message is not pending any more.
+func (w *Worker) SkipMessage(batchID int64, msg *models.BatchMessage, skippedBy string) (bool, error) {
+	ok, err := w.msgRepo.Skip(batchID, msg.ID, skippedBy)
+	if err != nil || !ok {
+		return false, err
+	}
+	slog.Info("Message skipped", "batch_id", batchID, "jid", msg.JID, "skipped_by", skippedBy)
+	w.messageSkipped(batchID, msg, "Skipped by "+skippedBy, "")
+	return true, nil
+}
+
+// skipWithReason marks a pending message skipped by the worker itself, with
+// code and reason stored on the message.
+func (w *Worker) skipWithReason(batchID int64, msg *models.BatchMessage, code, reason string) (bool, error) {
+	ok, err := w.msgRepo.SkipWithReason(batchID, msg.ID, code, reason)
+	if err != nil || !ok {
+		return false, err
+	}
+	slog.Info("Message skipped", "batch_id", batchID, "jid", msg.JID, "reason", code)
+	w.messageSkipped(batchID, msg, reason, code)
+	w.broadcastProgress(batchID)
+	return true, nil
+}
+
+// messageSkipped counts a message just skipped in its batch's state and
+// records and broadcasts the skip; code is empty for skips made on request.
+func (w *Worker) messageSkipped(batchID int64, msg *models.BatchMessage, detail, code string) {
+	w.mu.Lock()
+	if state := w.active[batchID]; state != nil {
+		state.counts.skipped++
+	}
+	w.mu.Unlock()
+
+	w.recordEvent(batchID, models.BatchEventMessageSkipped, msg.JID, detail)
+
+	contactName := ""
+	if msg.ContactName != nil {
+		contactName = *msg.ContactName
+	}
+
+	info := &MessageInfo{
+		JID:         msg.JID,
+		ContactName: contactName,
+		SentAt:      time.Now().Format(time.RFC3339),
+		Status:      string(models.MessageStatusSkipped),
+	}
+	if code != "" {
+		info.Error = detail
+		info.ErrorCode = code
+	}
+	w.broadcastEvent(batchID, w.messageEvent(batchID, "message_skipped", info))
+}
+
+// sendContext bounds one send by the batch.send_timeout_seconds setting. It is
+// also cancelled when the batch leaves the running set or the worker shuts
+// down, so a send stuck on a slow connection does not hold up either.
+func (w *Worker) sendContext(state *ActiveBatchState, document bool) (context.Context, context.CancelFunc) {
+	seconds, err := w.settingsRepo.GetInt(models.SettingSendTimeoutSeconds, models.DefaultSendTimeoutSeconds)
+	if err != nil || seconds < 1 {
+		seconds = models.DefaultSendTimeoutSeconds
+	}
+	timeout := time.Duration(seconds) * time.Second
+	if document {
+		timeout += documentUploadTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(w.ctx, timeout)
+	go func() {
+		select {
+		case <-state.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// scheduleNextMessage sets the time for the next message with a random delay
+// from the batch's delay bounds, or the delay settings for a nil batch.
+func (w *Worker) scheduleNextMessage(state *ActiveBatchState) {
+	delay := w.randomDelay(state)
 
 	w.mu.Lock()
 	w.nextSendAt = time.Now().Add(delay)
 	w.mu.Unlock()
 
-	log.Printf("Next message in %.1f seconds", delay.Seconds())
+	slog.Debug("Next message scheduled", "delay", delay.Round(100*time.Millisecond))
 }
 
-func (w *Worker) completeBatch(batchID int64) {
-	log.Printf("Batch %d completed", batchID)
+// deferNextSend moves the next message to at, unless it is already due later.
+func (w *Worker) deferNextSend(at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if at.After(w.nextSendAt) {
+		w.nextSendAt = at
+		slog.Debug("Next message deferred", "until", at.Format(time.RFC3339))
+	}
+}
 
-	w.batchRepo.Complete(batchID)
+// randomDelay picks a delay between the bounds of delayRange, plus jitter.
+func (w *Worker) randomDelay(state *ActiveBatchState) time.Duration {
+	minDelay, maxDelay := w.delayRange(state)
+	delta := maxDelay - minDelay
+
+	delay := minDelay
+	if delta > 0 {
+		randomMs := rand.Int63n(int64(delta / time.Millisecond))
+		delay += time.Duration(randomMs) * time.Millisecond
+	}
+
+	// Extra jitter so even min == max never produces a perfectly regular cadence
+	if jitter := time.Duration(w.jitter.Load()); jitter >= time.Millisecond {
+		delay += time.Duration(rand.Int63n(int64(jitter/time.Millisecond))) * time.Millisecond
+	}
+	return delay
+}
+
+func (w *Worker) completeBatch(batchID int64) {
+	completed, err := w.batchRepo.Complete(batchID)
+	if err != nil {
+		slog.Error("Failed to complete batch", "batch_id", batchID, "error", err)
+	}
 
 	w.mu.Lock()
-	w.currentRun = nil
+	state := w.active[batchID]
+	var counts batchCounts
+	if state != nil {
+		counts = state.counts
+	}
+	w.removeActiveLocked(batchID)
 	w.mu.Unlock()
 
+	if !completed {
+		// A cancel got there first and has announced itself
+		if err == nil {
+			slog.Info("Batch was no longer running when it completed", "batch_id", batchID)
+		}
+		w.checkQueue()
+		return
+	}
+
+	slog.Info("Batch completed", "batch_id", batchID)
+	w.recordEvent(batchID, models.BatchEventCompleted, "", "")
+	if state != nil {
+		w.checkCounts(batchID, counts)
+	}
+
+	w.notifyFinished(batchID)
+
 	w.broadcastEvent(batchID, &ProgressEvent{
 		Type:    "completed",
 		BatchID: batchID,
@@ -357,18 +1026,77 @@ func (w *Worker) completeBatch(batchID int64) {
 	w.checkQueue()
 }
 
-func (w *Worker) CancelBatch(batchID int64) error {
+// checkCounts compares the counts a batch finished with in memory to the
+// stored ones, which should never differ since both change together in
+// countMessages and SkipMessage.
+func (w *Worker) checkCounts(batchID int64, counts batchCounts) {
+	run, err := w.batchRepo.GetByID(batchID)
+	if err != nil || run == nil {
+		return
+	}
+	stored := batchCounts{
+		total:            run.TotalCount,
+		sent:             run.SentCount,
+		failed:           run.FailedCount,
+		validationFailed: run.ValidationFailedCount,
+		skipped:          run.SkippedCount,
+	}
+	if stored != counts {
+		slog.Warn("Batch counts in memory differ from the database",
+			"batch_id", batchID, "memory", fmt.Sprintf("%+v", counts), "database", fmt.Sprintf("%+v", stored))
+	}
+}
+
+// cancelAttempts bounds how often CancelBatch retries a batch whose status
+// keeps changing under it.
+const cancelAttempts = 3
+
+// CancelBatch cancels a preparing, queued, running or interrupted batch; the
+// messages a preparing batch got so far are removed. It returns false
+// if the batch got to a final status first, e.g. completed while the cancel
+// was on its way; the caller reports the batch's status instead.
+func (w *Worker) CancelBatch(batchID int64) (bool, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if w.currentRun != nil && w.currentRun.BatchID == batchID {
-		w.currentRun = nil
-		log.Printf("Batch %d cancelled", batchID)
+	cancelled, wasPreparing := false, false
+	for range cancelAttempts {
+		run, err := w.batchRepo.GetByID(batchID)
+		if err != nil {
+			return false, err
+		}
+		if run == nil || !models.CanTransition(run.Status, models.BatchStatusCancelled) {
+			return false, nil
+		}
+
+		if run.Status == models.BatchStatusInterrupted {
+			// Interrupted batches are not in the running set; their counts are settled on cancel
+			cancelled, err = w.batchRepo.CancelInterrupted(batchID, string(FailureInterrupted))
+		} else {
+			cancelled, err = w.batchRepo.Transition(batchID, run.Status, models.BatchStatusCancelled)
+		}
+		if err != nil {
+			return false, err
+		}
+		if cancelled {
+			wasPreparing = run.Status == models.BatchStatusPreparing
+			break
+		}
+		// The status changed since it was read, e.g. the batch started; try from the new one
+	}
+	if !cancelled {
+		return false, nil
 	}
 
-	if err := w.batchRepo.Cancel(batchID); err != nil {
-		return err
+	if wasPreparing {
+		// Prepare adds nothing once the batch is no longer preparing
+		w.discardPrepared(batchID)
+		slog.Info("Batch cancelled while preparing", "batch_id", batchID)
 	}
+	if w.removeActiveLocked(batchID) {
+		slog.Info("Batch cancelled", "batch_id", batchID)
+	}
+	w.recordEvent(batchID, models.BatchEventCancelled, "", "")
 
 	go w.broadcastEvent(batchID, &ProgressEvent{
 		Type:    "cancelled",
@@ -378,44 +1106,169 @@ func (w *Worker) CancelBatch(batchID int64) error {
 
 	go w.checkQueue()
 
-	return nil
+	return true, nil
 }
 
+// GetProgress returns the progress of a batch. A running batch is served from
+// its state, so the per-tick broadcasts and every stream viewer cost no
+// database read; other batches are read from the database.
 func (w *Worker) GetProgress(batchID int64) (*ProgressEvent, error) {
-	run, err := w.batchRepo.GetByID(batchID)
-	if err != nil {
-		return nil, err
+	event := &ProgressEvent{Type: "progress", BatchID: batchID}
+
+	w.mu.RLock()
+	nextSend := w.nextSendAt
+	turnsBefore := 0
+	state := w.active[batchID]
+	if state != nil {
+		event.Status = string(models.BatchStatusRunning)
+		event.Label = state.label
+		event.TotalCount = state.counts.total
+		event.SentCount = state.counts.sent
+		event.FailedCount = state.counts.failed
+		event.ValidationFailedCount = state.counts.validationFailed
+		event.SkippedCount = state.counts.skipped
+		event.CurrentContact = state.CurrentName
+		turnsBefore = w.turnsBeforeLocked(batchID)
+	}
+	w.mu.RUnlock()
+
+	if state == nil {
+		run, err := w.batchRepo.GetByID(batchID)
+		if err != nil {
+			return nil, err
+		}
+		if run == nil {
+			return nil, fmt.Errorf("batch not found")
+		}
+		event.Status = string(run.Status)
+		event.Label = run.Label
+		event.TotalCount = run.TotalCount
+		event.SentCount = run.SentCount
+		event.FailedCount = run.FailedCount
+		event.ValidationFailedCount = run.ValidationFailedCount
+		event.SkippedCount = run.SkippedCount
+
+		if run.Status == models.BatchStatusPreparing {
+			w.mu.RLock()
+			event.PreparedCount = w.preparing[batchID]
+			w.mu.RUnlock()
+		}
 	}
-	if run == nil {
-		return nil, fmt.Errorf("batch not found")
+
+	if event.Status == string(models.BatchStatusRunning) {
+		nextSend = nextSend.Add(time.Duration(turnsBefore) * w.expectedGap(state))
+		event.NextSendInSeconds = max(int(time.Until(nextSend).Seconds()), 0)
+		if quiet, resumeAt := w.inQuietHours(time.Now()); quiet {
+			event.Status = StatusWaitingQuietHours
+			event.NextSendInSeconds = int(time.Until(resumeAt).Seconds())
+		}
+		if w.waClient.IsReadOnly() {
+			event.Status = StatusPausedReadOnly
+			event.NextSendInSeconds = 0
+		}
 	}
 
+	w.addRate(event)
+	return event, nil
+}
+
+// recordAttempt adds a send attempt to a running batch's throughput window.
+func (w *Worker) recordAttempt(batchID int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if state := w.active[batchID]; state != nil {
+		state.rate.record(time.Now())
+	}
+}
+
+// addRate fills the throughput and ETA of a progress event for a running batch.
+// The ETA spaces the remaining messages by the observed average gap, or before
+// any sends by the expected gap times the number of batches taking turns.
+func (w *Worker) addRate(event *ProgressEvent) {
 	w.mu.RLock()
-	nextSend := w.nextSendAt
-	currentName := ""
-	if w.currentRun != nil && w.currentRun.BatchID == batchID {
-		currentName = w.currentRun.CurrentName
+	state := w.active[event.BatchID]
+	var gap time.Duration
+	var turnsBefore, sharing int
+	if state != nil {
+		gap = state.rate.averageGap()
+		turnsBefore = w.turnsBeforeLocked(event.BatchID)
+		sharing = len(w.order)
 	}
+	nextSend := w.nextSendAt
 	w.mu.RUnlock()
 
-	nextSendSeconds := 0
-	if run.Status == models.BatchStatusRunning {
-		nextSendSeconds = int(time.Until(nextSend).Seconds())
-		if nextSendSeconds < 0 {
-			nextSendSeconds = 0
+	if state == nil {
+		return
+	}
+	event.MessagesPerMinute = perMinute(gap)
+
+	remaining := event.TotalCount - event.SentCount - event.FailedCount - event.SkippedCount
+	if remaining <= 0 {
+		return
+	}
+	expected := w.expectedGap(state)
+	if gap == 0 {
+		gap = expected * time.Duration(sharing)
+	}
+
+	// The first remaining message goes out at the scheduled time, or one gap
+	// from now if the schedule is already due, after the other batches' turns.
+	now := time.Now()
+	start := now.Add(gap)
+	if nextSend.After(now) {
+		start = nextSend.Add(time.Duration(turnsBefore) * expected)
+	}
+	if quiet, resumeAt := w.inQuietHours(now); quiet && resumeAt.After(start) {
+		start = resumeAt
+	}
+	eta := start.Add(time.Duration(remaining-1) * gap).Truncate(time.Second)
+	event.EstimatedCompletionAt = &eta
+}
+
+// EstimateQueueStarts estimates when each of the queued batches, given in queue
+// order, starts: as soon as a running batch reaches its ETA, or a batch ahead of
+// it in the queue finishes. Batches share one delay schedule, so a queued batch
+// takes its messages times the expected gap times batch.max_concurrent. Every
+// estimate is nil while no batch is running, since nothing moves the queue then.
+func (w *Worker) EstimateQueueStarts(queued []models.BatchRun) []*time.Time {
+	estimates := make([]*time.Time, len(queued))
+	active := w.GetActiveBatchIDs()
+	if len(active) == 0 {
+		return estimates
+	}
+
+	// Each slot is free from the time in it
+	now := time.Now()
+	slots := make([]time.Time, 0, len(active))
+	for _, id := range active {
+		free := now
+		if progress, err := w.GetProgress(id); err == nil && progress.EstimatedCompletionAt != nil {
+			free = *progress.EstimatedCompletionAt
 		}
+		slots = append(slots, free)
+	}
+	sharing := w.MaxConcurrent()
+	for len(slots) < sharing {
+		slots = append(slots, now)
 	}
 
-	return &ProgressEvent{
-		Type:              "progress",
-		BatchID:           batchID,
-		Status:            string(run.Status),
-		TotalCount:        run.TotalCount,
-		SentCount:         run.SentCount,
-		FailedCount:       run.FailedCount,
-		CurrentContact:    currentName,
-		NextSendInSeconds: nextSendSeconds,
-	}, nil
+	gap := w.expectedGap(nil) * time.Duration(sharing)
+	for i, run := range queued {
+		first := 0
+		for j := range slots {
+			if slots[j].Before(slots[first]) {
+				first = j
+			}
+		}
+		start := slots[first].Truncate(time.Second)
+		estimates[i] = &start
+
+		remaining := run.TotalCount - run.SentCount - run.FailedCount - run.SkippedCount
+		if remaining > 0 {
+			slots[first] = slots[first].Add(time.Duration(remaining) * gap)
+		}
+	}
+	return estimates
 }
 
 func (w *Worker) Subscribe(batchID int64) chan *ProgressEvent {
@@ -440,6 +1293,17 @@ func (w *Worker) Unsubscribe(batchID int64, ch chan *ProgressEvent) {
 			break
 		}
 	}
+	if len(w.subscribers[batchID]) == 0 {
+		delete(w.subscribers, batchID)
+	}
+}
+
+// SubscriberCount returns how many progress streams and long polls are
+// subscribed to a batch.
+func (w *Worker) SubscriberCount(batchID int64) int {
+	w.subscriberMutex.RLock()
+	defer w.subscriberMutex.RUnlock()
+	return len(w.subscribers[batchID])
 }
 
 func (w *Worker) broadcastProgress(batchID int64) {
@@ -451,11 +1315,18 @@ func (w *Worker) broadcastProgress(batchID int64) {
 }
 
 func (w *Worker) broadcastEvent(batchID int64, event *ProgressEvent) {
+	// Periodic progress ticks stay on the per-batch stream; the global
+	// stream only carries lifecycle changes.
+	if event.Type != "progress" {
+		w.hub.Publish(events.TopicBatch, event)
+	}
+
+	// Sends do not block, so the lock is held across them; Unsubscribe cannot
+	// close a channel while it is being sent on
 	w.subscriberMutex.RLock()
-	subs := w.subscribers[batchID]
-	w.subscriberMutex.RUnlock()
+	defer w.subscriberMutex.RUnlock()
 
-	for _, ch := range subs {
+	for _, ch := range w.subscribers[batchID] {
 		select {
 		case ch <- event:
 		default:
@@ -463,10 +1334,188 @@ func (w *Worker) broadcastEvent(batchID int64, event *ProgressEvent) {
 	}
 }
 
-func (w *Worker) getPlaceholderValues(jid string) (map[string]string, error) {
+// CachedValidations returns the still-trusted "registered on WhatsApp" results
+// ValidateRecipients stored for jids, without asking WhatsApp. JIDs never
+// checked, or checked too long ago, are absent.
+func (w *Worker) CachedValidations(jids []string) (map[string]bool, error) {
+	return w.validationRepo.GetFresh(jids, validationCacheTTL)
+}
+
+// ValidateRecipients checks the pending recipients of a batch against WhatsApp and
+// pre-fails messages to numbers that are no longer registered, so the send loop
+// skips them. Results are cached per JID for validationCacheTTL.
+// Returns the number of messages pre-failed.
+func (w *Worker) ValidateRecipients(batchID int64) (int, error) {
+	if !w.waClient.IsConnected() {
+		return 0, fmt.Errorf("whatsapp client not connected")
+	}
+
+	messages, err := w.msgRepo.GetByBatchRun(batchID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Only phone-number JIDs can be checked with IsOnWhatsApp
+	jids := []string{}
+	seen := make(map[string]bool)
+	for _, msg := range messages {
+		if msg.Status != models.MessageStatusPending || seen[msg.JID] {
+			continue
+		}
+		seen[msg.JID] = true
+		if strings.HasSuffix(msg.JID, "@s.whatsapp.net") {
+			jids = append(jids, msg.JID)
+		}
+	}
+
+	known, err := w.validationRepo.GetFresh(jids, validationCacheTTL)
+	if err != nil {
+		return 0, err
+	}
+
+	unchecked := []string{}
+	for _, jid := range jids {
+		if _, ok := known[jid]; !ok {
+			unchecked = append(unchecked, jid)
+		}
+	}
+
+	for start := 0; start < len(unchecked); start += validationChunkSize {
+		end := start + validationChunkSize
+		if end > len(unchecked) {
+			end = len(unchecked)
+		}
+
+		queries := make([]string, 0, end-start)
+		queryToJID := make(map[string]string, end-start)
+		for _, jid := range unchecked[start:end] {
+			query := "+" + extractPhone(jid)
+			queries = append(queries, query)
+			queryToJID[query] = jid
+		}
+
+		results, err := w.waClient.ValidatePhones(queries)
+		if err != nil {
+			return 0, err
+		}
+
+		fresh := make(map[string]bool, len(results))
+		for query, isIn := range results {
+			jid, ok := queryToJID[query]
+			if !ok {
+				jid, ok = queryToJID["+"+strings.TrimPrefix(query, "+")]
+			}
+			if ok {
+				fresh[jid] = isIn
+				known[jid] = isIn
+			}
+		}
+
+		if err := w.validationRepo.SetMultiple(fresh); err != nil {
+			slog.Error("Failed to cache validation results", "batch_id", batchID, "error", err)
+		}
+	}
+
+	invalidIDs := []int64{}
+	invalidJIDs := []string{}
+	for _, msg := range messages {
+		if msg.Status != models.MessageStatusPending {
+			continue
+		}
+		if onWhatsApp, ok := known[msg.JID]; ok && !onWhatsApp {
+			invalidIDs = append(invalidIDs, msg.ID)
+			invalidJIDs = append(invalidJIDs, msg.JID)
+		}
+	}
+
+	if len(invalidIDs) == 0 {
+		return 0, nil
+	}
+
+	updated, err := w.msgRepo.MarkFailedMultiple(invalidIDs, string(FailureNotOnWhatsApp), NotOnWhatsAppError)
+	if err != nil {
+		return 0, err
+	}
+	if updated > 0 {
+		w.countMessages(batchID, 0, updated, updated)
+		w.recordEvent(batchID, models.BatchEventMessageFailed, "",
+			fmt.Sprintf("%d recipients are %s", updated, NotOnWhatsAppError))
+		for _, jid := range invalidJIDs {
+			w.recordPermanentFailure(batchID, jid, NotOnWhatsAppError)
+		}
+	}
+
+	return updated, nil
+}
+
+// FailUnresolvedLIDs pre-fails the pending messages of a batch addressed to
+// hidden numbers (LIDs) with no known phone number, which cannot be validated
+// and may never arrive. Returns the number of messages pre-failed.
+func (w *Worker) FailUnresolvedLIDs(batchID int64) (int, error) {
+	messages, err := w.msgRepo.GetByBatchRun(batchID)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := []int64{}
+	for _, msg := range messages {
+		if msg.Status != models.MessageStatusPending || !whatsapp.IsLID(msg.JID) {
+			continue
+		}
+		pn, err := w.waClient.ResolveLID(msg.JID)
+		if err != nil {
+			return 0, err
+		}
+		if pn == "" {
+			ids = append(ids, msg.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	updated, err := w.msgRepo.MarkFailedMultiple(ids, string(FailureRecipientInvalid), UnresolvedLIDError)
+	if err != nil {
+		return 0, err
+	}
+	if updated > 0 {
+		w.countMessages(batchID, 0, updated, updated)
+		w.recordEvent(batchID, models.BatchEventMessageFailed, "",
+			fmt.Sprintf("%d recipients are hidden numbers (LIDs) with no known phone number", updated))
+	}
+
+	return updated, nil
+}
+
+// recordPermanentFailure counts a failure that will recur for this recipient and
+// quarantines it once the batch.quarantine_threshold setting is reached.
+func (w *Worker) recordPermanentFailure(batchID int64, jid, errorMessage string) {
+	threshold, err := w.settingsRepo.GetInt(models.SettingQuarantineThreshold, models.DefaultQuarantineThreshold)
+	if err != nil {
+		slog.Warn("Failed to read quarantine threshold, using default", "error", err)
+	}
+
+	quarantined, err := w.quarantineRepo.RecordFailure(jid, errorMessage, threshold)
+	if err != nil {
+		slog.Error("Failed to record permanent failure", "batch_id", batchID, "jid", jid, "error", err)
+		return
+	}
+	if quarantined {
+		slog.Warn("Contact quarantined after consecutive permanent failures", "batch_id", batchID, "jid", jid, "failures", threshold)
+		w.recordEvent(batchID, models.BatchEventQuarantined, jid,
+			fmt.Sprintf("%d consecutive permanent failures, last: %s", threshold, errorMessage))
+	}
+}
+
+// getPlaceholderValues merges the contact's WhatsApp fields, the placeholder
+// defaults of the batch's group and the contact's attributes, later ones winning.
+func (w *Worker) getPlaceholderValues(jid string, groupID int64) (map[string]string, error) {
 	var builtIn map[string]string
 	if w.waClient.IsConnected() {
-		contact, _ := w.waClient.FindContactByJID(jid)
+		ctx, cancel := context.WithTimeout(w.ctx, contactLookupTimeout)
+		contact, _ := w.waClient.FindContactByJID(ctx, jid)
+		cancel()
 		if contact != nil {
 			builtIn = template.GetBuiltInPlaceholders(contact)
 		}
@@ -475,27 +1524,245 @@ func (w *Worker) getPlaceholderValues(jid string) (map[string]string, error) {
 		builtIn = map[string]string{}
 	}
 
+	groupDefaults, err := w.groupAttrRepo.GetByGroupAsMap(groupID)
+	if err != nil {
+		return nil, err
+	}
+
 	custom, err := w.attrRepo.GetAllForContactAsMap(jid)
 	if err != nil {
 		return nil, err
 	}
 
-	return template.MergePlaceholders(builtIn, custom), nil
+	return template.MergePlaceholders(builtIn, groupDefaults, custom), nil
+}
+
+// recordEvent appends to the batch's audit log. Failures are logged only.
+func (w *Worker) recordEvent(batchID int64, eventType, jid, detail string) {
+	if err := w.eventRepo.Record(batchID, eventType, jid, detail); err != nil {
+		slog.Error("Failed to record batch event", "batch_id", batchID, "event", eventType, "error", err)
+	}
+}
+
+// setPaused updates the pause state of the running batch and records paused/resumed
+// events on transitions only. An empty reason means sending can proceed.
+// Returns true if the state changed.
+func (w *Worker) setPaused(state *ActiveBatchState, reason, detail string) bool {
+	w.mu.Lock()
+	previous := state.PauseReason
+	state.PauseReason = reason
+	if reason != "" {
+		state.rate.interrupt()
+	}
+	w.mu.Unlock()
+
+	if previous == reason {
+		return false
+	}
+	if reason == "" {
+		w.recordEvent(state.BatchID, models.BatchEventResumed, "", "Resumed after "+previous)
+	} else {
+		w.recordEvent(state.BatchID, models.BatchEventPaused, "", detail)
+	}
+	return true
+}
+
+// SetQuietHours configures the daily window during which batch sending pauses.
+// Pass nil to disable. Manual single sends are not affected.
+func (w *Worker) SetQuietHours(q *QuietHours) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.quietHours = q
+}
+
+// SetSendJitter changes the bound of the random delay added to every send,
+// maxSendJitter by default. Zero keeps exactly to the delay settings, which
+// end-to-end tests against the fake use to finish in seconds.
+func (w *Worker) SetSendJitter(d time.Duration) {
+	w.jitter.Store(int64(d))
+}
+
+// SetNotifier configures where completion summaries are sent. Pass nil to disable.
+func (w *Worker) SetNotifier(n *Notifier) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.notifier = n
+}
+
+// SetDocuments configures where per-recipient documents are read from. Pass
+// nil to disable documents; batches that need one then fail those messages.
+func (w *Worker) SetDocuments(d *Documents) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.documents = d
+}
+
+// DocumentsEnabled reports whether a documents directory is configured.
+func (w *Worker) DocumentsEnabled() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.documents != nil
+}
+
+// QuietHoursEnabled reports whether a quiet hours window is configured, from
+// settings or at startup.
+func (w *Worker) QuietHoursEnabled() bool {
+	return w.currentQuietHours() != nil
+}
+
+// CheckDocument reports why ref could not be sent as a document right now,
+// or nil if it could. Used for dry runs; the file is not read.
+func (w *Worker) CheckDocument(ref string) error {
+	documents, maxBytes, err := w.documentLimits()
+	if err != nil {
+		return err
+	}
+	return documents.Check(ref, maxBytes)
+}
+
+// loadDocument reads ref for sending.
+func (w *Worker) loadDocument(ref string) (*Document, error) {
+	documents, maxBytes, err := w.documentLimits()
+	if err != nil {
+		return nil, err
+	}
+	return documents.Load(ref, maxBytes)
+}
+
+func (w *Worker) documentLimits() (*Documents, int64, error) {
+	w.mu.RLock()
+	documents := w.documents
+	w.mu.RUnlock()
+	if documents == nil {
+		return nil, 0, ErrDocumentsDisabled
+	}
+
+	maxMB, err := w.settingsRepo.GetInt(models.SettingDocumentMaxSizeMB, models.DefaultDocumentMaxSizeMB)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read document size limit: %w", err)
+	}
+	return documents, int64(maxMB) << 20, nil
+}
+
+// notifyFinished sends the completion summary in the background so slow
+// targets never hold up the queue.
+func (w *Worker) notifyFinished(batchID int64) {
+	w.mu.RLock()
+	n := w.notifier
+	w.mu.RUnlock()
+
+	if n == nil {
+		return
+	}
+
+	run, err := w.batchRepo.GetByID(batchID)
+	if err != nil || run == nil {
+		slog.Error("Failed to load batch for notification", "batch_id", batchID, "error", err)
+		return
+	}
+
+	go n.Notify(run)
+}
+
+func (w *Worker) inQuietHours(now time.Time) (bool, time.Time) {
+	q := w.currentQuietHours()
+	if q == nil {
+		return false, time.Time{}
+	}
+	return q.Active(now)
+}
+
+// currentQuietHours returns the window from settings when one is saved,
+// otherwise the one configured through SetQuietHours.
+func (w *Worker) currentQuietHours() *QuietHours {
+	spec, err := w.settingsRepo.GetString(models.SettingQuietHours, "")
+	if err != nil {
+		slog.Warn("Failed to read quiet hours setting", "error", err)
+	}
+	tz, err := w.settingsRepo.GetString(models.SettingTimezone, "")
+	if err != nil {
+		slog.Warn("Failed to read timezone setting", "error", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if spec == "" {
+		return w.quietHours
+	}
+
+	key := spec + "|" + tz
+	if key != w.quietSpec {
+		q, err := ParseQuietHours(spec, tz)
+		if err != nil {
+			slog.Warn("Ignoring invalid quiet hours setting", "key", key, "error", err)
+		}
+		w.quietSpec = key
+		w.quietParsed = q
+	}
+	if w.quietParsed == nil {
+		return w.quietHours
+	}
+	return w.quietParsed
+}
+
+// delayRange returns the bounds of the random delay after a batch's messages,
+// or the delay settings for a nil batch.
+func (w *Worker) delayRange(state *ActiveBatchState) (time.Duration, time.Duration) {
+	if state != nil && state.MaxDelay > 0 {
+		return state.MinDelay, state.MaxDelay
+	}
+	minSec, maxSec := w.delaySettings()
+	return time.Duration(minSec) * time.Second, time.Duration(maxSec) * time.Second
+}
+
+// delaySettings returns the configured bounds of the random delay between messages, in seconds.
+func (w *Worker) delaySettings() (int, int) {
+	minSec, err := w.settingsRepo.GetInt(models.SettingBatchMinDelaySeconds, models.DefaultBatchMinDelaySeconds)
+	if err != nil {
+		slog.Warn("Failed to read delay settings, using defaults", "error", err)
+	}
+	maxSec, _ := w.settingsRepo.GetInt(models.SettingBatchMaxDelaySeconds, models.DefaultBatchMaxDelaySeconds)
+
+	if minSec < 1 {
+		minSec = 1
+	}
+	if maxSec < minSec {
+		maxSec = minSec
+	}
+	return minSec, maxSec
+}
+
+// expectedGap is the average time between two messages of a batch, or under
+// the delay settings for a nil batch.
+func (w *Worker) expectedGap(state *ActiveBatchState) time.Duration {
+	minDelay, maxDelay := w.delayRange(state)
+	return (minDelay+maxDelay)/2 + time.Duration(w.jitter.Load())/2
+}
+
+// MaxConcurrent returns how many batches may run at once (batch.max_concurrent).
+func (w *Worker) MaxConcurrent() int {
+	n, err := w.settingsRepo.GetInt(models.SettingBatchMaxConcurrent, models.DefaultBatchMaxConcurrent)
+	if err != nil {
+		slog.Warn("Failed to read concurrency setting, using default", "error", err)
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
 }
 
 func (w *Worker) IsActive() bool {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
-	return w.currentRun != nil
+	return len(w.active) > 0
 }
 
-func (w *Worker) GetActiveBatchID() int64 {
+// GetActiveBatchIDs returns the running batch IDs in the order they started.
+func (w *Worker) GetActiveBatchIDs() []int64 {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
-	if w.currentRun != nil {
-		return w.currentRun.BatchID
-	}
-	return 0
+	return append([]int64(nil), w.order...)
 }
 
 func extractPhone(jid string) string {
@@ -0,0 +1,473 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"friday/internal/database"
+	"friday/internal/events"
+	"friday/internal/models"
+	"friday/internal/template"
+	"friday/internal/whatsapp/whatsapptest"
+)
+
+const testOwnJID = "15550000000@s.whatsapp.net"
+
+// testEnv is a worker on a fresh database, sending through a fake WhatsApp client.
+type testEnv struct {
+	db           *database.DB
+	batchRepo    *models.BatchRunRepository
+	msgRepo      *models.BatchMessageRepository
+	eventRepo    *models.BatchEventRepository
+	draftRepo    *models.DraftRepository
+	groupRepo    *models.GroupRepository
+	memberRepo   *models.GroupMemberRepository
+	settingsRepo *models.SettingsRepository
+	fake         *whatsapptest.Fake
+	worker       *Worker
+
+	batches int // Batches queued so far, to keep draft and group names unique
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	db, err := database.New(filepath.Join(t.TempDir(), "friday.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	e := &testEnv{
+		db:           db,
+		batchRepo:    models.NewBatchRunRepository(db),
+		msgRepo:      models.NewBatchMessageRepository(db),
+		eventRepo:    models.NewBatchEventRepository(db),
+		draftRepo:    models.NewDraftRepository(db),
+		groupRepo:    models.NewGroupRepository(db),
+		memberRepo:   models.NewGroupMemberRepository(db),
+		settingsRepo: models.NewSettingsRepository(db),
+		fake:         whatsapptest.New(testOwnJID),
+	}
+	e.worker = e.newWorker(t)
+	return e
+}
+
+// newWorker builds a worker on the environment's database and fake, shut
+// down when the test ends.
+func (e *testEnv) newWorker(t *testing.T) *Worker {
+	t.Helper()
+	worker := NewWorker(
+		e.batchRepo,
+		e.msgRepo,
+		e.eventRepo,
+		e.memberRepo,
+		e.draftRepo,
+		models.NewAttributeRepository(e.db),
+		models.NewGroupAttributeRepository(e.db),
+		models.NewValidationRepository(e.db),
+		models.NewQuarantineRepository(e.db),
+		e.settingsRepo,
+		models.NewOutboxRepository(e.db),
+		e.fake,
+		events.NewHub(),
+	)
+	t.Cleanup(worker.Shutdown)
+	return worker
+}
+
+// restart shuts the worker down and starts a new one on the same database, as
+// a server restart does, up to where Run enters its send loop.
+func (e *testEnv) restart(t *testing.T) {
+	t.Helper()
+	e.worker.Shutdown()
+	e.worker = e.newWorker(t)
+	e.worker.resumeIncompleteRuns()
+}
+
+// queueBatch creates a draft with content and a group of phones, added to the
+// fake's address book, and queues a batch of the draft to the group the way
+// the batch handler does.
+func (e *testEnv) queueBatch(t *testing.T, content string, phones ...string) *models.BatchRun {
+	t.Helper()
+	e.batches++
+
+	draft := &models.MessageDraft{
+		Title:      fmt.Sprintf("Draft %d", e.batches),
+		Content:    content,
+		Delimiters: template.DefaultDelimiters,
+	}
+	if err := e.draftRepo.Create(draft); err != nil {
+		t.Fatalf("failed to create draft: %v", err)
+	}
+	group := &models.ContactGroup{Name: fmt.Sprintf("Group %d", e.batches)}
+	if err := e.groupRepo.Create(group); err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+
+	jids := make([]string, len(phones))
+	messages := make([]models.BatchMessage, len(phones))
+	recipients := make([]models.BatchRecipient, len(phones))
+	for i, phone := range phones {
+		jids[i] = e.fake.AddContact(phone, "Contact "+phone)
+		messages[i] = models.BatchMessage{JID: jids[i], Status: models.MessageStatusPending, TemplateContent: content}
+		recipients[i] = models.BatchRecipient{JID: jids[i], Status: models.RecipientIncluded}
+	}
+	if err := e.memberRepo.AddMultiple(group.ID, jids); err != nil {
+		t.Fatalf("failed to add group members: %v", err)
+	}
+
+	run := &models.BatchRun{
+		DraftID:    draft.ID,
+		GroupID:    group.ID,
+		GroupName:  group.Name,
+		DraftTitle: draft.Title,
+		Status:     models.BatchStatusQueued,
+		TotalCount: len(messages),
+		Delimiters: &draft.Delimiters,
+	}
+	if err := e.batchRepo.CreateWithMessages(run, messages, recipients); err != nil {
+		t.Fatalf("failed to create batch: %v", err)
+	}
+	return run
+}
+
+// tick runs one round of the send loop with the delay since the last send
+// already waited out.
+func (e *testEnv) tick() {
+	e.worker.mu.Lock()
+	e.worker.nextSendAt = time.Time{}
+	e.worker.mu.Unlock()
+	e.worker.processNextMessage()
+}
+
+// runUntilFinished ticks until the batch reaches a final status and returns it.
+func (e *testEnv) runUntilFinished(t *testing.T, batchID int64) *models.BatchRun {
+	t.Helper()
+	for range 100 {
+		e.tick()
+		run := e.getBatch(t, batchID)
+		if run.Status.IsFinal() {
+			return run
+		}
+	}
+	t.Fatalf("batch %d did not finish", batchID)
+	return nil
+}
+
+func (e *testEnv) getBatch(t *testing.T, batchID int64) *models.BatchRun {
+	t.Helper()
+	run, err := e.batchRepo.GetByID(batchID)
+	if err != nil || run == nil {
+		t.Fatalf("failed to get batch %d: %v", batchID, err)
+	}
+	return run
+}
+
+func (e *testEnv) messages(t *testing.T, batchID int64) []models.BatchMessage {
+	t.Helper()
+	messages, err := e.msgRepo.GetByBatchRun(batchID)
+	if err != nil {
+		t.Fatalf("failed to get batch messages: %v", err)
+	}
+	return messages
+}
+
+// countEvents counts the batch's audit log entries of eventType.
+func (e *testEnv) countEvents(t *testing.T, batchID int64, eventType string) int {
+	t.Helper()
+	logged, err := e.eventRepo.GetByBatch(batchID, 0)
+	if err != nil {
+		t.Fatalf("failed to get batch events: %v", err)
+	}
+	count := 0
+	for _, event := range logged {
+		if event.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+func TestWorkerSendsBatch(t *testing.T) {
+	e := newTestEnv(t)
+	run := e.queueBatch(t, "Hello {{name}}", "15550000001", "15550000002", "15550000003")
+
+	finished := e.runUntilFinished(t, run.ID)
+
+	if finished.Status != models.BatchStatusCompleted {
+		t.Fatalf("status = %q, want %q", finished.Status, models.BatchStatusCompleted)
+	}
+	if finished.SentCount != 3 || finished.FailedCount != 0 {
+		t.Errorf("sent, failed = %d, %d; want 3, 0", finished.SentCount, finished.FailedCount)
+	}
+
+	sent := e.fake.Sent()
+	if len(sent) != 3 {
+		t.Fatalf("fake got %d messages, want 3", len(sent))
+	}
+	for i, phone := range []string{"15550000001", "15550000002", "15550000003"} {
+		if want := "Hello Contact " + phone; sent[i].Message != want {
+			t.Errorf("message %d = %q, want %q", i, sent[i].Message, want)
+		}
+	}
+	for _, msg := range e.messages(t, run.ID) {
+		if msg.Status != models.MessageStatusSent || msg.WAMessageID == nil {
+			t.Errorf("message to %s: status %q, WhatsApp ID %v; want sent with an ID", msg.JID, msg.Status, msg.WAMessageID)
+		}
+	}
+	if got := e.countEvents(t, run.ID, models.BatchEventCompleted); got != 1 {
+		t.Errorf("%d completed events, want 1", got)
+	}
+}
+
+func TestWorkerRecordsSendFailure(t *testing.T) {
+	e := newTestEnv(t)
+	run := e.queueBatch(t, "Hello", "15550000001", "15550000002", "15550000003")
+	e.fake.FailNext("15550000002@s.whatsapp.net", errors.New("server hiccup"))
+
+	finished := e.runUntilFinished(t, run.ID)
+
+	if finished.Status != models.BatchStatusCompleted {
+		t.Fatalf("status = %q, want %q", finished.Status, models.BatchStatusCompleted)
+	}
+	if finished.SentCount != 2 || finished.FailedCount != 1 {
+		t.Errorf("sent, failed = %d, %d; want 2, 1", finished.SentCount, finished.FailedCount)
+	}
+	if attempts := e.fake.Attempts(); attempts != 3 {
+		t.Errorf("%d send attempts, want 3; a failure is not retried", attempts)
+	}
+
+	for _, msg := range e.messages(t, run.ID) {
+		if msg.JID != "15550000002@s.whatsapp.net" {
+			continue
+		}
+		if msg.Status != models.MessageStatusFailed {
+			t.Fatalf("failed message has status %q", msg.Status)
+		}
+		if msg.ErrorCode == nil || *msg.ErrorCode != string(FailureUnknown) {
+			t.Errorf("error code = %v, want %q", msg.ErrorCode, FailureUnknown)
+		}
+		if msg.ErrorMessage == nil || *msg.ErrorMessage != "Send failed: server hiccup" {
+			t.Errorf("error message = %v", msg.ErrorMessage)
+		}
+	}
+}
+
+func TestWorkerPausesWhileDisconnected(t *testing.T) {
+	e := newTestEnv(t)
+	run := e.queueBatch(t, "Hello", "15550000001", "15550000002", "15550000003")
+
+	// The first tick starts the batch, the second sends its first message
+	e.tick()
+	e.tick()
+	if sent := len(e.fake.Sent()); sent != 1 {
+		t.Fatalf("%d messages sent before the disconnect, want 1", sent)
+	}
+
+	e.fake.SetConnected(false)
+	for range 5 {
+		e.tick()
+	}
+	if attempts := e.fake.Attempts(); attempts != 1 {
+		t.Errorf("%d send attempts while disconnected, want none after the first", attempts)
+	}
+	progress, err := e.worker.GetProgress(run.ID)
+	if err != nil {
+		t.Fatalf("failed to get progress: %v", err)
+	}
+	if progress.Status != string(models.BatchStatusRunning) {
+		t.Errorf("status while disconnected = %q, want %q", progress.Status, models.BatchStatusRunning)
+	}
+	if got := e.countEvents(t, run.ID, models.BatchEventPaused); got != 1 {
+		t.Errorf("%d paused events, want 1 however long the disconnect", got)
+	}
+
+	e.fake.SetConnected(true)
+	finished := e.runUntilFinished(t, run.ID)
+
+	if finished.Status != models.BatchStatusCompleted || finished.SentCount != 3 {
+		t.Errorf("after reconnecting: status %q with %d sent, want completed with 3", finished.Status, finished.SentCount)
+	}
+	if got := e.countEvents(t, run.ID, models.BatchEventResumed); got != 1 {
+		t.Errorf("%d resumed events, want 1", got)
+	}
+}
+
+func TestWorkerCancelBatch(t *testing.T) {
+	e := newTestEnv(t)
+	run := e.queueBatch(t, "Hello", "15550000001", "15550000002", "15550000003")
+
+	e.tick()
+	e.tick()
+
+	cancelled, err := e.worker.CancelBatch(run.ID)
+	if err != nil || !cancelled {
+		t.Fatalf("CancelBatch = %v, %v; want true", cancelled, err)
+	}
+	for range 5 {
+		e.tick()
+	}
+
+	if attempts := e.fake.Attempts(); attempts != 1 {
+		t.Errorf("%d send attempts, want none after the cancel", attempts)
+	}
+	if ids := e.worker.GetActiveBatchIDs(); len(ids) != 0 {
+		t.Errorf("running batches after the cancel: %v", ids)
+	}
+
+	finished := e.getBatch(t, run.ID)
+	if finished.Status != models.BatchStatusCancelled {
+		t.Errorf("status = %q, want %q", finished.Status, models.BatchStatusCancelled)
+	}
+	pending := 0
+	for _, msg := range e.messages(t, run.ID) {
+		if msg.Status == models.MessageStatusPending {
+			pending++
+		}
+	}
+	if pending != 2 {
+		t.Errorf("%d messages left pending, want 2", pending)
+	}
+
+	if cancelled, err := e.worker.CancelBatch(run.ID); err != nil || cancelled {
+		t.Errorf("second CancelBatch = %v, %v; want false", cancelled, err)
+	}
+}
+
+// TestWorkerSendsWithoutDraft runs a batch whose draft is gone, as a batch from
+// before runs recorded their delimiters can find it: the messages go out from
+// their snapshots with the default delimiters.
+func TestWorkerSendsWithoutDraft(t *testing.T) {
+	e := newTestEnv(t)
+	run := e.queueBatch(t, "Hello {{name}}", "15550000001", "15550000002")
+
+	// Batch runs keep their draft, so only a database written with foreign keys off lacks it
+	ctx := context.Background()
+	conn, err := e.db.Conn().Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+	for _, statement := range []string{
+		"PRAGMA foreign_keys = OFF",
+		fmt.Sprintf("UPDATE batch_runs SET placeholder_open = NULL, placeholder_close = NULL WHERE id = %d", run.ID),
+		fmt.Sprintf("DELETE FROM message_drafts WHERE id = %d", run.DraftID),
+		"PRAGMA foreign_keys = ON",
+	} {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	conn.Close()
+	if draft, err := e.draftRepo.GetByID(run.DraftID); err != nil || draft != nil {
+		t.Fatalf("draft still there: %v, %v", draft, err)
+	}
+
+	finished := e.runUntilFinished(t, run.ID)
+
+	if finished.Status != models.BatchStatusCompleted || finished.SentCount != 2 {
+		t.Fatalf("status %q with %d sent, want completed with 2", finished.Status, finished.SentCount)
+	}
+	for i, msg := range e.fake.Sent() {
+		if want := fmt.Sprintf("Hello Contact 1555000000%d", i+1); msg.Message != want {
+			t.Errorf("message %d = %q, want %q", i, msg.Message, want)
+		}
+	}
+}
+
+// TestWorkerFailsBatchWithoutMessages starts a batch whose messages were lost
+// to an interrupted creation; it fails rather than staying queued for good.
+func TestWorkerFailsBatchWithoutMessages(t *testing.T) {
+	e := newTestEnv(t)
+	run := e.queueBatch(t, "Hello", "15550000001", "15550000002")
+	if _, err := e.db.Conn().Exec("DELETE FROM batch_messages WHERE batch_run_id = ?", run.ID); err != nil {
+		t.Fatalf("failed to delete batch messages: %v", err)
+	}
+
+	e.tick()
+	failed := e.getBatch(t, run.ID)
+	if failed.Status != models.BatchStatusFailed {
+		t.Fatalf("status = %q, want %q", failed.Status, models.BatchStatusFailed)
+	}
+	if failed.ErrorMessage == nil || *failed.ErrorMessage != "Batch has no messages; its creation was interrupted" {
+		t.Errorf("error message = %v, want the interrupted creation", failed.ErrorMessage)
+	}
+	if n := e.countEvents(t, run.ID, models.BatchEventFailed); n != 1 {
+		t.Errorf("%d failed events, want 1", n)
+	}
+	if attempts := e.fake.Attempts(); attempts != 0 {
+		t.Errorf("fake got %d send attempts, want 0", attempts)
+	}
+	if active := e.worker.GetActiveBatchIDs(); len(active) != 0 {
+		t.Errorf("active batches = %v, want none", active)
+	}
+}
+
+// TestProgressMatchesDatabase runs a batch with every kind of outcome and
+// checks after each step that the counts served from memory are the ones
+// stored, up to the moment the batch completes.
+func TestProgressMatchesDatabase(t *testing.T) {
+	e := newTestEnv(t)
+	run := e.queueBatch(t, "Hello", "15550000001", "15550000002", "15550000003", "15550000004", "15550000005")
+	e.fake.FailNext("15550000002@s.whatsapp.net", errors.New("server hiccup"))
+	// A hidden number nobody can resolve is failed as the batch starts
+	if _, err := e.db.Conn().Exec("UPDATE batch_messages SET jid = '100000000000005@lid' WHERE batch_run_id = ? AND jid = '15550000005@s.whatsapp.net'", run.ID); err != nil {
+		t.Fatalf("failed to address a message to a LID: %v", err)
+	}
+
+	var last *ProgressEvent
+	compare := func(step string) {
+		t.Helper()
+		if !slices.Contains(e.worker.GetActiveBatchIDs(), run.ID) {
+			return
+		}
+		progress, err := e.worker.GetProgress(run.ID)
+		if err != nil {
+			t.Fatalf("%s: failed to get progress: %v", step, err)
+		}
+		stored := e.getBatch(t, run.ID)
+		memory := [5]int{progress.TotalCount, progress.SentCount, progress.FailedCount, progress.ValidationFailedCount, progress.SkippedCount}
+		database := [5]int{stored.TotalCount, stored.SentCount, stored.FailedCount, stored.ValidationFailedCount, stored.SkippedCount}
+		if memory != database {
+			t.Errorf("%s: total, sent, failed, validation failed, skipped = %v in memory, %v stored", step, memory, database)
+		}
+		last = progress
+	}
+
+	e.tick()
+	compare("start")
+	for _, msg := range e.messages(t, run.ID) {
+		if msg.JID == "15550000004@s.whatsapp.net" {
+			if ok, err := e.worker.SkipMessage(run.ID, &msg, "test"); err != nil || !ok {
+				t.Fatalf("SkipMessage = %t, %v", ok, err)
+			}
+		}
+	}
+	compare("after the skip")
+
+	for i := 0; !e.getBatch(t, run.ID).Status.IsFinal(); i++ {
+		if i == 100 {
+			t.Fatal("batch did not finish")
+		}
+		e.tick()
+		compare(fmt.Sprintf("tick %d", i+1))
+	}
+
+	finished := e.getBatch(t, run.ID)
+	if finished.Status != models.BatchStatusCompleted {
+		t.Fatalf("status = %q, want %q", finished.Status, models.BatchStatusCompleted)
+	}
+	if finished.SentCount != 2 || finished.FailedCount != 2 || finished.ValidationFailedCount != 1 || finished.SkippedCount != 1 {
+		t.Errorf("sent, failed, validation failed, skipped = %d, %d, %d, %d; want 2, 2, 1, 1",
+			finished.SentCount, finished.FailedCount, finished.ValidationFailedCount, finished.SkippedCount)
+	}
+	// The last snapshot taken while the batch ran already held the final counts
+	if last == nil || last.SentCount != finished.SentCount || last.FailedCount != finished.FailedCount || last.SkippedCount != finished.SkippedCount {
+		t.Errorf("last in-memory progress %+v, want the final counts", last)
+	}
+}
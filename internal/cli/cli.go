@@ -0,0 +1,378 @@
+// Package cli implements the friday command-line subcommands as a thin HTTP
+// client against a running server.
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK          = 0
+	ExitAPIError    = 1 // The server answered with an error
+	ExitUsage       = 2 // Bad arguments
+	ExitUnreachable = 3 // The server could not be reached
+)
+
+// Environment variables read by the CLI.
+const (
+	EnvURL   = "FRIDAY_URL"   // Server base URL, default http://localhost:8080
+	EnvToken = "FRIDAY_TOKEN" // Sent as a bearer token when set
+)
+
+const defaultBaseURL = "http://localhost:8080"
+
+const usage = `Usage: friday <command> [flags]
+
+Commands:
+  serve                                  Run the server (default)
+  send --to <phone|jid> --message <text> Send a WhatsApp message
+  drafts list                            List message drafts
+  batch status <id>                      Show a batch run's progress
+  contacts search <query>                Search WhatsApp contacts
+
+Flags:
+  --json    Print the raw API response instead of a table
+
+Environment:
+  FRIDAY_URL    Server base URL (default http://localhost:8080)
+  FRIDAY_TOKEN  API token, sent as "Authorization: Bearer <token>"
+`
+
+// usageError marks errors caused by bad arguments.
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+// apiError is returned when the server responds with success=false or a non-2xx status.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.message, e.status)
+}
+
+// client talks to a running friday server.
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+	stdout  io.Writer
+	json    bool
+}
+
+// Run executes a subcommand and returns the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stderr, usage)
+		return ExitUsage
+	}
+
+	baseURL := os.Getenv(EnvURL)
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	c := &client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   os.Getenv(EnvToken),
+		http:    &http.Client{Timeout: 60 * time.Second},
+		stdout:  stdout,
+	}
+
+	var err error
+	switch args[0] {
+	case "send":
+		err = c.send(args[1:])
+	case "drafts":
+		err = c.drafts(args[1:])
+	case "batch":
+		err = c.batch(args[1:])
+	case "contacts":
+		err = c.contacts(args[1:])
+	default:
+		err = &usageError{fmt.Sprintf("unknown command %q", args[0])}
+	}
+
+	if err == nil {
+		return ExitOK
+	}
+
+	fmt.Fprintf(stderr, "friday: %v\n", err)
+	var uerr *usageError
+	var aerr *apiError
+	switch {
+	case errors.As(err, &uerr):
+		fmt.Fprint(stderr, "\n"+usage)
+		return ExitUsage
+	case errors.As(err, &aerr):
+		return ExitAPIError
+	default:
+		return ExitUnreachable
+	}
+}
+
+// flags returns a flag set with the shared --json flag bound to the client.
+func (c *client) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&c.json, "json", false, "print the raw API response")
+	return fs
+}
+
+// parse parses flags anywhere in args and returns the positional arguments.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, &usageError{err.Error()}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// do sends a request and decodes the JSON response into out, or prints the raw
+// response when --json is set. Error responses become *apiError.
+func (c *client) do(method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot reach server at %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var envelope struct {
+		Success *bool  `json:"success"`
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	jsonErr := json.Unmarshal(data, &envelope)
+
+	if resp.StatusCode >= 300 || (envelope.Success != nil && !*envelope.Success) {
+		message := envelope.Message
+		if message == "" {
+			message = envelope.Error
+		}
+		if message == "" || jsonErr != nil {
+			message = strings.TrimSpace(string(data))
+		}
+		if message == "" {
+			message = http.StatusText(resp.StatusCode)
+		}
+		return &apiError{status: resp.StatusCode, message: message}
+	}
+	if jsonErr != nil {
+		return &apiError{status: resp.StatusCode, message: "server returned a non-JSON response"}
+	}
+
+	if c.json {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, data, "", "  "); err != nil {
+			return err
+		}
+		fmt.Fprintln(c.stdout, pretty.String())
+		return nil
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func (c *client) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+}
+
+// send handles: friday send --to <recipient> --message <text>
+func (c *client) send(args []string) error {
+	fs := c.flags("send")
+	to := fs.String("to", "", "phone number or JID")
+	message := fs.String("message", "", "message text")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	if *to == "" || *message == "" {
+		return &usageError{"send requires --to and --message"}
+	}
+
+	var resp struct {
+		Message string `json:"message"`
+		ID      string `json:"id"`
+	}
+	req := map[string]string{"recipient": *to, "message": *message}
+	if err := c.do(http.MethodPost, "/api/whatsapp/send", req, &resp); err != nil || c.json {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "%s (id %s)\n", resp.Message, resp.ID)
+	return nil
+}
+
+// drafts handles: friday drafts list
+func (c *client) drafts(args []string) error {
+	fs := c.flags("drafts")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || positional[0] != "list" {
+		return &usageError{"usage: friday drafts list"}
+	}
+
+	var resp struct {
+		Drafts []struct {
+			ID        int64     `json:"id"`
+			Title     string    `json:"title"`
+			Content   string    `json:"content"`
+			UpdatedAt time.Time `json:"updated_at"`
+			TimesUsed int       `json:"times_used"`
+		} `json:"drafts"`
+	}
+	if err := c.do(http.MethodGet, "/api/drafts", nil, &resp); err != nil || c.json {
+		return err
+	}
+
+	tw := c.table()
+	fmt.Fprintln(tw, "ID\tTITLE\tUPDATED\tUSED\tCONTENT")
+	for _, d := range resp.Drafts {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\n", d.ID, d.Title, d.UpdatedAt.Local().Format("2006-01-02 15:04"), d.TimesUsed, truncate(d.Content, 50))
+	}
+	return tw.Flush()
+}
+
+// batch handles: friday batch status <id>
+func (c *client) batch(args []string) error {
+	fs := c.flags("batch")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 || positional[0] != "status" {
+		return &usageError{"usage: friday batch status <id>"}
+	}
+
+	var resp struct {
+		Batch struct {
+			ID                    int64      `json:"id"`
+			DraftTitle            string     `json:"draft_title"`
+			GroupName             string     `json:"group_name"`
+			Status                string     `json:"status"`
+			TotalCount            int        `json:"total_count"`
+			SentCount             int        `json:"sent_count"`
+			FailedCount           int        `json:"failed_count"`
+			ValidationFailedCount int        `json:"validation_failed_count"`
+			SkippedCount          int        `json:"skipped_count"`
+			ErrorMessage          *string    `json:"error_message"`
+			StartedAt             *time.Time `json:"started_at"`
+			CompletedAt           *time.Time `json:"completed_at"`
+		} `json:"batch"`
+	}
+	path := "/api/batch-runs/" + url.PathEscape(positional[1])
+	if err := c.do(http.MethodGet, path, nil, &resp); err != nil || c.json {
+		return err
+	}
+
+	b := resp.Batch
+	tw := c.table()
+	fmt.Fprintf(tw, "Batch\t#%d\n", b.ID)
+	fmt.Fprintf(tw, "Status\t%s\n", b.Status)
+	fmt.Fprintf(tw, "Draft\t%s\n", b.DraftTitle)
+	fmt.Fprintf(tw, "Group\t%s\n", b.GroupName)
+	fmt.Fprintf(tw, "Progress\t%d/%d sent, %d failed\n", b.SentCount, b.TotalCount, b.FailedCount)
+	if b.ValidationFailedCount > 0 {
+		fmt.Fprintf(tw, "Not on WhatsApp\t%d\n", b.ValidationFailedCount)
+	}
+	if b.SkippedCount > 0 {
+		fmt.Fprintf(tw, "Skipped\t%d\n", b.SkippedCount)
+	}
+	if b.StartedAt != nil {
+		fmt.Fprintf(tw, "Started\t%s\n", b.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if b.CompletedAt != nil {
+		fmt.Fprintf(tw, "Completed\t%s\n", b.CompletedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if b.ErrorMessage != nil {
+		fmt.Fprintf(tw, "Error\t%s\n", *b.ErrorMessage)
+	}
+	return tw.Flush()
+}
+
+// contacts handles: friday contacts search <query>
+func (c *client) contacts(args []string) error {
+	fs := c.flags("contacts")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 2 || positional[0] != "search" {
+		return &usageError{"usage: friday contacts search <query>"}
+	}
+
+	var resp struct {
+		Contacts []struct {
+			JID   string `json:"jid"`
+			Phone string `json:"phone"`
+			Name  string `json:"name"`
+		} `json:"contacts"`
+	}
+	query := strings.Join(positional[1:], " ")
+	if err := c.do(http.MethodGet, "/api/contacts/search?q="+url.QueryEscape(query), nil, &resp); err != nil || c.json {
+		return err
+	}
+
+	tw := c.table()
+	fmt.Fprintln(tw, "NAME\tPHONE\tJID")
+	for _, contact := range resp.Contacts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", contact.Name, contact.Phone, contact.JID)
+	}
+	return tw.Flush()
+}
+
+// truncate shortens s to n runes on a single line.
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
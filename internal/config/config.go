@@ -0,0 +1,204 @@
+// Package config reads the optional FRIDAY_CONFIG_FILE, so the part of the
+// configuration that can change while the server runs can be re-read on SIGHUP
+// instead of restarting and interrupting batches.
+//
+// The file holds FRIDAY_* variables as KEY=VALUE lines; blank lines and lines
+// starting with # are ignored, and a value may be wrapped in double quotes. A
+// variable already set in the process environment takes precedence over the
+// file and keeps its value across reloads.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// EnvFile names the configuration file.
+const EnvFile = "FRIDAY_CONFIG_FILE"
+
+// Reloadable lists the variables a reload applies. The others, such as
+// FRIDAY_TIMEZONE, FRIDAY_DOCUMENTS_DIR or FRIDAY_CORS_ORIGINS, are read once
+// at startup. Settings saved through /api/settings apply without either.
+var Reloadable = []string{
+	"FRIDAY_LOG_LEVEL",
+	"FRIDAY_QUIET_HOURS",
+	"FRIDAY_RATE_LIMIT_RPS",
+	"FRIDAY_RATE_LIMIT_BURST",
+}
+
+// Change is a reloadable variable whose value a reload changes.
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+// Pending is the configuration file as Read found it, not yet applied.
+type Pending struct {
+	Values  map[string]string // Every reloadable variable as it is once applied; empty means its default
+	Changes []Change          // The reloadable variables whose value differs from now, sorted by key
+	Ignored []string          // Variables changed in the file that are only read at startup, sorted
+	file    map[string]string
+}
+
+// Loader reads the configuration file at startup and on every reload.
+type Loader struct {
+	path string
+
+	mu    sync.Mutex
+	fixed map[string]bool   // Set in the process environment before the file was read
+	file  map[string]string // The file as last applied
+}
+
+// Load reads FRIDAY_CONFIG_FILE, when set, into the environment, leaving
+// variables the environment already sets alone.
+func Load() (*Loader, error) {
+	l := &Loader{path: os.Getenv(EnvFile), fixed: make(map[string]bool), file: make(map[string]string)}
+	if l.path == "" {
+		return l, nil
+	}
+
+	file, err := readFile(l.path)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range file {
+		if _, ok := os.LookupEnv(key); ok {
+			l.fixed[key] = true
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	// Reloadable variables the environment sets but the file does not are fixed too
+	for _, key := range Reloadable {
+		if _, ok := file[key]; !ok {
+			if _, ok := os.LookupEnv(key); ok {
+				l.fixed[key] = true
+			}
+		}
+	}
+	l.file = file
+	return l, nil
+}
+
+// Path returns the configuration file, or "" when FRIDAY_CONFIG_FILE is unset.
+func (l *Loader) Path() string {
+	return l.path
+}
+
+// Read re-reads the configuration file and works out what applying it would
+// change. Without a file the reloadable variables keep their values.
+func (l *Loader) Read() (*Pending, error) {
+	file := make(map[string]string)
+	if l.path != "" {
+		var err error
+		if file, err = readFile(l.path); err != nil {
+			return nil, err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	pending := &Pending{Values: make(map[string]string, len(Reloadable)), file: file}
+	for _, key := range Reloadable {
+		current := os.Getenv(key)
+		value := current
+		if l.path != "" && !l.fixed[key] {
+			value = file[key]
+		}
+		pending.Values[key] = value
+		if value != current {
+			pending.Changes = append(pending.Changes, Change{Key: key, Old: current, New: value})
+		}
+	}
+	for key := range keys(file, l.file) {
+		if !slices.Contains(Reloadable, key) && !l.fixed[key] && file[key] != l.file[key] {
+			pending.Ignored = append(pending.Ignored, key)
+		}
+	}
+	slices.Sort(pending.Ignored)
+	return pending, nil
+}
+
+// Apply sets the reloadable variables in the environment to the values Read
+// found. The components using them are updated by the caller.
+func (l *Loader) Apply(pending *Pending) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, change := range pending.Changes {
+		var err error
+		if change.New == "" {
+			err = os.Unsetenv(change.Key)
+		} else {
+			err = os.Setenv(change.Key, change.New)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %w", change.Key, err)
+		}
+	}
+	// Startup-only variables keep the value they were read with, so a change
+	// to one is reported on every reload until the server is restarted
+	for _, key := range Reloadable {
+		if value, ok := pending.file[key]; ok {
+			l.file[key] = value
+		} else {
+			delete(l.file, key)
+		}
+	}
+	return nil
+}
+
+// readFile parses a configuration file into its variables.
+func readFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !strings.HasPrefix(key, "FRIDAY_") {
+			return nil, fmt.Errorf("%s:%d: want FRIDAY_NAME=value", path, n)
+		}
+		if key == EnvFile {
+			return nil, fmt.Errorf("%s:%d: %s cannot be set in the config file", path, n, EnvFile)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return values, nil
+}
+
+// keys returns the set of keys in either map.
+func keys(a, b map[string]string) map[string]bool {
+	set := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		set[key] = true
+	}
+	for key := range b {
+		set[key] = true
+	}
+	return set
+}
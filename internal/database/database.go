@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"sync"
 
+	"friday/internal/lockfile"
+
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -12,23 +14,47 @@ import (
 type DB struct {
 	conn *sql.DB
 	mu   sync.RWMutex
+	lock *lockfile.Lock
 }
 
+// New opens the database, holding dbPath+".lock" so a second instance cannot
+// share the file. The lock is released by Close.
 func New(dbPath string) (*DB, error) {
+	lock, err := lockfile.Acquire(dbPath + ".lock")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
 	if err != nil {
+		lock.Release()
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	if err := conn.Ping(); err != nil {
 		conn.Close()
+		lock.Release()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	db := &DB{conn: conn}
+	// Deletes rely on the schema's foreign keys to cascade or refuse, see relations
+	var foreignKeys bool
+	if err := conn.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		conn.Close()
+		lock.Release()
+		return nil, fmt.Errorf("failed to check foreign key enforcement: %w", err)
+	}
+	if !foreignKeys {
+		conn.Close()
+		lock.Release()
+		return nil, fmt.Errorf("SQLite has foreign key enforcement off")
+	}
+
+	db := &DB{conn: conn, lock: lock}
 
 	if err := db.migrate(); err != nil {
 		conn.Close()
+		lock.Release()
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
@@ -58,13 +84,19 @@ func (db *DB) migrate() error {
 		`CREATE INDEX IF NOT EXISTS idx_attrs_jid ON contact_attributes(jid)`,
 		`CREATE INDEX IF NOT EXISTS idx_attrs_key ON contact_attributes(key)`,
 
-		`CREATE TABLE IF NOT EXISTS contact_groups (
+		`CREATE TABLE IF NOT EXISTS attribute_history (
 			id          INTEGER PRIMARY KEY AUTOINCREMENT,
-			name        TEXT NOT NULL UNIQUE,
-			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+			jid         TEXT NOT NULL,
+			key         TEXT NOT NULL,
+			action      TEXT NOT NULL,
+			old_value   TEXT,
+			new_value   TEXT,
+			source      TEXT NOT NULL,
+			changed_at  DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
-		`CREATE INDEX IF NOT EXISTS idx_groups_name ON contact_groups(name)`,
+		`CREATE INDEX IF NOT EXISTS idx_attr_history_jid ON attribute_history(jid, id)`,
+
+		`CREATE TABLE IF NOT EXISTS contact_groups ` + contactGroupsColumns,
 
 		`CREATE TABLE IF NOT EXISTS group_members (
 			id         INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -76,6 +108,7 @@ func (db *DB) migrate() error {
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_members_group ON group_members(group_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_members_jid ON group_members(jid)`,
+		`CREATE INDEX IF NOT EXISTS idx_members_added ON group_members(added_at)`,
 
 		`CREATE TABLE IF NOT EXISTS batch_runs (
 			id              INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -96,6 +129,7 @@ func (db *DB) migrate() error {
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_batch_runs_status ON batch_runs(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_batch_runs_created ON batch_runs(created_at DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_runs_started ON batch_runs(started_at)`,
 
 		`CREATE TABLE IF NOT EXISTS batch_messages (
 			id              INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -112,6 +146,188 @@ func (db *DB) migrate() error {
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_batch_messages_run ON batch_messages(batch_run_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_batch_messages_status ON batch_messages(status)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_messages_sent ON batch_messages(sent_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_messages_jid ON batch_messages(jid)`,
+
+		`CREATE TABLE IF NOT EXISTS draft_variants (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			draft_id        INTEGER NOT NULL,
+			selector_key    TEXT NOT NULL,
+			selector_value  TEXT NOT NULL,
+			content         TEXT NOT NULL,
+			created_at      DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at      DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (draft_id) REFERENCES message_drafts(id) ON DELETE CASCADE,
+			UNIQUE(draft_id, selector_key, selector_value)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_draft_variants_draft ON draft_variants(draft_id)`,
+
+		`CREATE TABLE IF NOT EXISTS batch_events (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			batch_run_id    INTEGER NOT NULL,
+			seq             INTEGER NOT NULL,
+			event_type      TEXT NOT NULL,
+			jid             TEXT,
+			detail          TEXT,
+			created_at      DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (batch_run_id) REFERENCES batch_runs(id) ON DELETE CASCADE,
+			UNIQUE(batch_run_id, seq)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS batch_recipients (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			batch_run_id    INTEGER NOT NULL,
+			jid             TEXT NOT NULL,
+			contact_name    TEXT,
+			status          TEXT NOT NULL,
+			created_at      DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (batch_run_id) REFERENCES batch_runs(id) ON DELETE CASCADE,
+			UNIQUE(batch_run_id, jid)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS contact_validations (
+			jid             TEXT PRIMARY KEY,
+			on_whatsapp     INTEGER NOT NULL,
+			checked_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS recipient_failures (
+			jid                  TEXT PRIMARY KEY,
+			consecutive_failures INTEGER NOT NULL DEFAULT 0,
+			last_error           TEXT,
+			last_failed_at       DATETIME DEFAULT CURRENT_TIMESTAMP,
+			quarantined_at       DATETIME
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS contact_notes (
+			jid             TEXT PRIMARY KEY,
+			content         TEXT NOT NULL,
+			updated_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Placeholder defaults for a group's members; contact attributes take precedence
+		`CREATE TABLE IF NOT EXISTS group_attributes (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			group_id    INTEGER NOT NULL,
+			key         TEXT NOT NULL,
+			value       TEXT NOT NULL,
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (group_id) REFERENCES contact_groups(id) ON DELETE CASCADE,
+			UNIQUE(group_id, key)
+		)`,
+
+		// JIDs merged into another contact, see POST /api/contacts/merge
+		`CREATE TABLE IF NOT EXISTS contact_redirects (
+			jid             TEXT PRIMARY KEY,
+			target_jid      TEXT NOT NULL,
+			created_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS settings (
+			key             TEXT PRIMARY KEY,
+			value           TEXT NOT NULL,
+			updated_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS settings_audit (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			key             TEXT NOT NULL,
+			old_value       TEXT,
+			new_value       TEXT NOT NULL,
+			changed_by      TEXT NOT NULL DEFAULT '',
+			changed_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_settings_audit_key ON settings_audit(key)`,
+
+		`CREATE TABLE IF NOT EXISTS idempotency_keys (
+			key             TEXT PRIMARY KEY,
+			request_hash    TEXT NOT NULL,
+			status_code     INTEGER NOT NULL DEFAULT 0,
+			content_type    TEXT NOT NULL DEFAULT '',
+			response_body   BLOB,
+			completed       INTEGER NOT NULL DEFAULT 0,
+			created_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created ON idempotency_keys(created_at)`,
+
+		// Single sends queued while WhatsApp was disconnected
+		`CREATE TABLE IF NOT EXISTS outbox (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			jid             TEXT NOT NULL,
+			body            TEXT NOT NULL,
+			status          TEXT NOT NULL DEFAULT 'queued',
+			error_message   TEXT,
+			created_at      DATETIME DEFAULT CURRENT_TIMESTAMP,
+			expires_at      DATETIME NOT NULL,
+			sent_at         DATETIME
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_outbox_status ON outbox(status)`,
+
+		// One row per maintenance pass, newest last
+		`CREATE TABLE IF NOT EXISTS maintenance_runs (
+			id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+			trigger            TEXT NOT NULL,
+			started_at         DATETIME NOT NULL,
+			finished_at        DATETIME NOT NULL,
+			batch_runs_pruned  INTEGER NOT NULL DEFAULT 0,
+			outbox_pruned      INTEGER NOT NULL DEFAULT 0,
+			free_percent       REAL NOT NULL DEFAULT 0,
+			vacuumed           INTEGER NOT NULL DEFAULT 0,
+			bytes_freed        INTEGER NOT NULL DEFAULT 0,
+			error_message      TEXT
+		)`,
+
+		// Workspaces keep drafts, groups and batches apart; the default one (ID 1)
+		// holds everything created before workspaces existed
+		`CREATE TABLE IF NOT EXISTS workspaces (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			name            TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			created_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`INSERT OR IGNORE INTO workspaces (id, name) VALUES (1, 'default')`,
+
+		// API tokens; only a hash of each is kept, see models.APITokenRepository
+		`CREATE TABLE IF NOT EXISTS api_tokens (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			name            TEXT NOT NULL,
+			token_hash      TEXT NOT NULL UNIQUE,
+			prefix          TEXT NOT NULL,
+			scopes          TEXT NOT NULL,
+			created_at      DATETIME DEFAULT CURRENT_TIMESTAMP,
+			revoked_at      DATETIME
+		)`,
+
+		// One-time data rewrites that already ran, by name
+		`CREATE TABLE IF NOT EXISTS data_migrations (
+			name            TEXT PRIMARY KEY,
+			applied_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// The address book as the last contact sync copied it, and one row per
+		// sync with what it changed; see models.ContactSyncRepository
+		`CREATE TABLE IF NOT EXISTS contact_snapshot (
+			jid             TEXT PRIMARY KEY,
+			name            TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS sync_runs (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			contact_count   INTEGER NOT NULL DEFAULT 0,
+			added_count     INTEGER NOT NULL DEFAULT 0,
+			removed_count   INTEGER NOT NULL DEFAULT 0,
+			renamed_count   INTEGER NOT NULL DEFAULT 0,
+			baseline        INTEGER NOT NULL DEFAULT 0,
+			created_at      DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS sync_changes (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			sync_run_id     INTEGER NOT NULL REFERENCES sync_runs(id) ON DELETE CASCADE,
+			jid             TEXT NOT NULL,
+			change          TEXT NOT NULL,
+			old_name        TEXT,
+			new_name        TEXT
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_sync_changes_run ON sync_changes(sync_run_id)`,
 	}
 
 	for _, migration := range migrations {
@@ -120,11 +336,110 @@ func (db *DB) migrate() error {
 		}
 	}
 
+	// Columns added after the initial schema. SQLite has no ADD COLUMN IF NOT EXISTS,
+	// so each one is checked against the live table first.
+	columns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{"batch_runs", "validation_failed_count", "INTEGER NOT NULL DEFAULT 0"},
+		{"batch_runs", "spin_seed", "INTEGER NOT NULL DEFAULT 0"},
+		{"batch_runs", "quarantined_count", "INTEGER NOT NULL DEFAULT 0"},
+		{"contact_groups", "default_draft_id", "INTEGER REFERENCES message_drafts(id) ON DELETE SET NULL"},
+		{"batch_messages", "failed_at", "DATETIME"},
+		{"batch_runs", "source", "TEXT NOT NULL DEFAULT 'unknown'"},
+		{"batch_runs", "client_name", "TEXT"},
+		{"batch_runs", "label", "TEXT"},
+		{"batch_messages", "skipped_at", "DATETIME"},
+		{"batch_messages", "skipped_by", "TEXT"},
+		{"batch_runs", "skipped_count", "INTEGER NOT NULL DEFAULT 0"},
+		{"batch_messages", "error_code", "TEXT"},
+		{"batch_runs", "document_attribute", "TEXT"},
+		// No REFERENCES: SQLite only adds a foreign key column with a NULL default
+		{"message_drafts", "workspace_id", "INTEGER NOT NULL DEFAULT 1"},
+		{"contact_groups", "workspace_id", "INTEGER NOT NULL DEFAULT 1"},
+		{"batch_runs", "workspace_id", "INTEGER NOT NULL DEFAULT 1"},
+		{"batch_messages", "wa_message_id", "TEXT"},
+		{"batch_messages", "delivery_status", "TEXT"},
+		{"batch_messages", "delivered_at", "DATETIME"},
+		{"message_drafts", "placeholder_open", "TEXT NOT NULL DEFAULT '{{'"},
+		{"message_drafts", "placeholder_close", "TEXT NOT NULL DEFAULT '}}'"},
+		{"batch_runs", "last_sent_at", "DATETIME"},
+		{"batch_runs", "min_delay_seconds", "INTEGER"},
+		{"batch_runs", "max_delay_seconds", "INTEGER"},
+		{"group_members", "order_index", "INTEGER"},
+		{"batch_runs", "sort_order", "TEXT NOT NULL DEFAULT 'group_order'"},
+		{"batch_runs", "sort_seed", "INTEGER NOT NULL DEFAULT 0"},
+		{"batch_runs", "content_hash", "TEXT"},
+		{"batch_runs", "placeholder_open", "TEXT"},
+		{"batch_runs", "placeholder_close", "TEXT"},
+		{"batch_messages", "manually_edited", "INTEGER NOT NULL DEFAULT 0"},
+		{"batch_messages", "edited_at", "DATETIME"},
+	}
+
+	for _, c := range columns {
+		if err := db.addColumnIfMissing(c.table, c.column, c.definition); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
+	if err := db.scopeGroupNames(); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	// Indexes on the added columns, which do not exist yet when migrations run,
+	// and on contact_groups, which scopeGroupNames may have rebuilt
+	for _, index := range []string{
+		`CREATE INDEX IF NOT EXISTS idx_groups_name ON contact_groups(name)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_messages_failed ON batch_messages(failed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_drafts_workspace ON message_drafts(workspace_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_groups_workspace ON contact_groups(workspace_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_runs_workspace ON batch_runs(workspace_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_messages_wa_id ON batch_messages(wa_message_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_batch_messages_delivery ON batch_messages(delivery_status)`,
+	} {
+		if _, err := db.conn.Exec(index); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
+func (db *DB) addColumnIfMissing(table, column, definition string) error {
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name, ctype  string
+			notNull, pk  int
+			defaultValue sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 func (db *DB) Close() error {
-	return db.conn.Close()
+	err := db.conn.Close()
+	db.lock.Release()
+	return err
 }
 
 func (db *DB) Conn() *sql.DB {
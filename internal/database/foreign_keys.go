@@ -0,0 +1,185 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// orphanCleanupMigration names the one-time orphan cleanup in data_migrations.
+const orphanCleanupMigration = "clean_orphans"
+
+// What happens to a referring row when the row it refers to is deleted.
+const (
+	onDeleteCascade    = "CASCADE"     // The referring row is deleted too
+	onDeleteSetNull    = "SET NULL"    // The reference is cleared
+	onDeleteSetDefault = "SET DEFAULT" // The reference falls back to the column default
+	onDeleteRestrict   = "NO ACTION"   // The delete fails while referring rows exist
+)
+
+// relation is one reference from a column to the id of another table.
+type relation struct {
+	table    string
+	column   string
+	parent   string
+	onDelete string
+	enforced bool // A foreign key in the schema; workspace_id columns were added by ALTER TABLE, which cannot add one
+}
+
+// relations lists every reference between tables. Batch runs keep the draft and
+// group they were created from, so those cannot be deleted while a run refers
+// to them; everything else belonging to a draft, group or run goes with it.
+var relations = []relation{
+	{"group_members", "group_id", "contact_groups", onDeleteCascade, true},
+	{"group_attributes", "group_id", "contact_groups", onDeleteCascade, true},
+	{"draft_variants", "draft_id", "message_drafts", onDeleteCascade, true},
+	{"contact_groups", "default_draft_id", "message_drafts", onDeleteSetNull, true},
+	{"batch_runs", "draft_id", "message_drafts", onDeleteRestrict, true},
+	{"batch_runs", "group_id", "contact_groups", onDeleteRestrict, true},
+	{"batch_messages", "batch_run_id", "batch_runs", onDeleteCascade, true},
+	{"batch_events", "batch_run_id", "batch_runs", onDeleteCascade, true},
+	{"batch_recipients", "batch_run_id", "batch_runs", onDeleteCascade, true},
+	{"sync_changes", "sync_run_id", "sync_runs", onDeleteCascade, true},
+	{"message_drafts", "workspace_id", "workspaces", onDeleteSetDefault, false},
+	{"contact_groups", "workspace_id", "workspaces", onDeleteSetDefault, false},
+	{"batch_runs", "workspace_id", "workspaces", onDeleteSetDefault, false},
+}
+
+// OrphanCleanup reports what CleanOrphans changed, per "table.column".
+type OrphanCleanup struct {
+	Deleted  map[string]int // Rows deleted because what they belonged to was gone
+	Detached map[string]int // References cleared or moved to the default workspace
+	Kept     map[string]int // Batch runs whose draft or group is gone; kept as history
+}
+
+// Changed reports whether the cleanup found anything.
+func (c *OrphanCleanup) Changed() bool {
+	return len(c.Deleted)+len(c.Detached)+len(c.Kept) > 0
+}
+
+// CleanOrphans applies each relation's delete rule to rows left referring to a
+// missing row, as a database written with foreign keys off can have: once per
+// database; later calls return nil. Batch runs are never deleted here, only
+// counted in Kept.
+func (db *DB) CleanOrphans() (*OrphanCleanup, error) {
+	db.Lock()
+	defer db.Unlock()
+
+	var applied int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM data_migrations WHERE name = ?", orphanCleanupMigration).Scan(&applied); err != nil {
+		return nil, fmt.Errorf("failed to check data migrations: %w", err)
+	}
+	if applied > 0 {
+		return nil, nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	cleanup := &OrphanCleanup{Deleted: map[string]int{}, Detached: map[string]int{}, Kept: map[string]int{}}
+	for _, rel := range relations {
+		orphaned := fmt.Sprintf("%[1]s IS NOT NULL AND NOT EXISTS (SELECT 1 FROM %[2]s WHERE %[2]s.id = %[3]s.%[1]s)", rel.column, rel.parent, rel.table)
+		name := rel.table + "." + rel.column
+
+		var count int64
+		switch rel.onDelete {
+		case onDeleteRestrict:
+			if err = tx.QueryRow("SELECT COUNT(*) FROM " + rel.table + " WHERE " + orphaned).Scan(&count); err == nil && count > 0 {
+				cleanup.Kept[name] = int(count)
+			}
+		case onDeleteCascade:
+			count, err = execCount(tx, "DELETE FROM "+rel.table+" WHERE "+orphaned)
+			if count > 0 {
+				cleanup.Deleted[name] = int(count)
+			}
+		case onDeleteSetNull:
+			count, err = execCount(tx, "UPDATE "+rel.table+" SET "+rel.column+" = NULL WHERE "+orphaned)
+			if count > 0 {
+				cleanup.Detached[name] = int(count)
+			}
+		case onDeleteSetDefault:
+			count, err = execCount(tx, "UPDATE "+rel.table+" SET "+rel.column+" = 1 WHERE "+orphaned)
+			if count > 0 {
+				cleanup.Detached[name] = int(count)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to clean %s: %w", name, err)
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO data_migrations (name) VALUES (?)", orphanCleanupMigration); err != nil {
+		return nil, fmt.Errorf("failed to record data migration: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return cleanup, nil
+}
+
+// execCount runs a statement and returns how many rows it changed.
+func execCount(tx *sql.Tx, query string) (int64, error) {
+	result, err := tx.Exec(query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// MissingForeignKeys returns the enforced relations the live schema has no
+// foreign key for, or has with another delete rule, as "table.column".
+func (db *DB) MissingForeignKeys() ([]string, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	var missing []string
+	for _, rel := range relations {
+		if !rel.enforced {
+			continue
+		}
+		found, err := db.hasForeignKey(rel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read foreign keys of %s: %w", rel.table, err)
+		}
+		if !found {
+			missing = append(missing, rel.table+"."+rel.column)
+		}
+	}
+	return missing, nil
+}
+
+func (db *DB) hasForeignKey(rel relation) (bool, error) {
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA foreign_key_list(%s)", rel.table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id, seq                 int
+			table, from             string
+			to                      sql.NullString
+			onUpdate, onDelete, mat string
+		)
+		if err := rows.Scan(&id, &seq, &table, &from, &to, &onUpdate, &onDelete, &mat); err != nil {
+			return false, err
+		}
+		if from == rel.column && table == rel.parent && onDelete == rel.onDelete {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// IsForeignKeyViolation reports whether err is SQLite refusing a change that
+// would break a foreign key, such as deleting a draft batch runs refer to. It
+// goes by SQLite's message rather than the driver's error type, which only
+// exists in cgo builds.
+func IsForeignKeyViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "FOREIGN KEY constraint failed")
+}
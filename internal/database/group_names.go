@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// contactGroupsColumns defines contact_groups. Group names are unique within a
+// workspace; tables from before that, with names unique across workspaces, are
+// rebuilt with this definition by scopeGroupNames.
+const contactGroupsColumns = `(
+			id               INTEGER PRIMARY KEY AUTOINCREMENT,
+			name             TEXT NOT NULL,
+			created_at       DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at       DATETIME DEFAULT CURRENT_TIMESTAMP,
+			default_draft_id INTEGER REFERENCES message_drafts(id) ON DELETE SET NULL,
+			workspace_id     INTEGER NOT NULL DEFAULT 1,
+			UNIQUE(workspace_id, name)
+		)`
+
+// scopeGroupNames rebuilds contact_groups when its names are still unique
+// across workspaces. SQLite cannot drop a UNIQUE column constraint, so the rows
+// are copied into a new table, keeping their IDs and the AUTOINCREMENT counter.
+func (db *DB) scopeGroupNames() error {
+	scoped, err := db.hasUniqueIndex("contact_groups", "workspace_id", "name")
+	if err != nil {
+		return fmt.Errorf("failed to read indexes of contact_groups: %w", err)
+	}
+	if scoped {
+		return nil
+	}
+
+	// Dropping the old table with foreign keys on would delete every group's
+	// members; the pragma only holds for one connection and outside a transaction
+	ctx := context.Background()
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("failed to turn off foreign keys: %w", err)
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var seq int64
+	if err := tx.QueryRow("SELECT COALESCE(MAX(seq), 0) FROM sqlite_sequence WHERE name = 'contact_groups'").Scan(&seq); err != nil {
+		return fmt.Errorf("failed to read group ID counter: %w", err)
+	}
+
+	for _, statement := range []string{
+		`CREATE TABLE contact_groups_new ` + contactGroupsColumns,
+		`INSERT INTO contact_groups_new (id, name, created_at, updated_at, default_draft_id, workspace_id)
+			SELECT id, name, created_at, updated_at, default_draft_id, workspace_id FROM contact_groups`,
+		`DROP TABLE contact_groups`,
+		`ALTER TABLE contact_groups_new RENAME TO contact_groups`,
+	} {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("failed to rebuild contact_groups: %w", err)
+		}
+	}
+	if _, err := tx.Exec("UPDATE sqlite_sequence SET seq = ? WHERE name = 'contact_groups' AND seq < ?", seq, seq); err != nil {
+		return fmt.Errorf("failed to restore group ID counter: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// hasUniqueIndex reports whether table has a unique index on exactly columns, in order.
+func (db *DB) hasUniqueIndex(table string, columns ...string) (bool, error) {
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA index_list(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	var unique []string
+	for rows.Next() {
+		var (
+			seq, isUnique, partial int
+			name, origin           string
+		)
+		if err := rows.Scan(&seq, &name, &isUnique, &origin, &partial); err != nil {
+			rows.Close()
+			return false, err
+		}
+		if isUnique == 1 {
+			unique = append(unique, name)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return false, err
+	}
+
+	for _, index := range unique {
+		indexed, err := db.indexColumns(index)
+		if err != nil {
+			return false, err
+		}
+		if slices.Equal(indexed, columns) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (db *DB) indexColumns(index string) ([]string, error) {
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA index_info(%s)", index))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var (
+			seqno, cid int
+			name       string
+		)
+		if err := rows.Scan(&seqno, &cid, &name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// TestScopeGroupNamesRebuildsOldTable opens a database whose group names are
+// unique across workspaces, as before they were scoped, and checks the rebuild
+// keeps groups, members, IDs and the ID counter.
+func TestScopeGroupNamesRebuildsOldTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "friday.db")
+	old, err := sql.Open("sqlite3", path+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	for _, statement := range []string{
+		`CREATE TABLE message_drafts (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			title       TEXT NOT NULL,
+			content     TEXT NOT NULL,
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE contact_groups (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			name        TEXT NOT NULL UNIQUE,
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE group_members (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			group_id   INTEGER NOT NULL,
+			jid        TEXT NOT NULL,
+			added_at   DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (group_id) REFERENCES contact_groups(id) ON DELETE CASCADE
+		)`,
+		`INSERT INTO contact_groups (id, name) VALUES (1, 'Customers'), (7, 'Suppliers')`,
+		`INSERT INTO group_members (group_id, jid) VALUES (1, '15550000001@s.whatsapp.net'), (7, '15550000002@s.whatsapp.net')`,
+		// A group deleted after the last one was created; its ID must not come back
+		`UPDATE sqlite_sequence SET seq = 9 WHERE name = 'contact_groups'`,
+	} {
+		if _, err := old.Exec(statement); err != nil {
+			t.Fatalf("failed to create old schema: %v", err)
+		}
+	}
+	old.Close()
+
+	db, err := New(path)
+	if err != nil {
+		t.Fatalf("failed to open old database: %v", err)
+	}
+	defer db.Close()
+
+	scoped, err := db.hasUniqueIndex("contact_groups", "workspace_id", "name")
+	if err != nil || !scoped {
+		t.Fatalf("unique index on (workspace_id, name) = %t, %v; want one", scoped, err)
+	}
+	var members int
+	if err := db.Conn().QueryRow("SELECT COUNT(*) FROM group_members").Scan(&members); err != nil || members != 2 {
+		t.Errorf("group members after the rebuild = %d, %v; want 2", members, err)
+	}
+	var foreignKeys bool
+	if err := db.Conn().QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil || !foreignKeys {
+		t.Errorf("foreign keys after the rebuild = %t, %v; want on", foreignKeys, err)
+	}
+
+	if _, err := db.Conn().Exec("INSERT INTO workspaces (id, name) VALUES (2, 'sales')"); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+	result, err := db.Conn().Exec("INSERT INTO contact_groups (workspace_id, name) VALUES (2, 'Customers')")
+	if err != nil {
+		t.Fatalf("creating a group of a taken name in another workspace: %v", err)
+	}
+	if id, _ := result.LastInsertId(); id != 10 {
+		t.Errorf("new group ID = %d, want 10", id)
+	}
+	if _, err := db.Conn().Exec("INSERT INTO contact_groups (workspace_id, name) VALUES (1, 'Customers')"); err == nil {
+		t.Error("created a second group named Customers in the default workspace")
+	}
+
+	// Deleting a group still takes its members along
+	if _, err := db.Conn().Exec("DELETE FROM contact_groups WHERE id = 1"); err != nil {
+		t.Fatalf("failed to delete group: %v", err)
+	}
+	if err := db.Conn().QueryRow("SELECT COUNT(*) FROM group_members").Scan(&members); err != nil || members != 1 {
+		t.Errorf("group members after deleting a group = %d, %v; want 1", members, err)
+	}
+}
@@ -0,0 +1,58 @@
+package database
+
+import "fmt"
+
+// SpaceUsage describes how much of the database file is in use.
+type SpaceUsage struct {
+	SizeBytes int64 // Size of the main database file
+	FreeBytes int64 // Pages on the freelist, reclaimable only by VACUUM
+}
+
+// FreePercent is the share of the file taken by free pages.
+func (u SpaceUsage) FreePercent() float64 {
+	if u.SizeBytes == 0 {
+		return 0
+	}
+	return float64(u.FreeBytes) * 100 / float64(u.SizeBytes)
+}
+
+// SpaceUsage reads the page counts of the database file.
+func (db *DB) SpaceUsage() (SpaceUsage, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var pageSize, pageCount, freelistCount int64
+	if err := db.conn.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return SpaceUsage{}, fmt.Errorf("failed to read page size: %w", err)
+	}
+	if err := db.conn.QueryRow("PRAGMA page_count").Scan(&pageCount); err != nil {
+		return SpaceUsage{}, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if err := db.conn.QueryRow("PRAGMA freelist_count").Scan(&freelistCount); err != nil {
+		return SpaceUsage{}, fmt.Errorf("failed to read freelist count: %w", err)
+	}
+	return SpaceUsage{SizeBytes: pageSize * pageCount, FreeBytes: pageSize * freelistCount}, nil
+}
+
+// Analyze refreshes the statistics the query planner uses.
+func (db *DB) Analyze() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, err := db.conn.Exec("ANALYZE"); err != nil {
+		return fmt.Errorf("failed to analyze database: %w", err)
+	}
+	return nil
+}
+
+// Vacuum rebuilds the database file without its free pages. Every other
+// query waits until it is done, which takes a while on a large database.
+func (db *DB) Vacuum() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, err := db.conn.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
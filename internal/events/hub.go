@@ -0,0 +1,94 @@
+package events
+
+import (
+	"sync"
+)
+
+// Topics that can be published on the hub and selected by subscribers.
+const (
+	TopicStatus = "status" // WhatsApp connection status changes
+	TopicBatch  = "batch"  // Batch lifecycle events from the worker
+	TopicQR     = "qr"     // QR code generated or cleared
+)
+
+// AllTopics lists every topic a client may subscribe to.
+var AllTopics = []string{TopicStatus, TopicBatch, TopicQR}
+
+// Envelope wraps every event sent to subscribers.
+type Envelope struct {
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload"`
+}
+
+// Hub fans out server events to any number of subscribers, filtered by topic.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[chan *Envelope]map[string]bool
+}
+
+// NewHub creates an empty event hub.
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[chan *Envelope]map[string]bool),
+	}
+}
+
+// Subscribe registers a new subscriber for the given topics.
+// An empty topic list subscribes to everything.
+func (h *Hub) Subscribe(topics []string) chan *Envelope {
+	ch := make(chan *Envelope, 16)
+
+	selected := make(map[string]bool)
+	if len(topics) == 0 {
+		topics = AllTopics
+	}
+	for _, topic := range topics {
+		selected[topic] = true
+	}
+
+	h.mu.Lock()
+	h.subscribers[ch] = selected
+	h.mu.Unlock()
+
+	return ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel.
+func (h *Hub) Unsubscribe(ch chan *Envelope) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[ch]; ok {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Publish sends an event to every subscriber of the topic.
+// Slow subscribers drop events rather than blocking the publisher.
+func (h *Hub) Publish(topic string, payload interface{}) {
+	event := &Envelope{Type: topic, Payload: payload}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch, topics := range h.subscribers {
+		if !topics[topic] {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// IsTopic reports whether the name is a known topic.
+func IsTopic(name string) bool {
+	for _, topic := range AllTopics {
+		if topic == name {
+			return true
+		}
+	}
+	return false
+}
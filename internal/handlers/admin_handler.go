@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"friday/internal/batch"
+	"friday/internal/logging"
+	"friday/internal/models"
+	"friday/internal/whatsapp"
+)
+
+// readOnlyMessage is the error every send path returns while read-only mode is on.
+const readOnlyMessage = "Server is in read-only mode, outgoing messages are frozen"
+
+// AdminHandler serves server-wide maintenance switches.
+type AdminHandler struct {
+	settingsRepo  *models.SettingsRepository
+	waClient      *whatsapp.Client
+	eventsHandler *EventsHandler
+	maintainer    *batch.Maintainer
+}
+
+// NewAdminHandler creates a new admin handler.
+func NewAdminHandler(settingsRepo *models.SettingsRepository, waClient *whatsapp.Client, eventsHandler *EventsHandler, maintainer *batch.Maintainer) *AdminHandler {
+	return &AdminHandler{
+		settingsRepo:  settingsRepo,
+		waClient:      waClient,
+		eventsHandler: eventsHandler,
+		maintainer:    maintainer,
+	}
+}
+
+type ReadOnlyRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type ReadOnlyResponse struct {
+	Success  bool   `json:"success"`
+	Message  string `json:"message"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// HandleReadOnly handles GET /api/admin/read-only (current state) and
+// POST /api/admin/read-only (toggle). While read-only mode is on, single sends
+// and batch creation are refused and the running batch pauses until it is cleared.
+func (h *AdminHandler) HandleReadOnly(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, ReadOnlyResponse{
+			Success:  true,
+			Message:  tr(r, "read_only_retrieved"),
+			ReadOnly: h.waClient.IsReadOnly(),
+		})
+	case http.MethodPost:
+		h.setReadOnly(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *AdminHandler) setReadOnly(w http.ResponseWriter, r *http.Request) {
+	var req ReadOnlyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.settingsRepo.Set(models.SettingReadOnly, strconv.FormatBool(req.Enabled), r.RemoteAddr); err != nil {
+		jsonError(w, fmt.Sprintf("Failed to save read-only state: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	message := "Read-only mode disabled, sending resumed"
+	if req.Enabled {
+		message = "Read-only mode enabled, outgoing messages are frozen"
+	}
+	logging.FromContext(r.Context()).Warn(message, "remote_addr", r.RemoteAddr)
+	h.eventsHandler.PublishStatus(h.waClient.IsConnected())
+
+	writeJSON(w, http.StatusOK, ReadOnlyResponse{
+		Success:  true,
+		Message:  message,
+		ReadOnly: req.Enabled,
+	})
+}
+
+type MaintenanceResponse struct {
+	Success bool                   `json:"success"`
+	Message string                 `json:"message"`
+	Run     *models.MaintenanceRun `json:"run,omitempty"`
+}
+
+// HandleMaintenance handles POST /api/admin/maintenance, running the nightly
+// database maintenance now. It answers 409 while a batch is sending or a pass
+// is already running.
+func (h *AdminHandler) HandleMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	run, err := h.maintainer.RunNow(models.MaintenanceTriggerManual)
+	switch {
+	case errors.Is(err, batch.ErrMaintenanceBatchRunning):
+		jsonError(w, tr(r, "maintenance_batch_running"), http.StatusConflict)
+		return
+	case errors.Is(err, batch.ErrMaintenanceRunning):
+		jsonError(w, tr(r, "maintenance_running"), http.StatusConflict)
+		return
+	case err != nil:
+		// The failed pass is recorded too; return it with the error
+		writeJSON(w, http.StatusInternalServerError, MaintenanceResponse{
+			Success: false,
+			Message: fmt.Sprintf("Maintenance failed: %v", err),
+			Run:     run,
+		})
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("Maintenance run on request", "remote_addr", r.RemoteAddr)
+
+	writeJSON(w, http.StatusOK, MaintenanceResponse{
+		Success: true,
+		Message: tr(r, "maintenance_done"),
+		Run:     run,
+	})
+}
@@ -1,23 +1,24 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 
+	"friday/internal/logging"
 	"friday/internal/models"
 )
 
 // AttributeHandler handles HTTP requests for contact attribute operations.
 type AttributeHandler struct {
-	repo *models.AttributeRepository
+	repo     *models.AttributeRepository
+	noteRepo *models.ContactNoteRepository
 }
 
 // NewAttributeHandler creates a new attribute handler.
-func NewAttributeHandler(repo *models.AttributeRepository) *AttributeHandler {
-	return &AttributeHandler{repo: repo}
+func NewAttributeHandler(repo *models.AttributeRepository, noteRepo *models.ContactNoteRepository) *AttributeHandler {
+	return &AttributeHandler{repo: repo, noteRepo: noteRepo}
 }
 
 // Request/Response types
@@ -32,8 +33,30 @@ type AttributeResponse struct {
 	Message    string                     `json:"message"`
 	Attribute  *models.ContactAttribute   `json:"attribute,omitempty"`
 	Attributes []models.ContactAttribute  `json:"attributes,omitempty"`
+	Note       *models.ContactNote        `json:"note,omitempty"` // GET only; never used as a placeholder
 }
 
+type ClearAttributesResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Deleted int    `json:"deleted"` // How many attributes were removed
+}
+
+type AttributeHistoryResponse struct {
+	Success    bool                     `json:"success"`
+	Message    string                   `json:"message"`
+	Changes    []models.AttributeChange `json:"changes"`
+	TotalCount int                      `json:"total_count"` // Matching entries across all pages
+	Limit      int                      `json:"limit"`
+	Offset     int                      `json:"offset"`
+}
+
+// Page size limits for GET /api/contacts/{jid}/attributes/history
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 100
+)
+
 type AttributeKeysResponse struct {
 	Success bool              `json:"success"`
 	Message string            `json:"message"`
@@ -41,6 +64,74 @@ type AttributeKeysResponse struct {
 	Counts  map[string]int    `json:"counts,omitempty"` // Optional: count of contacts per key
 }
 
+// maxBatchGetJIDs caps the contacts of one POST /api/attributes/batch-get.
+const maxBatchGetJIDs = 1000
+
+// BatchGetAttributesRequest is the body of POST /api/attributes/batch-get.
+type BatchGetAttributesRequest struct {
+	JIDs []string `json:"jids"`
+	Keys []string `json:"keys,omitempty"` // Only these keys; all when empty
+}
+
+type BatchGetAttributesResponse struct {
+	Success    bool                         `json:"success"`
+	Message    string                       `json:"message"`
+	Attributes map[string]map[string]string `json:"attributes"` // Normalized JID -> key -> value; every requested JID is present
+	Count      int                          `json:"count"`
+}
+
+// HandleBatchGet handles POST /api/attributes/batch-get, returning the
+// attributes of many contacts in one call.
+func (h *AttributeHandler) HandleBatchGet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req BatchGetAttributesRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.JIDs) == 0 {
+		jsonError(w, tr(r, "jids_required"), http.StatusBadRequest)
+		return
+	}
+	if len(req.JIDs) > maxBatchGetJIDs {
+		jsonError(w, fmt.Sprintf("Too many JIDs: %d (limit %d per request)", len(req.JIDs), maxBatchGetJIDs), http.StatusBadRequest)
+		return
+	}
+	for _, key := range req.Keys {
+		if key == "" || !validAttributeKey(key) {
+			jsonError(w, fmt.Sprintf("Invalid key %q: only letters, numbers and underscores", key), http.StatusBadRequest)
+			return
+		}
+	}
+
+	jids, err := normalizeJIDList(req.JIDs)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	attributes, err := h.repo.GetForContacts(jids, req.Keys)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get attributes: %v", err), http.StatusInternalServerError)
+		return
+	}
+	for _, jid := range jids {
+		if attributes[jid] == nil {
+			attributes[jid] = map[string]string{}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, BatchGetAttributesResponse{
+		Success:    true,
+		Message:    tr(r, "attributes_retrieved"),
+		Attributes: attributes,
+		Count:      len(jids),
+	})
+}
+
 // HandleContactAttributes handles /api/contacts/{jid}/attributes[/{key}] routes.
 func (h *AttributeHandler) HandleContactAttributes(w http.ResponseWriter, r *http.Request) {
 	// Parse path: /api/contacts/{jid}/attributes[/{key}]
@@ -49,15 +140,13 @@ func (h *AttributeHandler) HandleContactAttributes(w http.ResponseWriter, r *htt
 	// Find where /attributes starts
 	attrIdx := strings.Index(path, "/attributes")
 	if attrIdx == -1 {
-		jsonError(w, "Invalid path", http.StatusBadRequest)
+		jsonError(w, tr(r, "invalid_path"), http.StatusBadRequest)
 		return
 	}
 
 	// Extract JID (URL-decode it since JIDs contain special characters)
-	jidEncoded := path[:attrIdx]
-	jid, err := url.PathUnescape(jidEncoded)
-	if err != nil {
-		jsonError(w, "Invalid JID encoding", http.StatusBadRequest)
+	jid, ok := pathJID(w, r, path[:attrIdx])
+	if !ok {
 		return
 	}
 
@@ -70,6 +159,12 @@ func (h *AttributeHandler) HandleContactAttributes(w http.ResponseWriter, r *htt
 		key, _ = url.PathUnescape(key)
 	}
 
+	// GET .../attributes/history; the name stays usable as a key for POST and DELETE
+	if key == "history" && r.Method == http.MethodGet {
+		h.getHistory(w, r, jid)
+		return
+	}
+
 	// Route based on method and whether key is present
 	switch r.Method {
 	case http.MethodGet:
@@ -78,7 +173,7 @@ func (h *AttributeHandler) HandleContactAttributes(w http.ResponseWriter, r *htt
 		h.setAttribute(w, r, jid)
 	case http.MethodDelete:
 		if key == "" {
-			jsonError(w, "Attribute key is required for DELETE", http.StatusBadRequest)
+			h.clearAttributes(w, r, jid)
 			return
 		}
 		h.deleteAttribute(w, r, jid, key)
@@ -96,9 +191,7 @@ func (h *AttributeHandler) HandleAttributeKeys(w http.ResponseWriter, r *http.Re
 
 	keys, err := h.repo.GetAllUniqueKeys()
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(AttributeKeysResponse{
+		writeJSON(w, http.StatusInternalServerError, AttributeKeysResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to get attribute keys: %v", err),
 		})
@@ -108,10 +201,9 @@ func (h *AttributeHandler) HandleAttributeKeys(w http.ResponseWriter, r *http.Re
 	// Also get counts for each key
 	counts, _ := h.repo.CountByKey() // Ignore error, counts are optional
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(AttributeKeysResponse{
+	writeJSON(w, http.StatusOK, AttributeKeysResponse{
 		Success: true,
-		Message: "Attribute keys retrieved successfully",
+		Message: tr(r, "attribute_keys_retrieved"),
 		Keys:    keys,
 		Counts:  counts,
 	})
@@ -120,32 +212,56 @@ func (h *AttributeHandler) HandleAttributeKeys(w http.ResponseWriter, r *http.Re
 func (h *AttributeHandler) getAttributes(w http.ResponseWriter, r *http.Request, jid string) {
 	attrs, err := h.repo.GetAllForContact(jid)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(AttributeResponse{
+		writeJSON(w, http.StatusInternalServerError, AttributeResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to get attributes: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(AttributeResponse{
+	note, err := h.noteRepo.Get(jid)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get note: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, AttributeResponse{
 		Success:    true,
-		Message:    fmt.Sprintf("Found %d attributes", len(attrs)),
+		Message:    tr(r, "attributes_found", len(attrs)),
 		Attributes: attrs,
+		Note:       note,
+	})
+}
+
+// getHistory handles GET /api/contacts/{jid}/attributes/history.
+// Optional query parameters: key (only this attribute), limit (default 50), offset.
+func (h *AttributeHandler) getHistory(w http.ResponseWriter, r *http.Request, jid string) {
+	limit, offset, err := parsePage(r, defaultHistoryPageSize, maxHistoryPageSize)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	changes, total, err := h.repo.GetHistory(jid, key, limit, offset)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve attribute history: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, AttributeHistoryResponse{
+		Success:    true,
+		Message:    tr(r, "attribute_changes_found", total),
+		Changes:    changes,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
 	})
 }
 
 func (h *AttributeHandler) setAttribute(w http.ResponseWriter, r *http.Request, jid string) {
 	var req SetAttributeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(AttributeResponse{
-			Success: false,
-			Message: fmt.Sprintf("Invalid JSON: %v", err),
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -154,27 +270,22 @@ func (h *AttributeHandler) setAttribute(w http.ResponseWriter, r *http.Request,
 	value := strings.TrimSpace(req.Value)
 
 	if key == "" {
-		jsonError(w, "Attribute key is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "attribute_key_required"), http.StatusBadRequest)
 		return
 	}
 
-	// Validate key format: only alphanumeric and underscores
-	for _, c := range key {
-		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_') {
-			jsonError(w, "Attribute key must contain only letters, numbers, and underscores", http.StatusBadRequest)
-			return
-		}
+	if !validAttributeKey(key) {
+		jsonError(w, tr(r, "attribute_key_invalid"), http.StatusBadRequest)
+		return
 	}
 
 	if value == "" {
-		jsonError(w, "Attribute value is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "attribute_value_required"), http.StatusBadRequest)
 		return
 	}
 
-	if err := h.repo.Set(jid, key, value); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(AttributeResponse{
+	if err := h.repo.Set(jid, key, value, models.AttributeSourceAPI); err != nil {
+		writeJSON(w, http.StatusInternalServerError, AttributeResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to set attribute: %v", err),
 		})
@@ -184,20 +295,28 @@ func (h *AttributeHandler) setAttribute(w http.ResponseWriter, r *http.Request,
 	// Fetch the saved attribute to return it
 	attr, _ := h.repo.Get(jid, key)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(AttributeResponse{
+	writeJSON(w, http.StatusOK, AttributeResponse{
 		Success:   true,
-		Message:   "Attribute saved successfully",
+		Message:   tr(r, "attribute_saved"),
 		Attribute: attr,
 	})
 }
 
+// validAttributeKey reports whether key can be used as a {{placeholder}}:
+// only letters, numbers and underscores.
+func validAttributeKey(key string) bool {
+	for _, c := range key {
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
 func (h *AttributeHandler) deleteAttribute(w http.ResponseWriter, r *http.Request, jid, key string) {
-	found, err := h.repo.Delete(jid, key)
+	found, err := h.repo.Delete(jid, key, models.AttributeSourceAPI)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(AttributeResponse{
+		writeJSON(w, http.StatusInternalServerError, AttributeResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to delete attribute: %v", err),
 		})
@@ -205,13 +324,35 @@ func (h *AttributeHandler) deleteAttribute(w http.ResponseWriter, r *http.Reques
 	}
 
 	if !found {
-		jsonError(w, "Attribute not found", http.StatusNotFound)
+		jsonError(w, tr(r, "attribute_not_found"), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, AttributeResponse{
+		Success: true,
+		Message: tr(r, "attribute_deleted"),
+	})
+}
+
+// clearAttributes handles DELETE /api/contacts/{jid}/attributes, removing every
+// attribute of the contact. It needs ?confirm=true, so a client that drops the
+// key from the path by mistake does not wipe them all.
+func (h *AttributeHandler) clearAttributes(w http.ResponseWriter, r *http.Request, jid string) {
+	if r.URL.Query().Get("confirm") != "true" {
+		jsonError(w, tr(r, "attributes_clear_confirm"), http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.repo.DeleteAllForContact(jid, models.AttributeSourceAPI)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to delete attributes: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(AttributeResponse{
+	logging.FromContext(r.Context()).Info("Contact attributes cleared", "jid", jid, "deleted", deleted)
+	writeJSON(w, http.StatusOK, ClearAttributesResponse{
 		Success: true,
-		Message: "Attribute deleted successfully",
+		Message: tr(r, "attributes_cleared", deleted),
+		Deleted: deleted,
 	})
 }
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"friday/internal/logging"
+	"friday/internal/models"
+)
+
+// TokenCookie carries the API token for the web pages, which cannot set an
+// Authorization header on event streams.
+const TokenCookie = "friday-token"
+
+// maxTokenNameLength bounds the name given to a token.
+const maxTokenNameLength = 100
+
+// readOnlyPosts are the POST endpoints that only read: a read token may use them.
+var readOnlyPosts = map[string]bool{
+	"/api/contacts/validate":    true,
+	"/api/attributes/batch-get": true,
+	"/api/batch-runs/preflight": true,
+}
+
+// requiredScope returns the scope a request needs, or "" when it needs no
+// token: pages, static files, CORS preflights and the signed integration trigger.
+func requiredScope(r *http.Request) string {
+	path := r.URL.Path
+	switch {
+	case !strings.HasPrefix(path, "/api/"), r.Method == http.MethodOptions:
+		return ""
+	case path == "/api/integrations/trigger-batch":
+		return "" // Signed with the integration secret instead
+	case strings.HasPrefix(path, "/api/admin/"),
+		path == "/api/settings", strings.HasPrefix(path, "/api/settings/"),
+		path == "/api/whatsapp/connect", path == "/api/whatsapp/disconnect":
+		return models.ScopeAdmin
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		return models.ScopeRead
+	case readOnlyPosts[path],
+		strings.HasPrefix(path, "/api/drafts/") && strings.HasSuffix(path, "/preview"):
+		return models.ScopeRead
+	}
+	return models.ScopeSend
+}
+
+// bearerToken returns the token from the Authorization header, or else from TokenCookie.
+func bearerToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, _ := strings.Cut(header, " ")
+		if strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if cookie, err := r.Cookie(TokenCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// AuthHandler checks API tokens and manages them.
+type AuthHandler struct {
+	repo *models.APITokenRepository
+}
+
+// NewAuthHandler creates a new auth handler.
+func NewAuthHandler(repo *models.APITokenRepository) *AuthHandler {
+	return &AuthHandler{repo: repo}
+}
+
+// RegisterCapabilities adds auth, on while at least one token is active.
+func (h *AuthHandler) RegisterCapabilities(c *Capabilities) {
+	c.Register(CapabilityAuth, func() bool {
+		active, _, err := h.repo.CountActive()
+		if err != nil {
+			slog.Warn("Failed to count API tokens", "error", err)
+		}
+		return active > 0
+	})
+}
+
+// Wrap requires a token holding the scope requiredScope picks, once at least
+// one token exists; until then the API is open as before. Tokens are looked up
+// on every request, so a revoked one stops working at once.
+func (h *AuthHandler) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := requiredScope(r)
+		if scope == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		active, _, err := h.repo.CountActive()
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to check API tokens: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if active == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		secret := bearerToken(r)
+		if secret == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="friday"`)
+			jsonError(w, tr(r, "auth_required"), http.StatusUnauthorized)
+			return
+		}
+		token, err := h.repo.Authenticate(secret)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to check API token: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if token == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="friday", error="invalid_token"`)
+			jsonError(w, tr(r, "auth_invalid"), http.StatusUnauthorized)
+			return
+		}
+		if !token.HasScope(scope) {
+			logging.FromContext(r.Context()).Warn("API token lacks scope", "token_id", token.ID, "scope", scope, "path", r.URL.Path)
+			jsonError(w, tr(r, "auth_missing_scope", scope), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+type CreateTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"` // read, send and/or admin
+}
+
+type TokenResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Token   *models.APIToken `json:"token,omitempty"`
+	Secret  string           `json:"secret,omitempty"` // The token itself, only in the creation response
+}
+
+type TokenListResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Tokens  []models.APIToken `json:"tokens"`
+	Count   int               `json:"count"`
+}
+
+// HandleTokens handles GET /api/admin/tokens (list) and POST /api/admin/tokens (create).
+func (h *AuthHandler) HandleTokens(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listTokens(w, r)
+	case http.MethodPost:
+		h.createToken(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleToken handles DELETE /api/admin/tokens/{id}, revoking the token.
+func (h *AuthHandler) HandleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/admin/tokens/"), 10, 64)
+	if err != nil {
+		jsonError(w, tr(r, "invalid_token_id"), http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.repo.GetByID(id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get token: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if token == nil || token.RevokedAt != nil {
+		jsonError(w, tr(r, "token_not_found"), http.StatusNotFound)
+		return
+	}
+
+	// Revoking the last token opens the API again; revoking the last admin
+	// token while others remain would leave nobody able to manage them
+	if token.HasScope(models.ScopeAdmin) {
+		active, admins, err := h.repo.CountActive()
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to count tokens: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if admins == 1 && active > 1 {
+			jsonError(w, tr(r, "token_last_admin"), http.StatusConflict)
+			return
+		}
+	}
+
+	revoked, err := h.repo.Revoke(id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to revoke token: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		jsonError(w, tr(r, "token_not_found"), http.StatusNotFound)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("API token revoked", "token_id", id, "name", token.Name)
+	writeJSON(w, http.StatusOK, TokenResponse{
+		Success: true,
+		Message: tr(r, "token_revoked"),
+	})
+}
+
+func (h *AuthHandler) listTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.repo.GetAll()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get tokens: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, TokenListResponse{
+		Success: true,
+		Message: tr(r, "tokens_retrieved"),
+		Tokens:  tokens,
+		Count:   len(tokens),
+	})
+}
+
+func (h *AuthHandler) createToken(w http.ResponseWriter, r *http.Request) {
+	var req CreateTokenRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		jsonError(w, tr(r, "token_name_required"), http.StatusBadRequest)
+		return
+	}
+	if len(name) > maxTokenNameLength {
+		jsonError(w, tr(r, "token_name_too_long", maxTokenNameLength), http.StatusBadRequest)
+		return
+	}
+	scopes, err := models.NormalizeScopes(req.Scopes)
+	if err != nil {
+		jsonError(w, tr(r, "token_invalid_scopes", err, strings.Join(models.APIScopes, ", ")), http.StatusBadRequest)
+		return
+	}
+	token := &models.APIToken{Name: name, Scopes: scopes}
+
+	// The first token turns authentication on; without admin nobody could manage tokens after it
+	_, admins, err := h.repo.CountActive()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to count tokens: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if admins == 0 && !token.HasScope(models.ScopeAdmin) {
+		jsonError(w, tr(r, "token_first_needs_admin"), http.StatusBadRequest)
+		return
+	}
+
+	secret, err := h.repo.Create(token)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to create token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("API token created", "token_id", token.ID, "name", token.Name, "scopes", token.Scopes)
+	writeJSON(w, http.StatusCreated, TokenResponse{
+		Success: true,
+		Message: tr(r, "token_created"),
+		Token:   token,
+		Secret:  secret,
+	})
+}
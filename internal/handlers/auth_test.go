@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"friday/internal/models"
+)
+
+// authRequest sends method path through h with the token secret, if any, and
+// returns the response.
+func authRequest(h *AuthHandler, method, path, secret string) *httptest.ResponseRecorder {
+	handler := h.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(method, path, nil)
+	if secret != "" {
+		req.Header.Set("Authorization", "Bearer "+secret)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthScopes(t *testing.T) {
+	repo := models.NewAPITokenRepository(newTestDB(t))
+	h := NewAuthHandler(repo)
+
+	if rec := authRequest(h, http.MethodPost, "/api/drafts", ""); rec.Code != http.StatusOK {
+		t.Fatalf("without any token the API is open, got status %d", rec.Code)
+	}
+
+	secrets := make(map[string]string)
+	for _, scope := range models.APIScopes {
+		secret, err := repo.Create(&models.APIToken{Name: scope, Scopes: []string{scope}})
+		if err != nil {
+			t.Fatalf("failed to create %s token: %v", scope, err)
+		}
+		secrets[scope] = secret
+	}
+
+	requests := []struct {
+		method, path string
+		scope        string
+	}{
+		{http.MethodGet, "/api/drafts", models.ScopeRead},
+		{http.MethodHead, "/api/batch-runs", models.ScopeRead},
+		{http.MethodPost, "/api/contacts/validate", models.ScopeRead},
+		{http.MethodPost, "/api/drafts/3/preview", models.ScopeRead},
+		{http.MethodPost, "/api/drafts", models.ScopeSend},
+		{http.MethodPost, "/api/whatsapp/send", models.ScopeSend},
+		{http.MethodDelete, "/api/groups/1", models.ScopeSend},
+		{http.MethodGet, "/api/settings", models.ScopeAdmin},
+		{http.MethodPut, "/api/settings", models.ScopeAdmin},
+		{http.MethodPost, "/api/whatsapp/connect", models.ScopeAdmin},
+		{http.MethodGet, "/api/admin/tokens", models.ScopeAdmin},
+	}
+	for _, req := range requests {
+		// A token passes only with the scope itself; admin does not imply send or read
+		for _, scope := range models.APIScopes {
+			want := http.StatusForbidden
+			if scope == req.scope {
+				want = http.StatusOK
+			}
+			if rec := authRequest(h, req.method, req.path, secrets[scope]); rec.Code != want {
+				t.Errorf("%s %s with a %s token: status %d, want %d", req.method, req.path, scope, rec.Code, want)
+			}
+		}
+
+		rec := authRequest(h, req.method, req.path, "")
+		if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s %s without a token: status %d, WWW-Authenticate %q; want 401 with the header",
+				req.method, req.path, rec.Code, rec.Header().Get("WWW-Authenticate"))
+		}
+		if rec := authRequest(h, req.method, req.path, "not-a-token"); rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s with an unknown token: status %d, want 401", req.method, req.path, rec.Code)
+		}
+	}
+}
+
+func TestAuthOpenPaths(t *testing.T) {
+	repo := models.NewAPITokenRepository(newTestDB(t))
+	h := NewAuthHandler(repo)
+	if _, err := repo.Create(&models.APIToken{Name: "admin", Scopes: []string{models.ScopeAdmin}}); err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/"},
+		{http.MethodGet, "/dashboard"},
+		{http.MethodGet, "/static/js/auth.js"},
+		{http.MethodOptions, "/api/drafts"},
+		{http.MethodPost, "/api/integrations/trigger-batch"},
+	} {
+		if rec := authRequest(h, req.method, req.path, ""); rec.Code != http.StatusOK {
+			t.Errorf("%s %s without a token: status %d, want 200", req.method, req.path, rec.Code)
+		}
+	}
+}
+
+func TestAuthCookieAndRevocation(t *testing.T) {
+	repo := models.NewAPITokenRepository(newTestDB(t))
+	h := NewAuthHandler(repo)
+	token := &models.APIToken{Name: "reader", Scopes: []string{models.ScopeRead}}
+	secret, err := repo.Create(token)
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+	// A second token keeps authentication on once the first is revoked
+	if _, err := repo.Create(&models.APIToken{Name: "admin", Scopes: []string{models.ScopeAdmin}}); err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	handler := h.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	fromCookie := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/events", nil)
+		req.AddCookie(&http.Cookie{Name: TokenCookie, Value: secret})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := fromCookie(); code != http.StatusOK {
+		t.Errorf("token in the cookie: status %d, want 200", code)
+	}
+
+	if revoked, err := repo.Revoke(token.ID); err != nil || !revoked {
+		t.Fatalf("failed to revoke token: %t, %v", revoked, err)
+	}
+	if code := fromCookie(); code != http.StatusUnauthorized {
+		t.Errorf("revoked token in the cookie: status %d, want 401", code)
+	}
+	if rec := authRequest(h, http.MethodGet, "/api/drafts", secret); rec.Code != http.StatusUnauthorized {
+		t.Errorf("revoked token in the header: status %d, want 401", rec.Code)
+	}
+}
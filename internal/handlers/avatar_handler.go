@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"friday/internal/logging"
+	"friday/internal/whatsapp"
+)
+
+const (
+	// avatarTTL is how long a fetched picture (or its absence) is served without asking WhatsApp.
+	avatarTTL = 6 * time.Hour
+	// maxAvatarEntries bounds the in-memory cache; expired entries are purged beyond it.
+	maxAvatarEntries = 2000
+	// maxAvatarBytes bounds a single downloaded picture.
+	maxAvatarBytes = 1 << 20
+)
+
+// avatarEntry is a cached profile picture. Missing entries remember that there is none.
+type avatarEntry struct {
+	id          string
+	contentType string
+	data        []byte
+	missing     bool
+	fetchedAt   time.Time
+}
+
+// AvatarHandler serves contact profile pictures from an in-memory cache,
+// fetching from WhatsApp only when an entry is missing or expired.
+type AvatarHandler struct {
+	client     *whatsapp.Client
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]*avatarEntry
+}
+
+// NewAvatarHandler creates a new avatar handler.
+func NewAvatarHandler(client *whatsapp.Client) *AvatarHandler {
+	return &AvatarHandler{
+		client:     client,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		cache:      make(map[string]*avatarEntry),
+	}
+}
+
+// HandleAvatar handles GET /api/contacts/{jid}/avatar. Contacts without a visible
+// picture return 204. While disconnected only cached pictures are served.
+func (h *AvatarHandler) HandleAvatar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/contacts/")
+	jid, ok := pathJID(w, r, strings.TrimSuffix(path, "/avatar"))
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	entry := h.cache[jid]
+	h.mu.Unlock()
+
+	fresh := entry != nil && time.Since(entry.fetchedAt) < avatarTTL
+	if !fresh && h.client.IsConnected() {
+		fetched, err := h.fetch(r.Context(), jid, entry)
+		if err != nil {
+			logging.FromContext(r.Context()).Warn("Failed to fetch avatar", "jid", jid, "error", err)
+		} else {
+			entry = fetched
+			h.store(jid, entry)
+		}
+	}
+
+	if entry == nil || entry.missing {
+		w.Header().Set("Cache-Control", "private, max-age=300")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	etag := fmt.Sprintf("%q", entry.id)
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(avatarTTL.Seconds())))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", entry.contentType)
+	w.Write(entry.data)
+}
+
+// fetch asks WhatsApp for the current picture, reusing the cached image when its ID is unchanged.
+func (h *AvatarHandler) fetch(ctx context.Context, jid string, previous *avatarEntry) (*avatarEntry, error) {
+	pic, err := h.client.GetProfilePicture(ctx, jid)
+	if errors.Is(err, whatsapp.ErrNoProfilePicture) {
+		return &avatarEntry{missing: true, fetchedAt: time.Now()}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != nil && !previous.missing && previous.id == pic.ID {
+		refreshed := *previous
+		refreshed.fetchedAt = time.Now()
+		return &refreshed, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pic.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download picture: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("picture download returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAvatarBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read picture: %w", err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	return &avatarEntry{
+		id:          pic.ID,
+		contentType: contentType,
+		data:        data,
+		fetchedAt:   time.Now(),
+	}, nil
+}
+
+func (h *AvatarHandler) store(jid string, entry *avatarEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.cache) >= maxAvatarEntries {
+		for key, e := range h.cache {
+			if time.Since(e.fetchedAt) >= avatarTTL {
+				delete(h.cache, key)
+			}
+		}
+	}
+	if _, exists := h.cache[jid]; !exists && len(h.cache) >= maxAvatarEntries {
+		return // Still full of fresh entries; serve without caching
+	}
+	h.cache[jid] = entry
+}
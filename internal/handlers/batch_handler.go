@@ -3,58 +3,182 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"friday/internal/batch"
+	"friday/internal/logging"
 	"friday/internal/models"
+	"friday/internal/template"
 	"friday/internal/whatsapp"
 )
 
+const (
+	// ClientNameHeader labels the batches a client creates; the client_name body
+	// field does the same for callers that cannot set headers.
+	ClientNameHeader = "X-Client-Name"
+	// maxClientNameLength bounds the stored label.
+	maxClientNameLength = 100
+)
+
 type BatchHandler struct {
 	batchRepo  *models.BatchRunRepository
 	msgRepo    *models.BatchMessageRepository
+	eventRepo  *models.BatchEventRepository
+	recipientRepo *models.BatchRecipientRepository
 	groupRepo  *models.GroupRepository
 	memberRepo *models.GroupMemberRepository
 	draftRepo  *models.DraftRepository
+	variantRepo *models.DraftVariantRepository
+	attrRepo   *models.AttributeRepository
+	groupAttrRepo *models.GroupAttributeRepository
+	quarantineRepo *models.QuarantineRepository
+	settingsRepo *models.SettingsRepository
 	worker     *batch.Worker
-	waClient   *whatsapp.Client
+	waClient   whatsapp.Messenger
+	defaultTimezone string // FRIDAY_TIMEZONE, for {{date}} in labels; the batch.timezone setting takes precedence
 }
 
 // NewBatchHandler creates a new batch handler with required dependencies.
 func NewBatchHandler(
 	batchRepo *models.BatchRunRepository,
 	msgRepo *models.BatchMessageRepository,
+	eventRepo *models.BatchEventRepository,
+	recipientRepo *models.BatchRecipientRepository,
 	groupRepo *models.GroupRepository,
 	memberRepo *models.GroupMemberRepository,
 	draftRepo *models.DraftRepository,
+	variantRepo *models.DraftVariantRepository,
+	attrRepo *models.AttributeRepository,
+	groupAttrRepo *models.GroupAttributeRepository,
+	quarantineRepo *models.QuarantineRepository,
+	settingsRepo *models.SettingsRepository,
 	worker *batch.Worker,
-	waClient *whatsapp.Client,
+	waClient whatsapp.Messenger,
+	defaultTimezone string,
 ) *BatchHandler {
 	return &BatchHandler{
 		batchRepo:  batchRepo,
 		msgRepo:    msgRepo,
+		eventRepo:  eventRepo,
+		recipientRepo: recipientRepo,
 		groupRepo:  groupRepo,
 		memberRepo: memberRepo,
 		draftRepo:  draftRepo,
+		variantRepo: variantRepo,
+		attrRepo:   attrRepo,
+		groupAttrRepo: groupAttrRepo,
+		quarantineRepo: quarantineRepo,
+		settingsRepo: settingsRepo,
 		worker:     worker,
 		waClient:   waClient,
+		defaultTimezone: defaultTimezone,
 	}
 }
 
+// RegisterCapabilities adds the optional parts of sending batches: documents,
+// quiet hours and completion webhooks.
+func (h *BatchHandler) RegisterCapabilities(c *Capabilities) {
+	c.Register(CapabilityDocuments, h.worker.DocumentsEnabled)
+	c.Register(CapabilityQuietHours, h.worker.QuietHoursEnabled)
+	c.Register(CapabilityRecipientCap, func() bool {
+		recipientCap, err := batch.LoadRecipientCap(h.settingsRepo)
+		return err == nil && recipientCap.Enabled()
+	})
+	c.Register(CapabilityWebhooks, settingSet(h.settingsRepo, models.SettingNotifyWebhookURL))
+}
+
 // Request/Response types
 
 type CreateBatchRequest struct {
-	DraftID int64 `json:"draft_id"`
-	GroupID int64 `json:"group_id"`
+	DraftID  int64 `json:"draft_id"`
+	GroupID  int64 `json:"group_id"`
+	Validate bool  `json:"validate"` // Check members are on WhatsApp before queueing
+	DryRun   bool  `json:"dry_run"`  // Report recipient counts without creating the batch
+	SpinSeed int64 `json:"spin_seed"` // Reuse a dry run's seed to send the spintax it showed; 0 picks a new one
+	ClientName string `json:"client_name,omitempty"` // Label stored with the batch; X-Client-Name takes precedence
+	Label    string `json:"label,omitempty"` // Display name; may use {{date}}, {{group}} and {{draft}}. Defaults to "<draft> → <group>"
+	DocumentAttribute string `json:"document_attribute,omitempty"` // Attribute naming each recipient's file in the documents directory; the message becomes its caption
+	Sort     string `json:"sort,omitempty"` // Send order: group_order (default), name or random
+	SortSeed int64  `json:"sort_seed"` // Reuse a dry run's seed to send in the random order it showed; 0 picks a new one
+	AllowSelf bool  `json:"allow_self"` // Message the linked account too when it is a member, instead of leaving it out
+
+	// Source overrides the source worked out from the request; set by automated creators
+	Source string `json:"-"`
+}
+
+// GroupSendRequest is the optional body of POST /api/groups/{id}/send.
+type GroupSendRequest struct {
+	DraftID  int64 `json:"draft_id"` // Overrides the group's default draft
+	Validate bool  `json:"validate"`
+	DryRun   bool  `json:"dry_run"`
+	SpinSeed int64 `json:"spin_seed"`
+	ClientName string `json:"client_name,omitempty"`
+	Label    string `json:"label,omitempty"`
+	DocumentAttribute string `json:"document_attribute,omitempty"`
+	Sort     string `json:"sort,omitempty"`
+	SortSeed int64  `json:"sort_seed"`
+	AllowSelf bool  `json:"allow_self"`
+}
+
+// BatchPlan summarizes which content each recipient of a batch would receive.
+type BatchPlan struct {
+	TotalCount           int             `json:"total_count"`
+	VariantCounts        map[string]int  `json:"variant_counts,omitempty"` // "lang=en" -> recipients
+	ParentCount          int             `json:"parent_count"`             // Recipients receiving the parent draft content
+	MissingSelectorCount int             `json:"missing_selector_count"`   // Recipients lacking every selector attribute
+	QuarantinedCount     int             `json:"quarantined_count"` // Members left out because they are quarantined
+	BlockedCount         int             `json:"blocked_count,omitempty"` // Members left out because the linked account has blocked them
+	CappedCount          int             `json:"capped_count,omitempty"` // Recipients at batch.recipient_cap, whose messages are created skipped
+	SelfSkipped          bool            `json:"self_skipped,omitempty"` // The linked account is a member and was left out, see allow_self
+	UnresolvedLIDCount   int             `json:"unresolved_lid_count"` // Hidden numbers (LIDs) without a known phone number, created failed
+	MissingDocumentCount int             `json:"missing_document_count,omitempty"` // Recipients whose document cannot be sent as things stand; they would fail
+	MissingDocuments     []MissingDocument `json:"missing_documents,omitempty"` // Dry runs only
+	Capped               []CappedRecipient `json:"capped,omitempty"` // Dry runs only, in send order
+	SpinSeed             int64           `json:"spin_seed"`
+	Sort                 string          `json:"sort"`
+	SortSeed             int64           `json:"sort_seed,omitempty"` // Random order only
+	Label                string          `json:"label"`
+	ContentHash          string          `json:"content_hash"` // The draft the plan was made from, see MessageDraft.ContentHash
+	Recipients           []RecipientPlan `json:"recipients,omitempty"` // Dry runs only, in send order
+}
+
+// MissingDocument is a recipient whose document could not be sent.
+type MissingDocument struct {
+	JID      string `json:"jid"`
+	Document string `json:"document"` // The attribute value, empty when the recipient has none
+	Error    string `json:"error"`
+}
+
+// CappedRecipient is a recipient already sent batch.recipient_cap messages
+// within batch.recipient_cap_days.
+type CappedRecipient struct {
+	JID       string `json:"jid"`
+	SentCount int    `json:"sent_count"`
+}
+
+// RecipientPlan is the content one recipient would receive, with spintax resolved
+// and placeholders left unfilled.
+type RecipientPlan struct {
+	JID     string `json:"jid"`
+	Variant string `json:"variant,omitempty"` // "lang=en", empty for the parent content
+	Content string `json:"content"`
 }
 
 type BatchResponse struct {
 	Success bool              `json:"success"`
-	Message string            `json:"message"`
+	Message string            `json:"message"` // Informational; on creation read the queue fields below rather than parsing it
 	Batch   *models.BatchRun  `json:"batch,omitempty"`
+	Plan    *BatchPlan        `json:"plan,omitempty"`
+	QueuePosition *int        `json:"queue_position,omitempty"` // On creation: place in line for a free slot, 0 when it starts right away; absent while preparing
+	BatchesAhead  *int        `json:"batches_ahead,omitempty"`  // On creation: running and earlier queued batches it waits for, 0 when it starts right away
+	EstimatedStartAt *time.Time `json:"estimated_start_at,omitempty"` // On creation, for a queued batch: from the running batches' ETAs; absent while none is running
+	Warnings []template.Issue `json:"warnings,omitempty"` // Formatting and length problems of the content sent; they do not block the batch
 }
 
 type BatchListResponse struct {
@@ -62,6 +186,7 @@ type BatchListResponse struct {
 	Message string             `json:"message"`
 	Batches []models.BatchRun  `json:"batches"`
 	Count   int                `json:"count"`
+	Interrupted []int64        `json:"interrupted"` // Listed batches held after a restart, waiting for POST /api/batch-runs/{id}/resume
 }
 
 type BatchDetailResponse struct {
@@ -69,13 +194,72 @@ type BatchDetailResponse struct {
 	Message  string                 `json:"message"`
 	Batch    *models.BatchRun       `json:"batch,omitempty"`
 	Messages []models.BatchMessage  `json:"messages,omitempty"`
+	Stats    *models.BatchRunStats  `json:"stats,omitempty"` // Final pace figures, once the run has finished
+	FailuresByCode map[string]int   `json:"failures_by_code,omitempty"` // Failed messages per failure code, e.g. "not_on_whatsapp"
+	Delivery *models.DeliveryCounts `json:"delivery,omitempty"` // Sent messages by delivery receipt; undelivered ones may have been blocked
+	DraftModified *bool             `json:"draft_modified,omitempty"` // The draft's content, variants or delimiters changed since the batch was created; unset when unknown
+}
+
+type BatchRecipientsResponse struct {
+	Success    bool                    `json:"success"`
+	Message    string                  `json:"message"`
+	Recipients []models.BatchRecipient `json:"recipients"`
+	Count      int                     `json:"count"`
+}
+
+type BatchMessagesResponse struct {
+	Success  bool                  `json:"success"`
+	Message  string                `json:"message,omitempty"`
+	Messages []models.BatchMessage `json:"messages"`
+	Count    int                   `json:"count"`
+}
+
+// EditMessageRequest is the body of PUT /api/batch-runs/{id}/messages/{messageId}.
+type EditMessageRequest struct {
+	TemplateContent *string `json:"template_content"` // Rendered like the draft content it replaces: spintax and placeholders are resolved at send time
+}
+
+type BatchMessageResponse struct {
+	Success  bool                 `json:"success"`
+	Message  string               `json:"message"`
+	BatchMessage *models.BatchMessage `json:"batch_message,omitempty"`
+	Warnings []template.Issue     `json:"warnings,omitempty"` // Lint results; errors here block the edit
+}
+
+type BatchEventsResponse struct {
+	Success bool                `json:"success"`
+	Message string              `json:"message"`
+	Events  []models.BatchEvent `json:"events"`
+	Count   int                 `json:"count"`
 }
 
+// ActiveBatchResponse reports the running batches. Batch and Progress describe
+// the oldest one, Active lists all of them in start order and Queued the
+// batches waiting behind them, next first.
 type ActiveBatchResponse struct {
 	Success   bool                  `json:"success"`
 	HasActive bool                  `json:"has_active"`
 	Batch     *models.BatchRun      `json:"batch,omitempty"`
 	Progress  *batch.ProgressEvent  `json:"progress,omitempty"`
+	Active    []ActiveBatch         `json:"active,omitempty"`
+	QueuedCount int                 `json:"queued_count"`
+	Queued    []QueuedBatch         `json:"queued,omitempty"`
+}
+
+type ActiveBatch struct {
+	Batch    *models.BatchRun     `json:"batch"`
+	Progress *batch.ProgressEvent `json:"progress,omitempty"`
+}
+
+// QueuedBatch summarizes a batch waiting for a free slot.
+type QueuedBatch struct {
+	ID         int64      `json:"id"`
+	Position   int        `json:"position"` // 1 for the next batch to start
+	Label      string     `json:"label"`
+	DraftTitle string     `json:"draft_title"`
+	GroupName  string     `json:"group_name"`
+	TotalCount int        `json:"total_count"`
+	EstimatedStartAt *time.Time `json:"estimated_start_at,omitempty"` // From the running batches' ETAs; absent while none is running
 }
 
 // HandleBatches handles GET /api/batch-runs (list) and POST /api/batch-runs (create)
@@ -91,7 +275,9 @@ func (h *BatchHandler) HandleBatches(w http.ResponseWriter, r *http.Request) {
 }
 
 // HandleBatch handles single batch operations: GET/DELETE /api/batch-runs/{id}
-// Also handles: POST /api/batch-runs/{id}/cancel and GET /api/batch-runs/{id}/stream
+// Also handles: POST /api/batch-runs/{id}/cancel, POST /api/batch-runs/{id}/resume,
+// POST /api/batch-runs/{id}/clone, GET /api/batch-runs/{id}/stream,
+// GET /api/batch-runs/{id}/progress and GET /api/batch-runs/{id}/events
 func (h *BatchHandler) HandleBatch(w http.ResponseWriter, r *http.Request) {
 	// Extract path after /api/batch-runs/
 	path := strings.TrimPrefix(r.URL.Path, "/api/batch-runs/")
@@ -101,32 +287,123 @@ func (h *BatchHandler) HandleBatch(w http.ResponseWriter, r *http.Request) {
 		h.getActiveBatch(w, r)
 		return
 	}
+	if path == "preflight" {
+		h.preflight(w, r)
+		return
+	}
 
 	// Check for /cancel or /stream suffix
 	if strings.Contains(path, "/cancel") {
 		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/cancel"), 10, 64)
 		if err != nil {
-			jsonError(w, "Invalid batch ID", http.StatusBadRequest)
+			jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
 			return
 		}
 		h.cancelBatch(w, r, id)
 		return
 	}
 
+	if strings.HasSuffix(path, "/resume") {
+		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/resume"), 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
+			return
+		}
+		h.resumeBatch(w, r, id)
+		return
+	}
+
+	if strings.Contains(path, "/clone") {
+		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/clone"), 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
+			return
+		}
+		h.cloneBatch(w, r, id)
+		return
+	}
+
 	if strings.Contains(path, "/stream") {
 		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/stream"), 10, 64)
 		if err != nil {
-			jsonError(w, "Invalid batch ID", http.StatusBadRequest)
+			jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
 			return
 		}
 		h.streamBatch(w, r, id)
 		return
 	}
 
+	if strings.HasSuffix(path, "/events") {
+		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/events"), 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
+			return
+		}
+		h.getBatchEvents(w, r, id)
+		return
+	}
+
+	if strings.HasSuffix(path, "/progress") {
+		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/progress"), 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
+			return
+		}
+		h.getBatchProgress(w, r, id)
+		return
+	}
+
+	if strings.HasSuffix(path, "/recipients") {
+		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/recipients"), 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
+			return
+		}
+		h.getBatchRecipients(w, r, id)
+		return
+	}
+
+	// {id}/messages/{messageId}/skip
+	if strings.HasSuffix(path, "/skip") {
+		parts := strings.Split(strings.TrimSuffix(path, "/skip"), "/")
+		if len(parts) != 3 || parts[1] != "messages" {
+			jsonError(w, tr(r, "not_found"), http.StatusNotFound)
+			return
+		}
+		id, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
+			return
+		}
+		messageID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_message_id"), http.StatusBadRequest)
+			return
+		}
+		h.skipMessage(w, r, id, messageID)
+		return
+	}
+
+	// {id}/messages/{messageId}
+	if parts := strings.Split(path, "/"); len(parts) == 3 && parts[1] == "messages" {
+		id, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
+			return
+		}
+		messageID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_message_id"), http.StatusBadRequest)
+			return
+		}
+		h.editMessage(w, r, id, messageID)
+		return
+	}
+
 	if strings.Contains(path, "/messages") {
 		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/messages"), 10, 64)
 		if err != nil {
-			jsonError(w, "Invalid batch ID", http.StatusBadRequest)
+			jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
 			return
 		}
 		h.getBatchMessages(w, r, id)
@@ -136,7 +413,7 @@ func (h *BatchHandler) HandleBatch(w http.ResponseWriter, r *http.Request) {
 	// Parse batch ID
 	id, err := strconv.ParseInt(path, 10, 64)
 	if err != nil {
-		jsonError(w, "Invalid batch ID", http.StatusBadRequest)
+		jsonError(w, tr(r, "invalid_batch_id"), http.StatusBadRequest)
 		return
 	}
 
@@ -150,321 +427,1384 @@ func (h *BatchHandler) HandleBatch(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// listBatches handles GET /api/batch-runs; ?source= keeps only the batches
+// created by one source.
 func (h *BatchHandler) listBatches(w http.ResponseWriter, r *http.Request) {
-	batches, err := h.batchRepo.GetAll()
+	source := strings.TrimSpace(r.URL.Query().Get("source"))
+	batches, err := h.batchRepo.GetAll(workspaceID(r), source)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchListResponse{
+		writeJSON(w, http.StatusInternalServerError, BatchListResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to retrieve batches: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(BatchListResponse{
+	interrupted := []int64{}
+	for _, b := range batches {
+		if b.Status == models.BatchStatusInterrupted {
+			interrupted = append(interrupted, b.ID)
+		}
+	}
+
+	message := "Batches retrieved successfully"
+	if len(interrupted) > 0 {
+		message = fmt.Sprintf("%d batch(es) interrupted by a restart; resume or cancel them", len(interrupted))
+	}
+
+	writeJSON(w, http.StatusOK, BatchListResponse{
 		Success: true,
-		Message: "Batches retrieved successfully",
+		Message: message,
 		Batches: batches,
 		Count:   len(batches),
+		Interrupted: interrupted,
 	})
 }
 
 func (h *BatchHandler) createBatch(w http.ResponseWriter, r *http.Request) {
 	var req CreateBatchRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(BatchResponse{
-			Success: false,
-			Message: fmt.Sprintf("Invalid JSON: %v", err),
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// Validate draft exists
-	draft, err := h.draftRepo.GetByID(req.DraftID)
+	draft, err := h.draftRepo.GetInWorkspace(workspaceID(r), req.DraftID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchResponse{
+		writeJSON(w, http.StatusInternalServerError, BatchResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to check draft: %v", err),
 		})
 		return
 	}
 	if draft == nil {
-		jsonError(w, "Draft not found", http.StatusNotFound)
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
 		return
 	}
 
 	// Validate group exists
-	group, err := h.groupRepo.GetByID(req.GroupID)
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), req.GroupID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchResponse{
+		writeJSON(w, http.StatusInternalServerError, BatchResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to check group: %v", err),
 		})
 		return
 	}
 	if group == nil {
-		jsonError(w, "Group not found", http.StatusNotFound)
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+		return
+	}
+
+	h.queueBatch(w, r, draft, group, req)
+}
+
+// queueBatch expands the group's current members into a new queued batch and
+// writes the created response. Shared by create and clone.
+func (h *BatchHandler) queueBatch(w http.ResponseWriter, r *http.Request, draft *models.MessageDraft, group *models.ContactGroup, req CreateBatchRequest) {
+	clientName, ok := requestClientName(w, r, req.ClientName)
+	if !ok {
+		return
+	}
+	source := req.Source
+	if source == "" {
+		source = requestSource(r)
+	}
+	label, ok := h.renderLabel(w, r, req.Label, draft, group)
+	if !ok {
+		return
+	}
+	sortOrder := req.Sort
+	if sortOrder == "" {
+		sortOrder = models.BatchSortGroupOrder
+	}
+	if !slices.Contains(models.BatchSorts, sortOrder) {
+		jsonError(w, tr(r, "batch_invalid_sort", strings.Join(models.BatchSorts, ", ")), http.StatusBadRequest)
 		return
 	}
+	if req.DocumentAttribute != "" {
+		if !h.worker.DocumentsEnabled() {
+			capabilityDisabled(w, tr(r, "documents_disabled"), CapabilityDocuments)
+			return
+		}
+		if !validAttributeKey(req.DocumentAttribute) {
+			jsonError(w, tr(r, "document_attribute_invalid"), http.StatusBadRequest)
+			return
+		}
+	}
 
 	// Check group has members
 	if group.MemberCount == 0 {
-		jsonError(w, "Group has no members", http.StatusBadRequest)
+		jsonError(w, tr(r, "group_no_members"), http.StatusBadRequest)
 		return
 	}
 
 	// Get group members
-	members, err := h.memberRepo.GetByGroup(req.GroupID)
+	members, err := h.memberRepo.GetByGroup(group.ID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchResponse{
+		writeJSON(w, http.StatusInternalServerError, BatchResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to get group members: %v", err),
 		})
 		return
 	}
 
-	// Create batch run
-	batchRun := &models.BatchRun{
-		DraftID:    req.DraftID,
-		GroupID:    req.GroupID,
-		GroupName:  group.Name,
-		DraftTitle: draft.Title,
-		Status:     models.BatchStatusQueued,
-		TotalCount: len(members),
-	}
-
-	if err := h.batchRepo.Create(batchRun); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to create batch: %v", err),
-		})
-		return
-	}
-
-	// Create batch messages for each member
-	messages := make([]models.BatchMessage, len(members))
-	for i, member := range members {
-		// Try to get contact name
-		var contactName *string
-		if h.waClient.IsConnected() {
-			contact, _ := h.waClient.FindContactByJID(member.JID)
-			if contact != nil {
-				contactName = &contact.Name
+	// Try to get contact names, which also sort by name
+	contactNames := make(map[string]*string, len(members))
+	if h.waClient.IsConnected() {
+		contacts := whatsapp.ContactsByJID(h.waClient)
+		for _, member := range members {
+			if contact, ok := contacts[member.JID]; ok {
+				contactNames[member.JID] = &contact.Name
 			}
 		}
+	}
 
-		messages[i] = models.BatchMessage{
-			BatchRunID:      batchRun.ID,
-			JID:             member.JID,
-			ContactName:     contactName,
-			Status:          models.MessageStatusPending,
-			TemplateContent: draft.Content,
+	// Messages are created, and so sent, in this order
+	sortSeed := int64(0)
+	if sortOrder == models.BatchSortRandom {
+		sortSeed = req.SortSeed
+		for sortSeed == 0 {
+			sortSeed = rand.Int63()
 		}
 	}
+	sortMembers(members, sortOrder, sortSeed, contactNames)
 
-	if err := h.msgRepo.CreateMultiple(messages); err != nil {
-		// Clean up the batch run
-		h.batchRepo.Delete(batchRun.ID)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to create batch messages: %v", err),
-		})
+	selection, ok := h.selectRecipients(w, r, members, req.AllowSelf)
+	if !ok {
 		return
 	}
+	members = selection.eligible
 
-	// Check if there's already an active batch
-	activeBatchID := h.worker.GetActiveBatchID()
-	message := "Batch queued successfully"
-	if activeBatchID == 0 {
-		message = "Batch started"
-	} else {
-		message = fmt.Sprintf("Batch queued (waiting for batch #%d to complete)", activeBatchID)
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(BatchResponse{
-		Success: true,
-		Message: message,
-		Batch:   batchRun,
-	})
-}
-
-func (h *BatchHandler) getBatch(w http.ResponseWriter, r *http.Request, id int64) {
-	batchRun, err := h.batchRepo.GetByID(id)
+	// Resolve per-recipient content (draft variants)
+	plan, contents, err := h.planRecipients(draft, group.ID, members)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to retrieve batch: %v", err),
-		})
+		jsonError(w, fmt.Sprintf("Failed to resolve draft variants: %v", err), http.StatusInternalServerError)
 		return
 	}
+	plan.QuarantinedCount = selection.quarantinedCount
+	plan.BlockedCount = selection.blockedCount
+	plan.SelfSkipped = len(selection.self) > 0
+	warnings := contentWarnings(h.attrRepo, h.settingsRepo, draft.Delimiters, contents...)
+
+	if req.DocumentAttribute != "" {
+		if plan.MissingDocuments, err = h.checkDocuments(group.ID, members, req.DocumentAttribute); err != nil {
+			jsonError(w, fmt.Sprintf("Failed to check documents: %v", err), http.StatusInternalServerError)
+			return
+		}
+		plan.MissingDocumentCount = len(plan.MissingDocuments)
+	}
 
-	if batchRun == nil {
-		jsonError(w, "Batch not found", http.StatusNotFound)
+	sendJIDs, unresolved, err := h.sendJIDs(members)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to resolve hidden numbers: %v", err), http.StatusInternalServerError)
 		return
 	}
+	plan.UnresolvedLIDCount = unresolved
 
-	// Get messages
-	messages, err := h.msgRepo.GetByBatchRun(id)
+	// Recipients at the recipient cap get a message created skipped, so the
+	// batch records why they were not sent one
+	recipientCap, err := batch.LoadRecipientCap(h.settingsRepo)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to retrieve messages: %v", err),
-		})
+		jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(BatchDetailResponse{
-		Success:  true,
-		Message:  "Batch retrieved successfully",
-		Batch:    batchRun,
-		Messages: messages,
-	})
-}
-
-func (h *BatchHandler) getBatchMessages(w http.ResponseWriter, r *http.Request, id int64) {
-	messages, err := h.msgRepo.GetByBatchRun(id)
+	capped, err := recipientCap.Reached(h.msgRepo, sendJIDs)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  false,
-			"message":  fmt.Sprintf("Failed to retrieve messages: %v", err),
-		})
+		jsonError(w, fmt.Sprintf("Failed to check the recipient cap: %v", err), http.StatusInternalServerError)
 		return
 	}
+	plan.CappedCount = len(capped)
+	if plan.CappedCount > 0 {
+		distinct := make(map[string]bool, len(sendJIDs))
+		for _, jid := range sendJIDs {
+			distinct[jid] = true
+		}
+		if len(distinct) == plan.CappedCount {
+			jsonError(w, tr(r, "batch_all_capped", plan.CappedCount, recipientCap.Days), http.StatusConflict)
+			return
+		}
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":  true,
-		"messages": messages,
-		"count":    len(messages),
-	})
-}
-
-func (h *BatchHandler) cancelBatch(w http.ResponseWriter, r *http.Request, id int64) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+	plan.SpinSeed = req.SpinSeed
+	for plan.SpinSeed == 0 {
+		plan.SpinSeed = rand.Int63()
 	}
+	plan.Sort = sortOrder
+	plan.SortSeed = sortSeed
+	plan.Label = label
 
-	// Check batch exists
-	batchRun, err := h.batchRepo.GetByID(id)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to check batch: %v", err),
+	if req.DryRun || r.URL.Query().Get("dry_run") == "true" {
+		for i := range plan.Recipients {
+			plan.Recipients[i].Content, _ = draft.Delimiters.Spin(contents[i], template.SpinSeed(plan.SpinSeed, members[i].JID))
+		}
+		listed := make(map[string]bool, len(capped))
+		for _, jid := range sendJIDs {
+			if count, ok := capped[jid]; ok && !listed[jid] {
+				listed[jid] = true
+				plan.Capped = append(plan.Capped, CappedRecipient{JID: jid, SentCount: count})
+			}
+		}
+
+		writeJSON(w, http.StatusOK, BatchResponse{
+			Success: true,
+			Message: batchSummary(r, tr(r, "batch_dry_run", plan.TotalCount), plan, len(warnings), nil),
+			Plan:    plan,
+			Warnings: warnings,
 		})
 		return
 	}
 
-	if batchRun == nil {
-		jsonError(w, "Batch not found", http.StatusNotFound)
+	// Dry runs only plan, so they stay available in read-only mode
+	if h.waClient.IsReadOnly() {
+		jsonError(w, readOnlyMessage, http.StatusServiceUnavailable)
 		return
 	}
 
-	// Can only cancel queued or running batches
-	if batchRun.Status != models.BatchStatusQueued && batchRun.Status != models.BatchStatusRunning {
-		jsonError(w, fmt.Sprintf("Cannot cancel batch with status: %s", batchRun.Status), http.StatusBadRequest)
-		return
+	// Create batch run
+	batchRun := &models.BatchRun{
+		WorkspaceID: workspaceID(r),
+		DraftID:    draft.ID,
+		GroupID:    group.ID,
+		GroupName:  group.Name,
+		DraftTitle: draft.Title,
+		Status:     models.BatchStatusQueued,
+		TotalCount: len(members),
+		QuarantinedCount: selection.quarantinedCount,
+		SpinSeed:   plan.SpinSeed,
+		Sort:       sortOrder,
+		SortSeed:   sortSeed,
+		Source:     source,
+		ClientName: clientName,
+		Label:      label,
+		DocumentAttribute: req.DocumentAttribute,
+		ContentHash: plan.ContentHash,
+		Delimiters: &draft.Delimiters,
+	}
+	plan.Recipients = nil
+	plan.MissingDocuments = nil
+	plan.Capped = nil
+
+	messages, cappedMembers := batchMessages(members, sendJIDs, contents, contactNames, capped, recipientCap)
+	batchRun.TotalCount = len(messages)
+	batchRun.SkippedCount = plan.CappedCount
+	recipients := selection.snapshot(contactNames, cappedMembers)
+
+	// Batches with more messages than one chunk are created preparing and get
+	// them from the worker, so the request does not wait for every row
+	validate := req.Validate || r.URL.Query().Get("validate") == "true"
+	preparing := len(messages) > batch.PrepareChunkSize
+	createMessages, createRecipients := messages, recipients
+	if preparing {
+		batchRun.Status = models.BatchStatusPreparing
+		createMessages, createRecipients = nil, nil
 	}
 
-	if err := h.worker.CancelBatch(id); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchResponse{
+	// The run, its messages and the snapshot are written together, so a failure
+	// part way leaves nothing behind for the worker to pick up
+	if err := h.batchRepo.CreateWithMessages(batchRun, createMessages, createRecipients); err != nil {
+		writeJSON(w, http.StatusInternalServerError, BatchResponse{
 			Success: false,
-			Message: fmt.Sprintf("Failed to cancel batch: %v", err),
+			Message: fmt.Sprintf("Failed to create batch: %v", err),
 		})
 		return
 	}
+	if preparing {
+		h.worker.Prepare(batchRun, messages, recipients, validate)
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(BatchResponse{
-		Success: true,
-		Message: "Batch cancelled successfully",
-	})
+	lidFailed := 0
+	if plan.UnresolvedLIDCount > 0 && !preparing {
+		if lidFailed, err = h.worker.FailUnresolvedLIDs(batchRun.ID); err != nil {
+			logging.FromContext(r.Context()).Warn("Failed to check hidden numbers", "batch_id", batchRun.ID, "error", err)
+		} else if refreshed, err := h.batchRepo.GetByID(batchRun.ID); err == nil && refreshed != nil {
+			batchRun = refreshed
+		}
+	}
+
+	// Optional early validation; the worker validates again (from cache) at start
+	if validate && !preparing {
+		if _, err := h.worker.ValidateRecipients(batchRun.ID); err != nil {
+			logging.FromContext(r.Context()).Warn("Recipient validation failed", "batch_id", batchRun.ID, "error", err)
+		} else if refreshed, err := h.batchRepo.GetByID(batchRun.ID); err == nil && refreshed != nil {
+			batchRun = refreshed
+		}
+	}
+
+	h.writeCreated(w, r, batchRun, plan, warnings, preparing, len(messages), lidFailed)
 }
 
-func (h *BatchHandler) deleteBatch(w http.ResponseWriter, r *http.Request, id int64) {
-	found, err := h.batchRepo.Delete(id)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(BatchResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to delete batch: %v", err),
-		})
-		return
+// writeCreated writes the response for a batch just created: where it stands
+// in the queue and what happened to the recipients left out or already failed.
+func (h *BatchHandler) writeCreated(w http.ResponseWriter, r *http.Request, batchRun *models.BatchRun, plan *BatchPlan, warnings []template.Issue, preparing bool, messageCount, lidFailed int) {
+	// Free slots next to the running batches take the first queued batches right
+	// away; the rest wait for the running batches and those queued before them.
+	// A preparing batch is not in the queue until its messages exist
+	activeBatchIDs := h.worker.GetActiveBatchIDs()
+	queuePosition, batchesAhead := 0, 0
+	var estimatedStart *time.Time
+	if queued, err := h.batchRepo.GetQueued(); err != nil {
+		logging.FromContext(r.Context()).Warn("Failed to get queue position", "batch_id", batchRun.ID, "error", err)
+	} else if i := slices.IndexFunc(queued, func(run models.BatchRun) bool { return run.ID == batchRun.ID }); i >= 0 {
+		queuePosition = max(i+1-(h.worker.MaxConcurrent()-len(activeBatchIDs)), 0)
+		if queuePosition > 0 {
+			batchesAhead = len(activeBatchIDs) + i
+			estimatedStart = h.worker.EstimateQueueStarts(queued)[i]
+		}
 	}
 
-	if !found {
-		jsonError(w, "Batch not found or currently running", http.StatusNotFound)
-		return
+	headline := tr(r, "batch_started")
+	if preparing {
+		headline = tr(r, "batch_preparing", messageCount)
+	} else if queuePosition > 0 && len(activeBatchIDs) > 0 {
+		headline = tr(r, "batch_queued_behind", activeBatchIDs[0])
+	} else if queuePosition > 0 {
+		headline = tr(r, "batch_queued")
 	}
+	outcome := &batchOutcome{notOnWhatsApp: batchRun.ValidationFailedCount - lidFailed, lidFailed: lidFailed}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(BatchResponse{
+	response := BatchResponse{
 		Success: true,
-		Message: "Batch deleted successfully",
-	})
+		Message: batchSummary(r, headline, plan, len(warnings), outcome),
+		Batch:   batchRun,
+		Plan:    plan,
+		EstimatedStartAt: estimatedStart,
+		Warnings: warnings,
+	}
+	if !preparing {
+		response.QueuePosition = &queuePosition
+		response.BatchesAhead = &batchesAhead
+	}
+	writeJSON(w, http.StatusCreated, response)
 }
 
-func (h *BatchHandler) getActiveBatch(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+// recipientSelection is a group's members split into those a batch sends to
+// and those it leaves out.
+type recipientSelection struct {
+	members          []models.GroupMember // Every member, in send order
+	eligible         []models.GroupMember // The members the batch sends to
+	quarantined      map[string]bool
+	blocked          map[string]bool
+	self             map[string]bool // The linked account, left out without allow_self
+	quarantinedCount int
+	blockedCount     int
+}
+
+// selectRecipients leaves out of members the quarantined ones, those the linked
+// account has blocked, which WhatsApp would not deliver to, and unless allowSelf
+// the linked account, which would only message its own chat. When a member's
+// JID is malformed or nobody is left it writes the error response and returns
+// false.
+func (h *BatchHandler) selectRecipients(w http.ResponseWriter, r *http.Request, members []models.GroupMember, allowSelf bool) (*recipientSelection, bool) {
+	// Members stored before JIDs were normalized may be malformed and would only fail at send time
+	memberJIDs := make([]string, len(members))
+	for i, member := range members {
+		memberJIDs[i] = member.JID
+	}
+	if _, err := normalizeJIDList(memberJIDs); err != nil {
+		jsonError(w, tr(r, "batch_members_invalid_jids", err), http.StatusBadRequest)
+		return nil, false
 	}
 
-	activeBatch, err := h.batchRepo.GetActive()
+	quarantined, err := h.quarantineRepo.QuarantinedJIDs()
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ActiveBatchResponse{
-			Success: false,
-		})
-		return
+		jsonError(w, fmt.Sprintf("Failed to get quarantined contacts: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	s := &recipientSelection{
+		members:     members,
+		eligible:    make([]models.GroupMember, 0, len(members)),
+		quarantined: quarantined,
+		blocked:     blockedMembers(r, h.waClient),
+		self:        make(map[string]bool),
+	}
+	for _, member := range members {
+		switch {
+		case s.quarantined[member.JID]:
+			s.quarantinedCount++
+		case s.blocked[member.JID]:
+			s.blockedCount++
+		case !allowSelf && isSelf(h.waClient, member.JID):
+			s.self[member.JID] = true
+		default:
+			s.eligible = append(s.eligible, member)
+		}
 	}
 
-	if activeBatch == nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(ActiveBatchResponse{
-			Success:   true,
-			HasActive: false,
-		})
-		return
+	if len(s.eligible) == 0 {
+		switch {
+		case len(s.self) > 0:
+			jsonError(w, tr(r, "batch_only_self"), http.StatusConflict)
+		case s.blockedCount > 0:
+			jsonError(w, tr(r, "batch_all_blocked", s.quarantinedCount+s.blockedCount), http.StatusBadRequest)
+		default:
+			jsonError(w, tr(r, "batch_all_quarantined", s.quarantinedCount), http.StatusBadRequest)
+		}
+		return nil, false
+	}
+	return s, true
+}
+
+// snapshot records every member, including those left out, so the run keeps a
+// record of who was targeted after the group changes.
+func (s *recipientSelection) snapshot(contactNames map[string]*string, capped map[string]bool) []models.BatchRecipient {
+	recipients := make([]models.BatchRecipient, len(s.members))
+	for i, member := range s.members {
+		status := models.RecipientIncluded
+		switch {
+		case s.quarantined[member.JID]:
+			status = models.RecipientQuarantined
+		case s.blocked[member.JID]:
+			status = models.RecipientBlocked
+		case capped[member.JID]:
+			status = models.RecipientCapped
+		case s.self[member.JID]:
+			status = models.RecipientSelf
+		}
+		recipients[i] = models.BatchRecipient{
+			JID:         member.JID,
+			ContactName: contactNames[member.JID],
+			Status:      status,
+		}
+	}
+	return recipients
+}
+
+// sendJIDs returns the JID each member is sent to: its own, or for a hidden
+// number the phone number WhatsApp has given for it. Hidden numbers without
+// one keep their JID for the worker to fail, and are counted as unresolved.
+func (h *BatchHandler) sendJIDs(members []models.GroupMember) ([]string, int, error) {
+	jids := make([]string, len(members))
+	unresolved := 0
+	for i, member := range members {
+		jids[i] = member.JID
+		if !whatsapp.IsLID(member.JID) {
+			continue
+		}
+		pn, err := h.waClient.ResolveLID(member.JID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if pn == "" {
+			unresolved++
+		} else {
+			jids[i] = pn
+		}
+	}
+	return jids, unresolved, nil
+}
+
+// batchMessages creates a message for each member, once per number when a
+// hidden number resolves to a member's phone number. Messages to recipients at
+// the recipient cap are created skipped; the members they were for are
+// returned as capped.
+func batchMessages(members []models.GroupMember, sendJIDs, contents []string, contactNames map[string]*string, capped map[string]int, recipientCap batch.RecipientCap) ([]models.BatchMessage, map[string]bool) {
+	messages := make([]models.BatchMessage, 0, len(members))
+	seen := make(map[string]bool, len(members))
+	cappedMembers := make(map[string]bool, len(capped))
+	for i, member := range members {
+		count, isCapped := capped[sendJIDs[i]]
+		if isCapped {
+			cappedMembers[member.JID] = true
+		}
+		if seen[sendJIDs[i]] {
+			continue
+		}
+		seen[sendJIDs[i]] = true
+		message := models.BatchMessage{
+			JID:             sendJIDs[i],
+			ContactName:     contactNames[member.JID],
+			Status:          models.MessageStatusPending,
+			TemplateContent: contents[i],
+		}
+		if isCapped {
+			code, reason := batch.SkipRecipientCap, recipientCap.Reason(count)
+			message.Status = models.MessageStatusSkipped
+			message.ErrorCode = &code
+			message.ErrorMessage = &reason
+		}
+		messages = append(messages, message)
 	}
+	return messages, cappedMembers
+}
 
-	progress, _ := h.worker.GetProgress(activeBatch.ID)
+// batchOutcome is what batch creation found out about recipients beyond the plan.
+type batchOutcome struct {
+	notOnWhatsApp int // Failed validation as not registered on WhatsApp
+	lidFailed     int // Hidden numbers without a phone number, failed
+}
+
+// batchSummary is the message of a dry run, with a nil outcome, or of a created
+// batch: headline, then a note for each kind of recipient that is left out or
+// fails. A dry run's notes say what will fail rather than what did.
+func batchSummary(r *http.Request, headline string, plan *BatchPlan, warnings int, outcome *batchOutcome) string {
+	parts := []string{headline}
+	note := func(count int, id string) {
+		if count > 0 {
+			parts = append(parts, tr(r, id, count))
+		}
+	}
+	if outcome == nil {
+		note(plan.MissingSelectorCount, "batch_note_missing_selector")
+		note(plan.UnresolvedLIDCount, "batch_note_lid_will_fail")
+		note(plan.MissingDocumentCount, "batch_note_no_document_will_fail")
+	} else {
+		note(outcome.notOnWhatsApp, "batch_note_not_on_whatsapp")
+		note(outcome.lidFailed, "batch_note_lid_failed")
+		note(plan.MissingDocumentCount, "batch_note_no_document")
+	}
+	note(plan.QuarantinedCount, "batch_note_quarantined")
+	note(plan.BlockedCount, "batch_note_blocked")
+	note(plan.CappedCount, "batch_note_capped")
+	if plan.SelfSkipped {
+		parts = append(parts, tr(r, "batch_note_self"))
+	}
+	note(warnings, "batch_note_warnings")
+	return strings.Join(parts, ", ")
+}
+
+// sortMembers puts members, given in group order, in the order set by sort:
+// kept for group_order, by contact name for name (members WhatsApp has no name
+// for come last, by phone number), or shuffled with seed for random, so a dry
+// run and a batch created with its sort_seed send in the same order.
+func sortMembers(members []models.GroupMember, sort string, seed int64, names map[string]*string) {
+	switch sort {
+	case models.BatchSortName:
+		name := func(member models.GroupMember) string {
+			if name := names[member.JID]; name != nil {
+				return strings.ToLower(strings.TrimSpace(*name))
+			}
+			return ""
+		}
+		slices.SortStableFunc(members, func(a, b models.GroupMember) int {
+			nameA, nameB := name(a), name(b)
+			if (nameA == "") != (nameB == "") {
+				if nameA == "" {
+					return 1
+				}
+				return -1
+			}
+			if c := strings.Compare(nameA, nameB); c != 0 {
+				return c
+			}
+			return strings.Compare(a.JID, b.JID)
+		})
+	case models.BatchSortRandom:
+		rng := rand.New(rand.NewSource(seed))
+		rng.Shuffle(len(members), func(i, j int) {
+			members[i], members[j] = members[j], members[i]
+		})
+	}
+}
+
+// planRecipients picks the draft content (parent or variant) for each member,
+// returning the counts and the per-member content in member order. Selectors
+// see the group's placeholder defaults under each member's attributes, as the
+// worker does when sending.
+func (h *BatchHandler) planRecipients(draft *models.MessageDraft, groupID int64, members []models.GroupMember) (*BatchPlan, []string, error) {
+	plan := &BatchPlan{TotalCount: len(members), Recipients: make([]RecipientPlan, len(members))}
+	contents := make([]string, len(members))
+	for i, member := range members {
+		plan.Recipients[i].JID = member.JID
+	}
+
+	variants, err := h.variantRepo.GetByDraft(draft.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan.ContentHash = draft.ContentHash(variants)
+
+	if len(variants) == 0 {
+		for i := range members {
+			contents[i] = draft.Content
+		}
+		plan.ParentCount = len(members)
+		return plan, contents, nil
+	}
+
+	groupDefaults, err := h.groupAttrRepo.GetByGroupAsMap(groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	jids := make([]string, len(members))
+	for i, member := range members {
+		jids[i] = member.JID
+	}
+	attributes, err := h.attrRepo.GetForContacts(jids, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan.VariantCounts = make(map[string]int)
+	for i, member := range members {
+		values := template.MergePlaceholders(groupDefaults, attributes[member.JID])
+
+		if variant := models.SelectVariant(variants, values); variant != nil {
+			contents[i] = variant.Content
+			plan.Recipients[i].Variant = variant.SelectorKey + "=" + variant.SelectorValue
+			plan.VariantCounts[plan.Recipients[i].Variant]++
+			continue
+		}
+
+		contents[i] = draft.Content
+		plan.ParentCount++
+		if !models.HasSelectorValue(variants, values) {
+			plan.MissingSelectorCount++
+		}
+	}
+
+	return plan, contents, nil
+}
+
+// checkDocuments returns the members whose document, named by their value of
+// attr over the group's defaults, could not be sent right now.
+func (h *BatchHandler) checkDocuments(groupID int64, members []models.GroupMember, attr string) ([]MissingDocument, error) {
+	groupDefaults, err := h.groupAttrRepo.GetByGroupAsMap(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	jids := make([]string, len(members))
+	for i, member := range members {
+		jids[i] = member.JID
+	}
+	attributes, err := h.attrRepo.GetForContacts(jids, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []MissingDocument
+	for _, member := range members {
+		ref := template.MergePlaceholders(groupDefaults, attributes[member.JID])[attr]
+		if err := h.worker.CheckDocument(ref); err != nil {
+			missing = append(missing, MissingDocument{JID: member.JID, Document: ref, Error: err.Error()})
+		}
+	}
+	return missing, nil
+}
+
+// cloneBatch handles POST /api/batch-runs/{id}/clone, queueing a fresh batch with the
+// same draft and group. Membership is re-expanded, so the new batch targets the group
+// as it is now. The source batch may be in any status.
+func (h *BatchHandler) cloneBatch(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	source, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if source == nil {
+		jsonError(w, tr(r, "batch_not_found"), http.StatusNotFound)
+		return
+	}
+
+	draft, err := h.draftRepo.GetInWorkspace(workspaceID(r), source.DraftID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if draft == nil {
+		jsonError(w, fmt.Sprintf("Cannot clone: draft %q has been deleted", source.DraftTitle), http.StatusConflict)
+		return
+	}
+
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), source.GroupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		jsonError(w, fmt.Sprintf("Cannot clone: group %q has been deleted", source.GroupName), http.StatusConflict)
+		return
+	}
+
+	h.queueBatch(w, r, draft, group, CreateBatchRequest{
+		DraftID:  draft.ID,
+		GroupID:  group.ID,
+		Validate: r.URL.Query().Get("validate") == "true",
+		DryRun:   r.URL.Query().Get("dry_run") == "true",
+		DocumentAttribute: source.DocumentAttribute,
+		Sort:     source.Sort,
+	})
+}
+
+// HandleGroupSend handles POST /api/groups/{id}/send, queueing a batch of the
+// group's default draft, or of draft_id from the body when given.
+func (h *BatchHandler) HandleGroupSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/groups/"), "/send")
+	groupID, err := strconv.ParseInt(path, 10, 64)
+	if err != nil {
+		jsonError(w, tr(r, "invalid_group_id"), http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional
+	var req GroupSendRequest
+	if !decodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), groupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+		return
+	}
+
+	draftID := req.DraftID
+	if draftID == 0 {
+		if group.DefaultDraftID == nil {
+			jsonError(w, tr(r, "group_no_default_draft"), http.StatusBadRequest)
+			return
+		}
+		draftID = *group.DefaultDraftID
+	}
+
+	draft, err := h.draftRepo.GetInWorkspace(workspaceID(r), draftID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if draft == nil {
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+		return
+	}
+
+	h.queueBatch(w, r, draft, group, CreateBatchRequest{
+		DraftID:  draft.ID,
+		GroupID:  group.ID,
+		Validate: req.Validate,
+		DryRun:   req.DryRun,
+		SpinSeed: req.SpinSeed,
+		ClientName: req.ClientName,
+		Label:    req.Label,
+		DocumentAttribute: req.DocumentAttribute,
+		Sort:     req.Sort,
+		SortSeed: req.SortSeed,
+		AllowSelf: req.AllowSelf,
+	})
+}
+
+// requestSource tells the app's own pages from other HTTP clients. Browsers mark
+// same-origin fetches with Sec-Fetch-Site; this is attribution, not access control.
+func requestSource(r *http.Request) string {
+	if r.Header.Get("Sec-Fetch-Site") == "same-origin" {
+		return models.BatchSourceWeb
+	}
+	return models.BatchSourceAPI
+}
+
+// requestClientName returns the client's label from the X-Client-Name header,
+// or fromBody when the header is absent; nil when neither is set. On an invalid
+// label it writes the error response and returns false.
+func requestClientName(w http.ResponseWriter, r *http.Request, fromBody string) (*string, bool) {
+	name := strings.TrimSpace(r.Header.Get(ClientNameHeader))
+	if name == "" {
+		name = strings.TrimSpace(fromBody)
+	}
+	if name == "" {
+		return nil, true
+	}
+	if len(name) > maxClientNameLength {
+		jsonError(w, tr(r, "client_name_too_long", maxClientNameLength), http.StatusBadRequest)
+		return nil, false
+	}
+	return &name, true
+}
+
+// renderLabel fills the {{date}}, {{group}} and {{draft}} placeholders of a batch
+// label; an empty label gives the default. On an invalid label it writes the
+// error response and returns false.
+func (h *BatchHandler) renderLabel(w http.ResponseWriter, r *http.Request, label string, draft *models.MessageDraft, group *models.ContactGroup) (string, bool) {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return models.DefaultBatchLabel(draft.Title, group.Name), true
+	}
+	if err := template.DefaultDelimiters.ValidateSyntax(label); err != nil {
+		jsonError(w, tr(r, "batch_label_invalid", err), http.StatusBadRequest)
+		return "", false
+	}
+
+	loc, err := settingsLocation(h.settingsRepo, h.defaultTimezone)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusInternalServerError)
+		return "", false
+	}
+	values := map[string]string{
+		"date":  time.Now().In(loc).Format("2006-01-02"),
+		"group": group.Name,
+		"draft": draft.Title,
+	}
+
+	rendered := template.DefaultDelimiters.Preview(label, values, 0)
+	if len(rendered.PlaceholdersMissing) > 0 {
+		jsonError(w, tr(r, "batch_label_unknown_placeholder", rendered.PlaceholdersMissing[0]), http.StatusBadRequest)
+		return "", false
+	}
+	if len(rendered.HelperErrors) > 0 {
+		jsonError(w, tr(r, "batch_label_invalid", rendered.HelperErrors[0]), http.StatusBadRequest)
+		return "", false
+	}
+	if n := utf8.RuneCountInString(rendered.Preview); n > models.MaxBatchLabelLength {
+		jsonError(w, tr(r, "batch_label_too_long", n, models.MaxBatchLabelLength), http.StatusBadRequest)
+		return "", false
+	}
+	return rendered.Preview, true
+}
+
+// getBatchEvents handles GET /api/batch-runs/{id}/events?after=N, returning the
+// worker's lifecycle log in order. after is a seq; only newer events are returned.
+func (h *BatchHandler) getBatchEvents(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var after int64
+	if v := r.URL.Query().Get("after"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			jsonError(w, tr(r, "events_bad_after"), http.StatusBadRequest)
+			return
+		}
+		after = n
+	}
+
+	batchRun, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if batchRun == nil {
+		jsonError(w, tr(r, "batch_not_found"), http.StatusNotFound)
+		return
+	}
+
+	events, err := h.eventRepo.GetByBatch(id, after)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve batch events: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BatchEventsResponse{
+		Success: true,
+		Message: tr(r, "batch_events_retrieved"),
+		Events:  events,
+		Count:   len(events),
+	})
+}
+
+func (h *BatchHandler) getBatch(w http.ResponseWriter, r *http.Request, id int64) {
+	batchRun, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, BatchDetailResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to retrieve batch: %v", err),
+		})
+		return
+	}
+
+	if batchRun == nil {
+		jsonError(w, tr(r, "batch_not_found"), http.StatusNotFound)
+		return
+	}
+
+	// Get messages
+	messages, err := h.msgRepo.GetByBatchRun(id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, BatchDetailResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to retrieve messages: %v", err),
+		})
+		return
+	}
+
+	var stats *models.BatchRunStats
+	if batchRun.CompletedAt != nil {
+		stats, err = h.eventRepo.GetRunStats(id)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("Failed to compute batch stats", "batch_id", id, "error", err)
+		}
+	}
+
+	// Messages failed before codes were recorded count as unknown
+	var failures map[string]int
+	for _, msg := range messages {
+		if msg.Status != models.MessageStatusFailed {
+			continue
+		}
+		if failures == nil {
+			failures = make(map[string]int)
+		}
+		code := "unknown"
+		if msg.ErrorCode != nil {
+			code = *msg.ErrorCode
+		}
+		failures[code]++
+	}
+
+	draftModified, err := h.draftModified(batchRun)
+	if err != nil {
+		logging.FromContext(r.Context()).Warn("Failed to compare batch with its draft", "batch_id", id, "error", err)
+	}
+
+	writeJSON(w, http.StatusOK, BatchDetailResponse{
+		Success:  true,
+		Message:  tr(r, "batch_retrieved"),
+		Batch:    batchRun,
+		Messages: messages,
+		Stats:    stats,
+		FailuresByCode: failures,
+		Delivery: models.CountDelivery(messages),
+		DraftModified: draftModified,
+	})
+}
+
+// draftModified reports whether the batch's draft now hashes differently from
+// when the batch was created. Messages are sent as they were snapshotted, so
+// this only tells the draft and the batch apart. Nil when the batch predates
+// content hashes or the draft has been deleted.
+func (h *BatchHandler) draftModified(batchRun *models.BatchRun) (*bool, error) {
+	if batchRun.ContentHash == "" {
+		return nil, nil
+	}
+	draft, err := h.draftRepo.GetByID(batchRun.DraftID)
+	if err != nil || draft == nil {
+		return nil, err
+	}
+	variants, err := h.variantRepo.GetByDraft(draft.ID)
+	if err != nil {
+		return nil, err
+	}
+	modified := draft.ContentHash(variants) != batchRun.ContentHash
+	return &modified, nil
+}
+
+func (h *BatchHandler) getBatchMessages(w http.ResponseWriter, r *http.Request, id int64) {
+	batchRun, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if batchRun == nil {
+		jsonError(w, tr(r, "batch_not_found"), http.StatusNotFound)
+		return
+	}
+
+	messages, err := h.msgRepo.GetByBatchRun(id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, BatchMessagesResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to retrieve messages: %v", err),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BatchMessagesResponse{
+		Success:  true,
+		Messages: messages,
+		Count:    len(messages),
+	})
+}
+
+// getBatchRecipients handles GET /api/batch-runs/{id}/recipients, the group's
+// membership as it was when the batch was created. Batches created before
+// snapshots were recorded return an empty list.
+func (h *BatchHandler) getBatchRecipients(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	batchRun, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if batchRun == nil {
+		jsonError(w, tr(r, "batch_not_found"), http.StatusNotFound)
+		return
+	}
+
+	recipients, err := h.recipientRepo.GetByBatch(id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve recipients: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BatchRecipientsResponse{
+		Success:    true,
+		Message:    tr(r, "recipients_retrieved"),
+		Recipients: recipients,
+		Count:      len(recipients),
+	})
+}
+
+func (h *BatchHandler) cancelBatch(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check batch exists
+	batchRun, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, BatchResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to check batch: %v", err),
+		})
+		return
+	}
+
+	if batchRun == nil {
+		jsonError(w, tr(r, "batch_not_found"), http.StatusNotFound)
+		return
+	}
+
+	// Can only cancel preparing, queued, running or interrupted batches
+	if !models.CanTransition(batchRun.Status, models.BatchStatusCancelled) {
+		jsonError(w, fmt.Sprintf("Cannot cancel batch with status: %s", batchRun.Status), http.StatusBadRequest)
+		return
+	}
+
+	cancelled, err := h.worker.CancelBatch(id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, BatchResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to cancel batch: %v", err),
+		})
+		return
+	}
+	if !cancelled {
+		// The batch finished between the check above and the cancel
+		status := batchRun.Status
+		if current, err := h.batchRepo.GetByID(id); err == nil && current != nil {
+			status = current.Status
+		}
+		jsonError(w, tr(r, "batch_cancel_too_late", status), http.StatusConflict)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BatchResponse{
+		Success: true,
+		Message: tr(r, "batch_cancelled"),
+	})
+}
+
+// skipMessage handles POST /api/batch-runs/{id}/messages/{messageId}/skip. The
+// message must still be pending; sent, sending and failed ones get a 409. The
+// caller is recorded by its X-Client-Name, or its address without one.
+func (h *BatchHandler) skipMessage(w http.ResponseWriter, r *http.Request, id, messageID int64) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	batchRun, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if batchRun == nil {
+		jsonError(w, tr(r, "batch_not_found"), http.StatusNotFound)
+		return
+	}
+	if batchRun.Status != models.BatchStatusQueued && batchRun.Status != models.BatchStatusRunning && batchRun.Status != models.BatchStatusInterrupted {
+		jsonError(w, fmt.Sprintf("Cannot skip messages of a batch with status: %s", batchRun.Status), http.StatusConflict)
+		return
+	}
+
+	msg, err := h.msgRepo.GetByID(messageID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check message: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if msg == nil || msg.BatchRunID != id {
+		jsonError(w, tr(r, "message_not_found"), http.StatusNotFound)
+		return
+	}
+	if msg.Status != models.MessageStatusPending {
+		jsonError(w, fmt.Sprintf("Cannot skip a message with status: %s", msg.Status), http.StatusConflict)
+		return
+	}
+
+	clientName, ok := requestClientName(w, r, "")
+	if !ok {
+		return
+	}
+	skippedBy := r.RemoteAddr
+	if clientName != nil {
+		skippedBy = *clientName
+	}
+
+	// The worker may have picked the message up since it was read
+	skipped, err := h.worker.SkipMessage(id, msg, skippedBy)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to skip message: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !skipped {
+		jsonError(w, tr(r, "message_not_pending"), http.StatusConflict)
+		return
+	}
+
+	batchRun, err = h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BatchResponse{
+		Success: true,
+		Message: tr(r, "message_skipped"),
+		Batch:   batchRun,
+	})
+}
+
+// editMessage handles PUT /api/batch-runs/{id}/messages/{messageId}, replacing
+// the content one recipient is sent while the message is pending and its batch
+// queued, running (paused included) or interrupted. The content is linted like
+// a draft's, with the batch's delimiters; sent, sending, failed and skipped
+// messages get a 409.
+func (h *BatchHandler) editMessage(w http.ResponseWriter, r *http.Request, id, messageID int64) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req EditMessageRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.TemplateContent == nil || strings.TrimSpace(*req.TemplateContent) == "" {
+		jsonError(w, tr(r, "content_required"), http.StatusBadRequest)
+		return
+	}
+	content := *req.TemplateContent
+
+	batchRun, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if batchRun == nil {
+		jsonError(w, tr(r, "batch_not_found"), http.StatusNotFound)
+		return
+	}
+	if batchRun.Status != models.BatchStatusQueued && batchRun.Status != models.BatchStatusRunning && batchRun.Status != models.BatchStatusInterrupted {
+		jsonError(w, fmt.Sprintf("Cannot edit messages of a batch with status: %s", batchRun.Status), http.StatusConflict)
+		return
+	}
+
+	msg, err := h.msgRepo.GetByID(messageID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check message: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if msg == nil || msg.BatchRunID != id {
+		jsonError(w, tr(r, "message_not_found"), http.StatusNotFound)
+		return
+	}
+	if msg.Status != models.MessageStatusPending {
+		jsonError(w, fmt.Sprintf("Cannot edit a message with status: %s", msg.Status), http.StatusConflict)
+		return
+	}
+
+	// Batches created before delimiters were stored use the draft's
+	delimiters := template.DefaultDelimiters
+	if batchRun.Delimiters != nil {
+		delimiters = *batchRun.Delimiters
+	} else if draft, err := h.draftRepo.GetByID(batchRun.DraftID); err == nil && draft != nil {
+		delimiters = draft.Delimiters
+	}
+	issues := delimiters.ValidateTemplate(content, nil)
+	if template.HasErrors(issues) {
+		writeJSON(w, http.StatusBadRequest, BatchMessageResponse{
+			Success:  false,
+			Message:  tr(r, "template_has_errors"),
+			Warnings: issues,
+		})
+		return
+	}
+	issues = append(issues, contentWarnings(h.attrRepo, h.settingsRepo, delimiters, content)...)
+
+	clientName, ok := requestClientName(w, r, "")
+	if !ok {
+		return
+	}
+	editedBy := r.RemoteAddr
+	if clientName != nil {
+		editedBy = *clientName
+	}
+
+	// The worker may have picked the message up since it was read
+	updated, err := h.msgRepo.UpdateContent(id, messageID, content)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to edit message: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !updated {
+		jsonError(w, tr(r, "message_not_pending"), http.StatusConflict)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("Batch message edited", "batch_id", id, "message_id", messageID, "jid", msg.JID, "edited_by", editedBy)
+	if err := h.eventRepo.Record(id, models.BatchEventMessageEdited, msg.JID, "Edited by "+editedBy); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to record batch event", "batch_id", id, "error", err)
+	}
+
+	msg, err = h.msgRepo.GetByID(messageID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve message: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BatchMessageResponse{
+		Success:      true,
+		Message:      tr(r, "message_edited"),
+		BatchMessage: msg,
+		Warnings:     issues,
+	})
+}
+
+// resumeBatch handles POST /api/batch-runs/{id}/resume for batches held as
+// interrupted at startup. The batch rejoins the queue and starts when a slot is
+// free, ahead of batches created after it.
+func (h *BatchHandler) resumeBatch(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	batchRun, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if batchRun == nil {
+		jsonError(w, tr(r, "batch_not_found"), http.StatusNotFound)
+		return
+	}
+	if batchRun.Status != models.BatchStatusInterrupted {
+		jsonError(w, fmt.Sprintf("Only interrupted batches can be resumed, this one is %s", batchRun.Status), http.StatusConflict)
+		return
+	}
+
+	resumed, err := h.worker.ResumeBatch(id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to resume batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !resumed {
+		jsonError(w, tr(r, "batch_not_interrupted"), http.StatusConflict)
+		return
+	}
+
+	batchRun, err = h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BatchResponse{
+		Success: true,
+		Message: tr(r, "batch_resumed"),
+		Batch:   batchRun,
+	})
+}
+
+func (h *BatchHandler) deleteBatch(w http.ResponseWriter, r *http.Request, id int64) {
+	found, err := h.batchRepo.Delete(workspaceID(r), id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, BatchResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to delete batch: %v", err),
+		})
+		return
+	}
+
+	if !found {
+		jsonError(w, tr(r, "batch_not_found_or_running"), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BatchResponse{
+		Success: true,
+		Message: tr(r, "batch_deleted"),
+	})
+}
+
+func (h *BatchHandler) getActiveBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	running, err := h.batchRepo.GetRunning()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ActiveBatchResponse{
+			Success: false,
+		})
+		return
+	}
+
+	queuedRuns, err := h.batchRepo.GetQueued()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ActiveBatchResponse{
+			Success: false,
+		})
+		return
+	}
+	starts := h.worker.EstimateQueueStarts(queuedRuns)
+	queued := make([]QueuedBatch, len(queuedRuns))
+	for i, run := range queuedRuns {
+		queued[i] = QueuedBatch{
+			ID:         run.ID,
+			Position:   i + 1,
+			Label:      run.Label,
+			DraftTitle: run.DraftTitle,
+			GroupName:  run.GroupName,
+			TotalCount: run.TotalCount,
+			EstimatedStartAt: starts[i],
+		}
+	}
+
+	if len(running) == 0 {
+		writeJSON(w, http.StatusOK, ActiveBatchResponse{
+			Success:   true,
+			HasActive: false,
+			QueuedCount: len(queued),
+			Queued:    queued,
+		})
+		return
+	}
+
+	active := make([]ActiveBatch, len(running))
+	for i := range running {
+		progress, _ := h.worker.GetProgress(running[i].ID)
+		active[i] = ActiveBatch{Batch: &running[i], Progress: progress}
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ActiveBatchResponse{
+	writeJSON(w, http.StatusOK, ActiveBatchResponse{
 		Success:   true,
 		HasActive: true,
-		Batch:     activeBatch,
-		Progress:  progress,
+		Batch:     active[0].Batch,
+		Progress:  active[0].Progress,
+		Active:    active,
+		QueuedCount: len(queued),
+		Queued:    queued,
 	})
 }
 
@@ -475,7 +1815,7 @@ func (h *BatchHandler) streamBatch(w http.ResponseWriter, r *http.Request, id in
 	}
 
 	// Check batch exists
-	batchRun, err := h.batchRepo.GetByID(id)
+	batchRun, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
 	if err != nil || batchRun == nil {
 		http.Error(w, "Batch not found", http.StatusNotFound)
 		return
@@ -485,7 +1825,6 @@ func (h *BatchHandler) streamBatch(w http.ResponseWriter, r *http.Request, id in
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
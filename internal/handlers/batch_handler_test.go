@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestClientName(t *testing.T) {
+	long := strings.Repeat("x", maxClientNameLength+1)
+	for _, tc := range []struct {
+		name     string
+		header   string
+		body     string
+		want     string // Empty for no client name
+		wantCode int    // Status written when the name is refused
+	}{
+		{name: "neither"},
+		{name: "header", header: "crm-sync", want: "crm-sync"},
+		{name: "body", body: "crm-sync", want: "crm-sync"},
+		{name: "header takes precedence", header: "crm-sync", body: "zapier", want: "crm-sync"},
+		{name: "blank header falls back to body", header: "  ", body: " zapier ", want: "zapier"},
+		{name: "trimmed", header: " crm-sync\t", want: "crm-sync"},
+		{name: "longest allowed", header: long[1:], want: long[1:]},
+		{name: "header too long", header: long, wantCode: http.StatusBadRequest},
+		{name: "body too long", body: long, wantCode: http.StatusBadRequest},
+		{name: "long body loses to header", header: "crm-sync", body: long, want: "crm-sync"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/batch-runs", nil)
+			if tc.header != "" {
+				req.Header.Set(ClientNameHeader, tc.header)
+			}
+			rec := httptest.NewRecorder()
+			got, ok := requestClientName(rec, req, tc.body)
+
+			if tc.wantCode != 0 {
+				if ok || rec.Code != tc.wantCode {
+					t.Fatalf("ok = %v, status %d; want refused with %d", ok, rec.Code, tc.wantCode)
+				}
+				var resp struct{ Message string }
+				decodeResponse(t, rec, &resp)
+				if want := "Client name must be at most 100 bytes"; resp.Message != want {
+					t.Errorf("message = %q, want %q", resp.Message, want)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("refused with %d: %s", rec.Code, rec.Body)
+			}
+			switch {
+			case tc.want == "" && got != nil:
+				t.Errorf("client name = %q, want none", *got)
+			case tc.want != "" && (got == nil || *got != tc.want):
+				t.Errorf("client name = %v, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestBatchSummary checks that a dry run and a created batch share their notes
+// and differ only where a dry run says what will fail.
+func TestBatchSummary(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/batch-runs", nil)
+	plan := &BatchPlan{
+		MissingSelectorCount: 1,
+		UnresolvedLIDCount:   2,
+		MissingDocumentCount: 3,
+		QuarantinedCount:     4,
+		SelfSkipped:          true,
+	}
+
+	for _, tc := range []struct {
+		name    string
+		outcome *batchOutcome
+		want    string
+	}{
+		{
+			name: "dry run",
+			want: "Head, 1 without a variant selector attribute will receive the default content, " +
+				"2 hidden numbers without a phone number will fail, 3 without a sendable document will fail, " +
+				"4 quarantined recipients skipped, the linked account was skipped, 5 content warnings",
+		},
+		{
+			name:    "created",
+			outcome: &batchOutcome{notOnWhatsApp: 0, lidFailed: 2},
+			want: "Head, 2 hidden numbers without a phone number failed, 3 recipients have no sendable document yet, " +
+				"4 quarantined recipients skipped, the linked account was skipped, 5 content warnings",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := batchSummary(r, "Head", plan, 5, tc.outcome); got != tc.want {
+				t.Errorf("batchSummary() = %q\nwant %q", got, tc.want)
+			}
+		})
+	}
+
+	if got := batchSummary(r, "Head", &BatchPlan{}, 0, &batchOutcome{}); got != "Head" {
+		t.Errorf("batchSummary() with nothing to note = %q, want %q", got, "Head")
+	}
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"friday/internal/batch"
+	"friday/internal/logging"
+	"friday/internal/models"
+	"friday/internal/template"
+	"friday/internal/whatsapp"
+)
+
+// defaultPreflightRecentHours is how far back a preflight looks for earlier
+// sends when the request does not say.
+const defaultPreflightRecentHours = 24
+
+type PreflightRequest struct {
+	DraftID     int64 `json:"draft_id"`
+	GroupID     int64 `json:"group_id"`
+	RecentHours int   `json:"recent_hours"` // Window for recently_sent, default 24
+}
+
+// PreflightCheck is what one check found: the members it flags, in group order.
+type PreflightCheck struct {
+	Count      int                  `json:"count"`
+	JIDs       []string             `json:"jids"`
+	Skipped    bool                 `json:"skipped,omitempty"`      // The check could not run
+	Note       string               `json:"note,omitempty"`         // Why it was skipped, or what it could not cover
+	Missing    map[string][]string  `json:"missing,omitempty"`      // missing_placeholders: JID -> placeholders without a value
+	Unchecked  int                  `json:"unchecked,omitempty"`    // not_on_whatsapp: members without a recent registration check
+	LastSentAt map[string]time.Time `json:"last_sent_at,omitempty"` // recently_sent: JID -> last batch message
+	SentCounts map[string]int       `json:"sent_counts,omitempty"`  // recipient_cap: JID -> messages sent within batch.recipient_cap_days
+}
+
+func (c *PreflightCheck) flag(jid string) {
+	c.JIDs = append(c.JIDs, jid)
+	c.Count++
+}
+
+func (c *PreflightCheck) skip(note string) {
+	c.Skipped = true
+	c.Note = note
+}
+
+// PreflightReport groups what the checks found before a batch is created.
+type PreflightReport struct {
+	MemberCount         int            `json:"member_count"`
+	Placeholders        []string       `json:"placeholders"` // Used by the draft content the members would get
+	MissingPlaceholders PreflightCheck `json:"missing_placeholders"`
+	NotOnWhatsApp       PreflightCheck `json:"not_on_whatsapp"` // From cached registration checks only
+	OptedOut            PreflightCheck `json:"opted_out"`
+	Quarantined         PreflightCheck `json:"quarantined"` // A batch leaves them out
+	Blocked             PreflightCheck `json:"blocked"`     // Blocked by the linked account; a batch leaves them out
+	RecentlySent        PreflightCheck `json:"recently_sent"`
+	RecipientCap        PreflightCheck `json:"recipient_cap"` // At batch.recipient_cap; a batch creates their messages skipped
+}
+
+type PreflightResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	OK      bool             `json:"ok"` // No check flagged a member
+	Report  *PreflightReport `json:"report,omitempty"`
+}
+
+// preflight handles POST /api/batch-runs/preflight, checking a draft and group
+// before a batch is created: members lacking placeholder values, members known
+// not to be on WhatsApp, opted-out, quarantined and blocked members, members
+// sent a batch message recently and members at the recipient cap. Nothing is sent or stored, and WhatsApp is not asked;
+// a check that cannot run is marked skipped instead of failing the report.
+func (h *BatchHandler) preflight(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req PreflightRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.RecentHours < 0 {
+		jsonError(w, tr(r, "preflight_invalid_recent_hours"), http.StatusBadRequest)
+		return
+	}
+	if req.RecentHours == 0 {
+		req.RecentHours = defaultPreflightRecentHours
+	}
+
+	draft, err := h.draftRepo.GetInWorkspace(workspaceID(r), req.DraftID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if draft == nil {
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+		return
+	}
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), req.GroupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+		return
+	}
+	members, err := h.memberRepo.GetByGroup(group.ID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get group members: %v", err), http.StatusInternalServerError)
+		return
+	}
+	jids := make([]string, len(members))
+	for i, member := range members {
+		jids[i] = member.JID
+	}
+
+	report := &PreflightReport{MemberCount: len(members), Placeholders: []string{}}
+	log := logging.FromContext(r.Context())
+
+	if err := h.checkPlaceholders(report, draft, group.ID, members); err != nil {
+		log.Warn("Preflight placeholder check failed", "error", err)
+		report.MissingPlaceholders.skip(err.Error())
+	}
+
+	if registered, err := h.worker.CachedValidations(jids); err != nil {
+		log.Warn("Preflight registration check failed", "error", err)
+		report.NotOnWhatsApp.skip(err.Error())
+	} else {
+		for _, jid := range jids {
+			onWhatsApp, checked := registered[jid]
+			switch {
+			case !checked:
+				report.NotOnWhatsApp.Unchecked++
+			case !onWhatsApp:
+				report.NotOnWhatsApp.flag(jid)
+			}
+		}
+	}
+
+	// There is no opt-out list yet; the check reports itself as skipped until there is
+	report.OptedOut.skip("opt-outs are not tracked")
+
+	if quarantined, err := h.quarantineRepo.QuarantinedJIDs(); err != nil {
+		log.Warn("Preflight quarantine check failed", "error", err)
+		report.Quarantined.skip(err.Error())
+	} else {
+		for _, jid := range jids {
+			if quarantined[jid] {
+				report.Quarantined.flag(jid)
+			}
+		}
+	}
+
+	if blocked, err := h.waClient.BlockedJIDs(r.Context()); err != nil {
+		report.Blocked.skip(err.Error())
+	} else {
+		for _, jid := range jids {
+			if blocked[jid] {
+				report.Blocked.flag(jid)
+			}
+		}
+	}
+
+	since := time.Now().Add(-time.Duration(req.RecentHours) * time.Hour)
+	if lastSent, err := h.msgRepo.LastSentTo(jids, since); err != nil {
+		log.Warn("Preflight recent send check failed", "error", err)
+		report.RecentlySent.skip(err.Error())
+	} else {
+		for _, jid := range jids {
+			if at, ok := lastSent[jid]; ok {
+				report.RecentlySent.flag(jid)
+				if report.RecentlySent.LastSentAt == nil {
+					report.RecentlySent.LastSentAt = make(map[string]time.Time)
+				}
+				report.RecentlySent.LastSentAt[jid] = at
+			}
+		}
+	}
+
+	if recipientCap, err := batch.LoadRecipientCap(h.settingsRepo); err != nil {
+		report.RecipientCap.skip(err.Error())
+	} else if !recipientCap.Enabled() {
+		report.RecipientCap.skip("batch.recipient_cap is 0")
+	} else if reached, err := recipientCap.Reached(h.msgRepo, jids); err != nil {
+		log.Warn("Preflight recipient cap check failed", "error", err)
+		report.RecipientCap.skip(err.Error())
+	} else {
+		for _, jid := range jids {
+			if count, ok := reached[jid]; ok {
+				report.RecipientCap.flag(jid)
+				if report.RecipientCap.SentCounts == nil {
+					report.RecipientCap.SentCounts = make(map[string]int)
+				}
+				report.RecipientCap.SentCounts[jid] = count
+			}
+		}
+	}
+
+	checks := []*PreflightCheck{&report.MissingPlaceholders, &report.NotOnWhatsApp, &report.OptedOut, &report.Quarantined, &report.Blocked, &report.RecentlySent, &report.RecipientCap}
+	flagged := make(map[string]bool)
+	for _, check := range checks {
+		if check.JIDs == nil {
+			check.JIDs = []string{}
+		}
+		for _, jid := range check.JIDs {
+			flagged[jid] = true
+		}
+	}
+
+	writeJSON(w, http.StatusOK, PreflightResponse{
+		Success: true,
+		Message: tr(r, "preflight_done", len(flagged), len(members)),
+		OK:      len(flagged) == 0,
+		Report:  report,
+	})
+}
+
+// checkPlaceholders fills report.Placeholders and flags the members left with
+// an unfilled placeholder in the content they would get, filled as the worker
+// fills it. While WhatsApp is disconnected the contact's own fields are
+// unknown, so only attributes and group defaults are checked.
+func (h *BatchHandler) checkPlaceholders(report *PreflightReport, draft *models.MessageDraft, groupID int64, members []models.GroupMember) error {
+	_, contents, err := h.planRecipients(draft, groupID, members)
+	if err != nil {
+		return fmt.Errorf("failed to resolve draft variants: %w", err)
+	}
+	groupDefaults, err := h.groupAttrRepo.GetByGroupAsMap(groupID)
+	if err != nil {
+		return fmt.Errorf("failed to get group placeholder defaults: %w", err)
+	}
+	jids := make([]string, len(members))
+	for i, member := range members {
+		jids[i] = member.JID
+	}
+	attributes, err := h.attrRepo.GetForContacts(jids, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get attributes: %w", err)
+	}
+
+	var contacts map[string]whatsapp.Contact
+	builtIn := template.GetBuiltInPlaceholders(&whatsapp.Contact{})
+	if h.waClient.IsConnected() {
+		contacts = whatsapp.ContactsByJID(h.waClient)
+	} else {
+		report.MissingPlaceholders.Note = "WhatsApp is disconnected; contact fields such as {{name}} were not checked"
+	}
+
+	seen := make(map[string]bool)
+	for i, member := range members {
+		for _, name := range draft.Delimiters.ExtractPlaceholders(contents[i]) {
+			if !seen[name] {
+				seen[name] = true
+				report.Placeholders = append(report.Placeholders, name)
+			}
+		}
+
+		var fields map[string]string
+		if contact, ok := contacts[member.JID]; ok {
+			fields = template.GetBuiltInPlaceholders(&contact)
+		}
+		values := template.MergePlaceholders(fields, groupDefaults, attributes[member.JID])
+		_, missing := draft.Delimiters.FillPlaceholders(contents[i], values, template.SpinSeed(0, member.JID))
+		if contacts == nil {
+			missing = slices.DeleteFunc(missing, func(name string) bool {
+				_, isField := builtIn[name]
+				return isField
+			})
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		report.MissingPlaceholders.flag(member.JID)
+		if report.MissingPlaceholders.Missing == nil {
+			report.MissingPlaceholders.Missing = make(map[string][]string)
+		}
+		report.MissingPlaceholders.Missing[member.JID] = missing
+	}
+	slices.Sort(report.Placeholders)
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"friday/internal/batch"
+)
+
+// maxProgressWait bounds how long GET /api/batch-runs/{id}/progress holds a request.
+const maxProgressWait = 60 * time.Second
+
+type BatchProgressResponse struct {
+	Success  bool                 `json:"success"`
+	Message  string               `json:"message"`
+	Progress *batch.ProgressEvent `json:"progress"`
+	ETag     string               `json:"etag"` // Pass back as If-None-Match or ?etag= to wait for the next change
+}
+
+// progressETag identifies a progress snapshot by what a change means to a
+// viewer: status, counts and the contact being sent to. The countdown and
+// rate move every second and are left out, or every poll would differ.
+func progressETag(p *batch.ProgressEvent) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%d|%d|%d|%d|%d|%s",
+		p.Status, p.TotalCount, p.SentCount, p.FailedCount, p.ValidationFailedCount, p.SkippedCount, p.CurrentContact))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// isFinalProgress reports whether the batch can no longer change.
+func isFinalProgress(p *batch.ProgressEvent) bool {
+	return p.Status == "completed" || p.Status == "cancelled" || p.Status == "failed"
+}
+
+// getBatchProgress handles GET /api/batch-runs/{id}/progress, a long-polling
+// fallback for clients behind proxies that break the event stream. Without an
+// etag, or when the snapshot differs from it, the progress is returned at once;
+// otherwise the request waits up to ?wait= seconds for a change and answers
+// 304 if none comes.
+func (h *BatchHandler) getBatchProgress(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var wait time.Duration
+	if v := r.URL.Query().Get("wait"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > maxProgressWait {
+			jsonError(w, tr(r, "progress_invalid_wait", int(maxProgressWait.Seconds())), http.StatusBadRequest)
+			return
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+	known := r.Header.Get("If-None-Match")
+	if known == "" {
+		known = r.URL.Query().Get("etag")
+	}
+	known = strings.TrimPrefix(known, "W/")
+	if known != "" && !strings.HasPrefix(known, `"`) {
+		known = `"` + known + `"`
+	}
+
+	batchRun, err := h.batchRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get batch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if batchRun == nil {
+		jsonError(w, tr(r, "batch_not_found"), http.StatusNotFound)
+		return
+	}
+
+	// Subscribe before the first snapshot so no change slips in between
+	eventCh := h.worker.Subscribe(id)
+	defer h.worker.Unsubscribe(id, eventCh)
+
+	progress, err := h.worker.GetProgress(id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get progress: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if etag := progressETag(progress); known == "" || etag != known || isFinalProgress(progress) || wait == 0 {
+		writeBatchProgress(w, r, progress, known)
+		return
+	}
+
+	// The request may outlast the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + 10*time.Second))
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		timedOut := false
+		select {
+		case <-r.Context().Done():
+			return
+		case _, ok := <-eventCh:
+			if !ok {
+				return
+			}
+		case <-timer.C:
+			// Changes the worker does not announce, like entering quiet hours, show up here
+			timedOut = true
+		}
+
+		progress, err := h.worker.GetProgress(id)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to get progress: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if progressETag(progress) != known || isFinalProgress(progress) {
+			writeBatchProgress(w, r, progress, known)
+			return
+		}
+		if timedOut {
+			break
+		}
+	}
+
+	w.Header().Set("ETag", known)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// writeBatchProgress answers with the snapshot, or 304 when it is still the
+// one the client has, as for a finished batch polled again.
+func writeBatchProgress(w http.ResponseWriter, r *http.Request, progress *batch.ProgressEvent, known string) {
+	etag := progressETag(progress)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-store")
+	if etag == known {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BatchProgressResponse{
+		Success:  true,
+		Message:  tr(r, "progress_retrieved"),
+		Progress: progress,
+		ETag:     etag,
+	})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"sync"
+
+	"friday/internal/config"
+	"friday/internal/models"
+)
+
+// Capabilities that depend on how a deployment is configured. Clients check
+// them with GET /api/capabilities instead of guessing from errors.
+const (
+	CapabilityAuth         = "auth"          // API tokens exist, so requests need one
+	CapabilityBatchTrigger = "batch_trigger" // Signed POST /api/integrations/trigger-batch
+	CapabilityCORS         = "cors"          // Cross-origin requests from FRIDAY_CORS_ORIGINS
+	CapabilityDocuments    = "documents"     // Batches sending per-recipient documents
+	CapabilityPolls        = "polls"         // POST /api/whatsapp/poll
+	CapabilityQuietHours   = "quiet_hours"   // A daily window without batch sends
+	CapabilityRecipientCap = "recipient_cap" // batch.recipient_cap limits messages per contact
+	CapabilityWebhooks     = "webhooks"      // Batch completion summaries POSTed to notify.webhook_url
+)
+
+// Capabilities records the optional features of this deployment. Each is
+// registered at startup by the part of the server that provides it, with a
+// function reporting whether it is on, so settings changed since are reflected.
+type Capabilities struct {
+	mu       sync.RWMutex
+	features map[string]func() bool
+}
+
+// NewCapabilities creates an empty capability registry.
+func NewCapabilities() *Capabilities {
+	return &Capabilities{features: make(map[string]func() bool)}
+}
+
+// Register adds a capability, replacing one registered under the same name.
+func (c *Capabilities) Register(name string, enabled func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.features[name] = enabled
+}
+
+// Enabled reports whether a capability is registered and on.
+func (c *Capabilities) Enabled(name string) bool {
+	c.mu.RLock()
+	enabled := c.features[name]
+	c.mu.RUnlock()
+	return enabled != nil && enabled()
+}
+
+// Snapshot returns every registered capability and whether it is on.
+func (c *Capabilities) Snapshot() map[string]bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	snapshot := make(map[string]bool, len(c.features))
+	for name, enabled := range c.features {
+		snapshot[name] = enabled()
+	}
+	return snapshot
+}
+
+// CapabilitiesResponse is the body of GET /api/capabilities.
+type CapabilitiesResponse struct {
+	Success      bool            `json:"success"`
+	Message      string          `json:"message"`
+	Version      string          `json:"version"`            // Module version, "(devel)" for a local build
+	Commit       string          `json:"commit,omitempty"`   // VCS revision the binary was built from, when recorded
+	Modified     bool            `json:"modified,omitempty"` // The build had uncommitted changes
+	Capabilities map[string]bool `json:"capabilities"`
+	Enabled      []string        `json:"enabled"`    // The capabilities that are on, sorted
+	Reloadable   []string        `json:"reloadable"` // Variables a SIGHUP re-reads from FRIDAY_CONFIG_FILE; settings always apply live
+}
+
+// buildVersion reads the version and VCS details the Go toolchain stamped into the binary.
+func buildVersion() (version, commit string, modified bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown", "", false
+	}
+	version = info.Main.Version
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			commit = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	return version, commit, modified
+}
+
+// HandleCapabilities handles GET /api/capabilities.
+func (c *Capabilities) HandleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	capabilities := c.Snapshot()
+	enabled := []string{}
+	for name, on := range capabilities {
+		if on {
+			enabled = append(enabled, name)
+		}
+	}
+	slices.Sort(enabled)
+
+	version, commit, modified := buildVersion()
+	writeJSON(w, http.StatusOK, CapabilitiesResponse{
+		Success:      true,
+		Message:      tr(r, "capabilities_retrieved"),
+		Version:      version,
+		Commit:       commit,
+		Modified:     modified,
+		Capabilities: capabilities,
+		Enabled:      enabled,
+		Reloadable:   config.Reloadable,
+	})
+}
+
+// settingSet returns a check that a setting has a value.
+func settingSet(repo *models.SettingsRepository, key string) func() bool {
+	return func() bool {
+		value, err := repo.GetString(key, "")
+		if err != nil {
+			slog.Warn("Failed to read setting", "key", key, "error", err)
+		}
+		return value != ""
+	}
+}
+
+// capabilityDisabled answers 501 for a request that needs a capability this
+// deployment has off, naming it so the client can look it up in /api/capabilities.
+func capabilityDisabled(w http.ResponseWriter, message, capability string) {
+	writeJSON(w, http.StatusNotImplemented, map[string]interface{}{
+		"success":    false,
+		"message":    message,
+		"capability": capability,
+	})
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"friday/internal/models"
+	"friday/internal/whatsapp"
+)
+
+// contactHistoryWindow is how far back GET /api/contacts/{jid} counts batch messages.
+const contactHistoryWindow = 30 * 24 * time.Hour
+
+// contactLookupTimeout bounds a WhatsApp contact store lookup.
+const contactLookupTimeout = 5 * time.Second
+
+// Values of the fields of ContactSources.
+const (
+	SourceOK          = "ok"
+	SourceNotFound    = "not_found"
+	SourceUnavailable = "unavailable" // Could not be asked, e.g. WhatsApp while disconnected
+)
+
+// ContactSources says what each source knew about a contact.
+type ContactSources struct {
+	WhatsApp string `json:"whatsapp"` // The WhatsApp contact store; unavailable while disconnected
+	Database string `json:"database"` // Attributes, note, groups, failures and batch messages
+}
+
+// ContactGroupRef is a group a contact belongs to.
+type ContactGroupRef struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// ContactDetail is everything known about one JID.
+type ContactDetail struct {
+	JID         string                     `json:"jid"`
+	Name        string                     `json:"name,omitempty"`
+	NameSource  string                     `json:"name_source,omitempty"` // "whatsapp", or "batch" for the name stored with its newest batch message
+	WhatsApp    *whatsapp.Contact          `json:"whatsapp,omitempty"`    // Nil when WhatsApp does not know the contact or is disconnected
+	MergedInto  string                     `json:"merged_into,omitempty"` // Contact this one was merged into; sends are redirected there
+	Attributes  map[string]string          `json:"attributes"`
+	Note        *models.ContactNote        `json:"note,omitempty"`
+	Groups      []ContactGroupRef          `json:"groups"`
+	Quarantined bool                       `json:"quarantined"`
+	Failures    *models.RecipientFailure   `json:"failures,omitempty"` // Consecutive permanent failures, nil when none are counted
+	History     *models.ContactSendHistory `json:"history,omitempty"`  // Batch messages of the last 30 days; nil if it was never sent one
+	Sources     ContactSources             `json:"sources"`
+}
+
+type ContactDetailResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Contact *ContactDetail `json:"contact,omitempty"`
+}
+
+// HandleContact handles GET /api/contacts/{jid}, gathering what WhatsApp and
+// the database know about a contact in one response. While WhatsApp is
+// disconnected the rest is still returned, with sources.whatsapp unavailable.
+// It is a 404 only when no source knows the JID.
+func (h *ContactHandler) HandleContact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jid, ok := pathJID(w, r, strings.TrimPrefix(r.URL.Path, "/api/contacts/"))
+	if !ok {
+		return
+	}
+
+	detail, err := h.contactDetail(r.Context(), workspaceID(r), jid)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get contact: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if detail.Sources.Database == SourceNotFound && detail.Sources.WhatsApp != SourceOK {
+		message := tr(r, "contact_not_found")
+		if detail.Sources.WhatsApp == SourceUnavailable {
+			message = tr(r, "contact_not_found_offline")
+		}
+		jsonError(w, message, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ContactDetailResponse{
+		Success: true,
+		Message: tr(r, "contact_retrieved"),
+		Contact: detail,
+	})
+}
+
+// contactDetail gathers a contact from every source, listing only the groups
+// of the workspace. Database errors fail the whole lookup; WhatsApp being
+// unreachable only marks it unavailable.
+func (h *ContactHandler) contactDetail(ctx context.Context, workspaceID int64, jid string) (*ContactDetail, error) {
+	detail := &ContactDetail{
+		JID:     jid,
+		Groups:  []ContactGroupRef{},
+		Sources: ContactSources{WhatsApp: SourceUnavailable, Database: SourceNotFound},
+	}
+
+	if h.client.IsConnected() {
+		lookupCtx, cancel := context.WithTimeout(ctx, contactLookupTimeout)
+		contact, err := h.client.FindContactByJID(lookupCtx, jid)
+		cancel()
+		switch {
+		case err != nil:
+			// Disconnected since the check, or the store failed; the rest still helps
+		case contact == nil:
+			detail.Sources.WhatsApp = SourceNotFound
+		default:
+			detail.Sources.WhatsApp = SourceOK
+			detail.WhatsApp = contact
+			if contact.Name != "" {
+				detail.Name = contact.Name
+				detail.NameSource = "whatsapp"
+			}
+		}
+	}
+
+	known := false
+
+	mergedInto, err := h.mergeRepo.Redirect(jid)
+	if err != nil {
+		return nil, err
+	}
+	detail.MergedInto = mergedInto
+	known = known || mergedInto != ""
+
+	if detail.Attributes, err = h.attrRepo.GetAllForContactAsMap(jid); err != nil {
+		return nil, err
+	}
+	known = known || len(detail.Attributes) > 0
+
+	if detail.Note, err = h.noteRepo.Get(jid); err != nil {
+		return nil, err
+	}
+	known = known || detail.Note != nil
+
+	groupIDs, err := h.memberRepo.GetGroupsForContact(jid)
+	if err != nil {
+		return nil, err
+	}
+	if len(groupIDs) > 0 {
+		groups, err := h.groupRepo.GetAll(workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		member := make(map[int64]bool, len(groupIDs))
+		for _, id := range groupIDs {
+			member[id] = true
+		}
+		for _, group := range groups {
+			if member[group.ID] {
+				detail.Groups = append(detail.Groups, ContactGroupRef{ID: group.ID, Name: group.Name})
+			}
+		}
+		known = true
+	}
+
+	if detail.Failures, err = h.quarantineRepo.Get(jid); err != nil {
+		return nil, err
+	}
+	detail.Quarantined = detail.Failures != nil && detail.Failures.QuarantinedAt != nil
+	known = known || detail.Failures != nil
+
+	if detail.History, err = h.msgRepo.GetHistoryForContact(jid, time.Now().UTC().Add(-contactHistoryWindow).Truncate(time.Second)); err != nil {
+		return nil, err
+	}
+	if detail.History != nil {
+		known = true
+		if detail.Name == "" {
+			name, err := h.msgRepo.LastContactName(jid)
+			if err != nil {
+				return nil, err
+			}
+			if name != "" {
+				detail.Name = name
+				detail.NameSource = "batch"
+			}
+		}
+	}
+
+	if known {
+		detail.Sources.Database = SourceOK
+	}
+	return detail, nil
+}
@@ -1,35 +1,115 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"friday/internal/logging"
+	"friday/internal/models"
 	"friday/internal/whatsapp"
 )
 
 type ContactHandler struct {
-	client *whatsapp.Client
+	client         whatsapp.Messenger
+	attrRepo       *models.AttributeRepository
+	groupRepo      *models.GroupRepository
+	memberRepo     *models.GroupMemberRepository
+	batchRepo      *models.BatchRunRepository
+	mergeRepo      *models.ContactMergeRepository
+	msgRepo        *models.BatchMessageRepository
+	noteRepo       *models.ContactNoteRepository
+	quarantineRepo *models.QuarantineRepository
+	syncRepo       *models.ContactSyncRepository
 }
 
-func NewContactHandler(client *whatsapp.Client) *ContactHandler {
-	return &ContactHandler{client: client}
+func NewContactHandler(
+	client whatsapp.Messenger,
+	attrRepo *models.AttributeRepository,
+	groupRepo *models.GroupRepository,
+	memberRepo *models.GroupMemberRepository,
+	batchRepo *models.BatchRunRepository,
+	mergeRepo *models.ContactMergeRepository,
+	msgRepo *models.BatchMessageRepository,
+	noteRepo *models.ContactNoteRepository,
+	quarantineRepo *models.QuarantineRepository,
+	syncRepo *models.ContactSyncRepository,
+) *ContactHandler {
+	return &ContactHandler{
+		client:         client,
+		attrRepo:       attrRepo,
+		groupRepo:      groupRepo,
+		memberRepo:     memberRepo,
+		batchRepo:      batchRepo,
+		mergeRepo:      mergeRepo,
+		msgRepo:        msgRepo,
+		noteRepo:       noteRepo,
+		quarantineRepo: quarantineRepo,
+		syncRepo:       syncRepo,
+	}
+}
+
+// attrFilterPrefix marks attribute filters in search query parameters: ?attr.plan=pro
+const attrFilterPrefix = "attr."
+
+// Page size limits for GET /api/contacts
+const (
+	defaultContactPageSize = 500
+	maxContactPageSize     = 5000
+)
+
+// Contact export formats for GET /api/contacts/export
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+
+	// exportChunkSize is how many contacts' attributes are loaded per query
+	// while an export streams.
+	exportChunkSize = 500
+)
+
+// contactExportColumns are the CSV columns before the attribute keys. An
+// attribute with one of these names gets the attr. prefix in the header.
+var contactExportColumns = []string{"jid", "phone", "name", "push_name"}
+
+// ContactExportRow is one contact of a JSON export. Attributes holds every
+// attribute key in use, empty where the contact has no value.
+type ContactExportRow struct {
+	JID        string            `json:"jid"`
+	Phone      string            `json:"phone"`
+	Name       string            `json:"name"`
+	PushName   string            `json:"push_name"`
+	Attributes map[string]string `json:"attributes"`
 }
 
 type ContactListResponse struct {
-	Success  bool               `json:"success"`
-	Message  string             `json:"message"`
-	Contacts []whatsapp.Contact `json:"contacts,omitempty"`
-	Count    int                `json:"count"`
+	Success    bool               `json:"success"`
+	Message    string             `json:"message"`
+	Contacts   []whatsapp.Contact `json:"contacts,omitempty"`
+	Count      int                `json:"count"`       // Contacts in this page
+	TotalCount int                `json:"total_count"` // Contacts matching the query across all pages
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
 }
 
 type ContactSearchResponse struct {
-	Success  bool               `json:"success"`
-	Message  string             `json:"message"`
-	Query    string             `json:"query"`
-	Contacts []whatsapp.Contact `json:"contacts,omitempty"`
-	Count    int                `json:"count"`
+	Success    bool              `json:"success"`
+	Message    string            `json:"message"`
+	Query      string            `json:"query"`
+	Filters    map[string]string `json:"filters,omitempty"`      // Attribute filters applied
+	NotInGroup int64             `json:"not_in_group,omitempty"` // Excluded group ID
+	Contacts   []ContactMatch    `json:"contacts,omitempty"`
+	Count      int               `json:"count"`
+}
+
+// ContactMatch is a search result with the attribute values that matched the filters.
+type ContactMatch struct {
+	whatsapp.Contact
+	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
 type PhoneValidationRequest struct {
@@ -42,7 +122,22 @@ type PhoneValidationResponse struct {
 	Results map[string]bool `json:"results,omitempty"`
 }
 
-// HandleGetContacts returns all WhatsApp contacts
+// MergeContactsRequest names the contact to keep and the one folded into it.
+type MergeContactsRequest struct {
+	PrimaryJID   string `json:"primary_jid"`
+	DuplicateJID string `json:"duplicate_jid"`
+	Redirect     bool   `json:"redirect"` // Send to the primary when the duplicate is used later
+}
+
+type MergeContactsResponse struct {
+	Success bool                       `json:"success"`
+	Message string                     `json:"message"`
+	Merge   *models.ContactMergeResult `json:"merge,omitempty"`
+}
+
+// HandleGetContacts returns WhatsApp contacts sorted by name, without blocked ones.
+// Optional query parameters: q (name/phone filter), limit (default 500), offset,
+// include_blocked=true.
 func (h *ContactHandler) HandleGetContacts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -50,38 +145,283 @@ func (h *ContactHandler) HandleGetContacts(w http.ResponseWriter, r *http.Reques
 	}
 
 	if !h.client.IsConnected() {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ContactListResponse{
+		writeJSON(w, http.StatusBadRequest, ContactListResponse{
 			Success: false,
-			Message: "WhatsApp client not connected",
+			Message: tr(r, "whatsapp_not_connected"),
 			Count:   0,
 		})
 		return
 	}
 
-	contacts, err := h.client.GetContacts()
+	limit, offset, err := parsePage(r, defaultContactPageSize, maxContactPageSize)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ContactListResponse{
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// SearchContacts returns everything for an empty query
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	contacts, err := h.client.SearchContacts(query)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ContactListResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to retrieve contacts: %v", err),
 			Count:   0,
 		})
 		return
 	}
+	contacts = h.applyBlocklist(r, contacts)
+
+	total := len(contacts)
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	page := contacts[offset:end]
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ContactListResponse{
-		Success:  true,
-		Message:  "Contacts retrieved successfully",
-		Contacts: contacts,
-		Count:    len(contacts),
+	writeJSON(w, http.StatusOK, ContactListResponse{
+		Success:    true,
+		Message:    tr(r, "contacts_retrieved"),
+		Contacts:   page,
+		Count:      len(page),
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
 	})
 }
 
-// HandleSearchContacts searches contacts by name or phone
+// HandleExportContacts handles GET /api/contacts/export?format=csv|json, one row
+// per contact with a column per attribute key. It exports the WhatsApp contact
+// list followed by contacts only known from their attributes; while
+// disconnected only the latter, without names. group_id={id} exports just that
+// group's members. Rows are written as they are built, so a failure after the
+// first one truncates the download instead of returning an error response.
+func (h *ContactHandler) HandleExportContacts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	log := logging.FromContext(r.Context())
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = ExportFormatCSV
+	}
+	if format != ExportFormatCSV && format != ExportFormatJSON {
+		jsonError(w, fmt.Sprintf("Invalid format %q (use csv or json)", format), http.StatusBadRequest)
+		return
+	}
+
+	var groupID int64
+	if v := r.URL.Query().Get("group_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_group_id_param"), http.StatusBadRequest)
+			return
+		}
+		group, err := h.groupRepo.GetInWorkspace(workspaceID(r), id)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if group == nil {
+			jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+			return
+		}
+		groupID = id
+	}
+
+	rows, err := h.exportRows(groupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve contacts: %v", err), http.StatusInternalServerError)
+		return
+	}
+	keys, err := h.attrRepo.GetAllUniqueKeys()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve attribute keys: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	filename := "friday-contacts"
+	if groupID != 0 {
+		filename += fmt.Sprintf("-group-%d", groupID)
+	}
+	filename += fmt.Sprintf("-%s.%s", time.Now().Format("20060102"), format)
+	if format == ExportFormatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	var write func(ContactExportRow) error
+	var finish func() error
+	if format == ExportFormatCSV {
+		cw := csv.NewWriter(w)
+		header := append([]string{}, contactExportColumns...)
+		for _, key := range keys {
+			for _, column := range contactExportColumns {
+				if key == column {
+					key = attrFilterPrefix + key
+					break
+				}
+			}
+			header = append(header, key)
+		}
+		cw.Write(header)
+		write = func(row ContactExportRow) error {
+			record := append(make([]string, 0, len(header)), row.JID, row.Phone, row.Name, row.PushName)
+			for _, key := range keys {
+				record = append(record, row.Attributes[key])
+			}
+			cw.Write(record)
+			cw.Flush()
+			return cw.Error()
+		}
+		finish = func() error { return nil }
+	} else {
+		fmt.Fprint(w, "[")
+		first := true
+		write = func(row ContactExportRow) error {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			if !first {
+				fmt.Fprint(w, ",")
+			}
+			first = false
+			_, err = fmt.Fprintf(w, "\n%s", data)
+			return err
+		}
+		finish = func() error {
+			_, err := fmt.Fprint(w, "\n]\n")
+			return err
+		}
+	}
+
+	flusher, _ := w.(http.Flusher)
+	for start := 0; start < len(rows); start += exportChunkSize {
+		chunk := rows[start:min(start+exportChunkSize, len(rows))]
+		jids := make([]string, len(chunk))
+		for i, row := range chunk {
+			jids[i] = row.JID
+		}
+		attributes, err := h.attrRepo.GetForContacts(jids, nil)
+		if err != nil {
+			log.Error("Contact export aborted", "error", err)
+			return
+		}
+
+		for _, row := range chunk {
+			row.Attributes = make(map[string]string, len(keys))
+			for _, key := range keys {
+				row.Attributes[key] = attributes[row.JID][key]
+			}
+			if err := write(row); err != nil {
+				log.Warn("Contact export aborted", "error", err)
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if err := finish(); err != nil {
+		log.Warn("Contact export aborted", "error", err)
+		return
+	}
+
+	log.Info("Contacts exported", "format", format, "group_id", groupID, "contacts", len(rows))
+}
+
+// exportRows lists the contacts to export, without their attributes: a group's
+// members in the order they were added, or the WhatsApp contact list by name
+// followed by contacts only known from their attributes.
+func (h *ContactHandler) exportRows(groupID int64) ([]ContactExportRow, error) {
+	var contacts []whatsapp.Contact
+	if h.client.IsConnected() {
+		var err error
+		if contacts, err = h.client.SearchContacts(""); err != nil {
+			return nil, err
+		}
+	}
+	toRow := func(c whatsapp.Contact) ContactExportRow {
+		return ContactExportRow{JID: c.JID.String(), Phone: c.Phone, Name: c.Name, PushName: c.PushName}
+	}
+
+	if groupID != 0 {
+		byJID := make(map[string]whatsapp.Contact, len(contacts))
+		for _, c := range contacts {
+			byJID[c.JID.String()] = c
+		}
+		jids, err := h.memberRepo.GetJIDsByGroup(groupID)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]ContactExportRow, len(jids))
+		for i, jid := range jids {
+			if c, ok := byJID[jid]; ok {
+				rows[i] = toRow(c)
+			} else {
+				rows[i] = ContactExportRow{JID: jid, Phone: extractPhone(jid)}
+			}
+		}
+		return rows, nil
+	}
+
+	rows := make([]ContactExportRow, 0, len(contacts))
+	seen := make(map[string]bool, len(contacts))
+	for _, c := range contacts {
+		row := toRow(c)
+		seen[row.JID] = true
+		rows = append(rows, row)
+	}
+	jids, err := h.attrRepo.GetAllJIDs()
+	if err != nil {
+		return nil, err
+	}
+	for _, jid := range jids {
+		if !seen[jid] {
+			rows = append(rows, ContactExportRow{JID: jid, Phone: extractPhone(jid)})
+		}
+	}
+	return rows, nil
+}
+
+// parsePage reads limit/offset query parameters, applying the default and cap.
+func parsePage(r *http.Request, defaultLimit, maxLimit int) (int, int, error) {
+	limit := defaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("limit must be a positive integer")
+		}
+		limit = n
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	offset := 0
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+		offset = n
+	}
+
+	return limit, offset, nil
+}
+
+// HandleSearchContacts searches contacts by name or phone (q), narrowed by attribute
+// filters (attr.{key}={value}) and excluding members of not_in_group={id}.
+// All conditions are combined with AND. Blocked contacts are left out unless
+// include_blocked=true.
 func (h *ContactHandler) HandleSearchContacts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -89,11 +429,9 @@ func (h *ContactHandler) HandleSearchContacts(w http.ResponseWriter, r *http.Req
 	}
 
 	if !h.client.IsConnected() {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ContactSearchResponse{
+		writeJSON(w, http.StatusBadRequest, ContactSearchResponse{
 			Success: false,
-			Message: "WhatsApp client not connected",
+			Message: tr(r, "whatsapp_not_connected"),
 			Count:   0,
 		})
 		return
@@ -101,11 +439,41 @@ func (h *ContactHandler) HandleSearchContacts(w http.ResponseWriter, r *http.Req
 
 	query := strings.TrimSpace(r.URL.Query().Get("q"))
 
+	filters := make(map[string]string)
+	for param, values := range r.URL.Query() {
+		if !strings.HasPrefix(param, attrFilterPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(param, attrFilterPrefix)
+		if key == "" || len(values) == 0 {
+			jsonError(w, fmt.Sprintf("Invalid attribute filter %q", param), http.StatusBadRequest)
+			return
+		}
+		filters[key] = strings.TrimSpace(values[0])
+	}
+
+	var notInGroup int64
+	if v := r.URL.Query().Get("not_in_group"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_not_in_group_id"), http.StatusBadRequest)
+			return
+		}
+		group, err := h.groupRepo.GetInWorkspace(workspaceID(r), id)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to retrieve group: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if group == nil {
+			jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+			return
+		}
+		notInGroup = id
+	}
+
 	contacts, err := h.client.SearchContacts(query)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ContactSearchResponse{
+		writeJSON(w, http.StatusInternalServerError, ContactSearchResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to search contacts: %v", err),
 			Query:   query,
@@ -113,17 +481,84 @@ func (h *ContactHandler) HandleSearchContacts(w http.ResponseWriter, r *http.Req
 		})
 		return
 	}
+	contacts = h.applyBlocklist(r, contacts)
+
+	var attrMatches map[string]map[string]string
+	if len(filters) > 0 {
+		attrMatches, err = h.attrRepo.FindByValues(filters)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to filter by attributes: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	excluded := make(map[string]bool)
+	if notInGroup != 0 {
+		jids, err := h.memberRepo.GetJIDsByGroup(notInGroup)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to get group members: %v", err), http.StatusInternalServerError)
+			return
+		}
+		for _, jid := range jids {
+			excluded[jid] = true
+		}
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ContactSearchResponse{
-		Success:  true,
-		Message:  "Contact search completed successfully",
-		Query:    query,
-		Contacts: contacts,
-		Count:    len(contacts),
+	results := make([]ContactMatch, 0, len(contacts))
+	for _, contact := range contacts {
+		jid := contact.JID.String()
+		if excluded[jid] {
+			continue
+		}
+		match := ContactMatch{Contact: contact}
+		if attrMatches != nil {
+			values, ok := attrMatches[jid]
+			if !ok {
+				continue
+			}
+			match.Attributes = values
+		}
+		results = append(results, match)
+	}
+
+	writeJSON(w, http.StatusOK, ContactSearchResponse{
+		Success:    true,
+		Message:    tr(r, "contact_search_done"),
+		Query:      query,
+		Filters:    filters,
+		NotInGroup: notInGroup,
+		Contacts:   results,
+		Count:      len(results),
 	})
 }
 
+// applyBlocklist leaves out the contacts the linked account has blocked, or with
+// include_blocked=true keeps them marked as blocked. When the blocklist cannot
+// be fetched the contacts are returned as they are.
+func (h *ContactHandler) applyBlocklist(r *http.Request, contacts []whatsapp.Contact) []whatsapp.Contact {
+	blocked, err := h.client.BlockedJIDs(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).Warn("Failed to get blocklist, blocked contacts are not marked", "error", err)
+		return contacts
+	}
+	if len(blocked) == 0 {
+		return contacts
+	}
+
+	includeBlocked := r.URL.Query().Get("include_blocked") == "true"
+	kept := make([]whatsapp.Contact, 0, len(contacts))
+	for _, contact := range contacts {
+		if blocked[contact.JID.String()] || contact.LID != "" && blocked[contact.LID] {
+			if !includeBlocked {
+				continue
+			}
+			contact.Blocked = true
+		}
+		kept = append(kept, contact)
+	}
+	return kept
+}
+
 // HandleValidatePhones checks if phone numbers are registered on WhatsApp
 func (h *ContactHandler) HandleValidatePhones(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -132,51 +567,103 @@ func (h *ContactHandler) HandleValidatePhones(w http.ResponseWriter, r *http.Req
 	}
 
 	if !h.client.IsConnected() {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(PhoneValidationResponse{
+		writeJSON(w, http.StatusBadRequest, PhoneValidationResponse{
 			Success: false,
-			Message: "WhatsApp client not connected",
+			Message: tr(r, "whatsapp_not_connected"),
 		})
 		return
 	}
 
 	var req PhoneValidationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(PhoneValidationResponse{
-			Success: false,
-			Message: fmt.Sprintf("Invalid JSON in request body: %v", err),
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	if len(req.Phones) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(PhoneValidationResponse{
+		writeJSON(w, http.StatusBadRequest, PhoneValidationResponse{
 			Success: false,
-			Message: "At least one phone number is required",
+			Message: tr(r, "phone_numbers_required"),
 		})
 		return
 	}
 
 	results, err := h.client.ValidatePhones(req.Phones)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(PhoneValidationResponse{
+		writeJSON(w, http.StatusInternalServerError, PhoneValidationResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to validate phone numbers: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(PhoneValidationResponse{
+	writeJSON(w, http.StatusOK, PhoneValidationResponse{
 		Success: true,
-		Message: "Phone validation completed successfully",
+		Message: tr(r, "phone_validation_done"),
 		Results: results,
 	})
 }
+
+// HandleMergeContacts handles POST /api/contacts/merge, moving a duplicate
+// contact's attributes, group memberships and pending batch messages to the
+// primary. Refused while a running batch messages either contact.
+func (h *ContactHandler) HandleMergeContacts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req MergeContactsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.PrimaryJID == "" || req.DuplicateJID == "" {
+		jsonError(w, tr(r, "merge_jids_required"), http.StatusBadRequest)
+		return
+	}
+	primary, ok := requireJID(w, req.PrimaryJID)
+	if !ok {
+		return
+	}
+	duplicate, ok := requireJID(w, req.DuplicateJID)
+	if !ok {
+		return
+	}
+	if primary == duplicate {
+		jsonError(w, tr(r, "merge_same_contact"), http.StatusBadRequest)
+		return
+	}
+
+	target, err := h.mergeRepo.Redirect(primary)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check merged contacts: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if target != "" {
+		jsonError(w, fmt.Sprintf("%s was merged into %s; merge into that contact instead", primary, target), http.StatusConflict)
+		return
+	}
+
+	running, err := h.batchRepo.RunningForJIDs(primary, duplicate)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check running batches: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if len(running) > 0 {
+		jsonError(w, fmt.Sprintf("Cannot merge while batch #%d is sending to either contact", running[0]), http.StatusConflict)
+		return
+	}
+
+	result, err := h.mergeRepo.Merge(primary, duplicate, req.Redirect)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to merge contacts: %v", err), http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("Merged contacts", "primary", primary, "duplicate", duplicate,
+		"attributes", result.AttributesMoved, "groups", result.GroupsMoved, "messages", result.MessagesMoved, "redirected", result.Redirected)
+
+	writeJSON(w, http.StatusOK, MergeContactsResponse{
+		Success: true,
+		Message: tr(r, "contacts_merged", duplicate, primary),
+		Merge:   result,
+	})
+}
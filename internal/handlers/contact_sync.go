@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"friday/internal/logging"
+	"friday/internal/models"
+)
+
+type ContactSyncResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Sync    *models.SyncRun `json:"sync,omitempty"`
+}
+
+type ContactChangesResponse struct {
+	Success bool                   `json:"success"`
+	Message string                 `json:"message"`
+	Changes *models.ContactChanges `json:"changes,omitempty"`
+}
+
+// HandleSyncContacts handles POST /api/contacts/sync, copying the WhatsApp
+// address book into the local snapshot and recording what changed since the
+// last sync. GET returns the last sync.
+func (h *ContactHandler) HandleSyncContacts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		last, err := h.syncRepo.Last()
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to get last contact sync: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if last == nil {
+			jsonError(w, tr(r, "contacts_never_synced"), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, ContactSyncResponse{Success: true, Message: tr(r, "contacts_sync_retrieved"), Sync: last})
+	case http.MethodPost:
+		h.syncContacts(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *ContactHandler) syncContacts(w http.ResponseWriter, r *http.Request) {
+	if !h.client.IsConnected() {
+		jsonError(w, tr(r, "whatsapp_not_connected"), http.StatusServiceUnavailable)
+		return
+	}
+
+	contacts, err := h.client.GetContacts()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve contacts: %v", err), http.StatusInternalServerError)
+		return
+	}
+	// A contact stored under both its number and hidden ID is listed once per JID
+	seen := make(map[string]bool, len(contacts))
+	snapshot := make([]models.SnapshotContact, 0, len(contacts))
+	for _, contact := range contacts {
+		jid := contact.JID.String()
+		if seen[jid] {
+			continue
+		}
+		seen[jid] = true
+		snapshot = append(snapshot, models.SnapshotContact{JID: jid, Name: contact.Name})
+	}
+
+	run, err := h.syncRepo.Sync(snapshot)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to sync contacts: %v", err), http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("Synced contacts", "sync_id", run.ID, "contacts", run.ContactCount,
+		"added", run.AddedCount, "removed", run.RemovedCount, "renamed", run.RenamedCount)
+
+	writeJSON(w, http.StatusOK, ContactSyncResponse{
+		Success: true,
+		Message: tr(r, "contacts_synced", run.ContactCount, run.AddedCount, run.RemovedCount, run.RenamedCount),
+		Sync:    run,
+	})
+}
+
+// HandleContactChanges handles GET /api/contacts/changes?since=, the contacts
+// added, removed and renamed between a sync and the latest one, from the
+// snapshots the syncs took rather than the live address book.
+func (h *ContactHandler) HandleContactChanges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	since, ok := syncSince(w, r, h.syncRepo)
+	if !ok {
+		return
+	}
+	changes, err := h.syncRepo.ChangesSince(since)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get contact changes: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ContactChangesResponse{
+		Success: true,
+		Message: tr(r, "contact_changes_retrieved", len(changes.Added), len(changes.Removed), len(changes.Renamed)),
+		Changes: changes,
+	})
+}
+
+// syncSince resolves the since query parameter of the contact change
+// endpoints to a sync ID: a number is one already, an RFC 3339 time stands for
+// the last sync at or before it. Empty means before the first sync. Writes an
+// error response and returns false when it cannot.
+func syncSince(w http.ResponseWriter, r *http.Request, repo *models.ContactSyncRepository) (int64, bool) {
+	since := r.URL.Query().Get("since")
+	if since == "" {
+		return 0, true
+	}
+	if id, err := strconv.ParseInt(since, 10, 64); err == nil && id >= 0 {
+		return id, true
+	}
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		jsonError(w, tr(r, "contact_changes_invalid_since"), http.StatusBadRequest)
+		return 0, false
+	}
+	id, err := repo.LastAt(t)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to find contact sync: %v", err), http.StatusInternalServerError)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// corsAllowedMethods and corsAllowedHeaders are answered to every preflight.
+const (
+	corsAllowedMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	corsAllowedHeaders = "Content-Type, Authorization, " + IdempotencyHeader + ", Last-Event-ID, " + RequestIDHeader + ", " + ClientNameHeader + ", " + WorkspaceHeader
+	corsExposedHeaders = "Idempotent-Replayed, Content-Disposition, ETag, " + RequestIDHeader
+	corsMaxAge         = "600"
+)
+
+// CORSConfig lists the origins allowed to call /api/ from a browser. With no
+// origins configured no CORS headers are sent, so only same-origin pages work.
+type CORSConfig struct {
+	AllowedOrigins   []string // Exact origins like https://admin.example.com, or "*"
+	AllowCredentials bool     // Allow cookies and Authorization headers
+}
+
+// ParseCORSOrigins splits a comma-separated origin list, dropping empty entries
+// and trailing slashes.
+func ParseCORSOrigins(spec string) []string {
+	var origins []string
+	for _, origin := range strings.Split(spec, ",") {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// CORSHandler adds CORS headers to /api/ responses and answers preflight requests.
+type CORSHandler struct {
+	origins     map[string]bool
+	any         bool
+	credentials bool
+}
+
+// NewCORSHandler creates a new CORS middleware.
+func NewCORSHandler(config CORSConfig) *CORSHandler {
+	h := &CORSHandler{
+		origins:     make(map[string]bool),
+		credentials: config.AllowCredentials,
+	}
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			h.any = true
+			continue
+		}
+		h.origins[strings.ToLower(origin)] = true
+	}
+	return h
+}
+
+// Enabled reports whether any origin is allowed.
+func (h *CORSHandler) Enabled() bool {
+	return h.any || len(h.origins) > 0
+}
+
+// RegisterCapabilities adds cors, fixed at startup.
+func (h *CORSHandler) RegisterCapabilities(c *Capabilities) {
+	c.Register(CapabilityCORS, h.Enabled)
+}
+
+// Wrap applies CORS to requests under /api/; other paths are passed through.
+func (h *CORSHandler) Wrap(next http.Handler) http.Handler {
+	if !h.Enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if !h.any && !h.origins[strings.ToLower(origin)] {
+			if preflight {
+				http.Error(w, "Origin not allowed", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r) // The browser blocks the response without our headers
+			return
+		}
+
+		// "*" cannot be combined with credentials, so the origin is echoed instead.
+		if h.any && !h.credentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if h.credentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
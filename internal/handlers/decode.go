@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Request body limits. Bundle imports carry many drafts at once and get more room.
+const (
+	maxJSONBodyBytes   = 1 << 20
+	maxImportBodyBytes = 10 << 20
+)
+
+// decodeJSON reads a required JSON body into dst, rejecting unknown fields and
+// bodies over maxJSONBodyBytes. On failure it writes the error response and
+// returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	return decodeBody(w, r, dst, maxJSONBodyBytes, false)
+}
+
+// decodeOptionalJSON is decodeJSON for endpoints where the body may be omitted;
+// dst is left untouched when it is.
+func decodeOptionalJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	return decodeBody(w, r, dst, maxJSONBodyBytes, true)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, dst interface{}, limit int64, optional bool) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	if errors.Is(err, io.EOF) && optional {
+		return true
+	}
+	if err == nil {
+		// Anything after the value is a client bug, e.g. two objects concatenated
+		if _, next := dec.Token(); next != io.EOF {
+			err = errTrailingData
+		}
+	}
+	if err == nil {
+		return true
+	}
+
+	status := http.StatusBadRequest
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		status = http.StatusRequestEntityTooLarge
+	}
+	jsonError(w, describeDecodeError(r, err, dst, limit), status)
+	return false
+}
+
+var errTrailingData = errors.New("trailing data")
+
+// describeDecodeError turns a decoding error into a message naming the field or
+// position at fault. Field and type details stay in English.
+func describeDecodeError(r *http.Request, err error, dst interface{}, limit int64) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		if limit%(1<<20) == 0 {
+			return tr(r, "request_body_too_large_mb", limit>>20)
+		}
+		return tr(r, "request_body_too_large_kb", limit>>10)
+	case errors.Is(err, io.EOF):
+		return tr(r, "request_body_empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return tr(r, "json_incomplete")
+	case errors.Is(err, errTrailingData):
+		return tr(r, "json_trailing_data")
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("Invalid JSON at byte %d: %s", syntaxErr.Offset, strings.TrimPrefix(syntaxErr.Error(), "json: "))
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Sprintf("Invalid JSON: expected %s, got %s", jsonTypeName(typeErr.Type), typeErr.Value)
+		}
+		return fmt.Sprintf("Invalid value for field %q: expected %s, got %s", typeErr.Field, jsonTypeName(typeErr.Type), typeErr.Value)
+	}
+
+	// encoding/json reports unknown fields only as text: json: unknown field "x"
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		msg := fmt.Sprintf("Unknown field %s", field)
+		if known := jsonFieldNames(dst); len(known) > 0 {
+			msg += fmt.Sprintf(" (accepted fields: %s)", strings.Join(known, ", "))
+		}
+		return msg
+	}
+	return fmt.Sprintf("Invalid JSON: %v", err)
+}
+
+// jsonTypeName describes a Go type the way a JSON client thinks of it.
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return t.String()
+	}
+}
+
+// jsonFieldNames lists the JSON field names a struct (or slice of structs) accepts.
+func jsonFieldNames(dst interface{}) []string {
+	t := reflect.TypeOf(dst)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
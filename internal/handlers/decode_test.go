@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type request struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	for _, tc := range []struct {
+		name        string
+		body        string
+		optional    bool
+		wantOK      bool
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "valid", body: `{"name":"a","count":2}`, wantOK: true},
+		{name: "unknown field", body: `{"name":"a","colour":"red"}`, wantStatus: http.StatusBadRequest,
+			wantMessage: `Unknown field "colour" (accepted fields: count, name)`},
+		{name: "wrong type", body: `{"count":"two"}`, wantStatus: http.StatusBadRequest,
+			wantMessage: `Invalid value for field "count": expected an integer, got string`},
+		{name: "syntax error", body: `{"name":}`, wantStatus: http.StatusBadRequest,
+			wantMessage: "Invalid JSON at byte 9: invalid character '}' looking for beginning of value"},
+		{name: "incomplete", body: `{"name":"a"`, wantStatus: http.StatusBadRequest, wantMessage: "Invalid JSON: body ends before the value is complete"},
+		{name: "trailing data", body: `{"name":"a"}{"name":"b"}`, wantStatus: http.StatusBadRequest,
+			wantMessage: "Invalid JSON: unexpected data after the JSON value"},
+		{name: "empty", body: ``, wantStatus: http.StatusBadRequest, wantMessage: "Request body is empty, expected a JSON object"},
+		{name: "empty optional", body: ``, optional: true, wantOK: true},
+		{name: "oversized", body: `{"name":"` + strings.Repeat("x", maxJSONBodyBytes) + `"}`, wantStatus: http.StatusRequestEntityTooLarge,
+			wantMessage: "Request body is too large (limit 1 MB)"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			var dst request
+			var ok bool
+			if tc.optional {
+				ok = decodeOptionalJSON(rec, req, &dst)
+			} else {
+				ok = decodeJSON(rec, req, &dst)
+			}
+
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %t, want %t; response %d %s", ok, tc.wantOK, rec.Code, rec.Body)
+			}
+			if ok {
+				return
+			}
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			var resp struct{ Message string }
+			decodeResponse(t, rec, &resp)
+			if resp.Message != tc.wantMessage {
+				t.Errorf("message = %q, want %q", resp.Message, tc.wantMessage)
+			}
+		})
+	}
+}
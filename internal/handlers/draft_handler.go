@@ -1,11 +1,14 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"friday/internal/models"
 	"friday/internal/template"
@@ -13,62 +16,164 @@ import (
 )
 
 type DraftHandler struct {
-	repo       *models.DraftRepository
-	attrRepo   *models.AttributeRepository
-	waClient   *whatsapp.Client
+	repo          *models.DraftRepository
+	variantRepo   *models.DraftVariantRepository
+	attrRepo      *models.AttributeRepository
+	groupAttrRepo *models.GroupAttributeRepository
+	memberRepo    *models.GroupMemberRepository
+	batchRepo     *models.BatchRunRepository
+	settingsRepo  *models.SettingsRepository
+	mergeRepo     *models.ContactMergeRepository
+	outboxRepo    *models.OutboxRepository
+	waClient      whatsapp.Messenger
 }
 
-func NewDraftHandler(repo *models.DraftRepository, attrRepo *models.AttributeRepository, waClient *whatsapp.Client) *DraftHandler {
+func NewDraftHandler(repo *models.DraftRepository, variantRepo *models.DraftVariantRepository, attrRepo *models.AttributeRepository, groupAttrRepo *models.GroupAttributeRepository, memberRepo *models.GroupMemberRepository, batchRepo *models.BatchRunRepository, settingsRepo *models.SettingsRepository, mergeRepo *models.ContactMergeRepository, outboxRepo *models.OutboxRepository, waClient whatsapp.Messenger) *DraftHandler {
 	return &DraftHandler{
-		repo:       repo,
-		attrRepo:   attrRepo,
-		waClient:   waClient,
+		repo:          repo,
+		variantRepo:   variantRepo,
+		attrRepo:      attrRepo,
+		groupAttrRepo: groupAttrRepo,
+		memberRepo:    memberRepo,
+		batchRepo:     batchRepo,
+		settingsRepo:  settingsRepo,
+		mergeRepo:     mergeRepo,
+		outboxRepo:    outboxRepo,
+		waClient:      waClient,
 	}
 }
 
 // Request/Response types
 
 type CreateDraftRequest struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title      string               `json:"title"`
+	Content    string               `json:"content"`
+	Delimiters *template.Delimiters `json:"delimiters,omitempty"` // Defaults to {{ }}
 }
 
 type UpdateDraftRequest struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title      string               `json:"title"`
+	Content    string               `json:"content"`
+	Delimiters *template.Delimiters `json:"delimiters,omitempty"` // Omitted keeps the draft's delimiters
 }
 
 type DraftResponse struct {
-	Success bool                 `json:"success"`
-	Message string               `json:"message"`
-	Draft   *models.MessageDraft `json:"draft,omitempty"`
+	Success  bool                 `json:"success"`
+	Message  string               `json:"message"`
+	Draft    *models.MessageDraft `json:"draft,omitempty"`
+	Warnings []template.Issue     `json:"warnings,omitempty"` // Lint results; errors here block saving
+}
+
+type LintResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Issues  []template.Issue `json:"issues"`
 }
 
 type DraftListResponse struct {
 	Success bool                   `json:"success"`
 	Message string                 `json:"message"`
-	Drafts  []models.MessageDraft  `json:"drafts"`
+	Drafts  []DraftListItem        `json:"drafts"`
 	Count   int                    `json:"count"`
 }
 
+// DraftListItem is a draft with how often it has been used.
+type DraftListItem struct {
+	models.MessageDraft
+	models.DraftUsage
+}
+
+type DraftStatsResponse struct {
+	Success bool               `json:"success"`
+	Message string             `json:"message"`
+	Stats   *models.DraftStats `json:"stats,omitempty"`
+}
+
 type PreviewRequest struct {
-	JID string `json:"jid"` // Contact JID to use for placeholder values
+	JID      string `json:"jid"`       // Contact JID to use for placeholder values
+	SpinSeed int64  `json:"spin_seed"` // Batch spin seed; matches a dry run's plan when set to its seed
+	GroupID  int64  `json:"group_id"`  // Preview as a member of this group, with its placeholder defaults
 }
 
 type PreviewResponse struct {
-	Success bool                   `json:"success"`
-	Message string                 `json:"message"`
-	Preview *template.PreviewResult `json:"preview,omitempty"`
+	Success  bool                    `json:"success"`
+	Message  string                  `json:"message"`
+	Preview  *template.PreviewResult `json:"preview,omitempty"`
+	Variant  *models.DraftVariant    `json:"variant,omitempty"`  // Chosen variant, nil when the parent content is used
+	Warnings []template.Issue        `json:"warnings,omitempty"` // Formatting and length problems of the chosen content
 }
 
 type SendWithDraftRequest struct {
-	JID string `json:"jid"` // Contact JID to send to
+	JID     string `json:"jid"`      // Contact JID to send to
+	GroupID int64  `json:"group_id"` // Send as a member of this group, with its placeholder defaults
+
+	// While WhatsApp is disconnected, queue the filled message in the outbox
+	// instead of failing; the response is then 202 with outbox_id
+	QueueIfDisconnected bool `json:"queue_if_disconnected"`
+
+	// Send to the linked account's own chat instead of answering 409
+	AllowSelf bool `json:"allow_self"`
 }
 
 type SendWithDraftResponse struct {
 	Success     bool   `json:"success"`
 	Message     string `json:"message"`
 	SentMessage string `json:"sent_message,omitempty"` // The actual message that was sent
+	VariantID   int64  `json:"variant_id,omitempty"`   // Variant used, 0 for the parent content
+	OutboxID    int64  `json:"outbox_id,omitempty"`    // Set when the message was queued instead of sent
+}
+
+type SetVariantRequest struct {
+	SelectorKey   string `json:"selector_key"`   // Attribute name, e.g. "lang"
+	SelectorValue string `json:"selector_value"` // Attribute value, e.g. "en"
+	Content       string `json:"content"`
+}
+
+type VariantResponse struct {
+	Success  bool                 `json:"success"`
+	Message  string               `json:"message"`
+	Variant  *models.DraftVariant `json:"variant,omitempty"`
+	Warnings []template.Issue     `json:"warnings,omitempty"` // Lint results as for drafts; errors here block saving
+}
+
+type VariantListResponse struct {
+	Success  bool                  `json:"success"`
+	Message  string                `json:"message"`
+	Variants []models.DraftVariant `json:"variants"`
+	Count    int                   `json:"count"`
+}
+
+// DraftBundleEntry is the portable representation of a draft used by export/import.
+type DraftBundleEntry struct {
+	Title      string               `json:"title"`
+	Content    string               `json:"content"`
+	Delimiters *template.Delimiters `json:"delimiters,omitempty"` // Omitted for {{ }}, as in bundles exported before delimiters could change
+	CreatedAt  time.Time            `json:"created_at,omitempty"`
+	UpdatedAt  time.Time            `json:"updated_at,omitempty"`
+}
+
+// Import conflict strategies, applied when a draft with the same title already exists.
+const (
+	ImportStrategySkip      = "skip"
+	ImportStrategyOverwrite = "overwrite-by-title"
+	ImportStrategyDuplicate = "duplicate"
+
+	// importStrategyOverwriteAlias is the name overwrite-by-title had first; still accepted
+	importStrategyOverwriteAlias = "overwrite"
+)
+
+type DraftImportResult struct {
+	Title   string `json:"title"`
+	Status  string `json:"status"` // created, overwritten, skipped, invalid, error
+	DraftID int64  `json:"draft_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type DraftImportResponse struct {
+	Success  bool                `json:"success"`
+	Message  string              `json:"message"`
+	Strategy string              `json:"strategy"`
+	Results  []DraftImportResult `json:"results"`
 }
 
 // HandleDrafts handles GET /api/drafts (list) and POST /api/drafts (create)
@@ -88,11 +193,56 @@ func (h *DraftHandler) HandleDraft(w http.ResponseWriter, r *http.Request) {
 	// Extract ID from path: /api/drafts/123 -> "123"
 	path := strings.TrimPrefix(r.URL.Path, "/api/drafts/")
 
-	// Check if this is a preview or send request
+	if path == "export" {
+		h.exportDrafts(w, r)
+		return
+	}
+	if path == "import" {
+		h.importDrafts(w, r)
+		return
+	}
+
+	// Check if this is a variants, preview or send request
+	if idx := strings.Index(path, "/variants"); idx != -1 {
+		id, err := strconv.ParseInt(path[:idx], 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_draft_id"), http.StatusBadRequest)
+			return
+		}
+		h.handleVariants(w, r, id, strings.TrimPrefix(path[idx:], "/variants"))
+		return
+	}
+	if strings.HasSuffix(path, "/stats") {
+		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/stats"), 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_draft_id"), http.StatusBadRequest)
+			return
+		}
+		h.getDraftStats(w, r, id)
+		return
+	}
+	if strings.HasSuffix(path, "/duplicate") {
+		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/duplicate"), 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_draft_id"), http.StatusBadRequest)
+			return
+		}
+		h.duplicateDraft(w, r, id)
+		return
+	}
+	if strings.HasSuffix(path, "/lint") {
+		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/lint"), 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_draft_id"), http.StatusBadRequest)
+			return
+		}
+		h.lintDraft(w, r, id)
+		return
+	}
 	if strings.Contains(path, "/preview") {
 		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/preview"), 10, 64)
 		if err != nil {
-			jsonError(w, "Invalid draft ID", http.StatusBadRequest)
+			jsonError(w, tr(r, "invalid_draft_id"), http.StatusBadRequest)
 			return
 		}
 		h.previewDraft(w, r, id)
@@ -101,7 +251,7 @@ func (h *DraftHandler) HandleDraft(w http.ResponseWriter, r *http.Request) {
 	if strings.Contains(path, "/send") {
 		id, err := strconv.ParseInt(strings.TrimSuffix(path, "/send"), 10, 64)
 		if err != nil {
-			jsonError(w, "Invalid draft ID", http.StatusBadRequest)
+			jsonError(w, tr(r, "invalid_draft_id"), http.StatusBadRequest)
 			return
 		}
 		h.sendWithDraft(w, r, id)
@@ -110,7 +260,7 @@ func (h *DraftHandler) HandleDraft(w http.ResponseWriter, r *http.Request) {
 
 	id, err := strconv.ParseInt(path, 10, 64)
 	if err != nil {
-		jsonError(w, "Invalid draft ID", http.StatusBadRequest)
+		jsonError(w, tr(r, "invalid_draft_id"), http.StatusBadRequest)
 		return
 	}
 
@@ -127,78 +277,153 @@ func (h *DraftHandler) HandleDraft(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *DraftHandler) listDrafts(w http.ResponseWriter, r *http.Request) {
-	drafts, err := h.repo.GetAll()
+	drafts, err := h.repo.GetAll(workspaceID(r))
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(DraftListResponse{
+		writeJSON(w, http.StatusInternalServerError, DraftListResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to retrieve drafts: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(DraftListResponse{
+	usage, err := h.repo.Usage()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve draft usage: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]DraftListItem, len(drafts))
+	for i, draft := range drafts {
+		items[i] = DraftListItem{MessageDraft: draft, DraftUsage: usage[draft.ID]}
+	}
+
+	writeJSON(w, http.StatusOK, DraftListResponse{
+		Success: true,
+		Message: tr(r, "drafts_retrieved"),
+		Drafts:  items,
+		Count:   len(items),
+	})
+}
+
+// getDraftStats handles GET /api/drafts/{id}/stats; see models.DraftStats for
+// which batches count.
+func (h *DraftHandler) getDraftStats(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	draft, err := h.repo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if draft == nil {
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+		return
+	}
+
+	stats, err := h.repo.Stats(id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve draft stats: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DraftStatsResponse{
 		Success: true,
-		Message: "Drafts retrieved successfully",
-		Drafts:  drafts,
-		Count:   len(drafts),
+		Message: tr(r, "draft_stats_retrieved"),
+		Stats:   stats,
 	})
 }
 
 func (h *DraftHandler) createDraft(w http.ResponseWriter, r *http.Request) {
 	var req CreateDraftRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(DraftResponse{
-			Success: false,
-			Message: fmt.Sprintf("Invalid JSON: %v", err),
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// Validate required fields
 	if strings.TrimSpace(req.Title) == "" {
-		jsonError(w, "Title is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "title_required"), http.StatusBadRequest)
 		return
 	}
 	if strings.TrimSpace(req.Content) == "" {
-		jsonError(w, "Content is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "content_required"), http.StatusBadRequest)
+		return
+	}
+
+	delimiters := template.DefaultDelimiters
+	if req.Delimiters != nil {
+		delimiters = *req.Delimiters
+	}
+	if err := delimiters.Validate(); err != nil {
+		jsonError(w, tr(r, "invalid_delimiters", err), http.StatusBadRequest)
+		return
+	}
+
+	issues := h.lint(delimiters, req.Content)
+	if template.HasErrors(issues) {
+		writeJSON(w, http.StatusBadRequest, DraftResponse{
+			Success:  false,
+			Message:  tr(r, "template_has_errors"),
+			Warnings: issues,
+		})
 		return
 	}
 
 	draft := &models.MessageDraft{
-		Title:   strings.TrimSpace(req.Title),
-		Content: req.Content,
+		WorkspaceID: workspaceID(r),
+		Title:       strings.TrimSpace(req.Title),
+		Content:     req.Content,
+		Delimiters:  delimiters,
 	}
 
 	if err := h.repo.Create(draft); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(DraftResponse{
+		writeJSON(w, http.StatusInternalServerError, DraftResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to create draft: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(DraftResponse{
-		Success: true,
-		Message: "Draft created successfully",
-		Draft:   draft,
+	writeJSON(w, http.StatusCreated, DraftResponse{
+		Success:  true,
+		Message:  tr(r, "draft_created"),
+		Draft:    draft,
+		Warnings: issues,
+	})
+}
+
+// duplicateDraft handles POST /api/drafts/{id}/duplicate: a new draft with the
+// same content and variants, answered like createDraft.
+func (h *DraftHandler) duplicateDraft(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	draft, err := h.repo.Duplicate(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to duplicate draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if draft == nil {
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, DraftResponse{
+		Success:  true,
+		Message:  tr(r, "draft_duplicated"),
+		Draft:    draft,
+		Warnings: h.lint(draft.Delimiters, draft.Content),
 	})
 }
 
 func (h *DraftHandler) getDraft(w http.ResponseWriter, r *http.Request, id int64) {
-	draft, err := h.repo.GetByID(id)
+	draft, err := h.repo.GetInWorkspace(workspaceID(r), id)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(DraftResponse{
+		writeJSON(w, http.StatusInternalServerError, DraftResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to retrieve draft: %v", err),
 		})
@@ -206,51 +431,74 @@ func (h *DraftHandler) getDraft(w http.ResponseWriter, r *http.Request, id int64
 	}
 
 	if draft == nil {
-		jsonError(w, "Draft not found", http.StatusNotFound)
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(DraftResponse{
+	writeJSON(w, http.StatusOK, DraftResponse{
 		Success: true,
-		Message: "Draft retrieved successfully",
+		Message: tr(r, "draft_retrieved"),
 		Draft:   draft,
 	})
 }
 
 func (h *DraftHandler) updateDraft(w http.ResponseWriter, r *http.Request, id int64) {
 	var req UpdateDraftRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(DraftResponse{
-			Success: false,
-			Message: fmt.Sprintf("Invalid JSON: %v", err),
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// Validate required fields
 	if strings.TrimSpace(req.Title) == "" {
-		jsonError(w, "Title is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "title_required"), http.StatusBadRequest)
 		return
 	}
 	if strings.TrimSpace(req.Content) == "" {
-		jsonError(w, "Content is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "content_required"), http.StatusBadRequest)
+		return
+	}
+
+	var delimiters template.Delimiters
+	if req.Delimiters != nil {
+		delimiters = *req.Delimiters
+		if err := delimiters.Validate(); err != nil {
+			jsonError(w, tr(r, "invalid_delimiters", err), http.StatusBadRequest)
+			return
+		}
+	} else {
+		existing, err := h.repo.GetInWorkspace(workspaceID(r), id)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to retrieve draft: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if existing == nil {
+			jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+			return
+		}
+		delimiters = existing.Delimiters
+	}
+
+	issues := h.lint(delimiters, req.Content)
+	if template.HasErrors(issues) {
+		writeJSON(w, http.StatusBadRequest, DraftResponse{
+			Success:  false,
+			Message:  tr(r, "template_has_errors"),
+			Warnings: issues,
+		})
 		return
 	}
 
 	draft := &models.MessageDraft{
-		ID:      id,
-		Title:   strings.TrimSpace(req.Title),
-		Content: req.Content,
+		ID:          id,
+		WorkspaceID: workspaceID(r),
+		Title:       strings.TrimSpace(req.Title),
+		Content:     req.Content,
+		Delimiters:  delimiters,
 	}
 
 	found, err := h.repo.Update(draft)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(DraftResponse{
+		writeJSON(w, http.StatusInternalServerError, DraftResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to update draft: %v", err),
 		})
@@ -258,24 +506,118 @@ func (h *DraftHandler) updateDraft(w http.ResponseWriter, r *http.Request, id in
 	}
 
 	if !found {
-		jsonError(w, "Draft not found", http.StatusNotFound)
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(DraftResponse{
+	writeJSON(w, http.StatusOK, DraftResponse{
+		Success:  true,
+		Message:  tr(r, "draft_updated"),
+		Draft:    draft,
+		Warnings: issues,
+	})
+}
+
+// lintDraft handles GET /api/drafts/{id}/lint
+func (h *DraftHandler) lintDraft(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	draft, err := h.repo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if draft == nil {
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+		return
+	}
+
+	issues := h.lint(draft.Delimiters, draft.Content)
+
+	writeJSON(w, http.StatusOK, LintResponse{
 		Success: true,
-		Message: "Draft updated successfully",
-		Draft:   draft,
+		Message: fmt.Sprintf("%d issues found", len(issues)),
+		Issues:  issues,
 	})
 }
 
+// lint validates content against the built-in placeholders and the attribute keys in use
+// by contacts or group defaults,
+// then adds the formatting and length warnings. If the keys cannot be loaded the
+// "never set" check is skipped.
+func (h *DraftHandler) lint(d template.Delimiters, content string) []template.Issue {
+	keys, err := h.attrRepo.GetAllUniqueKeys()
+	var groupKeys []string
+	if err == nil {
+		groupKeys, err = h.groupAttrRepo.GetAllKeys()
+	}
+	if err != nil {
+		slog.Error("Failed to load attribute keys for lint", "error", err)
+		keys = nil
+	} else {
+		keys = append(keys, groupKeys...) // Empty when nothing is set: every custom placeholder is unset
+	}
+	issues := d.ValidateTemplate(content, keys)
+	return append(issues, contentWarnings(h.attrRepo, h.settingsRepo, d, content)...)
+}
+
+// contentWarnings lints the WhatsApp formatting of each distinct content and
+// warns when one can render longer than drafts.max_length. If the limit or the
+// attribute values cannot be read, the length check is skipped.
+func contentWarnings(attrRepo *models.AttributeRepository, settingsRepo *models.SettingsRepository, d template.Delimiters, contents ...string) []template.Issue {
+	issues := []template.Issue{}
+	seen := make(map[string]bool, len(contents))
+	var distinct []string
+	for _, content := range contents {
+		if !seen[content] {
+			seen[content] = true
+			distinct = append(distinct, content)
+			issues = append(issues, d.LintFormatting(content)...)
+		}
+	}
+
+	limit, err := settingsRepo.GetInt(models.SettingDraftMaxLength, models.DefaultDraftMaxLength)
+	if err != nil {
+		slog.Error("Failed to read draft length limit", "error", err)
+		return issues
+	}
+	longest, err := attrRepo.LongestValues()
+	if err != nil {
+		slog.Error("Failed to load attribute values for length check", "error", err)
+		return issues
+	}
+	for _, content := range distinct {
+		_, lengthIssues := d.LintLength(content, longest, limit)
+		issues = append(issues, lengthIssues...)
+	}
+	return issues
+}
+
 func (h *DraftHandler) deleteDraft(w http.ResponseWriter, r *http.Request, id int64) {
-	found, err := h.repo.Delete(id)
+	// Queued batches still need the draft when the worker picks them up
+	inUse, err := h.batchRepo.HasPendingForDraft(id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, DraftResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to check batches for draft: %v", err),
+		})
+		return
+	}
+	if inUse {
+		jsonError(w, tr(r, "draft_in_use"), http.StatusConflict)
+		return
+	}
+
+	found, err := h.repo.Delete(workspaceID(r), id)
+	if errors.Is(err, models.ErrHasBatches) {
+		jsonError(w, tr(r, "draft_has_batches"), http.StatusConflict)
+		return
+	}
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(DraftResponse{
+		writeJSON(w, http.StatusInternalServerError, DraftResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to delete draft: %v", err),
 		})
@@ -283,14 +625,13 @@ func (h *DraftHandler) deleteDraft(w http.ResponseWriter, r *http.Request, id in
 	}
 
 	if !found {
-		jsonError(w, "Draft not found", http.StatusNotFound)
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(DraftResponse{
+	writeJSON(w, http.StatusOK, DraftResponse{
 		Success: true,
-		Message: "Draft deleted successfully",
+		Message: tr(r, "draft_deleted"),
 	})
 }
 
@@ -301,57 +642,67 @@ func (h *DraftHandler) previewDraft(w http.ResponseWriter, r *http.Request, id i
 	}
 
 	var req PreviewRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(PreviewResponse{
-			Success: false,
-			Message: fmt.Sprintf("Invalid JSON: %v", err),
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	if req.JID == "" {
-		jsonError(w, "Contact JID is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "contact_jid_required"), http.StatusBadRequest)
+		return
+	}
+	jid, ok := requireJID(w, req.JID)
+	if !ok {
+		return
+	}
+	if jid, ok = redirectJID(w, h.mergeRepo, jid); !ok {
 		return
 	}
+	req.JID = jid
 
 	// Get the draft
-	draft, err := h.repo.GetByID(id)
+	draft, err := h.repo.GetInWorkspace(workspaceID(r), id)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(PreviewResponse{
+		writeJSON(w, http.StatusInternalServerError, PreviewResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to retrieve draft: %v", err),
 		})
 		return
 	}
 	if draft == nil {
-		jsonError(w, "Draft not found", http.StatusNotFound)
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+		return
+	}
+
+	groupDefaults, ok := h.groupDefaults(w, req.GroupID, req.JID)
+	if !ok {
 		return
 	}
 
 	// Get placeholder values
-	values, err := h.getPlaceholderValues(req.JID)
+	values, err := h.getPlaceholderValues(req.JID, groupDefaults)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(PreviewResponse{
+		writeJSON(w, http.StatusInternalServerError, PreviewResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to get placeholder values: %v", err),
 		})
 		return
 	}
 
+	content, variant, err := h.selectContent(draft, values)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to load draft variants: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	// Generate preview
-	preview := template.Preview(draft.Content, values)
+	preview := draft.Delimiters.Preview(content, values, template.SpinSeed(req.SpinSeed, req.JID))
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(PreviewResponse{
-		Success: true,
-		Message: "Preview generated successfully",
-		Preview: &preview,
+	writeJSON(w, http.StatusOK, PreviewResponse{
+		Success:  true,
+		Message:  tr(r, "preview_generated"),
+		Preview:  &preview,
+		Variant:  variant,
+		Warnings: contentWarnings(h.attrRepo, h.settingsRepo, draft.Delimiters, content),
 	})
 }
 
@@ -362,62 +713,79 @@ func (h *DraftHandler) sendWithDraft(w http.ResponseWriter, r *http.Request, id
 		return
 	}
 
-	if !h.waClient.IsConnected() {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(SendWithDraftResponse{
-			Success: false,
-			Message: "WhatsApp client not connected",
-		})
+	if h.waClient.IsReadOnly() {
+		jsonError(w, readOnlyMessage, http.StatusServiceUnavailable)
 		return
 	}
 
 	var req SendWithDraftRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(SendWithDraftResponse{
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	connected := h.waClient.IsConnected()
+	if !connected && !req.QueueIfDisconnected {
+		writeJSON(w, http.StatusBadRequest, SendWithDraftResponse{
 			Success: false,
-			Message: fmt.Sprintf("Invalid JSON: %v", err),
+			Message: tr(r, "whatsapp_not_connected"),
 		})
 		return
 	}
 
 	if req.JID == "" {
-		jsonError(w, "Contact JID is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "contact_jid_required"), http.StatusBadRequest)
+		return
+	}
+	jid, ok := requireJID(w, req.JID)
+	if !ok {
+		return
+	}
+	if jid, ok = redirectJID(w, h.mergeRepo, jid); !ok {
+		return
+	}
+	req.JID = jid
+	if !req.AllowSelf && isSelf(h.waClient, req.JID) {
+		jsonError(w, tr(r, "recipient_is_self"), http.StatusConflict)
 		return
 	}
 
 	// Get the draft
-	draft, err := h.repo.GetByID(id)
+	draft, err := h.repo.GetInWorkspace(workspaceID(r), id)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(SendWithDraftResponse{
+		writeJSON(w, http.StatusInternalServerError, SendWithDraftResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to retrieve draft: %v", err),
 		})
 		return
 	}
 	if draft == nil {
-		jsonError(w, "Draft not found", http.StatusNotFound)
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+		return
+	}
+
+	groupDefaults, ok := h.groupDefaults(w, req.GroupID, req.JID)
+	if !ok {
 		return
 	}
 
 	// Get placeholder values
-	values, err := h.getPlaceholderValues(req.JID)
+	values, err := h.getPlaceholderValues(req.JID, groupDefaults)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(SendWithDraftResponse{
+		writeJSON(w, http.StatusInternalServerError, SendWithDraftResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to get placeholder values: %v", err),
 		})
 		return
 	}
 
+	content, variant, err := h.selectContent(draft, values)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to load draft variants: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	// Fill placeholders
-	filledMessage, missing := template.FillPlaceholders(draft.Content, values)
+	filledMessage, missing := draft.Delimiters.FillPlaceholders(content, values, template.SpinSeed(0, req.JID))
 
 	// Warn if there are missing placeholders but still send
 	warningMsg := ""
@@ -425,32 +793,368 @@ func (h *DraftHandler) sendWithDraft(w http.ResponseWriter, r *http.Request, id
 		warningMsg = fmt.Sprintf(" (warning: unfilled placeholders: %v)", missing)
 	}
 
+	var variantID int64
+	if variant != nil {
+		variantID = variant.ID
+	}
+
+	if !connected {
+		item, ok := queueOutbox(w, h.outboxRepo, h.settingsRepo, req.JID, filledMessage)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusAccepted, SendWithDraftResponse{
+			Success:     true,
+			Message:     tr(r, "message_queued_outbox", item.ID) + warningMsg,
+			SentMessage: filledMessage,
+			VariantID:   variantID,
+			OutboxID:    item.ID,
+		})
+		return
+	}
+
 	// Send the message
-	if err := h.waClient.SendMessage(r.Context(), req.JID, filledMessage); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(SendWithDraftResponse{
+	if _, err := h.waClient.SendMessage(r.Context(), req.JID, filledMessage); err != nil {
+		writeJSON(w, http.StatusInternalServerError, SendWithDraftResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to send message: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(SendWithDraftResponse{
+	writeJSON(w, http.StatusOK, SendWithDraftResponse{
 		Success:     true,
-		Message:     "Message sent successfully" + warningMsg,
+		Message:     tr(r, "message_sent") + warningMsg,
 		SentMessage: filledMessage,
+		VariantID:   variantID,
+	})
+}
+
+// selectContent picks the draft variant matching the recipient's values,
+// falling back to the parent draft content.
+func (h *DraftHandler) selectContent(draft *models.MessageDraft, values map[string]string) (string, *models.DraftVariant, error) {
+	variants, err := h.variantRepo.GetByDraft(draft.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	if variant := models.SelectVariant(variants, values); variant != nil {
+		return variant.Content, variant, nil
+	}
+	return draft.Content, nil, nil
+}
+
+// handleVariants handles /api/drafts/{id}/variants (GET list, POST set)
+// and /api/drafts/{id}/variants/{variantID} (DELETE).
+func (h *DraftHandler) handleVariants(w http.ResponseWriter, r *http.Request, draftID int64, rest string) {
+	draft, err := h.repo.GetInWorkspace(workspaceID(r), draftID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if draft == nil {
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+		return
+	}
+
+	rest = strings.Trim(rest, "/")
+	if rest != "" {
+		variantID, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_variant_id"), http.StatusBadRequest)
+			return
+		}
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.deleteVariant(w, r, draftID, variantID)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.listVariants(w, r, draftID)
+	case http.MethodPost:
+		h.setVariant(w, r, draft)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *DraftHandler) listVariants(w http.ResponseWriter, r *http.Request, draftID int64) {
+	variants, err := h.variantRepo.GetByDraft(draftID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve variants: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, VariantListResponse{
+		Success:  true,
+		Message:  tr(r, "variants_retrieved"),
+		Variants: variants,
+		Count:    len(variants),
+	})
+}
+
+func (h *DraftHandler) setVariant(w http.ResponseWriter, r *http.Request, draft *models.MessageDraft) {
+	var req SetVariantRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.SelectorKey = strings.TrimSpace(req.SelectorKey)
+	req.SelectorValue = strings.TrimSpace(req.SelectorValue)
+	if req.SelectorKey == "" || req.SelectorValue == "" {
+		jsonError(w, tr(r, "variant_selector_required"), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		jsonError(w, tr(r, "content_required"), http.StatusBadRequest)
+		return
+	}
+	issues := h.lint(draft.Delimiters, req.Content)
+	if template.HasErrors(issues) {
+		writeJSON(w, http.StatusBadRequest, VariantResponse{
+			Success:  false,
+			Message:  tr(r, "template_has_errors"),
+			Warnings: issues,
+		})
+		return
+	}
+
+	variant := &models.DraftVariant{
+		DraftID:       draft.ID,
+		SelectorKey:   req.SelectorKey,
+		SelectorValue: req.SelectorValue,
+		Content:       req.Content,
+	}
+	if err := h.variantRepo.Set(variant); err != nil {
+		jsonError(w, fmt.Sprintf("Failed to save variant: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, VariantResponse{
+		Success:  true,
+		Message:  tr(r, "variant_saved"),
+		Variant:  variant,
+		Warnings: issues,
 	})
 }
 
+func (h *DraftHandler) deleteVariant(w http.ResponseWriter, r *http.Request, draftID, variantID int64) {
+	deleted, err := h.variantRepo.Delete(draftID, variantID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to delete variant: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		jsonError(w, tr(r, "variant_not_found"), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, VariantResponse{
+		Success: true,
+		Message: tr(r, "variant_deleted"),
+	})
+}
+
+// exportDrafts handles GET /api/drafts/export, returning all drafts as a JSON bundle download.
+func (h *DraftHandler) exportDrafts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	drafts, err := h.repo.GetAll(workspaceID(r))
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve drafts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	bundle := make([]DraftBundleEntry, len(drafts))
+	for i, d := range drafts {
+		bundle[i] = DraftBundleEntry{
+			Title:     d.Title,
+			Content:   d.Content,
+			CreatedAt: d.CreatedAt,
+			UpdatedAt: d.UpdatedAt,
+		}
+		if d.Delimiters != template.DefaultDelimiters {
+			bundle[i].Delimiters = &d.Delimiters
+		}
+	}
+
+	filename := fmt.Sprintf("friday-drafts-%s.json", time.Now().Format("20060102"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	writeJSON(w, http.StatusOK, bundle)
+}
+
+// importDrafts handles POST /api/drafts/import?strategy=skip|overwrite-by-title|duplicate.
+// The body is the JSON array produced by exportDrafts.
+func (h *DraftHandler) importDrafts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	strategy := r.URL.Query().Get("strategy")
+	switch strategy {
+	case "":
+		strategy = ImportStrategySkip
+	case importStrategyOverwriteAlias:
+		strategy = ImportStrategyOverwrite
+	}
+	if strategy != ImportStrategySkip && strategy != ImportStrategyOverwrite && strategy != ImportStrategyDuplicate {
+		jsonError(w, tr(r, "invalid_import_strategy"), http.StatusBadRequest)
+		return
+	}
+
+	var bundle []DraftBundleEntry
+	if !decodeBody(w, r, &bundle, maxImportBodyBytes, false) {
+		return
+	}
+
+	results := make([]DraftImportResult, 0, len(bundle))
+	imported := 0
+
+	for _, entry := range bundle {
+		result := h.importDraft(workspaceID(r), entry, strategy)
+		if result.Status == "created" || result.Status == "overwritten" {
+			imported++
+		}
+		results = append(results, result)
+	}
+
+	writeJSON(w, http.StatusOK, DraftImportResponse{
+		Success:  true,
+		Message:  tr(r, "drafts_imported", imported, len(bundle)),
+		Strategy: strategy,
+		Results:  results,
+	})
+}
+
+func (h *DraftHandler) importDraft(workspaceID int64, entry DraftBundleEntry, strategy string) DraftImportResult {
+	title := strings.TrimSpace(entry.Title)
+	result := DraftImportResult{Title: title}
+
+	if title == "" || strings.TrimSpace(entry.Content) == "" {
+		result.Status = "invalid"
+		result.Error = "Title and content are required"
+		return result
+	}
+	delimiters := template.DefaultDelimiters
+	if entry.Delimiters != nil {
+		delimiters = *entry.Delimiters
+	}
+	if err := delimiters.Validate(); err != nil {
+		result.Status = "invalid"
+		result.Error = err.Error()
+		return result
+	}
+
+	if strategy != ImportStrategyDuplicate {
+		existing, err := h.repo.GetByTitle(workspaceID, title)
+		if err != nil {
+			result.Status = "error"
+			result.Error = err.Error()
+			return result
+		}
+
+		if existing != nil {
+			result.DraftID = existing.ID
+			if strategy == ImportStrategySkip {
+				result.Status = "skipped"
+				return result
+			}
+			if !h.importable(delimiters, entry.Content, &result) {
+				return result
+			}
+
+			existing.Content = entry.Content
+			existing.Delimiters = delimiters
+			if _, err := h.repo.Update(existing); err != nil {
+				result.Status = "error"
+				result.Error = err.Error()
+				return result
+			}
+			result.Status = "overwritten"
+			return result
+		}
+	}
+
+	if !h.importable(delimiters, entry.Content, &result) {
+		return result
+	}
+	draft := &models.MessageDraft{
+		WorkspaceID: workspaceID,
+		Title:       title,
+		Content:     entry.Content,
+		Delimiters:  delimiters,
+	}
+	if err := h.repo.Create(draft); err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = "created"
+	result.DraftID = draft.ID
+	return result
+}
+
+// importable lints content as creating or updating a draft does: warnings are
+// imported, errors mark the result invalid with their messages.
+func (h *DraftHandler) importable(d template.Delimiters, content string, result *DraftImportResult) bool {
+	var errs []string
+	for _, issue := range h.lint(d, content) {
+		if issue.Severity == template.SeverityError {
+			errs = append(errs, issue.Message)
+		}
+	}
+	if len(errs) == 0 {
+		return true
+	}
+	result.Status = "invalid"
+	result.Error = strings.Join(errs, "; ")
+	return false
+}
+
+// groupDefaults returns the placeholder defaults of the group a message is sent
+// through, nil when groupID is 0. The contact must be a member. On failure it
+// writes the error response and returns false.
+func (h *DraftHandler) groupDefaults(w http.ResponseWriter, groupID int64, jid string) (map[string]string, bool) {
+	if groupID == 0 {
+		return nil, true
+	}
+
+	member, err := h.memberRepo.IsMember(groupID, jid)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group membership: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	if !member {
+		jsonError(w, fmt.Sprintf("Contact is not a member of group %d", groupID), http.StatusBadRequest)
+		return nil, false
+	}
+
+	defaults, err := h.groupAttrRepo.GetByGroupAsMap(groupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get group placeholder defaults: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	return defaults, true
+}
+
 // getPlaceholderValues retrieves all placeholder values for a contact.
-// This merges built-in contact fields with custom attributes.
-func (h *DraftHandler) getPlaceholderValues(jid string) (map[string]string, error) {
+// This merges built-in contact fields, the group defaults and custom attributes,
+// later ones taking precedence.
+func (h *DraftHandler) getPlaceholderValues(jid string, groupDefaults map[string]string) (map[string]string, error) {
 	// Get built-in placeholders from contact
 	var builtIn map[string]string
 	if h.waClient.IsConnected() {
-		contact, _ := h.waClient.FindContactByJID(jid)
+		ctx, cancel := context.WithTimeout(context.Background(), contactLookupTimeout)
+		contact, _ := h.waClient.FindContactByJID(ctx, jid)
+		cancel()
 		if contact != nil {
 			builtIn = template.GetBuiltInPlaceholders(contact)
 		}
@@ -465,15 +1169,13 @@ func (h *DraftHandler) getPlaceholderValues(jid string) (map[string]string, erro
 		return nil, err
 	}
 
-	// Merge: custom attributes override built-in values
-	return template.MergePlaceholders(builtIn, custom), nil
+	// Merge: custom attributes override group defaults, which override built-in values
+	return template.MergePlaceholders(builtIn, groupDefaults, custom), nil
 }
 
 // Helper function for JSON error responses
 func jsonError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	writeJSON(w, statusCode, map[string]interface{}{
 		"success": false,
 		"message": message,
 	})
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// encodeFailedBody is sent instead of a response that could not be encoded.
+const encodeFailedBody = `{"success":false,"message":"Failed to encode response"}` + "\n"
+
+// writeJSON writes payload as a JSON response with the given status. The
+// payload is encoded before anything is sent, so one that cannot be encoded
+// (e.g. a NaN float) becomes a 500 with encodeFailedBody rather than a
+// truncated response under the original status.
+func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(payload); err != nil {
+		slog.Error("Failed to encode JSON response", "request_id", w.Header().Get(RequestIDHeader), "status", status, "error", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, encodeFailedBody)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(body.Bytes()); err != nil {
+		// Usually the client went away; the status is already sent
+		slog.Debug("Failed to write JSON response", "request_id", w.Header().Get(RequestIDHeader), "error", err)
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		payload    any
+		wantStatus int
+		wantBody   string
+	}{
+		{"encodable", map[string]int{"count": 3}, http.StatusCreated, "{\"count\":3}\n"},
+		{"NaN", map[string]float64{"rate": math.NaN()}, http.StatusInternalServerError, encodeFailedBody},
+		{"channel", map[string]any{"events": make(chan int)}, http.StatusInternalServerError, encodeFailedBody},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeJSON(rec, http.StatusCreated, tc.payload)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			if got := rec.Body.String(); got != tc.wantBody {
+				t.Errorf("body = %q, want %q", got, tc.wantBody)
+			}
+		})
+	}
+}
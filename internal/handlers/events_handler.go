@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"friday/internal/events"
+	"friday/internal/whatsapp"
+)
+
+// EventsHandler serves the global server-sent event stream used by the web UI.
+type EventsHandler struct {
+	hub    *events.Hub
+	client whatsapp.SessionState
+}
+
+// NewEventsHandler creates a new events handler.
+func NewEventsHandler(hub *events.Hub, client whatsapp.SessionState) *EventsHandler {
+	return &EventsHandler{hub: hub, client: client}
+}
+
+// StatusEvent is the payload published on the status topic.
+type StatusEvent struct {
+	Connected  bool `json:"connected"`
+	HasSession bool `json:"has_session"`
+	Connecting bool `json:"connecting"`
+	ReadOnly   bool `json:"read_only"`
+}
+
+// HandleEvents handles GET /api/events?topics=status,batch,qr
+//
+// The first event on the stream is a "subscribed" envelope echoing the accepted
+// topics, followed by a status snapshot when the status topic is selected.
+// Every subsequent event uses the {type, payload} envelope.
+func (h *EventsHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	topics := []string{}
+	if raw := strings.TrimSpace(r.URL.Query().Get("topics")); raw != "" {
+		for _, topic := range strings.Split(raw, ",") {
+			topic = strings.TrimSpace(topic)
+			if !events.IsTopic(topic) {
+				jsonError(w, fmt.Sprintf("Unknown topic: %s", topic), http.StatusBadRequest)
+				return
+			}
+			topics = append(topics, topic)
+		}
+	}
+	if len(topics) == 0 {
+		topics = events.AllTopics
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// The stream is long-lived, so lift the server-wide write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	eventCh := h.hub.Subscribe(topics)
+	defer h.hub.Unsubscribe(eventCh)
+
+	writeEnvelope(w, &events.Envelope{
+		Type:    "subscribed",
+		Payload: map[string]interface{}{"topics": topics},
+	})
+	for _, topic := range topics {
+		if topic == events.TopicStatus {
+			writeEnvelope(w, &events.Envelope{Type: events.TopicStatus, Payload: h.CurrentStatus()})
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case event, ok := <-eventCh:
+			if !ok {
+				return
+			}
+			writeEnvelope(w, event)
+			flusher.Flush()
+
+		case <-heartbeat.C:
+			// SSE comment line keeps proxies from closing an idle connection
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// CurrentStatus returns a snapshot of the WhatsApp connection state.
+func (h *EventsHandler) CurrentStatus() StatusEvent {
+	return StatusEvent{
+		Connected:  h.client.IsConnected(),
+		HasSession: h.client.HasSession(),
+		Connecting: h.client.IsConnecting(),
+		ReadOnly:   h.client.IsReadOnly(),
+	}
+}
+
+// PublishStatus publishes the current connection state on the status topic.
+// It is registered as the WhatsApp client's status handler.
+func (h *EventsHandler) PublishStatus(connected bool) {
+	status := h.CurrentStatus()
+	status.Connected = connected
+	h.hub.Publish(events.TopicStatus, status)
+}
+
+func writeEnvelope(w http.ResponseWriter, event *events.Envelope) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "data: %s\n\n", data)
+}
@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -14,14 +14,26 @@ import (
 type GroupHandler struct {
 	groupRepo  *models.GroupRepository
 	memberRepo *models.GroupMemberRepository
-	waClient   *whatsapp.Client
+	groupAttrRepo *models.GroupAttributeRepository
+	draftRepo  *models.DraftRepository
+	batchRepo  *models.BatchRunRepository
+	quarantineRepo *models.QuarantineRepository
+	mergeRepo  *models.ContactMergeRepository
+	syncRepo   *models.ContactSyncRepository
+	waClient   whatsapp.Messenger
 }
 
 // NewGroupHandler creates a new group handler with required dependencies.
-func NewGroupHandler(groupRepo *models.GroupRepository, memberRepo *models.GroupMemberRepository, waClient *whatsapp.Client) *GroupHandler {
+func NewGroupHandler(groupRepo *models.GroupRepository, memberRepo *models.GroupMemberRepository, groupAttrRepo *models.GroupAttributeRepository, draftRepo *models.DraftRepository, batchRepo *models.BatchRunRepository, quarantineRepo *models.QuarantineRepository, mergeRepo *models.ContactMergeRepository, syncRepo *models.ContactSyncRepository, waClient whatsapp.Messenger) *GroupHandler {
 	return &GroupHandler{
 		groupRepo:  groupRepo,
 		memberRepo: memberRepo,
+		groupAttrRepo: groupAttrRepo,
+		draftRepo:  draftRepo,
+		batchRepo:  batchRepo,
+		quarantineRepo: quarantineRepo,
+		mergeRepo:  mergeRepo,
+		syncRepo:   syncRepo,
 		waClient:   waClient,
 	}
 }
@@ -29,17 +41,51 @@ func NewGroupHandler(groupRepo *models.GroupRepository, memberRepo *models.Group
 // Request/Response types
 
 type CreateGroupRequest struct {
-	Name string `json:"name"`
+	Name           string `json:"name"`
+	DefaultDraftID *int64 `json:"default_draft_id,omitempty"`
 }
 
 type UpdateGroupRequest struct {
-	Name string `json:"name"`
+	Name           string `json:"name"`
+	DefaultDraftID *int64 `json:"default_draft_id,omitempty"` // Omit to keep the current default, 0 to clear it
+}
+
+// SetGroupAttributeRequest sets one placeholder default of a group.
+type SetGroupAttributeRequest struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type GroupAttributeResponse struct {
+	Success    bool                    `json:"success"`
+	Message    string                  `json:"message"`
+	Attribute  *models.GroupAttribute  `json:"attribute,omitempty"`
+	Attributes []models.GroupAttribute `json:"attributes,omitempty"`
 }
 
 type AddMembersRequest struct {
 	JIDs []string `json:"jids"`
 }
 
+// ReorderMembersRequest is the body of PUT /api/groups/{id}/members/order.
+type ReorderMembersRequest struct {
+	JIDs []string `json:"jids"` // Members to put first, in this order; the rest follow in their current order
+}
+
+// MoveMembersRequest is the body of POST /api/groups/{id}/members/move.
+type MoveMembersRequest struct {
+	TargetGroupID int64    `json:"target_group_id"`
+	JIDs          []string `json:"jids"`
+}
+
+type MoveMembersResponse struct {
+	Success  bool                `json:"success"`
+	Message  string              `json:"message"`
+	Moved    int                 `json:"moved"`     // Including members the target group had already
+	NotFound int                 `json:"not_found"` // JIDs that are not members of the source group
+	Results  []models.MemberMove `json:"results"`   // One per JID, in request order
+}
+
 type GroupResponse struct {
 	Success bool                  `json:"success"`
 	Message string                `json:"message"`
@@ -66,8 +112,10 @@ type GroupMemberInfo struct {
 	ID      int64  `json:"id"`
 	JID     string `json:"jid"`
 	Name    string `json:"name"`
-	Phone   string `json:"phone"`
+	Phone   string `json:"phone"` // Empty for hidden numbers (LIDs)
+	JIDType string `json:"jid_type"` // whatsapp.JIDTypePhone or whatsapp.JIDTypeLID
 	AddedAt string `json:"added_at"`
+	Quarantined bool `json:"quarantined,omitempty"` // Left out of new batches, see /api/contacts/quarantined
 }
 
 type MembersResponse struct {
@@ -75,6 +123,14 @@ type MembersResponse struct {
 	Message string            `json:"message"`
 	Members []GroupMemberInfo `json:"members,omitempty"`
 	Count   int               `json:"count"`
+	Warnings []string         `json:"warnings,omitempty"` // Added members that batches will not be able to send to
+}
+
+// MemberCountResponse is the body of GET /api/groups/{id}/members/count.
+type MemberCountResponse struct {
+	Success bool  `json:"success"`
+	GroupID int64 `json:"group_id"`
+	Count   int   `json:"count"`
 }
 
 // HandleGroups handles GET /api/groups (list) and POST /api/groups (create)
@@ -91,16 +147,28 @@ func (h *GroupHandler) HandleGroups(w http.ResponseWriter, r *http.Request) {
 
 // HandleGroup handles single group operations: GET/PUT/DELETE /api/groups/{id}
 // Also handles member operations: POST/GET /api/groups/{id}/members
+// and placeholder defaults: GET/PUT/DELETE /api/groups/{id}/attributes
 func (h *GroupHandler) HandleGroup(w http.ResponseWriter, r *http.Request) {
 	// Extract path after /api/groups/
 	path := strings.TrimPrefix(r.URL.Path, "/api/groups/")
 
+	// Placeholder defaults: /api/groups/{id}/attributes[/{key}]
+	if idStr, rest, ok := strings.Cut(path, "/attributes"); ok && (rest == "" || strings.HasPrefix(rest, "/")) {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			jsonError(w, tr(r, "invalid_group_id"), http.StatusBadRequest)
+			return
+		}
+		h.handleAttributes(w, r, id, strings.TrimPrefix(rest, "/"))
+		return
+	}
+
 	// Check if this is a members operation: /api/groups/{id}/members
 	if strings.Contains(path, "/members") {
 		parts := strings.Split(path, "/members")
 		id, err := strconv.ParseInt(parts[0], 10, 64)
 		if err != nil {
-			jsonError(w, "Invalid group ID", http.StatusBadRequest)
+			jsonError(w, tr(r, "invalid_group_id"), http.StatusBadRequest)
 			return
 		}
 
@@ -110,6 +178,40 @@ func (h *GroupHandler) HandleGroup(w http.ResponseWriter, r *http.Request) {
 			memberJID = strings.TrimPrefix(parts[1], "/")
 		}
 
+		if memberJID == "add-new-since" {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			h.addNewSince(w, r, id)
+			return
+		}
+
+		if memberJID == "count" {
+			if r.Method != http.MethodGet {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			h.countMembers(w, r, id)
+			return
+		}
+		if memberJID == "move" {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			h.moveMembers(w, r, id)
+			return
+		}
+		if memberJID == "order" {
+			if r.Method != http.MethodPut {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			h.reorderMembers(w, r, id)
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			h.getMembers(w, r, id)
@@ -119,7 +221,7 @@ func (h *GroupHandler) HandleGroup(w http.ResponseWriter, r *http.Request) {
 			if memberJID != "" {
 				h.removeMember(w, r, id, memberJID)
 			} else {
-				jsonError(w, "Member JID required for deletion", http.StatusBadRequest)
+				jsonError(w, tr(r, "member_jid_required"), http.StatusBadRequest)
 			}
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -130,7 +232,7 @@ func (h *GroupHandler) HandleGroup(w http.ResponseWriter, r *http.Request) {
 	// Parse group ID
 	id, err := strconv.ParseInt(path, 10, 64)
 	if err != nil {
-		jsonError(w, "Invalid group ID", http.StatusBadRequest)
+		jsonError(w, tr(r, "invalid_group_id"), http.StatusBadRequest)
 		return
 	}
 
@@ -147,21 +249,18 @@ func (h *GroupHandler) HandleGroup(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *GroupHandler) listGroups(w http.ResponseWriter, r *http.Request) {
-	groups, err := h.groupRepo.GetAll()
+	groups, err := h.groupRepo.GetAll(workspaceID(r))
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(GroupListResponse{
+		writeJSON(w, http.StatusInternalServerError, GroupListResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to retrieve groups: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(GroupListResponse{
+	writeJSON(w, http.StatusOK, GroupListResponse{
 		Success: true,
-		Message: "Groups retrieved successfully",
+		Message: tr(r, "groups_retrieved"),
 		Groups:  groups,
 		Count:   len(groups),
 	})
@@ -169,67 +268,64 @@ func (h *GroupHandler) listGroups(w http.ResponseWriter, r *http.Request) {
 
 func (h *GroupHandler) createGroup(w http.ResponseWriter, r *http.Request) {
 	var req CreateGroupRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(GroupResponse{
-			Success: false,
-			Message: fmt.Sprintf("Invalid JSON: %v", err),
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	name := strings.TrimSpace(req.Name)
 	if name == "" {
-		jsonError(w, "Group name is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "group_name_required"), http.StatusBadRequest)
 		return
 	}
 
 	// Check if name already exists
-	existing, err := h.groupRepo.GetByName(name)
+	existing, err := h.groupRepo.GetByName(workspaceID(r), name)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(GroupResponse{
+		writeJSON(w, http.StatusInternalServerError, GroupResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to check group name: %v", err),
 		})
 		return
 	}
 	if existing != nil {
-		jsonError(w, "A group with this name already exists", http.StatusConflict)
+		jsonError(w, tr(r, "group_name_taken"), http.StatusConflict)
+		return
+	}
+
+	if !h.checkDefaultDraft(w, r, req.DefaultDraftID) {
 		return
 	}
 
 	group := &models.ContactGroup{
-		Name: name,
+		WorkspaceID:    workspaceID(r),
+		Name:           name,
+		DefaultDraftID: nonZero(req.DefaultDraftID),
 	}
 
 	if err := h.groupRepo.Create(group); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(GroupResponse{
+		writeJSON(w, http.StatusInternalServerError, GroupResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to create group: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(GroupResponse{
+	// Re-read so the response carries the default draft's title
+	if created, err := h.groupRepo.GetByID(group.ID); err == nil && created != nil {
+		group = created
+	}
+
+	writeJSON(w, http.StatusCreated, GroupResponse{
 		Success: true,
-		Message: "Group created successfully",
+		Message: tr(r, "group_created"),
 		Group:   group,
 	})
 }
 
 func (h *GroupHandler) getGroup(w http.ResponseWriter, r *http.Request, id int64) {
-	group, err := h.groupRepo.GetByID(id)
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), id)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(GroupDetailResponse{
+		writeJSON(w, http.StatusInternalServerError, GroupDetailResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to retrieve group: %v", err),
 		})
@@ -237,26 +333,23 @@ func (h *GroupHandler) getGroup(w http.ResponseWriter, r *http.Request, id int64
 	}
 
 	if group == nil {
-		jsonError(w, "Group not found", http.StatusNotFound)
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
 		return
 	}
 
 	// Get members with contact info
 	members, err := h.getMembersWithInfo(id)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(GroupDetailResponse{
+		writeJSON(w, http.StatusInternalServerError, GroupDetailResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to retrieve members: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(GroupDetailResponse{
+	writeJSON(w, http.StatusOK, GroupDetailResponse{
 		Success: true,
-		Message: "Group retrieved successfully",
+		Message: tr(r, "group_retrieved"),
 		Group:   group,
 		Members: members,
 	})
@@ -264,48 +357,58 @@ func (h *GroupHandler) getGroup(w http.ResponseWriter, r *http.Request, id int64
 
 func (h *GroupHandler) updateGroup(w http.ResponseWriter, r *http.Request, id int64) {
 	var req UpdateGroupRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(GroupResponse{
-			Success: false,
-			Message: fmt.Sprintf("Invalid JSON: %v", err),
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	name := strings.TrimSpace(req.Name)
 	if name == "" {
-		jsonError(w, "Group name is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "group_name_required"), http.StatusBadRequest)
 		return
 	}
 
 	// Check if another group has this name
-	existing, err := h.groupRepo.GetByName(name)
+	existing, err := h.groupRepo.GetByName(workspaceID(r), name)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(GroupResponse{
+		writeJSON(w, http.StatusInternalServerError, GroupResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to check group name: %v", err),
 		})
 		return
 	}
 	if existing != nil && existing.ID != id {
-		jsonError(w, "A group with this name already exists", http.StatusConflict)
+		jsonError(w, tr(r, "group_name_taken"), http.StatusConflict)
 		return
 	}
 
+	current, err := h.groupRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if current == nil {
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+		return
+	}
+
+	defaultDraftID := current.DefaultDraftID
+	if req.DefaultDraftID != nil {
+		if !h.checkDefaultDraft(w, r, req.DefaultDraftID) {
+			return
+		}
+		defaultDraftID = nonZero(req.DefaultDraftID)
+	}
+
 	group := &models.ContactGroup{
-		ID:   id,
-		Name: name,
+		ID:             id,
+		WorkspaceID:    workspaceID(r),
+		Name:           name,
+		DefaultDraftID: defaultDraftID,
 	}
 
 	found, err := h.groupRepo.Update(group)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(GroupResponse{
+		writeJSON(w, http.StatusInternalServerError, GroupResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to update group: %v", err),
 		})
@@ -313,24 +416,69 @@ func (h *GroupHandler) updateGroup(w http.ResponseWriter, r *http.Request, id in
 	}
 
 	if !found {
-		jsonError(w, "Group not found", http.StatusNotFound)
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(GroupResponse{
+	// Re-read so the response carries the default draft's title
+	if updated, err := h.groupRepo.GetByID(id); err == nil && updated != nil {
+		group = updated
+	}
+
+	writeJSON(w, http.StatusOK, GroupResponse{
 		Success: true,
-		Message: "Group updated successfully",
+		Message: tr(r, "group_updated"),
 		Group:   group,
 	})
 }
 
+// checkDefaultDraft writes a 400 and returns false if a requested default draft
+// does not exist in the request's workspace. nil and 0 (no default) always pass.
+func (h *GroupHandler) checkDefaultDraft(w http.ResponseWriter, r *http.Request, draftID *int64) bool {
+	if draftID == nil || *draftID == 0 {
+		return true
+	}
+	draft, err := h.draftRepo.GetInWorkspace(workspaceID(r), *draftID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check draft: %v", err), http.StatusInternalServerError)
+		return false
+	}
+	if draft == nil {
+		jsonError(w, fmt.Sprintf("Default draft %d not found", *draftID), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// nonZero maps an optional ID of 0 to nil.
+func nonZero(id *int64) *int64 {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	return id
+}
+
 func (h *GroupHandler) deleteGroup(w http.ResponseWriter, r *http.Request, id int64) {
-	found, err := h.groupRepo.Delete(id)
+	inUse, err := h.batchRepo.HasPendingForGroup(id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, GroupResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to check batches for group: %v", err),
+		})
+		return
+	}
+	if inUse {
+		jsonError(w, tr(r, "group_in_use"), http.StatusConflict)
+		return
+	}
+
+	found, err := h.groupRepo.Delete(workspaceID(r), id)
+	if errors.Is(err, models.ErrHasBatches) {
+		jsonError(w, tr(r, "group_has_batches"), http.StatusConflict)
+		return
+	}
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(GroupResponse{
+		writeJSON(w, http.StatusInternalServerError, GroupResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to delete group: %v", err),
 		})
@@ -338,14 +486,13 @@ func (h *GroupHandler) deleteGroup(w http.ResponseWriter, r *http.Request, id in
 	}
 
 	if !found {
-		jsonError(w, "Group not found", http.StatusNotFound)
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(GroupResponse{
+	writeJSON(w, http.StatusOK, GroupResponse{
 		Success: true,
-		Message: "Group deleted successfully",
+		Message: tr(r, "group_deleted"),
 	})
 }
 
@@ -353,79 +500,116 @@ func (h *GroupHandler) deleteGroup(w http.ResponseWriter, r *http.Request, id in
 
 func (h *GroupHandler) getMembers(w http.ResponseWriter, r *http.Request, groupID int64) {
 	// Verify group exists
-	group, err := h.groupRepo.GetByID(groupID)
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), groupID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(MembersResponse{
+		writeJSON(w, http.StatusInternalServerError, MembersResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to check group: %v", err),
 		})
 		return
 	}
 	if group == nil {
-		jsonError(w, "Group not found", http.StatusNotFound)
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
 		return
 	}
 
 	members, err := h.getMembersWithInfo(groupID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(MembersResponse{
+		writeJSON(w, http.StatusInternalServerError, MembersResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to retrieve members: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(MembersResponse{
+	writeJSON(w, http.StatusOK, MembersResponse{
 		Success: true,
-		Message: "Members retrieved successfully",
+		Message: tr(r, "members_retrieved"),
 		Members: members,
 		Count:   len(members),
 	})
 }
 
+// countMembers handles GET /api/groups/{id}/members/count, for callers that need
+// only the number and not each member's contact details.
+func (h *GroupHandler) countMembers(w http.ResponseWriter, r *http.Request, groupID int64) {
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), groupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, MemberCountResponse{
+		Success: true,
+		GroupID: group.ID,
+		Count:   group.MemberCount,
+	})
+}
+
 func (h *GroupHandler) addMembers(w http.ResponseWriter, r *http.Request, groupID int64) {
 	// Verify group exists
-	group, err := h.groupRepo.GetByID(groupID)
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), groupID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(MembersResponse{
+		writeJSON(w, http.StatusInternalServerError, MembersResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to check group: %v", err),
 		})
 		return
 	}
 	if group == nil {
-		jsonError(w, "Group not found", http.StatusNotFound)
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
 		return
 	}
 
 	var req AddMembersRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(MembersResponse{
-			Success: false,
-			Message: fmt.Sprintf("Invalid JSON: %v", err),
-		})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	if len(req.JIDs) == 0 {
-		jsonError(w, "At least one JID is required", http.StatusBadRequest)
+		jsonError(w, tr(r, "jids_required"), http.StatusBadRequest)
 		return
 	}
 
+	jids, err := normalizeJIDList(req.JIDs)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.addJIDs(w, r, groupID, jids)
+}
+
+// addJIDs adds normalized JIDs to a group and answers with its member list.
+func (h *GroupHandler) addJIDs(w http.ResponseWriter, r *http.Request, groupID int64, jids []string) {
+	// Merged contacts are added as the contact they were merged into
+	jids, err := h.mergeRepo.ApplyRedirects(jids)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check merged contacts: %v", err), http.StatusInternalServerError)
+		return
+	}
+	// Hidden numbers are stored as their phone number when WhatsApp has given one
+	jids, unresolved, err := resolveLIDs(h.waClient, jids)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to resolve hidden numbers: %v", err), http.StatusInternalServerError)
+		return
+	}
+	var warnings []string
+	for _, jid := range unresolved {
+		warnings = append(warnings, fmt.Sprintf("%s is a hidden number (LID) with no known phone number; batches will mark it failed", jid))
+	}
+	for _, jid := range jids {
+		if isSelf(h.waClient, jid) {
+			warnings = append(warnings, fmt.Sprintf("%s is the linked account; batches leave it out unless created with allow_self", jid))
+		}
+	}
+
 	// Add members
-	if err := h.memberRepo.AddMultiple(groupID, req.JIDs); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(MembersResponse{
+	if err := h.memberRepo.AddMultiple(groupID, jids); err != nil {
+		writeJSON(w, http.StatusInternalServerError, MembersResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to add members: %v", err),
 		})
@@ -435,37 +619,193 @@ func (h *GroupHandler) addMembers(w http.ResponseWriter, r *http.Request, groupI
 	// Return updated member list
 	members, _ := h.getMembersWithInfo(groupID)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(MembersResponse{
+	writeJSON(w, http.StatusOK, MembersResponse{
 		Success: true,
-		Message: fmt.Sprintf("Added %d members to group", len(req.JIDs)),
+		Message: tr(r, "members_added", len(jids)),
 		Members: members,
 		Count:   len(members),
+		Warnings: warnings,
 	})
 }
 
+// addNewSince handles POST /api/groups/{id}/members/add-new-since?since=,
+// adding every contact the syncs after since saw appear and still present in
+// the latest one, as GET /api/contacts/changes lists them.
+func (h *GroupHandler) addNewSince(w http.ResponseWriter, r *http.Request, groupID int64) {
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), groupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+		return
+	}
+
+	since, ok := syncSince(w, r, h.syncRepo)
+	if !ok {
+		return
+	}
+	changes, err := h.syncRepo.ChangesSince(since)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get contact changes: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if len(changes.Added) == 0 {
+		jsonError(w, tr(r, "contact_changes_none_added"), http.StatusBadRequest)
+		return
+	}
+
+	added := make([]string, 0, len(changes.Added))
+	for _, change := range changes.Added {
+		added = append(added, change.JID)
+	}
+	jids, err := normalizeJIDList(added)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.addJIDs(w, r, groupID, jids)
+}
+
+// reorderMembers handles PUT /api/groups/{id}/members/order, setting the order
+// batches sent in group order follow. Members added later go to the end.
+func (h *GroupHandler) reorderMembers(w http.ResponseWriter, r *http.Request, groupID int64) {
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), groupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+		return
+	}
+
+	var req ReorderMembersRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.JIDs) == 0 {
+		jsonError(w, tr(r, "jids_required"), http.StatusBadRequest)
+		return
+	}
+	jids, err := normalizeJIDList(req.JIDs)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	unknown, err := h.memberRepo.Reorder(groupID, jids)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to reorder members: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if len(unknown) > 0 {
+		jsonError(w, tr(r, "members_order_unknown", strings.Join(unknown, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	members, err := h.getMembersWithInfo(groupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve members: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, MembersResponse{
+		Success: true,
+		Message: tr(r, "members_reordered"),
+		Members: members,
+		Count:   len(members),
+	})
+}
+
+// moveMembers handles POST /api/groups/{id}/members/move, taking members out of
+// the group and into the target one at once. A member the target already has
+// still counts as moved.
+func (h *GroupHandler) moveMembers(w http.ResponseWriter, r *http.Request, groupID int64) {
+	var req MoveMembersRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.JIDs) == 0 {
+		jsonError(w, tr(r, "jids_required"), http.StatusBadRequest)
+		return
+	}
+	if req.TargetGroupID == groupID {
+		jsonError(w, tr(r, "members_move_same_group"), http.StatusBadRequest)
+		return
+	}
+	jids, err := normalizeJIDList(req.JIDs)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, id := range []int64{groupID, req.TargetGroupID} {
+		group, err := h.groupRepo.GetInWorkspace(workspaceID(r), id)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if group == nil {
+			jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+			return
+		}
+	}
+
+	// As with deletion, a batch that has not finished still sends to the source group's members
+	inUse, err := h.batchRepo.HasPendingForGroup(groupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check batches for group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if inUse {
+		jsonError(w, tr(r, "group_in_use"), http.StatusConflict)
+		return
+	}
+
+	results, err := h.memberRepo.Move(groupID, req.TargetGroupID, jids)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to move members: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := MoveMembersResponse{Success: true, Results: results}
+	for _, result := range results {
+		if result.Result == models.MemberNotInSource {
+			resp.NotFound++
+		} else {
+			resp.Moved++
+		}
+	}
+	resp.Message = tr(r, "members_moved", resp.Moved, resp.NotFound)
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
 func (h *GroupHandler) removeMember(w http.ResponseWriter, r *http.Request, groupID int64, jid string) {
 	// Verify group exists
-	group, err := h.groupRepo.GetByID(groupID)
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), groupID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(MembersResponse{
+		writeJSON(w, http.StatusInternalServerError, MembersResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to check group: %v", err),
 		})
 		return
 	}
 	if group == nil {
-		jsonError(w, "Group not found", http.StatusNotFound)
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
 		return
 	}
 
+	// Members stored before JIDs were normalized may not parse; they are removed as given
+	if normalized, err := whatsapp.NormalizeJID(jid); err == nil {
+		jid = normalized
+	}
+
 	found, err := h.memberRepo.Remove(groupID, jid)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(MembersResponse{
+		writeJSON(w, http.StatusInternalServerError, MembersResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to remove member: %v", err),
 		})
@@ -473,17 +813,16 @@ func (h *GroupHandler) removeMember(w http.ResponseWriter, r *http.Request, grou
 	}
 
 	if !found {
-		jsonError(w, "Member not found in group", http.StatusNotFound)
+		jsonError(w, tr(r, "member_not_found"), http.StatusNotFound)
 		return
 	}
 
 	// Return updated member list
 	members, _ := h.getMembersWithInfo(groupID)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(MembersResponse{
+	writeJSON(w, http.StatusOK, MembersResponse{
 		Success: true,
-		Message: "Member removed successfully",
+		Message: tr(r, "member_removed"),
 		Members: members,
 		Count:   len(members),
 	})
@@ -496,6 +835,17 @@ func (h *GroupHandler) getMembersWithInfo(groupID int64) ([]GroupMemberInfo, err
 		return nil, err
 	}
 
+	quarantined, err := h.quarantineRepo.QuarantinedJIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	// One pass over the contact list instead of one per member
+	var contacts map[string]whatsapp.Contact
+	if h.waClient.IsConnected() {
+		contacts = whatsapp.ContactsByJID(h.waClient)
+	}
+
 	result := make([]GroupMemberInfo, len(members))
 	for i, m := range members {
 		info := GroupMemberInfo{
@@ -503,16 +853,20 @@ func (h *GroupHandler) getMembersWithInfo(groupID int64) ([]GroupMemberInfo, err
 			JID:     m.JID,
 			Phone:   extractPhone(m.JID),
 			Name:    extractPhone(m.JID), // Default to phone
+			JIDType: whatsapp.JIDTypePhone,
 			AddedAt: m.AddedAt.Format("2006-01-02 15:04"),
+			Quarantined: quarantined[m.JID],
 		}
 
-		// Try to get contact info from WhatsApp
-		if h.waClient.IsConnected() {
-			contact, _ := h.waClient.FindContactByJID(m.JID)
-			if contact != nil {
-				info.Name = contact.Name
-				info.Phone = contact.Phone
-			}
+		if whatsapp.IsLID(m.JID) {
+			info.Name = m.JID
+			info.JIDType = whatsapp.JIDTypeLID
+		}
+
+		if contact, ok := contacts[m.JID]; ok {
+			info.Name = contact.Name
+			info.Phone = contact.Phone
+			info.JIDType = contact.JIDType
 		}
 
 		result[i] = info
@@ -523,10 +877,288 @@ func (h *GroupHandler) getMembersWithInfo(groupID int64) ([]GroupMemberInfo, err
 
 // extractPhone extracts the phone number from a JID.
 // JID format: "1234567890@s.whatsapp.net"
+// Hidden user IDs (LIDs) are not phone numbers and give "".
 func extractPhone(jid string) string {
+	if whatsapp.IsLID(jid) {
+		return ""
+	}
 	parts := strings.Split(jid, "@")
 	if len(parts) > 0 {
 		return parts[0]
 	}
 	return jid
 }
+
+// Placeholder defaults
+
+// handleAttributes handles GET/PUT /api/groups/{id}/attributes and
+// DELETE /api/groups/{id}/attributes/{key}.
+func (h *GroupHandler) handleAttributes(w http.ResponseWriter, r *http.Request, groupID int64, key string) {
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), groupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		h.getAttributes(w, r, groupID)
+	case r.Method == http.MethodPut && key == "":
+		h.setAttribute(w, r, groupID)
+	case r.Method == http.MethodDelete && key != "":
+		h.deleteAttribute(w, r, groupID, key)
+	case r.Method == http.MethodDelete:
+		jsonError(w, tr(r, "attribute_key_required_deletion"), http.StatusBadRequest)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *GroupHandler) getAttributes(w http.ResponseWriter, r *http.Request, groupID int64) {
+	attrs, err := h.groupAttrRepo.GetByGroup(groupID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve group attributes: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, GroupAttributeResponse{
+		Success:    true,
+		Message:    tr(r, "placeholder_defaults_found", len(attrs)),
+		Attributes: attrs,
+	})
+}
+
+func (h *GroupHandler) setAttribute(w http.ResponseWriter, r *http.Request, groupID int64) {
+	var req SetGroupAttributeRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	key := strings.TrimSpace(req.Key)
+	value := strings.TrimSpace(req.Value)
+	if key == "" {
+		jsonError(w, tr(r, "attribute_key_required"), http.StatusBadRequest)
+		return
+	}
+	if !validAttributeKey(key) {
+		jsonError(w, tr(r, "attribute_key_invalid"), http.StatusBadRequest)
+		return
+	}
+	if value == "" {
+		jsonError(w, tr(r, "attribute_value_required"), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.groupAttrRepo.Set(groupID, key, value); err != nil {
+		jsonError(w, fmt.Sprintf("Failed to set group attribute: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	attr, _ := h.groupAttrRepo.Get(groupID, key)
+
+	writeJSON(w, http.StatusOK, GroupAttributeResponse{
+		Success:   true,
+		Message:   tr(r, "placeholder_default_saved"),
+		Attribute: attr,
+	})
+}
+
+func (h *GroupHandler) deleteAttribute(w http.ResponseWriter, r *http.Request, groupID int64, key string) {
+	found, err := h.groupAttrRepo.Delete(groupID, key)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to delete group attribute: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		jsonError(w, tr(r, "attribute_not_found"), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, GroupAttributeResponse{
+		Success: true,
+		Message: tr(r, "placeholder_default_deleted"),
+	})
+}
+
+// Group set operations for POST /api/groups/combine.
+const (
+	CombineUnion        = "union"
+	CombineIntersection = "intersection"
+	CombineDifference   = "difference"
+)
+
+// CombineGroupsRequest builds a new group from the members of existing ones.
+// For difference the order matters: the first group minus all the others.
+type CombineGroupsRequest struct {
+	Name      string  `json:"name"`
+	Operation string  `json:"operation"` // union, intersection or difference
+	GroupIDs  []int64 `json:"group_ids"`
+}
+
+// CombineSource is what one source group added to or took from the result.
+type CombineSource struct {
+	GroupID     int64  `json:"group_id"`
+	Name        string `json:"name"`
+	MemberCount int    `json:"member_count"`
+	Contributed int    `json:"contributed"`       // Members of the new group that are members of this one
+	Removed     int    `json:"removed,omitempty"` // Difference only: members of the first group left out for being in this one
+}
+
+type CombineGroupsResponse struct {
+	Success bool                 `json:"success"`
+	Message string               `json:"message"`
+	Group   *models.ContactGroup `json:"group,omitempty"`
+	Sources []CombineSource      `json:"sources,omitempty"`
+	Empty   bool                 `json:"empty,omitempty"` // The group was created without members
+}
+
+// HandleCombine handles POST /api/groups/combine, creating a static group from
+// the union, intersection or difference of other groups' members. Later
+// changes to the source groups do not affect it.
+func (h *GroupHandler) HandleCombine(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CombineGroupsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		jsonError(w, tr(r, "group_name_required"), http.StatusBadRequest)
+		return
+	}
+	switch req.Operation {
+	case CombineUnion, CombineIntersection, CombineDifference:
+	default:
+		jsonError(w, tr(r, "combine_invalid_operation"), http.StatusBadRequest)
+		return
+	}
+	if len(req.GroupIDs) < 2 {
+		jsonError(w, tr(r, "combine_two_groups_required"), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.groupRepo.GetByName(workspaceID(r), name)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group name: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		jsonError(w, tr(r, "group_name_taken"), http.StatusConflict)
+		return
+	}
+
+	sources := make([]CombineSource, len(req.GroupIDs))
+	members := make([]map[string]bool, len(req.GroupIDs))
+	var order []string // Result candidates in the order they first appear
+	seen := make(map[string]bool)
+	for i, id := range req.GroupIDs {
+		for _, earlier := range req.GroupIDs[:i] {
+			if earlier == id {
+				jsonError(w, tr(r, "combine_duplicate_group", id), http.StatusBadRequest)
+				return
+			}
+		}
+
+		group, err := h.groupRepo.GetInWorkspace(workspaceID(r), id)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to get group: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if group == nil {
+			jsonError(w, tr(r, "combine_group_not_found", id), http.StatusNotFound)
+			return
+		}
+
+		jids, err := h.memberRepo.GetJIDsByGroup(id)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to get group members: %v", err), http.StatusInternalServerError)
+			return
+		}
+		sources[i] = CombineSource{GroupID: id, Name: group.Name, MemberCount: len(jids)}
+		members[i] = make(map[string]bool, len(jids))
+		for _, jid := range jids {
+			members[i][jid] = true
+			if !seen[jid] {
+				seen[jid] = true
+				order = append(order, jid)
+			}
+		}
+	}
+
+	jids := []string{}
+	for _, jid := range order {
+		if combineIncludes(req.Operation, members, jid) {
+			jids = append(jids, jid)
+		}
+	}
+
+	for i := range sources {
+		for _, jid := range jids {
+			if members[i][jid] {
+				sources[i].Contributed++
+			}
+		}
+	}
+	if req.Operation == CombineDifference {
+		for jid := range members[0] {
+			for i := 1; i < len(members); i++ {
+				if members[i][jid] {
+					sources[i].Removed++
+				}
+			}
+		}
+	}
+
+	group := &models.ContactGroup{WorkspaceID: workspaceID(r), Name: name}
+	if err := h.groupRepo.CreateWithMembers(group, jids); err != nil {
+		jsonError(w, fmt.Sprintf("Failed to create group: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	message := tr(r, "group_combined", len(jids))
+	if len(jids) == 0 {
+		message = tr(r, "group_combined_empty")
+	}
+
+	writeJSON(w, http.StatusCreated, CombineGroupsResponse{
+		Success: true,
+		Message: message,
+		Group:   group,
+		Sources: sources,
+		Empty:   len(jids) == 0,
+	})
+}
+
+// combineIncludes reports whether jid belongs in the result of op over the
+// member sets, given that it is a member of at least one of them.
+func combineIncludes(op string, members []map[string]bool, jid string) bool {
+	switch op {
+	case CombineIntersection:
+		for _, set := range members {
+			if !set[jid] {
+				return false
+			}
+		}
+		return true
+	case CombineDifference:
+		if !members[0][jid] {
+			return false
+		}
+		for _, set := range members[1:] {
+			if set[jid] {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+
+	"friday/internal/database"
+	"friday/internal/models"
+	"friday/internal/whatsapp"
+	"friday/internal/whatsapp/whatsapptest"
+)
+
+// newMembersHandler returns a group handler and a group whose members' numbers
+// are spaced five apart over the fake's contacts, so the members past the last
+// contact are unknown to the account.
+func newMembersHandler(tb testing.TB, members, contacts int) (*GroupHandler, int64) {
+	tb.Helper()
+	db := newTestDB(tb)
+	fake := whatsapptest.New("15550000000@s.whatsapp.net")
+	fake.SetConnected(true)
+	for i := 0; i < contacts; i++ {
+		fake.AddContact(fmt.Sprintf("90555%07d", i), fmt.Sprintf("Contact %d", i))
+	}
+
+	h := newGroupHandler(db, fake)
+	group := &models.ContactGroup{Name: "Large"}
+	jids := make([]string, members)
+	for i := range jids {
+		jids[i] = fmt.Sprintf("90555%07d@s.whatsapp.net", i*5)
+	}
+	if err := h.groupRepo.CreateWithMembers(group, jids); err != nil {
+		tb.Fatalf("failed to create group: %v", err)
+	}
+	return h, group.ID
+}
+
+func newGroupHandler(db *database.DB, fake *whatsapptest.Fake) *GroupHandler {
+	return NewGroupHandler(models.NewGroupRepository(db), models.NewGroupMemberRepository(db), models.NewGroupAttributeRepository(db),
+		models.NewDraftRepository(db), models.NewBatchRunRepository(db), models.NewQuarantineRepository(db),
+		models.NewContactMergeRepository(db), models.NewContactSyncRepository(db), fake)
+}
+
+func TestMembersWithInfo(t *testing.T) {
+	h, groupID := newMembersHandler(t, 10, 20)
+	members, err := h.getMembersWithInfo(groupID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 10 {
+		t.Fatalf("got %d members, want 10", len(members))
+	}
+	for _, m := range members {
+		var n int
+		fmt.Sscanf(m.Phone, "90555%07d", &n)
+		want := fmt.Sprintf("Contact %d", n)
+		if n >= 20 {
+			want = m.Phone
+		}
+		if m.Name != want {
+			t.Errorf("%s: name %q, want %q", m.JID, m.Name, want)
+		}
+	}
+}
+
+// BenchmarkMembersWithInfo lists a 500-member group on a 2000-contact account,
+// the size the member API has to answer well under a second for.
+func BenchmarkMembersWithInfo(b *testing.B) {
+	h, groupID := newMembersHandler(b, 500, 2000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := h.getMembersWithInfo(groupID); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMembersWithInfoHiddenNumbers(t *testing.T) {
+	db := newTestDB(t)
+	fake := whatsapptest.New("15550000000@s.whatsapp.net")
+	fake.SetConnected(true)
+	known := fake.AddLIDContact("111111111111111", "Ada")
+	h := newGroupHandler(db, fake)
+
+	group := &models.ContactGroup{Name: "Hidden"}
+	if err := h.groupRepo.CreateWithMembers(group, []string{known, "222222222222222@lid"}); err != nil {
+		t.Fatal(err)
+	}
+	members, err := h.getMembersWithInfo(group.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]GroupMemberInfo{
+		known:                 {Name: "Ada", JIDType: whatsapp.JIDTypeLID},
+		"222222222222222@lid": {Name: "222222222222222@lid", JIDType: whatsapp.JIDTypeLID},
+	}
+	for _, m := range members {
+		w := want[m.JID]
+		if m.Name != w.Name || m.Phone != "" || m.JIDType != w.JIDType {
+			t.Errorf("%s: name %q, phone %q, type %q; want %q, no phone, %q", m.JID, m.Name, m.Phone, m.JIDType, w.Name, w.JIDType)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"friday/internal/database"
+)
+
+// newTestDB opens a fresh database in a temporary directory.
+func newTestDB(t testing.TB) *database.DB {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "friday.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// decodeResponse decodes a recorded JSON response into out.
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder, out any) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+}
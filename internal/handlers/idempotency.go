@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"friday/internal/logging"
+	"friday/internal/models"
+)
+
+const (
+	// IdempotencyHeader lets clients safely retry POSTs that create resources.
+	IdempotencyHeader = "Idempotency-Key"
+	// idempotencyTTL is how long a key and its response are kept.
+	idempotencyTTL = 24 * time.Hour
+	// maxIdempotencyKeyLength bounds the stored key.
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyHandler replays stored responses for POST requests that carry an
+// Idempotency-Key header.
+type IdempotencyHandler struct {
+	repo *models.IdempotencyRepository
+}
+
+// NewIdempotencyHandler creates a new idempotency middleware.
+func NewIdempotencyHandler(repo *models.IdempotencyRepository) *IdempotencyHandler {
+	return &IdempotencyHandler{repo: repo}
+}
+
+// idempotentBodyLimit is the most Wrap reads of a request body: the bundle
+// import limit for /api/drafts/import, maxJSONBodyBytes for everything else.
+func idempotentBodyLimit(r *http.Request) int64 {
+	if r.URL.Path == "/api/drafts/import" {
+		return maxImportBodyBytes
+	}
+	return maxJSONBodyBytes
+}
+
+// responseRecorder captures a response so it can be stored and then written out.
+type responseRecorder struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rec *responseRecorder) Header() http.Header { return rec.header }
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.statusCode == 0 {
+		rec.statusCode = http.StatusOK
+	}
+	return rec.body.Write(b)
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if rec.statusCode == 0 {
+		rec.statusCode = statusCode
+	}
+}
+
+// Wrap applies idempotency handling to POST requests of the given handler.
+// Requests without the header, and other methods, pass straight through.
+//
+// The first request with a key reserves it before the handler runs; a concurrent
+// duplicate gets 409 until the first finishes, then the stored response. Reusing
+// a key with a different method, path or body is rejected with 422. Server errors
+// (5xx) are not stored so the client can retry with the same key.
+func (h *IdempotencyHandler) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get(IdempotencyHeader))
+		if r.Method != http.MethodPost || key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			jsonError(w, tr(r, "idempotency_too_long"), http.StatusBadRequest)
+			return
+		}
+
+		// Read within the limit the handler decodes the body with, as decodeBody does
+		limit := idempotentBodyLimit(r)
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				jsonError(w, describeDecodeError(r, err, nil, limit), http.StatusRequestEntityTooLarge)
+				return
+			}
+			jsonError(w, tr(r, "failed_to_read_body"), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256([]byte(r.Method + " " + r.URL.RequestURI() + "\n" + string(body)))
+		hash := hex.EncodeToString(sum[:])
+
+		existing, reserved, err := h.repo.Reserve(key, hash, idempotencyTTL)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("Idempotency check failed", "key", key, "error", err)
+			jsonError(w, tr(r, "idempotency_check_failed"), http.StatusInternalServerError)
+			return
+		}
+
+		if !reserved {
+			if existing.RequestHash != hash {
+				jsonError(w, tr(r, "idempotency_reused"), http.StatusUnprocessableEntity)
+				return
+			}
+			if !existing.Completed {
+				jsonError(w, tr(r, "idempotency_in_progress"), http.StatusConflict)
+				return
+			}
+			if existing.ContentType != "" {
+				w.Header().Set("Content-Type", existing.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(existing.StatusCode)
+			w.Write(existing.ResponseBody)
+			return
+		}
+
+		rec := &responseRecorder{header: make(http.Header)}
+		next(rec, r)
+		if rec.statusCode == 0 {
+			rec.statusCode = http.StatusOK
+		}
+
+		if rec.statusCode >= 500 {
+			if err := h.repo.Release(key); err != nil {
+				logging.FromContext(r.Context()).Error("Failed to release idempotency key", "key", key, "error", err)
+			}
+		} else if err := h.repo.Complete(key, rec.statusCode, rec.header.Get("Content-Type"), rec.body.Bytes()); err != nil {
+			logging.FromContext(r.Context()).Error("Failed to store response for idempotency key", "key", key, "error", err)
+		}
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(rec.body.Len()))
+		w.WriteHeader(rec.statusCode)
+		w.Write(rec.body.Bytes())
+	}
+}
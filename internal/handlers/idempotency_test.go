@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"friday/internal/models"
+)
+
+func TestIdempotencyLimitsBody(t *testing.T) {
+	idempotent := NewIdempotencyHandler(models.NewIdempotencyRepository(newTestDB(t))).Wrap
+	calls := 0
+	handler := idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var body map[string]string
+		if !decodeBody(w, r, &body, idempotentBodyLimit(r), false) {
+			return
+		}
+		writeJSON(w, http.StatusCreated, body)
+	})
+
+	post := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(IdempotencyHeader, key)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+	// A JSON object of about size bytes
+	bodyOf := func(size int) string {
+		return `{"content":"` + strings.Repeat("x", size) + `"}`
+	}
+
+	rec := post("/api/drafts", "big", bodyOf(maxJSONBodyBytes))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized body: status %d, want 413: %s", rec.Code, rec.Body)
+	}
+	var resp struct{ Message string }
+	decodeResponse(t, rec, &resp)
+	if resp.Message != "Request body is too large (limit 1 MB)" {
+		t.Errorf("message = %q", resp.Message)
+	}
+	if calls != 0 {
+		t.Errorf("handler ran %d times for an oversized body", calls)
+	}
+
+	// The key was not reserved, so a retry within the limit goes through
+	if rec := post("/api/drafts", "big", bodyOf(10)); rec.Code != http.StatusCreated {
+		t.Errorf("retry with the same key: status %d, want 201: %s", rec.Code, rec.Body)
+	}
+
+	// Bundle imports get the import limit
+	if rec := post("/api/drafts/import", "import", bodyOf(2*maxJSONBodyBytes)); rec.Code != http.StatusCreated {
+		t.Errorf("2 MB import: status %d, want 201", rec.Code)
+	}
+	if rec := post("/api/drafts/import", "huge", bodyOf(maxImportBodyBytes)); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized import: status %d, want 413", rec.Code)
+	}
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"friday/internal/logging"
+	"friday/internal/models"
+)
+
+// Trigger requests are signed with HMAC-SHA256 over "<timestamp>.<body>", keyed
+// with the integrations.trigger_secret setting. The timestamp is Unix seconds.
+const (
+	TriggerTimestampHeader = "X-Friday-Timestamp"
+	TriggerSignatureHeader = "X-Friday-Signature" // "sha256=<hex>"
+
+	// maxTriggerSkew is how far a request's timestamp may be from the server clock.
+	maxTriggerSkew = 5 * time.Minute
+)
+
+var errBadSignature = errors.New("signature does not match")
+
+// IntegrationHandler handles requests from external systems such as a CRM.
+type IntegrationHandler struct {
+	settingsRepo *models.SettingsRepository
+	draftRepo    *models.DraftRepository
+	groupRepo    *models.GroupRepository
+	batches      *BatchHandler
+
+	// Signatures accepted within the skew window, so a captured request cannot be replayed
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewIntegrationHandler creates a new integration handler. Batches are created
+// through the batch handler so they follow the same rules as POST /api/batch-runs.
+func NewIntegrationHandler(settingsRepo *models.SettingsRepository, draftRepo *models.DraftRepository, groupRepo *models.GroupRepository, batches *BatchHandler) *IntegrationHandler {
+	return &IntegrationHandler{
+		settingsRepo: settingsRepo,
+		draftRepo:    draftRepo,
+		groupRepo:    groupRepo,
+		batches:      batches,
+		seen:         make(map[string]time.Time),
+	}
+}
+
+// RegisterCapabilities adds batch_trigger, on once an integration secret is saved.
+func (h *IntegrationHandler) RegisterCapabilities(c *Capabilities) {
+	c.Register(CapabilityBatchTrigger, settingSet(h.settingsRepo, models.SettingTriggerSecret))
+}
+
+// TriggerBatchRequest names the draft and group by ID or, case-insensitively, by title and name.
+type TriggerBatchRequest struct {
+	DraftID    int64  `json:"draft_id"`
+	DraftTitle string `json:"draft_title"`
+	GroupID    int64  `json:"group_id"`
+	GroupName  string `json:"group_name"`
+	Validate   bool   `json:"validate"`
+	DryRun     bool   `json:"dry_run"`
+	ClientName string `json:"client_name,omitempty"` // Also taken from X-Client-Name
+	Label      string `json:"label,omitempty"`       // See CreateBatchRequest.Label
+	Sort       string `json:"sort,omitempty"`        // See CreateBatchRequest.Sort
+}
+
+// HandleTriggerBatch handles POST /api/integrations/trigger-batch. The signature
+// is checked before anything is looked up, and every signature problem gets the
+// same 401, so unsigned callers learn nothing about drafts or groups.
+func (h *IntegrationHandler) HandleTriggerBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	log := logging.FromContext(r.Context())
+
+	secret, err := h.settingsRepo.GetString(models.SettingTriggerSecret, "")
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to read settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if secret == "" {
+		capabilityDisabled(w, tr(r, "batch_trigger_disabled", models.SettingTriggerSecret), CapabilityBatchTrigger)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxJSONBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			jsonError(w, fmt.Sprintf("Request body too large (limit %d bytes)", maxJSONBodyBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		jsonError(w, tr(r, "failed_to_read_body"), http.StatusBadRequest)
+		return
+	}
+
+	signature := r.Header.Get(TriggerSignatureHeader)
+	if err := verifyTriggerSignature(secret, r.Header.Get(TriggerTimestampHeader), signature, body, time.Now()); err != nil {
+		log.Warn("Rejected batch trigger", "reason", err)
+		jsonError(w, tr(r, "trigger_bad_signature"), http.StatusUnauthorized)
+		return
+	}
+	// Hex is case-insensitive, so key the replay check on the decoded digest
+	if !h.claimSignature(strings.ToLower(strings.TrimPrefix(signature, "sha256="))) {
+		log.Warn("Rejected batch trigger", "reason", "signature already used")
+		jsonError(w, tr(r, "trigger_bad_signature"), http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	var req TriggerBatchRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	draft, ok := h.resolveDraft(w, r, req)
+	if !ok {
+		return
+	}
+	group, ok := h.resolveGroup(w, r, req)
+	if !ok {
+		return
+	}
+
+	log.Info("Batch triggered by integration", "draft_id", draft.ID, "group_id", group.ID)
+	h.batches.queueBatch(w, r, draft, group, CreateBatchRequest{
+		DraftID:    draft.ID,
+		GroupID:    group.ID,
+		Validate:   req.Validate,
+		DryRun:     req.DryRun,
+		ClientName: req.ClientName,
+		Label:      req.Label,
+		Sort:       req.Sort,
+		Source:     models.BatchSourceIntegration,
+	})
+}
+
+// resolveDraft finds the draft named by ID or title. On failure it writes the
+// error response and returns false.
+func (h *IntegrationHandler) resolveDraft(w http.ResponseWriter, r *http.Request, req TriggerBatchRequest) (*models.MessageDraft, bool) {
+	title := strings.TrimSpace(req.DraftTitle)
+	if (req.DraftID == 0) == (title == "") {
+		jsonError(w, tr(r, "trigger_draft_ambiguous"), http.StatusBadRequest)
+		return nil, false
+	}
+
+	var draft *models.MessageDraft
+	var err error
+	if req.DraftID != 0 {
+		draft, err = h.draftRepo.GetInWorkspace(workspaceID(r), req.DraftID)
+	} else {
+		draft, err = h.draftRepo.GetByTitle(workspaceID(r), title)
+	}
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check draft: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	if draft == nil {
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+		return nil, false
+	}
+	return draft, true
+}
+
+// resolveGroup finds the group named by ID or name. On failure it writes the
+// error response and returns false.
+func (h *IntegrationHandler) resolveGroup(w http.ResponseWriter, r *http.Request, req TriggerBatchRequest) (*models.ContactGroup, bool) {
+	name := strings.TrimSpace(req.GroupName)
+	if (req.GroupID == 0) == (name == "") {
+		jsonError(w, tr(r, "trigger_group_ambiguous"), http.StatusBadRequest)
+		return nil, false
+	}
+
+	id := req.GroupID
+	if name != "" {
+		byName, err := h.groupRepo.GetByName(workspaceID(r), name)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+			return nil, false
+		}
+		if byName == nil {
+			jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+			return nil, false
+		}
+		id = byName.ID
+	}
+
+	// GetInWorkspace also fills in the member count queueBatch checks
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	if group == nil {
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+		return nil, false
+	}
+	return group, true
+}
+
+// claimSignature records a verified signature and reports whether it was new.
+// Entries are dropped once their timestamp could no longer pass the skew check.
+func (h *IntegrationHandler) claimSignature(signature string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for sig, at := range h.seen {
+		if now.Sub(at) > 2*maxTriggerSkew {
+			delete(h.seen, sig)
+		}
+	}
+	if _, ok := h.seen[signature]; ok {
+		return false
+	}
+	h.seen[signature] = now
+	return true
+}
+
+// verifyTriggerSignature checks the timestamp is within maxTriggerSkew of now and
+// the signature matches the body.
+func verifyTriggerSignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or malformed %s header", TriggerTimestampHeader)
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > maxTriggerSkew || skew < -maxTriggerSkew {
+		return fmt.Errorf("timestamp is %v away from server time", skew.Round(time.Second))
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(got) == 0 {
+		return fmt.Errorf("missing or malformed %s header", TriggerSignatureHeader)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return errBadSignature
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"friday/internal/logging"
+	"friday/internal/models"
+	"friday/internal/whatsapp"
+)
+
+// pathJID URL-decodes and normalizes a JID taken from the request path. On
+// failure it writes a 400 naming the problem and returns false.
+func pathJID(w http.ResponseWriter, r *http.Request, escaped string) (string, bool) {
+	raw, err := url.PathUnescape(escaped)
+	if err != nil {
+		jsonError(w, tr(r, "invalid_jid_encoding"), http.StatusBadRequest)
+		return "", false
+	}
+	return requireJID(w, raw)
+}
+
+// requireJID normalizes a JID from a request, writing a 400 with the reason when
+// it is invalid.
+func requireJID(w http.ResponseWriter, raw string) (string, bool) {
+	jid, err := whatsapp.NormalizeJID(raw)
+	if err != nil {
+		jsonError(w, describeJIDError(err), http.StatusBadRequest)
+		return "", false
+	}
+	return jid, true
+}
+
+// redirectJID returns the contact jid was merged into, or jid itself. On failure
+// it writes a 500 and returns false.
+func redirectJID(w http.ResponseWriter, repo *models.ContactMergeRepository, jid string) (string, bool) {
+	target, err := repo.Redirect(jid)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check merged contacts: %v", err), http.StatusInternalServerError)
+		return "", false
+	}
+	if target != "" {
+		return target, true
+	}
+	return jid, true
+}
+
+// isSelf reports whether jid is the linked account. A message to it lands in the
+// account's own chat, which some use for notes but is usually a mistake.
+func isSelf(sender whatsapp.Sender, jid string) bool {
+	own := sender.OwnJID()
+	return own != "" && jid == own
+}
+
+// blockedMembers returns the JIDs the linked account has blocked, or nil while
+// disconnected or when the blocklist cannot be fetched; batches then include
+// those members and the worker fails their messages if still blocked at send time.
+func blockedMembers(r *http.Request, store whatsapp.ContactStore) map[string]bool {
+	blocked, err := store.BlockedJIDs(r.Context())
+	if err != nil {
+		if !errors.Is(err, whatsapp.ErrNotConnected) {
+			logging.FromContext(r.Context()).Warn("Failed to get blocklist, blocked members are not left out", "error", err)
+		}
+		return nil
+	}
+	return blocked
+}
+
+// normalizeJIDList normalizes every JID, dropping duplicates that normalize to
+// the same value. If any is invalid, the error lists each one with its reason.
+func normalizeJIDList(raw []string) ([]string, error) {
+	jids := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	var problems []string
+	for _, r := range raw {
+		jid, err := whatsapp.NormalizeJID(r)
+		if err != nil {
+			problems = append(problems, describeJIDError(err))
+			continue
+		}
+		if !seen[jid] {
+			seen[jid] = true
+			jids = append(jids, jid)
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return jids, nil
+}
+
+// resolveLIDs replaces hidden user IDs (LIDs) with the phone-number JIDs the
+// client knows for them, dropping JIDs that then repeat. It also returns the
+// LIDs left unresolved, which batches cannot validate or send to.
+func resolveLIDs(client whatsapp.ContactStore, jids []string) (resolved, unresolved []string, err error) {
+	resolved = make([]string, 0, len(jids))
+	seen := make(map[string]bool, len(jids))
+	for _, jid := range jids {
+		if whatsapp.IsLID(jid) {
+			pn, err := client.ResolveLID(jid)
+			if err != nil {
+				return nil, nil, err
+			}
+			if pn == "" {
+				unresolved = append(unresolved, jid)
+			} else {
+				jid = pn
+			}
+		}
+		if !seen[jid] {
+			seen[jid] = true
+			resolved = append(resolved, jid)
+		}
+	}
+	return resolved, unresolved, nil
+}
+
+func describeJIDError(err error) string {
+	var invalid *whatsapp.InvalidJIDError
+	if errors.As(err, &invalid) {
+		return fmt.Sprintf("Invalid JID %q: %s", invalid.JID, invalid.Reason)
+	}
+	return err.Error()
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"friday/internal/i18n"
+)
+
+// WithLanguage picks the language of response messages for every request (see
+// i18n.Negotiate), stores it in the request context for tr and reports it in
+// the Content-Language header.
+func WithLanguage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := i18n.Negotiate(r)
+		w.Header().Set("Content-Language", lang)
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.WithLanguage(r.Context(), lang)))
+	})
+}
+
+// tr returns the message with the given ID in the request's language.
+func tr(r *http.Request, id string, args ...interface{}) string {
+	return i18n.T(i18n.FromContext(r.Context()), id, args...)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"friday/internal/models"
+)
+
+// NoteHandler handles the free-form note kept per contact.
+type NoteHandler struct {
+	repo *models.ContactNoteRepository
+}
+
+// NewNoteHandler creates a new contact note handler.
+func NewNoteHandler(repo *models.ContactNoteRepository) *NoteHandler {
+	return &NoteHandler{repo: repo}
+}
+
+type SetNoteRequest struct {
+	Content string `json:"content"` // Plain text or markdown; empty clears the note
+}
+
+type NoteResponse struct {
+	Success bool                `json:"success"`
+	Message string              `json:"message"`
+	Note    *models.ContactNote `json:"note"`
+}
+
+// HandleContactNote handles GET and PUT /api/contacts/{jid}/notes.
+func (h *NoteHandler) HandleContactNote(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/contacts/")
+	jid, ok := pathJID(w, r, strings.TrimSuffix(path, "/notes"))
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getNote(w, jid)
+	case http.MethodPut:
+		h.setNote(w, r, jid)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *NoteHandler) getNote(w http.ResponseWriter, jid string) {
+	note, err := h.repo.Get(jid)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get note: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	message := "Note retrieved successfully"
+	if note == nil {
+		message = "No note for this contact"
+	}
+
+	writeJSON(w, http.StatusOK, NoteResponse{
+		Success: true,
+		Message: message,
+		Note:    note,
+	})
+}
+
+func (h *NoteHandler) setNote(w http.ResponseWriter, r *http.Request, jid string) {
+	var req SetNoteRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if len(req.Content) > models.MaxContactNoteLength {
+		jsonError(w, fmt.Sprintf("Note is too long: %d bytes, the limit is %d", len(req.Content), models.MaxContactNoteLength), http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Content) == "" {
+		if _, err := h.repo.Delete(jid); err != nil {
+			jsonError(w, fmt.Sprintf("Failed to clear note: %v", err), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, NoteResponse{
+			Success: true,
+			Message: tr(r, "note_cleared"),
+		})
+		return
+	}
+
+	note, err := h.repo.Set(jid, req.Content)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to save note: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NoteResponse{
+		Success: true,
+		Message: tr(r, "note_saved"),
+		Note:    note,
+	})
+}
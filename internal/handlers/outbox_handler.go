@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"friday/internal/models"
+)
+
+// OutboxHandler lists and removes single sends queued while WhatsApp was
+// disconnected. The batch worker sends them once the connection is back.
+type OutboxHandler struct {
+	repo *models.OutboxRepository
+}
+
+// NewOutboxHandler creates a new outbox handler.
+func NewOutboxHandler(repo *models.OutboxRepository) *OutboxHandler {
+	return &OutboxHandler{repo: repo}
+}
+
+type OutboxListResponse struct {
+	Success bool                `json:"success"`
+	Message string              `json:"message"`
+	Items   []models.OutboxItem `json:"items"`
+}
+
+type OutboxItemResponse struct {
+	Success bool               `json:"success"`
+	Message string             `json:"message"`
+	Item    *models.OutboxItem `json:"item,omitempty"`
+}
+
+// HandleOutbox handles GET /api/outbox, optionally filtered with ?status=.
+func (h *OutboxHandler) HandleOutbox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := models.OutboxStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", models.OutboxStatusQueued, models.OutboxStatusSent, models.OutboxStatusFailed, models.OutboxStatusExpired:
+	default:
+		jsonError(w, tr(r, "outbox_invalid_status"), http.StatusBadRequest)
+		return
+	}
+
+	// The worker expires items on its own schedule; do it now so the list is current
+	if _, err := h.repo.Expire(); err != nil {
+		jsonError(w, fmt.Sprintf("Failed to expire outbox: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	items, err := h.repo.GetAll(status)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get outbox: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, OutboxListResponse{
+		Success: true,
+		Message: tr(r, "outbox_retrieved"),
+		Items:   items,
+	})
+}
+
+// HandleOutboxItem handles GET and DELETE /api/outbox/{id}. Deleting a queued
+// item stops it from being sent.
+func (h *OutboxHandler) HandleOutboxItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/outbox/"), 10, 64)
+	if err != nil {
+		jsonError(w, tr(r, "outbox_invalid_id"), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		item, err := h.repo.GetByID(id)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to get outbox message: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if item == nil {
+			jsonError(w, tr(r, "outbox_not_found"), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, OutboxItemResponse{
+			Success: true,
+			Message: tr(r, "outbox_item_retrieved"),
+			Item:    item,
+		})
+	case http.MethodDelete:
+		deleted, err := h.repo.Delete(id)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to delete outbox message: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			jsonError(w, tr(r, "outbox_not_found"), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, OutboxItemResponse{
+			Success: true,
+			Message: tr(r, "outbox_deleted"),
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// queueOutbox stores a send for later, expiring after the outbox.ttl_minutes
+// setting. On failure it writes the error response and returns false.
+func queueOutbox(w http.ResponseWriter, outboxRepo *models.OutboxRepository, settingsRepo *models.SettingsRepository, jid, body string) (*models.OutboxItem, bool) {
+	ttl, err := settingsRepo.GetInt(models.SettingOutboxTTLMinutes, models.DefaultOutboxTTLMinutes)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to read settings: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	item, err := outboxRepo.Create(jid, body, time.Duration(ttl)*time.Minute)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to queue message: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	return item, true
+}
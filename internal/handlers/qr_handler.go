@@ -1,76 +1,305 @@
 package handlers
 
 import (
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/skip2/go-qrcode"
+
+	"friday/internal/events"
+	"friday/internal/i18n"
+	"friday/internal/whatsapp"
 )
 
+// QRHandler holds the QR code of the current pairing attempt. Every browser tab
+// reads the same attempt, so refreshing one tab does not invalidate another.
 type QRHandler struct {
-	currentQR string
+	hub *events.Hub
+
+	mu          sync.RWMutex
+	currentQR   string
+	attemptID   int64 // Increments each time WhatsApp starts a new pairing attempt
+	generation  int   // Codes shown so far in the current attempt
+	generatedAt time.Time
+	expiresAt   time.Time
+
+	terminalMu    sync.Mutex
+	terminal      io.Writer // Where SetTerminal draws codes; nil when off
+	terminalTTY   bool      // Whether a drawn code can be erased with ANSI escapes
+	terminalLines int       // Lines of the code drawn last, 0 when none is shown
 }
 
-func NewQRHandler() *QRHandler {
-	return &QRHandler{}
+// Render sizes accepted by /api/whatsapp/qr.png, in pixels.
+const (
+	defaultQRImageSize = 512
+	minQRImageSize     = 128
+	maxQRImageSize     = 1024
+)
+
+func NewQRHandler(hub *events.Hub) *QRHandler {
+	return &QRHandler{hub: hub}
+}
+
+// SetTerminal draws every new pairing code on w as text, for servers without a
+// browser at hand (FRIDAY_TERMINAL_QR). On a terminal each code replaces the
+// previous one and the last is erased once pairing ends; elsewhere, such as
+// container logs, a line notes that the code is gone.
+func (h *QRHandler) SetTerminal(w io.Writer) {
+	tty := false
+	if f, ok := w.(*os.File); ok {
+		if info, err := f.Stat(); err == nil {
+			tty = info.Mode()&os.ModeCharDevice != 0
+		}
+	}
+
+	h.terminalMu.Lock()
+	defer h.terminalMu.Unlock()
+	h.terminal = w
+	h.terminalTTY = tty
 }
 
 type QRResponse struct {
-	QRCode    string `json:"qr_code"`
-	Available bool   `json:"available"`
-	Message   string `json:"message"`
+	QRCode        string     `json:"qr_code,omitempty"` // The pairing string the image encodes
+	CodeAvailable bool       `json:"code_available"`
+	Message       string     `json:"message"`
+	AttemptID     int64      `json:"attempt_id"`
+	Generation    int        `json:"generation"`
+	GeneratedAt   *time.Time `json:"generated_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	ImageBase64   string     `json:"image_base64,omitempty"` // PNG of the code, with format=base64
 }
 
+// HandleGetQR handles GET /api/whatsapp/qr, returning the current code and its
+// attempt metadata. The image itself is served by /api/whatsapp/qr.png, or
+// inlined as a base64 PNG with format=base64 (and size, as for the image) for
+// clients that cannot fetch a second URL.
 func (h *QRHandler) HandleGetQR(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "base64" {
+		jsonError(w, tr(r, "qr_bad_json_format"), http.StatusBadRequest)
+		return
+	}
+	size, ok := qrImageSize(w, r)
+	if !ok {
+		return
+	}
+
+	response := h.Snapshot(i18n.FromContext(r.Context()))
+	if format == "base64" && response.CodeAvailable {
+		image, err := qrcode.Encode(response.QRCode, qrcode.Medium, size)
+		if err != nil {
+			jsonError(w, tr(r, "qr_image_failed"), http.StatusInternalServerError)
+			return
+		}
+		response.ImageBase64 = base64.StdEncoding.EncodeToString(image)
+	}
 
+	if format == "base64" {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// qrImageSize reads the size query parameter, writing the error response when
+// it is out of range.
+func qrImageSize(w http.ResponseWriter, r *http.Request) (int, bool) {
+	s := r.URL.Query().Get("size")
+	if s == "" {
+		return defaultQRImageSize, true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < minQRImageSize || n > maxQRImageSize {
+		jsonError(w, fmt.Sprintf("size must be a number of pixels from %d to %d", minQRImageSize, maxQRImageSize), http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
+
+// Snapshot returns the current QR state, with its message in lang.
+func (h *QRHandler) Snapshot(lang string) QRResponse {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.responseLocked(lang)
+}
+
+func (h *QRHandler) responseLocked(lang string) QRResponse {
 	if h.currentQR == "" {
-		json.NewEncoder(w).Encode(QRResponse{
-			Available: false,
-			Message:   "No QR code available. Try connecting to WhatsApp first.",
-		})
-		return
+		return QRResponse{
+			CodeAvailable: false,
+			Message:       i18n.T(lang, "qr_not_available"),
+			AttemptID:     h.attemptID,
+			Generation:    h.generation,
+		}
 	}
 
-	json.NewEncoder(w).Encode(QRResponse{
-		QRCode:    h.currentQR,
-		Available: true,
-		Message:   "QR code ready for scanning",
-	})
+	generatedAt, expiresAt := h.generatedAt, h.expiresAt
+	return QRResponse{
+		QRCode:        h.currentQR,
+		CodeAvailable: true,
+		Message:       i18n.T(lang, "qr_ready"),
+		AttemptID:     h.attemptID,
+		Generation:    h.generation,
+		GeneratedAt:   &generatedAt,
+		ExpiresAt:     &expiresAt,
+	}
 }
 
-func (h *QRHandler) SetQR(qrCode string) {
-	h.currentQR = qrCode
+func (h *QRHandler) SetQR(qr whatsapp.QRCode) {
+	h.mu.Lock()
+	if qr.Index == 0 {
+		h.attemptID++
+		h.generation = 0
+	}
+	h.generation++
+	h.currentQR = qr.Code
+	h.generatedAt = time.Now()
+	h.expiresAt = qr.ExpiresAt
+	// Events go to every subscriber, whatever their language
+	response := h.responseLocked(i18n.Default)
+	h.mu.Unlock()
+
+	h.hub.Publish(events.TopicQR, response)
+	h.drawTerminal(qr.Code)
 }
 
 func (h *QRHandler) ClearQR() {
+	h.mu.Lock()
 	h.currentQR = ""
+	response := h.responseLocked(i18n.Default)
+	h.mu.Unlock()
+
+	response.Message = i18n.T(i18n.Default, "qr_cleared")
+	h.hub.Publish(events.TopicQR, response)
+	h.drawTerminal("")
 }
 
+// drawTerminal replaces the code shown on the terminal with code, or erases it
+// when code is empty.
+func (h *QRHandler) drawTerminal(code string) {
+	h.terminalMu.Lock()
+	defer h.terminalMu.Unlock()
+	if h.terminal == nil {
+		return
+	}
+
+	if h.terminalLines > 0 {
+		if h.terminalTTY {
+			fmt.Fprintf(h.terminal, "\x1b[%dA\x1b[J", h.terminalLines)
+		} else if code == "" {
+			fmt.Fprintln(h.terminal, "WhatsApp QR code cleared")
+		}
+		h.terminalLines = 0
+	}
+	if code == "" {
+		return
+	}
+
+	qr, err := qrcode.New(code, qrcode.Low)
+	if err != nil {
+		slog.Warn("Failed to draw QR code on the terminal", "error", err)
+		return
+	}
+	text := "Scan with WhatsApp (Settings > Linked devices):\n" + qr.ToSmallString(false)
+	fmt.Fprint(h.terminal, text)
+	h.terminalLines = strings.Count(text, "\n")
+}
+
+// HandleQRImage handles GET /api/whatsapp/qr.png, rendering the current code.
+// Query: size in pixels (128-1024, default 512) and format=svg for a vector
+// image. The ETag changes with the code, so clients can poll with If-None-Match.
 func (h *QRHandler) HandleQRImage(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	if h.currentQR == "" {
-		http.Error(w, "No QR code available. Try connecting to WhatsApp first.", http.StatusNotFound)
+	size, ok := qrImageSize(w, r)
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "png"
+	}
+	if format != "png" && format != "svg" {
+		jsonError(w, tr(r, "qr_bad_format"), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.RLock()
+	code := h.currentQR
+	h.mu.RUnlock()
+
+	if code == "" {
+		jsonError(w, tr(r, "qr_not_available"), http.StatusNotFound)
+		return
+	}
+
+	sum := sha256.Sum256([]byte(code))
+	etag := fmt.Sprintf(`"%s-%s-%d"`, hex.EncodeToString(sum[:8]), format, size)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
-	qrBytes, err := qrcode.Encode(h.currentQR, qrcode.Medium, 512)
+	qr, err := qrcode.New(code, qrcode.Medium)
 	if err != nil {
-		http.Error(w, "Failed to generate QR code image", http.StatusInternalServerError)
+		jsonError(w, tr(r, "qr_image_failed"), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "image/png")
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(qrBytes)))
-	w.Write(qrBytes)
+	var image []byte
+	if format == "svg" {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		image = qrSVG(qr.Bitmap(), size)
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+		if image, err = qr.PNG(size); err != nil {
+			jsonError(w, tr(r, "qr_image_failed"), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(image)))
+	w.Write(image)
+}
+
+// qrSVG draws a QR bitmap, quiet zone included, as an SVG of size pixels with
+// one module per user unit and each row's dark runs as single rectangles.
+func qrSVG(bitmap [][]bool, size int) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, len(bitmap), len(bitmap))
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, len(bitmap), len(bitmap))
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="1"/>`, start, y, x-start)
+		}
+	}
+	b.WriteString("</svg>")
+	return []byte(b.String())
 }
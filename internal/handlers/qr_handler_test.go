@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"friday/internal/events"
+	"friday/internal/whatsapp"
+)
+
+func TestQRMessageLanguage(t *testing.T) {
+	h := NewQRHandler(events.NewHub())
+	handler := WithLanguage(http.HandlerFunc(h.HandleGetQR))
+
+	get := func(lang string) QRResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/whatsapp/qr", nil)
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		var resp QRResponse
+		decodeResponse(t, rec, &resp)
+		return resp
+	}
+
+	if got := get("tr").Message; got != "QR kod yok. Önce WhatsApp'a bağlanmayı deneyin." {
+		t.Errorf("Turkish message without a code = %q", got)
+	}
+	h.SetQR(whatsapp.QRCode{Code: "2@abc", ExpiresAt: time.Now().Add(time.Minute)})
+	if got := get("tr").Message; got != "QR kod taramaya hazır" {
+		t.Errorf("Turkish message with a code = %q", got)
+	}
+	if got := get("en").Message; got != "QR code ready for scanning" {
+		t.Errorf("English message with a code = %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"friday/internal/models"
+)
+
+// QuarantineHandler lists and clears contacts quarantined after repeated
+// permanent send failures.
+type QuarantineHandler struct {
+	repo *models.QuarantineRepository
+}
+
+// NewQuarantineHandler creates a new quarantine handler.
+func NewQuarantineHandler(repo *models.QuarantineRepository) *QuarantineHandler {
+	return &QuarantineHandler{repo: repo}
+}
+
+type QuarantineListResponse struct {
+	Success  bool                      `json:"success"`
+	Message  string                    `json:"message"`
+	Contacts []models.RecipientFailure `json:"contacts"`
+	Count    int                       `json:"count"`
+}
+
+type QuarantineClearResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	JID     string `json:"jid"`
+}
+
+// HandleQuarantined handles GET /api/contacts/quarantined.
+func (h *QuarantineHandler) HandleQuarantined(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	contacts, err := h.repo.GetQuarantined()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get quarantined contacts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, QuarantineListResponse{
+		Success:  true,
+		Message:  tr(r, "quarantined_found", len(contacts)),
+		Contacts: contacts,
+		Count:    len(contacts),
+	})
+}
+
+// HandleClear handles POST /api/contacts/{jid}/quarantine/clear. It resets the
+// failure counter, so the contact is included in new batches again.
+func (h *QuarantineHandler) HandleClear(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/contacts/")
+	jid, ok := pathJID(w, r, strings.TrimSuffix(path, "/quarantine/clear"))
+	if !ok {
+		return
+	}
+
+	cleared, err := h.repo.Clear(jid)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to clear quarantine: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	message := "Quarantine cleared"
+	if !cleared {
+		message = "Contact has no recorded failures"
+	}
+
+	writeJSON(w, http.StatusOK, QuarantineClearResponse{
+		Success: true,
+		Message: message,
+		JID:     jid,
+	})
+}
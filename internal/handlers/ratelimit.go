@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"friday/internal/logging"
+	"friday/internal/models"
+)
+
+// Defaults used when neither the settings nor the environment set a limit.
+const (
+	DefaultRateLimitRPS   = 5
+	DefaultRateLimitBurst = 20
+
+	// rateLimitSweepInterval is how often idle buckets are dropped.
+	rateLimitSweepInterval = time.Minute
+)
+
+// rateLimitedReads are the GET endpoints expensive enough to limit: each scans
+// the whole WhatsApp contact store or the batch history. Other reads, including
+// the status endpoints and event streams the UI polls, are not limited.
+var rateLimitedReads = map[string]bool{
+	"/api/contacts":        true,
+	"/api/contacts/search": true,
+	"/api/contacts/export": true,
+	"/api/batch-runs":      true,
+}
+
+// RateLimitConfig is the per-client token bucket: RPS requests per second on
+// average, with bursts of up to Burst. RPS 0 turns limiting off.
+type RateLimitConfig struct {
+	RPS   int
+	Burst int
+}
+
+// ParseRateLimitConfig reads the limits from FRIDAY_RATE_LIMIT_RPS and
+// FRIDAY_RATE_LIMIT_BURST style values; empty ones keep the defaults.
+func ParseRateLimitConfig(rps, burst string) (RateLimitConfig, error) {
+	config := RateLimitConfig{RPS: DefaultRateLimitRPS, Burst: DefaultRateLimitBurst}
+	if rps = strings.TrimSpace(rps); rps != "" {
+		n, err := strconv.Atoi(rps)
+		if err != nil || n < 0 {
+			return config, fmt.Errorf("rate limit RPS must be a whole number of 0 or more, got %q", rps)
+		}
+		config.RPS = n
+	}
+	if burst = strings.TrimSpace(burst); burst != "" {
+		n, err := strconv.Atoi(burst)
+		if err != nil || n < 1 {
+			return config, fmt.Errorf("rate limit burst must be a whole number of 1 or more, got %q", burst)
+		}
+		config.Burst = n
+	}
+	return config, nil
+}
+
+// tokenBucket holds a client's remaining requests as of last.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket for the time since it was last used and spends one
+// token. When it is empty it returns false and how long until a token is back.
+func (b *tokenBucket) take(now time.Time, rps, burst int) (bool, time.Duration) {
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*float64(rps))
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / float64(rps) * float64(time.Second))
+	return false, wait
+}
+
+// RateLimiter limits how fast each client IP may call the expensive read
+// endpoints and anything that writes. Every read endpoint has its own bucket;
+// writes share one. The server.rate_limit_* settings take precedence over the
+// configured defaults and apply without a restart.
+type RateLimiter struct {
+	settingsRepo *models.SettingsRepository
+
+	mu        sync.Mutex
+	defaults  RateLimitConfig
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a new rate limiting middleware.
+func NewRateLimiter(settingsRepo *models.SettingsRepository, defaults RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		settingsRepo: settingsRepo,
+		defaults:     defaults,
+		buckets:      make(map[string]*tokenBucket),
+		lastSweep:    time.Now(),
+	}
+}
+
+// Wrap limits the requests rateLimitBucket picks out, answering 429 with a
+// Retry-After header once a client has used up its bucket.
+func (l *RateLimiter) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket := rateLimitBucket(r)
+		if bucket == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		config := l.config()
+		if config.RPS == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := clientIP(r)
+		ok, wait := l.take(ip+" "+bucket, config, time.Now())
+		if !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			logging.FromContext(r.Context()).Warn("Rate limited", "client_ip", ip, "bucket", bucket)
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			jsonError(w, tr(r, "rate_limited", seconds), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SetDefaults replaces the limits used where the settings set none, as when
+// FRIDAY_RATE_LIMIT_RPS or FRIDAY_RATE_LIMIT_BURST is reloaded.
+func (l *RateLimiter) SetDefaults(defaults RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.defaults = defaults
+}
+
+// config returns the limits in effect: settings first, then the defaults.
+func (l *RateLimiter) config() RateLimitConfig {
+	l.mu.Lock()
+	defaults := l.defaults
+	l.mu.Unlock()
+
+	rps, err := l.settingsRepo.GetInt(models.SettingRateLimitRPS, defaults.RPS)
+	if err != nil {
+		rps = defaults.RPS
+	}
+	burst, err := l.settingsRepo.GetInt(models.SettingRateLimitBurst, defaults.Burst)
+	if err != nil {
+		burst = defaults.Burst
+	}
+	return RateLimitConfig{RPS: rps, Burst: burst}
+}
+
+// take spends a token from the named bucket, creating it full.
+func (l *RateLimiter) take(key string, config RateLimitConfig, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweepLocked(now, config)
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(config.Burst), last: now}
+		l.buckets[key] = bucket
+	}
+	return bucket.take(now, config.RPS, config.Burst)
+}
+
+// sweepLocked drops buckets that have refilled completely, which behave the
+// same as a new one.
+func (l *RateLimiter) sweepLocked(now time.Time, config RateLimitConfig) {
+	full := time.Duration(float64(config.Burst) / float64(config.RPS) * float64(time.Second))
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// rateLimitBucket names the bucket a request draws from, or "" when it is not
+// limited: the path for expensive reads, "write" for changes under /api/.
+func rateLimitBucket(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if rateLimitedReads[r.URL.Path] {
+			return r.URL.Path
+		}
+		return ""
+	case http.MethodOptions:
+		return ""
+	}
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		return "write"
+	}
+	return ""
+}
+
+// clientIP is the address the request came from, without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"friday/internal/models"
+)
+
+func TestParseRateLimitConfig(t *testing.T) {
+	tests := []struct {
+		rps, burst string
+		want       RateLimitConfig
+		wantErr    bool
+	}{
+		{"", "", RateLimitConfig{RPS: DefaultRateLimitRPS, Burst: DefaultRateLimitBurst}, false},
+		{" 10 ", "", RateLimitConfig{RPS: 10, Burst: DefaultRateLimitBurst}, false},
+		{"0", "1", RateLimitConfig{RPS: 0, Burst: 1}, false},
+		{"-1", "", RateLimitConfig{}, true},
+		{"fast", "", RateLimitConfig{}, true},
+		{"", "0", RateLimitConfig{}, true},
+		{"", "1.5", RateLimitConfig{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRateLimitConfig(tt.rps, tt.burst)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseRateLimitConfig(%q, %q) = %+v, want an error", tt.rps, tt.burst, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRateLimitConfig(%q, %q) = %+v, %v; want %+v", tt.rps, tt.burst, got, err, tt.want)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := &tokenBucket{tokens: 3, last: start}
+
+	for i := range 3 {
+		if ok, _ := b.take(start, 2, 3); !ok {
+			t.Fatalf("take %d of a full bucket of 3 refused", i+1)
+		}
+	}
+	if ok, wait := b.take(start, 2, 3); ok || wait != 500*time.Millisecond {
+		t.Errorf("take from an empty bucket = %t, %v; want false, 500ms", ok, wait)
+	}
+	if ok, wait := b.take(start.Add(250*time.Millisecond), 2, 3); ok || wait != 250*time.Millisecond {
+		t.Errorf("take after half a token refilled = %t, %v; want false, 250ms", ok, wait)
+	}
+	if ok, _ := b.take(start.Add(500*time.Millisecond), 2, 3); !ok {
+		t.Error("take after a token refilled refused")
+	}
+
+	// An idle bucket refills to the burst and no further
+	idle := start.Add(time.Hour)
+	for i := range 3 {
+		if ok, _ := b.take(idle, 2, 3); !ok {
+			t.Fatalf("take %d after an hour idle refused", i+1)
+		}
+	}
+	if ok, _ := b.take(idle, 2, 3); ok {
+		t.Error("an idle bucket held more than its burst")
+	}
+}
+
+// limitedRequest sends method path from ip through l and returns the response.
+func limitedRequest(l *RateLimiter, method, path, ip string) *httptest.ResponseRecorder {
+	handler := l.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = ip + ":40000"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimiter(t *testing.T) {
+	settings := models.NewSettingsRepository(newTestDB(t))
+	l := NewRateLimiter(settings, RateLimitConfig{RPS: 1, Burst: 2})
+
+	for i := range 2 {
+		if rec := limitedRequest(l, http.MethodGet, "/api/contacts", "10.0.0.1"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d within the burst: status %d", i+1, rec.Code)
+		}
+	}
+	rec := limitedRequest(l, http.MethodGet, "/api/contacts", "10.0.0.1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request past the burst: status %d, want 429", rec.Code)
+	}
+	if retry := rec.Header().Get("Retry-After"); retry != "1" {
+		t.Errorf("Retry-After = %q, want 1", retry)
+	}
+	var body struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+	decodeResponse(t, rec, &body)
+	if body.Success || body.Message != "Too many requests, retry after 1s" {
+		t.Errorf("body = %+v, want the rate limit error", body)
+	}
+
+	// Other clients, other read endpoints and unlimited endpoints are not affected
+	for _, req := range []struct{ method, path, ip string }{
+		{http.MethodGet, "/api/contacts", "10.0.0.2"},
+		{http.MethodGet, "/api/batch-runs", "10.0.0.1"},
+		{http.MethodGet, "/api/whatsapp/status", "10.0.0.1"},
+		{http.MethodOptions, "/api/contacts", "10.0.0.1"},
+		{http.MethodPost, "/api/drafts", "10.0.0.1"},
+	} {
+		if rec := limitedRequest(l, req.method, req.path, req.ip); rec.Code != http.StatusOK {
+			t.Errorf("%s %s from %s: status %d, want 200", req.method, req.path, req.ip, rec.Code)
+		}
+	}
+
+	// Writes share one bucket, whatever the endpoint
+	limitedRequest(l, http.MethodPut, "/api/settings", "10.0.0.3")
+	limitedRequest(l, http.MethodDelete, "/api/drafts/1", "10.0.0.3")
+	if rec := limitedRequest(l, http.MethodPost, "/api/groups", "10.0.0.3"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("third write of a burst of 2: status %d, want 429", rec.Code)
+	}
+
+	// The settings take precedence, and RPS 0 turns limiting off
+	if err := settings.Set(models.SettingRateLimitRPS, "0", "test"); err != nil {
+		t.Fatalf("failed to set rate limit: %v", err)
+	}
+	for i := range 5 {
+		if rec := limitedRequest(l, http.MethodGet, "/api/contacts", "10.0.0.1"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d with limiting off: status %d", i+1, rec.Code)
+		}
+	}
+}
+
+func TestRateLimiterSweepsFullBuckets(t *testing.T) {
+	l := NewRateLimiter(models.NewSettingsRepository(newTestDB(t)), RateLimitConfig{RPS: 1, Burst: 2})
+	config := RateLimitConfig{RPS: 1, Burst: 2}
+	now := time.Now()
+	for i := range 3 {
+		l.take("10.0.0."+strconv.Itoa(i)+" write", config, now)
+	}
+	l.take("10.0.0.9 write", config, now.Add(rateLimitSweepInterval))
+	if len(l.buckets) != 1 {
+		t.Errorf("%d buckets after a sweep, want only the one just used", len(l.buckets))
+	}
+}
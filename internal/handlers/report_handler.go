@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"friday/internal/models"
+)
+
+// ReportHandler serves activity reports.
+type ReportHandler struct {
+	repo            *models.ReportRepository
+	settingsRepo    *models.SettingsRepository
+	defaultTimezone string // FRIDAY_TIMEZONE; the batch.timezone setting takes precedence
+}
+
+// NewReportHandler creates a new report handler.
+func NewReportHandler(repo *models.ReportRepository, settingsRepo *models.SettingsRepository, defaultTimezone string) *ReportHandler {
+	return &ReportHandler{repo: repo, settingsRepo: settingsRepo, defaultTimezone: defaultTimezone}
+}
+
+type WeeklyReportResponse struct {
+	Success bool                 `json:"success"`
+	Message string               `json:"message"`
+	Report  *models.WeeklyReport `json:"report"`
+}
+
+// weeklyCSVSections lists the tabular parts of the weekly report available as CSV.
+var weeklyCSVSections = []string{"days", "errors", "groups", "batches"}
+
+// HandleWeekly handles GET /api/reports/weekly?week=2025-W12. Without week it
+// reports the current week. format=csv with section=days|errors|groups|batches
+// downloads one table instead of the JSON report.
+func (h *ReportHandler) HandleWeekly(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	loc, err := h.location()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to resolve report timezone: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	start := models.ISOWeekStart(time.Now().In(loc))
+	if week := r.URL.Query().Get("week"); week != "" {
+		if start, err = models.ParseISOWeek(week, loc); err != nil {
+			jsonError(w, fmt.Sprintf("Invalid week: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	format := r.URL.Query().Get("format")
+	section := r.URL.Query().Get("section")
+	switch format {
+	case "", "json":
+	case "csv":
+		if section == "" {
+			section = "days"
+		}
+		valid := false
+		for _, s := range weeklyCSVSections {
+			valid = valid || s == section
+		}
+		if !valid {
+			jsonError(w, fmt.Sprintf("Invalid section %q (use %s)", section, strings.Join(weeklyCSVSections, ", ")), http.StatusBadRequest)
+			return
+		}
+	default:
+		jsonError(w, fmt.Sprintf("Invalid format %q (use json or csv)", format), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.repo.Weekly(start)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to build report: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if format == "csv" {
+		writeWeeklyCSV(w, report, section)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, WeeklyReportResponse{
+		Success: true,
+		Message: tr(r, "weekly_report_retrieved", report.Week),
+		Report:  report,
+	})
+}
+
+// location returns the zone weeks and days are counted in, the same one quiet
+// hours use.
+func (h *ReportHandler) location() (*time.Location, error) {
+	return settingsLocation(h.settingsRepo, h.defaultTimezone)
+}
+
+// settingsLocation resolves the batch.timezone setting, falling back to
+// defaultTimezone (FRIDAY_TIMEZONE) and then the server's zone.
+func settingsLocation(settingsRepo *models.SettingsRepository, defaultTimezone string) (*time.Location, error) {
+	tz, err := settingsRepo.GetString(models.SettingTimezone, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timezone setting: %w", err)
+	}
+	if tz == "" {
+		tz = defaultTimezone
+	}
+	if tz == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	return loc, nil
+}
+
+func writeWeeklyCSV(w http.ResponseWriter, report *models.WeeklyReport, section string) {
+	var rows [][]string
+	switch section {
+	case "days":
+		rows = append(rows, []string{"date", "sent", "failed"})
+		for _, d := range report.Days {
+			rows = append(rows, []string{d.Date, strconv.Itoa(d.Sent), strconv.Itoa(d.Failed)})
+		}
+	case "errors":
+		rows = append(rows, []string{"error", "count"})
+		for _, e := range report.TopErrors {
+			rows = append(rows, []string{e.Error, strconv.Itoa(e.Count)})
+		}
+	case "groups":
+		rows = append(rows, []string{"group_id", "group_name", "added"})
+		for _, g := range report.NewMembers {
+			rows = append(rows, []string{strconv.FormatInt(g.GroupID, 10), g.GroupName, strconv.Itoa(g.Added)})
+		}
+	case "batches":
+		rows = append(rows, []string{"id", "draft_title", "group_name", "status", "total", "sent", "failed", "skipped", "started_at", "completed_at"})
+		for _, b := range report.BatchRuns {
+			var started, completed string
+			if b.StartedAt != nil {
+				started = b.StartedAt.Format(time.RFC3339)
+			}
+			if b.CompletedAt != nil {
+				completed = b.CompletedAt.Format(time.RFC3339)
+			}
+			rows = append(rows, []string{
+				strconv.FormatInt(b.ID, 10), b.DraftTitle, b.GroupName, string(b.Status),
+				strconv.Itoa(b.TotalCount), strconv.Itoa(b.SentCount), strconv.Itoa(b.FailedCount), strconv.Itoa(b.SkippedCount),
+				started, completed,
+			})
+		}
+	}
+
+	filename := fmt.Sprintf("friday-%s-%s.csv", report.Week, section)
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	csv.NewWriter(w).WriteAll(rows)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"friday/internal/logging"
+)
+
+// RequestIDHeader carries the request ID. A client-supplied value is kept so
+// logs can be correlated across services; otherwise one is generated.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied IDs so they cannot bloat log lines.
+const maxRequestIDLength = 64
+
+// WithRequestID tags every request with an ID, echoes it in the response header
+// and stores it in the request context, where logging.FromContext picks it up.
+func WithRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RouteDoc documents one method of a registered path. Request and Response are
+// example values; only their Go types matter, the published JSON shape is derived
+// from them by reflection so it cannot drift from the handler structs.
+type RouteDoc struct {
+	Method      string
+	Path        string // Defaults to the registered pattern
+	Description string
+	Request     interface{} // Example request body, nil when there is none
+	Response    interface{} // Example response body, nil for non-JSON responses
+}
+
+// RouteInfo is a documented route as returned by GET /api/routes.
+type RouteInfo struct {
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Description string      `json:"description"`
+	Request     interface{} `json:"request,omitempty"`  // JSON shape of the request body
+	Response    interface{} `json:"response,omitempty"` // JSON shape of the response body
+}
+
+type RoutesResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Routes  []RouteInfo `json:"routes"`
+	Count   int         `json:"count"`
+}
+
+// RouteRegistry wraps a ServeMux and records documentation for the routes
+// registered through it.
+type RouteRegistry struct {
+	mux    *http.ServeMux
+	mu     sync.RWMutex
+	routes []RouteInfo
+}
+
+// NewRouteRegistry creates a registry that registers handlers on mux.
+func NewRouteRegistry(mux *http.ServeMux) *RouteRegistry {
+	return &RouteRegistry{mux: mux}
+}
+
+// HandleFunc registers handler for pattern on the mux and records its docs.
+func (reg *RouteRegistry) HandleFunc(pattern string, handler http.HandlerFunc, docs ...RouteDoc) {
+	reg.mux.HandleFunc(pattern, handler)
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for _, doc := range docs {
+		path := doc.Path
+		if path == "" {
+			path = pattern
+		}
+		info := RouteInfo{
+			Method:      doc.Method,
+			Path:        path,
+			Description: doc.Description,
+		}
+		if doc.Request != nil {
+			info.Request = jsonShape(reflect.TypeOf(doc.Request), map[reflect.Type]bool{})
+		}
+		if doc.Response != nil {
+			info.Response = jsonShape(reflect.TypeOf(doc.Response), map[reflect.Type]bool{})
+		}
+		reg.routes = append(reg.routes, info)
+	}
+}
+
+// Routes returns the documented routes in registration order.
+func (reg *RouteRegistry) Routes() []RouteInfo {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	return append([]RouteInfo(nil), reg.routes...)
+}
+
+// HandleRoutes handles GET /api/routes.
+func (reg *RouteRegistry) HandleRoutes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	routes := reg.Routes()
+	writeJSON(w, http.StatusOK, RoutesResponse{
+		Success: true,
+		Message: tr(r, "routes_retrieved"),
+		Routes:  routes,
+		Count:   len(routes),
+	})
+}
+
+type UnknownEndpointResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Path    string `json:"path"`
+}
+
+// HandleUnknownAPI answers /api/ paths no registered pattern matches with a
+// JSON 404, so a mistyped endpoint does not fall through to the landing page.
+func HandleUnknownAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusNotFound, UnknownEndpointResponse{
+		Success: false,
+		Message: tr(r, "unknown_endpoint"),
+		Path:    r.URL.Path,
+	})
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// jsonShape describes how encoding/json renders values of type t: objects become
+// maps of field name to shape, arrays a one-element list, and scalars their JSON
+// type name. Types already being described are referenced by name to stop recursion.
+func jsonShape(t reflect.Type, seen map[reflect.Type]bool) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return "datetime"
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		return "any"
+	case t.Implements(textType) || reflect.PointerTo(t).Implements(textType):
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // base64
+		}
+		return []interface{}{jsonShape(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"{key}": jsonShape(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return t.Name()
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		fields := map[string]interface{}{}
+		addStructFields(t, fields, seen)
+		return fields
+	default:
+		return "any"
+	}
+}
+
+// addStructFields adds the JSON fields of struct type t to fields, flattening
+// embedded structs the way encoding/json does.
+func addStructFields(t reflect.Type, fields map[string]interface{}, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructFields(ft, fields, seen)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		if strings.Contains(opts, "string") {
+			fields[name] = "string"
+			continue
+		}
+		fields[name] = jsonShape(f.Type, seen)
+	}
+}
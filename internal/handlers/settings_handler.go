@@ -0,0 +1,626 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"friday/internal/batch"
+	"friday/internal/models"
+)
+
+// SettingsHandler handles HTTP requests for admin settings.
+type SettingsHandler struct {
+	repo *models.SettingsRepository
+}
+
+// NewSettingsHandler creates a new settings handler.
+func NewSettingsHandler(repo *models.SettingsRepository) *SettingsHandler {
+	return &SettingsHandler{repo: repo}
+}
+
+// Reload re-reads the settings from the database, so values changed outside
+// the API apply without a restart, and logs each one that changed, masking
+// secrets. A removed setting is logged with an empty new value and falls back
+// to its default. Returns how many changed.
+func (h *SettingsHandler) Reload() (int, error) {
+	changes, err := h.repo.Reload()
+	if err != nil {
+		return 0, err
+	}
+	for _, change := range changes {
+		var oldValue, newValue string
+		if change.OldValue != nil {
+			oldValue = *change.OldValue
+		}
+		if change.NewValue != nil {
+			newValue = *change.NewValue
+		}
+		if def := findSettingDefinition(change.Key); def != nil && def.Secret {
+			oldValue, newValue = maskSecret(oldValue), maskSecret(newValue)
+		}
+		slog.Info("Setting reloaded", "key", change.Key, "old", oldValue, "new", newValue)
+	}
+	return len(changes), nil
+}
+
+// settingDefinition describes a setting that may be read and changed through the API.
+type settingDefinition struct {
+	Key         string
+	Type        string // int, bool, string
+	Default     string
+	Description string
+	Secret      bool // Value is masked in listings and the audit trail
+	Validate    func(value string) error
+}
+
+// maskedSecret replaces the value of a secret setting that is set.
+const maskedSecret = "********"
+
+// settingDefinitions is the whitelist of settings exposed by /api/settings.
+var settingDefinitions = []settingDefinition{
+	{
+		Key:         models.SettingBatchMinDelaySeconds,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultBatchMinDelaySeconds),
+		Description: "Minimum seconds between batch messages",
+		Validate:    intRange(1, 3600),
+	},
+	{
+		Key:         models.SettingBatchMaxDelaySeconds,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultBatchMaxDelaySeconds),
+		Description: "Maximum seconds between batch messages",
+		Validate:    intRange(1, 3600),
+	},
+	{
+		Key:         models.SettingBatchMaxConcurrent,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultBatchMaxConcurrent),
+		Description: "Batches sent at the same time; their messages take turns under the one delay schedule",
+		Validate:    intRange(1, 10),
+	},
+	{
+		Key:         models.SettingSendTimeoutSeconds,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultSendTimeoutSeconds),
+		Description: "Seconds a batch message may wait on WhatsApp before it fails as a timeout; documents get 90 more for the upload",
+		Validate:    intRange(5, 600),
+	},
+	{
+		Key:         models.SettingUndeliveredAfterHours,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultUndeliveredAfterHours),
+		Description: "Hours a sent batch message may go without a delivery receipt before it counts as undelivered, e.g. because the recipient blocked this number; a later receipt still marks it delivered",
+		Validate:    intRange(1, 30*24),
+	},
+	{
+		Key:         models.SettingRecipientCap,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultRecipientCap),
+		Description: "Messages one contact may be sent within batch.recipient_cap_days, counting batch messages and outbox sends; batches skip contacts at the cap (0 = no cap)",
+		Validate:    intRange(0, 1000),
+	},
+	{
+		Key:         models.SettingRecipientCapDays,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultRecipientCapDays),
+		Description: "Days back the recipient cap counts sends, e.g. 1 for a daily cap or 7 for a weekly one",
+		Validate:    intRange(1, 365),
+	},
+	{
+		Key:         models.SettingDraftMaxLength,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultDraftMaxLength),
+		Description: "Characters a draft may render to, with the longest placeholder values, before lint and batch creation warn",
+		Validate:    intRange(100, 65536),
+	},
+	{
+		Key:         models.SettingAutoResumeBatches,
+		Type:        "bool",
+		Default:     strconv.FormatBool(models.DefaultAutoResumeBatches),
+		Description: "Resume batches that were running when the server stopped (false = hold them as interrupted until resumed)",
+		Validate: func(value string) error {
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("must be true or false")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         models.SettingQuarantineThreshold,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultQuarantineThreshold),
+		Description: "Consecutive permanent send failures before a contact is left out of new batches (0 = never)",
+		Validate:    intRange(0, 100),
+	},
+	{
+		Key:         models.SettingQuietHours,
+		Type:        "string",
+		Description: "Daily window with no batch sending, e.g. 21:00-09:00 (empty = FRIDAY_QUIET_HOURS)",
+		Validate: func(value string) error {
+			if value == "" {
+				return nil
+			}
+			_, err := batch.ParseQuietHours(value, "")
+			return err
+		},
+	},
+	{
+		Key:         models.SettingTimezone,
+		Type:        "string",
+		Description: "IANA timezone for quiet hours (empty = FRIDAY_TIMEZONE or server zone)",
+		Validate: func(value string) error {
+			if value == "" {
+				return nil
+			}
+			if _, err := time.LoadLocation(value); err != nil {
+				return fmt.Errorf("unknown timezone %q", value)
+			}
+			return nil
+		},
+	},
+	{
+		Key:         models.SettingDefaultCountryCode,
+		Type:        "string",
+		Description: "Country calling code for numbers entered in national format, e.g. 90",
+		Validate: func(value string) error {
+			if value == "" {
+				return nil
+			}
+			if len(value) > 4 || strings.Trim(value, "0123456789") != "" || value[0] == '0' {
+				return fmt.Errorf("country code must be 1-4 digits without a leading zero or +")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         models.SettingKeepaliveSeconds,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultKeepaliveSeconds),
+		Description: "Seconds between presence updates that keep an idle session alive (0 = off, otherwise 30-3600)",
+		Validate: func(value string) error {
+			if value == "0" {
+				return nil
+			}
+			return intRange(30, 3600)(value)
+		},
+	},
+	{
+		Key:         models.SettingPresence,
+		Type:        "string",
+		Default:     models.DefaultPresence,
+		Description: "Presence sent by the keepalive: available or unavailable (available mutes phone notifications)",
+		Validate: func(value string) error {
+			if value != "available" && value != "unavailable" {
+				return fmt.Errorf("must be available or unavailable")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         models.SettingRateLimitRPS,
+		Type:        "int",
+		Description: "Requests per second each client IP may make to the contact list, search and export, batch listing and every write endpoint (0 = no limit; unset = FRIDAY_RATE_LIMIT_RPS or 5)",
+		Validate:    intRange(0, 1000),
+	},
+	{
+		Key:         models.SettingRateLimitBurst,
+		Type:        "int",
+		Description: "Requests a client may make in a burst before the per-second limit applies (unset = FRIDAY_RATE_LIMIT_BURST or 20)",
+		Validate:    intRange(1, 10000),
+	},
+	{
+		Key:         models.SettingOutboxTTLMinutes,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultOutboxTTLMinutes),
+		Description: "Minutes a send queued while WhatsApp is disconnected (queue_if_disconnected) waits before it expires unsent",
+		Validate:    intRange(1, 7*24*60),
+	},
+	{
+		Key:         models.SettingDocumentMaxSizeMB,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultDocumentMaxSizeMB),
+		Description: "Largest file, in megabytes, a batch may send as a per-recipient document; larger ones fail that message",
+		Validate:    intRange(1, 100),
+	},
+	{
+		Key:         models.SettingMaintenanceHour,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultMaintenanceHour),
+		Description: "Hour of the day (0-23, in batch.timezone) the nightly database maintenance runs; it waits while a batch is sending",
+		Validate:    intRange(0, 23),
+	},
+	{
+		Key:         models.SettingMaintenanceRetentionDays,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultMaintenanceRetentionDays),
+		Description: "Days finished batches (with their messages) and sent, failed or expired outbox messages are kept before maintenance deletes them (0 = forever)",
+		Validate:    intRange(0, 3650),
+	},
+	{
+		Key:         models.SettingMaintenanceVacuumPercent,
+		Type:        "int",
+		Default:     strconv.Itoa(models.DefaultMaintenanceVacuumPercent),
+		Description: "Percentage of the database file left free by deletions above which maintenance runs VACUUM to shrink it",
+		Validate:    intRange(1, 100),
+	},
+	{
+		Key:         models.SettingNotifySelfMessage,
+		Type:        "bool",
+		Default:     "false",
+		Description: "Send a WhatsApp summary when a batch finishes",
+		Validate: func(value string) error {
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("must be true or false")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         models.SettingNotifySelfJID,
+		Type:        "string",
+		Description: "Recipient of the batch summary (empty = own number)",
+		Validate: func(value string) error {
+			if value != "" && !strings.Contains(value, "@") {
+				return fmt.Errorf("must be a full JID like 905551234567@s.whatsapp.net")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         models.SettingNotifyWebhookURL,
+		Type:        "string",
+		Description: "URL receiving a JSON POST when a batch finishes",
+		Validate: func(value string) error {
+			if value == "" {
+				return nil
+			}
+			u, err := url.Parse(value)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("must be an absolute http(s) URL")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         models.SettingTriggerSecret,
+		Type:        "string",
+		Description: "Shared secret signing POST /api/integrations/trigger-batch requests (empty = endpoint disabled)",
+		Secret:      true,
+		Validate: func(value string) error {
+			if value != "" && len(value) < 16 {
+				return fmt.Errorf("must be at least 16 characters")
+			}
+			return nil
+		},
+	},
+}
+
+// maskSecret hides a secret value, keeping empty values visible so it is clear
+// whether one is set.
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return maskedSecret
+}
+
+func intRange(min, max int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < min || n > max {
+			return fmt.Errorf("must be an integer between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+func findSettingDefinition(key string) *settingDefinition {
+	for i := range settingDefinitions {
+		if settingDefinitions[i].Key == key {
+			return &settingDefinitions[i]
+		}
+	}
+	return nil
+}
+
+// Request/Response types
+
+// SettingInfo is a setting as returned by GET /api/settings.
+type SettingInfo struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Default     string `json:"default"`
+	IsDefault   bool   `json:"is_default"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+type SettingsResponse struct {
+	Success  bool          `json:"success"`
+	Message  string        `json:"message"`
+	Settings []SettingInfo `json:"settings,omitempty"`
+}
+
+type SettingsAuditResponse struct {
+	Success bool                   `json:"success"`
+	Message string                 `json:"message"`
+	Changes []models.SettingChange `json:"changes"`
+	Count   int                    `json:"count"`
+}
+
+// NotificationSettings configures where batch completion summaries are sent.
+type NotificationSettings struct {
+	SelfMessage bool   `json:"self_message"` // Send a WhatsApp summary to SelfJID (or the own number)
+	SelfJID     string `json:"self_jid"`     // Optional recipient override
+	WebhookURL  string `json:"webhook_url"`  // Optional URL receiving a JSON POST
+}
+
+type NotificationSettingsResponse struct {
+	Success  bool                  `json:"success"`
+	Message  string                `json:"message"`
+	Settings *NotificationSettings `json:"settings,omitempty"`
+}
+
+// HandleSettings handles GET /api/settings (list) and PUT /api/settings (update).
+// PUT takes a JSON object of key -> value; values may be strings, numbers or booleans.
+func (h *SettingsHandler) HandleSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listSettings(w, r)
+	case http.MethodPut:
+		h.updateSettings(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleAudit handles GET /api/settings/audit?limit=N
+func (h *SettingsHandler) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, _, err := parsePage(r, 100, 1000)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	changes, err := h.repo.GetAudit(limit)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve audit trail: %v", err), http.StatusInternalServerError)
+		return
+	}
+	for i := range changes {
+		if def := findSettingDefinition(changes[i].Key); def != nil && def.Secret {
+			changes[i].NewValue = maskSecret(changes[i].NewValue)
+			if changes[i].OldValue != nil {
+				masked := maskSecret(*changes[i].OldValue)
+				changes[i].OldValue = &masked
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, SettingsAuditResponse{
+		Success: true,
+		Message: tr(r, "audit_retrieved"),
+		Changes: changes,
+		Count:   len(changes),
+	})
+}
+
+func (h *SettingsHandler) listSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.currentSettings()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SettingsResponse{
+		Success:  true,
+		Message:  tr(r, "settings_retrieved"),
+		Settings: settings,
+	})
+}
+
+func (h *SettingsHandler) updateSettings(w http.ResponseWriter, r *http.Request) {
+	var raw map[string]json.RawMessage
+	if !decodeJSON(w, r, &raw) {
+		return
+	}
+	if len(raw) == 0 {
+		jsonError(w, tr(r, "no_settings_given"), http.StatusBadRequest)
+		return
+	}
+
+	// Validate everything before saving anything
+	updates := make(map[string]string, len(raw))
+	for key, rawValue := range raw {
+		def := findSettingDefinition(key)
+		if def == nil {
+			jsonError(w, fmt.Sprintf("Unknown setting %q", key), http.StatusBadRequest)
+			return
+		}
+
+		var value string
+		if err := json.Unmarshal(rawValue, &value); err != nil {
+			value = string(rawValue) // Number or boolean literal
+		}
+		value = strings.TrimSpace(value)
+
+		if err := def.Validate(value); err != nil {
+			jsonError(w, fmt.Sprintf("Invalid value for %s: %v", key, err), http.StatusBadRequest)
+			return
+		}
+		updates[key] = value
+	}
+
+	if err := h.validateDelayRange(updates); err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.saveSettings(updates, r); err != nil {
+		jsonError(w, fmt.Sprintf("Failed to save settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.currentSettings()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SettingsResponse{
+		Success:  true,
+		Message:  fmt.Sprintf("%d settings saved", len(updates)),
+		Settings: settings,
+	})
+}
+
+// validateDelayRange checks min <= max using the pending updates over the saved values.
+func (h *SettingsHandler) validateDelayRange(updates map[string]string) error {
+	minDelay, err := h.repo.GetInt(models.SettingBatchMinDelaySeconds, models.DefaultBatchMinDelaySeconds)
+	if err != nil {
+		return err
+	}
+	maxDelay, err := h.repo.GetInt(models.SettingBatchMaxDelaySeconds, models.DefaultBatchMaxDelaySeconds)
+	if err != nil {
+		return err
+	}
+	if v, ok := updates[models.SettingBatchMinDelaySeconds]; ok {
+		minDelay, _ = strconv.Atoi(v)
+	}
+	if v, ok := updates[models.SettingBatchMaxDelaySeconds]; ok {
+		maxDelay, _ = strconv.Atoi(v)
+	}
+	if minDelay > maxDelay {
+		return fmt.Errorf("%s (%d) must not exceed %s (%d)",
+			models.SettingBatchMinDelaySeconds, minDelay, models.SettingBatchMaxDelaySeconds, maxDelay)
+	}
+	return nil
+}
+
+func (h *SettingsHandler) saveSettings(updates map[string]string, r *http.Request) error {
+	for key, value := range updates {
+		if err := h.repo.Set(key, value, r.RemoteAddr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *SettingsHandler) currentSettings() ([]SettingInfo, error) {
+	saved, err := h.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]SettingInfo, 0, len(settingDefinitions))
+	for _, def := range settingDefinitions {
+		value, ok := saved[def.Key]
+		if !ok {
+			value = def.Default
+		}
+		if def.Secret {
+			value = maskSecret(value)
+		}
+		settings = append(settings, SettingInfo{
+			Key:         def.Key,
+			Value:       value,
+			Default:     def.Default,
+			IsDefault:   !ok,
+			Type:        def.Type,
+			Description: def.Description,
+		})
+	}
+	return settings, nil
+}
+
+// HandleNotifications handles GET/PUT /api/settings/notifications
+func (h *SettingsHandler) HandleNotifications(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.getNotifications(w, r)
+	case http.MethodPut:
+		h.updateNotifications(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *SettingsHandler) getNotifications(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.loadNotifications()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to retrieve settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NotificationSettingsResponse{
+		Success:  true,
+		Message:  tr(r, "settings_retrieved"),
+		Settings: settings,
+	})
+}
+
+func (h *SettingsHandler) updateNotifications(w http.ResponseWriter, r *http.Request) {
+	var req NotificationSettings
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.SelfJID = strings.TrimSpace(req.SelfJID)
+	req.WebhookURL = strings.TrimSpace(req.WebhookURL)
+
+	updates := map[string]string{
+		models.SettingNotifySelfMessage: strconv.FormatBool(req.SelfMessage),
+		models.SettingNotifySelfJID:     req.SelfJID,
+		models.SettingNotifyWebhookURL:  req.WebhookURL,
+	}
+	for key, value := range updates {
+		if err := findSettingDefinition(key).Validate(value); err != nil {
+			jsonError(w, fmt.Sprintf("Invalid value for %s: %v", key, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.saveSettings(updates, r); err != nil {
+		jsonError(w, fmt.Sprintf("Failed to save settings: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NotificationSettingsResponse{
+		Success:  true,
+		Message:  tr(r, "settings_saved"),
+		Settings: &req,
+	})
+}
+
+func (h *SettingsHandler) loadNotifications() (*NotificationSettings, error) {
+	selfMessage, err := h.repo.GetBool(models.SettingNotifySelfMessage, false)
+	if err != nil {
+		return nil, err
+	}
+	selfJID, err := h.repo.GetString(models.SettingNotifySelfJID, "")
+	if err != nil {
+		return nil, err
+	}
+	webhookURL, err := h.repo.GetString(models.SettingNotifyWebhookURL, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationSettings{
+		SelfMessage: selfMessage,
+		SelfJID:     selfJID,
+		WebhookURL:  webhookURL,
+	}, nil
+}
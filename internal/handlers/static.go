@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// Stylesheets and scripts for the web pages, served under /static/ so the UI
+// works without internet access. static/css/tailwind.css is generated from the
+// classes the templates use; see tailwind.config.js.
+//
+//go:embed static
+var embeddedStatic embed.FS
+
+var staticFiles, assetVersions = loadStatic()
+
+// loadStatic returns the static directory and a short content hash of each
+// file in it, keyed by path relative to the directory.
+func loadStatic() (fs.FS, map[string]string) {
+	sub, err := fs.Sub(embeddedStatic, "static")
+	if err != nil {
+		panic(err)
+	}
+
+	versions := make(map[string]string)
+	err = fs.WalkDir(sub, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(sub, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		versions[name] = hex.EncodeToString(sum[:])[:12]
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return sub, versions
+}
+
+// assetURL is the "asset" template function: the URL of a static file, with
+// its content hash as version so browsers fetch it again after an upgrade.
+func assetURL(name string) (string, error) {
+	version, ok := assetVersions[name]
+	if !ok {
+		return "", fmt.Errorf("unknown static asset %q", name)
+	}
+	return "/static/" + name + "?v=" + version, nil
+}
+
+// HandleStatic serves GET /static/{path}. Versioned URLs never change content,
+// so they may be cached for good.
+func HandleStatic() http.Handler {
+	files := http.StripPrefix("/static/", http.FileServer(http.FS(staticFiles)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r) // No directory listings
+			return
+		}
+		if r.URL.Query().Get("v") != "" {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,22 @@
+// Once API tokens exist the API answers 401 without one. The pages send the
+// token in the friday-token cookie; ask for it the first time a call is refused.
+(() => {
+    const COOKIE = 'friday-token';
+    const originalFetch = window.fetch.bind(window);
+    let asked = false;
+
+    window.fetch = async (input, init) => {
+        const response = await originalFetch(input, init);
+        const url = new URL(typeof input === 'string' ? input : input.url, location.href);
+        if (response.status !== 401 || url.origin !== location.origin || !url.pathname.startsWith('/api/') || asked) {
+            return response;
+        }
+        asked = true;
+        const token = window.prompt(t('This server requires an API token. Paste a token with the scopes you need:'));
+        if (token && token.trim()) {
+            document.cookie = COOKIE + '=' + encodeURIComponent(token.trim()) + '; path=/; max-age=31536000; SameSite=Strict';
+            location.reload();
+        }
+        return response;
+    };
+})();
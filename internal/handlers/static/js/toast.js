@@ -0,0 +1,44 @@
+// Toast shows transient notifications in the top right corner.
+const Toast = {
+    container: null,
+    init() {
+        if (!this.container) {
+            this.container = document.createElement('div');
+            this.container.className = 'fixed top-4 right-4 z-50 flex flex-col gap-3 max-w-sm';
+            document.body.appendChild(this.container);
+        }
+    },
+    show(message, type = 'info', duration = 4000) {
+        this.init();
+        const colors = {
+            success: 'border-green-500 bg-green-50',
+            error: 'border-red-500 bg-red-50',
+            warning: 'border-amber-500 bg-amber-50',
+            info: 'border-blue-500 bg-blue-50'
+        };
+        const icons = {
+            success: '<svg class="w-5 h-5 text-green-500" fill="currentColor" viewBox="0 0 20 20"><path fill-rule="evenodd" d="M10 18a8 8 0 100-16 8 8 0 000 16zm3.707-9.293a1 1 0 00-1.414-1.414L9 10.586 7.707 9.293a1 1 0 00-1.414 1.414l2 2a1 1 0 001.414 0l4-4z" clip-rule="evenodd"/></svg>',
+            error: '<svg class="w-5 h-5 text-red-500" fill="currentColor" viewBox="0 0 20 20"><path fill-rule="evenodd" d="M10 18a8 8 0 100-16 8 8 0 000 16zM8.707 7.293a1 1 0 00-1.414 1.414L8.586 10l-1.293 1.293a1 1 0 101.414 1.414L10 11.414l1.293 1.293a1 1 0 001.414-1.414L11.414 10l1.293-1.293a1 1 0 00-1.414-1.414L10 8.586 8.707 7.293z" clip-rule="evenodd"/></svg>',
+            warning: '<svg class="w-5 h-5 text-amber-500" fill="currentColor" viewBox="0 0 20 20"><path fill-rule="evenodd" d="M8.257 3.099c.765-1.36 2.722-1.36 3.486 0l5.58 9.92c.75 1.334-.213 2.98-1.742 2.98H4.42c-1.53 0-2.493-1.646-1.743-2.98l5.58-9.92zM11 13a1 1 0 11-2 0 1 1 0 012 0zm-1-8a1 1 0 00-1 1v3a1 1 0 002 0V6a1 1 0 00-1-1z" clip-rule="evenodd"/></svg>',
+            info: '<svg class="w-5 h-5 text-blue-500" fill="currentColor" viewBox="0 0 20 20"><path fill-rule="evenodd" d="M18 10a8 8 0 11-16 0 8 8 0 0116 0zm-7-4a1 1 0 11-2 0 1 1 0 012 0zM9 9a1 1 0 000 2v3a1 1 0 001 1h1a1 1 0 100-2v-3a1 1 0 00-1-1H9z" clip-rule="evenodd"/></svg>'
+        };
+        const toast = document.createElement('div');
+        toast.className = 'flex items-start gap-3 p-4 bg-white rounded-lg shadow-lg border-l-4 ' + colors[type] + ' toast-enter';
+        toast.innerHTML = icons[type] + '<p class="text-sm text-gray-700 flex-1">' + message + '</p>' +
+            '<button onclick="Toast.dismiss(this.parentElement)" class="text-gray-400 hover:text-gray-600"><svg class="w-4 h-4" fill="currentColor" viewBox="0 0 20 20"><path fill-rule="evenodd" d="M4.293 4.293a1 1 0 011.414 0L10 8.586l4.293-4.293a1 1 0 111.414 1.414L11.414 10l4.293 4.293a1 1 0 01-1.414 1.414L10 11.414l-4.293 4.293a1 1 0 01-1.414-1.414L8.586 10 4.293 5.707a1 1 0 010-1.414z" clip-rule="evenodd"/></svg></button>';
+        this.container.appendChild(toast);
+        if (duration > 0) setTimeout(() => this.dismiss(toast), duration);
+        return toast;
+    },
+    dismiss(toast) {
+        if (toast && toast.parentElement) {
+            toast.classList.remove('toast-enter');
+            toast.classList.add('toast-exit');
+            setTimeout(() => toast.remove(), 200);
+        }
+    },
+    success(msg, dur) { return this.show(msg, 'success', dur); },
+    error(msg, dur) { return this.show(msg, 'error', dur); },
+    warning(msg, dur) { return this.show(msg, 'warning', dur); },
+    info(msg, dur) { return this.show(msg, 'info', dur); }
+};
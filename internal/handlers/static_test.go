@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var (
+	// cssClass matches a class selector, with Tailwind's escapes as in .px-1\.5 or .hover\:bg-gray-50
+	cssClass = regexp.MustCompile(`\.((?:\\.|[\w-])+)`)
+	// styleBlock matches a page's own <style> element
+	styleBlock = regexp.MustCompile(`(?s)<style>(.*?)</style>`)
+	// classAttr matches class attributes and className assignments in markup and scripts
+	classAttr = regexp.MustCompile("\\bclass(?:Name)?\\s*=\\s*(\"[^\"]*\"|'[^']*'|`[^`]*`)")
+	// classListCall matches classList calls, whose string arguments are classes
+	classListCall = regexp.MustCompile(`classList\.(add|remove|toggle|replace)\(([^)]*)\)`)
+	quotedString  = regexp.MustCompile(`'([^']*)'|"([^"]*)"`)
+	// classSeparator splits a class list, including one built in a ${} expression
+	classSeparator = regexp.MustCompile("[\\s'\"`{}()?|=+><;,!&]+")
+	// utilityName is what a class looks like; other tokens are script left in a class attribute
+	utilityName = regexp.MustCompile(`^-?[a-z][a-z0-9-]*(?:\.[0-9]+)?(?:[:/][a-z0-9-]+(?:\.[0-9]+)?)*$`)
+)
+
+// scriptOnlyClasses are used by scripts to find elements, or compared with in
+// a class expression, and need no style.
+var scriptOnlyClasses = map[string]bool{
+	"nav-link": true, // nav.html marks the current page's link
+	"csv-link": true, // weekly_report.html appends the chosen week to the CSV links
+	"lid":      true, // contacts.html compares a contact's jid_type with 'lid'
+}
+
+// TestTemplateClassesInBundle checks that every class the templates and
+// scripts use is styled by the bundled stylesheets or a page's own <style>, so
+// a class added without rebuilding tailwind.css fails here instead of leaving
+// the page unstyled.
+func TestTemplateClassesInBundle(t *testing.T) {
+	styled := make(map[string]bool)
+	addSelectors := func(css string) {
+		for _, m := range cssClass.FindAllStringSubmatch(css, -1) {
+			styled[strings.ReplaceAll(m[1], `\`, "")] = true
+		}
+	}
+	for _, name := range []string{"static/css/tailwind.css", "static/css/app.css"} {
+		data, err := fs.ReadFile(embeddedStatic, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addSelectors(string(data))
+	}
+
+	used := make(map[string][]string) // Class -> files using it
+	scan := func(fsys fs.FS, pattern string) {
+		names, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range names {
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content := string(data)
+			for _, m := range styleBlock.FindAllStringSubmatch(content, -1) {
+				addSelectors(m[1])
+			}
+
+			var lists []string
+			for _, m := range classAttr.FindAllStringSubmatch(content, -1) {
+				lists = append(lists, m[1][1:len(m[1])-1])
+			}
+			for _, m := range classListCall.FindAllStringSubmatch(content, -1) {
+				args := quotedString.FindAllStringSubmatch(m[2], -1)
+				if m[1] == "toggle" && len(args) > 1 {
+					args = args[:1] // The second argument is the condition
+				}
+				for _, arg := range args {
+					lists = append(lists, arg[1]+arg[2])
+				}
+			}
+			for _, list := range lists {
+				for _, class := range classSeparator.Split(list, -1) {
+					if utilityName.MatchString(class) && !scriptOnlyClasses[class] {
+						used[class] = append(used[class], name)
+					}
+				}
+			}
+		}
+	}
+	pages, _ := fs.Sub(embeddedTemplates, "templates")
+	scan(pages, "pages/*.html")
+	scan(pages, "partials/*.html")
+	scripts, _ := fs.Sub(embeddedStatic, "static")
+	scan(scripts, "js/*.js")
+
+	var missing []string
+	for class, files := range used {
+		if !styled[class] {
+			missing = append(missing, class+" ("+files[0]+")")
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("classes missing from static/css/tailwind.css; rebuild it as README.md describes:\n%s", strings.Join(missing, "\n"))
+	}
+	if len(used) < 100 {
+		t.Errorf("found only %d classes in the templates; the scan is broken", len(used))
+	}
+}
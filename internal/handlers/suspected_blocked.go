@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"friday/internal/models"
+)
+
+// defaultSuspectedBlockMin is how many undelivered messages in a row make a
+// contact suspect when the request does not say; one alone proves little.
+const defaultSuspectedBlockMin = 2
+
+type SuspectedBlockedResponse struct {
+	Success  bool                    `json:"success"`
+	Message  string                  `json:"message"`
+	Contacts []models.SuspectedBlock `json:"contacts"`
+	Count    int                     `json:"count"`
+}
+
+// HandleSuspectedBlocked handles GET /api/contacts/suspected-blocked, listing
+// contacts whose last batch messages all went without a delivery receipt.
+// WhatsApp does not say when a contact blocks the sender, so this is a guess:
+// a phone that stays off looks the same until it comes back.
+func (h *ContactHandler) HandleSuspectedBlocked(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	minUndelivered := defaultSuspectedBlockMin
+	if v := r.URL.Query().Get("min"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			jsonError(w, tr(r, "suspected_blocked_invalid_min"), http.StatusBadRequest)
+			return
+		}
+		minUndelivered = n
+	}
+
+	contacts, err := h.msgRepo.GetSuspectedBlocked(minUndelivered)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get suspected blocks: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SuspectedBlockedResponse{
+		Success:  true,
+		Message:  tr(r, "suspected_blocked_found", len(contacts)),
+		Contacts: contacts,
+		Count:    len(contacts),
+	})
+}
@@ -0,0 +1,23 @@
+// Builds static/css/tailwind.css from the classes used in the templates and
+// scripts; see README.md.
+module.exports = {
+    content: ['./templates/**/*.html', './static/js/**/*.js'],
+    theme: {
+        extend: {
+            colors: {
+                whatsapp: {
+                    50: '#e8f8ef',
+                    100: '#d1f1df',
+                    200: '#a3e9bf',
+                    500: '#25D366',
+                    600: '#1ebe5d',
+                    700: '#128C7E',
+                    900: '#075E54'
+                }
+            },
+            borderWidth: {
+                3: '3px'
+            }
+        }
+    }
+}
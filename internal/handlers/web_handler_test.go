@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden page files in testdata/pages")
+
+// assetVersion matches the content hash on asset URLs, which the golden files
+// leave out so restyling a page does not change every one of them.
+var assetVersion = regexp.MustCompile(`\?v=[0-9a-f]+`)
+
+// externalResource matches a script, stylesheet, image or font loaded from
+// another host; the UI must work without internet access.
+var externalResource = regexp.MustCompile(`(?i)(?:\b(?:src|href)\s*=\s*["']?|url\(\s*["']?|@import\s+["'])(?:https?:)?//`)
+
+// TestPagesRender renders every page and compares it with its golden file in
+// testdata/pages, after checking the markers each page cannot work without and
+// that nothing is loaded from a CDN or other external host.
+// Run with -update after changing a template.
+func TestPagesRender(t *testing.T) {
+	h := NewWebHandler(nil, nil, nil)
+	pages := []struct {
+		page    string
+		handler http.HandlerFunc
+		path    string
+		markers []string
+	}{
+		{"landing", h.HandleLandingPage, "/", []string{"<title>Friday - WhatsApp API</title>", "I18N.setLang('en')"}},
+		{"qr_scan", h.HandleQRScanPage, "/qr", []string{"<title>Scan QR Code - Friday</title>", "new EventSource('/api/events?topics=status,qr')"}},
+		{"dashboard", h.HandleDashboard, "/dashboard", []string{"<title>Dashboard - Friday</title>", `id="status-indicator"`}},
+		{"drafts", h.HandleDraftsPage, "/drafts", []string{"<title>Drafts - Friday</title>", `id="status-indicator"`}},
+		{"contact", h.HandleContactDetailPage, "/contact/905550000001@s.whatsapp.net", []string{
+			"<title>Contact Details - Friday</title>",
+			`<script type="application/json" id="page-data">{"jid":"905550000001@s.whatsapp.net"}</script>`,
+		}},
+		{"send", h.HandleSendPage, "/send", []string{"<title>Send Message - Friday</title>", `id="status-indicator"`}},
+		{"contacts", h.HandleContactsPage, "/contacts", []string{"<title>Contacts - Friday</title>", `id="status-indicator"`}},
+		{"groups", h.HandleGroupsPage, "/groups", []string{"<title>Contact Groups - Friday</title>", `id="status-indicator"`}},
+		{"group_detail", h.HandleGroupDetailPage, "/groups/7", []string{
+			"<title>Group Details - Friday</title>",
+			`<script type="application/json" id="page-data">{"id":7}</script>`,
+		}},
+		{"batch_runs", h.HandleBatchRunsPage, "/batch-runs", []string{"<title>Batch Runs - Friday</title>", `id="status-indicator"`}},
+		{"batch_detail", h.HandleBatchRunDetailPage, "/batch-runs/12", []string{
+			"<title>Batch Progress - Friday</title>",
+			`<script type="application/json" id="page-data">{"id":12}</script>`,
+		}},
+		{"weekly_report", h.HandleWeeklyReportPage, "/reports/weekly", []string{"<title>Weekly Report - Friday</title>", `id="status-indicator"`}},
+	}
+
+	for _, tt := range pages {
+		t.Run(tt.page, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "text/html" {
+				t.Errorf("Content-Type = %q, want text/html", ct)
+			}
+
+			body := rec.Body.String()
+			markers := append([]string{"<!DOCTYPE html>", "/static/css/tailwind.css?v=", "/static/js/auth.js?v=", "const I18N ="}, tt.markers...)
+			for _, marker := range markers {
+				if !strings.Contains(body, marker) {
+					t.Errorf("page lacks %q", marker)
+				}
+			}
+
+			if external := externalResource.FindAllString(body, -1); len(external) > 0 {
+				t.Errorf("page loads external resources: %q", external)
+			}
+
+			got := assetVersion.ReplaceAllString(body, "")
+			golden := filepath.Join("testdata", "pages", tt.page+".html")
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("rendered page differs from %s; run with -update if the change is intended", golden)
+			}
+		})
+	}
+}
+
+// TestPagesRejectMaliciousPaths requests the pages addressed by a path segment
+// with segments crafted to break out of the page's script; none may be echoed.
+func TestPagesRejectMaliciousPaths(t *testing.T) {
+	h := NewWebHandler(nil, nil, nil)
+	payloads := []string{
+		`'};alert(1);//`,
+		`</script><script>alert(1)</script>`,
+		"${alert(1)}",
+		`"-alert(1)-"`,
+	}
+	pages := []struct {
+		prefix  string
+		handler http.HandlerFunc
+		valid   string // A segment the page accepts, for payloads appended to it
+	}{
+		{"/contact/", h.HandleContactDetailPage, "905550000001@s.whatsapp.net"},
+		{"/groups/", h.HandleGroupDetailPage, "7"},
+		{"/batch-runs/", h.HandleBatchRunDetailPage, "12"},
+	}
+
+	for _, page := range pages {
+		for _, payload := range payloads {
+			for _, segment := range []string{payload, page.valid + payload, payload + page.valid} {
+				rec := httptest.NewRecorder()
+				page.handler(rec, httptest.NewRequest(http.MethodGet, page.prefix+url.PathEscape(segment), nil))
+				if rec.Code != http.StatusBadRequest {
+					t.Errorf("%s%s: status %d, want 400", page.prefix, segment, rec.Code)
+				}
+				if body := rec.Body.String(); strings.Contains(body, "alert(1)") {
+					t.Errorf("%s%s: payload appears in the response: %s", page.prefix, segment, body)
+				}
+				if location := rec.Header().Get("Location"); strings.Contains(location, "alert") {
+					t.Errorf("%s%s: payload appears in the redirect to %s", page.prefix, segment, location)
+				}
+			}
+		}
+	}
+}
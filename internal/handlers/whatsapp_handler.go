@@ -1,38 +1,141 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"friday/internal/i18n"
+	"friday/internal/models"
 	"friday/internal/whatsapp"
 )
 
 type WhatsAppHandler struct {
-	client *whatsapp.Client
+	client       *whatsapp.Client      // Pairing, the session, the account, polls, replies and reactions
+	messenger    whatsapp.Messenger    // Single sends; the fake when FRIDAY_FAKE_WHATSAPP is set
+	session      whatsapp.SessionState // Connection status; the fake when FRIDAY_FAKE_WHATSAPP is set
+	qrHandler    *QRHandler
+	outboxRepo   *models.OutboxRepository
+	settingsRepo *models.SettingsRepository
 }
 
-func NewWhatsAppHandler(client *whatsapp.Client) *WhatsAppHandler {
-	return &WhatsAppHandler{client: client}
+// NewWhatsAppHandler creates a WhatsApp handler. Status and single sends go
+// through session and messenger, which are client unless a fake stands in for it.
+func NewWhatsAppHandler(client *whatsapp.Client, messenger whatsapp.Messenger, session whatsapp.SessionState, qrHandler *QRHandler, outboxRepo *models.OutboxRepository, settingsRepo *models.SettingsRepository) *WhatsAppHandler {
+	return &WhatsAppHandler{client: client, messenger: messenger, session: session, qrHandler: qrHandler, outboxRepo: outboxRepo, settingsRepo: settingsRepo}
+}
+
+// RegisterCapabilities adds polls, which need no configuration.
+func (h *WhatsAppHandler) RegisterCapabilities(c *Capabilities) {
+	c.Register(CapabilityPolls, func() bool { return true })
 }
 
 type StatusResponse struct {
 	Connected  bool   `json:"connected"`
 	HasSession bool   `json:"has_session"`  // true if device was previously linked
 	Connecting bool   `json:"connecting"`   // true if websocket connected but not authenticated yet
+	State      string `json:"state"`        // disconnected, connecting, pairing or connected
+	ReadOnly   bool   `json:"read_only"`    // true while outgoing messages are frozen
+	OwnJID     string `json:"own_jid,omitempty"` // Linked account, see /api/whatsapp/me
 	Message    string `json:"message"`
+
+	LastKeepaliveAt      *time.Time `json:"last_keepalive_at"`    // Last successful presence keepalive, null if none
+	LastKeepaliveError   string     `json:"last_keepalive_error"` // Most recent keepalive failure, empty if none
+	LastKeepaliveErrorAt *time.Time `json:"last_keepalive_error_at"`
+	KeepaliveFailures    int        `json:"keepalive_failures"` // Consecutive failures; the connection is reset after 3
+}
+
+// MeResponse describes the linked account. Without a session it is a 404 with
+// only Success and Message set.
+type MeResponse struct {
+	Success      bool       `json:"success"`
+	Message      string     `json:"message"`
+	JID          string     `json:"jid,omitempty"`
+	DeviceJID    string     `json:"device_jid,omitempty"`
+	LID          string     `json:"lid,omitempty"`
+	Phone        string     `json:"phone,omitempty"`
+	PushName     string     `json:"push_name,omitempty"`
+	BusinessName string     `json:"business_name,omitempty"`
+	Platform     string     `json:"platform,omitempty"`
+	PairedAt     *time.Time `json:"paired_at,omitempty"` // Omitted when the session does not record it
+	Connected    bool       `json:"connected"`
+}
+
+// ClientEventsResponse is the body of GET /api/whatsapp/events.
+type ClientEventsResponse struct {
+	Success bool                   `json:"success"`
+	Message string                 `json:"message"`
+	Events  []whatsapp.ClientEvent `json:"events"` // Newest first
+	Count   int                    `json:"count"`
+}
+
+type BlocklistResponse struct {
+	Success  bool               `json:"success"`
+	Message  string             `json:"message"`
+	Contacts []whatsapp.Contact `json:"contacts"`
+	Count    int                `json:"count"`
+}
+
+type ConnectResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	State   string      `json:"state"`
+	QR      *QRResponse `json:"qr,omitempty"` // Current pairing attempt, while pairing
+}
+
+type DisconnectResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
 }
 
 type SendMessageRequest struct {
 	Phone     string `json:"phone,omitempty"` // deprecated, use recipient
 	Recipient string `json:"recipient"`
 	Message   string `json:"message"`
+
+	// While WhatsApp is disconnected, queue the message in the outbox instead
+	// of failing; the response is then 202 with outbox_id
+	QueueIfDisconnected bool `json:"queue_if_disconnected"`
+
+	// Send to the linked account's own chat instead of answering 409
+	AllowSelf bool `json:"allow_self"`
 }
 
 type SendMessageResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	ID      string `json:"id,omitempty"`
+	Success  bool   `json:"success"`
+	Message  string `json:"message"`
+	ID       string `json:"id,omitempty"`
+	OutboxID int64  `json:"outbox_id,omitempty"` // Set when the message was queued instead of sent
+}
+
+// ReplyRequest sends a message quoting an earlier one. Friday does not store
+// incoming messages, so the caller supplies the quoted message's details.
+type ReplyRequest struct {
+	Recipient       string `json:"recipient"`
+	Message         string `json:"message"`
+	QuotedMessageID string `json:"quoted_message_id"`
+	QuotedSender    string `json:"quoted_sender,omitempty"` // JID of the quoted message's author; defaults to the recipient
+	QuotedText      string `json:"quoted_text,omitempty"`   // Shown in the quoted bubble
+	AllowSelf       bool   `json:"allow_self"`              // Reply in the linked account's own chat instead of answering 409
+}
+
+// ReactionRequest reacts to a message with an emoji. Friday does not store
+// incoming messages, so the caller supplies the target message's details.
+type ReactionRequest struct {
+	Chat            string `json:"chat"`                    // Phone number, contact name, or a contact or group JID
+	TargetMessageID string `json:"target_message_id"`
+	TargetSender    string `json:"target_sender,omitempty"` // JID of the target message's author; defaults to the chat, so required in groups
+	Emoji           string `json:"emoji"`                   // A single emoji; empty removes an earlier reaction
+}
+
+// PollRequest sends a WhatsApp poll. Votes are not collected.
+type PollRequest struct {
+	Recipient   string   `json:"recipient"` // Phone number, contact name, or a contact or group JID
+	Question    string   `json:"question"`
+	Options     []string `json:"options"`      // 2 to 12, each different
+	MultiSelect bool     `json:"multi_select"` // Voters may pick several options instead of one
+	AllowSelf   bool     `json:"allow_self"`   // Send to the linked account's own chat instead of answering 409
 }
 
 func (h *WhatsAppHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
@@ -41,15 +144,28 @@ func (h *WhatsAppHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	connected := h.client.IsConnected()
-	hasSession := h.client.HasSession()
-	connecting := h.client.IsConnecting()
+	connected := h.session.IsConnected()
+	hasSession := h.session.HasSession()
+	connecting := h.session.IsConnecting()
 
 	response := StatusResponse{
 		Connected:  connected,
 		HasSession: hasSession,
 		Connecting: connecting,
-		Message:    "WhatsApp client connected",
+		State:      string(h.session.State()),
+		ReadOnly:   h.session.IsReadOnly(),
+		OwnJID:     h.messenger.OwnJID(),
+		Message:    tr(r, "whatsapp_connected"),
+	}
+
+	keepalive := h.client.Keepalive()
+	response.LastKeepaliveError = keepalive.LastError
+	response.KeepaliveFailures = keepalive.Failures
+	if !keepalive.LastAt.IsZero() {
+		response.LastKeepaliveAt = &keepalive.LastAt
+	}
+	if !keepalive.LastErrorAt.IsZero() {
+		response.LastKeepaliveErrorAt = &keepalive.LastErrorAt
 	}
 
 	if !connected {
@@ -62,11 +178,99 @@ func (h *WhatsAppHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// HandleEvents handles GET /api/whatsapp/events, the client's log of recent
+// pairing, connection and send events, for debugging without shell access.
+// ?since= returns only events after that time.
+func (h *WhatsAppHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			jsonError(w, tr(r, "client_events_invalid_since"), http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	events := h.client.Events(since)
+	writeJSON(w, http.StatusOK, ClientEventsResponse{
+		Success: true,
+		Message: tr(r, "client_events_retrieved"),
+		Events:  events,
+		Count:   len(events),
+	})
+}
+
+// HandleBlocklist handles GET /api/whatsapp/blocklist, the contacts the linked
+// account has blocked. The list is cached for a few minutes and kept current by
+// block events; ?refresh=true fetches it from WhatsApp again.
+func (h *WhatsAppHandler) HandleBlocklist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.client.IsConnected() {
+		jsonError(w, tr(r, "whatsapp_not_connected"), http.StatusBadRequest)
+		return
+	}
+
+	contacts, err := h.client.GetBlockedContacts(r.Context(), r.URL.Query().Get("refresh") == "true")
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get blocklist: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BlocklistResponse{
+		Success:  true,
+		Message:  tr(r, "blocklist_retrieved"),
+		Contacts: contacts,
+		Count:    len(contacts),
+	})
+}
+
+// HandleMe handles GET /api/whatsapp/me, identifying the linked account so a
+// wrongly paired phone is easy to spot.
+func (h *WhatsAppHandler) HandleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	account := h.client.Account()
+	if account == nil {
+		writeJSON(w, http.StatusNotFound, MeResponse{
+			Success: false,
+			Message: tr(r, "no_session"),
+		})
 		return
 	}
+
+	response := MeResponse{
+		Success:      true,
+		Message:      tr(r, "account_retrieved"),
+		JID:          account.JID,
+		DeviceJID:    account.DeviceJID,
+		LID:          account.LID,
+		Phone:        account.Phone,
+		PushName:     account.PushName,
+		BusinessName: account.BusinessName,
+		Platform:     account.Platform,
+		Connected:    h.client.IsConnected(),
+	}
+	if !account.PairedAt.IsZero() {
+		response.PairedAt = &account.PairedAt
+	}
+
+	writeJSON(w, http.StatusOK, response)
 }
 
 func (h *WhatsAppHandler) HandleConnect(w http.ResponseWriter, r *http.Request) {
@@ -75,31 +279,45 @@ func (h *WhatsAppHandler) HandleConnect(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if h.client.IsConnected() {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": true,
-			"message": "Already connected to WhatsApp",
-		})
+	// Connecting again while an attempt is in progress would restart pairing and
+	// invalidate the QR code other tabs are showing, so report the attempt instead.
+	switch state := h.client.State(); state {
+	case whatsapp.StateConnected:
+		h.writeConnectResponse(w, r, state, "Already connected to WhatsApp")
+		return
+	case whatsapp.StatePairing:
+		h.writeConnectResponse(w, r, state, "Pairing already in progress")
+		return
+	case whatsapp.StateConnecting:
+		h.writeConnectResponse(w, r, state, "Session restore already in progress")
 		return
 	}
 
 	err := h.client.Connect()
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"message": fmt.Sprintf("Failed to connect: %v", err),
+		writeJSON(w, http.StatusInternalServerError, ConnectResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to connect: %v", err),
+			State:   string(h.client.State()),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"message": "Connection initiated - check logs for QR code if needed",
-	})
+	h.writeConnectResponse(w, r, h.client.State(), "Connection initiated - check logs for QR code if needed")
+}
+
+func (h *WhatsAppHandler) writeConnectResponse(w http.ResponseWriter, r *http.Request, state whatsapp.ConnectionState, message string) {
+	response := ConnectResponse{
+		Success: true,
+		Message: message,
+		State:   string(state),
+	}
+	if state == whatsapp.StatePairing {
+		qr := h.qrHandler.Snapshot(i18n.FromContext(r.Context()))
+		response.QR = &qr
+	}
+
+	writeJSON(w, http.StatusOK, response)
 }
 
 // HandleDisconnect clears the WhatsApp session and disconnects the client.
@@ -112,19 +330,16 @@ func (h *WhatsAppHandler) HandleDisconnect(w http.ResponseWriter, r *http.Reques
 
 	// ClearSession disconnects and removes the stored session
 	if err := h.client.ClearSession(); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"message": fmt.Sprintf("Failed to disconnect: %v", err),
+		writeJSON(w, http.StatusInternalServerError, DisconnectResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to disconnect: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"message": "WhatsApp disconnected and session cleared",
+	writeJSON(w, http.StatusOK, DisconnectResponse{
+		Success: true,
+		Message: tr(r, "whatsapp_disconnected_cleared"),
 	})
 }
 
@@ -134,23 +349,21 @@ func (h *WhatsAppHandler) HandleSendMessage(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if !h.client.IsConnected() {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(SendMessageResponse{
-			Success: false,
-			Message: "WhatsApp client not connected",
-		})
+	if h.messenger.IsReadOnly() {
+		jsonError(w, readOnlyMessage, http.StatusServiceUnavailable)
 		return
 	}
 
 	var req SendMessageRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(SendMessageResponse{
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	connected := h.messenger.IsConnected()
+	if !connected && !req.QueueIfDisconnected {
+		writeJSON(w, http.StatusBadRequest, SendMessageResponse{
 			Success: false,
-			Message: fmt.Sprintf("Invalid JSON in request body: %v", err),
+			Message: tr(r, "whatsapp_not_connected"),
 		})
 		return
 	}
@@ -162,50 +375,244 @@ func (h *WhatsAppHandler) HandleSendMessage(w http.ResponseWriter, r *http.Reque
 	}
 
 	if recipient == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(SendMessageResponse{
+		writeJSON(w, http.StatusBadRequest, SendMessageResponse{
 			Success: false,
-			Message: "Recipient (phone number or contact name) is required",
+			Message: tr(r, "recipient_required"),
 		})
 		return
 	}
 
 	if req.Message == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(SendMessageResponse{
+		writeJSON(w, http.StatusBadRequest, SendMessageResponse{
 			Success: false,
-			Message: "Message content is required",
+			Message: tr(r, "message_content_required"),
 		})
 		return
 	}
 
-	jid, err := h.client.ResolveRecipient(recipient)
+	jid, err := h.messenger.ResolveRecipient(recipient)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(SendMessageResponse{
+		writeJSON(w, http.StatusBadRequest, SendMessageResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to resolve recipient '%s': %v", recipient, err),
 		})
 		return
 	}
+	if !req.AllowSelf && isSelf(h.messenger, jid) {
+		jsonError(w, tr(r, "recipient_is_self"), http.StatusConflict)
+		return
+	}
 
-	err = h.client.SendMessage(r.Context(), jid, req.Message)
+	if !connected {
+		item, ok := queueOutbox(w, h.outboxRepo, h.settingsRepo, jid, req.Message)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusAccepted, SendMessageResponse{
+			Success:  true,
+			Message:  tr(r, "message_queued_outbox", item.ID),
+			OutboxID: item.ID,
+		})
+		return
+	}
+
+	_, err = h.messenger.SendMessage(r.Context(), jid, req.Message)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(SendMessageResponse{
+		writeJSON(w, http.StatusInternalServerError, SendMessageResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to send message: %v", err),
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(SendMessageResponse{
+	writeJSON(w, http.StatusOK, SendMessageResponse{
+		Success: true,
+		Message: tr(r, "message_sent"),
+	})
+}
+
+// HandleReply handles POST /api/whatsapp/reply, sending a message that quotes an
+// earlier one and returning the new message's ID. Unlike /api/whatsapp/send it
+// never queues while disconnected.
+// HandlePoll handles POST /api/whatsapp/poll, sending a poll to a contact or
+// group and returning the poll message's id.
+func (h *WhatsAppHandler) HandlePoll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.client.IsReadOnly() {
+		jsonError(w, readOnlyMessage, http.StatusServiceUnavailable)
+		return
+	}
+
+	var req PollRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Recipient == "" {
+		jsonError(w, tr(r, "recipient_required"), http.StatusBadRequest)
+		return
+	}
+	if err := whatsapp.ValidatePoll(req.Question, req.Options); err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.client.IsConnected() {
+		jsonError(w, tr(r, "whatsapp_not_connected"), http.StatusBadRequest)
+		return
+	}
+
+	jid, err := h.client.ResolveRecipient(req.Recipient)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to resolve recipient '%s': %v", req.Recipient, err), http.StatusBadRequest)
+		return
+	}
+	if !req.AllowSelf && isSelf(h.client, jid) {
+		jsonError(w, tr(r, "recipient_is_self"), http.StatusConflict)
+		return
+	}
+
+	id, err := h.client.SendPoll(r.Context(), jid, req.Question, req.Options, req.MultiSelect)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to send poll: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SendMessageResponse{
+		Success: true,
+		Message: tr(r, "poll_sent"),
+		ID:      id,
+	})
+}
+
+// HandleReaction handles POST /api/whatsapp/react, reacting to a message with
+// an emoji, or removing a reaction with an empty one, and returning the
+// reaction message's ID. Reactions are never queued while disconnected.
+func (h *WhatsAppHandler) HandleReaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.client.IsReadOnly() {
+		jsonError(w, readOnlyMessage, http.StatusServiceUnavailable)
+		return
+	}
+
+	var req ReactionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Chat == "" {
+		jsonError(w, tr(r, "chat_required"), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.TargetMessageID) == "" {
+		jsonError(w, tr(r, "target_message_id_required"), http.StatusBadRequest)
+		return
+	}
+	if err := whatsapp.ValidateReaction(req.Emoji); err != nil {
+		jsonError(w, tr(r, "reaction_invalid"), http.StatusBadRequest)
+		return
+	}
+	if !h.client.IsConnected() {
+		jsonError(w, tr(r, "whatsapp_not_connected"), http.StatusBadRequest)
+		return
+	}
+
+	jid, err := h.client.ResolveRecipient(req.Chat)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to resolve chat '%s': %v", req.Chat, err), http.StatusBadRequest)
+		return
+	}
+	targetSender := ""
+	if req.TargetSender != "" {
+		var ok bool
+		if targetSender, ok = requireJID(w, req.TargetSender); !ok {
+			return
+		}
+	}
+
+	id, err := h.client.SendReaction(r.Context(), jid, strings.TrimSpace(req.TargetMessageID), targetSender, req.Emoji)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to send reaction: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	message := tr(r, "reaction_sent")
+	if req.Emoji == "" {
+		message = tr(r, "reaction_removed")
+	}
+	writeJSON(w, http.StatusOK, SendMessageResponse{
+		Success: true,
+		Message: message,
+		ID:      id,
+	})
+}
+
+func (h *WhatsAppHandler) HandleReply(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.client.IsReadOnly() {
+		jsonError(w, readOnlyMessage, http.StatusServiceUnavailable)
+		return
+	}
+
+	var req ReplyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !h.client.IsConnected() {
+		jsonError(w, tr(r, "whatsapp_not_connected"), http.StatusBadRequest)
+		return
+	}
+	if req.Recipient == "" {
+		jsonError(w, tr(r, "recipient_required"), http.StatusBadRequest)
+		return
+	}
+	if req.Message == "" {
+		jsonError(w, tr(r, "message_content_required"), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.QuotedMessageID) == "" {
+		jsonError(w, tr(r, "quoted_message_id_required"), http.StatusBadRequest)
+		return
+	}
+
+	jid, err := h.client.ResolveRecipient(req.Recipient)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to resolve recipient '%s': %v", req.Recipient, err), http.StatusBadRequest)
+		return
+	}
+	if !req.AllowSelf && isSelf(h.client, jid) {
+		jsonError(w, tr(r, "recipient_is_self"), http.StatusConflict)
+		return
+	}
+	quotedSender := ""
+	if req.QuotedSender != "" {
+		var ok bool
+		if quotedSender, ok = requireJID(w, req.QuotedSender); !ok {
+			return
+		}
+	}
+
+	id, err := h.client.SendReply(r.Context(), jid, req.Message, strings.TrimSpace(req.QuotedMessageID), quotedSender, req.QuotedText)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to send reply: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SendMessageResponse{
 		Success: true,
-		Message: "Message sent successfully",
+		Message: tr(r, "reply_sent"),
+		ID:      id,
 	})
 }
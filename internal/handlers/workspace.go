@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"friday/internal/models"
+)
+
+// WorkspaceHeader selects the workspace of a request, by ID or name; the
+// workspace query parameter does the same for links and the web pages.
+// Without either the default workspace is used.
+const WorkspaceHeader = "X-Workspace"
+
+type workspaceKey struct{}
+
+// workspaceID returns the workspace selected for the request.
+func workspaceID(r *http.Request) int64 {
+	if id, ok := r.Context().Value(workspaceKey{}).(int64); ok {
+		return id
+	}
+	return models.DefaultWorkspaceID
+}
+
+// WorkspaceHandler manages workspaces and resolves the one each request works in.
+type WorkspaceHandler struct {
+	repo      *models.WorkspaceRepository
+	draftRepo *models.DraftRepository
+	groupRepo *models.GroupRepository
+}
+
+// NewWorkspaceHandler creates a new workspace handler.
+func NewWorkspaceHandler(repo *models.WorkspaceRepository, draftRepo *models.DraftRepository, groupRepo *models.GroupRepository) *WorkspaceHandler {
+	return &WorkspaceHandler{repo: repo, draftRepo: draftRepo, groupRepo: groupRepo}
+}
+
+// Wrap stores the workspace selected by WorkspaceHeader or the workspace
+// query parameter in the request context for workspaceID. A workspace that
+// does not exist is a 404 rather than a silent fall back to the default.
+func (h *WorkspaceHandler) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		selector := strings.TrimSpace(r.Header.Get(WorkspaceHeader))
+		if selector == "" {
+			selector = strings.TrimSpace(r.URL.Query().Get("workspace"))
+		}
+		if selector == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		workspace, err := h.lookup(selector)
+		if err != nil {
+			jsonError(w, fmt.Sprintf("Failed to get workspace: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if workspace == nil {
+			jsonError(w, tr(r, "workspace_not_found", selector), http.StatusNotFound)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), workspaceKey{}, workspace.ID)))
+	})
+}
+
+// lookup finds a workspace by ID or, failing that, by name.
+func (h *WorkspaceHandler) lookup(selector string) (*models.Workspace, error) {
+	if id, err := strconv.ParseInt(selector, 10, 64); err == nil {
+		return h.repo.GetByID(id)
+	}
+	return h.repo.GetByName(selector)
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+type WorkspaceResponse struct {
+	Success   bool              `json:"success"`
+	Message   string            `json:"message"`
+	Workspace *models.Workspace `json:"workspace,omitempty"`
+}
+
+type WorkspaceListResponse struct {
+	Success    bool               `json:"success"`
+	Message    string             `json:"message"`
+	Workspaces []models.Workspace `json:"workspaces"`
+	Current    int64              `json:"current"` // The workspace this request was made in
+}
+
+// MoveRequest is the body of POST /api/drafts/{id}/move and /api/groups/{id}/move.
+type MoveRequest struct {
+	WorkspaceID int64 `json:"workspace_id"`
+}
+
+// HandleWorkspaces handles GET /api/workspaces (list) and POST /api/workspaces (create).
+func (h *WorkspaceHandler) HandleWorkspaces(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.listWorkspaces(w, r)
+	case http.MethodPost:
+		h.createWorkspace(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *WorkspaceHandler) listWorkspaces(w http.ResponseWriter, r *http.Request) {
+	workspaces, err := h.repo.GetAll()
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get workspaces: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, WorkspaceListResponse{
+		Success:    true,
+		Message:    tr(r, "workspaces_found", len(workspaces)),
+		Workspaces: workspaces,
+		Current:    workspaceID(r),
+	})
+}
+
+func (h *WorkspaceHandler) createWorkspace(w http.ResponseWriter, r *http.Request) {
+	var req CreateWorkspaceRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		jsonError(w, tr(r, "workspace_name_required"), http.StatusBadRequest)
+		return
+	}
+	// Selectors that parse as a number are taken as IDs
+	if _, err := strconv.ParseInt(name, 10, 64); err == nil {
+		jsonError(w, tr(r, "workspace_name_numeric"), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.repo.GetByName(name)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check workspace name: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		jsonError(w, tr(r, "workspace_name_taken"), http.StatusConflict)
+		return
+	}
+
+	workspace := &models.Workspace{Name: name}
+	if err := h.repo.Create(workspace); err != nil {
+		jsonError(w, fmt.Sprintf("Failed to create workspace: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, WorkspaceResponse{
+		Success:   true,
+		Message:   tr(r, "workspace_created"),
+		Workspace: workspace,
+	})
+}
+
+// HandleMoveDraft handles POST /api/drafts/{id}/move, moving a draft of the
+// request's workspace to another one.
+func (h *WorkspaceHandler) HandleMoveDraft(w http.ResponseWriter, r *http.Request) {
+	id, target, ok := h.moveRequest(w, r, "/api/drafts/", "invalid_draft_id")
+	if !ok {
+		return
+	}
+
+	draft, err := h.draftRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if draft == nil {
+		jsonError(w, tr(r, "draft_not_found"), http.StatusNotFound)
+		return
+	}
+
+	if _, err := h.draftRepo.MoveToWorkspace(id, target.ID); err != nil {
+		jsonError(w, fmt.Sprintf("Failed to move draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if draft, err = h.draftRepo.GetByID(id); err != nil || draft == nil {
+		jsonError(w, fmt.Sprintf("Failed to get draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DraftResponse{
+		Success: true,
+		Message: tr(r, "draft_moved", target.Name),
+		Draft:   draft,
+	})
+}
+
+// HandleMoveGroup handles POST /api/groups/{id}/move, moving a group of the
+// request's workspace, with its members, to another one.
+func (h *WorkspaceHandler) HandleMoveGroup(w http.ResponseWriter, r *http.Request) {
+	id, target, ok := h.moveRequest(w, r, "/api/groups/", "invalid_group_id")
+	if !ok {
+		return
+	}
+
+	group, err := h.groupRepo.GetInWorkspace(workspaceID(r), id)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		jsonError(w, tr(r, "group_not_found"), http.StatusNotFound)
+		return
+	}
+
+	// Names are unique within a workspace, so the target must not have one like it
+	existing, err := h.groupRepo.GetByName(target.ID, group.Name)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to check group name: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if existing != nil && existing.ID != id {
+		jsonError(w, tr(r, "group_name_taken"), http.StatusConflict)
+		return
+	}
+
+	if _, err := h.groupRepo.MoveToWorkspace(id, target.ID); err != nil {
+		jsonError(w, fmt.Sprintf("Failed to move group: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if group, err = h.groupRepo.GetByID(id); err != nil || group == nil {
+		jsonError(w, fmt.Sprintf("Failed to get group: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, GroupResponse{
+		Success: true,
+		Message: tr(r, "group_moved", target.Name),
+		Group:   group,
+	})
+}
+
+// moveRequest parses the ID from /{prefix}{id}/move and the target workspace
+// from the body, writing the error response when either is invalid.
+func (h *WorkspaceHandler) moveRequest(w http.ResponseWriter, r *http.Request, prefix, invalidIDKey string) (int64, *models.Workspace, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return 0, nil, false
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, prefix), "/move"), 10, 64)
+	if err != nil {
+		jsonError(w, tr(r, invalidIDKey), http.StatusBadRequest)
+		return 0, nil, false
+	}
+
+	var req MoveRequest
+	if !decodeJSON(w, r, &req) {
+		return 0, nil, false
+	}
+	if req.WorkspaceID <= 0 {
+		jsonError(w, tr(r, "workspace_id_required"), http.StatusBadRequest)
+		return 0, nil, false
+	}
+
+	target, err := h.repo.GetByID(req.WorkspaceID)
+	if err != nil {
+		jsonError(w, fmt.Sprintf("Failed to get workspace: %v", err), http.StatusInternalServerError)
+		return 0, nil, false
+	}
+	if target == nil {
+		jsonError(w, tr(r, "workspace_not_found", strconv.FormatInt(req.WorkspaceID, 10)), http.StatusNotFound)
+		return 0, nil, false
+	}
+
+	return id, target, true
+}
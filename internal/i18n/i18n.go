@@ -0,0 +1,122 @@
+// Package i18n translates the user-facing messages of API responses. Messages
+// are looked up by ID in a catalog per language; an ID missing from a language
+// falls back to English. Field names, status values and other machine-read
+// strings are never translated.
+//
+// The web pages translate their own text in the browser (see the i18n partial);
+// they store the chosen language in the friday-lang cookie so API messages shown
+// on a page come back in the same language.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported languages.
+const (
+	English = "en"
+	Turkish = "tr"
+
+	// Default is used when the request names no supported language.
+	Default = English
+)
+
+// CookieName holds the language picked in the web UI.
+const CookieName = "friday-lang"
+
+type contextKey struct{}
+
+// WithLanguage returns a copy of ctx carrying lang.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext returns the language stored by WithLanguage, or Default.
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok {
+		return lang
+	}
+	return Default
+}
+
+// Supported reports whether lang has a catalog.
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Negotiate picks the response language of a request: the lang query parameter,
+// then the friday-lang cookie, then the best supported Accept-Language entry.
+// Unsupported values are skipped; with none left it returns Default.
+func Negotiate(r *http.Request) string {
+	if lang := primaryTag(r.URL.Query().Get("lang")); Supported(lang) {
+		return lang
+	}
+	if cookie, err := r.Cookie(CookieName); err == nil {
+		if lang := primaryTag(cookie.Value); Supported(lang) {
+			return lang
+		}
+	}
+	return parseAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// parseAcceptLanguage returns the supported language with the highest quality
+// in an Accept-Language header, e.g. "tr-TR,tr;q=0.9,en;q=0.8". Ties keep the
+// header's order.
+func parseAcceptLanguage(header string) string {
+	type candidate struct {
+		lang    string
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		lang := primaryTag(tag)
+		if !Supported(lang) {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{lang, quality})
+		}
+	}
+	if len(candidates) == 0 {
+		return Default
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+	return candidates[0].lang
+}
+
+// primaryTag lowercases a language tag and drops its region, so "tr-TR" is "tr".
+func primaryTag(tag string) string {
+	tag, _, _ = strings.Cut(strings.TrimSpace(tag), "-")
+	return strings.ToLower(tag)
+}
+
+// T returns the message with the given ID in lang, formatted with args as by
+// fmt.Sprintf. Missing translations fall back to English; an unknown ID is
+// returned as is.
+func T(lang, id string, args ...interface{}) string {
+	format, ok := catalogs[lang][id]
+	if !ok {
+		if format, ok = catalogs[English][id]; !ok {
+			format = id
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
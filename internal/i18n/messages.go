@@ -0,0 +1,520 @@
+package i18n
+
+// catalogs maps a language to its messages by ID. Every ID needs an entry in
+// every language, as TestCatalogsComplete checks; T falls back to English for
+// one that is missing. Messages with arguments use fmt verbs, in the same order
+// in every language.
+var catalogs = map[string]map[string]string{
+	English: {
+		// Common
+		"not_found":                     "Not found",
+		"invalid_path":                  "Invalid path",
+		"invalid_jid_encoding":          "Invalid JID encoding",
+		"failed_to_read_body":           "Failed to read request body",
+		"request_body_too_large_mb":     "Request body is too large (limit %d MB)",
+		"request_body_too_large_kb":     "Request body is too large (limit %d KB)",
+		"request_body_empty":            "Request body is empty, expected a JSON object",
+		"json_incomplete":               "Invalid JSON: body ends before the value is complete",
+		"json_trailing_data":            "Invalid JSON: unexpected data after the JSON value",
+		"rate_limited":                  "Too many requests, retry after %ds",
+		"whatsapp_not_connected":        "WhatsApp client not connected",
+		"whatsapp_connected":            "WhatsApp client connected",
+		"whatsapp_disconnected_cleared": "WhatsApp disconnected and session cleared",
+		"no_session":                    "No session - QR code scan required",
+		"qr_ready":                      "QR code ready for scanning",
+		"qr_not_available":              "No QR code available. Try connecting to WhatsApp first.",
+		"qr_cleared":                    "QR code cleared",
+		"qr_image_failed":               "Failed to generate QR code image",
+		"qr_bad_format":                 "format must be png or svg",
+		"qr_bad_json_format":            "format must be base64, or left out for the code without an image",
+		"account_retrieved":             "Linked account retrieved successfully",
+		"client_events_retrieved":       "Client events retrieved successfully",
+		"client_events_invalid_since":   "since must be an RFC 3339 time, e.g. 2025-03-01T12:00:00Z",
+		"routes_retrieved":              "Routes retrieved successfully",
+		"unknown_endpoint":              "Unknown endpoint",
+
+		// Idempotency
+		"idempotency_in_progress":  "A request with this Idempotency-Key is still being processed",
+		"idempotency_check_failed": "Failed to check Idempotency-Key",
+		"idempotency_too_long":     "Idempotency-Key is too long",
+		"idempotency_reused":       "Idempotency-Key was already used for a different request",
+
+		// Messages
+		"message_content_required":   "Message content is required",
+		"recipient_required":         "Recipient (phone number or contact name) is required",
+		"message_sent":               "Message sent successfully",
+		"reply_sent":                 "Reply sent successfully",
+		"reaction_sent":              "Reaction sent successfully",
+		"reaction_removed":           "Reaction removed successfully",
+		"reaction_invalid":           "emoji must be a single emoji, or empty to remove a reaction",
+		"chat_required":              "Chat (phone number, contact name or JID) is required",
+		"target_message_id_required": "target_message_id is required",
+		"poll_sent":                  "Poll sent successfully",
+		"capabilities_retrieved":     "Capabilities retrieved",
+		"blocklist_retrieved":        "Blocklist retrieved",
+		"batch_all_blocked":          "All %d group members are quarantined or blocked",
+		"batch_all_capped":           "All %d recipients were already sent batch.recipient_cap messages in the last %d days",
+		"batch_trigger_disabled":     "Batch trigger is disabled: set %s first",
+		"quoted_message_id_required": "quoted_message_id is required",
+		"phone_numbers_required":     "At least one phone number is required",
+		"phone_validation_done":      "Phone validation completed successfully",
+		"message_queued_outbox":      "WhatsApp is not connected; message queued as outbox #%d and sent once it reconnects",
+
+		// Outbox
+		"outbox_retrieved":      "Outbox retrieved successfully",
+		"outbox_item_retrieved": "Outbox message retrieved successfully",
+		"outbox_deleted":        "Outbox message deleted",
+		"outbox_not_found":      "Outbox message not found",
+		"outbox_invalid_id":     "Invalid outbox message ID",
+		"outbox_invalid_status": "status must be queued, sent, failed or expired",
+
+		// Contacts
+		"contacts_retrieved":            "Contacts retrieved successfully",
+		"contact_retrieved":             "Contact retrieved successfully",
+		"contact_not_found":             "Contact not found",
+		"contact_not_found_offline":     "Contact not found; WhatsApp is not connected, so only local data was checked",
+		"suspected_blocked_found":       "Found %d contacts whose recent messages were not delivered",
+		"contacts_synced":               "Synced %d contacts: %d added, %d removed, %d renamed",
+		"contacts_sync_retrieved":       "Last contact sync retrieved successfully",
+		"contacts_never_synced":         "Contacts have not been synced yet",
+		"contact_changes_retrieved":     "Contact changes: %d added, %d removed, %d renamed",
+		"contact_changes_invalid_since": "since must be a sync ID or an RFC 3339 time, e.g. 2025-03-01T12:00:00Z",
+		"contact_changes_none_added":    "No contacts were added since then",
+		"suspected_blocked_invalid_min": "min must be a positive integer",
+		"contact_search_done":           "Contact search completed successfully",
+		"contact_jid_required":          "Contact JID is required",
+		"recipient_is_self":             "The recipient is the linked account, so the message would go to your own chat; set allow_self to send it anyway",
+		"batch_only_self":               "The only group member to message is the linked account; set allow_self to message yourself",
+		"jids_required":                 "At least one JID is required",
+		"merge_jids_required":           "primary_jid and duplicate_jid are required",
+		"merge_same_contact":            "primary_jid and duplicate_jid are the same contact",
+		"note_saved":                    "Note saved successfully",
+		"note_cleared":                  "Note cleared",
+		"contacts_merged":               "Merged %s into %s",
+		"quarantined_found":             "Found %d quarantined contacts",
+
+		// Attributes
+		"attribute_key_required":          "Attribute key is required",
+		"attribute_key_required_deletion": "Attribute key required for deletion",
+		"attribute_key_invalid":           "Attribute key must contain only letters, numbers, and underscores",
+		"attribute_value_required":        "Attribute value is required",
+		"attribute_not_found":             "Attribute not found",
+		"attribute_saved":                 "Attribute saved successfully",
+		"attribute_deleted":               "Attribute deleted successfully",
+		"attributes_cleared":              "%d attribute(s) deleted",
+		"attributes_clear_confirm":        "Deleting all attributes of a contact requires ?confirm=true; to delete one, add its key to the path",
+		"attributes_retrieved":            "Attributes retrieved successfully",
+		"attribute_keys_retrieved":        "Attribute keys retrieved successfully",
+		"placeholder_default_saved":       "Placeholder default saved successfully",
+		"placeholder_default_deleted":     "Placeholder default deleted successfully",
+		"attributes_found":                "Found %d attributes",
+		"attribute_changes_found":         "Found %d attribute changes",
+		"placeholder_defaults_found":      "Found %d placeholder defaults",
+
+		// Drafts
+		"invalid_draft_id":          "Invalid draft ID",
+		"draft_not_found":           "Draft not found",
+		"title_required":            "Title is required",
+		"content_required":          "Content is required",
+		"template_has_errors":       "Template has errors",
+		"invalid_delimiters":        "Invalid placeholder delimiters: %v",
+		"draft_in_use":              "Draft is used by a queued or running batch",
+		"draft_has_batches":         "Batches were created from this draft; delete them first",
+		"draft_created":             "Draft created successfully",
+		"draft_duplicated":          "Draft duplicated successfully",
+		"draft_updated":             "Draft updated successfully",
+		"draft_deleted":             "Draft deleted successfully",
+		"draft_retrieved":           "Draft retrieved successfully",
+		"drafts_retrieved":          "Drafts retrieved successfully",
+		"draft_stats_retrieved":     "Draft stats retrieved successfully",
+		"preview_generated":         "Preview generated successfully",
+		"invalid_import_strategy":   "Invalid strategy (use skip, overwrite-by-title or duplicate)",
+		"invalid_variant_id":        "Invalid variant ID",
+		"variant_not_found":         "Variant not found",
+		"variant_selector_required": "Selector key and value are required",
+		"variant_saved":             "Variant saved successfully",
+		"variant_deleted":           "Variant deleted successfully",
+		"variants_retrieved":        "Variants retrieved successfully",
+		"drafts_imported":           "Imported %d of %d drafts",
+
+		// Groups
+		"invalid_group_id":            "Invalid group ID",
+		"invalid_group_id_param":      "Invalid group_id",
+		"invalid_not_in_group_id":     "Invalid not_in_group ID",
+		"group_not_found":             "Group not found",
+		"group_name_required":         "Group name is required",
+		"group_name_taken":            "A group with this name already exists",
+		"group_in_use":                "Group is used by a queued or running batch",
+		"group_has_batches":           "Batches were created from this group; delete them first",
+		"group_no_members":            "Group has no members",
+		"group_no_default_draft":      "Group has no default draft; set one or pass draft_id",
+		"group_created":               "Group created successfully",
+		"group_combined":              "Group created with %d members",
+		"group_combined_empty":        "Group created, but the operation left no members",
+		"combine_invalid_operation":   "operation must be union, intersection or difference",
+		"combine_two_groups_required": "group_ids must name at least two groups",
+		"combine_duplicate_group":     "Group %d is listed more than once",
+		"combine_group_not_found":     "Group %d not found",
+		"group_moved":                 "Group moved to workspace %s",
+		"draft_moved":                 "Draft moved to workspace %s",
+
+		// Workspaces
+		"workspace_not_found":     "Workspace %s not found",
+		"workspaces_found":        "Found %d workspaces",
+		"workspace_created":       "Workspace created",
+		"workspace_name_required": "Workspace name is required",
+		"workspace_name_numeric":  "Workspace name cannot be a number; numbers select workspaces by ID",
+		"workspace_name_taken":    "A workspace with this name already exists",
+		"workspace_id_required":   "workspace_id is required",
+		"group_updated":           "Group updated successfully",
+		"group_deleted":           "Group deleted successfully",
+		"group_retrieved":         "Group retrieved successfully",
+		"groups_retrieved":        "Groups retrieved successfully",
+		"member_jid_required":     "Member JID required for deletion",
+		"member_not_found":        "Member not found in group",
+		"member_removed":          "Member removed successfully",
+		"members_retrieved":       "Members retrieved successfully",
+		"members_reordered":       "Member order saved",
+		"members_moved":           "Moved %d members, %d were not in the group",
+		"members_move_same_group": "Members cannot be moved to the group they are in",
+		"members_order_unknown":   "Not members of this group: %s",
+		"members_added":           "Added %d members to group",
+
+		// Batches
+		"invalid_batch_id":                 "Invalid batch ID",
+		"invalid_message_id":               "Invalid message ID",
+		"batch_not_found":                  "Batch not found",
+		"progress_retrieved":               "Progress retrieved",
+		"preflight_done":                   "Preflight flagged %d of %d members",
+		"preflight_invalid_recent_hours":   "recent_hours cannot be negative",
+		"progress_invalid_wait":            "wait must be a number of seconds from 0 to %d",
+		"batch_invalid_sort":               "sort must be one of %s",
+		"batch_not_found_or_running":       "Batch not found or currently running",
+		"batch_not_interrupted":            "Batch is no longer interrupted",
+		"documents_disabled":               "Documents are disabled; set FRIDAY_DOCUMENTS_DIR to send them",
+		"document_attribute_invalid":       "document_attribute may only contain letters, numbers and underscores",
+		"batch_retrieved":                  "Batch retrieved successfully",
+		"batch_cancelled":                  "Batch cancelled successfully",
+		"batch_cancel_too_late":            "Batch already %s",
+		"batch_deleted":                    "Batch deleted successfully",
+		"batch_resumed":                    "Batch resumed; it continues when a sending slot is free",
+		"batch_events_retrieved":           "Batch events retrieved successfully",
+		"recipients_retrieved":             "Recipients retrieved successfully",
+		"message_not_found":                "Message not found",
+		"message_not_pending":              "Message is no longer pending",
+		"message_skipped":                  "Message skipped",
+		"message_edited":                   "Message edited",
+		"events_bad_after":                 "after must be a non-negative integer",
+		"batch_members_invalid_jids":       "Group has members with invalid JIDs, remove them first: %v",
+		"batch_all_quarantined":            "All %d group members are quarantined",
+		"batch_label_unknown_placeholder":  "Unknown label placeholder {{%s}}; use {{date}}, {{group}} or {{draft}}",
+		"batch_label_invalid":              "Invalid label: %s",
+		"batch_label_too_long":             "Label is %d characters once rendered; the limit is %d",
+		"client_name_too_long":             "Client name must be at most %d bytes",
+		"batch_dry_run":                    "Dry run: %d recipients",
+		"batch_started":                    "Batch started",
+		"batch_preparing":                  "Batch preparing (%d messages)",
+		"batch_queued":                     "Batch queued",
+		"batch_queued_behind":              "Batch queued (waiting for batch #%d to complete)",
+		"batch_note_missing_selector":      "%d without a variant selector attribute will receive the default content",
+		"batch_note_not_on_whatsapp":       "%d recipients are not on WhatsApp",
+		"batch_note_lid_will_fail":         "%d hidden numbers without a phone number will fail",
+		"batch_note_lid_failed":            "%d hidden numbers without a phone number failed",
+		"batch_note_no_document_will_fail": "%d without a sendable document will fail",
+		"batch_note_no_document":           "%d recipients have no sendable document yet",
+		"batch_note_quarantined":           "%d quarantined recipients skipped",
+		"batch_note_blocked":               "%d blocked contacts skipped",
+		"batch_note_capped":                "%d recipients at the recipient cap skipped",
+		"batch_note_self":                  "the linked account was skipped",
+		"batch_note_warnings":              "%d content warnings",
+		"weekly_report_retrieved":          "Report for %s",
+
+		// Integrations
+		"trigger_bad_signature":   "Invalid or expired signature",
+		"trigger_draft_ambiguous": "Give either draft_id or draft_title",
+		"trigger_group_ambiguous": "Give either group_id or group_name",
+
+		// Settings
+		"no_settings_given":   "No settings given",
+		"settings_retrieved":  "Settings retrieved successfully",
+		"settings_saved":      "Settings saved successfully",
+		"audit_retrieved":     "Audit trail retrieved successfully",
+		"read_only_retrieved": "Read-only state retrieved successfully",
+
+		// Maintenance
+		"maintenance_done":          "Maintenance completed",
+		"maintenance_running":       "Maintenance is already running",
+		"maintenance_batch_running": "A batch is sending; run maintenance once it finishes",
+
+		// API tokens
+		"auth_required":           "An API token is required: send it as Authorization: Bearer <token>",
+		"auth_invalid":            "Invalid or revoked API token",
+		"auth_missing_scope":      "This API token lacks the %s scope",
+		"tokens_retrieved":        "Tokens retrieved successfully",
+		"token_created":           "Token created; copy it now, it is not shown again",
+		"token_revoked":           "Token revoked",
+		"token_not_found":         "Token not found",
+		"invalid_token_id":        "Invalid token ID",
+		"token_name_required":     "Token name is required",
+		"token_name_too_long":     "Token name must be at most %d characters",
+		"token_invalid_scopes":    "Invalid scopes: %v; use %s",
+		"token_first_needs_admin": "No token has the admin scope yet; this one needs it so tokens can still be managed",
+		"token_last_admin":        "This is the last admin token; create another admin token or revoke the others first",
+	},
+	Turkish: {
+		// Common
+		"not_found":                     "Bulunamadı",
+		"invalid_path":                  "Geçersiz yol",
+		"invalid_jid_encoding":          "Geçersiz JID kodlaması",
+		"failed_to_read_body":           "İstek gövdesi okunamadı",
+		"request_body_too_large_mb":     "İstek gövdesi çok büyük (sınır %d MB)",
+		"request_body_too_large_kb":     "İstek gövdesi çok büyük (sınır %d KB)",
+		"request_body_empty":            "İstek gövdesi boş, bir JSON nesnesi bekleniyordu",
+		"json_incomplete":               "Geçersiz JSON: gövde, değer tamamlanmadan bitiyor",
+		"json_trailing_data":            "Geçersiz JSON: JSON değerinden sonra beklenmeyen veri",
+		"rate_limited":                  "Çok fazla istek, %d sn sonra tekrar deneyin",
+		"whatsapp_not_connected":        "WhatsApp istemcisi bağlı değil",
+		"whatsapp_connected":            "WhatsApp istemcisi bağlandı",
+		"whatsapp_disconnected_cleared": "WhatsApp bağlantısı kesildi ve oturum temizlendi",
+		"no_session":                    "Oturum yok - QR kod taraması gerekli",
+		"qr_ready":                      "QR kod taramaya hazır",
+		"qr_not_available":              "QR kod yok. Önce WhatsApp'a bağlanmayı deneyin.",
+		"qr_cleared":                    "QR kod kaldırıldı",
+		"qr_image_failed":               "QR kod görseli oluşturulamadı",
+		"qr_bad_format":                 "format png veya svg olmalı",
+		"qr_bad_json_format":            "format base64 olmalı ya da görselsiz kod için boş bırakılmalı",
+		"account_retrieved":             "Bağlı hesap alındı",
+		"client_events_retrieved":       "İstemci olayları alındı",
+		"client_events_invalid_since":   "since bir RFC 3339 zamanı olmalı, ör. 2025-03-01T12:00:00Z",
+		"routes_retrieved":              "Rotalar alındı",
+		"unknown_endpoint":              "Bilinmeyen uç nokta",
+
+		// Idempotency
+		"idempotency_in_progress":  "Bu Idempotency-Key ile gönderilen istek hâlâ işleniyor",
+		"idempotency_check_failed": "Idempotency-Key kontrol edilemedi",
+		"idempotency_too_long":     "Idempotency-Key çok uzun",
+		"idempotency_reused":       "Idempotency-Key başka bir istek için zaten kullanıldı",
+
+		// Messages
+		"message_content_required":   "Mesaj içeriği gerekli",
+		"recipient_required":         "Alıcı (telefon numarası veya kişi adı) gerekli",
+		"message_sent":               "Mesaj gönderildi",
+		"reply_sent":                 "Yanıt gönderildi",
+		"reaction_sent":              "Tepki gönderildi",
+		"reaction_removed":           "Tepki kaldırıldı",
+		"reaction_invalid":           "emoji tek bir emoji olmalı ya da tepkiyi kaldırmak için boş bırakılmalı",
+		"chat_required":              "Sohbet (telefon numarası, kişi adı veya JID) gerekli",
+		"target_message_id_required": "target_message_id gerekli",
+		"poll_sent":                  "Anket gönderildi",
+		"capabilities_retrieved":     "Özellikler alındı",
+		"blocklist_retrieved":        "Engellenenler listesi alındı",
+		"batch_all_blocked":          "Grubun %d üyesinin tamamı karantinada veya engellenmiş",
+		"batch_all_capped":           "%d alıcının tamamı son %d günde batch.recipient_cap sınırına ulaştı",
+		"batch_trigger_disabled":     "Toplu gönderim tetikleyicisi devre dışı: önce %s ayarlayın",
+		"quoted_message_id_required": "quoted_message_id gerekli",
+		"phone_numbers_required":     "En az bir telefon numarası gerekli",
+		"phone_validation_done":      "Telefon doğrulaması tamamlandı",
+		"message_queued_outbox":      "WhatsApp bağlı değil; mesaj #%d numarayla giden kutusuna alındı, bağlantı gelince gönderilecek",
+
+		// Outbox
+		"outbox_retrieved":      "Giden kutusu alındı",
+		"outbox_item_retrieved": "Giden kutusu mesajı alındı",
+		"outbox_deleted":        "Giden kutusu mesajı silindi",
+		"outbox_not_found":      "Giden kutusu mesajı bulunamadı",
+		"outbox_invalid_id":     "Geçersiz giden kutusu mesaj ID'si",
+		"outbox_invalid_status": "status queued, sent, failed veya expired olmalı",
+
+		// Contacts
+		"contacts_retrieved":            "Kişiler alındı",
+		"contact_retrieved":             "Kişi alındı",
+		"contact_not_found":             "Kişi bulunamadı",
+		"contact_not_found_offline":     "Kişi bulunamadı; WhatsApp bağlı olmadığı için yalnızca yerel veriler kontrol edildi",
+		"suspected_blocked_found":       "Son mesajları iletilmeyen %d kişi bulundu",
+		"contacts_synced":               "%d kişi eşitlendi: %d eklendi, %d silindi, %d yeniden adlandırıldı",
+		"contacts_sync_retrieved":       "Son kişi eşitlemesi alındı",
+		"contacts_never_synced":         "Kişiler henüz eşitlenmedi",
+		"contact_changes_retrieved":     "Kişi değişiklikleri: %d eklendi, %d silindi, %d yeniden adlandırıldı",
+		"contact_changes_invalid_since": "since bir eşitleme numarası veya RFC 3339 zamanı olmalı, ör. 2025-03-01T12:00:00Z",
+		"contact_changes_none_added":    "O zamandan beri kişi eklenmedi",
+		"suspected_blocked_invalid_min": "min pozitif bir tam sayı olmalıdır",
+		"contact_search_done":           "Kişi araması tamamlandı",
+		"contact_jid_required":          "Kişi JID'i gerekli",
+		"recipient_is_self":             "Alıcı bağlı hesabın kendisi, mesaj kendi sohbetinize gider; yine de göndermek için allow_self ayarlayın",
+		"batch_only_self":               "Gruptaki tek mesaj atılabilecek üye bağlı hesabın kendisi; kendinize mesaj atmak için allow_self ayarlayın",
+		"jids_required":                 "En az bir JID gerekli",
+		"merge_jids_required":           "primary_jid ve duplicate_jid gerekli",
+		"merge_same_contact":            "primary_jid ve duplicate_jid aynı kişi",
+		"note_saved":                    "Not kaydedildi",
+		"note_cleared":                  "Not temizlendi",
+		"contacts_merged":               "%s kişisi %s kişisine birleştirildi",
+		"quarantined_found":             "Karantinada %d kişi bulundu",
+
+		// Attributes
+		"attribute_key_required":          "Özellik anahtarı gerekli",
+		"attribute_key_required_deletion": "Silmek için özellik anahtarı gerekli",
+		"attribute_key_invalid":           "Özellik anahtarı yalnızca harf, rakam ve alt çizgi içerebilir",
+		"attribute_value_required":        "Özellik değeri gerekli",
+		"attribute_not_found":             "Özellik bulunamadı",
+		"attribute_saved":                 "Özellik kaydedildi",
+		"attribute_deleted":               "Özellik silindi",
+		"attributes_cleared":              "%d özellik silindi",
+		"attributes_clear_confirm":        "Bir kişinin tüm özelliklerini silmek için ?confirm=true gerekli; tek bir özelliği silmek için anahtarını yola ekleyin",
+		"attributes_retrieved":            "Özellikler alındı",
+		"attribute_keys_retrieved":        "Özellik anahtarları alındı",
+		"placeholder_default_saved":       "Yer tutucu varsayılanı kaydedildi",
+		"placeholder_default_deleted":     "Yer tutucu varsayılanı silindi",
+		"attributes_found":                "%d özellik bulundu",
+		"attribute_changes_found":         "%d özellik değişikliği bulundu",
+		"placeholder_defaults_found":      "%d yer tutucu varsayılanı bulundu",
+
+		// Drafts
+		"invalid_draft_id":          "Geçersiz taslak ID'si",
+		"draft_not_found":           "Taslak bulunamadı",
+		"title_required":            "Başlık gerekli",
+		"content_required":          "İçerik gerekli",
+		"template_has_errors":       "Şablonda hatalar var",
+		"invalid_delimiters":        "Geçersiz yer tutucu ayraçları: %v",
+		"draft_in_use":              "Taslak sırada bekleyen veya çalışan bir toplu gönderimde kullanılıyor",
+		"draft_has_batches":         "Bu taslaktan toplu gönderimler oluşturuldu; önce onları silin",
+		"draft_created":             "Taslak oluşturuldu",
+		"draft_duplicated":          "Taslak kopyalandı",
+		"draft_updated":             "Taslak güncellendi",
+		"draft_deleted":             "Taslak silindi",
+		"draft_retrieved":           "Taslak alındı",
+		"drafts_retrieved":          "Taslaklar alındı",
+		"draft_stats_retrieved":     "Taslak istatistikleri alındı",
+		"preview_generated":         "Önizleme oluşturuldu",
+		"invalid_import_strategy":   "Geçersiz strateji (skip, overwrite-by-title veya duplicate kullanın)",
+		"invalid_variant_id":        "Geçersiz varyant ID'si",
+		"variant_not_found":         "Varyant bulunamadı",
+		"variant_selector_required": "Seçici anahtarı ve değeri gerekli",
+		"variant_saved":             "Varyant kaydedildi",
+		"variant_deleted":           "Varyant silindi",
+		"variants_retrieved":        "Varyantlar alındı",
+		"drafts_imported":           "%d/%d taslak içe aktarıldı",
+
+		// Groups
+		"invalid_group_id":            "Geçersiz grup ID'si",
+		"invalid_group_id_param":      "Geçersiz group_id",
+		"invalid_not_in_group_id":     "Geçersiz not_in_group ID'si",
+		"group_not_found":             "Grup bulunamadı",
+		"group_name_required":         "Grup adı gerekli",
+		"group_name_taken":            "Bu adla bir grup zaten var",
+		"group_in_use":                "Grup sırada bekleyen veya çalışan bir toplu gönderimde kullanılıyor",
+		"group_has_batches":           "Bu gruptan toplu gönderimler oluşturuldu; önce onları silin",
+		"group_no_members":            "Grubun üyesi yok",
+		"group_no_default_draft":      "Grubun varsayılan taslağı yok; bir tane ayarlayın veya draft_id verin",
+		"group_created":               "Grup oluşturuldu",
+		"group_combined":              "Grup %d üyeyle oluşturuldu",
+		"group_combined_empty":        "Grup oluşturuldu, ancak işlem sonucunda hiç üye kalmadı",
+		"combine_invalid_operation":   "operation union, intersection veya difference olmalı",
+		"combine_two_groups_required": "group_ids en az iki grup içermeli",
+		"combine_duplicate_group":     "%d numaralı grup birden fazla kez verildi",
+		"combine_group_not_found":     "%d numaralı grup bulunamadı",
+		"group_moved":                 "Grup %s çalışma alanına taşındı",
+		"draft_moved":                 "Taslak %s çalışma alanına taşındı",
+
+		// Workspaces
+		"workspace_not_found":     "%s çalışma alanı bulunamadı",
+		"workspaces_found":        "%d çalışma alanı bulundu",
+		"workspace_created":       "Çalışma alanı oluşturuldu",
+		"workspace_name_required": "Çalışma alanı adı gerekli",
+		"workspace_name_numeric":  "Çalışma alanı adı sayı olamaz; sayılar çalışma alanını ID ile seçer",
+		"workspace_name_taken":    "Bu adda bir çalışma alanı zaten var",
+		"workspace_id_required":   "workspace_id gerekli",
+		"group_updated":           "Grup güncellendi",
+		"group_deleted":           "Grup silindi",
+		"group_retrieved":         "Grup alındı",
+		"groups_retrieved":        "Gruplar alındı",
+		"member_jid_required":     "Silmek için üye JID'i gerekli",
+		"member_not_found":        "Üye grupta bulunamadı",
+		"member_removed":          "Üye çıkarıldı",
+		"members_retrieved":       "Üyeler alındı",
+		"members_reordered":       "Üye sırası kaydedildi",
+		"members_moved":           "%d üye taşındı, %d üye grupta değildi",
+		"members_move_same_group": "Üyeler bulundukları gruba taşınamaz",
+		"members_order_unknown":   "Bu grubun üyesi değil: %s",
+		"members_added":           "Gruba %d üye eklendi",
+
+		// Batches
+		"invalid_batch_id":                 "Geçersiz toplu gönderim ID'si",
+		"invalid_message_id":               "Geçersiz mesaj ID'si",
+		"batch_not_found":                  "Toplu gönderim bulunamadı",
+		"progress_retrieved":               "İlerleme alındı",
+		"preflight_done":                   "Ön kontrol %d/%d üyeyi işaretledi",
+		"preflight_invalid_recent_hours":   "recent_hours negatif olamaz",
+		"progress_invalid_wait":            "wait 0 ile %d arasında bir saniye sayısı olmalı",
+		"batch_invalid_sort":               "sort şunlardan biri olmalı: %s",
+		"batch_not_found_or_running":       "Toplu gönderim bulunamadı veya şu anda çalışıyor",
+		"batch_not_interrupted":            "Toplu gönderim artık kesintiye uğramış durumda değil",
+		"documents_disabled":               "Belgeler devre dışı; göndermek için FRIDAY_DOCUMENTS_DIR ayarlayın",
+		"document_attribute_invalid":       "document_attribute yalnızca harf, rakam ve alt çizgi içerebilir",
+		"batch_retrieved":                  "Toplu gönderim alındı",
+		"batch_cancelled":                  "Toplu gönderim iptal edildi",
+		"batch_cancel_too_late":            "Toplu gönderim zaten %s",
+		"batch_deleted":                    "Toplu gönderim silindi",
+		"batch_resumed":                    "Toplu gönderime devam edildi; gönderim sırası boşaldığında sürecek",
+		"batch_events_retrieved":           "Toplu gönderim olayları alındı",
+		"recipients_retrieved":             "Alıcılar alındı",
+		"message_not_found":                "Mesaj bulunamadı",
+		"message_not_pending":              "Mesaj artık beklemede değil",
+		"message_skipped":                  "Mesaj atlandı",
+		"message_edited":                   "Mesaj düzenlendi",
+		"events_bad_after":                 "after negatif olmayan bir tam sayı olmalı",
+		"batch_members_invalid_jids":       "Grupta geçersiz JID'li üyeler var, önce onları çıkarın: %v",
+		"batch_all_quarantined":            "Grubun %d üyesinin tamamı karantinada",
+		"batch_label_unknown_placeholder":  "Bilinmeyen etiket yer tutucusu {{%s}}; {{date}}, {{group}} veya {{draft}} kullanın",
+		"batch_label_invalid":              "Geçersiz etiket: %s",
+		"batch_label_too_long":             "Etiket işlendiğinde %d karakter; sınır %d",
+		"client_name_too_long":             "İstemci adı en fazla %d bayt olabilir",
+		"batch_dry_run":                    "Deneme: %d alıcı",
+		"batch_started":                    "Toplu gönderim başladı",
+		"batch_preparing":                  "Toplu gönderim hazırlanıyor (%d mesaj)",
+		"batch_queued":                     "Toplu gönderim sıraya alındı",
+		"batch_queued_behind":              "Toplu gönderim sıraya alındı (#%d numaralı gönderimin bitmesi bekleniyor)",
+		"batch_note_missing_selector":      "varyant seçici özelliği olmayan %d alıcıya varsayılan içerik gidecek",
+		"batch_note_not_on_whatsapp":       "%d alıcı WhatsApp'ta değil",
+		"batch_note_lid_will_fail":         "telefon numarası olmayan %d gizli numara başarısız olacak",
+		"batch_note_lid_failed":            "telefon numarası olmayan %d gizli numara başarısız oldu",
+		"batch_note_no_document_will_fail": "gönderilebilir belgesi olmayan %d alıcı başarısız olacak",
+		"batch_note_no_document":           "%d alıcının henüz gönderilebilir belgesi yok",
+		"batch_note_quarantined":           "karantinadaki %d alıcı atlandı",
+		"batch_note_blocked":               "engellenmiş %d kişi atlandı",
+		"batch_note_capped":                "alıcı sınırına ulaşmış %d alıcı atlandı",
+		"batch_note_self":                  "bağlı hesap atlandı",
+		"batch_note_warnings":              "%d içerik uyarısı",
+		"weekly_report_retrieved":          "%s raporu",
+
+		// Integrations
+		"trigger_bad_signature":   "Geçersiz veya süresi dolmuş imza",
+		"trigger_draft_ambiguous": "draft_id veya draft_title'dan birini verin",
+		"trigger_group_ambiguous": "group_id veya group_name'den birini verin",
+
+		// Settings
+		"no_settings_given":   "Ayar verilmedi",
+		"settings_retrieved":  "Ayarlar alındı",
+		"settings_saved":      "Ayarlar kaydedildi",
+		"audit_retrieved":     "Değişiklik geçmişi alındı",
+		"read_only_retrieved": "Salt okunur durumu alındı",
+
+		// Maintenance
+		"maintenance_done":          "Bakım tamamlandı",
+		"maintenance_running":       "Bakım zaten çalışıyor",
+		"maintenance_batch_running": "Bir toplu gönderim sürüyor; bakımı o bittikten sonra çalıştırın",
+
+		// API tokens
+		"auth_required":           "API anahtarı gerekli: Authorization: Bearer <anahtar> olarak gönderin",
+		"auth_invalid":            "Geçersiz veya iptal edilmiş API anahtarı",
+		"auth_missing_scope":      "Bu API anahtarında %s yetkisi yok",
+		"tokens_retrieved":        "Anahtarlar başarıyla alındı",
+		"token_created":           "Anahtar oluşturuldu; şimdi kopyalayın, bir daha gösterilmeyecek",
+		"token_revoked":           "Anahtar iptal edildi",
+		"token_not_found":         "Anahtar bulunamadı",
+		"invalid_token_id":        "Geçersiz anahtar kimliği",
+		"token_name_required":     "Anahtar adı gerekli",
+		"token_name_too_long":     "Anahtar adı en fazla %d karakter olabilir",
+		"token_invalid_scopes":    "Geçersiz yetkiler: %v; şunları kullanın: %s",
+		"token_first_needs_admin": "Henüz admin yetkili anahtar yok; anahtarların yönetilebilmesi için bu anahtarda olmalı",
+		"token_last_admin":        "Bu son admin anahtarı; önce başka bir admin anahtarı oluşturun ya da diğerlerini iptal edin",
+	},
+}
@@ -0,0 +1,42 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// verb matches a fmt verb with its flags, width and precision.
+var verb = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z%]`)
+
+// TestCatalogsComplete checks that every language has every English message,
+// with the same fmt verbs in the same order, and nothing English lacks.
+func TestCatalogsComplete(t *testing.T) {
+	english := catalogs[English]
+	for lang, catalog := range catalogs {
+		if lang == English {
+			continue
+		}
+		var missing []string
+		for id, format := range english {
+			translated, ok := catalog[id]
+			if !ok {
+				missing = append(missing, id)
+				continue
+			}
+			if want, got := verb.FindAllString(format, -1), verb.FindAllString(translated, -1); !slices.Equal(got, want) {
+				t.Errorf("%s %q has verbs %q, want %q as in English", lang, id, got, want)
+			}
+		}
+		sort.Strings(missing)
+		for _, id := range missing {
+			t.Errorf("%s is missing %q", lang, id)
+		}
+		for id := range catalog {
+			if _, ok := english[id]; !ok {
+				t.Errorf("%s has %q, which English does not", lang, id)
+			}
+		}
+	}
+}
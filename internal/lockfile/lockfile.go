@@ -0,0 +1,119 @@
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// errHeld is what tryLock returns when another open file holds the lock.
+var errHeld = errors.New("lock is held elsewhere")
+
+// ErrLocked is returned when another live process holds the lock.
+type ErrLocked struct {
+	Path string
+	PID  int // 0 when the holder has not written its PID yet
+}
+
+func (e *ErrLocked) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("another Friday instance is already using this data directory (%s is locked)", e.Path)
+	}
+	return fmt.Sprintf("another Friday instance is already using this data directory (pid %d holds %s)", e.PID, e.Path)
+}
+
+// Lock is an exclusive OS lock on a file next to a database: flock on Unix,
+// LockFileEx on Windows. The file carries the holder's PID for the error others
+// get; the kernel drops the lock when the holder exits, however it exits, so a
+// stale file is simply locked again.
+type Lock struct {
+	path string
+	file *os.File
+}
+
+// Acquire opens path, creating it if needed, locks it without waiting and
+// writes the current PID into it. The file is never removed while another
+// process may hold it.
+func Acquire(path string) (*Lock, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+		}
+
+		if err := tryLock(f); err != nil {
+			f.Close()
+			if errors.Is(err, errHeld) {
+				pid, _ := readPID(path)
+				return nil, &ErrLocked{Path: path, PID: pid}
+			}
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		// A holder releasing as we opened may have removed the file we locked;
+		// the lock must be on the file at path, so start over with that one
+		if !samePath(f, path) {
+			f.Close()
+			continue
+		}
+
+		if err := writePID(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to write lock file %s: %w", path, err)
+		}
+		return &Lock{path: path, file: f}, nil
+	}
+	return nil, fmt.Errorf("failed to acquire lock file %s: lost race with another process", path)
+}
+
+// Release removes the lock file and drops the lock. It is safe to call more than once.
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	f := l.file
+	l.file = nil
+
+	if err := removeAndClose(l.path, f); err != nil {
+		return fmt.Errorf("failed to release lock file %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// samePath reports whether f is still the file at path.
+func samePath(f *os.File, path string) bool {
+	opened, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(opened, current)
+}
+
+// writePID replaces the file's content with the current PID.
+func writePID(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func readPID(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("lock file %s has no valid pid", path)
+	}
+	return pid, nil
+}
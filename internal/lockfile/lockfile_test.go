@@ -0,0 +1,156 @@
+package lockfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireRefusesWhileHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "friday.db.lock")
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer lock.Release()
+
+	_, err = Acquire(path)
+	var locked *ErrLocked
+	if !errors.As(err, &locked) {
+		t.Fatalf("second Acquire = %v, want ErrLocked", err)
+	}
+	if locked.PID != os.Getpid() {
+		t.Errorf("ErrLocked.PID = %d, want %d", locked.PID, os.Getpid())
+	}
+}
+
+func TestAcquireKeepsFileWithoutPID(t *testing.T) {
+	// Another process has created and locked the file but not written its PID yet
+	path := filepath.Join(t.TempDir(), "friday.db.lock")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := tryLock(f); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Acquire(path)
+	var locked *ErrLocked
+	if !errors.As(err, &locked) || locked.PID != 0 {
+		t.Fatalf("Acquire = %v, want ErrLocked without a PID", err)
+	}
+	if !samePath(f, path) {
+		t.Error("the holder's lock file was removed or replaced")
+	}
+}
+
+func TestAcquireTakesOverUnlockedFile(t *testing.T) {
+	for name, content := range map[string]string{
+		"dead pid": "2147483647\n",
+		"empty":    "",
+		"garbage":  "not a pid",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "friday.db.lock")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			lock, err := Acquire(path)
+			if err != nil {
+				t.Fatalf("Acquire: %v", err)
+			}
+			defer lock.Release()
+			if pid, err := readPID(path); err != nil || pid != os.Getpid() {
+				t.Errorf("lock file PID = %d, %v; want ours", pid, err)
+			}
+		})
+	}
+}
+
+func TestReleaseRemovesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "friday.db.lock")
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file still there after Release: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Errorf("second Release: %v", err)
+	}
+
+	again, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire after Release: %v", err)
+	}
+	again.Release()
+}
+
+// TestStaleLockRecovery kills a process holding the lock, which leaves the
+// file behind with its PID, and takes the lock over.
+func TestStaleLockRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "friday.db.lock")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperHoldLock$")
+	cmd.Env = append(os.Environ(), "LOCKFILE_HELPER_PATH="+path)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Process.Kill()
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil || line != "locked\n" {
+		t.Fatalf("helper did not lock: %q, %v", line, err)
+	}
+
+	_, err = Acquire(path)
+	var locked *ErrLocked
+	if !errors.As(err, &locked) || locked.PID != cmd.Process.Pid {
+		t.Fatalf("Acquire while the helper runs = %v, want ErrLocked naming pid %d", err, cmd.Process.Pid)
+	}
+
+	cmd.Process.Kill()
+	cmd.Wait()
+	if pid, err := readPID(path); err != nil || pid != cmd.Process.Pid {
+		t.Fatalf("killed helper's lock file: pid %d, %v; want it left behind", pid, err)
+	}
+
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire after the holder died: %v", err)
+	}
+	defer lock.Release()
+	if pid, err := readPID(path); err != nil || pid != os.Getpid() {
+		t.Errorf("lock file PID = %d, %v; want ours", pid, err)
+	}
+}
+
+// TestHelperHoldLock is run by TestStaleLockRecovery in a child process.
+func TestHelperHoldLock(t *testing.T) {
+	path := os.Getenv("LOCKFILE_HELPER_PATH")
+	if path == "" {
+		t.Skip("helper process only")
+	}
+	if _, err := Acquire(path); err != nil {
+		fmt.Println("failed:", err)
+		os.Exit(1)
+	}
+	fmt.Println("locked")
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package lockfile
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLock takes an exclusive flock on f without waiting.
+func tryLock(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errHeld
+	}
+	return err
+}
+
+// removeAndClose removes the file while it is still locked, so no other
+// process can lock this file in between, then closes it to drop the lock.
+func removeAndClose(path string, f *os.File) error {
+	rerr := os.Remove(path)
+	if os.IsNotExist(rerr) {
+		rerr = nil
+	}
+	return errors.Join(rerr, f.Close())
+}
@@ -0,0 +1,35 @@
+//go:build windows
+
+package lockfile
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// tryLock takes an exclusive LockFileEx lock on f without waiting. The locked
+// byte lies far past the PID, since Windows locks are mandatory and would
+// otherwise keep others from reading who holds the file.
+func tryLock(f *os.File) error {
+	ol := &windows.Overlapped{OffsetHigh: 1}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, ol)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errHeld
+	}
+	return err
+}
+
+// removeAndClose closes the file to drop the lock, then removes it; Windows
+// cannot delete a file that is still open. If another instance opened the file
+// in between, the removal fails and the file is left for it.
+func removeAndClose(path string, f *os.File) error {
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) && !errors.Is(err, windows.ERROR_SHARING_VIOLATION) {
+		return err
+	}
+	return nil
+}
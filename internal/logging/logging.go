@@ -0,0 +1,104 @@
+// Package logging configures the process-wide slog logger from the environment.
+//
+// FRIDAY_LOG_LEVEL is one of debug, info (default), warn or error, and can be
+// changed while the server runs with SetLevel.
+// FRIDAY_LOG_FORMAT is text (default, one human-readable line per record) or json.
+// Packages log through slog directly and attach fields such as batch_id, jid and
+// request_id; lines written with the standard log package end up in the same
+// handler at info level.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Environment variables read by Setup.
+const (
+	EnvLevel  = "FRIDAY_LOG_LEVEL"
+	EnvFormat = "FRIDAY_LOG_FORMAT"
+)
+
+// level is the minimum level of the default logger installed by Setup.
+var level = new(slog.LevelVar)
+
+// Setup installs the default logger described by FRIDAY_LOG_LEVEL and
+// FRIDAY_LOG_FORMAT, writing to stderr.
+func Setup() error {
+	parsed, err := ParseLevel(os.Getenv(EnvLevel))
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+	handler, err := NewHandler(os.Stderr, os.Getenv(EnvFormat), level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// Level returns the minimum level of the default logger.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the minimum level of the default logger, taking effect for
+// every record logged after it returns.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// ParseLevel parses a level name; empty means info.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid %s %q (use debug, info, warn or error)", EnvLevel, name)
+	}
+}
+
+// NewHandler returns a handler writing format ("text", "json" or empty for text) to w.
+func NewHandler(w io.Writer, format string, level slog.Leveler) (slog.Handler, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "text":
+		return newTextHandler(w, level), nil
+	case "json":
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}), nil
+	default:
+		return nil, fmt.Errorf("invalid %s %q (use text or json)", EnvFormat, format)
+	}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID for FromContext.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// FromContext returns the default logger, with a request_id field when ctx
+// belongs to an HTTP request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if id := RequestID(ctx); id != "" {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
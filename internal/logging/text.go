@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// textHandler writes one line per record in the layout the standard log package
+// used, with the level and fields added:
+//
+//	2006/01/02 15:04:05 INFO  Batch 3 completed batch_id=3 sent=120
+type textHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Leveler
+	prefix string // Fields added with WithAttrs, already formatted
+	group  string // Key prefix from WithGroup, e.g. "http."
+}
+
+func newTextHandler(w io.Writer, level slog.Leveler) *textHandler {
+	return &textHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	b.WriteString(t.Format("2006/01/02 15:04:05 "))
+	level := r.Level.String()
+	b.WriteString(level)
+	b.WriteString(strings.Repeat(" ", max(1, 6-len(level))))
+	b.WriteString(r.Message)
+	b.WriteString(h.prefix)
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.group, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, a := range attrs {
+		writeAttr(&b, h.group, a)
+	}
+	clone := *h
+	clone.prefix += b.String()
+	return &clone
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.group += name + "."
+	return &clone
+}
+
+// writeAttr appends " key=value", quoting values that contain spaces or quotes.
+func writeAttr(b *strings.Builder, group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		prefix := group
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, prefix, ga)
+		}
+		return
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(group)
+	b.WriteString(a.Key)
+	b.WriteByte('=')
+	value := a.Value.String()
+	if a.Value.Kind() == slog.KindTime {
+		value = a.Value.Time().Format(time.RFC3339)
+	}
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	b.WriteString(value)
+}
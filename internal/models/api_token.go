@@ -0,0 +1,222 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"friday/internal/database"
+)
+
+// API token scopes. A request needs the one scope its endpoint requires; scopes
+// do not imply each other, so an operator token usually holds read and send.
+const (
+	ScopeRead  = "read"  // GET endpoints
+	ScopeSend  = "send"  // Sending messages, creating batches and other changes
+	ScopeAdmin = "admin" // Settings, the WhatsApp session, maintenance and tokens
+)
+
+// APIScopes are the valid scopes in display order.
+var APIScopes = []string{ScopeRead, ScopeSend, ScopeAdmin}
+
+// apiTokenPrefix starts every token so it is recognizable in configs and logs.
+const apiTokenPrefix = "fri_"
+
+// APIToken is a bearer token for the API. The token itself is shown once, at
+// creation; only its SHA-256 hash and first characters are stored.
+type APIToken struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"` // First characters of the token, to tell tokens apart
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the token holds scope.
+func (t *APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// NormalizeScopes validates scopes and returns them deduplicated in the order
+// of APIScopes.
+func NormalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(APIScopes, scope) {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	normalized := []string{}
+	for _, scope := range APIScopes {
+		if slices.Contains(scopes, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
+
+// hashAPIToken is how tokens are stored and looked up.
+func hashAPIToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// APITokenRepository handles database operations for API tokens.
+type APITokenRepository struct {
+	db *database.DB
+}
+
+// NewAPITokenRepository creates a new API token repository.
+func NewAPITokenRepository(db *database.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// Create generates a token, stores its hash and returns the token, which
+// cannot be recovered later. token.Scopes must be normalized.
+func (r *APITokenRepository) Create(token *APIToken) (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	secret := apiTokenPrefix + hex.EncodeToString(random)
+	token.Prefix = secret[:len(apiTokenPrefix)+8]
+
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec(`
+		INSERT INTO api_tokens (name, token_hash, prefix, scopes, created_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, token.Name, hashAPIToken(secret), token.Prefix, strings.Join(token.Scopes, ","))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return "", fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	token.ID = id
+
+	row := r.db.Conn().QueryRow("SELECT created_at FROM api_tokens WHERE id = ?", id)
+	if err := row.Scan(&token.CreatedAt); err != nil {
+		token.CreatedAt = time.Now()
+	}
+
+	return secret, nil
+}
+
+const apiTokenColumns = `id, name, prefix, scopes, created_at, revoked_at`
+
+func scanAPIToken(row rowScanner) (*APIToken, error) {
+	var token APIToken
+	var scopes string
+	var revokedAt sql.NullTime
+	if err := row.Scan(&token.ID, &token.Name, &token.Prefix, &scopes, &token.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	token.Scopes = strings.Split(scopes, ",")
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
+
+// GetAll returns every token, revoked ones included, newest first.
+func (r *APITokenRepository) GetAll() ([]APIToken, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	rows, err := r.db.Conn().Query(`SELECT ` + apiTokenColumns + ` FROM api_tokens ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// GetByID retrieves a token, or nil if there is none with that ID.
+func (r *APITokenRepository) GetByID(id int64) (*APIToken, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	token, err := scanAPIToken(r.db.Conn().QueryRow(`SELECT `+apiTokenColumns+` FROM api_tokens WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	return token, nil
+}
+
+// Authenticate returns the active token matching secret, or nil if it is
+// unknown or revoked.
+func (r *APITokenRepository) Authenticate(secret string) (*APIToken, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = ? AND revoked_at IS NULL`
+	token, err := scanAPIToken(r.db.Conn().QueryRow(query, hashAPIToken(secret)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up token: %w", err)
+	}
+	return token, nil
+}
+
+// CountActive returns how many tokens are not revoked, and how many of those
+// hold the admin scope. While none is active the API needs no token.
+func (r *APITokenRepository) CountActive() (active, admins int, err error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	err = r.db.Conn().QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(',' || scopes || ',' LIKE '%,admin,%'), 0)
+		FROM api_tokens
+		WHERE revoked_at IS NULL
+	`).Scan(&active, &admins)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	return active, admins, nil
+}
+
+// Revoke stops a token from working, from the next request on. It returns
+// false if there is no active token with that ID.
+func (r *APITokenRepository) Revoke(id int64) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec(`UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
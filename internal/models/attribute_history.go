@@ -0,0 +1,123 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Where an attribute write came from, recorded with each history entry.
+const (
+	AttributeSourceAPI    = "api"    // Single attribute set or deleted through the API
+	AttributeSourceImport = "import" // Contact import
+	AttributeSourceBulk   = "bulk"   // Bulk edit across contacts
+)
+
+// What happened to an attribute.
+const (
+	AttributeActionSet    = "set"    // Key did not exist before
+	AttributeActionUpdate = "update" // Value changed
+	AttributeActionDelete = "delete"
+)
+
+// attributeHistoryLimit is how many history entries are kept per contact; older
+// ones are pruned whenever a new one is written.
+const attributeHistoryLimit = 100
+
+// AttributeChange is one recorded attribute write.
+type AttributeChange struct {
+	ID        int64     `json:"id"`
+	JID       string    `json:"jid"`
+	Key       string    `json:"key"`
+	Action    string    `json:"action"`
+	OldValue  *string   `json:"old_value"` // nil when the key was first set
+	NewValue  *string   `json:"new_value"` // nil when the key was deleted
+	Source    string    `json:"source"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// recordAttributeChange appends a history entry and prunes the contact's oldest entries
+// beyond attributeHistoryLimit. Called inside the write's transaction.
+func recordAttributeChange(tx *sql.Tx, jid, key string, oldValue, newValue sql.NullString, source string) error {
+	action := AttributeActionUpdate
+	switch {
+	case !oldValue.Valid:
+		action = AttributeActionSet
+	case !newValue.Valid:
+		action = AttributeActionDelete
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO attribute_history (jid, key, action, old_value, new_value, source)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, jid, key, action, oldValue, newValue, source)
+	if err != nil {
+		return fmt.Errorf("failed to record attribute change: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM attribute_history
+		WHERE jid = ? AND id NOT IN (
+			SELECT id FROM attribute_history WHERE jid = ? ORDER BY id DESC LIMIT ?
+		)
+	`, jid, jid, attributeHistoryLimit)
+	if err != nil {
+		return fmt.Errorf("failed to prune attribute history: %w", err)
+	}
+
+	return nil
+}
+
+// GetHistory returns a contact's attribute changes, newest first, optionally for
+// a single key, along with the total number of matching entries.
+func (r *AttributeRepository) GetHistory(jid, key string, limit, offset int) ([]AttributeChange, int, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	where := "WHERE jid = ?"
+	args := []interface{}{jid}
+	if key != "" {
+		where += " AND key = ?"
+		args = append(args, key)
+	}
+
+	var total int
+	if err := r.db.Conn().QueryRow("SELECT COUNT(*) FROM attribute_history "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count attribute history: %w", err)
+	}
+
+	rows, err := r.db.Conn().Query(`
+		SELECT id, jid, key, action, old_value, new_value, source, changed_at
+		FROM attribute_history
+		`+where+`
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query attribute history: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []AttributeChange{}
+
+	for rows.Next() {
+		var c AttributeChange
+		var oldValue, newValue sql.NullString
+		if err := rows.Scan(&c.ID, &c.JID, &c.Key, &c.Action, &oldValue, &newValue, &c.Source, &c.ChangedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan attribute change: %w", err)
+		}
+		if oldValue.Valid {
+			c.OldValue = &oldValue.String
+		}
+		if newValue.Valid {
+			c.NewValue = &newValue.String
+		}
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating attribute history: %w", err)
+	}
+
+	return changes, total, nil
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"friday/internal/database"
+)
+
+// Batch lifecycle event types recorded by the worker.
+const (
+	BatchEventPrepared       = "prepared" // The messages of a preparing batch were created and it was queued
+	BatchEventStarted        = "started"
+	BatchEventMessageSent    = "message_sent"
+	BatchEventMessageFailed  = "message_failed"
+	BatchEventMessageSkipped = "message_skipped" // Detail names who skipped it, or why the server did
+	BatchEventMessageEdited  = "message_edited"  // Detail names who changed the message's content
+	BatchEventPaused         = "paused"
+	BatchEventResumed        = "resumed"
+	BatchEventCompleted      = "completed"
+	BatchEventCancelled      = "cancelled"
+	BatchEventInterrupted    = "interrupted" // Held at startup instead of resuming, see SettingAutoResumeBatches
+	BatchEventFailed         = "failed"
+	BatchEventQuarantined    = "quarantined" // A recipient reached the permanent failure threshold
+)
+
+// BatchEvent is one entry in a batch's audit log. Seq increases monotonically per batch.
+type BatchEvent struct {
+	ID         int64     `json:"id"`
+	BatchRunID int64     `json:"batch_run_id"`
+	Seq        int64     `json:"seq"`
+	Type       string    `json:"type"`
+	JID        *string   `json:"jid,omitempty"`
+	Detail     *string   `json:"detail,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BatchEventRepository handles database operations for batch events.
+type BatchEventRepository struct {
+	db *database.DB
+}
+
+// NewBatchEventRepository creates a new batch event repository.
+func NewBatchEventRepository(db *database.DB) *BatchEventRepository {
+	return &BatchEventRepository{db: db}
+}
+
+// Record appends an event to a batch's log. jid and detail may be empty.
+func (r *BatchEventRepository) Record(batchID int64, eventType, jid, detail string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	query := `
+		INSERT INTO batch_events (batch_run_id, seq, event_type, jid, detail)
+		SELECT ?, COALESCE(MAX(seq), 0) + 1, ?, ?, ?
+		FROM batch_events WHERE batch_run_id = ?
+	`
+
+	_, err := r.db.Conn().Exec(query, batchID, eventType, nullString(jid), nullString(detail), batchID)
+	if err != nil {
+		return fmt.Errorf("failed to record batch event: %w", err)
+	}
+
+	return nil
+}
+
+// GetByBatch returns a batch's events with seq greater than afterSeq, oldest first.
+func (r *BatchEventRepository) GetByBatch(batchID, afterSeq int64) ([]BatchEvent, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := `
+		SELECT id, batch_run_id, seq, event_type, jid, detail, created_at
+		FROM batch_events
+		WHERE batch_run_id = ? AND seq > ?
+		ORDER BY seq ASC
+	`
+
+	rows, err := r.db.Conn().Query(query, batchID, afterSeq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []BatchEvent{}
+
+	for rows.Next() {
+		var e BatchEvent
+		var jid, detail sql.NullString
+		if err := rows.Scan(&e.ID, &e.BatchRunID, &e.Seq, &e.Type, &jid, &detail, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan batch event: %w", err)
+		}
+		if jid.Valid {
+			e.JID = &jid.String
+		}
+		if detail.Valid {
+			e.Detail = &detail.String
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating batch events: %w", err)
+	}
+
+	return events, nil
+}
+
+// BatchRunStats summarises the pace of a finished batch run, derived from its event log.
+type BatchRunStats struct {
+	DurationSeconds   int64   `json:"duration_seconds"` // First start to finish, wall clock
+	PausedSeconds     int64   `json:"paused_seconds"`   // Paused, disconnected, or down between restarts
+	AttemptCount      int     `json:"attempt_count"`    // Messages the worker sent or failed to send
+	AverageGapSeconds float64 `json:"average_gap_seconds"`
+	MessagesPerMinute float64 `json:"messages_per_minute"`
+}
+
+// GetRunStats computes final stats for a batch that has finished. It returns nil
+// if the log has no start or no finish. Gaps between sends that span a pause or a
+// restart are left out of the average, matching the live throughput figure.
+func (r *BatchEventRepository) GetRunStats(batchID int64) (*BatchRunStats, error) {
+	events, err := r.GetByBatch(batchID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats BatchRunStats
+	var started, finished, previous, lastAttempt, pausedAt time.Time
+	var paused, gapTotal time.Duration
+	gaps := 0
+
+	for _, e := range events {
+		switch e.Type {
+		case BatchEventStarted:
+			if started.IsZero() {
+				started = e.CreatedAt
+			} else if pausedAt.IsZero() {
+				// Resumed after a restart: the worker was down since the previous event.
+				paused += e.CreatedAt.Sub(previous)
+			}
+			lastAttempt = time.Time{}
+		case BatchEventPaused:
+			if pausedAt.IsZero() {
+				pausedAt = e.CreatedAt
+			}
+			lastAttempt = time.Time{}
+		case BatchEventResumed:
+			if !pausedAt.IsZero() {
+				paused += e.CreatedAt.Sub(pausedAt)
+				pausedAt = time.Time{}
+			}
+		case BatchEventMessageSent, BatchEventMessageFailed:
+			// Validation failures are logged as one summary event without a JID.
+			if e.JID != nil {
+				stats.AttemptCount++
+				if !lastAttempt.IsZero() {
+					gapTotal += e.CreatedAt.Sub(lastAttempt)
+					gaps++
+				}
+				lastAttempt = e.CreatedAt
+			}
+		case BatchEventCompleted, BatchEventCancelled, BatchEventFailed:
+			finished = e.CreatedAt
+		}
+		previous = e.CreatedAt
+	}
+
+	if started.IsZero() || finished.IsZero() {
+		return nil, nil
+	}
+	if !pausedAt.IsZero() {
+		paused += finished.Sub(pausedAt)
+	}
+
+	stats.DurationSeconds = int64(finished.Sub(started).Seconds())
+	stats.PausedSeconds = int64(paused.Seconds())
+	if gaps > 0 {
+		avg := gapTotal.Seconds() / float64(gaps)
+		stats.AverageGapSeconds = math.Round(avg*10) / 10
+		if avg > 0 {
+			stats.MessagesPerMinute = math.Round(60/avg*100) / 100
+		}
+	}
+	return &stats, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"friday/internal/database"
@@ -16,6 +17,7 @@ const (
 	MessageStatusSending BatchMessageStatus = "sending"
 	MessageStatusSent    BatchMessageStatus = "sent"
 	MessageStatusFailed  BatchMessageStatus = "failed"
+	MessageStatusSkipped BatchMessageStatus = "skipped" // Passed over on request, see Skip, or by the server, see SkipWithReason
 )
 
 type BatchMessage struct {
@@ -27,10 +29,91 @@ type BatchMessage struct {
 	TemplateContent string             `json:"template_content"`
 	SentContent     *string            `json:"sent_content,omitempty"`
 	ErrorMessage    *string            `json:"error_message,omitempty"`
+	ErrorCode       *string            `json:"error_code,omitempty"` // Failure class, e.g. "not_on_whatsapp", see batch.FailureCode; for a skipped message, why the server skipped it
 	SentAt          *time.Time         `json:"sent_at,omitempty"`
+	SkippedAt       *time.Time         `json:"skipped_at,omitempty"`
+	SkippedBy       *string            `json:"skipped_by,omitempty"`      // X-Client-Name of the caller, or its address; nil when the server skipped it
+	ManuallyEdited  bool               `json:"manually_edited,omitempty"` // TemplateContent was changed for this recipient after the batch was created, see UpdateContent
+	EditedAt        *time.Time         `json:"edited_at,omitempty"`
+	WAMessageID     *string            `json:"wa_message_id,omitempty"`   // WhatsApp's ID for the sent message, which receipts refer to
+	DeliveryStatus  *DeliveryStatus    `json:"delivery_status,omitempty"` // Set once sent; nil for messages sent before receipts were tracked
+	DeliveredAt     *time.Time         `json:"delivered_at,omitempty"`
 	CreatedAt       time.Time          `json:"created_at"`
 }
 
+// batchMessageColumns is the column list shared by every batch message SELECT;
+// keep it in sync with scanBatchMessage.
+const batchMessageColumns = `id, batch_run_id, jid, contact_name, status,
+		       template_content, sent_content, error_message, error_code,
+		       sent_at, skipped_at, skipped_by, manually_edited, edited_at,
+		       wa_message_id, delivery_status, delivered_at, created_at`
+
+func scanBatchMessage(row rowScanner) (*BatchMessage, error) {
+	var msg BatchMessage
+	var contactName, sentContent, errorMessage, errorCode, skippedBy, waMessageID, deliveryStatus sql.NullString
+	var sentAt, skippedAt, editedAt, deliveredAt sql.NullTime
+
+	if err := row.Scan(
+		&msg.ID,
+		&msg.BatchRunID,
+		&msg.JID,
+		&contactName,
+		&msg.Status,
+		&msg.TemplateContent,
+		&sentContent,
+		&errorMessage,
+		&errorCode,
+		&sentAt,
+		&skippedAt,
+		&skippedBy,
+		&msg.ManuallyEdited,
+		&editedAt,
+		&waMessageID,
+		&deliveryStatus,
+		&deliveredAt,
+		&msg.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if contactName.Valid {
+		msg.ContactName = &contactName.String
+	}
+	if sentContent.Valid {
+		msg.SentContent = &sentContent.String
+	}
+	if errorMessage.Valid {
+		msg.ErrorMessage = &errorMessage.String
+	}
+	if errorCode.Valid {
+		msg.ErrorCode = &errorCode.String
+	}
+	if sentAt.Valid {
+		msg.SentAt = &sentAt.Time
+	}
+	if skippedAt.Valid {
+		msg.SkippedAt = &skippedAt.Time
+	}
+	if skippedBy.Valid {
+		msg.SkippedBy = &skippedBy.String
+	}
+	if editedAt.Valid {
+		msg.EditedAt = &editedAt.Time
+	}
+	if waMessageID.Valid {
+		msg.WAMessageID = &waMessageID.String
+	}
+	if deliveryStatus.Valid {
+		status := DeliveryStatus(deliveryStatus.String)
+		msg.DeliveryStatus = &status
+	}
+	if deliveredAt.Valid {
+		msg.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &msg, nil
+}
+
 // BatchMessageRepository handles database operations for batch messages.
 type BatchMessageRepository struct {
 	db *database.DB
@@ -97,12 +180,25 @@ func (r *BatchMessageRepository) CreateMultiple(messages []BatchMessage) error {
 	}
 	defer tx.Rollback()
 
+	if err := insertBatchMessages(tx, messages); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertBatchMessages inserts messages within tx and sets their IDs.
+func insertBatchMessages(tx *sql.Tx, messages []BatchMessage) error {
 	query := `
 		INSERT INTO batch_messages (
 			batch_run_id, jid, contact_name, status,
-			template_content, created_at
+			template_content, error_message, error_code, skipped_at, created_at
 		)
-		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CASE WHEN ? = 'skipped' THEN CURRENT_TIMESTAMP END, CURRENT_TIMESTAMP)
 	`
 
 	stmt, err := tx.Prepare(query)
@@ -118,6 +214,9 @@ func (r *BatchMessageRepository) CreateMultiple(messages []BatchMessage) error {
 			messages[i].ContactName,
 			messages[i].Status,
 			messages[i].TemplateContent,
+			messages[i].ErrorMessage,
+			messages[i].ErrorCode,
+			messages[i].Status,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to create message for %s: %w", messages[i].JID, err)
@@ -127,10 +226,6 @@ func (r *BatchMessageRepository) CreateMultiple(messages []BatchMessage) error {
 		messages[i].ID = id
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
@@ -140,29 +235,12 @@ func (r *BatchMessageRepository) GetByID(id int64) (*BatchMessage, error) {
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, batch_run_id, jid, contact_name, status,
-		       template_content, sent_content, error_message,
-		       sent_at, created_at
+		SELECT ` + batchMessageColumns + `
 		FROM batch_messages
 		WHERE id = ?
 	`
 
-	var msg BatchMessage
-	var contactName, sentContent, errorMessage sql.NullString
-	var sentAt sql.NullTime
-
-	err := r.db.Conn().QueryRow(query, id).Scan(
-		&msg.ID,
-		&msg.BatchRunID,
-		&msg.JID,
-		&contactName,
-		&msg.Status,
-		&msg.TemplateContent,
-		&sentContent,
-		&errorMessage,
-		&sentAt,
-		&msg.CreatedAt,
-	)
+	msg, err := scanBatchMessage(r.db.Conn().QueryRow(query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -171,34 +249,20 @@ func (r *BatchMessageRepository) GetByID(id int64) (*BatchMessage, error) {
 		return nil, fmt.Errorf("failed to get batch message: %w", err)
 	}
 
-	if contactName.Valid {
-		msg.ContactName = &contactName.String
-	}
-	if sentContent.Valid {
-		msg.SentContent = &sentContent.String
-	}
-	if errorMessage.Valid {
-		msg.ErrorMessage = &errorMessage.String
-	}
-	if sentAt.Valid {
-		msg.SentAt = &sentAt.Time
-	}
-
-	return &msg, nil
+	return msg, nil
 }
 
-// GetByBatchRun retrieves all messages for a batch run, ordered by creation time.
+// GetByBatchRun retrieves all messages for a batch run in the order they were
+// created, which is the order they are sent in.
 func (r *BatchMessageRepository) GetByBatchRun(batchRunID int64) ([]BatchMessage, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, batch_run_id, jid, contact_name, status,
-		       template_content, sent_content, error_message,
-		       sent_at, created_at
+		SELECT ` + batchMessageColumns + `
 		FROM batch_messages
 		WHERE batch_run_id = ?
-		ORDER BY created_at ASC
+		ORDER BY id ASC
 	`
 
 	rows, err := r.db.Conn().Query(query, batchRunID)
@@ -210,39 +274,11 @@ func (r *BatchMessageRepository) GetByBatchRun(batchRunID int64) ([]BatchMessage
 	messages := []BatchMessage{}
 
 	for rows.Next() {
-		var msg BatchMessage
-		var contactName, sentContent, errorMessage sql.NullString
-		var sentAt sql.NullTime
-
-		if err := rows.Scan(
-			&msg.ID,
-			&msg.BatchRunID,
-			&msg.JID,
-			&contactName,
-			&msg.Status,
-			&msg.TemplateContent,
-			&sentContent,
-			&errorMessage,
-			&sentAt,
-			&msg.CreatedAt,
-		); err != nil {
+		msg, err := scanBatchMessage(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan batch message: %w", err)
 		}
-
-		if contactName.Valid {
-			msg.ContactName = &contactName.String
-		}
-		if sentContent.Valid {
-			msg.SentContent = &sentContent.String
-		}
-		if errorMessage.Valid {
-			msg.ErrorMessage = &errorMessage.String
-		}
-		if sentAt.Valid {
-			msg.SentAt = &sentAt.Time
-		}
-
-		messages = append(messages, msg)
+		messages = append(messages, *msg)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -252,38 +288,22 @@ func (r *BatchMessageRepository) GetByBatchRun(batchRunID int64) ([]BatchMessage
 	return messages, nil
 }
 
-// GetNextPending returns the next pending message for a batch run.
+// GetNextPending returns the next pending message for a batch run, in the
+// order the messages were created (see BatchRun.Sort).
 // This is used by the worker to get the next message to send.
 func (r *BatchMessageRepository) GetNextPending(batchRunID int64) (*BatchMessage, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, batch_run_id, jid, contact_name, status,
-		       template_content, sent_content, error_message,
-		       sent_at, created_at
+		SELECT ` + batchMessageColumns + `
 		FROM batch_messages
 		WHERE batch_run_id = ? AND status = 'pending'
-		ORDER BY created_at ASC
+		ORDER BY id ASC
 		LIMIT 1
 	`
 
-	var msg BatchMessage
-	var contactName, sentContent, errorMessage sql.NullString
-	var sentAt sql.NullTime
-
-	err := r.db.Conn().QueryRow(query, batchRunID).Scan(
-		&msg.ID,
-		&msg.BatchRunID,
-		&msg.JID,
-		&contactName,
-		&msg.Status,
-		&msg.TemplateContent,
-		&sentContent,
-		&errorMessage,
-		&sentAt,
-		&msg.CreatedAt,
-	)
+	msg, err := scanBatchMessage(r.db.Conn().QueryRow(query, batchRunID))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -292,47 +312,147 @@ func (r *BatchMessageRepository) GetNextPending(batchRunID int64) (*BatchMessage
 		return nil, fmt.Errorf("failed to get next pending message: %w", err)
 	}
 
-	if contactName.Valid {
-		msg.ContactName = &contactName.String
+	return msg, nil
+}
+
+// MarkSending marks a pending message as currently being sent. It returns false
+// when the message is no longer pending, e.g. because it was skipped meanwhile.
+func (r *BatchMessageRepository) MarkSending(id int64) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	query := `UPDATE batch_messages SET status = 'sending' WHERE id = ? AND status = 'pending'`
+	result, err := r.db.Conn().Exec(query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark message as sending: %w", err)
 	}
-	if sentContent.Valid {
-		msg.SentContent = &sentContent.String
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// Skip marks a pending message of a batch as skipped by skippedBy and counts it
+// in the batch's skipped_count. It returns false when the message is not
+// pending, leaving it unchanged.
+func (r *BatchMessageRepository) Skip(batchRunID, id int64, skippedBy string) (bool, error) {
+	return r.skip(batchRunID, id, sql.NullString{String: skippedBy, Valid: true}, sql.NullString{}, sql.NullString{})
+}
+
+// SkipWithReason marks a pending message skipped by the server rather than a
+// caller, storing code and reason as its error_code and error_message so
+// listings show why. It is counted like Skip.
+func (r *BatchMessageRepository) SkipWithReason(batchRunID, id int64, code, reason string) (bool, error) {
+	return r.skip(batchRunID, id, sql.NullString{}, sql.NullString{String: code, Valid: true}, sql.NullString{String: reason, Valid: true})
+}
+
+func (r *BatchMessageRepository) skip(batchRunID, id int64, skippedBy, code, reason sql.NullString) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	if errorMessage.Valid {
-		msg.ErrorMessage = &errorMessage.String
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE batch_messages
+		SET status = 'skipped', skipped_at = CURRENT_TIMESTAMP, skipped_by = ?,
+		    error_code = ?, error_message = ?
+		WHERE id = ? AND batch_run_id = ? AND status = 'pending'
+	`, skippedBy, code, reason, id, batchRunID)
+	if err != nil {
+		return false, fmt.Errorf("failed to skip message: %w", err)
 	}
-	if sentAt.Valid {
-		msg.SentAt = &sentAt.Time
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return false, nil
 	}
 
-	return &msg, nil
+	if _, err := tx.Exec("UPDATE batch_runs SET skipped_count = skipped_count + 1 WHERE id = ?", batchRunID); err != nil {
+		return false, fmt.Errorf("failed to count skipped message: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// UpdateContent replaces the template content of one pending message of a
+// batch run and flags it manually edited; the worker renders the new content
+// when it gets to the message. It returns false when the message is not
+// pending any more.
+func (r *BatchMessageRepository) UpdateContent(batchRunID, id int64, content string) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec(`
+		UPDATE batch_messages
+		SET template_content = ?, manually_edited = 1, edited_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND batch_run_id = ? AND status = 'pending'
+	`, content, id, batchRunID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update message content: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return affected > 0, nil
 }
 
-// MarkSending marks a message as currently being sent.
-func (r *BatchMessageRepository) MarkSending(id int64) error {
+// MarkPending returns a message that was being sent to the queue, for sends
+// refused before they reached WhatsApp.
+func (r *BatchMessageRepository) MarkPending(id int64) error {
 	r.db.Lock()
 	defer r.db.Unlock()
 
-	query := `UPDATE batch_messages SET status = 'sending' WHERE id = ?`
+	query := `UPDATE batch_messages SET status = 'pending' WHERE id = ? AND status = 'sending'`
 	_, err := r.db.Conn().Exec(query, id)
 	if err != nil {
-		return fmt.Errorf("failed to mark message as sending: %w", err)
+		return fmt.Errorf("failed to mark message as pending: %w", err)
 	}
 
 	return nil
 }
 
-// MarkSent marks a message as successfully sent and stores the actual sent content.
-func (r *BatchMessageRepository) MarkSent(id int64, sentContent string) error {
+// UpdateContactName renames a contact on its messages that have not been sent yet,
+// so progress shows the name the contact has now. It returns the number of rows changed.
+func (r *BatchMessageRepository) UpdateContactName(jid, name string) (int, error) {
 	r.db.Lock()
 	defer r.db.Unlock()
 
 	query := `
 		UPDATE batch_messages
-		SET status = 'sent', sent_content = ?, sent_at = CURRENT_TIMESTAMP
+		SET contact_name = ?
+		WHERE jid = ? AND status = 'pending' AND (contact_name IS NULL OR contact_name != ?)
+	`
+	result, err := r.db.Conn().Exec(query, name, jid, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update contact name: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return int(affected), nil
+}
+
+// MarkSent marks a message as successfully sent and stores the actual sent
+// content. With the WhatsApp message ID its delivery is awaited, see MarkDelivered.
+func (r *BatchMessageRepository) MarkSent(id int64, sentContent, waMessageID string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	query := `
+		UPDATE batch_messages
+		SET status = 'sent', sent_content = ?, sent_at = CURRENT_TIMESTAMP,
+		    wa_message_id = NULLIF(?, ''),
+		    delivery_status = CASE WHEN ? = '' THEN NULL ELSE 'awaiting' END,
+		    delivered_at = NULL
 		WHERE id = ?
 	`
-	_, err := r.db.Conn().Exec(query, sentContent, id)
+	_, err := r.db.Conn().Exec(query, sentContent, waMessageID, waMessageID, id)
 	if err != nil {
 		return fmt.Errorf("failed to mark message as sent: %w", err)
 	}
@@ -340,17 +460,17 @@ func (r *BatchMessageRepository) MarkSent(id int64, sentContent string) error {
 	return nil
 }
 
-// MarkFailed marks a message as failed with an error message.
-func (r *BatchMessageRepository) MarkFailed(id int64, errorMessage string) error {
+// MarkFailed marks a message as failed with a failure code and the raw error message.
+func (r *BatchMessageRepository) MarkFailed(id int64, errorCode, errorMessage string) error {
 	r.db.Lock()
 	defer r.db.Unlock()
 
 	query := `
 		UPDATE batch_messages
-		SET status = 'failed', error_message = ?
+		SET status = 'failed', error_code = ?, error_message = ?, failed_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
-	_, err := r.db.Conn().Exec(query, errorMessage, id)
+	_, err := r.db.Conn().Exec(query, errorCode, errorMessage, id)
 	if err != nil {
 		return fmt.Errorf("failed to mark message as failed: %w", err)
 	}
@@ -358,6 +478,46 @@ func (r *BatchMessageRepository) MarkFailed(id int64, errorMessage string) error
 	return nil
 }
 
+// MarkFailedMultiple marks several pending messages as failed with the same error in one
+// transaction. Messages no longer pending are left untouched; returns how many were updated.
+func (r *BatchMessageRepository) MarkFailedMultiple(ids []int64, errorCode, errorMessage string) (int, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		UPDATE batch_messages
+		SET status = 'failed', error_code = ?, error_message = ?, failed_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'pending'
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	updated := 0
+	for _, id := range ids {
+		result, err := stmt.Exec(errorCode, errorMessage, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to mark message %d as failed: %w", id, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			updated++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return updated, nil
+}
+
 // GetPendingCount returns the number of pending messages for a batch run.
 func (r *BatchMessageRepository) GetPendingCount(batchRunID int64) (int, error) {
 	r.db.RLock()
@@ -377,21 +537,22 @@ func (r *BatchMessageRepository) GetPendingCount(batchRunID int64) (int, error)
 }
 
 // GetStats returns message counts by status for a batch run.
-func (r *BatchMessageRepository) GetStats(batchRunID int64) (pending, sending, sent, failed int, err error) {
+func (r *BatchMessageRepository) GetStats(batchRunID int64) (pending, sending, sent, failed, skipped int, err error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
 		SELECT
-			SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END) as pending,
-			SUM(CASE WHEN status = 'sending' THEN 1 ELSE 0 END) as sending,
-			SUM(CASE WHEN status = 'sent' THEN 1 ELSE 0 END) as sent,
-			SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END) as failed
+			COALESCE(SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END), 0) as pending,
+			COALESCE(SUM(CASE WHEN status = 'sending' THEN 1 ELSE 0 END), 0) as sending,
+			COALESCE(SUM(CASE WHEN status = 'sent' THEN 1 ELSE 0 END), 0) as sent,
+			COALESCE(SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END), 0) as failed,
+			COALESCE(SUM(CASE WHEN status = 'skipped' THEN 1 ELSE 0 END), 0) as skipped
 		FROM batch_messages
 		WHERE batch_run_id = ?
 	`
 
-	err = r.db.Conn().QueryRow(query, batchRunID).Scan(&pending, &sending, &sent, &failed)
+	err = r.db.Conn().QueryRow(query, batchRunID).Scan(&pending, &sending, &sent, &failed, &skipped)
 	if err != nil {
 		err = fmt.Errorf("failed to get message stats: %w", err)
 	}
@@ -406,9 +567,7 @@ func (r *BatchMessageRepository) GetRecentSent(batchRunID int64, limit int) ([]B
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, batch_run_id, jid, contact_name, status,
-		       template_content, sent_content, error_message,
-		       sent_at, created_at
+		SELECT ` + batchMessageColumns + `
 		FROM batch_messages
 		WHERE batch_run_id = ? AND status = 'sent'
 		ORDER BY sent_at DESC
@@ -424,44 +583,184 @@ func (r *BatchMessageRepository) GetRecentSent(batchRunID int64, limit int) ([]B
 	messages := []BatchMessage{}
 
 	for rows.Next() {
-		var msg BatchMessage
-		var contactName, sentContent, errorMessage sql.NullString
-		var sentAt sql.NullTime
-
-		if err := rows.Scan(
-			&msg.ID,
-			&msg.BatchRunID,
-			&msg.JID,
-			&contactName,
-			&msg.Status,
-			&msg.TemplateContent,
-			&sentContent,
-			&errorMessage,
-			&sentAt,
-			&msg.CreatedAt,
-		); err != nil {
+		msg, err := scanBatchMessage(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
+		messages = append(messages, *msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// ContactSendHistory counts the batch messages addressed to one contact.
+type ContactSendHistory struct {
+	Since      time.Time  `json:"since"` // The counts cover messages created from here on
+	Sent       int        `json:"sent"`
+	Failed     int        `json:"failed"`
+	Pending    int        `json:"pending"` // Including one being sent
+	Skipped    int        `json:"skipped"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"` // Over all time
+}
+
+// GetHistoryForContact counts jid's batch messages created since the given
+// time. It returns nil if the contact was never sent a batch message.
+func (r *BatchMessageRepository) GetHistoryForContact(jid string, since time.Time) (*ContactSendHistory, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
 
-		if contactName.Valid {
-			msg.ContactName = &contactName.String
+	from := since.UTC().Format(sqliteTimeLayout)
+	var total int
+	var lastSent sql.NullString
+	history := &ContactSendHistory{Since: since}
+	err := r.db.Conn().QueryRow(`
+		SELECT
+			COUNT(*),
+			MAX(sent_at),
+			COALESCE(SUM(CASE WHEN status = 'sent' AND created_at >= ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'failed' AND created_at >= ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status IN ('pending', 'sending') AND created_at >= ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'skipped' AND created_at >= ? THEN 1 ELSE 0 END), 0)
+		FROM batch_messages
+		WHERE jid = ?
+	`, from, from, from, from, jid).Scan(&total, &lastSent, &history.Sent, &history.Failed, &history.Pending, &history.Skipped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact send history: %w", err)
+	}
+	if total == 0 {
+		return nil, nil
+	}
+	if history.LastSentAt, err = parseAggregateTime(lastSent); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// LastSentTo returns when each of jids was last sent a batch message, for
+// those sent one since the given time, with one query per
+// attributeQueryChunkSize JIDs.
+func (r *BatchMessageRepository) LastSentTo(jids []string, since time.Time) (map[string]time.Time, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	lastSent := make(map[string]time.Time)
+	for start := 0; start < len(jids); start += attributeQueryChunkSize {
+		chunk := jids[start:min(start+attributeQueryChunkSize, len(jids))]
+		args := make([]interface{}, 0, len(chunk)+1)
+		args = append(args, since.UTC().Format(sqliteTimeLayout))
+		for _, jid := range chunk {
+			args = append(args, jid)
 		}
-		if sentContent.Valid {
-			msg.SentContent = &sentContent.String
+
+		rows, err := r.db.Conn().Query(`
+			SELECT jid, MAX(sent_at)
+			FROM batch_messages
+			WHERE status = 'sent' AND sent_at >= ? AND jid IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(chunk)), ", ")+`)
+			GROUP BY jid
+		`, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query recent sends: %w", err)
 		}
-		if errorMessage.Valid {
-			msg.ErrorMessage = &errorMessage.String
+		for rows.Next() {
+			var jid string
+			var at sql.NullString
+			if err := rows.Scan(&jid, &at); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan recent send: %w", err)
+			}
+			sentAt, err := parseAggregateTime(at)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if sentAt != nil {
+				lastSent[jid] = *sentAt
+			}
 		}
-		if sentAt.Valid {
-			msg.SentAt = &sentAt.Time
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating recent sends: %w", err)
 		}
-
-		messages = append(messages, msg)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating messages: %w", err)
+	return lastSent, nil
+}
+
+// SentCounts returns how many messages each of jids was sent since the given
+// time, for those sent any: sent batch messages plus sent outbox messages,
+// the single sends queued while WhatsApp was disconnected. Single sends made
+// while connected are not stored, so they are not counted.
+func (r *BatchMessageRepository) SentCounts(jids []string, since time.Time) (map[string]int, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	counts := make(map[string]int)
+	cutoff := since.UTC().Format(sqliteTimeLayout)
+	for start := 0; start < len(jids); start += attributeQueryChunkSize {
+		chunk := jids[start:min(start+attributeQueryChunkSize, len(jids))]
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(chunk)), ", ")
+		args := make([]interface{}, 0, 2*len(chunk)+2)
+		args = append(args, cutoff)
+		for _, jid := range chunk {
+			args = append(args, jid)
+		}
+		args = append(args, cutoff)
+		for _, jid := range chunk {
+			args = append(args, jid)
+		}
+
+		rows, err := r.db.Conn().Query(`
+			SELECT jid, COUNT(*)
+			FROM (
+				SELECT jid FROM batch_messages
+				WHERE status = 'sent' AND sent_at >= ? AND jid IN (`+placeholders+`)
+				UNION ALL
+				SELECT jid FROM outbox
+				WHERE status = 'sent' AND sent_at >= ? AND jid IN (`+placeholders+`)
+			)
+			GROUP BY jid
+		`, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count recent sends: %w", err)
+		}
+		for rows.Next() {
+			var jid string
+			var count int
+			if err := rows.Scan(&jid, &count); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan recent send count: %w", err)
+			}
+			counts[jid] = count
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating recent send counts: %w", err)
+		}
 	}
 
-	return messages, nil
+	return counts, nil
+}
+
+// LastContactName returns the contact name stored with jid's newest batch
+// message, or "" if none was stored.
+func (r *BatchMessageRepository) LastContactName(jid string) (string, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var name string
+	err := r.db.Conn().QueryRow(
+		"SELECT contact_name FROM batch_messages WHERE jid = ? AND contact_name IS NOT NULL ORDER BY id DESC LIMIT 1",
+		jid,
+	).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get stored contact name: %w", err)
+	}
+	return name, nil
 }
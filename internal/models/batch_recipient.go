@@ -0,0 +1,129 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"friday/internal/database"
+)
+
+// Recipient statuses recorded in a batch's member snapshot.
+const (
+	RecipientIncluded    = "included"    // A message was queued for the member
+	RecipientQuarantined = "quarantined" // Left out because the contact was quarantined
+	RecipientSelf        = "self"        // Left out because it is the linked account
+	RecipientBlocked     = "blocked"     // Left out because the linked account has blocked the contact
+	RecipientCapped      = "capped"      // Its message was created skipped because of batch.recipient_cap
+)
+
+// BatchRecipient is one group member as it was when the batch was created.
+// Unlike batch messages, the snapshot also lists members that were left out.
+type BatchRecipient struct {
+	ID          int64     `json:"id"`
+	BatchRunID  int64     `json:"batch_run_id"`
+	JID         string    `json:"jid"`
+	ContactName *string   `json:"contact_name,omitempty"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BatchRecipientRepository handles database operations for batch member snapshots.
+type BatchRecipientRepository struct {
+	db *database.DB
+}
+
+// NewBatchRecipientRepository creates a new batch recipient repository.
+func NewBatchRecipientRepository(db *database.DB) *BatchRecipientRepository {
+	return &BatchRecipientRepository{db: db}
+}
+
+// CreateMultiple stores a batch's member snapshot in a single transaction.
+func (r *BatchRecipientRepository) CreateMultiple(recipients []BatchRecipient) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertBatchRecipients(tx, recipients); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertBatchRecipients inserts recipients within tx and sets their IDs.
+func insertBatchRecipients(tx *sql.Tx, recipients []BatchRecipient) error {
+	stmt, err := tx.Prepare(`
+		INSERT INTO batch_recipients (batch_run_id, jid, contact_name, status, created_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := range recipients {
+		result, err := stmt.Exec(
+			recipients[i].BatchRunID,
+			recipients[i].JID,
+			recipients[i].ContactName,
+			recipients[i].Status,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create recipient %s: %w", recipients[i].JID, err)
+		}
+
+		id, _ := result.LastInsertId()
+		recipients[i].ID = id
+	}
+
+	return nil
+}
+
+// GetByBatch returns a batch's member snapshot in group order.
+func (r *BatchRecipientRepository) GetByBatch(batchRunID int64) ([]BatchRecipient, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := `
+		SELECT id, batch_run_id, jid, contact_name, status, created_at
+		FROM batch_recipients
+		WHERE batch_run_id = ?
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.Conn().Query(query, batchRunID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch recipients: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := []BatchRecipient{}
+
+	for rows.Next() {
+		var rec BatchRecipient
+		var contactName sql.NullString
+		if err := rows.Scan(&rec.ID, &rec.BatchRunID, &rec.JID, &contactName, &rec.Status, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan batch recipient: %w", err)
+		}
+		if contactName.Valid {
+			rec.ContactName = &contactName.String
+		}
+		recipients = append(recipients, rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating batch recipients: %w", err)
+	}
+
+	return recipients, nil
+}
@@ -2,12 +2,20 @@ package models
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"friday/internal/database"
+	"friday/internal/template"
 )
 
+// ErrHasBatches is returned when deleting a draft or group that batch runs
+// were created from; the runs must be deleted first.
+var ErrHasBatches = errors.New("batch runs refer to it")
+
 type BatchRunStatus string
 
 const (
@@ -16,24 +24,164 @@ const (
 	BatchStatusCompleted BatchRunStatus = "completed"
 	BatchStatusCancelled BatchRunStatus = "cancelled"
 	BatchStatusFailed    BatchRunStatus = "failed"
+
+	// BatchStatusInterrupted is a batch that was running when the server stopped
+	// and waits for POST /api/batch-runs/{id}/resume, see SettingAutoResumeBatches.
+	BatchStatusInterrupted BatchRunStatus = "interrupted"
+
+	// BatchStatusPreparing is a batch whose messages are still being created in
+	// the background, see BatchRunRepository.AddMessages; it is queued once they
+	// all exist.
+	BatchStatusPreparing BatchRunStatus = "preparing"
+)
+
+// MaxBatchLabelLength is the most characters a rendered batch label may have.
+const MaxBatchLabelLength = 200
+
+// DefaultBatchLabel is the label of a batch created without one.
+func DefaultBatchLabel(draftTitle, groupName string) string {
+	return draftTitle + " → " + groupName
+}
+
+// InterruptedSendError is recorded on messages that were being sent when the
+// server stopped; whether they reached WhatsApp is unknown.
+const InterruptedSendError = "Server stopped while sending; delivery unknown"
+
+// Batch sources record what created a batch. Automated creators use their own
+// prefix with an ID, e.g. "schedule:<id>".
+const (
+	BatchSourceWeb         = "web"         // The app's own pages
+	BatchSourceAPI         = "api"         // Any other HTTP client
+	BatchSourceIntegration = "integration" // Signed POST /api/integrations/trigger-batch
+	BatchSourceUnknown     = "unknown"     // Created before sources were recorded
+)
+
+// Batch sort orders: the sequence a batch's messages are created, and so sent, in.
+const (
+	BatchSortGroupOrder = "group_order" // The group's member order, see GroupMemberRepository.Reorder
+	BatchSortName       = "name"        // Contact name, then phone number
+	BatchSortRandom     = "random"      // Shuffled with the batch's SortSeed
 )
 
+// BatchSorts are the valid batch sort orders.
+var BatchSorts = []string{BatchSortGroupOrder, BatchSortName, BatchSortRandom}
+
 type BatchRun struct {
 	ID           int64          `json:"id"`
+	WorkspaceID  int64          `json:"workspace_id"` // Workspace of the draft and group at creation
 	DraftID      int64          `json:"draft_id"`
 	GroupID      int64          `json:"group_id"`
 	GroupName    string         `json:"group_name"`    // Snapshot at creation
 	DraftTitle   string         `json:"draft_title"`   // Snapshot at creation
+	Label        string         `json:"label"`         // Display name, rendered at creation; see DefaultBatchLabel
 	Status       BatchRunStatus `json:"status"`
 	TotalCount   int            `json:"total_count"`
 	SentCount    int            `json:"sent_count"`
 	FailedCount  int            `json:"failed_count"`
+	ValidationFailedCount int   `json:"validation_failed_count"` // Pre-failed: not on WhatsApp
+	SkippedCount int            `json:"skipped_count"` // Passed over on request or for the recipient cap; not counted as failed
+	QuarantinedCount int        `json:"quarantined_count"` // Group members left out because they are quarantined
+	SpinSeed     int64          `json:"spin_seed"` // Seeds spintax choices per recipient, see template.SpinSeed
+	Sort         string         `json:"sort"`      // Order the messages were created and are sent in, see BatchSortGroupOrder
+	SortSeed     int64          `json:"sort_seed,omitempty"` // Seed of the shuffle, for BatchSortRandom
+	Source       string         `json:"source"`                // What created the batch, see BatchSourceWeb
+	ClientName   *string        `json:"client_name,omitempty"` // Caller's own label, from X-Client-Name or client_name
+	DocumentAttribute string    `json:"document_attribute,omitempty"` // Attribute naming each recipient's document; the message is its caption
+	MinDelaySeconds int         `json:"min_delay_seconds,omitempty"` // Delay settings when the batch first started, kept across restarts; 0 before it started
+	MaxDelaySeconds int         `json:"max_delay_seconds,omitempty"`
+	ContentHash  string         `json:"content_hash,omitempty"` // Draft content, variants and delimiters at creation, see MessageDraft.ContentHash; empty for batches created before it was recorded
+	Delimiters   *template.Delimiters `json:"delimiters,omitempty"` // The draft's delimiters at creation, which messages are filled with; nil for batches created before they were recorded
+	LastSentAt   *time.Time     `json:"last_sent_at,omitempty"` // Last send attempt, so a restart waits out the rest of the delay
 	ErrorMessage *string        `json:"error_message,omitempty"`
 	StartedAt    *time.Time     `json:"started_at,omitempty"`
 	CompletedAt  *time.Time     `json:"completed_at,omitempty"`
 	CreatedAt    time.Time      `json:"created_at"`
 }
 
+// batchRunColumns is the column list shared by every batch run SELECT; keep it in
+// sync with scanBatchRun.
+const batchRunColumns = `id, workspace_id, draft_id, group_id, group_name, draft_title, status,
+		       total_count, sent_count, failed_count, validation_failed_count, skipped_count, quarantined_count,
+		       spin_seed, sort_order, sort_seed, source, client_name, label, document_attribute, min_delay_seconds, max_delay_seconds, last_sent_at,
+		       content_hash, placeholder_open, placeholder_close, error_message, started_at, completed_at, created_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBatchRun(row rowScanner) (*BatchRun, error) {
+	var run BatchRun
+	var errorMessage, clientName, label, documentAttribute, contentHash, placeholderOpen, placeholderClose sql.NullString
+	var minDelay, maxDelay sql.NullInt64
+	var startedAt, completedAt, lastSentAt sql.NullTime
+
+	if err := row.Scan(
+		&run.ID,
+		&run.WorkspaceID,
+		&run.DraftID,
+		&run.GroupID,
+		&run.GroupName,
+		&run.DraftTitle,
+		&run.Status,
+		&run.TotalCount,
+		&run.SentCount,
+		&run.FailedCount,
+		&run.ValidationFailedCount,
+		&run.SkippedCount,
+		&run.QuarantinedCount,
+		&run.SpinSeed,
+		&run.Sort,
+		&run.SortSeed,
+		&run.Source,
+		&clientName,
+		&label,
+		&documentAttribute,
+		&minDelay,
+		&maxDelay,
+		&lastSentAt,
+		&contentHash,
+		&placeholderOpen,
+		&placeholderClose,
+		&errorMessage,
+		&startedAt,
+		&completedAt,
+		&run.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if errorMessage.Valid {
+		run.ErrorMessage = &errorMessage.String
+	}
+	if clientName.Valid {
+		run.ClientName = &clientName.String
+	}
+	// Batches created without a label, or before labels existed, store none
+	run.Label = label.String
+	if !label.Valid {
+		run.Label = DefaultBatchLabel(run.DraftTitle, run.GroupName)
+	}
+	run.DocumentAttribute = documentAttribute.String
+	run.MinDelaySeconds = int(minDelay.Int64)
+	run.MaxDelaySeconds = int(maxDelay.Int64)
+	run.ContentHash = contentHash.String
+	if placeholderOpen.Valid && placeholderClose.Valid {
+		run.Delimiters = &template.Delimiters{Open: placeholderOpen.String, Close: placeholderClose.String}
+	}
+	if lastSentAt.Valid {
+		run.LastSentAt = &lastSentAt.Time
+	}
+	if startedAt.Valid {
+		run.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		run.CompletedAt = &completedAt.Time
+	}
+
+	return &run, nil
+}
+
 // BatchRunRepository handles database operations for batch runs.
 type BatchRunRepository struct {
 	db *database.DB
@@ -44,27 +192,63 @@ func NewBatchRunRepository(db *database.DB) *BatchRunRepository {
 	return &BatchRunRepository{db: db}
 }
 
-// Create inserts a new batch run into the database.
-func (r *BatchRunRepository) Create(run *BatchRun) error {
+// CreateWithMessages inserts a new batch run together with its messages and
+// recipient snapshot in one transaction, so the worker never sees a run whose
+// messages are missing. The new run's ID is set on the messages and recipients.
+func (r *BatchRunRepository) CreateWithMessages(run *BatchRun, messages []BatchMessage, recipients []BatchRecipient) error {
 	r.db.Lock()
 	defer r.db.Unlock()
 
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if run.WorkspaceID == 0 {
+		run.WorkspaceID = DefaultWorkspaceID
+	}
+	if run.Sort == "" {
+		run.Sort = BatchSortGroupOrder
+	}
+
 	query := `
 		INSERT INTO batch_runs (
-			draft_id, group_id, group_name, draft_title, status,
-			total_count, sent_count, failed_count, created_at
+			workspace_id, draft_id, group_id, group_name, draft_title, status,
+			total_count, sent_count, failed_count, skipped_count, quarantined_count, spin_seed,
+			sort_order, sort_seed, source, client_name, label, document_attribute,
+			content_hash, placeholder_open, placeholder_close, created_at
 		)
-		VALUES (?, ?, ?, ?, ?, ?, 0, 0, CURRENT_TIMESTAMP)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 0, 0, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 	`
 
-	result, err := r.db.Conn().Exec(
+	var placeholderOpen, placeholderClose sql.NullString
+	if run.Delimiters != nil {
+		placeholderOpen = sql.NullString{String: run.Delimiters.Open, Valid: true}
+		placeholderClose = sql.NullString{String: run.Delimiters.Close, Valid: true}
+	}
+
+	result, err := tx.Exec(
 		query,
+		run.WorkspaceID,
 		run.DraftID,
 		run.GroupID,
 		run.GroupName,
 		run.DraftTitle,
 		run.Status,
 		run.TotalCount,
+		run.SkippedCount,
+		run.QuarantinedCount,
+		run.SpinSeed,
+		run.Sort,
+		run.SortSeed,
+		run.Source,
+		run.ClientName,
+		sql.NullString{String: run.Label, Valid: run.Label != ""},
+		sql.NullString{String: run.DocumentAttribute, Valid: run.DocumentAttribute != ""},
+		sql.NullString{String: run.ContentHash, Valid: run.ContentHash != ""},
+		placeholderOpen,
+		placeholderClose,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create batch run: %w", err)
@@ -75,12 +259,22 @@ func (r *BatchRunRepository) Create(run *BatchRun) error {
 		return fmt.Errorf("failed to get last insert ID: %w", err)
 	}
 
-	run.ID = id
-	run.SentCount = 0
-	run.FailedCount = 0
+	for i := range messages {
+		messages[i].BatchRunID = id
+	}
+	if err := insertBatchMessages(tx, messages); err != nil {
+		return err
+	}
+
+	for i := range recipients {
+		recipients[i].BatchRunID = id
+	}
+	if err := insertBatchRecipients(tx, recipients); err != nil {
+		return err
+	}
 
 	// Fetch the timestamp
-	row := r.db.Conn().QueryRow(
+	row := tx.QueryRow(
 		"SELECT created_at FROM batch_runs WHERE id = ?",
 		id,
 	)
@@ -88,42 +282,108 @@ func (r *BatchRunRepository) Create(run *BatchRun) error {
 		run.CreatedAt = time.Now()
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	run.ID = id
+	run.SentCount = 0
+	run.FailedCount = 0
+	if run.Label == "" {
+		run.Label = DefaultBatchLabel(run.DraftTitle, run.GroupName)
+	}
+
 	return nil
 }
 
-// GetByID retrieves a single batch run by ID.
+// AddMessages adds a chunk of messages and recipient snapshot rows to a batch
+// run created in BatchStatusPreparing, in one transaction. It returns false,
+// adding nothing, once the run is no longer preparing, e.g. was cancelled.
+func (r *BatchRunRepository) AddMessages(id int64, messages []BatchMessage, recipients []BatchRecipient) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status BatchRunStatus
+	err = tx.QueryRow("SELECT status FROM batch_runs WHERE id = ?", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get batch status: %w", err)
+	}
+	if status != BatchStatusPreparing {
+		return false, nil
+	}
+
+	for i := range messages {
+		messages[i].BatchRunID = id
+	}
+	if err := insertBatchMessages(tx, messages); err != nil {
+		return false, err
+	}
+	for i := range recipients {
+		recipients[i].BatchRunID = id
+	}
+	if err := insertBatchRecipients(tx, recipients); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteMessages removes every message and recipient snapshot row of a batch
+// run, as when it is cancelled or fails before its preparation finished. The
+// run itself is kept. Returns how many messages were removed.
+func (r *BatchRunRepository) DeleteMessages(id int64) (int64, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM batch_messages WHERE batch_run_id = ?", id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete batch messages: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM batch_recipients WHERE batch_run_id = ?", id); err != nil {
+		return 0, fmt.Errorf("failed to delete batch recipients: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return deleted, nil
+}
+
+// GetByID retrieves a single batch run by ID, in any workspace; requests use
+// GetInWorkspace.
 func (r *BatchRunRepository) GetByID(id int64) (*BatchRun, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, draft_id, group_id, group_name, draft_title, status,
-		       total_count, sent_count, failed_count, error_message,
-		       started_at, completed_at, created_at
+		SELECT ` + batchRunColumns + `
 		FROM batch_runs
 		WHERE id = ?
 	`
 
-	var run BatchRun
-	var errorMessage sql.NullString
-	var startedAt, completedAt sql.NullTime
-
-	err := r.db.Conn().QueryRow(query, id).Scan(
-		&run.ID,
-		&run.DraftID,
-		&run.GroupID,
-		&run.GroupName,
-		&run.DraftTitle,
-		&run.Status,
-		&run.TotalCount,
-		&run.SentCount,
-		&run.FailedCount,
-		&errorMessage,
-		&startedAt,
-		&completedAt,
-		&run.CreatedAt,
-	)
-
+	run, err := scanBatchRun(r.db.Conn().QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -131,74 +391,115 @@ func (r *BatchRunRepository) GetByID(id int64) (*BatchRun, error) {
 		return nil, fmt.Errorf("failed to get batch run: %w", err)
 	}
 
-	if errorMessage.Valid {
-		run.ErrorMessage = &errorMessage.String
+	return run, nil
+}
+
+// GetInWorkspace retrieves a batch run, or nil if there is none with that ID in the workspace.
+func (r *BatchRunRepository) GetInWorkspace(workspaceID, id int64) (*BatchRun, error) {
+	run, err := r.GetByID(id)
+	if err != nil || run == nil || run.WorkspaceID != workspaceID {
+		return nil, err
 	}
-	if startedAt.Valid {
-		run.StartedAt = &startedAt.Time
+	return run, nil
+}
+
+// GetAll retrieves the batch runs of a workspace, ordered by most recently
+// created. A non-empty source returns only the batches created by it.
+func (r *BatchRunRepository) GetAll(workspaceID int64, source string) ([]BatchRun, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := `
+		SELECT ` + batchRunColumns + `
+		FROM batch_runs
+		WHERE workspace_id = ? AND (? = '' OR source = ?)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Conn().Query(query, workspaceID, source, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch runs: %w", err)
 	}
-	if completedAt.Valid {
-		run.CompletedAt = &completedAt.Time
+	defer rows.Close()
+
+	runs := []BatchRun{}
+
+	for rows.Next() {
+		run, err := scanBatchRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch run: %w", err)
+		}
+		runs = append(runs, *run)
 	}
 
-	return &run, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating batch runs: %w", err)
+	}
+
+	return runs, nil
 }
 
-// GetAll retrieves all batch runs, ordered by most recently created.
-func (r *BatchRunRepository) GetAll() ([]BatchRun, error) {
+// GetRunning returns the running batch runs in the order they started.
+func (r *BatchRunRepository) GetRunning() ([]BatchRun, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, draft_id, group_id, group_name, draft_title, status,
-		       total_count, sent_count, failed_count, error_message,
-		       started_at, completed_at, created_at
+		SELECT ` + batchRunColumns + `
 		FROM batch_runs
-		ORDER BY created_at DESC
+		WHERE status = 'running'
+		ORDER BY started_at ASC, id ASC
 	`
 
 	rows, err := r.db.Conn().Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query batch runs: %w", err)
+		return nil, fmt.Errorf("failed to query running batch runs: %w", err)
 	}
 	defer rows.Close()
 
 	runs := []BatchRun{}
 
 	for rows.Next() {
-		var run BatchRun
-		var errorMessage sql.NullString
-		var startedAt, completedAt sql.NullTime
-
-		if err := rows.Scan(
-			&run.ID,
-			&run.DraftID,
-			&run.GroupID,
-			&run.GroupName,
-			&run.DraftTitle,
-			&run.Status,
-			&run.TotalCount,
-			&run.SentCount,
-			&run.FailedCount,
-			&errorMessage,
-			&startedAt,
-			&completedAt,
-			&run.CreatedAt,
-		); err != nil {
+		run, err := scanBatchRun(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan batch run: %w", err)
 		}
+		runs = append(runs, *run)
+	}
 
-		if errorMessage.Valid {
-			run.ErrorMessage = &errorMessage.String
-		}
-		if startedAt.Valid {
-			run.StartedAt = &startedAt.Time
-		}
-		if completedAt.Valid {
-			run.CompletedAt = &completedAt.Time
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating batch runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// GetQueued returns the queued batch runs in the order GetNextQueued hands them out.
+func (r *BatchRunRepository) GetQueued() ([]BatchRun, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := `
+		SELECT ` + batchRunColumns + `
+		FROM batch_runs
+		WHERE status = 'queued'
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Conn().Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queued batch runs: %w", err)
+	}
+	defer rows.Close()
 
-		runs = append(runs, run)
+	runs := []BatchRun{}
+
+	for rows.Next() {
+		run, err := scanBatchRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch run: %w", err)
+		}
+		runs = append(runs, *run)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -208,58 +509,75 @@ func (r *BatchRunRepository) GetAll() ([]BatchRun, error) {
 	return runs, nil
 }
 
-// GetActive returns the currently running batch run, if any.
-func (r *BatchRunRepository) GetActive() (*BatchRun, error) {
+// GetPreparing returns the batch runs whose messages were still being created,
+// oldest first.
+func (r *BatchRunRepository) GetPreparing() ([]BatchRun, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, draft_id, group_id, group_name, draft_title, status,
-		       total_count, sent_count, failed_count, error_message,
-		       started_at, completed_at, created_at
+		SELECT ` + batchRunColumns + `
 		FROM batch_runs
-		WHERE status = 'running'
-		LIMIT 1
+		WHERE status = 'preparing'
+		ORDER BY created_at ASC, id ASC
 	`
 
-	var run BatchRun
-	var errorMessage sql.NullString
-	var startedAt, completedAt sql.NullTime
+	rows, err := r.db.Conn().Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query preparing batch runs: %w", err)
+	}
+	defer rows.Close()
 
-	err := r.db.Conn().QueryRow(query).Scan(
-		&run.ID,
-		&run.DraftID,
-		&run.GroupID,
-		&run.GroupName,
-		&run.DraftTitle,
-		&run.Status,
-		&run.TotalCount,
-		&run.SentCount,
-		&run.FailedCount,
-		&errorMessage,
-		&startedAt,
-		&completedAt,
-		&run.CreatedAt,
-	)
+	runs := []BatchRun{}
 
-	if err == sql.ErrNoRows {
-		return nil, nil
+	for rows.Next() {
+		run, err := scanBatchRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch run: %w", err)
+		}
+		runs = append(runs, *run)
 	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get active batch run: %w", err)
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating batch runs: %w", err)
 	}
 
-	if errorMessage.Valid {
-		run.ErrorMessage = &errorMessage.String
+	return runs, nil
+}
+
+// GetInterrupted returns the interrupted batch runs, oldest first.
+func (r *BatchRunRepository) GetInterrupted() ([]BatchRun, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := `
+		SELECT ` + batchRunColumns + `
+		FROM batch_runs
+		WHERE status = 'interrupted'
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Conn().Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interrupted batch runs: %w", err)
 	}
-	if startedAt.Valid {
-		run.StartedAt = &startedAt.Time
+	defer rows.Close()
+
+	runs := []BatchRun{}
+
+	for rows.Next() {
+		run, err := scanBatchRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch run: %w", err)
+		}
+		runs = append(runs, *run)
 	}
-	if completedAt.Valid {
-		run.CompletedAt = &completedAt.Time
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating batch runs: %w", err)
 	}
 
-	return &run, nil
+	return runs, nil
 }
 
 // GetNextQueued returns the oldest queued batch run (FIFO order).
@@ -268,35 +586,14 @@ func (r *BatchRunRepository) GetNextQueued() (*BatchRun, error) {
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, draft_id, group_id, group_name, draft_title, status,
-		       total_count, sent_count, failed_count, error_message,
-		       started_at, completed_at, created_at
+		SELECT ` + batchRunColumns + `
 		FROM batch_runs
 		WHERE status = 'queued'
-		ORDER BY created_at ASC
+		ORDER BY created_at ASC, id ASC
 		LIMIT 1
 	`
 
-	var run BatchRun
-	var errorMessage sql.NullString
-	var startedAt, completedAt sql.NullTime
-
-	err := r.db.Conn().QueryRow(query).Scan(
-		&run.ID,
-		&run.DraftID,
-		&run.GroupID,
-		&run.GroupName,
-		&run.DraftTitle,
-		&run.Status,
-		&run.TotalCount,
-		&run.SentCount,
-		&run.FailedCount,
-		&errorMessage,
-		&startedAt,
-		&completedAt,
-		&run.CreatedAt,
-	)
-
+	run, err := scanBatchRun(r.db.Conn().QueryRow(query))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -304,142 +601,196 @@ func (r *BatchRunRepository) GetNextQueued() (*BatchRun, error) {
 		return nil, fmt.Errorf("failed to get next queued batch run: %w", err)
 	}
 
-	if errorMessage.Valid {
-		run.ErrorMessage = &errorMessage.String
-	}
-	if startedAt.Valid {
-		run.StartedAt = &startedAt.Time
-	}
-	if completedAt.Valid {
-		run.CompletedAt = &completedAt.Time
-	}
+	return run, nil
+}
 
-	return &run, nil
+// batchTransitions are the status changes a batch run may go through; every
+// change of status is one of them, made with Transition or a method built on it.
+var batchTransitions = map[BatchRunStatus][]BatchRunStatus{
+	BatchStatusQueued: {BatchStatusRunning, BatchStatusCancelled, BatchStatusFailed},
+	// Running to running is a batch started again in place after a restart, see Start
+	BatchStatusRunning:     {BatchStatusRunning, BatchStatusCompleted, BatchStatusCancelled, BatchStatusFailed, BatchStatusInterrupted},
+	BatchStatusInterrupted: {BatchStatusQueued, BatchStatusCancelled},
+	BatchStatusPreparing:   {BatchStatusQueued, BatchStatusCancelled, BatchStatusFailed},
 }
 
-// UpdateStatus changes the status of a batch run.
-func (r *BatchRunRepository) UpdateStatus(id int64, status BatchRunStatus) error {
-	r.db.Lock()
-	defer r.db.Unlock()
+// CanTransition reports whether a batch run in status from may move to status to.
+func CanTransition(from, to BatchRunStatus) bool {
+	return slices.Contains(batchTransitions[from], to)
+}
 
-	query := `UPDATE batch_runs SET status = ? WHERE id = ?`
-	_, err := r.db.Conn().Exec(query, status, id)
-	if err != nil {
-		return fmt.Errorf("failed to update batch status: %w", err)
-	}
+// IsFinal reports whether a batch run in this status can no longer change.
+func (s BatchRunStatus) IsFinal() bool {
+	return len(batchTransitions[s]) == 0
+}
 
-	return nil
+// Transition moves a batch run from one status to another, only if it is still
+// in from: concurrent changes, such as a cancel racing the batch's completion,
+// cannot both apply. It returns false, changing nothing, when the run was no
+// longer in from, so the caller lost the race and should say so. Moving to a
+// final status sets completed_at.
+func (r *BatchRunRepository) Transition(id int64, from, to BatchRunStatus) (bool, error) {
+	return r.transition(id, from, to, "")
 }
 
-// Start marks a batch run as running and sets the started_at timestamp.
-func (r *BatchRunRepository) Start(id int64) error {
+// transition is Transition with more columns to set, as "column = ?, ..." with
+// args for the placeholders.
+func (r *BatchRunRepository) transition(id int64, from, to BatchRunStatus, set string, args ...any) (bool, error) {
+	if !CanTransition(from, to) {
+		return false, fmt.Errorf("batch run cannot go from %s to %s", from, to)
+	}
+	if to.IsFinal() {
+		set += ", completed_at = CURRENT_TIMESTAMP"
+	}
+
 	r.db.Lock()
 	defer r.db.Unlock()
 
-	query := `
-		UPDATE batch_runs
-		SET status = 'running', started_at = CURRENT_TIMESTAMP
-		WHERE id = ?
-	`
-	_, err := r.db.Conn().Exec(query, id)
+	query := `UPDATE batch_runs SET status = ?` + set + ` WHERE id = ? AND status = ?`
+	result, err := r.db.Conn().Exec(query, append(append([]any{to}, args...), id, from)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to set batch run %s: %w", to, err)
+	}
+	n, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to start batch run: %w", err)
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
 	}
+	return n > 0, nil
+}
 
-	return nil
+// Start marks a queued batch run as running, or a running one found at startup
+// as started again, and sets the started_at timestamp. The delay bounds are
+// stored the first time it starts; a resumed batch keeps them. It returns false
+// if the run left from in the meantime, e.g. was cancelled.
+func (r *BatchRunRepository) Start(id int64, from BatchRunStatus, minDelaySeconds, maxDelaySeconds int) (bool, error) {
+	return r.transition(id, from, BatchStatusRunning, `, started_at = CURRENT_TIMESTAMP,
+		    min_delay_seconds = COALESCE(min_delay_seconds, ?),
+		    max_delay_seconds = COALESCE(max_delay_seconds, ?)`, minDelaySeconds, maxDelaySeconds)
 }
 
-// Complete marks a batch run as completed and sets the completed_at timestamp.
-func (r *BatchRunRepository) Complete(id int64) error {
-	r.db.Lock()
-	defer r.db.Unlock()
+// Complete marks a running batch run as completed. It returns false if the run
+// was no longer running, e.g. was cancelled first.
+func (r *BatchRunRepository) Complete(id int64) (bool, error) {
+	return r.Transition(id, BatchStatusRunning, BatchStatusCompleted)
+}
 
-	query := `
-		UPDATE batch_runs
-		SET status = 'completed', completed_at = CURRENT_TIMESTAMP
-		WHERE id = ?
-	`
-	_, err := r.db.Conn().Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("failed to complete batch run: %w", err)
-	}
+// Interrupt holds a running batch run until it is resumed. It returns false if
+// the run was not running.
+func (r *BatchRunRepository) Interrupt(id int64) (bool, error) {
+	return r.Transition(id, BatchStatusRunning, BatchStatusInterrupted)
+}
 
-	return nil
+// Requeue puts an interrupted batch run back in the queue. It keeps its
+// created_at, so it starts ahead of batches created after it. It returns false
+// if the run was not interrupted.
+func (r *BatchRunRepository) Requeue(id int64) (bool, error) {
+	return r.Transition(id, BatchStatusInterrupted, BatchStatusQueued)
 }
 
-// Cancel marks a batch run as cancelled.
-func (r *BatchRunRepository) Cancel(id int64) error {
+// CancelInterrupted cancels an interrupted batch run and settles its counts:
+// messages left mid-send are failed with errorCode and InterruptedSendError,
+// and sent_count and failed_count are recounted from the messages, since the
+// server may have stopped between updating a message and its run. It returns
+// false if the run was not interrupted.
+func (r *BatchRunRepository) CancelInterrupted(id int64, errorCode string) (bool, error) {
 	r.db.Lock()
 	defer r.db.Unlock()
 
-	query := `
-		UPDATE batch_runs
-		SET status = 'cancelled', completed_at = CURRENT_TIMESTAMP
-		WHERE id = ? AND status IN ('queued', 'running')
-	`
-	_, err := r.db.Conn().Exec(query, id)
+	tx, err := r.db.Conn().Begin()
 	if err != nil {
-		return fmt.Errorf("failed to cancel batch run: %w", err)
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	return nil
-}
+	var status BatchRunStatus
+	err = tx.QueryRow("SELECT status FROM batch_runs WHERE id = ?", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get batch status: %w", err)
+	}
+	if status != BatchStatusInterrupted {
+		return false, nil
+	}
 
-// Fail marks a batch run as failed with an error message.
-func (r *BatchRunRepository) Fail(id int64, errorMessage string) error {
-	r.db.Lock()
-	defer r.db.Unlock()
+	_, err = tx.Exec(`
+		UPDATE batch_messages
+		SET status = 'failed', error_code = ?, error_message = ?, failed_at = CURRENT_TIMESTAMP
+		WHERE batch_run_id = ? AND status = 'sending'
+	`, errorCode, InterruptedSendError, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to fail interrupted messages: %w", err)
+	}
 
-	query := `
+	_, err = tx.Exec(`
 		UPDATE batch_runs
-		SET status = 'failed', error_message = ?, completed_at = CURRENT_TIMESTAMP
-		WHERE id = ?
-	`
-	_, err := r.db.Conn().Exec(query, errorMessage, id)
+		SET status = 'cancelled', completed_at = CURRENT_TIMESTAMP,
+		    sent_count = (SELECT COUNT(*) FROM batch_messages WHERE batch_run_id = ? AND status = 'sent'),
+		    failed_count = (SELECT COUNT(*) FROM batch_messages WHERE batch_run_id = ? AND status = 'failed')
+		WHERE id = ? AND status = 'interrupted'
+	`, id, id, id)
 	if err != nil {
-		return fmt.Errorf("failed to fail batch run: %w", err)
+		return false, fmt.Errorf("failed to cancel batch run: %w", err)
 	}
 
-	return nil
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
 }
 
-// IncrementSentCount increments the sent_count by 1.
-func (r *BatchRunRepository) IncrementSentCount(id int64) error {
+// Fail marks a batch run in status from as failed with an error message. It
+// returns false if the run left from in the meantime.
+func (r *BatchRunRepository) Fail(id int64, from BatchRunStatus, errorMessage string) (bool, error) {
+	return r.transition(id, from, BatchStatusFailed, ", error_message = ?", errorMessage)
+}
+
+// AddCounts adds to the sent, failed and validation-failed counts of a batch
+// run. Messages pre-failed by validation count towards failed as well, so
+// callers pass them in both.
+func (r *BatchRunRepository) AddCounts(id int64, sent, failed, validationFailed int) error {
 	r.db.Lock()
 	defer r.db.Unlock()
 
-	query := `UPDATE batch_runs SET sent_count = sent_count + 1 WHERE id = ?`
-	_, err := r.db.Conn().Exec(query, id)
+	query := `
+		UPDATE batch_runs
+		SET sent_count = sent_count + ?,
+		    failed_count = failed_count + ?,
+		    validation_failed_count = validation_failed_count + ?
+		WHERE id = ?
+	`
+	_, err := r.db.Conn().Exec(query, sent, failed, validationFailed, id)
 	if err != nil {
-		return fmt.Errorf("failed to increment sent count: %w", err)
+		return fmt.Errorf("failed to update batch counts: %w", err)
 	}
 
 	return nil
 }
 
-// IncrementFailedCount increments the failed_count by 1.
-func (r *BatchRunRepository) IncrementFailedCount(id int64) error {
+// SetLastSentAt records when the batch last handed a message to WhatsApp.
+func (r *BatchRunRepository) SetLastSentAt(id int64, at time.Time) error {
 	r.db.Lock()
 	defer r.db.Unlock()
 
-	query := `UPDATE batch_runs SET failed_count = failed_count + 1 WHERE id = ?`
-	_, err := r.db.Conn().Exec(query, id)
+	_, err := r.db.Conn().Exec(`UPDATE batch_runs SET last_sent_at = ? WHERE id = ?`, at.UTC().Format(sqliteTimeLayout), id)
 	if err != nil {
-		return fmt.Errorf("failed to increment failed count: %w", err)
+		return fmt.Errorf("failed to record last send: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes a batch run by ID (only if not running).
-func (r *BatchRunRepository) Delete(id int64) (bool, error) {
+// Delete removes a batch run of the workspace by ID (only if not running).
+func (r *BatchRunRepository) Delete(workspaceID, id int64) (bool, error) {
 	r.db.Lock()
 	defer r.db.Unlock()
 
 	// Only delete if not currently running
 	result, err := r.db.Conn().Exec(
-		"DELETE FROM batch_runs WHERE id = ? AND status != 'running'",
-		id,
+		"DELETE FROM batch_runs WHERE id = ? AND workspace_id = ? AND status != 'running'",
+		id, workspaceID,
 	)
 	if err != nil {
 		return false, fmt.Errorf("failed to delete batch run: %w", err)
@@ -453,6 +804,29 @@ func (r *BatchRunRepository) Delete(id int64) (bool, error) {
 	return rowsAffected > 0, nil
 }
 
+// DeleteFinishedBefore deletes completed, cancelled and failed batch runs that
+// finished before cutoff, with their messages, events and recipient snapshots.
+// Returns how many runs were deleted.
+func (r *BatchRunRepository) DeleteFinishedBefore(cutoff time.Time) (int, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec(`
+		DELETE FROM batch_runs
+		WHERE status IN ('completed', 'cancelled', 'failed')
+		  AND COALESCE(completed_at, created_at) < ?
+	`, cutoff.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune batch runs: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rows), nil
+}
+
 // GetQueuedCount returns the number of queued batch runs.
 func (r *BatchRunRepository) GetQueuedCount() (int, error) {
 	r.db.RLock()
@@ -469,3 +843,83 @@ func (r *BatchRunRepository) GetQueuedCount() (int, error) {
 
 	return count, nil
 }
+
+// HasPendingForDraft reports whether a preparing, queued, running or interrupted batch references the draft.
+func (r *BatchRunRepository) HasPendingForDraft(draftID int64) (bool, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var exists int
+	err := r.db.Conn().QueryRow(
+		"SELECT 1 FROM batch_runs WHERE draft_id = ? AND status IN ('preparing', 'queued', 'running', 'interrupted') LIMIT 1",
+		draftID,
+	).Scan(&exists)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check batches for draft: %w", err)
+	}
+
+	return true, nil
+}
+
+// RunningForJIDs returns the IDs of running batches that message any of jids.
+func (r *BatchRunRepository) RunningForJIDs(jids ...string) ([]int64, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(jids)), ", ")
+	args := make([]interface{}, len(jids))
+	for i, jid := range jids {
+		args[i] = jid
+	}
+
+	rows, err := r.db.Conn().Query(`
+		SELECT DISTINCT r.id FROM batch_runs r
+		JOIN batch_messages m ON m.batch_run_id = r.id
+		WHERE r.status = 'running' AND m.jid IN (`+placeholders+`)
+		ORDER BY r.id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check running batches: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan batch run ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating batch runs: %w", err)
+	}
+
+	return ids, nil
+}
+
+// HasPendingForGroup reports whether a preparing, queued, running or interrupted batch references the group.
+func (r *BatchRunRepository) HasPendingForGroup(groupID int64) (bool, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var exists int
+	err := r.db.Conn().QueryRow(
+		"SELECT 1 FROM batch_runs WHERE group_id = ? AND status IN ('preparing', 'queued', 'running', 'interrupted') LIMIT 1",
+		groupID,
+	).Scan(&exists)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check batches for group: %w", err)
+	}
+
+	return true, nil
+}
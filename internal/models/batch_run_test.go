@@ -0,0 +1,39 @@
+package models
+
+import "testing"
+
+// TestCreateWithMessagesRollsBack fails batch creation after the run and its
+// messages are inserted; nothing of the batch may be left behind.
+func TestCreateWithMessagesRollsBack(t *testing.T) {
+	db := newTestDB(t)
+	draft := &MessageDraft{Title: "Welcome", Content: "Hello"}
+	if err := NewDraftRepository(db).Create(draft); err != nil {
+		t.Fatalf("failed to create draft: %v", err)
+	}
+	group := &ContactGroup{Name: "Customers"}
+	if err := NewGroupRepository(db).Create(group); err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+
+	jid := "15550000001@s.whatsapp.net"
+	run := &BatchRun{DraftID: draft.ID, GroupID: group.ID, Status: BatchStatusQueued, TotalCount: 2}
+	messages := []BatchMessage{
+		{JID: jid, Status: MessageStatusPending, TemplateContent: draft.Content},
+		{JID: "15550000002@s.whatsapp.net", Status: MessageStatusPending, TemplateContent: draft.Content},
+	}
+	// A recipient listed twice breaks UNIQUE(batch_run_id, jid) on the last insert
+	recipients := []BatchRecipient{{JID: jid, Status: RecipientIncluded}, {JID: jid, Status: RecipientIncluded}}
+	if err := NewBatchRunRepository(db).CreateWithMessages(run, messages, recipients); err == nil {
+		t.Fatal("creating a batch with a repeated recipient succeeded, want an error")
+	}
+
+	for _, table := range []string{"batch_runs", "batch_messages", "batch_recipients"} {
+		var count int
+		if err := db.Conn().QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			t.Fatalf("failed to count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("%s has %d rows after the failed creation, want 0", table, count)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"friday/internal/database"
@@ -25,11 +26,24 @@ func NewAttributeRepository(db *database.DB) *AttributeRepository {
 	return &AttributeRepository{db: db}
 }
 
-// Set creates or updates an attribute for a contact (upsert).
-func (r *AttributeRepository) Set(jid, key, value string) error {
+// Set creates or updates an attribute for a contact (upsert). A changed value is
+// recorded in the attribute history with the given source.
+func (r *AttributeRepository) Set(jid, key, value, source string) error {
 	r.db.Lock()
 	defer r.db.Unlock()
 
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldValue sql.NullString
+	err = tx.QueryRow("SELECT value FROM contact_attributes WHERE jid = ? AND key = ?", jid, key).Scan(&oldValue)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read attribute: %w", err)
+	}
+
 	query := `
 		INSERT INTO contact_attributes (jid, key, value, created_at, updated_at)
 		VALUES (?, ?, ?,
@@ -44,11 +58,22 @@ func (r *AttributeRepository) Set(jid, key, value string) error {
 			updated_at = CURRENT_TIMESTAMP
 	`
 
-	_, err := r.db.Conn().Exec(query, jid, key, value, jid, key)
+	_, err = tx.Exec(query, jid, key, value, jid, key)
 	if err != nil {
 		return fmt.Errorf("failed to set attribute: %w", err)
 	}
 
+	if !oldValue.Valid || oldValue.String != value {
+		newValue := sql.NullString{String: value, Valid: true}
+		if err := recordAttributeChange(tx, jid, key, oldValue, newValue, source); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -138,39 +163,93 @@ func (r *AttributeRepository) GetAllForContactAsMap(jid string) (map[string]stri
 	return result, nil
 }
 
-func (r *AttributeRepository) Delete(jid, key string) (bool, error) {
+// Delete removes an attribute, recording the deletion in the attribute history.
+// It reports whether the attribute existed.
+func (r *AttributeRepository) Delete(jid, key, source string) (bool, error) {
 	r.db.Lock()
 	defer r.db.Unlock()
 
-	result, err := r.db.Conn().Exec(
-		"DELETE FROM contact_attributes WHERE jid = ? AND key = ?",
-		jid, key,
-	)
+	tx, err := r.db.Conn().Begin()
 	if err != nil {
-		return false, fmt.Errorf("failed to delete attribute: %w", err)
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	var oldValue sql.NullString
+	err = tx.QueryRow("SELECT value FROM contact_attributes WHERE jid = ? AND key = ?", jid, key).Scan(&oldValue)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
 	if err != nil {
-		return false, fmt.Errorf("failed to get rows affected: %w", err)
+		return false, fmt.Errorf("failed to read attribute: %w", err)
+	}
+
+	_, err = tx.Exec("DELETE FROM contact_attributes WHERE jid = ? AND key = ?", jid, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete attribute: %w", err)
 	}
 
-	return rowsAffected > 0, nil
+	if err := recordAttributeChange(tx, jid, key, oldValue, sql.NullString{}, source); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
 }
 
-func (r *AttributeRepository) DeleteAllForContact(jid string) error {
+// DeleteAllForContact removes every attribute of a contact, recording each
+// deletion in the attribute history. It returns how many were removed.
+func (r *AttributeRepository) DeleteAllForContact(jid, source string) (int, error) {
 	r.db.Lock()
 	defer r.db.Unlock()
 
-	_, err := r.db.Conn().Exec(
-		"DELETE FROM contact_attributes WHERE jid = ?",
-		jid,
-	)
+	tx, err := r.db.Conn().Begin()
 	if err != nil {
-		return fmt.Errorf("failed to delete attributes: %w", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	return nil
+	rows, err := tx.Query("SELECT key, value FROM contact_attributes WHERE jid = ? ORDER BY key ASC", jid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query attributes: %w", err)
+	}
+	var keys, values []string
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan attribute: %w", err)
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating attributes: %w", err)
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec("DELETE FROM contact_attributes WHERE jid = ?", jid); err != nil {
+		return 0, fmt.Errorf("failed to delete attributes: %w", err)
+	}
+
+	for i, key := range keys {
+		oldValue := sql.NullString{String: values[i], Valid: true}
+		if err := recordAttributeChange(tx, jid, key, oldValue, sql.NullString{}, source); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(keys), nil
 }
 
 // GetAllUniqueKeys returns all unique attribute keys used across all contacts.
@@ -207,6 +286,143 @@ func (r *AttributeRepository) GetAllUniqueKeys() ([]string, error) {
 	return keys, nil
 }
 
+// GetAllJIDs returns every contact that has at least one attribute, ordered by JID.
+func (r *AttributeRepository) GetAllJIDs() ([]string, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	rows, err := r.db.Conn().Query("SELECT DISTINCT jid FROM contact_attributes ORDER BY jid ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contacts with attributes: %w", err)
+	}
+	defer rows.Close()
+
+	jids := []string{}
+
+	for rows.Next() {
+		var jid string
+		if err := rows.Scan(&jid); err != nil {
+			return nil, fmt.Errorf("failed to scan jid: %w", err)
+		}
+		jids = append(jids, jid)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jids: %w", err)
+	}
+
+	return jids, nil
+}
+
+// attributeQueryChunkSize bounds the JIDs bound into one IN clause, keeping
+// queries under SQLite's limit on host parameters.
+const attributeQueryChunkSize = 500
+
+// GetForContacts returns the attributes of the given contacts, keyed by JID and
+// then key, with one query per attributeQueryChunkSize JIDs. With keys, only
+// those keys are returned. Contacts without matching attributes are left out.
+func (r *AttributeRepository) GetForContacts(jids, keys []string) (map[string]map[string]string, error) {
+	attributes := make(map[string]map[string]string)
+	if len(jids) == 0 {
+		return attributes, nil
+	}
+
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	for start := 0; start < len(jids); start += attributeQueryChunkSize {
+		chunk := jids[start:min(start+attributeQueryChunkSize, len(jids))]
+		if err := r.getForContactsChunk(chunk, keys, attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	return attributes, nil
+}
+
+// getForContactsChunk adds the attributes of one chunk of JIDs to attributes.
+// Callers must hold the read lock.
+func (r *AttributeRepository) getForContactsChunk(jids, keys []string, attributes map[string]map[string]string) error {
+	query := `
+		SELECT jid, key, value
+		FROM contact_attributes
+		WHERE jid IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(jids)), ", ") + `)`
+	args := make([]interface{}, 0, len(jids)+len(keys))
+	for _, jid := range jids {
+		args = append(args, jid)
+	}
+	if len(keys) > 0 {
+		query += ` AND key IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ") + `)`
+		for _, key := range keys {
+			args = append(args, key)
+		}
+	}
+
+	rows, err := r.db.Conn().Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query attributes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var jid, key, value string
+		if err := rows.Scan(&jid, &key, &value); err != nil {
+			return fmt.Errorf("failed to scan attribute: %w", err)
+		}
+		if attributes[jid] == nil {
+			attributes[jid] = make(map[string]string)
+		}
+		attributes[jid][key] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating attributes: %w", err)
+	}
+
+	return nil
+}
+
+// LongestValues returns the longest value stored for each attribute key,
+// counting group defaults as well, the worst case for a draft's rendered length.
+func (r *AttributeRepository) LongestValues() (map[string]string, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	// SQLite takes the bare value column from the row holding the MAX
+	query := `
+		SELECT key, value, MAX(length(value))
+		FROM (
+			SELECT key, value FROM contact_attributes
+			UNION ALL
+			SELECT key, value FROM group_attributes
+		)
+		GROUP BY key
+	`
+
+	rows, err := r.db.Conn().Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query longest values: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+
+	for rows.Next() {
+		var key, value string
+		var length int
+		if err := rows.Scan(&key, &value, &length); err != nil {
+			return nil, fmt.Errorf("failed to scan longest value: %w", err)
+		}
+		values[key] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating longest values: %w", err)
+	}
+
+	return values, nil
+}
+
 func (r *AttributeRepository) CountByKey() (map[string]int, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
@@ -241,3 +457,57 @@ func (r *AttributeRepository) CountByKey() (map[string]int, error) {
 
 	return counts, nil
 }
+
+// FindByValues returns the contacts whose attributes match every key=value filter,
+// keyed by JID, with the matched values. Values compare case-insensitively.
+func (r *AttributeRepository) FindByValues(filters map[string]string) (map[string]map[string]string, error) {
+	matches := make(map[string]map[string]string)
+	if len(filters) == 0 {
+		return matches, nil
+	}
+
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	conditions := make([]string, 0, len(filters))
+	args := make([]interface{}, 0, len(filters)*2)
+	for key, value := range filters {
+		conditions = append(conditions, "(key = ? AND LOWER(value) = LOWER(?))")
+		args = append(args, key, value)
+	}
+
+	query := `
+		SELECT jid, key, value
+		FROM contact_attributes
+		WHERE ` + strings.Join(conditions, " OR ")
+
+	rows, err := r.db.Conn().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attributes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var jid, key, value string
+		if err := rows.Scan(&jid, &key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan attribute: %w", err)
+		}
+		if matches[jid] == nil {
+			matches[jid] = make(map[string]string)
+		}
+		matches[jid][key] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attributes: %w", err)
+	}
+
+	// A contact must match every filter, not just one
+	for jid, values := range matches {
+		if len(values) != len(filters) {
+			delete(matches, jid)
+		}
+	}
+
+	return matches, nil
+}
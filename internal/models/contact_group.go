@@ -9,11 +9,14 @@ import (
 )
 
 type ContactGroup struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	MemberCount int       `json:"member_count,omitempty"` // Populated by queries that JOIN with group_members
+	ID                int64     `json:"id"`
+	WorkspaceID       int64     `json:"workspace_id"`
+	Name              string    `json:"name"`
+	DefaultDraftID    *int64    `json:"default_draft_id"`              // Draft sent by POST /api/groups/{id}/send; nulled when the draft is deleted
+	DefaultDraftTitle *string   `json:"default_draft_title,omitempty"` // Populated by GetByID and GetAll
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	MemberCount       int       `json:"member_count,omitempty"` // Populated by queries that JOIN with group_members
 }
 
 // GroupRepository handles all database operations for contact groups.
@@ -26,17 +29,21 @@ func NewGroupRepository(db *database.DB) *GroupRepository {
 	return &GroupRepository{db: db}
 }
 
-// Create inserts a new group into the database.
+// Create inserts a new group into group.WorkspaceID, or the default workspace when it is 0.
 func (r *GroupRepository) Create(group *ContactGroup) error {
 	r.db.Lock()
 	defer r.db.Unlock()
 
+	if group.WorkspaceID == 0 {
+		group.WorkspaceID = DefaultWorkspaceID
+	}
+
 	query := `
-		INSERT INTO contact_groups (name, created_at, updated_at)
-		VALUES (?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		INSERT INTO contact_groups (workspace_id, name, default_draft_id, created_at, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 	`
 
-	result, err := r.db.Conn().Exec(query, group.Name)
+	result, err := r.db.Conn().Exec(query, group.WorkspaceID, group.Name, group.DefaultDraftID)
 	if err != nil {
 		return fmt.Errorf("failed to create group: %w", err)
 	}
@@ -61,23 +68,90 @@ func (r *GroupRepository) Create(group *ContactGroup) error {
 	return nil
 }
 
-// GetByID retrieves a single group by its ID.
+// CreateWithMembers inserts a new group and its members in one transaction, so
+// a failure leaves no half-filled group behind.
+func (r *GroupRepository) CreateWithMembers(group *ContactGroup, jids []string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // No-op if committed
+
+	if group.WorkspaceID == 0 {
+		group.WorkspaceID = DefaultWorkspaceID
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO contact_groups (workspace_id, name, default_draft_id, created_at, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, group.WorkspaceID, group.Name, group.DefaultDraftID)
+	if err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO group_members (group_id, jid, added_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, jid := range jids {
+		if _, err := stmt.Exec(id, jid); err != nil {
+			return fmt.Errorf("failed to add member %s: %w", jid, err)
+		}
+	}
+
+	if err := tx.QueryRow(
+		"SELECT created_at, updated_at FROM contact_groups WHERE id = ?",
+		id,
+	).Scan(&group.CreatedAt, &group.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to read new group: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	group.ID = id
+	group.MemberCount = len(jids)
+	return nil
+}
+
+// GetByID retrieves a single group by its ID, in any workspace; requests use
+// GetInWorkspace.
 func (r *GroupRepository) GetByID(id int64) (*ContactGroup, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT g.id, g.name, g.created_at, g.updated_at, COUNT(gm.id) as member_count
+		SELECT g.id, g.workspace_id, g.name, g.default_draft_id, d.title, g.created_at, g.updated_at, COUNT(gm.id) as member_count
 		FROM contact_groups g
+		LEFT JOIN message_drafts d ON d.id = g.default_draft_id
 		LEFT JOIN group_members gm ON g.id = gm.group_id
 		WHERE g.id = ?
 		GROUP BY g.id
 	`
 
 	var group ContactGroup
+	var defaultDraftID sql.NullInt64
+	var defaultDraftTitle sql.NullString
 	err := r.db.Conn().QueryRow(query, id).Scan(
 		&group.ID,
+		&group.WorkspaceID,
 		&group.Name,
+		&defaultDraftID,
+		&defaultDraftTitle,
 		&group.CreatedAt,
 		&group.UpdatedAt,
 		&group.MemberCount,
@@ -89,24 +163,40 @@ func (r *GroupRepository) GetByID(id int64) (*ContactGroup, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get group: %w", err)
 	}
+	group.setDefaultDraft(defaultDraftID, defaultDraftTitle)
 
 	return &group, nil
 }
 
-// GetByName retrieves a group by its name (for uniqueness checks).
-func (r *GroupRepository) GetByName(name string) (*ContactGroup, error) {
+// GetInWorkspace retrieves a group, or nil if there is none with that ID in the workspace.
+func (r *GroupRepository) GetInWorkspace(workspaceID, id int64) (*ContactGroup, error) {
+	group, err := r.GetByID(id)
+	if err != nil || group == nil || group.WorkspaceID != workspaceID {
+		return nil, err
+	}
+	return group, nil
+}
+
+// GetByName retrieves the group of the workspace with the given name, ignoring
+// ASCII case; an exact match wins when names differ only in case. Names are
+// unique within a workspace, which callers check with this before saving one.
+// MemberCount is not filled in.
+func (r *GroupRepository) GetByName(workspaceID int64, name string) (*ContactGroup, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, name, created_at, updated_at
+		SELECT id, workspace_id, name, created_at, updated_at
 		FROM contact_groups
-		WHERE name = ?
+		WHERE workspace_id = ? AND name = ? COLLATE NOCASE
+		ORDER BY name = ? DESC
+		LIMIT 1
 	`
 
 	var group ContactGroup
-	err := r.db.Conn().QueryRow(query, name).Scan(
+	err := r.db.Conn().QueryRow(query, workspaceID, name, name).Scan(
 		&group.ID,
+		&group.WorkspaceID,
 		&group.Name,
 		&group.CreatedAt,
 		&group.UpdatedAt,
@@ -122,20 +212,22 @@ func (r *GroupRepository) GetByName(name string) (*ContactGroup, error) {
 	return &group, nil
 }
 
-// GetAll retrieves all groups with their member counts, ordered by name.
-func (r *GroupRepository) GetAll() ([]ContactGroup, error) {
+// GetAll retrieves the groups of a workspace with their member counts, ordered by name.
+func (r *GroupRepository) GetAll(workspaceID int64) ([]ContactGroup, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT g.id, g.name, g.created_at, g.updated_at, COUNT(gm.id) as member_count
+		SELECT g.id, g.workspace_id, g.name, g.default_draft_id, d.title, g.created_at, g.updated_at, COUNT(gm.id) as member_count
 		FROM contact_groups g
+		LEFT JOIN message_drafts d ON d.id = g.default_draft_id
 		LEFT JOIN group_members gm ON g.id = gm.group_id
+		WHERE g.workspace_id = ?
 		GROUP BY g.id
 		ORDER BY g.name ASC
 	`
 
-	rows, err := r.db.Conn().Query(query)
+	rows, err := r.db.Conn().Query(query, workspaceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query groups: %w", err)
 	}
@@ -145,15 +237,21 @@ func (r *GroupRepository) GetAll() ([]ContactGroup, error) {
 
 	for rows.Next() {
 		var group ContactGroup
+		var defaultDraftID sql.NullInt64
+		var defaultDraftTitle sql.NullString
 		if err := rows.Scan(
 			&group.ID,
+			&group.WorkspaceID,
 			&group.Name,
+			&defaultDraftID,
+			&defaultDraftTitle,
 			&group.CreatedAt,
 			&group.UpdatedAt,
 			&group.MemberCount,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan group: %w", err)
 		}
+		group.setDefaultDraft(defaultDraftID, defaultDraftTitle)
 		groups = append(groups, group)
 	}
 
@@ -164,18 +262,19 @@ func (r *GroupRepository) GetAll() ([]ContactGroup, error) {
 	return groups, nil
 }
 
-// Update modifies an existing group's name.
+// Update modifies an existing group's name and default draft. It returns false
+// if the group does not exist in group.WorkspaceID.
 func (r *GroupRepository) Update(group *ContactGroup) (bool, error) {
 	r.db.Lock()
 	defer r.db.Unlock()
 
 	query := `
 		UPDATE contact_groups
-		SET name = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
+		SET name = ?, default_draft_id = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND workspace_id = ?
 	`
 
-	result, err := r.db.Conn().Exec(query, group.Name, group.ID)
+	result, err := r.db.Conn().Exec(query, group.Name, group.DefaultDraftID, group.ID, group.WorkspaceID)
 	if err != nil {
 		return false, fmt.Errorf("failed to update group: %w", err)
 	}
@@ -199,13 +298,55 @@ func (r *GroupRepository) Update(group *ContactGroup) (bool, error) {
 	return true, nil
 }
 
-// Delete removes a group by ID.
-// Note: Due to ON DELETE CASCADE, this also removes all group memberships.
-func (r *GroupRepository) Delete(id int64) (bool, error) {
+// MoveToWorkspace moves a group and its members to another workspace. A default
+// draft that is not in the new workspace is dropped; batches already sent to
+// the group stay where they are. It returns false if the group does not exist.
+func (r *GroupRepository) MoveToWorkspace(id, workspaceID int64) (bool, error) {
 	r.db.Lock()
 	defer r.db.Unlock()
 
-	result, err := r.db.Conn().Exec("DELETE FROM contact_groups WHERE id = ?", id)
+	query := `
+		UPDATE contact_groups
+		SET workspace_id = ?,
+		    default_draft_id = (SELECT d.id FROM message_drafts d WHERE d.id = default_draft_id AND d.workspace_id = ?),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	result, err := r.db.Conn().Exec(query, workspaceID, workspaceID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to move group: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// setDefaultDraft fills the default draft fields from a LEFT JOIN on message_drafts.
+func (g *ContactGroup) setDefaultDraft(id sql.NullInt64, title sql.NullString) {
+	if id.Valid {
+		g.DefaultDraftID = &id.Int64
+	}
+	if title.Valid {
+		g.DefaultDraftTitle = &title.String
+	}
+}
+
+// Delete removes a group of the workspace by ID.
+// Note: Due to ON DELETE CASCADE, this also removes all group memberships and
+// placeholder defaults. It returns ErrHasBatches when batch runs were created from it.
+func (r *GroupRepository) Delete(workspaceID, id int64) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec("DELETE FROM contact_groups WHERE id = ? AND workspace_id = ?", id, workspaceID)
+	if database.IsForeignKeyViolation(err) {
+		return false, ErrHasBatches
+	}
 	if err != nil {
 		return false, fmt.Errorf("failed to delete group: %w", err)
 	}
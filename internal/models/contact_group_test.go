@@ -0,0 +1,66 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+
+	"friday/internal/database"
+)
+
+// newTestDB opens a fresh database in a temporary directory.
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "friday.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestGroupNamesUniquePerWorkspace(t *testing.T) {
+	db := newTestDB(t)
+	groups := NewGroupRepository(db)
+	other := &Workspace{Name: "sales"}
+	if err := NewWorkspaceRepository(db).Create(other); err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	first := &ContactGroup{Name: "Customers"}
+	if err := groups.Create(first); err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+	second := &ContactGroup{WorkspaceID: other.ID, Name: "Customers"}
+	if err := groups.Create(second); err != nil {
+		t.Fatalf("creating a group of the same name in another workspace: %v", err)
+	}
+	if err := groups.Create(&ContactGroup{Name: "Customers"}); err == nil {
+		t.Error("created a second group named Customers in the default workspace")
+	}
+
+	for _, tc := range []struct {
+		workspaceID int64
+		name        string
+		want        int64
+	}{
+		{DefaultWorkspaceID, "Customers", first.ID},
+		{other.ID, "customers", second.ID},
+		{other.ID + 1, "Customers", 0},
+	} {
+		group, err := groups.GetByName(tc.workspaceID, tc.name)
+		if err != nil {
+			t.Fatalf("GetByName(%d, %q): %v", tc.workspaceID, tc.name, err)
+		}
+		var got int64
+		if group != nil {
+			got = group.ID
+		}
+		if got != tc.want {
+			t.Errorf("GetByName(%d, %q) = group %d, want %d", tc.workspaceID, tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := groups.MoveToWorkspace(second.ID, DefaultWorkspaceID); err == nil {
+		t.Error("moved a group into a workspace with a group of the same name")
+	}
+}
@@ -0,0 +1,295 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+
+	"friday/internal/database"
+)
+
+// AttributeSourceMerge marks history entries written by a contact merge.
+const AttributeSourceMerge = "merge"
+
+// ContactMergeResult reports what Merge moved from the duplicate to the primary.
+type ContactMergeResult struct {
+	PrimaryJID         string   `json:"primary_jid"`
+	DuplicateJID       string   `json:"duplicate_jid"`
+	AttributesMoved    int      `json:"attributes_moved"`
+	AttributeConflicts []string `json:"attribute_conflicts"` // Keys both had; the primary's value was kept
+	HistoryMoved       int      `json:"history_moved"`       // Attribute history entries now under the primary
+	GroupsMoved        int      `json:"groups_moved"`
+	GroupsDeduped      int      `json:"groups_deduped"`   // Groups both were in; the duplicate's membership was dropped
+	MessagesMoved      int      `json:"messages_moved"`   // Pending batch messages now addressed to the primary
+	MessagesDropped    int      `json:"messages_dropped"` // Pending batch messages dropped because their batch already messages the primary
+	NoteMerged         bool     `json:"note_merged"`
+	Redirected         bool     `json:"redirected"`
+}
+
+// ContactMergeRepository merges duplicate contacts and keeps the redirects
+// from merged JIDs to the contact they were merged into.
+type ContactMergeRepository struct {
+	db *database.DB
+}
+
+// NewContactMergeRepository creates a new contact merge repository.
+func NewContactMergeRepository(db *database.DB) *ContactMergeRepository {
+	return &ContactMergeRepository{db: db}
+}
+
+// Merge moves duplicate's attributes, group memberships, pending batch messages
+// and note to primary in one transaction. Attributes both have keep the
+// primary's value; a differing duplicate value is recorded in the primary's
+// history as the old value. A note is appended to the primary's unless the
+// result would exceed MaxContactNoteLength, in which case it stays where it is.
+// Sent messages, validations and failure counts describe the duplicate's own
+// number and are left alone. With redirect, later sends to duplicate go to
+// primary, as do existing redirects to duplicate.
+func (r *ContactMergeRepository) Merge(primary, duplicate string, redirect bool) (*ContactMergeResult, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &ContactMergeResult{
+		PrimaryJID:         primary,
+		DuplicateJID:       duplicate,
+		AttributeConflicts: []string{},
+	}
+
+	if err := mergeAttributes(tx, primary, duplicate, result); err != nil {
+		return nil, err
+	}
+	if err := mergeGroupMemberships(tx, primary, duplicate, result); err != nil {
+		return nil, err
+	}
+	if err := mergePendingMessages(tx, primary, duplicate, result); err != nil {
+		return nil, err
+	}
+	if err := mergeNotes(tx, primary, duplicate, result); err != nil {
+		return nil, err
+	}
+
+	if redirect {
+		if _, err := tx.Exec("UPDATE contact_redirects SET target_jid = ? WHERE target_jid = ?", primary, duplicate); err != nil {
+			return nil, fmt.Errorf("failed to update redirects: %w", err)
+		}
+		_, err := tx.Exec(`
+			INSERT INTO contact_redirects (jid, target_jid, created_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(jid) DO UPDATE SET
+				target_jid = excluded.target_jid,
+				created_at = CURRENT_TIMESTAMP
+		`, duplicate, primary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record redirect: %w", err)
+		}
+		result.Redirected = true
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+func mergeAttributes(tx *sql.Tx, primary, duplicate string, result *ContactMergeResult) error {
+	// Move the history first so the entries written below are pruned together with it
+	res, err := tx.Exec("UPDATE attribute_history SET jid = ? WHERE jid = ?", primary, duplicate)
+	if err != nil {
+		return fmt.Errorf("failed to move attribute history: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	result.HistoryMoved = int(n)
+
+	rows, err := tx.Query(`
+		SELECT d.key, d.value, p.value
+		FROM contact_attributes d
+		LEFT JOIN contact_attributes p ON p.jid = ? AND p.key = d.key
+		WHERE d.jid = ?
+		ORDER BY d.key
+	`, primary, duplicate)
+	if err != nil {
+		return fmt.Errorf("failed to query attributes: %w", err)
+	}
+	type attribute struct {
+		key          string
+		value        string
+		primaryValue sql.NullString
+	}
+	var attributes []attribute
+	for rows.Next() {
+		var a attribute
+		if err := rows.Scan(&a.key, &a.value, &a.primaryValue); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan attribute: %w", err)
+		}
+		attributes = append(attributes, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating attributes: %w", err)
+	}
+
+	for _, a := range attributes {
+		if !a.primaryValue.Valid {
+			if _, err := tx.Exec("UPDATE contact_attributes SET jid = ? WHERE jid = ? AND key = ?", primary, duplicate, a.key); err != nil {
+				return fmt.Errorf("failed to move attribute %s: %w", a.key, err)
+			}
+			if err := recordAttributeChange(tx, primary, a.key, sql.NullString{}, sql.NullString{String: a.value, Valid: true}, AttributeSourceMerge); err != nil {
+				return err
+			}
+			result.AttributesMoved++
+			continue
+		}
+
+		result.AttributeConflicts = append(result.AttributeConflicts, a.key)
+		if a.value != a.primaryValue.String {
+			if err := recordAttributeChange(tx, primary, a.key, sql.NullString{String: a.value, Valid: true}, a.primaryValue, AttributeSourceMerge); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec("DELETE FROM contact_attributes WHERE jid = ? AND key = ?", duplicate, a.key); err != nil {
+			return fmt.Errorf("failed to delete attribute %s: %w", a.key, err)
+		}
+	}
+
+	return nil
+}
+
+func mergeGroupMemberships(tx *sql.Tx, primary, duplicate string, result *ContactMergeResult) error {
+	res, err := tx.Exec(`
+		DELETE FROM group_members
+		WHERE jid = ? AND group_id IN (SELECT group_id FROM group_members WHERE jid = ?)
+	`, duplicate, primary)
+	if err != nil {
+		return fmt.Errorf("failed to dedupe group memberships: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	result.GroupsDeduped = int(n)
+
+	res, err = tx.Exec("UPDATE group_members SET jid = ? WHERE jid = ?", primary, duplicate)
+	if err != nil {
+		return fmt.Errorf("failed to move group memberships: %w", err)
+	}
+	n, _ = res.RowsAffected()
+	result.GroupsMoved = int(n)
+
+	return nil
+}
+
+// mergePendingMessages readdresses the duplicate's unsent batch messages. Where
+// the batch already messages the primary, the duplicate's message is dropped and
+// the batch's total lowered, so the primary is not messaged twice.
+func mergePendingMessages(tx *sql.Tx, primary, duplicate string, result *ContactMergeResult) error {
+	redundant := `
+		jid = ? AND status = 'pending' AND batch_run_id IN (
+			SELECT batch_run_id FROM batch_messages WHERE jid = ?
+		)
+	`
+
+	_, err := tx.Exec(`
+		UPDATE batch_runs
+		SET total_count = total_count - (
+			SELECT COUNT(*) FROM batch_messages
+			WHERE batch_messages.batch_run_id = batch_runs.id AND `+redundant+`
+		)
+		WHERE id IN (SELECT batch_run_id FROM batch_messages WHERE `+redundant+`)
+	`, duplicate, primary, duplicate, primary)
+	if err != nil {
+		return fmt.Errorf("failed to update batch totals: %w", err)
+	}
+
+	res, err := tx.Exec("DELETE FROM batch_messages WHERE "+redundant, duplicate, primary)
+	if err != nil {
+		return fmt.Errorf("failed to drop duplicate batch messages: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	result.MessagesDropped = int(n)
+
+	res, err = tx.Exec("UPDATE batch_messages SET jid = ? WHERE jid = ? AND status = 'pending'", primary, duplicate)
+	if err != nil {
+		return fmt.Errorf("failed to move batch messages: %w", err)
+	}
+	n, _ = res.RowsAffected()
+	result.MessagesMoved = int(n)
+
+	return nil
+}
+
+func mergeNotes(tx *sql.Tx, primary, duplicate string, result *ContactMergeResult) error {
+	var duplicateNote, primaryNote sql.NullString
+	err := tx.QueryRow("SELECT content FROM contact_notes WHERE jid = ?", duplicate).Scan(&duplicateNote)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get note: %w", err)
+	}
+	err = tx.QueryRow("SELECT content FROM contact_notes WHERE jid = ?", primary).Scan(&primaryNote)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get note: %w", err)
+	}
+
+	if !primaryNote.Valid {
+		if _, err := tx.Exec("UPDATE contact_notes SET jid = ? WHERE jid = ?", primary, duplicate); err != nil {
+			return fmt.Errorf("failed to move note: %w", err)
+		}
+		result.NoteMerged = true
+		return nil
+	}
+
+	combined := primaryNote.String + "\n\n" + duplicateNote.String
+	if len(combined) > MaxContactNoteLength {
+		return nil
+	}
+	if _, err := tx.Exec("UPDATE contact_notes SET content = ?, updated_at = CURRENT_TIMESTAMP WHERE jid = ?", combined, primary); err != nil {
+		return fmt.Errorf("failed to merge note: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM contact_notes WHERE jid = ?", duplicate); err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+	result.NoteMerged = true
+	return nil
+}
+
+// Redirect returns the JID that jid was merged into, or "" if it was not.
+func (r *ContactMergeRepository) Redirect(jid string) (string, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var target string
+	err := r.db.Conn().QueryRow("SELECT target_jid FROM contact_redirects WHERE jid = ?", jid).Scan(&target)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get redirect: %w", err)
+	}
+	return target, nil
+}
+
+// ApplyRedirects replaces merged JIDs with the JID they were merged into,
+// keeping the order and dropping JIDs that end up listed twice.
+func (r *ContactMergeRepository) ApplyRedirects(jids []string) ([]string, error) {
+	resolved := make([]string, 0, len(jids))
+	seen := make(map[string]bool, len(jids))
+	for _, jid := range jids {
+		target, err := r.Redirect(jid)
+		if err != nil {
+			return nil, err
+		}
+		if target != "" {
+			jid = target
+		}
+		if !seen[jid] {
+			seen[jid] = true
+			resolved = append(resolved, jid)
+		}
+	}
+	return resolved, nil
+}
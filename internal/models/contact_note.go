@@ -0,0 +1,95 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"friday/internal/database"
+)
+
+// MaxContactNoteLength caps a note's size in bytes.
+const MaxContactNoteLength = 10 * 1024
+
+// ContactNote is free-form text about a contact. Notes are kept apart from
+// attributes so they never become template placeholders.
+type ContactNote struct {
+	JID       string    `json:"jid"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ContactNoteRepository handles database operations for contact notes.
+type ContactNoteRepository struct {
+	db *database.DB
+}
+
+// NewContactNoteRepository creates a new contact note repository.
+func NewContactNoteRepository(db *database.DB) *ContactNoteRepository {
+	return &ContactNoteRepository{db: db}
+}
+
+// Get returns the note for a contact, or nil if none is set.
+func (r *ContactNoteRepository) Get(jid string) (*ContactNote, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var note ContactNote
+	err := r.db.Conn().QueryRow(
+		"SELECT jid, content, updated_at FROM contact_notes WHERE jid = ?",
+		jid,
+	).Scan(&note.JID, &note.Content, &note.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact note: %w", err)
+	}
+
+	return &note, nil
+}
+
+// Set creates or replaces the note for a contact (upsert) and returns it.
+func (r *ContactNoteRepository) Set(jid, content string) (*ContactNote, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	query := `
+		INSERT INTO contact_notes (jid, content, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(jid) DO UPDATE SET
+			content = excluded.content,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	if _, err := r.db.Conn().Exec(query, jid, content); err != nil {
+		return nil, fmt.Errorf("failed to set contact note: %w", err)
+	}
+
+	note := &ContactNote{JID: jid, Content: content}
+	row := r.db.Conn().QueryRow("SELECT updated_at FROM contact_notes WHERE jid = ?", jid)
+	if err := row.Scan(&note.UpdatedAt); err != nil {
+		note.UpdatedAt = time.Now()
+	}
+
+	return note, nil
+}
+
+// Delete removes a contact's note. Returns false if there was none.
+func (r *ContactNoteRepository) Delete(jid string) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec("DELETE FROM contact_notes WHERE jid = ?", jid)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete contact note: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
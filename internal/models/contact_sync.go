@@ -0,0 +1,309 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"friday/internal/database"
+)
+
+// Kinds of change a contact sync records.
+const (
+	ContactAdded   = "added"
+	ContactRemoved = "removed"
+	ContactRenamed = "renamed"
+)
+
+// SnapshotContact is one address book entry as a sync copies it.
+type SnapshotContact struct {
+	JID  string
+	Name string
+}
+
+// SyncRun records one contact sync: the WhatsApp address book was copied into
+// the local snapshot and compared with the snapshot before it.
+type SyncRun struct {
+	ID           int64     `json:"id"`
+	ContactCount int       `json:"contact_count"`
+	AddedCount   int       `json:"added_count"`
+	RemovedCount int       `json:"removed_count"`
+	RenamedCount int       `json:"renamed_count"`
+	Baseline     bool      `json:"baseline,omitempty"` // The first sync; with nothing to compare with, it records no changes
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ContactChange is a contact that appeared, disappeared or changed its name
+// between two syncs.
+type ContactChange struct {
+	JID       string    `json:"jid"`
+	Change    string    `json:"change"`             // ContactAdded, ContactRemoved or ContactRenamed
+	OldName   *string   `json:"old_name,omitempty"` // Removed and renamed contacts
+	NewName   *string   `json:"new_name,omitempty"` // Added and renamed contacts
+	SyncRunID int64     `json:"sync_run_id"`        // The last sync that saw it change
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ContactChanges is the net difference between the snapshot of one sync and
+// that of the latest. A contact added and removed again in between is left
+// out, as is one renamed back to its old name.
+type ContactChanges struct {
+	FromSyncID int64           `json:"from_sync_id"` // Changes after this sync; 0 means since the first
+	ToSyncID   int64           `json:"to_sync_id"`   // The latest sync, or FromSyncID when none came after it
+	Syncs      int             `json:"syncs"`        // Syncs the changes were collected from
+	Added      []ContactChange `json:"added"`
+	Removed    []ContactChange `json:"removed"`
+	Renamed    []ContactChange `json:"renamed"`
+}
+
+// ContactSyncRepository handles the contact snapshot and the changes recorded
+// by each sync. Changes are worked out from snapshots when a sync runs and
+// stored with it, so reading them later does not depend on the live address book.
+type ContactSyncRepository struct {
+	db *database.DB
+}
+
+// NewContactSyncRepository creates a new contact sync repository.
+func NewContactSyncRepository(db *database.DB) *ContactSyncRepository {
+	return &ContactSyncRepository{db: db}
+}
+
+// Sync replaces the snapshot with contacts and records a sync run with the
+// changes from the previous snapshot, in one transaction. contacts must not
+// repeat a JID. The first sync is a baseline and records no changes.
+func (r *ContactSyncRepository) Sync(contacts []SnapshotContact) (*SyncRun, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var previousRuns int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM sync_runs").Scan(&previousRuns); err != nil {
+		return nil, fmt.Errorf("failed to count sync runs: %w", err)
+	}
+
+	previous := make(map[string]string)
+	rows, err := tx.Query("SELECT jid, name FROM contact_snapshot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contact snapshot: %w", err)
+	}
+	for rows.Next() {
+		var jid, name string
+		if err := rows.Scan(&jid, &name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan contact snapshot: %w", err)
+		}
+		previous[jid] = name
+	}
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read contact snapshot: %w", err)
+	}
+
+	run := &SyncRun{ContactCount: len(contacts), Baseline: previousRuns == 0}
+	var changes []ContactChange
+	if !run.Baseline {
+		current := make(map[string]bool, len(contacts))
+		for _, contact := range contacts {
+			current[contact.JID] = true
+			newName := contact.Name
+			oldName, existed := previous[contact.JID]
+			switch {
+			case !existed:
+				changes = append(changes, ContactChange{JID: contact.JID, Change: ContactAdded, NewName: &newName})
+			case oldName != newName:
+				changes = append(changes, ContactChange{JID: contact.JID, Change: ContactRenamed, OldName: &oldName, NewName: &newName})
+			}
+		}
+		for jid, name := range previous {
+			if !current[jid] {
+				oldName := name
+				changes = append(changes, ContactChange{JID: jid, Change: ContactRemoved, OldName: &oldName})
+			}
+		}
+		slices.SortFunc(changes, func(a, b ContactChange) int { return strings.Compare(a.JID, b.JID) })
+	}
+	for _, change := range changes {
+		switch change.Change {
+		case ContactAdded:
+			run.AddedCount++
+		case ContactRemoved:
+			run.RemovedCount++
+		case ContactRenamed:
+			run.RenamedCount++
+		}
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO sync_runs (contact_count, added_count, removed_count, renamed_count, baseline, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, run.ContactCount, run.AddedCount, run.RemovedCount, run.RenamedCount, run.Baseline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record sync run: %w", err)
+	}
+	if run.ID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("failed to get sync run ID: %w", err)
+	}
+	if err := tx.QueryRow("SELECT created_at FROM sync_runs WHERE id = ?", run.ID).Scan(&run.CreatedAt); err != nil {
+		run.CreatedAt = time.Now()
+	}
+
+	if len(changes) > 0 {
+		stmt, err := tx.Prepare("INSERT INTO sync_changes (sync_run_id, jid, change, old_name, new_name) VALUES (?, ?, ?, ?, ?)")
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		defer stmt.Close()
+		for _, change := range changes {
+			if _, err := stmt.Exec(run.ID, change.JID, change.Change, change.OldName, change.NewName); err != nil {
+				return nil, fmt.Errorf("failed to record contact change: %w", err)
+			}
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM contact_snapshot"); err != nil {
+		return nil, fmt.Errorf("failed to clear contact snapshot: %w", err)
+	}
+	stmt, err := tx.Prepare("INSERT INTO contact_snapshot (jid, name) VALUES (?, ?)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+	for _, contact := range contacts {
+		if _, err := stmt.Exec(contact.JID, contact.Name); err != nil {
+			return nil, fmt.Errorf("failed to store contact snapshot: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return run, nil
+}
+
+// Last returns the newest sync run, or nil if contacts were never synced.
+func (r *ContactSyncRepository) Last() (*SyncRun, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var run SyncRun
+	err := r.db.Conn().QueryRow(`
+		SELECT id, contact_count, added_count, removed_count, renamed_count, baseline, created_at
+		FROM sync_runs
+		ORDER BY id DESC
+		LIMIT 1
+	`).Scan(&run.ID, &run.ContactCount, &run.AddedCount, &run.RemovedCount, &run.RenamedCount, &run.Baseline, &run.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last sync run: %w", err)
+	}
+	return &run, nil
+}
+
+// LastAt returns the ID of the newest sync run at or before t, or 0 if none
+// ran by then.
+func (r *ContactSyncRepository) LastAt(t time.Time) (int64, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var id int64
+	err := r.db.Conn().QueryRow(
+		"SELECT COALESCE(MAX(id), 0) FROM sync_runs WHERE created_at <= ?",
+		t.UTC().Format(sqliteTimeLayout),
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find sync run: %w", err)
+	}
+	return id, nil
+}
+
+// ChangesSince folds the changes recorded by the sync runs after syncID into
+// the net difference from that sync's snapshot to the latest one.
+func (r *ContactSyncRepository) ChangesSince(syncID int64) (*ContactChanges, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	result := &ContactChanges{
+		FromSyncID: syncID,
+		ToSyncID:   syncID,
+		Added:      []ContactChange{},
+		Removed:    []ContactChange{},
+		Renamed:    []ContactChange{},
+	}
+	err := r.db.Conn().QueryRow(
+		"SELECT COUNT(*), COALESCE(MAX(id), ?) FROM sync_runs WHERE id > ?",
+		syncID, syncID,
+	).Scan(&result.Syncs, &result.ToSyncID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sync runs: %w", err)
+	}
+
+	rows, err := r.db.Conn().Query(`
+		SELECT c.sync_run_id, c.jid, c.change, c.old_name, c.new_name, s.created_at
+		FROM sync_changes c
+		JOIN sync_runs s ON s.id = c.sync_run_id
+		WHERE c.sync_run_id > ?
+		ORDER BY c.sync_run_id ASC, c.id ASC
+	`, syncID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contact changes: %w", err)
+	}
+	defer rows.Close()
+
+	// Each contact goes from how the first change found it to how the last left it
+	type span struct {
+		existed, exists  bool
+		oldName, newName string
+		lastRun          int64
+		lastChangedAt    time.Time
+	}
+	spans := make(map[string]*span)
+	var order []string
+	for rows.Next() {
+		var runID int64
+		var jid, change string
+		var oldName, newName sql.NullString
+		var changedAt time.Time
+		if err := rows.Scan(&runID, &jid, &change, &oldName, &newName, &changedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan contact change: %w", err)
+		}
+		s := spans[jid]
+		if s == nil {
+			s = &span{existed: change != ContactAdded, oldName: oldName.String}
+			spans[jid] = s
+			order = append(order, jid)
+		}
+		s.exists = change != ContactRemoved
+		s.newName = newName.String
+		s.lastRun = runID
+		s.lastChangedAt = changedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contact changes: %w", err)
+	}
+
+	for _, jid := range order {
+		s := spans[jid]
+		change := ContactChange{JID: jid, SyncRunID: s.lastRun, ChangedAt: s.lastChangedAt}
+		oldName, newName := s.oldName, s.newName
+		switch {
+		case !s.existed && s.exists:
+			change.Change, change.NewName = ContactAdded, &newName
+			result.Added = append(result.Added, change)
+		case s.existed && !s.exists:
+			change.Change, change.OldName = ContactRemoved, &oldName
+			result.Removed = append(result.Removed, change)
+		case s.existed && s.exists && oldName != newName:
+			change.Change, change.OldName, change.NewName = ContactRenamed, &oldName, &newName
+			result.Renamed = append(result.Renamed, change)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"friday/internal/database"
+)
+
+// ValidationRepository caches whether JIDs are registered on WhatsApp, so repeated
+// batches to the same group do not re-check every member.
+type ValidationRepository struct {
+	db *database.DB
+}
+
+// NewValidationRepository creates a new validation cache repository.
+func NewValidationRepository(db *database.DB) *ValidationRepository {
+	return &ValidationRepository{db: db}
+}
+
+// GetFresh returns cached results for the given JIDs that were checked within maxAge.
+// JIDs without a fresh entry are absent from the map.
+func (r *ValidationRepository) GetFresh(jids []string, maxAge time.Duration) (map[string]bool, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	result := make(map[string]bool, len(jids))
+	cutoff := time.Now().Add(-maxAge).UTC()
+
+	stmt, err := r.db.Conn().Prepare(
+		"SELECT on_whatsapp, checked_at FROM contact_validations WHERE jid = ?",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, jid := range jids {
+		var onWhatsApp bool
+		var checkedAt time.Time
+		if err := stmt.QueryRow(jid).Scan(&onWhatsApp, &checkedAt); err != nil {
+			continue // No cached entry
+		}
+		if checkedAt.After(cutoff) {
+			result[jid] = onWhatsApp
+		}
+	}
+
+	return result, nil
+}
+
+// SetMultiple stores validation results, replacing any previous entry for each JID.
+func (r *ValidationRepository) SetMultiple(results map[string]bool) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO contact_validations (jid, on_whatsapp, checked_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(jid) DO UPDATE SET
+			on_whatsapp = excluded.on_whatsapp,
+			checked_at = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for jid, onWhatsApp := range results {
+		if _, err := stmt.Exec(jid, onWhatsApp); err != nil {
+			return fmt.Errorf("failed to store validation for %s: %w", jid, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DeliveryStatus says whether a sent batch message reached the recipient's phone.
+type DeliveryStatus string
+
+const (
+	DeliveryAwaiting  DeliveryStatus = "awaiting"  // Sent, no receipt yet
+	DeliveryDelivered DeliveryStatus = "delivered" // The phone sent a delivery, read or played receipt
+	// No receipt within batch.undelivered_after_hours. WhatsApp never delivers
+	// to a phone that blocked the sender, but the phone may also just be off;
+	// a receipt arriving later still marks the message delivered.
+	DeliveryUndelivered DeliveryStatus = "undelivered"
+)
+
+// DeliveryCounts counts the sent messages of a batch by delivery status.
+// Messages sent before receipts were tracked are not counted.
+type DeliveryCounts struct {
+	Awaiting    int `json:"awaiting"`
+	Delivered   int `json:"delivered"`
+	Undelivered int `json:"undelivered"`
+}
+
+// CountDelivery counts messages by delivery status, or returns nil when none
+// of them is tracked.
+func CountDelivery(messages []BatchMessage) *DeliveryCounts {
+	var counts *DeliveryCounts
+	for _, msg := range messages {
+		if msg.DeliveryStatus == nil {
+			continue
+		}
+		if counts == nil {
+			counts = &DeliveryCounts{}
+		}
+		switch *msg.DeliveryStatus {
+		case DeliveryAwaiting:
+			counts.Awaiting++
+		case DeliveryDelivered:
+			counts.Delivered++
+		case DeliveryUndelivered:
+			counts.Undelivered++
+		}
+	}
+	return counts
+}
+
+// MarkDelivered records receipts for the messages with the given WhatsApp
+// IDs, whatever their delivery status was, and returns how many it matched.
+// IDs of messages not sent by a batch match nothing.
+func (r *BatchMessageRepository) MarkDelivered(waMessageIDs []string, at time.Time) (int, error) {
+	if len(waMessageIDs) == 0 {
+		return 0, nil
+	}
+
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	args := make([]any, 0, len(waMessageIDs)+1)
+	args = append(args, at.UTC().Format(sqliteTimeLayout))
+	for _, id := range waMessageIDs {
+		args = append(args, id)
+	}
+
+	query := `
+		UPDATE batch_messages
+		SET delivery_status = 'delivered', delivered_at = COALESCE(delivered_at, ?)
+		WHERE wa_message_id IN (` + strings.TrimSuffix(strings.Repeat("?,", len(waMessageIDs)), ",") + `)
+		  AND status = 'sent'
+	`
+	result, err := r.db.Conn().Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark messages delivered: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return int(affected), nil
+}
+
+// MarkUndelivered marks messages still awaiting a receipt that were sent
+// before the given time as undelivered, and returns how many it marked.
+func (r *BatchMessageRepository) MarkUndelivered(sentBefore time.Time) (int, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec(`
+		UPDATE batch_messages
+		SET delivery_status = 'undelivered'
+		WHERE delivery_status = 'awaiting' AND sent_at < ?
+	`, sentBefore.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark messages undelivered: %w", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return int(affected), nil
+}
+
+// SuspectedBlock is a contact whose recent batch messages were never delivered.
+type SuspectedBlock struct {
+	JID                string     `json:"jid"`
+	ContactName        string     `json:"contact_name,omitempty"`      // As stored with its newest batch message
+	UndeliveredCount   int        `json:"undelivered_count"`           // Since the last delivered message
+	FirstUndeliveredAt time.Time  `json:"first_undelivered_at"`        // Sent time of the oldest of them
+	LastUndeliveredAt  time.Time  `json:"last_undelivered_at"`         // Sent time of the newest
+	LastDeliveredAt    *time.Time `json:"last_delivered_at,omitempty"` // Sent time of the last message that was delivered, nil if none was
+}
+
+// GetSuspectedBlocked lists contacts with at least minUndelivered undelivered
+// messages in a row: messages still awaiting a receipt neither count nor break
+// the run, a delivered one resets it. The longest runs come first.
+func (r *BatchMessageRepository) GetSuspectedBlocked(minUndelivered int) ([]SuspectedBlock, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := `
+		SELECT m.jid,
+		       COALESCE((SELECT n.contact_name FROM batch_messages n
+		                 WHERE n.jid = m.jid AND n.contact_name IS NOT NULL
+		                 ORDER BY n.id DESC LIMIT 1), ''),
+		       COUNT(*), MIN(m.sent_at), MAX(m.sent_at), d.last_delivered
+		FROM batch_messages m
+		LEFT JOIN (
+			SELECT jid, MAX(sent_at) AS last_delivered
+			FROM batch_messages
+			WHERE delivery_status = 'delivered'
+			GROUP BY jid
+		) d ON d.jid = m.jid
+		WHERE m.delivery_status = 'undelivered'
+		  AND (d.last_delivered IS NULL OR m.sent_at > d.last_delivered)
+		GROUP BY m.jid
+		HAVING COUNT(*) >= ?
+		ORDER BY COUNT(*) DESC, MAX(m.sent_at) DESC
+	`
+
+	rows, err := r.db.Conn().Query(query, minUndelivered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suspected blocks: %w", err)
+	}
+	defer rows.Close()
+
+	blocks := []SuspectedBlock{}
+	for rows.Next() {
+		var block SuspectedBlock
+		var first, last, lastDelivered sql.NullString
+		if err := rows.Scan(&block.JID, &block.ContactName, &block.UndeliveredCount, &first, &last, &lastDelivered); err != nil {
+			return nil, fmt.Errorf("failed to scan suspected block: %w", err)
+		}
+		// Undelivered messages were all sent, so first and last are never NULL
+		firstAt, err := parseAggregateTime(first)
+		if err != nil {
+			return nil, err
+		}
+		lastAt, err := parseAggregateTime(last)
+		if err != nil {
+			return nil, err
+		}
+		block.FirstUndeliveredAt, block.LastUndeliveredAt = *firstAt, *lastAt
+		if block.LastDeliveredAt, err = parseAggregateTime(lastDelivered); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suspected blocks: %w", err)
+	}
+
+	return blocks, nil
+}
@@ -1,19 +1,52 @@
 package models
 
 import (
+	"cmp"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"friday/internal/database"
+	"friday/internal/template"
 )
 
 type MessageDraft struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int64               `json:"id"`
+	WorkspaceID int64               `json:"workspace_id"`
+	Title       string              `json:"title"`
+	Content     string              `json:"content"`
+	Delimiters  template.Delimiters `json:"delimiters"` // Mark the placeholders of the content and its variants
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// ContentHash identifies what the draft sends: its content, delimiters and
+// variants, as a hex SHA-256 digest. Batches record it at creation, so a later
+// hash of the same draft tells whether it was edited since; the title and
+// timestamps do not count.
+func (d *MessageDraft) ContentHash(variants []DraftVariant) string {
+	h := sha256.New()
+	// Each field is length-prefixed, so no two drafts hash the same input
+	write := func(s string) { fmt.Fprintf(h, "%d:%s", len(s), s) }
+	write(d.Content)
+	write(d.Delimiters.Open)
+	write(d.Delimiters.Close)
+
+	sorted := slices.Clone(variants)
+	slices.SortFunc(sorted, func(a, b DraftVariant) int {
+		return cmp.Or(strings.Compare(a.SelectorKey, b.SelectorKey), strings.Compare(a.SelectorValue, b.SelectorValue))
+	})
+	for _, variant := range sorted {
+		write(variant.SelectorKey)
+		write(variant.SelectorValue)
+		write(variant.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 type DraftRepository struct {
@@ -24,16 +57,25 @@ func NewDraftRepository(db *database.DB) *DraftRepository {
 	return &DraftRepository{db: db}
 }
 
+// Create inserts a draft into draft.WorkspaceID, or the default workspace when
+// it is 0. Drafts without delimiters get the default ones.
 func (r *DraftRepository) Create(draft *MessageDraft) error {
 	r.db.Lock()
 	defer r.db.Unlock()
 
+	if draft.WorkspaceID == 0 {
+		draft.WorkspaceID = DefaultWorkspaceID
+	}
+	if draft.Delimiters == (template.Delimiters{}) {
+		draft.Delimiters = template.DefaultDelimiters
+	}
+
 	query := `
-		INSERT INTO message_drafts (title, content, created_at, updated_at)
-		VALUES (?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		INSERT INTO message_drafts (workspace_id, title, content, placeholder_open, placeholder_close, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 	`
 
-	result, err := r.db.Conn().Exec(query, draft.Title, draft.Content)
+	result, err := r.db.Conn().Exec(query, draft.WorkspaceID, draft.Title, draft.Content, draft.Delimiters.Open, draft.Delimiters.Close)
 	if err != nil {
 		return fmt.Errorf("failed to create draft: %w", err)
 	}
@@ -57,12 +99,13 @@ func (r *DraftRepository) Create(draft *MessageDraft) error {
 	return nil
 }
 
+// GetByID retrieves a draft in any workspace; requests use GetInWorkspace.
 func (r *DraftRepository) GetByID(id int64) (*MessageDraft, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, title, content, created_at, updated_at
+		SELECT id, workspace_id, title, content, placeholder_open, placeholder_close, created_at, updated_at
 		FROM message_drafts
 		WHERE id = ?
 	`
@@ -70,8 +113,11 @@ func (r *DraftRepository) GetByID(id int64) (*MessageDraft, error) {
 	var draft MessageDraft
 	err := r.db.Conn().QueryRow(query, id).Scan(
 		&draft.ID,
+		&draft.WorkspaceID,
 		&draft.Title,
 		&draft.Content,
+		&draft.Delimiters.Open,
+		&draft.Delimiters.Close,
 		&draft.CreatedAt,
 		&draft.UpdatedAt,
 	)
@@ -86,17 +132,65 @@ func (r *DraftRepository) GetByID(id int64) (*MessageDraft, error) {
 	return &draft, nil
 }
 
-func (r *DraftRepository) GetAll() ([]MessageDraft, error) {
+// GetInWorkspace retrieves a draft, or nil if there is none with that ID in the workspace.
+func (r *DraftRepository) GetInWorkspace(workspaceID, id int64) (*MessageDraft, error) {
+	draft, err := r.GetByID(id)
+	if err != nil || draft == nil || draft.WorkspaceID != workspaceID {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// GetByTitle retrieves the most recently updated draft of the workspace with
+// the given title, ignoring ASCII case; exact matches are preferred over
+// case-only ones.
+func (r *DraftRepository) GetByTitle(workspaceID int64, title string) (*MessageDraft, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := `
+		SELECT id, workspace_id, title, content, placeholder_open, placeholder_close, created_at, updated_at
+		FROM message_drafts
+		WHERE workspace_id = ? AND title = ? COLLATE NOCASE
+		ORDER BY title = ? DESC, updated_at DESC
+		LIMIT 1
+	`
+
+	var draft MessageDraft
+	err := r.db.Conn().QueryRow(query, workspaceID, title, title).Scan(
+		&draft.ID,
+		&draft.WorkspaceID,
+		&draft.Title,
+		&draft.Content,
+		&draft.Delimiters.Open,
+		&draft.Delimiters.Close,
+		&draft.CreatedAt,
+		&draft.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft by title: %w", err)
+	}
+
+	return &draft, nil
+}
+
+// GetAll retrieves the drafts of a workspace, most recently updated first.
+func (r *DraftRepository) GetAll(workspaceID int64) ([]MessageDraft, error) {
 	r.db.RLock()
 	defer r.db.RUnlock()
 
 	query := `
-		SELECT id, title, content, created_at, updated_at
+		SELECT id, workspace_id, title, content, placeholder_open, placeholder_close, created_at, updated_at
 		FROM message_drafts
+		WHERE workspace_id = ?
 		ORDER BY updated_at DESC
 	`
 
-	rows, err := r.db.Conn().Query(query)
+	rows, err := r.db.Conn().Query(query, workspaceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query drafts: %w", err)
 	}
@@ -108,8 +202,11 @@ func (r *DraftRepository) GetAll() ([]MessageDraft, error) {
 		var draft MessageDraft
 		if err := rows.Scan(
 			&draft.ID,
+			&draft.WorkspaceID,
 			&draft.Title,
 			&draft.Content,
+			&draft.Delimiters.Open,
+			&draft.Delimiters.Close,
 			&draft.CreatedAt,
 			&draft.UpdatedAt,
 		); err != nil {
@@ -125,17 +222,19 @@ func (r *DraftRepository) GetAll() ([]MessageDraft, error) {
 	return drafts, nil
 }
 
+// Update changes a draft's title, content and delimiters. It returns false if
+// the draft does not exist in draft.WorkspaceID.
 func (r *DraftRepository) Update(draft *MessageDraft) (bool, error) {
 	r.db.Lock()
 	defer r.db.Unlock()
 
 	query := `
 		UPDATE message_drafts
-		SET title = ?, content = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
+		SET title = ?, content = ?, placeholder_open = ?, placeholder_close = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND workspace_id = ?
 	`
 
-	result, err := r.db.Conn().Exec(query, draft.Title, draft.Content, draft.ID)
+	result, err := r.db.Conn().Exec(query, draft.Title, draft.Content, draft.Delimiters.Open, draft.Delimiters.Close, draft.ID, draft.WorkspaceID)
 	if err != nil {
 		return false, fmt.Errorf("failed to update draft: %w", err)
 	}
@@ -159,11 +258,17 @@ func (r *DraftRepository) Update(draft *MessageDraft) (bool, error) {
 	return true, nil
 }
 
-func (r *DraftRepository) Delete(id int64) (bool, error) {
+// Delete removes a draft of the workspace by ID, with its variants; groups
+// using it as their default draft are left without one. It returns
+// ErrHasBatches when batch runs were created from it.
+func (r *DraftRepository) Delete(workspaceID, id int64) (bool, error) {
 	r.db.Lock()
 	defer r.db.Unlock()
 
-	result, err := r.db.Conn().Exec("DELETE FROM message_drafts WHERE id = ?", id)
+	result, err := r.db.Conn().Exec("DELETE FROM message_drafts WHERE id = ? AND workspace_id = ?", id, workspaceID)
+	if database.IsForeignKeyViolation(err) {
+		return false, ErrHasBatches
+	}
 	if err != nil {
 		return false, fmt.Errorf("failed to delete draft: %w", err)
 	}
@@ -175,3 +280,131 @@ func (r *DraftRepository) Delete(id int64) (bool, error) {
 
 	return rowsAffected > 0, nil
 }
+
+// MoveToWorkspace moves a draft to another workspace. Groups left behind stop
+// using it as their default draft; batches already created from it stay where
+// they are. It returns false if the draft does not exist.
+func (r *DraftRepository) MoveToWorkspace(id, workspaceID int64) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE message_drafts SET workspace_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", workspaceID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to move draft: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE contact_groups SET default_draft_id = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE default_draft_id = ? AND workspace_id != ?
+	`, id, workspaceID); err != nil {
+		return false, fmt.Errorf("failed to clear default drafts: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// copySuffix matches the suffix Duplicate adds, e.g. " (copy)" or " (copy 3)".
+var copySuffix = regexp.MustCompile(` \(copy(?: [0-9]+)?\)$`)
+
+// Duplicate copies a draft and its variants into a new draft of the same
+// workspace titled "<title> (copy)", or "(copy 2)", "(copy 3)" and so on when
+// that title is taken there. Copying a copy numbers from the original title
+// instead of stacking suffixes. Returns nil if the source draft does not exist
+// in the workspace.
+func (r *DraftRepository) Duplicate(workspaceID, id int64) (*MessageDraft, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var source MessageDraft
+	err = tx.QueryRow("SELECT workspace_id, title, content, placeholder_open, placeholder_close FROM message_drafts WHERE id = ? AND workspace_id = ?", id, workspaceID).Scan(&source.WorkspaceID, &source.Title, &source.Content, &source.Delimiters.Open, &source.Delimiters.Close)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+
+	rows, err := tx.Query("SELECT title FROM message_drafts WHERE workspace_id = ?", source.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query draft titles: %w", err)
+	}
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan draft title: %w", err)
+		}
+		taken[strings.ToLower(title)] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating draft titles: %w", err)
+	}
+
+	draft := &MessageDraft{WorkspaceID: source.WorkspaceID, Title: copyTitle(source.Title, taken), Content: source.Content, Delimiters: source.Delimiters}
+	result, err := tx.Exec(`
+		INSERT INTO message_drafts (workspace_id, title, content, placeholder_open, placeholder_close, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, draft.WorkspaceID, draft.Title, draft.Content, draft.Delimiters.Open, draft.Delimiters.Close)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create draft: %w", err)
+	}
+	if draft.ID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO draft_variants (draft_id, selector_key, selector_value, content, created_at, updated_at)
+		SELECT ?, selector_key, selector_value, content, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		FROM draft_variants WHERE draft_id = ?
+	`, draft.ID, id); err != nil {
+		return nil, fmt.Errorf("failed to copy draft variants: %w", err)
+	}
+
+	if err := tx.QueryRow(
+		"SELECT created_at, updated_at FROM message_drafts WHERE id = ?",
+		draft.ID,
+	).Scan(&draft.CreatedAt, &draft.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to read back draft: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit draft copy: %w", err)
+	}
+	return draft, nil
+}
+
+// copyTitle returns the first of "<title> (copy)", "<title> (copy 2)", ...
+// not in taken, which holds lowercased titles. A copy suffix already on title
+// is dropped first.
+func copyTitle(title string, taken map[string]bool) string {
+	base := copySuffix.ReplaceAllString(title, "")
+	candidate := base + " (copy)"
+	for n := 2; taken[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s (copy %d)", base, n)
+	}
+	return candidate
+}
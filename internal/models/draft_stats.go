@@ -0,0 +1,158 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// A draft counts as used by every batch that started sending it, including
+// ones later cancelled or failed. Batches cancelled while still queued never
+// sent anything and are only reported as CancelledBeforeStart. Deleted batches
+// take their messages with them and drop out of the numbers.
+
+// DraftUsage is the lightweight usage summary shown with each draft in lists.
+type DraftUsage struct {
+	TimesUsed  int        `json:"times_used"`
+	LastUsedAt *time.Time `json:"last_used_at"` // When the latest batch started; nil if never used
+}
+
+// DraftStats aggregates every batch run of a draft.
+type DraftStats struct {
+	DraftID              int64      `json:"draft_id"`
+	TimesUsed            int        `json:"times_used"`
+	LastUsedAt           *time.Time `json:"last_used_at"`
+	Queued               int        `json:"queued"`                 // Batches waiting to start
+	CancelledBeforeStart int        `json:"cancelled_before_start"` // Not counted in times_used
+	Sent                 int        `json:"sent"`
+	Failed               int        `json:"failed"`       // Includes recipients found not to be on WhatsApp
+	Pending              int        `json:"pending"`      // Not sent yet, in preparing, queued, running or interrupted batches
+	SuccessRate          float64    `json:"success_rate"` // Sent / (sent + failed), 0 when nothing was attempted
+
+	// Failed messages per failure code, e.g. "not_on_whatsapp"; ones failed
+	// before codes were recorded count as "unknown"
+	FailuresByCode map[string]int `json:"failures_by_code"`
+}
+
+// Usage returns the usage summary of every draft that has been used, keyed by
+// draft ID, in one grouped query. Drafts missing from the map were never used.
+func (r *DraftRepository) Usage() (map[int64]DraftUsage, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	rows, err := r.db.Conn().Query(`
+		SELECT draft_id, COUNT(*), MAX(started_at)
+		FROM batch_runs
+		WHERE started_at IS NOT NULL
+		GROUP BY draft_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query draft usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make(map[int64]DraftUsage)
+
+	for rows.Next() {
+		var draftID int64
+		var u DraftUsage
+		var lastUsed sql.NullString
+		if err := rows.Scan(&draftID, &u.TimesUsed, &lastUsed); err != nil {
+			return nil, fmt.Errorf("failed to scan draft usage: %w", err)
+		}
+		if u.LastUsedAt, err = parseAggregateTime(lastUsed); err != nil {
+			return nil, err
+		}
+		usage[draftID] = u
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating draft usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// Stats returns the batch and message totals of a draft; all zero if it was never used.
+func (r *DraftRepository) Stats(draftID int64) (*DraftStats, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	stats := &DraftStats{DraftID: draftID}
+
+	var lastUsed sql.NullString
+	err := r.db.Conn().QueryRow(`
+		SELECT
+			COUNT(started_at),
+			MAX(started_at),
+			COALESCE(SUM(CASE WHEN status = 'queued' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'cancelled' AND started_at IS NULL THEN 1 ELSE 0 END), 0)
+		FROM batch_runs
+		WHERE draft_id = ?
+	`, draftID).Scan(&stats.TimesUsed, &lastUsed, &stats.Queued, &stats.CancelledBeforeStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft batch stats: %w", err)
+	}
+	if stats.LastUsedAt, err = parseAggregateTime(lastUsed); err != nil {
+		return nil, err
+	}
+
+	// Pending only counts batches that can still send; cancelled ones never will
+	err = r.db.Conn().QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN m.status = 'sent' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN m.status = 'failed' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN m.status IN ('pending', 'sending') AND b.status IN ('preparing', 'queued', 'running', 'interrupted') THEN 1 ELSE 0 END), 0)
+		FROM batch_messages m
+		JOIN batch_runs b ON b.id = m.batch_run_id
+		WHERE b.draft_id = ?
+	`, draftID).Scan(&stats.Sent, &stats.Failed, &stats.Pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft message stats: %w", err)
+	}
+
+	if attempted := stats.Sent + stats.Failed; attempted > 0 {
+		stats.SuccessRate = float64(stats.Sent) / float64(attempted)
+	}
+
+	rows, err := r.db.Conn().Query(`
+		SELECT COALESCE(m.error_code, 'unknown'), COUNT(*)
+		FROM batch_messages m
+		JOIN batch_runs b ON b.id = m.batch_run_id
+		WHERE b.draft_id = ? AND m.status = 'failed'
+		GROUP BY 1
+	`, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count draft failures: %w", err)
+	}
+	defer rows.Close()
+
+	stats.FailuresByCode = make(map[string]int)
+	for rows.Next() {
+		var code string
+		var n int
+		if err := rows.Scan(&code, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan draft failures: %w", err)
+		}
+		stats.FailuresByCode[code] = n
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating draft failures: %w", err)
+	}
+
+	return stats, nil
+}
+
+// parseAggregateTime parses a MAX() over a timestamp column, which SQLite
+// returns as CURRENT_TIMESTAMP text rather than a typed time.
+func parseAggregateTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(sqliteTimeLayout, s.String, time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp %q: %w", s.String, err)
+	}
+	return &t, nil
+}
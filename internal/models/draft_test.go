@@ -0,0 +1,92 @@
+package models
+
+import "testing"
+
+func TestDuplicateNumbersCopies(t *testing.T) {
+	db := newTestDB(t)
+	drafts := NewDraftRepository(db)
+	variants := NewDraftVariantRepository(db)
+
+	original := &MessageDraft{Title: "Welcome", Content: "Hello {{name}}"}
+	if err := drafts.Create(original); err != nil {
+		t.Fatalf("failed to create draft: %v", err)
+	}
+	if err := variants.Set(&DraftVariant{DraftID: original.ID, SelectorKey: "lang", SelectorValue: "tr", Content: "Merhaba {{name}}"}); err != nil {
+		t.Fatalf("failed to set variant: %v", err)
+	}
+
+	duplicate := func(id int64) *MessageDraft {
+		t.Helper()
+		copied, err := drafts.Duplicate(DefaultWorkspaceID, id)
+		if err != nil || copied == nil {
+			t.Fatalf("Duplicate(%d) = %v, %v", id, copied, err)
+		}
+		return copied
+	}
+
+	var copies []*MessageDraft
+	for _, want := range []string{"Welcome (copy)", "Welcome (copy 2)", "Welcome (copy 3)", "Welcome (copy 4)"} {
+		copied := duplicate(original.ID)
+		if copied.Title != want {
+			t.Errorf("copy titled %q, want %q", copied.Title, want)
+		}
+		if copied.Content != original.Content {
+			t.Errorf("%s: content %q, want %q", copied.Title, copied.Content, original.Content)
+		}
+		copies = append(copies, copied)
+	}
+
+	// A copy of a copy numbers from the original title
+	if copied := duplicate(copies[1].ID); copied.Title != "Welcome (copy 5)" {
+		t.Errorf("copy of %q titled %q, want Welcome (copy 5)", copies[1].Title, copied.Title)
+	}
+
+	// Titles taken in another case are skipped too
+	if err := drafts.Create(&MessageDraft{Title: "WELCOME (copy 6)", Content: "Hi"}); err != nil {
+		t.Fatalf("failed to create draft: %v", err)
+	}
+	if copied := duplicate(original.ID); copied.Title != "Welcome (copy 7)" {
+		t.Errorf("copy titled %q, want Welcome (copy 7)", copied.Title)
+	}
+
+	// A freed title is reused
+	if _, err := drafts.Delete(DefaultWorkspaceID, copies[0].ID); err != nil {
+		t.Fatalf("failed to delete draft: %v", err)
+	}
+	if copied := duplicate(original.ID); copied.Title != "Welcome (copy)" {
+		t.Errorf("copy titled %q, want Welcome (copy) once it is free", copied.Title)
+	}
+
+	copiedVariants, err := variants.GetByDraft(copies[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copiedVariants) != 1 || copiedVariants[0].Content != "Merhaba {{name}}" {
+		t.Errorf("copy has variants %+v, want the original's", copiedVariants)
+	}
+}
+
+func TestCopyTitle(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		taken []string
+		want  string
+	}{
+		{title: "Welcome", want: "Welcome (copy)"},
+		{title: "Welcome", taken: []string{"welcome (copy)"}, want: "Welcome (copy 2)"},
+		{title: "Welcome", taken: []string{"welcome (copy)", "welcome (copy 2)", "welcome (copy 3)"}, want: "Welcome (copy 4)"},
+		{title: "Welcome", taken: []string{"welcome (copy 2)"}, want: "Welcome (copy)"},
+		{title: "Welcome (copy)", taken: []string{"welcome (copy)"}, want: "Welcome (copy 2)"},
+		{title: "Welcome (copy 9)", want: "Welcome (copy)"},
+		{title: "Welcome (copy) (copy)", want: "Welcome (copy) (copy)"},
+		{title: "Welcome (copy of mine)", want: "Welcome (copy of mine) (copy)"},
+	} {
+		taken := make(map[string]bool, len(tc.taken))
+		for _, title := range tc.taken {
+			taken[title] = true
+		}
+		if got := copyTitle(tc.title, taken); got != tc.want {
+			t.Errorf("copyTitle(%q, %q) = %q, want %q", tc.title, tc.taken, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"friday/internal/database"
+)
+
+// DraftVariant is alternative content for a draft, chosen for recipients whose
+// attribute SelectorKey equals SelectorValue (e.g. lang=en).
+type DraftVariant struct {
+	ID            int64     `json:"id"`
+	DraftID       int64     `json:"draft_id"`
+	SelectorKey   string    `json:"selector_key"`
+	SelectorValue string    `json:"selector_value"`
+	Content       string    `json:"content"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// DraftVariantRepository handles database operations for draft variants.
+type DraftVariantRepository struct {
+	db *database.DB
+}
+
+// NewDraftVariantRepository creates a new draft variant repository.
+func NewDraftVariantRepository(db *database.DB) *DraftVariantRepository {
+	return &DraftVariantRepository{db: db}
+}
+
+// Set creates or replaces the variant for a draft and selector (upsert).
+func (r *DraftVariantRepository) Set(variant *DraftVariant) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	query := `
+		INSERT INTO draft_variants (draft_id, selector_key, selector_value, content, created_at, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(draft_id, selector_key, selector_value) DO UPDATE SET
+			content = excluded.content,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	_, err := r.db.Conn().Exec(query, variant.DraftID, variant.SelectorKey, variant.SelectorValue, variant.Content)
+	if err != nil {
+		return fmt.Errorf("failed to set draft variant: %w", err)
+	}
+
+	row := r.db.Conn().QueryRow(
+		`SELECT id, created_at, updated_at FROM draft_variants
+		 WHERE draft_id = ? AND selector_key = ? AND selector_value = ?`,
+		variant.DraftID, variant.SelectorKey, variant.SelectorValue,
+	)
+	if err := row.Scan(&variant.ID, &variant.CreatedAt, &variant.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to read back draft variant: %w", err)
+	}
+
+	return nil
+}
+
+// GetByDraft returns all variants of a draft, ordered by selector.
+func (r *DraftVariantRepository) GetByDraft(draftID int64) ([]DraftVariant, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := `
+		SELECT id, draft_id, selector_key, selector_value, content, created_at, updated_at
+		FROM draft_variants
+		WHERE draft_id = ?
+		ORDER BY selector_key ASC, selector_value ASC
+	`
+
+	rows, err := r.db.Conn().Query(query, draftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query draft variants: %w", err)
+	}
+	defer rows.Close()
+
+	variants := []DraftVariant{}
+
+	for rows.Next() {
+		var v DraftVariant
+		if err := rows.Scan(
+			&v.ID,
+			&v.DraftID,
+			&v.SelectorKey,
+			&v.SelectorValue,
+			&v.Content,
+			&v.CreatedAt,
+			&v.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan draft variant: %w", err)
+		}
+		variants = append(variants, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating draft variants: %w", err)
+	}
+
+	return variants, nil
+}
+
+// Delete removes a variant of a draft.
+func (r *DraftVariantRepository) Delete(draftID, variantID int64) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec(
+		"DELETE FROM draft_variants WHERE id = ? AND draft_id = ?",
+		variantID, draftID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete draft variant: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// SelectVariant returns the first variant whose selector matches the recipient's
+// values (case-insensitive), or nil when the parent content should be used.
+func SelectVariant(variants []DraftVariant, values map[string]string) *DraftVariant {
+	for i := range variants {
+		value, ok := values[variants[i].SelectorKey]
+		if ok && strings.EqualFold(strings.TrimSpace(value), variants[i].SelectorValue) {
+			return &variants[i]
+		}
+	}
+	return nil
+}
+
+// HasSelectorValue reports whether the values contain any selector key used by the variants.
+func HasSelectorValue(variants []DraftVariant, values map[string]string) bool {
+	for _, v := range variants {
+		if values[v.SelectorKey] != "" {
+			return true
+		}
+	}
+	return false
+}
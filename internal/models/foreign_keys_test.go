@@ -0,0 +1,123 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"friday/internal/database"
+)
+
+// countRows counts the rows of table with column = value.
+func countRows(t *testing.T, db *database.DB, table, column string, value int64) int {
+	t.Helper()
+	var count int
+	if err := db.Conn().QueryRow("SELECT COUNT(*) FROM "+table+" WHERE "+column+" = ?", value).Scan(&count); err != nil {
+		t.Fatalf("failed to count %s: %v", table, err)
+	}
+	return count
+}
+
+func TestOrphanInsertsFail(t *testing.T) {
+	db := newTestDB(t)
+	const missing = 999
+	missingID := int64(missing)
+
+	for name, insert := range map[string]func() error{
+		"group member":    func() error { return NewGroupMemberRepository(db).Add(missing, "15550000001@s.whatsapp.net") },
+		"group attribute": func() error { return NewGroupAttributeRepository(db).Set(missing, "city", "Izmir") },
+		"draft variant": func() error {
+			return NewDraftVariantRepository(db).Set(&DraftVariant{DraftID: missing, SelectorKey: "lang", SelectorValue: "tr", Content: "Merhaba"})
+		},
+		"batch event": func() error { return NewBatchEventRepository(db).Record(missing, BatchEventStarted, "", "") },
+		"group default": func() error {
+			return NewGroupRepository(db).Create(&ContactGroup{Name: "Orphan", DefaultDraftID: &missingID})
+		},
+		"batch of no draft": func() error {
+			return NewBatchRunRepository(db).CreateWithMessages(&BatchRun{DraftID: missing, GroupID: missing, Status: BatchStatusQueued}, nil, nil)
+		},
+	} {
+		err := insert()
+		if !database.IsForeignKeyViolation(err) {
+			t.Errorf("%s referring to a missing row: error %v, want a foreign key violation", name, err)
+		}
+	}
+}
+
+func TestDeleteRules(t *testing.T) {
+	db := newTestDB(t)
+	drafts := NewDraftRepository(db)
+	groups := NewGroupRepository(db)
+	batches := NewBatchRunRepository(db)
+
+	draft := &MessageDraft{Title: "Welcome", Content: "Hello"}
+	if err := drafts.Create(draft); err != nil {
+		t.Fatalf("failed to create draft: %v", err)
+	}
+	if err := NewDraftVariantRepository(db).Set(&DraftVariant{DraftID: draft.ID, SelectorKey: "lang", SelectorValue: "tr", Content: "Merhaba"}); err != nil {
+		t.Fatalf("failed to create variant: %v", err)
+	}
+	group := &ContactGroup{Name: "Customers", DefaultDraftID: &draft.ID}
+	if err := groups.Create(group); err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+	jid := "15550000001@s.whatsapp.net"
+	if err := NewGroupMemberRepository(db).Add(group.ID, jid); err != nil {
+		t.Fatalf("failed to add member: %v", err)
+	}
+	if err := NewGroupAttributeRepository(db).Set(group.ID, "city", "Izmir"); err != nil {
+		t.Fatalf("failed to set group attribute: %v", err)
+	}
+
+	run := &BatchRun{DraftID: draft.ID, GroupID: group.ID, GroupName: group.Name, DraftTitle: draft.Title, Status: BatchStatusQueued, TotalCount: 1}
+	messages := []BatchMessage{{JID: jid, Status: MessageStatusPending, TemplateContent: draft.Content}}
+	recipients := []BatchRecipient{{JID: jid, Status: RecipientIncluded}}
+	if err := batches.CreateWithMessages(run, messages, recipients); err != nil {
+		t.Fatalf("failed to create batch: %v", err)
+	}
+	if err := NewBatchEventRepository(db).Record(run.ID, BatchEventStarted, "", ""); err != nil {
+		t.Fatalf("failed to record event: %v", err)
+	}
+
+	// Restrict: batch runs keep the draft and group they were created from
+	if _, err := drafts.Delete(DefaultWorkspaceID, draft.ID); !errors.Is(err, ErrHasBatches) {
+		t.Errorf("deleting a draft with batches: error %v, want ErrHasBatches", err)
+	}
+	if _, err := groups.Delete(DefaultWorkspaceID, group.ID); !errors.Is(err, ErrHasBatches) {
+		t.Errorf("deleting a group with batches: error %v, want ErrHasBatches", err)
+	}
+
+	// Cascade: a batch run takes its messages, events and recipients along
+	if deleted, err := batches.Delete(DefaultWorkspaceID, run.ID); err != nil || !deleted {
+		t.Fatalf("failed to delete batch: %t, %v", deleted, err)
+	}
+	for _, table := range []string{"batch_messages", "batch_events", "batch_recipients"} {
+		if n := countRows(t, db, table, "batch_run_id", run.ID); n != 0 {
+			t.Errorf("%d %s rows left after deleting their batch", n, table)
+		}
+	}
+
+	// Cascade for the draft's variants, set null for the group's default draft
+	if deleted, err := drafts.Delete(DefaultWorkspaceID, draft.ID); err != nil || !deleted {
+		t.Fatalf("failed to delete draft: %t, %v", deleted, err)
+	}
+	if n := countRows(t, db, "draft_variants", "draft_id", draft.ID); n != 0 {
+		t.Errorf("%d variants left after deleting their draft", n)
+	}
+	got, err := groups.GetByID(group.ID)
+	if err != nil || got == nil {
+		t.Fatalf("group gone with its default draft: %v, %v", got, err)
+	}
+	if got.DefaultDraftID != nil {
+		t.Errorf("default draft = %d after deleting it, want none", *got.DefaultDraftID)
+	}
+
+	// Cascade: a group takes its members and placeholder defaults along
+	if deleted, err := groups.Delete(DefaultWorkspaceID, group.ID); err != nil || !deleted {
+		t.Fatalf("failed to delete group: %t, %v", deleted, err)
+	}
+	for _, table := range []string{"group_members", "group_attributes"} {
+		if n := countRows(t, db, table, "group_id", group.ID); n != 0 {
+			t.Errorf("%d %s rows left after deleting their group", n, table)
+		}
+	}
+}
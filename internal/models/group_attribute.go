@@ -0,0 +1,162 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"friday/internal/database"
+)
+
+// GroupAttribute is a placeholder default for the members of a group. When a
+// message is sent to a member as part of the group, it fills in for attributes
+// the contact does not have; the contact's own value always wins.
+type GroupAttribute struct {
+	ID        int64     `json:"id"`
+	GroupID   int64     `json:"group_id"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GroupAttributeRepository handles database operations for group placeholder defaults.
+type GroupAttributeRepository struct {
+	db *database.DB
+}
+
+// NewGroupAttributeRepository creates a new group attribute repository.
+func NewGroupAttributeRepository(db *database.DB) *GroupAttributeRepository {
+	return &GroupAttributeRepository{db: db}
+}
+
+// Set creates or updates a group's default for key.
+func (r *GroupAttributeRepository) Set(groupID int64, key, value string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	_, err := r.db.Conn().Exec(`
+		INSERT INTO group_attributes (group_id, key, value, created_at, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(group_id, key) DO UPDATE SET
+			value = excluded.value,
+			updated_at = CURRENT_TIMESTAMP
+	`, groupID, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to set group attribute: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns a group's default for key, or nil if it has none.
+func (r *GroupAttributeRepository) Get(groupID int64, key string) (*GroupAttribute, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var attr GroupAttribute
+	err := r.db.Conn().QueryRow(`
+		SELECT id, group_id, key, value, created_at, updated_at
+		FROM group_attributes
+		WHERE group_id = ? AND key = ?
+	`, groupID, key).Scan(&attr.ID, &attr.GroupID, &attr.Key, &attr.Value, &attr.CreatedAt, &attr.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group attribute: %w", err)
+	}
+
+	return &attr, nil
+}
+
+// GetByGroup returns a group's defaults ordered by key.
+func (r *GroupAttributeRepository) GetByGroup(groupID int64) ([]GroupAttribute, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	rows, err := r.db.Conn().Query(`
+		SELECT id, group_id, key, value, created_at, updated_at
+		FROM group_attributes
+		WHERE group_id = ?
+		ORDER BY key ASC
+	`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group attributes: %w", err)
+	}
+	defer rows.Close()
+
+	attrs := []GroupAttribute{}
+
+	for rows.Next() {
+		var attr GroupAttribute
+		if err := rows.Scan(&attr.ID, &attr.GroupID, &attr.Key, &attr.Value, &attr.CreatedAt, &attr.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan group attribute: %w", err)
+		}
+		attrs = append(attrs, attr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating group attributes: %w", err)
+	}
+
+	return attrs, nil
+}
+
+// GetByGroupAsMap returns a group's defaults as key/value pairs, ready to merge
+// with a member's placeholder values.
+func (r *GroupAttributeRepository) GetByGroupAsMap(groupID int64) (map[string]string, error) {
+	attrs, err := r.GetByGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		values[attr.Key] = attr.Value
+	}
+	return values, nil
+}
+
+// GetAllKeys returns the keys any group has a default for.
+func (r *GroupAttributeRepository) GetAllKeys() ([]string, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	rows, err := r.db.Conn().Query("SELECT DISTINCT key FROM group_attributes ORDER BY key ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group attribute keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Delete removes a group's default for key. It returns false if there was none.
+func (r *GroupAttributeRepository) Delete(groupID int64, key string) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec("DELETE FROM group_attributes WHERE group_id = ? AND key = ?", groupID, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete group attribute: %w", err)
+	}
+
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
@@ -100,7 +100,82 @@ func (r *GroupMemberRepository) Remove(groupID int64, jid string) (bool, error)
 	return rowsAffected > 0, nil
 }
 
-// GetByGroup retrieves all members of a group.
+// Outcomes of moving one member, see Move.
+const (
+	MemberMoved           = "moved"
+	MemberAlreadyInTarget = "already_in_target" // Removed from the source; the target had it already
+	MemberNotInSource     = "not_member"
+)
+
+// MemberMove is what Move did with one JID.
+type MemberMove struct {
+	JID    string `json:"jid"`
+	Result string `json:"result"` // MemberMoved, MemberAlreadyInTarget or MemberNotInSource
+}
+
+// Move takes the given members out of one group and adds them to another in a
+// single transaction, so a member is never left in neither. Moved members go
+// to the end of the target's order. JIDs that are not members of the source
+// are reported and left alone.
+func (r *GroupMemberRepository) Move(fromGroupID, toGroupID int64, jids []string) ([]MemberMove, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // No-op if committed
+
+	remove, err := tx.Prepare("DELETE FROM group_members WHERE group_id = ? AND jid = ?")
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer remove.Close()
+
+	add, err := tx.Prepare(`
+		INSERT OR IGNORE INTO group_members (group_id, jid, added_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer add.Close()
+
+	moves := make([]MemberMove, 0, len(jids))
+	for _, jid := range jids {
+		result, err := remove.Exec(fromGroupID, jid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove member %s: %w", jid, err)
+		}
+		if removed, _ := result.RowsAffected(); removed == 0 {
+			moves = append(moves, MemberMove{JID: jid, Result: MemberNotInSource})
+			continue
+		}
+
+		result, err = add.Exec(toGroupID, jid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add member %s: %w", jid, err)
+		}
+		move := MemberMove{JID: jid, Result: MemberMoved}
+		if added, _ := result.RowsAffected(); added == 0 {
+			move.Result = MemberAlreadyInTarget
+		}
+		moves = append(moves, move)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return moves, nil
+}
+
+// memberOrder orders a group's members: the order set with Reorder, then members
+// added since in the order they were added.
+const memberOrder = `ORDER BY order_index IS NULL, order_index, added_at, id`
+
+// GetByGroup retrieves all members of a group in member order.
 // Note: Name and Phone fields are not populated - the handler must enrich these
 // from WhatsApp contact data.
 func (r *GroupMemberRepository) GetByGroup(groupID int64) ([]GroupMember, error) {
@@ -111,7 +186,7 @@ func (r *GroupMemberRepository) GetByGroup(groupID int64) ([]GroupMember, error)
 		SELECT id, group_id, jid, added_at
 		FROM group_members
 		WHERE group_id = ?
-		ORDER BY added_at ASC
+		` + memberOrder + `
 	`
 
 	rows, err := r.db.Conn().Query(query, groupID)
@@ -152,7 +227,7 @@ func (r *GroupMemberRepository) GetJIDsByGroup(groupID int64) ([]string, error)
 		SELECT jid
 		FROM group_members
 		WHERE group_id = ?
-		ORDER BY added_at ASC
+		` + memberOrder + `
 	`
 
 	rows, err := r.db.Conn().Query(query, groupID)
@@ -178,6 +253,79 @@ func (r *GroupMemberRepository) GetJIDsByGroup(groupID int64) ([]string, error)
 	return jids, nil
 }
 
+// Reorder puts the given members first, in that order, followed by the rest in
+// their current order; batches sent in group order follow it. It returns the
+// JIDs that are not members, changing nothing when there are any.
+func (r *GroupMemberRepository) Reorder(groupID int64, jids []string) ([]string, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // No-op if committed
+
+	rows, err := tx.Query(`SELECT jid FROM group_members WHERE group_id = ? `+memberOrder, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group members: %w", err)
+	}
+	current := []string{}
+	for rows.Next() {
+		var jid string
+		if err := rows.Scan(&jid); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan jid: %w", err)
+		}
+		current = append(current, jid)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jids: %w", err)
+	}
+
+	isMember := make(map[string]bool, len(current))
+	for _, jid := range current {
+		isMember[jid] = true
+	}
+	unknown := []string{}
+	listed := make(map[string]bool, len(jids))
+	for _, jid := range jids {
+		if !isMember[jid] {
+			unknown = append(unknown, jid)
+		}
+		listed[jid] = true
+	}
+	if len(unknown) > 0 {
+		return unknown, nil
+	}
+
+	order := append([]string{}, jids...)
+	for _, jid := range current {
+		if !listed[jid] {
+			order = append(order, jid)
+		}
+	}
+
+	stmt, err := tx.Prepare("UPDATE group_members SET order_index = ? WHERE group_id = ? AND jid = ?")
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for i, jid := range order {
+		if _, err := stmt.Exec(i, groupID, jid); err != nil {
+			return nil, fmt.Errorf("failed to reorder member %s: %w", jid, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil, nil
+}
+
 // IsMember checks if a contact is a member of a group.
 func (r *GroupMemberRepository) IsMember(groupID int64, jid string) (bool, error) {
 	r.db.RLock()
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"friday/internal/database"
+)
+
+// IdempotencyRecord is a stored Idempotency-Key and, once the request finished, its response.
+type IdempotencyRecord struct {
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	Completed    bool // false while the first request is still being processed
+	CreatedAt    time.Time
+}
+
+// idempotencyPendingTimeout is how long a reservation without a stored response
+// blocks retries; after that the first request is assumed lost (e.g. a crash).
+const idempotencyPendingTimeout = 5 * time.Minute
+
+// IdempotencyRepository stores idempotency keys so retried POSTs replay the first response.
+type IdempotencyRepository struct {
+	db *database.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository.
+func NewIdempotencyRepository(db *database.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve claims a key for a new request. If the key is already stored (and younger
+// than ttl) the existing record is returned with reserved=false. Claiming happens in
+// one transaction under the write lock, so two concurrent requests with the same key
+// can never both proceed to create the resource.
+func (r *IdempotencyRepository) Reserve(key, requestHash string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	cutoff := time.Now().Add(-ttl).UTC()
+	if _, err := tx.Exec("DELETE FROM idempotency_keys WHERE created_at < ?", cutoff); err != nil {
+		return nil, false, fmt.Errorf("failed to expire idempotency keys: %w", err)
+	}
+
+	var rec IdempotencyRecord
+	var body []byte
+	err = tx.QueryRow(`
+		SELECT key, request_hash, status_code, content_type, response_body, completed, created_at
+		FROM idempotency_keys WHERE key = ?
+	`, key).Scan(&rec.Key, &rec.RequestHash, &rec.StatusCode, &rec.ContentType, &body, &rec.Completed, &rec.CreatedAt)
+	switch {
+	case err == nil && (rec.Completed || time.Since(rec.CreatedAt) < idempotencyPendingTimeout):
+		rec.ResponseBody = body
+		return &rec, false, nil
+	case err == nil:
+		// Abandoned reservation, take it over
+		if _, err := tx.Exec("DELETE FROM idempotency_keys WHERE key = ?", key); err != nil {
+			return nil, false, fmt.Errorf("failed to clear stale idempotency key: %w", err)
+		}
+	case err != sql.ErrNoRows:
+		return nil, false, fmt.Errorf("failed to read idempotency key: %w", err)
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO idempotency_keys (key, request_hash) VALUES (?, ?)",
+		key, requestHash,
+	); err != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil, true, nil
+}
+
+// Complete stores the response of the request that reserved the key.
+func (r *IdempotencyRepository) Complete(key string, statusCode int, contentType string, body []byte) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	_, err := r.db.Conn().Exec(`
+		UPDATE idempotency_keys
+		SET status_code = ?, content_type = ?, response_body = ?, completed = 1
+		WHERE key = ?
+	`, statusCode, contentType, body, key)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+// Release drops a reservation so the request can be retried with the same key.
+func (r *IdempotencyRepository) Release(key string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	if _, err := r.db.Conn().Exec("DELETE FROM idempotency_keys WHERE key = ?", key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+
+	"friday/internal/database"
+)
+
+// jidNormalizationMigration names the one-time JID rewrite in data_migrations.
+const jidNormalizationMigration = "normalize_jids"
+
+// jidColumn describes a table holding contact JIDs. Tables where the JID is part
+// of a unique key list the other key columns in scope (empty when the JID alone
+// is the key); when two JIDs normalize to the same value, the row with the
+// highest keep value survives, or the lowest with keepOldest.
+type jidColumn struct {
+	table      string
+	scope      []string
+	keep       string
+	keepOldest bool
+}
+
+var jidColumns = []jidColumn{
+	{table: "contact_attributes", scope: []string{"key"}, keep: "updated_at"},
+	{table: "attribute_history"},
+	{table: "group_members", scope: []string{"group_id"}, keep: "added_at", keepOldest: true},
+	{table: "batch_messages"},
+	{table: "batch_events"},
+	{table: "batch_recipients", scope: []string{"batch_run_id"}, keep: "id", keepOldest: true},
+	{table: "contact_validations", scope: []string{}, keep: "checked_at"},
+	{table: "recipient_failures", scope: []string{}, keep: "last_failed_at"},
+	{table: "contact_notes", scope: []string{}, keep: "updated_at"},
+}
+
+// JIDNormalizationResult reports what NormalizeStoredJIDs changed.
+type JIDNormalizationResult struct {
+	Rewritten int      // JIDs replaced by their normalized form, counted once per table
+	Merged    int      // Rows dropped because another row already held the normalized JID
+	Invalid   []string // Stored JIDs that do not normalize; left as they are
+}
+
+// NormalizeStoredJIDs rewrites every stored contact JID to normalize's result,
+// once per database; later calls return nil. Duplicates that end up with the
+// same JID are merged per table, keeping the newest attribute, note, validation
+// and failure record and the oldest group membership.
+func NormalizeStoredJIDs(db *database.DB, normalize func(string) (string, error)) (*JIDNormalizationResult, error) {
+	db.Lock()
+	defer db.Unlock()
+
+	var applied int
+	if err := db.Conn().QueryRow("SELECT COUNT(*) FROM data_migrations WHERE name = ?", jidNormalizationMigration).Scan(&applied); err != nil {
+		return nil, fmt.Errorf("failed to check data migrations: %w", err)
+	}
+	if applied > 0 {
+		return nil, nil
+	}
+
+	tx, err := db.Conn().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &JIDNormalizationResult{}
+	invalid := make(map[string]bool)
+	for _, c := range jidColumns {
+		if err := normalizeJIDColumn(tx, c, normalize, result, invalid); err != nil {
+			return nil, fmt.Errorf("failed to normalize %s: %w", c.table, err)
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO data_migrations (name) VALUES (?)", jidNormalizationMigration); err != nil {
+		return nil, fmt.Errorf("failed to record data migration: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+func normalizeJIDColumn(tx *sql.Tx, c jidColumn, normalize func(string) (string, error), result *JIDNormalizationResult, invalid map[string]bool) error {
+	rows, err := tx.Query("SELECT DISTINCT jid FROM " + c.table + " WHERE jid IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	var jids []string
+	for rows.Next() {
+		var jid string
+		if err := rows.Scan(&jid); err != nil {
+			rows.Close()
+			return err
+		}
+		jids = append(jids, jid)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, old := range jids {
+		normalized, err := normalize(old)
+		if err != nil {
+			if !invalid[old] {
+				invalid[old] = true
+				result.Invalid = append(result.Invalid, old)
+			}
+			continue
+		}
+		if normalized == old {
+			continue
+		}
+
+		if c.scope != nil {
+			merged, err := mergeJIDRows(tx, c, old, normalized)
+			if err != nil {
+				return err
+			}
+			result.Merged += merged
+		}
+
+		if _, err := tx.Exec("UPDATE "+c.table+" SET jid = ? WHERE jid = ?", normalized, old); err != nil {
+			return err
+		}
+		result.Rewritten++
+	}
+
+	return nil
+}
+
+// mergeJIDRows deletes whichever of old's and normalized's rows would collide
+// once old is renamed, keeping the one c.keep prefers (normalized's on a tie).
+// Returns how many rows were deleted.
+func mergeJIDRows(tx *sql.Tx, c jidColumn, old, normalized string) (int, error) {
+	match := "other.jid = ?"
+	for _, col := range c.scope {
+		match += fmt.Sprintf(" AND other.%s = %s.%s", col, c.table, col)
+	}
+
+	wins := ">="
+	if c.keepOldest {
+		wins = "<="
+	}
+
+	// Old rows that lose to an existing normalized row
+	res, err := tx.Exec(fmt.Sprintf(
+		"DELETE FROM %[1]s WHERE jid = ? AND EXISTS (SELECT 1 FROM %[1]s other WHERE %[2]s AND other.%[3]s %[4]s %[1]s.%[3]s)",
+		c.table, match, c.keep, wins), old, normalized)
+	if err != nil {
+		return 0, err
+	}
+	dropped, _ := res.RowsAffected()
+
+	// Normalized rows that lose to the old rows still left
+	res, err = tx.Exec(fmt.Sprintf(
+		"DELETE FROM %[1]s WHERE jid = ? AND EXISTS (SELECT 1 FROM %[1]s other WHERE %[2]s)",
+		c.table, match), normalized, old)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+
+	return int(dropped + n), nil
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"friday/internal/database"
+)
+
+// Maintenance triggers.
+const (
+	MaintenanceTriggerScheduled = "scheduled"
+	MaintenanceTriggerManual    = "manual"
+)
+
+// MaintenanceRun records one database maintenance pass.
+type MaintenanceRun struct {
+	ID              int64     `json:"id"`
+	Trigger         string    `json:"trigger"` // "scheduled" or "manual"
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	BatchRunsPruned int       `json:"batch_runs_pruned"` // Finished batches deleted with their messages, past maintenance.retention_days
+	OutboxPruned    int       `json:"outbox_pruned"`     // Sent, failed and expired outbox messages deleted
+	FreePercent     float64   `json:"free_percent"`      // Share of the file that was free pages after pruning
+	Vacuumed        bool      `json:"vacuumed"`
+	BytesFreed      int64     `json:"bytes_freed"` // How much VACUUM shrank the file
+	ErrorMessage    *string   `json:"error_message,omitempty"`
+}
+
+// MaintenanceRepository handles database operations for maintenance records.
+type MaintenanceRepository struct {
+	db *database.DB
+}
+
+// NewMaintenanceRepository creates a new maintenance repository.
+func NewMaintenanceRepository(db *database.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+// Create stores a finished maintenance pass and sets its ID.
+func (r *MaintenanceRepository) Create(run *MaintenanceRun) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec(`
+		INSERT INTO maintenance_runs (trigger, started_at, finished_at, batch_runs_pruned, outbox_pruned, free_percent, vacuumed, bytes_freed, error_message)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.Trigger, run.StartedAt.UTC(), run.FinishedAt.UTC(), run.BatchRunsPruned, run.OutboxPruned, run.FreePercent, run.Vacuumed, run.BytesFreed, run.ErrorMessage)
+	if err != nil {
+		return fmt.Errorf("failed to record maintenance run: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get maintenance run ID: %w", err)
+	}
+	run.ID = id
+	return nil
+}
+
+// Last returns the newest maintenance pass, or nil if none ran yet.
+func (r *MaintenanceRepository) Last() (*MaintenanceRun, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var run MaintenanceRun
+	var errMsg sql.NullString
+	err := r.db.Conn().QueryRow(`
+		SELECT id, trigger, started_at, finished_at, batch_runs_pruned, outbox_pruned, free_percent, vacuumed, bytes_freed, error_message
+		FROM maintenance_runs
+		ORDER BY id DESC
+		LIMIT 1
+	`).Scan(&run.ID, &run.Trigger, &run.StartedAt, &run.FinishedAt, &run.BatchRunsPruned, &run.OutboxPruned, &run.FreePercent, &run.Vacuumed, &run.BytesFreed, &errMsg)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last maintenance run: %w", err)
+	}
+	if errMsg.Valid {
+		run.ErrorMessage = &errMsg.String
+	}
+	return &run, nil
+}
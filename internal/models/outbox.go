@@ -0,0 +1,243 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"friday/internal/database"
+)
+
+// OutboxStatus is where a queued send stands.
+type OutboxStatus string
+
+const (
+	OutboxStatusQueued  OutboxStatus = "queued"
+	OutboxStatusSent    OutboxStatus = "sent"
+	OutboxStatusFailed  OutboxStatus = "failed"
+	OutboxStatusExpired OutboxStatus = "expired" // Still unsent when its TTL ran out
+)
+
+// OutboxItem is a single message accepted while WhatsApp was disconnected,
+// sent by the worker once the connection is back.
+type OutboxItem struct {
+	ID           int64        `json:"id"`
+	JID          string       `json:"jid"`
+	Body         string       `json:"body"`
+	Status       OutboxStatus `json:"status"`
+	ErrorMessage *string      `json:"error_message,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	ExpiresAt    time.Time    `json:"expires_at"`
+	SentAt       *time.Time   `json:"sent_at,omitempty"`
+}
+
+// OutboxRepository handles database operations for the outbox.
+type OutboxRepository struct {
+	db *database.DB
+}
+
+// NewOutboxRepository creates a new outbox repository.
+func NewOutboxRepository(db *database.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+const outboxColumns = "id, jid, body, status, error_message, created_at, expires_at, sent_at"
+
+func scanOutboxItem(row rowScanner) (*OutboxItem, error) {
+	var item OutboxItem
+	var errMsg sql.NullString
+	var sentAt sql.NullTime
+	if err := row.Scan(&item.ID, &item.JID, &item.Body, &item.Status, &errMsg, &item.CreatedAt, &item.ExpiresAt, &sentAt); err != nil {
+		return nil, err
+	}
+	if errMsg.Valid {
+		item.ErrorMessage = &errMsg.String
+	}
+	if sentAt.Valid {
+		item.SentAt = &sentAt.Time
+	}
+	return &item, nil
+}
+
+// Create queues a message that expires ttl from now.
+func (r *OutboxRepository) Create(jid, body string, ttl time.Duration) (*OutboxItem, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	// Same text format as CURRENT_TIMESTAMP so expiry compares as a string
+	expiresAt := time.Now().UTC().Add(ttl).Format(sqliteTimeLayout)
+	result, err := r.db.Conn().Exec(
+		"INSERT INTO outbox (jid, body, expires_at) VALUES (?, ?, ?)",
+		jid, body, expiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue outbox message: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox ID: %w", err)
+	}
+
+	item, err := scanOutboxItem(r.db.Conn().QueryRow("SELECT "+outboxColumns+" FROM outbox WHERE id = ?", id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queued outbox message: %w", err)
+	}
+	return item, nil
+}
+
+// GetByID returns an outbox item, or nil if it does not exist.
+func (r *OutboxRepository) GetByID(id int64) (*OutboxItem, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	item, err := scanOutboxItem(r.db.Conn().QueryRow("SELECT "+outboxColumns+" FROM outbox WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox message: %w", err)
+	}
+	return item, nil
+}
+
+// GetAll returns outbox items oldest first, only those with the given status
+// unless it is empty.
+func (r *OutboxRepository) GetAll(status OutboxStatus) ([]OutboxItem, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := "SELECT " + outboxColumns + " FROM outbox"
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.db.Conn().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	items := []OutboxItem{}
+	for rows.Next() {
+		item, err := scanOutboxItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		items = append(items, *item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox: %w", err)
+	}
+
+	return items, nil
+}
+
+// NextQueued returns the oldest queued item that has not expired, or nil.
+func (r *OutboxRepository) NextQueued() (*OutboxItem, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	item, err := scanOutboxItem(r.db.Conn().QueryRow(
+		"SELECT " + outboxColumns + " FROM outbox WHERE status = 'queued' AND expires_at > CURRENT_TIMESTAMP ORDER BY id LIMIT 1",
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next outbox message: %w", err)
+	}
+	return item, nil
+}
+
+// Expire marks queued items past their expiry as expired and returns how many there were.
+func (r *OutboxRepository) Expire() (int, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec(
+		"UPDATE outbox SET status = 'expired' WHERE status = 'queued' AND expires_at <= CURRENT_TIMESTAMP",
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire outbox messages: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rows), nil
+}
+
+// MarkSent records that an item was delivered to WhatsApp.
+func (r *OutboxRepository) MarkSent(id int64) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	_, err := r.db.Conn().Exec(
+		"UPDATE outbox SET status = 'sent', sent_at = CURRENT_TIMESTAMP WHERE id = ?",
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message sent: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records that sending an item failed.
+func (r *OutboxRepository) MarkFailed(id int64, errMsg string) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	_, err := r.db.Conn().Exec(
+		"UPDATE outbox SET status = 'failed', error_message = ? WHERE id = ?",
+		errMsg, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message failed: %w", err)
+	}
+	return nil
+}
+
+// DeleteFinishedBefore removes sent, failed and expired items created before
+// cutoff and returns how many there were.
+func (r *OutboxRepository) DeleteFinishedBefore(cutoff time.Time) (int, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec(
+		"DELETE FROM outbox WHERE status != 'queued' AND created_at < ?",
+		cutoff.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune outbox: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rows), nil
+}
+
+// Delete removes an outbox item whatever its status. Returns false if it did not exist.
+func (r *OutboxRepository) Delete(id int64) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec("DELETE FROM outbox WHERE id = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete outbox message: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"friday/internal/database"
+)
+
+// RecipientFailure tracks consecutive permanent send failures for a JID. Once the
+// count reaches the quarantine threshold the contact is quarantined and left out
+// of new batches until cleared.
+type RecipientFailure struct {
+	JID                 string     `json:"jid"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error"`
+	LastFailedAt        time.Time  `json:"last_failed_at"`
+	QuarantinedAt       *time.Time `json:"quarantined_at,omitempty"`
+}
+
+// QuarantineRepository handles database operations for recipient failure counters.
+type QuarantineRepository struct {
+	db *database.DB
+}
+
+// NewQuarantineRepository creates a new quarantine repository.
+func NewQuarantineRepository(db *database.DB) *QuarantineRepository {
+	return &QuarantineRepository{db: db}
+}
+
+// RecordFailure counts a permanent failure for jid and quarantines it when the
+// count reaches threshold (0 disables quarantining). Returns true only when this
+// failure caused the quarantine.
+func (r *QuarantineRepository) RecordFailure(jid, errorMessage string, threshold int) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO recipient_failures (jid, consecutive_failures, last_error, last_failed_at)
+		VALUES (?, 1, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(jid) DO UPDATE SET
+			consecutive_failures = consecutive_failures + 1,
+			last_error = excluded.last_error,
+			last_failed_at = CURRENT_TIMESTAMP
+	`, jid, errorMessage)
+	if err != nil {
+		return false, fmt.Errorf("failed to record failure: %w", err)
+	}
+
+	quarantined := false
+	if threshold > 0 {
+		result, err := tx.Exec(`
+			UPDATE recipient_failures SET quarantined_at = CURRENT_TIMESTAMP
+			WHERE jid = ? AND quarantined_at IS NULL AND consecutive_failures >= ?
+		`, jid, threshold)
+		if err != nil {
+			return false, fmt.Errorf("failed to quarantine recipient: %w", err)
+		}
+		rows, _ := result.RowsAffected()
+		quarantined = rows > 0
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return quarantined, nil
+}
+
+// Clear removes the failure counter and any quarantine for jid. Returns false if
+// there was nothing to clear.
+func (r *QuarantineRepository) Clear(jid string) (bool, error) {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec("DELETE FROM recipient_failures WHERE jid = ?", jid)
+	if err != nil {
+		return false, fmt.Errorf("failed to clear recipient failures: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// GetQuarantined returns all quarantined recipients, most recently quarantined first.
+func (r *QuarantineRepository) GetQuarantined() ([]RecipientFailure, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	rows, err := r.db.Conn().Query(`
+		SELECT jid, consecutive_failures, last_error, last_failed_at, quarantined_at
+		FROM recipient_failures
+		WHERE quarantined_at IS NOT NULL
+		ORDER BY quarantined_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quarantined recipients: %w", err)
+	}
+	defer rows.Close()
+
+	failures := []RecipientFailure{}
+	for rows.Next() {
+		var f RecipientFailure
+		var lastError sql.NullString
+		var quarantinedAt sql.NullTime
+		if err := rows.Scan(&f.JID, &f.ConsecutiveFailures, &lastError, &f.LastFailedAt, &quarantinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan quarantined recipient: %w", err)
+		}
+		f.LastError = lastError.String
+		if quarantinedAt.Valid {
+			f.QuarantinedAt = &quarantinedAt.Time
+		}
+		failures = append(failures, f)
+	}
+
+	return failures, rows.Err()
+}
+
+// QuarantinedJIDs returns the set of quarantined JIDs.
+func (r *QuarantineRepository) QuarantinedJIDs() (map[string]bool, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	rows, err := r.db.Conn().Query("SELECT jid FROM recipient_failures WHERE quarantined_at IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quarantined recipients: %w", err)
+	}
+	defer rows.Close()
+
+	jids := make(map[string]bool)
+	for rows.Next() {
+		var jid string
+		if err := rows.Scan(&jid); err != nil {
+			return nil, fmt.Errorf("failed to scan quarantined recipient: %w", err)
+		}
+		jids[jid] = true
+	}
+
+	return jids, rows.Err()
+}
+
+// Get returns the failure counter for jid, or nil if it has none.
+func (r *QuarantineRepository) Get(jid string) (*RecipientFailure, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var f RecipientFailure
+	var lastError sql.NullString
+	var quarantinedAt sql.NullTime
+	err := r.db.Conn().QueryRow(`
+		SELECT jid, consecutive_failures, last_error, last_failed_at, quarantined_at
+		FROM recipient_failures
+		WHERE jid = ?
+	`, jid).Scan(&f.JID, &f.ConsecutiveFailures, &lastError, &f.LastFailedAt, &quarantinedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipient failures: %w", err)
+	}
+	f.LastError = lastError.String
+	if quarantinedAt.Valid {
+		f.QuarantinedAt = &quarantinedAt.Time
+	}
+	return &f, nil
+}
@@ -0,0 +1,288 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"friday/internal/database"
+)
+
+// reportTopErrors is how many distinct failure messages a weekly report lists.
+const reportTopErrors = 10
+
+// sqliteTimeLayout matches CURRENT_TIMESTAMP, which every timestamp column is
+// written with (UTC), so range bounds compare as plain strings.
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// WeeklyReport summarises one ISO week of sending activity.
+type WeeklyReport struct {
+	Week        string              `json:"week"` // ISO week, e.g. 2025-W12
+	Timezone    string              `json:"timezone"`
+	Start       time.Time           `json:"start"`
+	End         time.Time           `json:"end"` // Exclusive
+	Batches     ReportBatchTotals   `json:"batches"`
+	Messages    ReportMessageTotals `json:"messages"`
+	Days        []ReportDay         `json:"days"`
+	TopErrors   []ReportError       `json:"top_errors"`
+	NewContacts int                 `json:"new_contacts"` // Distinct contacts added to any group
+	NewMembers  []ReportGroupGrowth `json:"new_members"`
+	BatchRuns   []BatchRun          `json:"batch_runs"` // Batches started during the week
+}
+
+// ReportBatchTotals counts the batches started during the week by their current status.
+type ReportBatchTotals struct {
+	Started   int `json:"started"`
+	Completed int `json:"completed"`
+	Cancelled int `json:"cancelled"`
+	Failed    int `json:"failed"`
+	Running   int `json:"running"`
+}
+
+type ReportMessageTotals struct {
+	Sent        int     `json:"sent"`
+	Failed      int     `json:"failed"`
+	SuccessRate float64 `json:"success_rate"` // Percent of attempts that were sent; 0 without attempts
+}
+
+// ReportDay is one day of the week in the report's timezone.
+type ReportDay struct {
+	Date   string `json:"date"` // 2006-01-02
+	Sent   int    `json:"sent"`
+	Failed int    `json:"failed"`
+}
+
+type ReportError struct {
+	Error string `json:"error"`
+	Count int    `json:"count"`
+}
+
+type ReportGroupGrowth struct {
+	GroupID   int64  `json:"group_id"`
+	GroupName string `json:"group_name"`
+	Added     int    `json:"added"`
+}
+
+// ReportRepository aggregates activity across batches, messages and groups.
+type ReportRepository struct {
+	db *database.DB
+}
+
+// NewReportRepository creates a new report repository.
+func NewReportRepository(db *database.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// ParseISOWeek parses "2025-W12" and returns midnight on that week's Monday in loc.
+func ParseISOWeek(s string, loc *time.Location) (time.Time, error) {
+	yearPart, weekPart, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(s)), "-W")
+	year, yearErr := strconv.Atoi(yearPart)
+	week, weekErr := strconv.Atoi(weekPart)
+	if !ok || yearErr != nil || weekErr != nil || len(yearPart) != 4 || len(weekPart) != 2 {
+		return time.Time{}, fmt.Errorf("%q is not an ISO week (use YYYY-Www, e.g. 2025-W12)", s)
+	}
+
+	// Week 1 is the week containing January 4th
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	start := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(week-1)*7)
+	if y, w := start.ISOWeek(); week < 1 || y != year || w != week {
+		return time.Time{}, fmt.Errorf("%d has no week %d", year, week)
+	}
+	return start, nil
+}
+
+// ISOWeekStart returns midnight on the Monday of t's ISO week, in t's location.
+func ISOWeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// Weekly builds the report for the week starting at start (a Monday midnight,
+// see ParseISOWeek). Days are bucketed by start's UTC offset, so a DST change
+// mid-week shifts the bucket edges by an hour after it.
+func (r *ReportRepository) Weekly(start time.Time) (*WeeklyReport, error) {
+	end := start.AddDate(0, 0, 7)
+	year, week := start.ISOWeek()
+	report := &WeeklyReport{
+		Week:     fmt.Sprintf("%04d-W%02d", year, week),
+		Timezone: start.Location().String(),
+		Start:    start,
+		End:      end,
+	}
+
+	from := start.UTC().Format(sqliteTimeLayout)
+	to := end.UTC().Format(sqliteTimeLayout)
+	_, offset := start.Zone()
+	shift := fmt.Sprintf("%+d minutes", offset/60)
+
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	if err := r.weeklyDays(report, shift, from, to); err != nil {
+		return nil, err
+	}
+	if err := r.weeklyErrors(report, from, to); err != nil {
+		return nil, err
+	}
+	if err := r.weeklyMembers(report, from, to); err != nil {
+		return nil, err
+	}
+	if err := r.weeklyBatches(report, from, to); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// weeklyDays fills Days and Messages. Sent messages are dated by sent_at, failed
+// ones by failed_at; failures recorded before failed_at existed are not counted.
+func (r *ReportRepository) weeklyDays(report *WeeklyReport, shift, from, to string) error {
+	rows, err := r.db.Conn().Query(`
+		SELECT day, SUM(sent), SUM(failed) FROM (
+			SELECT date(sent_at, ?) AS day, 1 AS sent, 0 AS failed
+			FROM batch_messages
+			WHERE status = 'sent' AND sent_at >= ? AND sent_at < ?
+			UNION ALL
+			SELECT date(failed_at, ?), 0, 1
+			FROM batch_messages
+			WHERE status = 'failed' AND failed_at >= ? AND failed_at < ?
+		)
+		GROUP BY day
+	`, shift, from, to, shift, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query daily message counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]ReportDay)
+	for rows.Next() {
+		var d ReportDay
+		if err := rows.Scan(&d.Date, &d.Sent, &d.Failed); err != nil {
+			return fmt.Errorf("failed to scan daily message counts: %w", err)
+		}
+		counts[d.Date] = d
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating daily message counts: %w", err)
+	}
+
+	report.Days = make([]ReportDay, 7)
+	for i := range report.Days {
+		date := report.Start.AddDate(0, 0, i).Format("2006-01-02")
+		d := counts[date]
+		d.Date = date
+		report.Days[i] = d
+		report.Messages.Sent += d.Sent
+		report.Messages.Failed += d.Failed
+	}
+	if attempts := report.Messages.Sent + report.Messages.Failed; attempts > 0 {
+		report.Messages.SuccessRate = float64(report.Messages.Sent*1000/attempts) / 10
+	}
+
+	return nil
+}
+
+func (r *ReportRepository) weeklyErrors(report *WeeklyReport, from, to string) error {
+	rows, err := r.db.Conn().Query(`
+		SELECT COALESCE(error_message, ''), COUNT(*) AS n
+		FROM batch_messages
+		WHERE status = 'failed' AND failed_at >= ? AND failed_at < ?
+		GROUP BY 1
+		ORDER BY n DESC, 1
+		LIMIT ?
+	`, from, to, reportTopErrors)
+	if err != nil {
+		return fmt.Errorf("failed to query failure reasons: %w", err)
+	}
+	defer rows.Close()
+
+	report.TopErrors = []ReportError{}
+	for rows.Next() {
+		var e ReportError
+		if err := rows.Scan(&e.Error, &e.Count); err != nil {
+			return fmt.Errorf("failed to scan failure reason: %w", err)
+		}
+		report.TopErrors = append(report.TopErrors, e)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating failure reasons: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ReportRepository) weeklyMembers(report *WeeklyReport, from, to string) error {
+	err := r.db.Conn().QueryRow(`
+		SELECT COUNT(DISTINCT jid) FROM group_members WHERE added_at >= ? AND added_at < ?
+	`, from, to).Scan(&report.NewContacts)
+	if err != nil {
+		return fmt.Errorf("failed to count new contacts: %w", err)
+	}
+
+	rows, err := r.db.Conn().Query(`
+		SELECT g.id, g.name, COUNT(*) AS n
+		FROM group_members m
+		JOIN contact_groups g ON g.id = m.group_id
+		WHERE m.added_at >= ? AND m.added_at < ?
+		GROUP BY g.id
+		ORDER BY n DESC, g.name
+	`, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query new group members: %w", err)
+	}
+	defer rows.Close()
+
+	report.NewMembers = []ReportGroupGrowth{}
+	for rows.Next() {
+		var g ReportGroupGrowth
+		if err := rows.Scan(&g.GroupID, &g.GroupName, &g.Added); err != nil {
+			return fmt.Errorf("failed to scan new group members: %w", err)
+		}
+		report.NewMembers = append(report.NewMembers, g)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating new group members: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ReportRepository) weeklyBatches(report *WeeklyReport, from, to string) error {
+	rows, err := r.db.Conn().Query(`
+		SELECT `+batchRunColumns+`
+		FROM batch_runs
+		WHERE started_at >= ? AND started_at < ?
+		ORDER BY started_at, id
+	`, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query batch runs: %w", err)
+	}
+	defer rows.Close()
+
+	report.BatchRuns = []BatchRun{}
+	for rows.Next() {
+		run, err := scanBatchRun(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan batch run: %w", err)
+		}
+		report.BatchRuns = append(report.BatchRuns, *run)
+
+		report.Batches.Started++
+		switch run.Status {
+		case BatchStatusCompleted:
+			report.Batches.Completed++
+		case BatchStatusCancelled:
+			report.Batches.Cancelled++
+		case BatchStatusFailed:
+			report.Batches.Failed++
+		case BatchStatusRunning, BatchStatusInterrupted:
+			report.Batches.Running++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating batch runs: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,345 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"friday/internal/database"
+)
+
+// Setting keys. Values are stored as text; use the typed getters to read them.
+const (
+	SettingBatchMinDelaySeconds = "batch.min_delay_seconds"       // Lower bound of the random delay between batch messages
+	SettingBatchMaxDelaySeconds = "batch.max_delay_seconds"       // Upper bound of the random delay between batch messages
+	SettingQuietHours           = "batch.quiet_hours"             // "HH:MM-HH:MM"; empty falls back to FRIDAY_QUIET_HOURS
+	SettingTimezone             = "batch.timezone"                // IANA zone for quiet hours; empty falls back to FRIDAY_TIMEZONE
+	SettingDefaultCountryCode   = "whatsapp.default_country_code" // Prefix for national numbers like 0555...
+	SettingKeepaliveSeconds     = "whatsapp.keepalive_seconds"    // Seconds between presence keepalives; 0 disables them
+	SettingPresence             = "whatsapp.presence"             // Presence the keepalive advertises: available or unavailable
+	SettingQuarantineThreshold  = "batch.quarantine_threshold"    // Consecutive permanent failures before a contact is quarantined
+	SettingBatchMaxConcurrent   = "batch.max_concurrent"          // Batches sent at the same time, interleaved under one delay schedule
+	SettingDraftMaxLength       = "drafts.max_length"             // Worst-case rendered characters before drafts get a length warning
+	SettingReadOnly             = "server.read_only"              // "true" freezes all outgoing messages; toggled via /api/admin/read-only
+	SettingAutoResumeBatches    = "batch.auto_resume"             // "false" holds batches that were running at shutdown as interrupted
+	SettingRateLimitRPS         = "server.rate_limit_rps"         // Requests per second per client IP on limited endpoints; unset falls back to FRIDAY_RATE_LIMIT_RPS
+	SettingRateLimitBurst       = "server.rate_limit_burst"       // Requests a client may make at once before the per-second rate applies
+	SettingOutboxTTLMinutes     = "outbox.ttl_minutes"            // Minutes a send queued while disconnected waits before it expires
+	SettingDocumentMaxSizeMB    = "documents.max_size_mb"         // Largest file a batch may send as a document
+	SettingSendTimeoutSeconds   = "batch.send_timeout_seconds"    // Seconds a batch send may wait on WhatsApp before it fails as a timeout
+
+	SettingUndeliveredAfterHours = "batch.undelivered_after_hours" // Hours without a delivery receipt before a sent batch message counts as undelivered
+	SettingRecipientCap          = "batch.recipient_cap"           // Messages one contact may be sent per batch.recipient_cap_days; 0 = no cap
+	SettingRecipientCapDays      = "batch.recipient_cap_days"      // Days back the recipient cap counts sends
+
+	SettingMaintenanceHour          = "maintenance.hour"                     // Hour of the day (batch.timezone) the nightly maintenance runs
+	SettingMaintenanceRetentionDays = "maintenance.retention_days"           // Days finished batches and outbox messages are kept; 0 keeps them forever
+	SettingMaintenanceVacuumPercent = "maintenance.vacuum_threshold_percent" // Free space, as a share of the file, above which maintenance runs VACUUM
+
+	SettingNotifySelfMessage = "notify.self_message" // "true" to message the own number
+	SettingNotifySelfJID     = "notify.self_jid"     // Override recipient; empty = own number
+	SettingNotifyWebhookURL  = "notify.webhook_url"  // POST a JSON summary here when set
+
+	SettingTriggerSecret = "integrations.trigger_secret" // HMAC key for /api/integrations/trigger-batch; empty disables it
+)
+
+// Defaults used when a setting has never been saved.
+const (
+	DefaultBatchMinDelaySeconds = 10
+	DefaultBatchMaxDelaySeconds = 15
+	DefaultQuarantineThreshold  = 3
+	DefaultBatchMaxConcurrent   = 1
+	DefaultDraftMaxLength       = 4096
+	DefaultKeepaliveSeconds     = 0
+	DefaultPresence             = "unavailable"
+	DefaultAutoResumeBatches    = true
+	DefaultOutboxTTLMinutes     = 24 * 60
+	DefaultDocumentMaxSizeMB    = 16
+	DefaultSendTimeoutSeconds   = 30
+
+	DefaultUndeliveredAfterHours = 72
+	DefaultRecipientCap          = 0
+	DefaultRecipientCapDays      = 7
+
+	DefaultMaintenanceHour          = 3
+	DefaultMaintenanceRetentionDays = 0
+	DefaultMaintenanceVacuumPercent = 20
+)
+
+// SettingChange is one entry of the settings audit trail.
+type SettingChange struct {
+	ID        int64     `json:"id"`
+	Key       string    `json:"key"`
+	OldValue  *string   `json:"old_value"` // nil when the setting was first created
+	NewValue  string    `json:"new_value"`
+	ChangedBy string    `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// SettingReload is a setting Reload found changed in the database.
+type SettingReload struct {
+	Key      string
+	OldValue *string // nil when the setting was not set
+	NewValue *string // nil when the setting was removed
+}
+
+// SettingsRepository stores key/value settings that can change at runtime.
+// Values are cached in memory so the batch worker can read them on every tick;
+// the cache is filled on first use, updated by Set and replaced by Reload.
+type SettingsRepository struct {
+	db *database.DB
+
+	mu     sync.RWMutex
+	cache  map[string]string
+	loaded bool
+}
+
+// NewSettingsRepository creates a new settings repository.
+func NewSettingsRepository(db *database.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// load fills the cache from the database. Callers must not hold r.mu.
+func (r *SettingsRepository) load() error {
+	r.mu.RLock()
+	loaded := r.loaded
+	r.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	r.db.RLock()
+	values, err := r.readAll()
+	r.db.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if !r.loaded {
+		r.cache = values
+		r.loaded = true
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// readAll reads every setting from the database. Callers hold r.db's lock.
+func (r *SettingsRepository) readAll() (map[string]string, error) {
+	rows, err := r.db.Conn().Query("SELECT key, value FROM settings")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan setting: %w", err)
+		}
+		values[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating settings: %w", err)
+	}
+	return values, nil
+}
+
+// Reload replaces the cache with the settings now in the database and returns
+// the ones that differ from the cached values, sorted by key: rows changed
+// outside the API, for example with the sqlite3 shell, since they were cached.
+func (r *SettingsRepository) Reload() ([]SettingReload, error) {
+	// Holding the database lock until the cache is replaced keeps a concurrent
+	// Set from being overwritten with the value it replaced
+	r.db.RLock()
+	values, err := r.readAll()
+	if err != nil {
+		r.db.RUnlock()
+		return nil, err
+	}
+	r.mu.Lock()
+	old := r.cache
+	r.cache = values
+	r.loaded = true
+	r.mu.Unlock()
+	r.db.RUnlock()
+
+	var changes []SettingReload
+	for key, value := range values {
+		if oldValue, ok := old[key]; !ok || oldValue != value {
+			change := SettingReload{Key: key, NewValue: &value}
+			if ok {
+				change.OldValue = &oldValue
+			}
+			changes = append(changes, change)
+		}
+	}
+	for key, oldValue := range old {
+		if _, ok := values[key]; !ok {
+			changes = append(changes, SettingReload{Key: key, OldValue: &oldValue})
+		}
+	}
+	slices.SortFunc(changes, func(a, b SettingReload) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return changes, nil
+}
+
+// Get returns the raw value of a setting and whether it is set.
+func (r *SettingsRepository) Get(key string) (string, bool, error) {
+	if err := r.load(); err != nil {
+		return "", false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	value, ok := r.cache[key]
+	return value, ok, nil
+}
+
+// GetAll returns a copy of every saved setting.
+func (r *SettingsRepository) GetAll() (map[string]string, error) {
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	values := make(map[string]string, len(r.cache))
+	for k, v := range r.cache {
+		values[k] = v
+	}
+	return values, nil
+}
+
+// GetString returns a setting, or def when it is unset.
+func (r *SettingsRepository) GetString(key, def string) (string, error) {
+	value, ok, err := r.Get(key)
+	if err != nil || !ok {
+		return def, err
+	}
+	return value, nil
+}
+
+// GetBool returns a boolean setting, or def when it is unset or unparsable.
+func (r *SettingsRepository) GetBool(key string, def bool) (bool, error) {
+	value, ok, err := r.Get(key)
+	if err != nil || !ok {
+		return def, err
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return def, nil
+	}
+	return b, nil
+}
+
+// GetInt returns an integer setting, or def when it is unset or unparsable.
+func (r *SettingsRepository) GetInt(key string, def int) (int, error) {
+	value, ok, err := r.Get(key)
+	if err != nil || !ok {
+		return def, err
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def, nil
+	}
+	return n, nil
+}
+
+// Set creates or replaces a setting and records the change in the audit trail.
+// Setting a key to its current value is a no-op.
+func (r *SettingsRepository) Set(key, value, changedBy string) error {
+	if err := r.load(); err != nil {
+		return err
+	}
+
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	tx, err := r.db.Conn().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldValue sql.NullString
+	err = tx.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&oldValue)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read setting %s: %w", key, err)
+	}
+	if oldValue.Valid && oldValue.String == value {
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO settings (key, value, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(key) DO UPDATE SET
+			value = excluded.value,
+			updated_at = CURRENT_TIMESTAMP
+	`, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to set setting %s: %w", key, err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO settings_audit (key, old_value, new_value, changed_by) VALUES (?, ?, ?, ?)",
+		key, oldValue, value, changedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record setting change: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cache[key] = value
+	r.mu.Unlock()
+
+	return nil
+}
+
+// GetAudit returns the most recent setting changes, newest first.
+func (r *SettingsRepository) GetAudit(limit int) ([]SettingChange, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	rows, err := r.db.Conn().Query(`
+		SELECT id, key, old_value, new_value, changed_by, changed_at
+		FROM settings_audit
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settings audit: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []SettingChange{}
+
+	for rows.Next() {
+		var c SettingChange
+		var oldValue sql.NullString
+		if err := rows.Scan(&c.ID, &c.Key, &oldValue, &c.NewValue, &c.ChangedBy, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan setting change: %w", err)
+		}
+		if oldValue.Valid {
+			c.OldValue = &oldValue.String
+		}
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating settings audit: %w", err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"friday/internal/database"
+)
+
+// DefaultWorkspaceID is the workspace used when a request names none. It is
+// created by the migrations and holds everything from before workspaces.
+const DefaultWorkspaceID int64 = 1
+
+// Workspace separates the drafts, groups and batches of people sharing one
+// server and one WhatsApp number. Contacts, attributes and settings are shared.
+type Workspace struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+	DraftCount int       `json:"draft_count"`
+	GroupCount int       `json:"group_count"`
+}
+
+// WorkspaceRepository handles database operations for workspaces.
+type WorkspaceRepository struct {
+	db *database.DB
+}
+
+// NewWorkspaceRepository creates a new workspace repository.
+func NewWorkspaceRepository(db *database.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+// Create inserts a new workspace.
+func (r *WorkspaceRepository) Create(workspace *Workspace) error {
+	r.db.Lock()
+	defer r.db.Unlock()
+
+	result, err := r.db.Conn().Exec(`INSERT INTO workspaces (name, created_at) VALUES (?, CURRENT_TIMESTAMP)`, workspace.Name)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	workspace.ID = id
+
+	row := r.db.Conn().QueryRow("SELECT created_at FROM workspaces WHERE id = ?", id)
+	if err := row.Scan(&workspace.CreatedAt); err != nil {
+		workspace.CreatedAt = time.Now()
+	}
+
+	return nil
+}
+
+// GetByID retrieves a workspace, or nil if there is none with that ID.
+// DraftCount and GroupCount are not filled in.
+func (r *WorkspaceRepository) GetByID(id int64) (*Workspace, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var workspace Workspace
+	err := r.db.Conn().QueryRow("SELECT id, name, created_at FROM workspaces WHERE id = ?", id).Scan(
+		&workspace.ID,
+		&workspace.Name,
+		&workspace.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	return &workspace, nil
+}
+
+// GetByName retrieves a workspace by its name, ignoring ASCII case.
+// DraftCount and GroupCount are not filled in.
+func (r *WorkspaceRepository) GetByName(name string) (*Workspace, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	var workspace Workspace
+	err := r.db.Conn().QueryRow("SELECT id, name, created_at FROM workspaces WHERE name = ?", name).Scan(
+		&workspace.ID,
+		&workspace.Name,
+		&workspace.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace by name: %w", err)
+	}
+
+	return &workspace, nil
+}
+
+// GetAll retrieves all workspaces with their draft and group counts, oldest first.
+func (r *WorkspaceRepository) GetAll() ([]Workspace, error) {
+	r.db.RLock()
+	defer r.db.RUnlock()
+
+	query := `
+		SELECT w.id, w.name, w.created_at,
+			(SELECT COUNT(*) FROM message_drafts d WHERE d.workspace_id = w.id),
+			(SELECT COUNT(*) FROM contact_groups g WHERE g.workspace_id = w.id)
+		FROM workspaces w
+		ORDER BY w.id ASC
+	`
+
+	rows, err := r.db.Conn().Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := []Workspace{}
+	for rows.Next() {
+		var workspace Workspace
+		if err := rows.Scan(
+			&workspace.ID,
+			&workspace.Name,
+			&workspace.CreatedAt,
+			&workspace.DraftCount,
+			&workspace.GroupCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		workspaces = append(workspaces, workspace)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workspaces: %w", err)
+	}
+
+	return workspaces, nil
+}
@@ -0,0 +1,126 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Delimiters mark placeholders in a template. Drafts use {{ }} unless they
+// switch to another pair of DelimiterChoices, e.g. [[name]] for drafts that
+// contain literal {{ and }} such as code snippets. With any delimiters, a
+// delimiter written with a backslash before each character, \{\{ or \}\} (or
+// \[\[ and \]\]), is sent as the delimiter itself instead of opening or
+// closing a placeholder.
+//
+// The template functions are methods on the delimiters the content is written
+// with; use DefaultDelimiters for text that is not a draft, like batch labels.
+type Delimiters struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// DefaultDelimiters are the delimiters of every draft that does not choose others.
+var DefaultDelimiters = Delimiters{Open: "{{", Close: "}}"}
+
+// DelimiterChoices are the delimiters a draft may use.
+var DelimiterChoices = []Delimiters{
+	DefaultDelimiters,
+	{Open: "[[", Close: "]]"},
+	{Open: "<<", Close: ">>"},
+}
+
+// Validate reports whether the delimiters are one of DelimiterChoices.
+func (d Delimiters) Validate() error {
+	for _, choice := range DelimiterChoices {
+		if d == choice {
+			return nil
+		}
+	}
+	choices := make([]string, len(DelimiterChoices))
+	for i, choice := range DelimiterChoices {
+		choices[i] = choice.wrap("name")
+	}
+	return fmt.Errorf("unsupported placeholder delimiters %q %q; use one of %s", d.Open, d.Close, strings.Join(choices, ", "))
+}
+
+// wrap returns inner between the delimiters, as a placeholder is written.
+func (d Delimiters) wrap(inner string) string {
+	return d.Open + inner + d.Close
+}
+
+// Escaped delimiters are swapped for these private-use characters while a
+// template is spun and filled, so neither step sees them.
+const (
+	escapedOpenMark  = "\uE000"
+	escapedCloseMark = "\uE001"
+)
+
+// syntax holds what is compiled once per pair of delimiters.
+type syntax struct {
+	placeholder  *regexp.Regexp // Group 1 is the name, group 2 the pipeline including its leading |
+	escapedOpen  string         // \{\{ for {{
+	escapedClose string
+	marks        *strings.Replacer // Escaped delimiters to escapedOpenMark and escapedCloseMark
+}
+
+var syntaxes sync.Map // Delimiters -> *syntax
+
+func (d Delimiters) syntax() *syntax {
+	if s, ok := syntaxes.Load(d); ok {
+		return s.(*syntax)
+	}
+
+	// Helper arguments cannot contain | or any delimiter character
+	excluded := "|"
+	for _, r := range d.Open + d.Close {
+		if !strings.ContainsRune(excluded, r) {
+			excluded += string(r)
+		}
+	}
+	s := &syntax{
+		placeholder:  regexp.MustCompile(regexp.QuoteMeta(d.Open) + `(\w+)((?:\|[^` + regexp.QuoteMeta(excluded) + `]*)*)` + regexp.QuoteMeta(d.Close)),
+		escapedOpen:  escapeDelimiter(d.Open),
+		escapedClose: escapeDelimiter(d.Close),
+	}
+	s.marks = strings.NewReplacer(s.escapedOpen, escapedOpenMark, s.escapedClose, escapedCloseMark)
+
+	actual, _ := syntaxes.LoadOrStore(d, s)
+	return actual.(*syntax)
+}
+
+func escapeDelimiter(delimiter string) string {
+	var b strings.Builder
+	for _, r := range delimiter {
+		b.WriteByte('\\')
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// markEscapes swaps escaped delimiters for their marks; see unmarkEscapes.
+func (d Delimiters) markEscapes(content string) string {
+	return d.syntax().marks.Replace(content)
+}
+
+// unmarkEscapes turns marked escapes back into the delimiters themselves, for
+// text that is sent, or into their escaped form, for text that is still a template.
+func (d Delimiters) unmarkEscapes(content string, escaped bool) string {
+	openText, closeText := d.Open, d.Close
+	if escaped {
+		s := d.syntax()
+		openText, closeText = s.escapedOpen, s.escapedClose
+	}
+	return strings.NewReplacer(escapedOpenMark, openText, escapedCloseMark, closeText).Replace(content)
+}
+
+// blankEscapes replaces escaped delimiters with filler of the same length, for
+// linting without them while keeping offsets valid.
+func (d Delimiters) blankEscapes(content string) string {
+	s := d.syntax()
+	return strings.NewReplacer(
+		s.escapedOpen, strings.Repeat("x", len(s.escapedOpen)),
+		s.escapedClose, strings.Repeat("x", len(s.escapedClose)),
+	).Replace(content)
+}
@@ -0,0 +1,70 @@
+package template
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestEscapedDelimiters mixes backslash-escaped delimiters with real
+// placeholders for each of the DelimiterChoices. Contents are written with $o
+// and $c for the delimiters and $eo and $ec for their escaped forms.
+func TestEscapedDelimiters(t *testing.T) {
+	values := map[string]string{"name": "Ada", "x": "X"}
+
+	for _, tc := range []struct {
+		name      string
+		content   string
+		want      string
+		wantFound []string
+	}{
+		{name: "escaped pair beside a placeholder", content: `$eox$ec and $oname$c`, want: "$ox$c and Ada", wantFound: []string{"name"}},
+		{name: "placeholder inside an escaped pair", content: `$eo$oname$c$ec`, want: "$oAda$c", wantFound: []string{"name"}},
+		{name: "escaped pair inside spintax", content: `{$eox$ec $oname$c|$eox$ec $oname$c}`, want: "$ox$c Ada", wantFound: []string{"name"}},
+		{name: "escaped open only", content: `$eo $oname$c`, want: "$o Ada", wantFound: []string{"name"}},
+		{name: "escaped close only", content: `$oname$c $ec`, want: "Ada $c", wantFound: []string{"name"}},
+		{name: "escaped pipeline", content: `$eoname|upper$ec $oname|upper$c`, want: "$oname|upper$c ADA", wantFound: []string{"name"}},
+		{name: "only escapes", content: `$eox$ec$eoname$ec`, want: "$ox$c$oname$c", wantFound: []string{}},
+	} {
+		for _, d := range DelimiterChoices {
+			expand := strings.NewReplacer(
+				"$eo", d.syntax().escapedOpen, "$ec", d.syntax().escapedClose,
+				"$o", d.Open, "$c", d.Close,
+			).Replace
+			content, want := expand(tc.content), expand(tc.want)
+
+			t.Run(d.wrap(tc.name), func(t *testing.T) {
+				if got, missing := d.FillPlaceholders(content, values, 1); got != want || len(missing) != 0 {
+					t.Errorf("FillPlaceholders(%q) = %q, missing %q; want %q", content, got, missing, want)
+				}
+				if found := d.ExtractPlaceholders(content); !slices.Equal(found, tc.wantFound) {
+					t.Errorf("ExtractPlaceholders(%q) = %q, want %q", content, found, tc.wantFound)
+				}
+				if err := d.ValidateSyntax(content); err != nil {
+					t.Errorf("ValidateSyntax(%q) = %v", content, err)
+				}
+				if issues := d.ValidateTemplate(content, nil); HasErrors(issues) {
+					t.Errorf("ValidateTemplate(%q) = %+v, want no errors", content, issues)
+				}
+			})
+		}
+	}
+}
+
+// TestOtherDelimitersAreLiteral checks that a draft's placeholders are only its
+// own delimiters: the other choices are sent as written.
+func TestOtherDelimitersAreLiteral(t *testing.T) {
+	values := map[string]string{"name": "Ada"}
+	for _, d := range DelimiterChoices {
+		for _, other := range DelimiterChoices {
+			if other == d {
+				continue
+			}
+			content := other.wrap("name") + " " + d.wrap("name")
+			want := other.wrap("name") + " Ada"
+			if got, _ := d.FillPlaceholders(content, values, 1); got != want {
+				t.Errorf("%s: FillPlaceholders(%q) = %q, want %q", d.wrap(""), content, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// WhatsApp formats text between paired markers: *bold*, _italic_, ~strikethrough~
+// and ```monospace```. A marker opens a span at the start of a word and closes
+// it at the end of one, on the same line; markers inside words (snake_case, 2*3)
+// and ones standing alone (a "* " bullet) are plain text. Nothing inside
+// ```monospace``` is formatted.
+
+var formattingMarkers = []struct {
+	char byte
+	name string
+}{
+	{'*', "bold"},
+	{'_', "italic"},
+	{'~', "strikethrough"},
+}
+
+const monospaceMarker = "```"
+
+// builtInWorstCase holds the longest values WhatsApp allows for the built-in
+// placeholders: push names are capped at 25 characters, phone numbers at 15 digits.
+var builtInWorstCase = map[string]string{
+	"phone":      strings.Repeat("9", 15),
+	"name":       strings.Repeat("W", 25),
+	"push_name":  strings.Repeat("W", 25),
+	"first_name": strings.Repeat("W", 25),
+	"full_name":  strings.Repeat("W", 25),
+}
+
+// LintFormatting reports formatting markers WhatsApp will show as literal
+// characters because their span is never closed, or was never opened.
+// Placeholders and escaped delimiters are treated as words, so *{{name}}* is balanced.
+func (d Delimiters) LintFormatting(content string) []Issue {
+	issues := []Issue{}
+
+	// Mask placeholders and monospace blocks with same-length filler so offsets stay valid
+	masked := []byte(d.syntax().placeholder.ReplaceAllStringFunc(d.blankEscapes(content), func(match string) string {
+		return strings.Repeat("x", len(match))
+	}))
+	pos := 0
+	for {
+		start := strings.Index(string(masked[pos:]), monospaceMarker)
+		if start == -1 {
+			break
+		}
+		start += pos
+		end := strings.Index(string(masked[start+len(monospaceMarker):]), monospaceMarker)
+		if end == -1 {
+			issues = append(issues, Issue{
+				Code:     IssueUnbalancedFormatting,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("monospace ``` near %q is never closed; WhatsApp will show it as is", snippet(content, start)),
+				Offset:   start,
+			})
+			break
+		}
+		end += start + len(monospaceMarker)
+		for i := start; i < end+len(monospaceMarker); i++ {
+			masked[i] = 'x'
+		}
+		pos = end + len(monospaceMarker)
+	}
+
+	lineStart := 0
+	for _, line := range strings.SplitAfter(string(masked), "\n") {
+		for _, marker := range formattingMarkers {
+			issues = append(issues, lintMarker(content, line, lineStart, marker.char, marker.name)...)
+		}
+		lineStart += len(line)
+	}
+
+	return issues
+}
+
+// lintMarker pairs up one marker character within a line. offset is the line's
+// position in content, used for the issue offsets and snippets.
+func lintMarker(content, line string, offset int, char byte, name string) []Issue {
+	var issues []Issue
+	open := -1
+	for i := 0; i < len(line); i++ {
+		if line[i] != char {
+			continue
+		}
+		prev, next := byte(' '), byte(' ')
+		if i > 0 {
+			prev = line[i-1]
+		}
+		if i+1 < len(line) {
+			next = line[i+1]
+		}
+		canOpen := isWordBoundary(prev) && !isSpace(next)
+		canClose := !isSpace(prev) && isWordBoundary(next)
+
+		switch {
+		case open == -1 && canOpen:
+			open = i
+		case open != -1 && canClose:
+			open = -1
+		case open == -1 && canClose:
+			issues = append(issues, Issue{
+				Code:     IssueUnbalancedFormatting,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("%s marker %c near %q closes a span that was never opened; WhatsApp will show it as is", name, char, lineSnippet(content, offset, i)),
+				Offset:   offset + i,
+			})
+		}
+	}
+
+	if open != -1 {
+		issues = append(issues, Issue{
+			Code:     IssueUnbalancedFormatting,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("%s marker %c near %q is never closed on the same line; WhatsApp will show it as is", name, char, lineSnippet(content, offset, open)),
+			Offset:   offset + open,
+		})
+	}
+	return issues
+}
+
+// lineSnippet is a few characters of content around position i of the line at offset.
+func lineSnippet(content string, offset, i int) string {
+	start := offset + i - 10
+	if start < offset {
+		start = offset
+	}
+	for start > 0 && !utf8.RuneStart(content[start]) {
+		start--
+	}
+	return strings.SplitN(snippet(content, start), "\n", 2)[0]
+}
+
+// isWordBoundary reports whether b cannot be part of a word: space, punctuation or
+// the end of the line. Bytes of multi-byte characters count as letters.
+func isWordBoundary(b byte) bool {
+	if b >= utf8.RuneSelf {
+		return false
+	}
+	return !(b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9')
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// WorstCaseLength returns how many characters content renders to at most: each
+// spintax group resolved to its longest alternative and placeholders filled from
+// values (e.g. the longest stored value of each attribute). Built-in placeholders
+// without a value count at their maximum length; other missing ones count as
+// their {{placeholder}} text.
+func (d Delimiters) WorstCaseLength(content string, values map[string]string) int {
+	values = MergePlaceholders(builtInWorstCase, values)
+	longest, _ := d.spin(d.markEscapes(content), func(alternatives []string) string {
+		best, bestLen := "", -1
+		for _, alt := range alternatives {
+			filled, _, _ := d.fillMarked(alt, values)
+			if n := utf8.RuneCountInString(filled); n > bestLen {
+				best, bestLen = alt, n
+			}
+		}
+		return best
+	})
+	filled, _, _ := d.fillMarked(longest, values)
+	return utf8.RuneCountInString(d.unmarkEscapes(filled, false))
+}
+
+// LintLength warns when content can render to more than limit characters; see
+// WorstCaseLength. It also returns the worst-case length.
+func (d Delimiters) LintLength(content string, values map[string]string, limit int) (int, []Issue) {
+	n := d.WorstCaseLength(content, values)
+	if limit <= 0 || n <= limit {
+		return n, nil
+	}
+	return n, []Issue{{
+		Code:     IssueMessageTooLong,
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("message can be up to %d characters long with the longest placeholder values, over the %d character limit", n, limit),
+	}}
+}
@@ -0,0 +1,237 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Placeholders may pipe their value through helpers:
+//
+//	placeholder = "{{" name { "|" helper } "}}"
+//	name        = letters, digits and _
+//	helper      = helper-name [ ":" argument ]
+//	argument    = text up to the next "|" or "}}", taken verbatim (spaces included)
+//
+// Helpers run left to right on the looked-up value, e.g. {{name|trim|upper}}.
+// Only the first ":" separates the argument, so {{at|date:15:04}} formats as 15:04.
+// Arguments cannot contain "|" or a character of the delimiters, "{" or "}" by default.
+// The examples use the default delimiters; see Delimiters.
+//
+// "default" supplies a value when the placeholder has none (or an empty one):
+// {{name|default:there|title}}. Helpers before it are skipped for a missing value.
+// Without a default, a missing value leaves the whole placeholder unfilled, exactly
+// as it would without helpers. An unknown helper, or one that fails (for example
+// date on text that is not a date), leaves the value as it was before that helper.
+
+// helper transforms a placeholder value. arg is the text after ":", if any.
+type helper func(value, arg string) (string, error)
+
+var helpers = map[string]helper{
+	"upper": func(value, arg string) (string, error) {
+		c, err := caseFor(arg)
+		if err != nil {
+			return value, err
+		}
+		return strings.ToUpperSpecial(c, value), nil
+	},
+	"lower": func(value, arg string) (string, error) {
+		c, err := caseFor(arg)
+		if err != nil {
+			return value, err
+		}
+		return strings.ToLowerSpecial(c, value), nil
+	},
+	"title": func(value, arg string) (string, error) {
+		c, err := caseFor(arg)
+		if err != nil {
+			return value, err
+		}
+		return titleCase(value, c), nil
+	},
+	"trim": func(value, arg string) (string, error) {
+		return strings.TrimSpace(value), nil
+	},
+	"date": formatDate,
+	// default is applied by applyHelpers itself; listed here so it is a known name.
+	"default": func(value, arg string) (string, error) {
+		return value, nil
+	},
+}
+
+// HelperNames returns the names of the supported helpers, sorted.
+func HelperNames() []string {
+	names := make([]string, 0, len(helpers))
+	for name := range helpers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dateInputLayouts are the formats accepted for the value passed to date.
+var dateInputLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"02.01.2006",
+	"02/01/2006",
+}
+
+// formatDate parses value in one of dateInputLayouts and formats it with the Go
+// layout given as the argument, e.g. date:02 January 2006.
+func formatDate(value, layout string) (string, error) {
+	if layout == "" {
+		return value, fmt.Errorf("needs a layout, e.g. date:02 Jan 2006")
+	}
+	trimmed := strings.TrimSpace(value)
+	for _, in := range dateInputLayouts {
+		if t, err := time.Parse(in, trimmed); err == nil {
+			return t.Format(layout), nil
+		}
+	}
+	return value, fmt.Errorf("cannot parse %q as a date (use YYYY-MM-DD or DD.MM.YYYY)", value)
+}
+
+// caseFor maps the optional locale argument of upper, lower and title to casing
+// rules. "tr" gives Turkish dotted and dotless i.
+func caseFor(locale string) (unicode.SpecialCase, error) {
+	switch strings.ToLower(locale) {
+	case "":
+		return nil, nil
+	case "tr":
+		return unicode.TurkishCase, nil
+	default:
+		return nil, fmt.Errorf("unsupported locale %q (only tr)", locale)
+	}
+}
+
+// titleCase upper-cases the first letter of each space-separated word and lower-cases the rest.
+func titleCase(value string, c unicode.SpecialCase) string {
+	var b strings.Builder
+	start := true
+	for _, r := range value {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune(r)
+			start = true
+		case start:
+			if c != nil {
+				b.WriteRune(c.ToUpper(r))
+			} else {
+				b.WriteRune(unicode.ToUpper(r))
+			}
+			start = false
+		default:
+			if c != nil {
+				b.WriteRune(c.ToLower(r))
+			} else {
+				b.WriteRune(unicode.ToLower(r))
+			}
+		}
+	}
+	return b.String()
+}
+
+// pipe is one "|name:arg" step of a placeholder.
+type pipe struct {
+	Name   string
+	Arg    string
+	HasArg bool
+}
+
+// parsePipes splits the text after a placeholder's name (without the leading |).
+func parsePipes(pipeline string) []pipe {
+	segments := strings.Split(pipeline, "|")
+	pipes := make([]pipe, len(segments))
+	for i, segment := range segments {
+		name, arg, hasArg := strings.Cut(segment, ":")
+		pipes[i] = pipe{Name: name, Arg: arg, HasArg: hasArg}
+	}
+	return pipes
+}
+
+// applyHelpers runs the pipes over a looked-up value. ok reports whether the
+// placeholder had a value; the returned ok is false when it still has none after
+// any default. Problems are returned as messages; the pipeline carries on past them.
+func applyHelpers(placeholder, value string, ok bool, pipes []pipe) (string, bool, []string) {
+	var problems []string
+	for _, p := range pipes {
+		if p.Name == "default" {
+			if !ok || value == "" {
+				value, ok = p.Arg, true
+			}
+			continue
+		}
+
+		fn, known := helpers[p.Name]
+		if !known {
+			if p.Name == "" {
+				problems = append(problems, fmt.Sprintf("%s: empty helper after |", placeholder))
+			} else {
+				problems = append(problems, fmt.Sprintf("%s: unknown helper %q", placeholder, p.Name))
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		result, err := fn(value, p.Arg)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s: %v", placeholder, p.Name, err))
+			continue
+		}
+		value = result
+	}
+	return value, ok, problems
+}
+
+// lintHelpers reports unknown helpers and bad arguments in a placeholder's pipeline.
+func lintHelpers(placeholder, name, pipeline string, offset int) []Issue {
+	var issues []Issue
+	for _, p := range parsePipes(pipeline) {
+		if p.Name == "" {
+			issues = append(issues, Issue{
+				Code:        IssueUnknownHelper,
+				Severity:    SeverityWarning,
+				Message:     fmt.Sprintf("%s has an empty helper after |", placeholder),
+				Placeholder: name,
+				Offset:      offset,
+			})
+			continue
+		}
+
+		fn, known := helpers[p.Name]
+		if !known {
+			issues = append(issues, Issue{
+				Code:     IssueUnknownHelper,
+				Severity: SeverityWarning,
+				Message: fmt.Sprintf("%s uses unknown helper %q, the value is used unchanged (helpers: %s)",
+					placeholder, p.Name, strings.Join(HelperNames(), ", ")),
+				Placeholder: name,
+				Offset:      offset,
+			})
+			continue
+		}
+
+		// Run the helper on a sample value to catch argument errors up front.
+		sample := "sample"
+		if p.Name == "date" {
+			sample = "2006-01-02"
+		}
+		if _, err := fn(sample, p.Arg); err != nil {
+			issues = append(issues, Issue{
+				Code:        IssueHelperArgument,
+				Severity:    SeverityWarning,
+				Message:     fmt.Sprintf("%s: %s: %v", placeholder, p.Name, err),
+				Placeholder: name,
+				Offset:      offset,
+			})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,213 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Issue severities. Only errors block saving a draft.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue codes reported by ValidateTemplate.
+const (
+	IssueUnbalancedBraces = "unbalanced_braces"
+	IssueEmptyPlaceholder = "empty_placeholder"
+	IssueWhitespace       = "whitespace_in_placeholder"
+	IssueInvalidName      = "invalid_placeholder_name"
+	IssueUnknownBuiltIn   = "unknown_builtin"
+	IssueUnknownAttribute = "unknown_attribute"
+	IssueUnknownHelper    = "unknown_helper"
+	IssueHelperArgument   = "invalid_helper_argument"
+
+	IssueUnbalancedFormatting = "unbalanced_formatting" // See LintFormatting
+	IssueMessageTooLong       = "message_too_long"      // See LintLength
+)
+
+// BuiltInPlaceholders are the placeholder names filled from the WhatsApp contact itself.
+var BuiltInPlaceholders = []string{"phone", "name", "push_name", "first_name", "full_name"}
+
+var placeholderNameRegex = regexp.MustCompile(`^\w+$`)
+
+// Issue is a single problem found in a template.
+type Issue struct {
+	Code        string `json:"code"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	Placeholder string `json:"placeholder,omitempty"`
+	Offset      int    `json:"offset"` // Byte offset in the content
+}
+
+// ValidateTemplate lints draft content. attributeKeys are the custom attribute keys
+// set on at least one contact; pass nil to skip the "never set" check.
+// Escaped delimiters are not checked.
+func (d Delimiters) ValidateTemplate(content string, attributeKeys []string) []Issue {
+	issues := []Issue{}
+
+	known := make(map[string]bool, len(attributeKeys))
+	for _, key := range attributeKeys {
+		known[key] = true
+	}
+
+	// Snippets quote the content as written; the scan skips its escapes
+	original := content
+	content = d.blankEscapes(content)
+
+	pos := 0
+	for pos < len(content) {
+		openIdx := strings.Index(content[pos:], d.Open)
+		closeIdx := strings.Index(content[pos:], d.Close)
+
+		if openIdx == -1 && closeIdx == -1 {
+			break
+		}
+		if openIdx == -1 || (closeIdx != -1 && closeIdx < openIdx) {
+			issues = append(issues, Issue{
+				Code:     IssueUnbalancedBraces,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("closing %s without opening %s near %q", d.Close, d.Open, snippet(original, pos+closeIdx)),
+				Offset:   pos + closeIdx,
+			})
+			pos += closeIdx + len(d.Close)
+			continue
+		}
+
+		start := pos + openIdx
+		rest := content[start+len(d.Open):]
+		end := strings.Index(rest, d.Close)
+		if nested := strings.Index(rest, d.Open); end == -1 || (nested != -1 && nested < end) {
+			issues = append(issues, Issue{
+				Code:     IssueUnbalancedBraces,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("opening %s is never closed near %q", d.Open, snippet(original, start)),
+				Offset:   start,
+			})
+			pos = start + len(d.Open)
+			continue
+		}
+
+		inner := original[start+len(d.Open) : start+len(d.Open)+end]
+		issues = append(issues, d.lintPlaceholder(inner, start, attributeKeys != nil, known)...)
+		pos = start + len(d.Open) + end + len(d.Close)
+	}
+
+	return issues
+}
+
+func (d Delimiters) lintPlaceholder(inner string, offset int, checkKeys bool, known map[string]bool) []Issue {
+	rawName, pipeline, hasPipeline := strings.Cut(inner, "|")
+	name := strings.TrimSpace(rawName)
+
+	if name == "" {
+		return []Issue{{
+			Code:     IssueEmptyPlaceholder,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("empty placeholder %s", d.wrap(inner)),
+			Offset:   offset,
+		}}
+	}
+
+	var issues []Issue
+	if name != rawName {
+		suggestion := name
+		if hasPipeline {
+			suggestion += "|" + pipeline
+		}
+		issues = append(issues, Issue{
+			Code:        IssueWhitespace,
+			Severity:    SeverityWarning,
+			Message:     fmt.Sprintf("%s contains spaces and will not be filled; use %s", d.wrap(inner), d.wrap(suggestion)),
+			Placeholder: name,
+			Offset:      offset,
+		})
+	}
+
+	if !placeholderNameRegex.MatchString(name) {
+		return append(issues, Issue{
+			Code:        IssueInvalidName,
+			Severity:    SeverityWarning,
+			Message:     fmt.Sprintf("%s is not a valid placeholder name (letters, digits and _ only)", d.wrap(name)),
+			Placeholder: name,
+			Offset:      offset,
+		})
+	}
+
+	if hasPipeline {
+		issues = append(issues, lintHelpers(d.wrap(inner), name, pipeline, offset)...)
+	}
+
+	if isBuiltIn(name) || !checkKeys || known[name] {
+		return issues
+	}
+
+	if suggestion := closestBuiltIn(name); suggestion != "" {
+		return append(issues, Issue{
+			Code:        IssueUnknownBuiltIn,
+			Severity:    SeverityWarning,
+			Message:     fmt.Sprintf("%s is not a built-in placeholder; did you mean %s?", d.wrap(name), d.wrap(suggestion)),
+			Placeholder: name,
+			Offset:      offset,
+		})
+	}
+
+	return append(issues, Issue{
+		Code:        IssueUnknownAttribute,
+		Severity:    SeverityWarning,
+		Message:     fmt.Sprintf("%s is not set on any contact", d.wrap(name)),
+		Placeholder: name,
+		Offset:      offset,
+	})
+}
+
+// HasErrors reports whether any issue blocks saving.
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func isBuiltIn(name string) bool {
+	for _, b := range BuiltInPlaceholders {
+		if b == name {
+			return true
+		}
+	}
+	return false
+}
+
+// closestBuiltIn returns a built-in name within edit distance 2 of name, if any.
+func closestBuiltIn(name string) string {
+	best, bestDist := "", 3
+	for _, b := range BuiltInPlaceholders {
+		if d := editDistance(strings.ToLower(name), b); d < bestDist {
+			best, bestDist = b, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -1,17 +1,18 @@
 package template
 
 import (
-	"regexp"
+	"fmt"
 	"sort"
+	"strings"
+	"unicode/utf8"
 
 	"friday/internal/whatsapp"
 )
 
-var placeholderRegex = regexp.MustCompile(`\{\{(\w+)\}\}`)
-
 // ExtractPlaceholders returns all unique placeholder names from the content, sorted.
-func ExtractPlaceholders(content string) []string {
-	matches := placeholderRegex.FindAllStringSubmatch(content, -1)
+// Placeholders match {{name}} and {{name|helper:arg|...}}; see helpers.go for the grammar.
+func (d Delimiters) ExtractPlaceholders(content string) []string {
+	matches := d.syntax().placeholder.FindAllStringSubmatch(d.markEscapes(content), -1)
 
 	seen := make(map[string]bool)
 	for _, match := range matches {
@@ -30,15 +31,65 @@ func ExtractPlaceholders(content string) []string {
 	return result
 }
 
-// FillPlaceholders replaces {{name}} placeholders with values from the map.
+// ValidateSyntax checks that every {{ ... }} in the content is a well-formed placeholder.
+// Returns an error describing the first malformed occurrence.
+func (d Delimiters) ValidateSyntax(content string) error {
+	stripped := d.syntax().placeholder.ReplaceAllString(d.blankEscapes(content), "")
+
+	if idx := strings.Index(stripped, d.Open); idx != -1 {
+		return fmt.Errorf("malformed placeholder near %q", snippet(stripped, idx))
+	}
+	if idx := strings.Index(stripped, d.Close); idx != -1 {
+		return fmt.Errorf("unmatched closing %s near %q", d.Close, snippet(stripped, idx))
+	}
+
+	return nil
+}
+
+func snippet(s string, idx int) string {
+	end := idx + 20
+	if end > len(s) {
+		end = len(s)
+	}
+	return s[idx:end]
+}
+
+// FillPlaceholders resolves spintax groups using seed (see SpinSeed), then replaces
+// {{name}} placeholders with values from the map, applying any helpers.
 // Returns the filled content and a list of placeholders that had no values.
-func FillPlaceholders(content string, values map[string]string) (string, []string) {
+// Escaped delimiters are unescaped.
+func (d Delimiters) FillPlaceholders(content string, values map[string]string, seed int64) (string, []string) {
+	filled, missing, _ := d.fill(content, values, seed)
+	return filled, missing
+}
+
+// fill is FillPlaceholders that also returns the helper problems encountered.
+func (d Delimiters) fill(content string, values map[string]string, seed int64) (string, []string, []string) {
+	filled, missing, problems := d.fillMarked(d.spinMarked(d.markEscapes(content), seed), values)
+	for i := range problems {
+		problems[i] = d.unmarkEscapes(problems[i], true)
+	}
+	return d.unmarkEscapes(filled, false), missing, problems
+}
+
+// fillMarked fills the placeholders of spun content whose escapes are marked.
+func (d Delimiters) fillMarked(content string, values map[string]string) (string, []string, []string) {
+	placeholder := d.syntax().placeholder
 	missingMap := make(map[string]bool)
+	var problems []string
+
+	filled := placeholder.ReplaceAllStringFunc(content, func(match string) string {
+		parts := placeholder.FindStringSubmatch(match)
+		name, pipeline := parts[1], parts[2]
 
-	filled := placeholderRegex.ReplaceAllStringFunc(content, func(match string) string {
-		name := match[2 : len(match)-2]
+		value, ok := values[name]
+		if pipeline != "" {
+			var helperProblems []string
+			value, ok, helperProblems = applyHelpers(match, value, ok, parsePipes(pipeline[1:]))
+			problems = append(problems, helperProblems...)
+		}
 
-		if value, ok := values[name]; ok {
+		if ok {
 			return value
 		}
 
@@ -52,7 +103,7 @@ func FillPlaceholders(content string, values map[string]string) (string, []strin
 	}
 	sort.Strings(missing)
 
-	return filled, missing
+	return filled, missing, problems
 }
 
 type PreviewResult struct {
@@ -61,12 +112,17 @@ type PreviewResult struct {
 	PlaceholdersFound   []string `json:"placeholders_found"`
 	PlaceholdersFilled  []string `json:"placeholders_filled"`
 	PlaceholdersMissing []string `json:"placeholders_missing"`
+	SpintaxChoices      []string `json:"spintax_choices,omitempty"` // Alternative picked for each {a|b} group
+	HelperErrors        []string `json:"helper_errors,omitempty"`   // Unknown or failing {{name|helper}} steps
+	CharCount           int      `json:"char_count"`                // Characters in Preview
 }
 
-// Preview generates a preview of the content with placeholders filled from values.
-func Preview(content string, values map[string]string) PreviewResult {
-	found := ExtractPlaceholders(content)
-	filled, missing := FillPlaceholders(content, values)
+// Preview generates a preview of the content with spintax resolved from seed and
+// placeholders filled from values.
+func (d Delimiters) Preview(content string, values map[string]string, seed int64) PreviewResult {
+	found := d.ExtractPlaceholders(content)
+	_, choices := d.Spin(content, seed)
+	filled, missing, problems := d.fill(content, values, seed)
 
 	filledList := make([]string, 0, len(found)-len(missing))
 	missingSet := make(map[string]bool)
@@ -85,6 +141,9 @@ func Preview(content string, values map[string]string) PreviewResult {
 		PlaceholdersFound:   found,
 		PlaceholdersFilled:  filledList,
 		PlaceholdersMissing: missing,
+		SpintaxChoices:      choices,
+		HelperErrors:        problems,
+		CharCount:           utf8.RuneCountInString(filled),
 	}
 }
 
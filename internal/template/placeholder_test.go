@@ -0,0 +1,94 @@
+package template
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFillPlaceholders(t *testing.T) {
+	values := map[string]string{
+		"first_name": "Ada",
+		"last_name":  "Lovelace",
+		"city":       "istanbul",
+		"at":         "2025-03-01 09:30",
+		"blank":      "",
+	}
+
+	for _, tc := range []struct {
+		name        string
+		content     string
+		want        string
+		wantMissing []string
+	}{
+		// Nested and adjacent delimiters
+		{name: "adjacent placeholders", content: "{{first_name}}{{last_name}}", want: "AdaLovelace"},
+		{name: "adjacent with a separator", content: "{{first_name}} {{last_name}}!", want: "Ada Lovelace!"},
+		{name: "extra brace before", content: "{{{first_name}}", want: "{Ada"},
+		{name: "extra brace after", content: "{{first_name}}}", want: "Ada}"},
+		{name: "triple braces", content: "{{{first_name}}}", want: "{Ada}"},
+		{name: "placeholder inside a pair", content: "{{outer{{first_name}}}}", want: "{{outerAda}}"},
+		{name: "unclosed before a placeholder", content: "{{first_name {{last_name}}", want: "{{first_name Lovelace"},
+
+		// Escapes inside spintax
+		{name: "escaped delimiters in every alternative", content: `{Use \{\{x\}\}|Use \{\{x\}\}}`, want: "Use {{x}}"},
+		{name: "escape next to a placeholder in spintax", content: `{\{\{{{first_name}}\}\}|\{\{{{first_name}}\}\}}`, want: "{{Ada}}"},
+		{name: "escape does not start a group", content: `\{\{a|b\}\}`, want: "{{a|b}}"},
+		{name: "escaped pipe text outside spintax", content: `{{first_name}} \{\{a|b\}\} {Hi|Hi}`, want: "Ada {{a|b}} Hi"},
+
+		// Pipes with arguments
+		{name: "default for a missing value", content: "Hi {{nickname|default:there}}", want: "Hi there"},
+		{name: "default for an empty value", content: "Hi {{blank|default:there}}", want: "Hi there"},
+		{name: "default keeps a value", content: "Hi {{first_name|default:there}}", want: "Hi Ada"},
+		{name: "default argument with spaces", content: "{{nickname|default:dear friend}}", want: "dear friend"},
+		{name: "helpers after default", content: "{{nickname|default:there|upper}}", want: "THERE"},
+		{name: "locale argument", content: "{{city|upper:tr}}", want: "İSTANBUL"},
+		{name: "date layout with colons", content: "{{at|date:15:04}}", want: "09:30"},
+		{name: "date layout with spaces", content: "{{at|date:02 Jan 2006}}", want: "01 Mar 2025"},
+		{name: "helper inside spintax", content: "{Hi {{first_name|upper}}|Hi {{first_name|upper}}}", want: "Hi ADA"},
+		{name: "default inside spintax", content: "{Hi {{nickname|default:you}}|Hi {{nickname|default:you}}}", want: "Hi you"},
+		{name: "chained helpers", content: "{{last_name|lower|title}}", want: "Lovelace"},
+		{name: "missing without default", content: "Hi {{nickname|upper}}", want: "Hi {{nickname|upper}}", wantMissing: []string{"nickname"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, missing := DefaultDelimiters.FillPlaceholders(tc.content, values, 1)
+			if got != tc.want {
+				t.Errorf("FillPlaceholders(%q) = %q, want %q", tc.content, got, tc.want)
+			}
+			if !slices.Equal(missing, tc.wantMissing) {
+				t.Errorf("FillPlaceholders(%q) missing = %q, want %q", tc.content, missing, tc.wantMissing)
+			}
+		})
+	}
+}
+
+func TestFillPlaceholdersHelperProblems(t *testing.T) {
+	values := map[string]string{"name": "ada", "at": "soon"}
+
+	for _, tc := range []struct {
+		content      string
+		want         string
+		wantProblems int
+	}{
+		{content: "{{name|shout|upper}}", want: "ADA", wantProblems: 1},
+		{content: "{{name||upper}}", want: "ADA", wantProblems: 1},
+		{content: "{{at|date:2006|upper}}", want: "SOON", wantProblems: 1},
+		{content: "{{name|upper:xx}}", want: "ada", wantProblems: 1},
+		{content: "{{name|title:tr}}", want: "Ada", wantProblems: 0},
+	} {
+		got, _, problems := DefaultDelimiters.fill(tc.content, values, 0)
+		if got != tc.want || len(problems) != tc.wantProblems {
+			t.Errorf("fill(%q) = %q with problems %q, want %q with %d", tc.content, got, problems, tc.want, tc.wantProblems)
+		}
+	}
+}
+
+// BenchmarkFillPlaceholders renders a message the way the worker does for every
+// recipient: spintax, placeholders with helpers and an escaped delimiter.
+func BenchmarkFillPlaceholders(b *testing.B) {
+	content := `{Hi|Hello|Hey} {{first_name|title}}, your order {{order}} ships on {{at|date:02 Jan 2006}}. ` +
+		`Reply \{\{stop\}\} to opt out{, thanks|!}`
+	values := map[string]string{"first_name": "ada", "order": "#1042", "at": "2025-03-01"}
+	for i := 0; i < b.N; i++ {
+		DefaultDelimiters.FillPlaceholders(content, values, SpinSeed(int64(i), "905550000001@s.whatsapp.net"))
+	}
+}
@@ -0,0 +1,160 @@
+package template
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+)
+
+// Spintax lets one draft read differently per recipient: {Hi|Hello|Hey} is replaced
+// by one of its alternatives. Only single-brace groups containing a | are spintax;
+// {{name}} placeholders, whatever the draft's Delimiters, any other {{ }} pair and
+// braces without a | are left untouched. Placeholders may appear inside
+// alternatives, e.g. {Hi {{first_name}}|Hello}, and the | of their helpers does
+// not separate alternatives. Groups do not nest.
+
+// SpinSeed derives the seed used to pick alternatives for one recipient of a batch.
+// The same (batchSeed, jid) pair always renders the same text, so retries and
+// previews match what is actually sent.
+func SpinSeed(batchSeed int64, jid string) int64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d:%s", batchSeed, jid)
+	return int64(h.Sum64())
+}
+
+// HasSpintax reports whether the content contains at least one spintax group.
+func (d Delimiters) HasSpintax(content string) bool {
+	_, choices := d.Spin(content, 0)
+	return len(choices) > 0
+}
+
+// Spin replaces every spintax group with an alternative chosen deterministically
+// from seed. It returns the resolved content and the chosen alternatives in order.
+// Placeholders and escaped delimiters are left as they are.
+func (d Delimiters) Spin(content string, seed int64) (string, []string) {
+	spun, choices := d.spinMarkedChoices(d.markEscapes(content), seed)
+	for i := range choices {
+		choices[i] = d.unmarkEscapes(choices[i], true)
+	}
+	return d.unmarkEscapes(spun, true), choices
+}
+
+// spinMarked is Spin for content whose escapes are marked, without the choices.
+func (d Delimiters) spinMarked(content string, seed int64) string {
+	spun, _ := d.spinMarkedChoices(content, seed)
+	return spun
+}
+
+func (d Delimiters) spinMarkedChoices(content string, seed int64) (string, []string) {
+	if !strings.Contains(content, "|") {
+		return content, nil
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	return d.spin(content, func(alternatives []string) string {
+		return alternatives[rng.Intn(len(alternatives))]
+	})
+}
+
+// skipPair returns the length of the delimited text at the start of s, a
+// placeholder or a literal {{ }} pair, or 0 if s does not start one. An
+// unclosed pair runs to the end of s and is reported as such.
+func (d Delimiters) skipPair(s string) (n int, closed bool) {
+	for _, pair := range []Delimiters{d, DefaultDelimiters} {
+		if !strings.HasPrefix(s, pair.Open) {
+			continue
+		}
+		end := strings.Index(s[len(pair.Open):], pair.Close)
+		if end == -1 {
+			return len(s), false
+		}
+		return len(pair.Open) + end + len(pair.Close), true
+	}
+	return 0, false
+}
+
+// spin replaces every spintax group with the alternative pick returns for it.
+func (d Delimiters) spin(content string, pick func(alternatives []string) string) (string, []string) {
+	var b strings.Builder
+	var choices []string
+
+	pos := 0
+	for pos < len(content) {
+		// Copy {{placeholder}} through unchanged
+		if n, _ := d.skipPair(content[pos:]); n > 0 {
+			b.WriteString(content[pos : pos+n])
+			pos += n
+			continue
+		}
+
+		if content[pos] != '{' {
+			b.WriteByte(content[pos])
+			pos++
+			continue
+		}
+
+		end := d.spinGroupEnd(content, pos)
+		if end == -1 {
+			b.WriteByte('{')
+			pos++
+			continue
+		}
+
+		alternatives := d.alternatives(content[pos+1 : end])
+		if len(alternatives) < 2 {
+			b.WriteString(content[pos : end+1])
+			pos = end + 1
+			continue
+		}
+
+		choice := pick(alternatives)
+		choices = append(choices, choice)
+		b.WriteString(choice)
+		pos = end + 1
+	}
+
+	return b.String(), choices
+}
+
+// alternatives splits the inside of a spintax group at each | outside its
+// placeholders, so {Hi {{name|upper}}|Hello} has two alternatives, not three.
+func (d Delimiters) alternatives(inner string) []string {
+	var alternatives []string
+	start, i := 0, 0
+	for i < len(inner) {
+		if n, _ := d.skipPair(inner[i:]); n > 0 {
+			i += n
+			continue
+		}
+		if inner[i] == '|' {
+			alternatives = append(alternatives, inner[start:i])
+			start = i + 1
+		}
+		i++
+	}
+	return append(alternatives, inner[start:])
+}
+
+// spinGroupEnd returns the index of the } closing the single-brace group opened at
+// start, skipping {{placeholders}} inside it, or -1 if the group is not closed or
+// contains another single brace.
+func (d Delimiters) spinGroupEnd(content string, start int) int {
+	i := start + 1
+	for i < len(content) {
+		n, closed := d.skipPair(content[i:])
+		switch {
+		case n > 0 && !closed:
+			return -1
+		case n > 0:
+			i += n
+		case content[i] == '{':
+			return -1
+		case content[i] == '}':
+			return i
+		default:
+			i++
+		}
+	}
+	return -1
+}
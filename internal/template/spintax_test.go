@@ -0,0 +1,33 @@
+package template
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSpin(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		content     string
+		want        string
+		wantChoices []string
+	}{
+		{name: "adjacent groups", content: "{Hi|Hi}{!|!}", want: "Hi!", wantChoices: []string{"Hi", "!"}},
+		{name: "group next to a placeholder", content: "{Hi|Hi}{{name}}{!|!}", want: "Hi{{name}}!", wantChoices: []string{"Hi", "!"}},
+		{name: "placeholder inside a group", content: "{Hi {{name}}|Hi {{name}}}", want: "Hi {{name}}", wantChoices: []string{"Hi {{name}}"}},
+		{name: "pipeline inside a group", content: "{Hi {{name|default:a}}|Hi {{name|default:a}}}", want: "Hi {{name|default:a}}", wantChoices: []string{"Hi {{name|default:a}}"}},
+		{name: "pipeline in a group without alternatives", content: "{Hi {{name|upper}}}", want: "{Hi {{name|upper}}}"},
+		{name: "nested group spins the inner one", content: "{a|{b|b}}", want: "{a|b}", wantChoices: []string{"b"}},
+		{name: "braces without a pipe", content: "{a}{b|b}", want: "{a}b", wantChoices: []string{"b"}},
+		{name: "unclosed group", content: "{a|b", want: "{a|b"},
+		{name: "escaped delimiters in a group", content: `{\{\{x\}\}|\{\{x\}\}}`, want: `\{\{x\}\}`, wantChoices: []string{`\{\{x\}\}`}},
+		{name: "escaped pipe text is not a group", content: `\{\{a|b\}\}`, want: `\{\{a|b\}\}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, choices := DefaultDelimiters.Spin(tc.content, 1)
+			if got != tc.want || !slices.Equal(choices, tc.wantChoices) {
+				t.Errorf("Spin(%q) = %q, %q; want %q, %q", tc.content, got, choices, tc.want, tc.wantChoices)
+			}
+		})
+	}
+}
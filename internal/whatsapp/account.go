@@ -0,0 +1,54 @@
+package whatsapp
+
+import (
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waAdv"
+	"google.golang.org/protobuf/proto"
+)
+
+// Account describes the linked WhatsApp account, as stored by whatsmeow.
+type Account struct {
+	JID          string // Without device part, e.g. "905551234567@s.whatsapp.net"
+	DeviceJID    string // This linked device, e.g. "905551234567:12@s.whatsapp.net"
+	LID          string // Hidden user ID; empty if the server has not sent it
+	Phone        string
+	PushName     string    // Name shown to people without the number saved
+	BusinessName string    // Only for business accounts
+	Platform     string    // Platform of the primary phone as reported at pairing, e.g. "android"
+	PairedAt     time.Time // When this device was linked; zero if unknown
+}
+
+// Account returns the linked account, or nil when there is no session.
+func (c *Client) Account() *Account {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	client := c.whatsappClient
+	if client == nil || client.Store == nil || client.Store.ID == nil {
+		return nil
+	}
+	store := client.Store
+
+	account := &Account{
+		JID:          store.ID.ToNonAD().String(),
+		DeviceJID:    store.ID.String(),
+		Phone:        store.ID.User,
+		PushName:     store.PushName,
+		BusinessName: store.BusinessName,
+		Platform:     store.Platform,
+	}
+	if !store.LID.IsEmpty() {
+		account.LID = store.LID.ToNonAD().String()
+	}
+
+	// The phone signs the device identity when linking it, timestamp included
+	if store.Account != nil {
+		var identity waAdv.ADVDeviceIdentity
+		if err := proto.Unmarshal(store.Account.GetDetails(), &identity); err == nil && identity.GetTimestamp() > 0 {
+			account.PairedAt = time.Unix(int64(identity.GetTimestamp()), 0).UTC()
+		}
+	}
+
+	return account
+}
@@ -0,0 +1,153 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// blocklistTTL is how long a fetched blocklist is used before it is fetched
+// again. Block and unblock events from WhatsApp keep it current in between.
+const blocklistTTL = 5 * time.Minute
+
+// blocklist caches the JIDs the linked account has blocked, as WhatsApp lists
+// them: phone-number JIDs or hidden user IDs. Protected by Client.mu.
+type blocklist struct {
+	jids      []types.JID // Nil until fetched
+	fetchedAt time.Time
+}
+
+// BlockedJIDs returns the JIDs the linked account has blocked, as a set. A
+// blocked hidden user ID is included together with its phone-number JID when
+// that is known, so members stored either way are found.
+func (c *Client) BlockedJIDs(ctx context.Context) (map[string]bool, error) {
+	jids, err := c.blockedJIDs(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[string]bool, len(jids))
+	for _, jid := range jids {
+		blocked[jid.String()] = true
+		if pn := c.phoneJID(jid); pn != "" {
+			blocked[pn] = true
+		}
+	}
+	return blocked, nil
+}
+
+// GetBlockedContacts returns the contacts the linked account has blocked, by
+// phone-number JID where one is known, with their names from the address book.
+// refresh fetches the list from WhatsApp even when the cached one is recent.
+func (c *Client) GetBlockedContacts(ctx context.Context, refresh bool) ([]Contact, error) {
+	jids, err := c.blockedJIDs(ctx, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+
+	contacts := make([]Contact, 0, len(jids))
+	seen := make(map[string]bool, len(jids))
+	for _, jid := range jids {
+		contact := Contact{JID: jid}
+		if pn := c.phoneJID(jid); pn != "" {
+			contact.JID, _ = types.ParseJID(pn)
+			contact.LID = jid.String()
+		}
+		if seen[contact.JID.String()] {
+			continue
+		}
+		seen[contact.JID.String()] = true
+
+		info, err := client.Store.Contacts.GetContact(ctx, contact.JID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get contact: %w", err)
+		}
+		fillContact(&contact, info)
+		contact.Blocked = true
+		contacts = append(contacts, contact)
+	}
+
+	slices.SortFunc(contacts, func(a, b Contact) int {
+		return strings.Compare(a.JID.String(), b.JID.String())
+	})
+	return contacts, nil
+}
+
+// blockedJIDs returns the cached blocklist, fetching it when it is missing,
+// older than blocklistTTL or refresh is set.
+func (c *Client) blockedJIDs(ctx context.Context, refresh bool) ([]types.JID, error) {
+	c.mu.RLock()
+	client := c.whatsappClient
+	cached := c.blocklist
+	c.mu.RUnlock()
+
+	if client == nil || !client.IsConnected() || !client.IsLoggedIn() {
+		return nil, ErrNotConnected
+	}
+	if !refresh && cached.jids != nil && time.Since(cached.fetchedAt) < blocklistTTL {
+		return cached.jids, nil
+	}
+
+	fetched, err := client.GetBlocklist(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocklist: %w", err)
+	}
+	jids := make([]types.JID, 0, len(fetched.JIDs))
+	for _, jid := range fetched.JIDs {
+		jids = append(jids, jid.ToNonAD())
+	}
+
+	c.mu.Lock()
+	c.blocklist = blocklist{jids: jids, fetchedAt: time.Now()}
+	c.mu.Unlock()
+	slog.Debug("WhatsApp blocklist fetched", "count", len(jids))
+	return jids, nil
+}
+
+// phoneJID returns the phone-number JID of a hidden user ID, or "" when it is
+// not one or its number is unknown.
+func (c *Client) phoneJID(jid types.JID) string {
+	if jid.Server != types.HiddenUserServer {
+		return ""
+	}
+	pn, err := c.ResolveLID(jid.String())
+	if err != nil {
+		slog.Warn("Failed to resolve blocked hidden number", "jid", jid, "error", err)
+		return ""
+	}
+	return pn
+}
+
+// applyBlocklistEventLocked updates the cached blocklist with a block or unblock made
+// on another device. Callers hold c.mu.
+func (c *Client) applyBlocklistEventLocked(evt *events.Blocklist) {
+	if c.blocklist.jids == nil {
+		return
+	}
+	// A modify event, or a change we cannot apply, means the whole list is fetched again
+	if evt.Action == events.BlocklistActionModify || len(evt.Changes) == 0 {
+		c.blocklist = blocklist{}
+		return
+	}
+	// Callers may still hold the cached slice, so changes go to a copy
+	jids := slices.Clone(c.blocklist.jids)
+	for _, change := range evt.Changes {
+		jid := change.JID.ToNonAD()
+		jids = slices.DeleteFunc(jids, func(blocked types.JID) bool {
+			return blocked == jid
+		})
+		if change.Action == events.BlocklistChangeActionBlock {
+			jids = append(jids, jid)
+		}
+	}
+	c.blocklist.jids = jids
+}
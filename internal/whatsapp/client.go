@@ -2,14 +2,18 @@ package whatsapp
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"friday/internal/lockfile"
+
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/store/sqlstore"
 	"go.mau.fi/whatsmeow/types"
@@ -22,43 +26,140 @@ import (
 
 type Contact struct {
 	JID       types.JID `json:"jid"`
-	Phone     string    `json:"phone"`
+	JIDType   string    `json:"jid_type"`      // JIDTypePhone, or JIDTypeLID when only the hidden ID is known
+	LID       string    `json:"lid,omitempty"` // Hidden ID the contact was stored under, when resolved to JID
+	Phone     string    `json:"phone"`         // Empty for unresolved LIDs
 	Name      string    `json:"name"`
 	PushName  string    `json:"push_name"`
 	FirstName string    `json:"first_name"`
 	FullName  string    `json:"full_name"`
+	Blocked   bool      `json:"blocked,omitempty"` // Blocked by the linked account; only set where blocked contacts are listed
+}
+
+// ErrNoProfilePicture is returned when a contact has no profile picture or hides it from us.
+var ErrNoProfilePicture = errors.New("no profile picture available")
+
+// ErrInvalidJID is returned by SendMessage and NormalizeJID when a JID is malformed.
+var ErrInvalidJID = errors.New("invalid JID format")
+
+// ErrReadOnly is returned by SendMessage while the server is in read-only mode.
+var ErrReadOnly = errors.New("server is in read-only mode")
+
+// ErrNotConnected is returned by SendMessage when there is no logged-in connection.
+var ErrNotConnected = errors.New("whatsapp client not connected")
+
+// IsPermanentSendError reports whether a SendMessage error will recur on every
+// retry because the recipient itself is invalid, as opposed to connection or
+// timeout problems.
+func IsPermanentSendError(err error) bool {
+	return errors.Is(err, ErrInvalidJID) ||
+		errors.Is(err, whatsmeow.ErrUnknownServer) ||
+		errors.Is(err, whatsmeow.ErrRecipientADJID) ||
+		errors.Is(err, whatsmeow.ErrBroadcastListUnsupported)
+}
+
+// ContactUpdate reports a contact whose display name may have changed.
+type ContactUpdate struct {
+	JID     string // Phone-number JID, as stored in groups and batches
+	OldName string // Previous push or business name, empty for address book changes
+	Name    string // Display name as GetContacts now reports it
+}
+
+// Receipt reports that the recipient's phone got, read or played messages we sent.
+type Receipt struct {
+	MessageIDs []string  // As returned by SendMessage and SendDocument
+	Timestamp  time.Time // When the phone acknowledged them
+}
+
+// ProfilePicture identifies a contact's current profile picture.
+type ProfilePicture struct {
+	ID  string // Changes whenever the picture changes
+	URL string // Short-lived download URL
+}
+
+// ConnectionState describes where the client is in the connect/pair lifecycle.
+type ConnectionState string
+
+const (
+	StateDisconnected ConnectionState = "disconnected"
+	StateConnecting   ConnectionState = "connecting" // Websocket up, restoring a linked session
+	StatePairing      ConnectionState = "pairing"    // Websocket up, no session: QR codes are being shown
+	StateConnected    ConnectionState = "connected"
+)
+
+// QR code lifetimes used by WhatsApp: the first code of a pairing attempt lasts
+// longer than the codes that replace it.
+const (
+	firstQRTimeout = 60 * time.Second
+	nextQRTimeout  = 20 * time.Second
+)
+
+// QRCode is one code of a pairing attempt. Index 0 starts a new attempt.
+type QRCode struct {
+	Code      string
+	Index     int
+	ExpiresAt time.Time
 }
 
 type Client struct {
 	whatsappClient *whatsmeow.Client
 	container      *sqlstore.Container
-	eventHandler   func(*events.Message)
-	qrHandler      func(string)
-	qrClearHandler func()
 	dbPath         string
+	lock           *lockfile.Lock // held for the client's lifetime, across session clears
 
 	mu              sync.RWMutex  // protects state fields below
 	clearMu         sync.Mutex    // serializes clear+delete+reinitialize sequences
+	connectMu       sync.Mutex    // serializes Connect so concurrent callers share one attempt
 	clearInProgress atomic.Bool   // prevents duplicate clearAndReinitialize goroutines
 	eventHandlerID  uint32        // whatsmeow handler registration ID; 0 = not registered
 
 	// State fields (protected by mu)
 	qrReceived    bool
 	connectedOnce bool
+	stopQR        chan struct{} // closes to stop rotating the current attempt's QR codes
+	stopKeepalive chan struct{} // closes to stop the keepalive loop of the current connection
+	keepalive     KeepaliveStatus
+	events        eventLog // Recent pairing, connection and send events, see Events
+	blocklist     blocklist // Cached blocked JIDs, see BlockedJIDs
+
+	// Callbacks (protected by mu, invoked outside the lock)
+	messageHandlers []func(*events.Message)
+	qrHandler       func(QRCode)
+	qrClearHandler  func()
+	statusHandler   func(connected bool)
+	contactHandler  func(ContactUpdate)
+	receiptHandler  func(Receipt)
+
+	// Returns the default country calling code (e.g. "90"), read on every use
+	countryCode func() string
+
+	// Reports whether outgoing messages are frozen, read on every send
+	readOnly func() bool
+
+	// Returns the keepalive interval (0 = off) and presence to advertise, read on every tick
+	keepaliveConfig func() (time.Duration, string)
 }
 
 func NewClient() (*Client, error) {
 	dbLog := waLog.Noop
 	dbPath := "whatsapp_session.db"
 
+	lock, err := lockfile.Acquire(dbPath + ".lock")
+	if err != nil {
+		return nil, err
+	}
+
 	ctx := context.Background()
 	container, err := sqlstore.New(ctx, "sqlite3", "file:"+dbPath+"?_foreign_keys=on", dbLog)
 	if err != nil {
+		lock.Release()
 		return nil, fmt.Errorf("failed to create database container: %w", err)
 	}
 
 	deviceStore, err := container.GetFirstDevice(ctx)
 	if err != nil {
+		container.Close()
+		lock.Release()
 		return nil, fmt.Errorf("failed to get device store: %w", err)
 	}
 
@@ -68,20 +169,36 @@ func NewClient() (*Client, error) {
 		whatsappClient: whatsappClient,
 		container:      container,
 		dbPath:         dbPath,
+		lock:           lock,
 	}, nil
 }
 
+// Connect opens the websocket. It is a no-op while a connection or pairing attempt
+// is already in progress, so a second caller does not reset the first one's QR codes.
 func (c *Client) Connect() error {
+	c.connectMu.Lock()
+	defer c.connectMu.Unlock()
+
 	c.mu.Lock()
+	if c.whatsappClient == nil {
+		c.mu.Unlock()
+		return fmt.Errorf("whatsapp client not initialized")
+	}
+	if c.whatsappClient.IsConnected() {
+		c.mu.Unlock()
+		return nil
+	}
 	c.qrReceived = false
 	if c.eventHandlerID != 0 {
 		c.whatsappClient.RemoveEventHandler(c.eventHandlerID)
 	}
 	c.eventHandlerID = c.whatsappClient.AddEventHandler(c.handleEvent)
 	client := c.whatsappClient
+	c.recordEventLocked(EventConnectAttempt, "has session: %t", client.Store.ID != nil)
 	c.mu.Unlock()
 
 	if err := client.Connect(); err != nil {
+		c.recordEvent(EventConnectFailure, "%v", err)
 		return err
 	}
 
@@ -99,27 +216,27 @@ func (c *Client) logConnectionStatus() {
 	c.mu.RUnlock()
 
 	if client == nil {
-		log.Printf("WhatsApp: client not initialized")
+		slog.Warn("WhatsApp: client not initialized")
 		return
 	}
 	if client.IsLoggedIn() {
-		log.Printf("WhatsApp: logged in successfully")
+		slog.Info("WhatsApp: logged in successfully")
 		return
 	}
 	if connectedOnce {
-		log.Printf("WhatsApp: was connected, session may be restoring...")
+		slog.Info("WhatsApp: was connected, session may be restoring...")
 		return
 	}
 	if qrReceived {
-		log.Printf("WhatsApp: QR code displayed, waiting for scan")
+		slog.Info("WhatsApp: QR code displayed, waiting for scan")
 		return
 	}
 	if client.Store != nil && client.Store.ID != nil {
-		log.Printf("WhatsApp: session exists but not logged in yet, waiting for restoration...")
-		log.Printf("WhatsApp: if this persists, user may need to manually disconnect and reconnect")
+		slog.Info("WhatsApp: session exists but not logged in yet, waiting for restoration...")
+		slog.Info("WhatsApp: if this persists, user may need to manually disconnect and reconnect")
 		return
 	}
-	log.Printf("WhatsApp: no session found and no QR code received")
+	slog.Warn("WhatsApp: no session found and no QR code received")
 }
 
 func (c *Client) reinitialize() error {
@@ -158,7 +275,7 @@ func (c *Client) clearAndReinitialize() {
 	c.mu.Unlock()
 
 	if err := os.Remove(c.dbPath); err != nil && !os.IsNotExist(err) {
-		log.Printf("Failed to delete stale session file: %v", err)
+		slog.Error("Failed to delete stale session file", "error", err)
 		c.mu.Lock()
 		c.whatsappClient = nil
 		c.container = nil
@@ -167,7 +284,7 @@ func (c *Client) clearAndReinitialize() {
 	}
 
 	if err := c.reinitialize(); err != nil {
-		log.Printf("Failed to reinitialize after clearing stale session: %v", err)
+		slog.Error("Failed to reinitialize after clearing stale session", "error", err)
 		c.mu.Lock()
 		c.whatsappClient = nil
 		c.container = nil
@@ -175,7 +292,8 @@ func (c *Client) clearAndReinitialize() {
 		return
 	}
 
-	log.Printf("Stale session cleared and client reinitialized — ready for fresh QR scan")
+	slog.Info("Stale session cleared and client reinitialized — ready for fresh QR scan")
+	c.recordEvent(EventSessionCleared, "invalidated from the phone")
 }
 
 func (c *Client) handleEvent(evt interface{}) {
@@ -184,60 +302,245 @@ func (c *Client) handleEvent(evt interface{}) {
 		if len(v.Codes) > 0 {
 			c.mu.Lock()
 			c.qrReceived = true
+			c.stopQRRotationLocked()
+			stop := make(chan struct{})
+			c.stopQR = stop
+			c.recordEventLocked(EventQRIssued, "%d codes", len(v.Codes))
 			c.mu.Unlock()
-			if c.qrHandler != nil {
-				c.qrHandler(v.Codes[0])
-			}
+			go c.rotateQR(v.Codes, stop)
 		}
 
 	case *events.Connected:
-		log.Printf("WhatsApp connected")
+		slog.Info("WhatsApp connected")
 		c.mu.Lock()
 		c.connectedOnce = true
+		c.stopQRRotationLocked()
+		c.startKeepaliveLocked()
+		c.blocklist = blocklist{} // Possibly another account's, or changed while away
+		c.recordEventLocked(EventConnected, "")
+		qrClearHandler := c.qrClearHandler
+		statusHandler := c.statusHandler
 		c.mu.Unlock()
-		if c.qrClearHandler != nil {
-			c.qrClearHandler()
+		if qrClearHandler != nil {
+			qrClearHandler()
+		}
+		if statusHandler != nil {
+			statusHandler(true)
+		}
+
+	case *events.Disconnected:
+		c.mu.Lock()
+		c.stopQRRotationLocked()
+		c.stopKeepaliveLocked()
+		c.recordEventLocked(EventDisconnected, "")
+		statusHandler := c.statusHandler
+		c.mu.Unlock()
+		if statusHandler != nil {
+			statusHandler(false)
 		}
 
 	case *events.LoggedOut:
-		log.Printf("WhatsApp logged out: %+v", v)
+		slog.Warn("WhatsApp logged out", "on_connect", v.OnConnect, "reason", v.Reason.String())
 		c.mu.Lock()
 		c.connectedOnce = false
+		c.stopKeepaliveLocked()
+		c.recordEventLocked(EventLoggedOut, "on_connect: %t, reason: %s", v.OnConnect, v.Reason)
+		statusHandler := c.statusHandler
 		c.mu.Unlock()
+		if statusHandler != nil {
+			statusHandler(false)
+		}
 		// OnConnect=true means the session was invalidated from the phone side
 		if v.OnConnect {
 			if c.clearInProgress.CompareAndSwap(false, true) {
-				log.Printf("Session was invalidated externally, clearing stale session data...")
+				slog.Warn("Session was invalidated externally, clearing stale session data...")
 				go c.clearAndReinitialize()
 			}
 		}
 
+	case *events.PushName:
+		c.notifyContactUpdate(v.JID, v.JIDAlt, v.OldPushName)
+
+	case *events.BusinessName:
+		c.notifyContactUpdate(v.JID, types.EmptyJID, v.OldBusinessName)
+
+	case *events.Contact:
+		// A full sync replays the whole address book; only live edits are reported
+		if !v.FromFullSync {
+			c.notifyContactUpdate(v.JID, types.EmptyJID, "")
+		}
+
+	case *events.Receipt:
+		// Receipts from our own devices and retry requests say nothing about the recipient
+		switch v.Type {
+		case types.ReceiptTypeDelivered, types.ReceiptTypeRead, types.ReceiptTypePlayed:
+		default:
+			return
+		}
+		c.mu.RLock()
+		handler := c.receiptHandler
+		c.mu.RUnlock()
+		if handler != nil && !v.IsFromMe {
+			handler(Receipt{MessageIDs: v.MessageIDs, Timestamp: v.Timestamp})
+		}
+
+	case *events.Blocklist:
+		c.mu.Lock()
+		c.applyBlocklistEventLocked(v)
+		c.mu.Unlock()
+
 	case *events.ClientOutdated:
-		log.Printf("CLIENT OUTDATED: run 'go get -u go.mau.fi/whatsmeow@latest && go mod tidy'")
+		slog.Error("CLIENT OUTDATED: run 'go get -u go.mau.fi/whatsmeow@latest && go mod tidy'")
+		c.recordEvent(EventClientOutdated, "")
+
+	case *events.ConnectFailure:
+		c.recordEvent(EventConnectFailure, "%s: %s", v.Reason, v.Message)
+
+	case *events.StreamReplaced:
+		c.recordEvent(EventStreamReplaced, "another client connected with this session")
+
+	case *events.TemporaryBan:
+		c.recordEvent(EventTemporaryBan, "%s", v)
+
+	case *events.KeepAliveTimeout:
+		c.recordEvent(EventKeepaliveTimeout, "%d errors, last success %s", v.ErrorCount, v.LastSuccess.Format(time.RFC3339))
 
 	case *events.Message:
-		if c.eventHandler != nil {
-			c.eventHandler(v)
+		c.mu.RLock()
+		handlers := c.messageHandlers
+		c.mu.RUnlock()
+		for _, handler := range handlers {
+			handler(v)
 		}
 	}
 }
 
-func (c *Client) SetMessageHandler(handler func(*events.Message)) {
-	c.eventHandler = handler
+// AddMessageHandler registers a callback for incoming messages.
+// Handlers run in registration order on the whatsmeow event goroutine.
+func (c *Client) AddMessageHandler(handler func(*events.Message)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Copy-on-write so handleEvent can iterate a snapshot without holding the lock
+	handlers := make([]func(*events.Message), len(c.messageHandlers), len(c.messageHandlers)+1)
+	copy(handlers, c.messageHandlers)
+	c.messageHandlers = append(handlers, handler)
+}
+
+// rotateQR hands each code of a pairing attempt to the QR handler as the previous
+// one expires, then clears the QR once the attempt runs out of codes.
+func (c *Client) rotateQR(codes []string, stop chan struct{}) {
+	for i, code := range codes {
+		timeout := nextQRTimeout
+		if i == 0 {
+			timeout = firstQRTimeout
+		}
+
+		c.mu.RLock()
+		qrHandler := c.qrHandler
+		c.mu.RUnlock()
+		if qrHandler != nil {
+			qrHandler(QRCode{Code: code, Index: i, ExpiresAt: time.Now().Add(timeout)})
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(timeout):
+		}
+	}
+
+	c.mu.RLock()
+	qrClearHandler := c.qrClearHandler
+	c.mu.RUnlock()
+	slog.Info("WhatsApp: pairing attempt ran out of QR codes")
+	c.recordEvent(EventQRExhausted, "%d codes shown", len(codes))
+	if qrClearHandler != nil {
+		qrClearHandler()
+	}
+}
+
+// stopQRRotationLocked ends the current rotateQR goroutine. Caller must hold c.mu.
+func (c *Client) stopQRRotationLocked() {
+	if c.stopQR != nil {
+		close(c.stopQR)
+		c.stopQR = nil
+	}
 }
 
-func (c *Client) SetQRHandler(handler func(string)) {
+func (c *Client) SetQRHandler(handler func(QRCode)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.qrHandler = handler
 }
 
 func (c *Client) SetQRClearHandler(handler func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.qrClearHandler = handler
 }
 
+// SetStatusHandler registers a callback invoked when the connection is established or lost.
+func (c *Client) SetStatusHandler(handler func(connected bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statusHandler = handler
+}
+
+// SetContactUpdateHandler registers a callback invoked when a contact's push name,
+// business name or address book entry changes.
+func (c *Client) SetContactUpdateHandler(handler func(ContactUpdate)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.contactHandler = handler
+}
+
+// SetReceiptHandler registers a callback invoked when a recipient's phone
+// acknowledges messages we sent. It runs on the whatsmeow event goroutine.
+func (c *Client) SetReceiptHandler(handler func(Receipt)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.receiptHandler = handler
+}
+
+// notifyContactUpdate resolves the contact's current display name from the store,
+// which whatsmeow has already updated, and passes it to the contact handler.
+// alt is the phone-number JID when jid is a LID.
+func (c *Client) notifyContactUpdate(jid, alt types.JID, oldName string) {
+	c.mu.RLock()
+	handler := c.contactHandler
+	client := c.whatsappClient
+	c.mu.RUnlock()
+	if handler == nil || client == nil {
+		return
+	}
+
+	if jid.Server == types.HiddenUserServer && alt.Server == types.DefaultUserServer {
+		jid = alt
+	}
+	jid = jid.ToNonAD()
+	if jid.Server != types.DefaultUserServer {
+		return
+	}
+
+	info, err := client.Store.Contacts.GetContact(context.Background(), jid)
+	if err != nil {
+		slog.Error("Failed to look up updated contact", "jid", jid.String(), "error", err)
+		return
+	}
+
+	handler(ContactUpdate{
+		JID:     jid.String(),
+		OldName: oldName,
+		Name:    displayName(info, jid.User),
+	})
+}
+
 // Disconnect closes the websocket and releases the session database file lock.
 func (c *Client) Disconnect() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.stopQRRotationLocked()
+	c.stopKeepaliveLocked()
 	if c.whatsappClient != nil {
 		c.whatsappClient.Disconnect()
 	}
@@ -247,12 +550,23 @@ func (c *Client) Disconnect() {
 	}
 }
 
+// Close disconnects and releases the instance lock on the session database.
+// Use it on shutdown; Disconnect alone keeps the lock so the session can be reopened.
+func (c *Client) Close() {
+	c.Disconnect()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.lock.Release(); err != nil {
+		slog.Error("Failed to release session lock", "error", err)
+	}
+}
+
 // ClearSession removes the stored session and reinitializes the client for a fresh QR scan.
 func (c *Client) ClearSession() error {
 	c.clearMu.Lock()
 	defer c.clearMu.Unlock()
 
-	log.Printf("Clearing WhatsApp session...")
+	slog.Info("Clearing WhatsApp session...")
 	c.Disconnect()
 
 	c.mu.Lock()
@@ -272,7 +586,8 @@ func (c *Client) ClearSession() error {
 		return fmt.Errorf("session cleared but failed to reinitialize: %w", err)
 	}
 
-	log.Printf("Session cleared — client ready for reconnection")
+	slog.Info("Session cleared — client ready for reconnection")
+	c.recordEvent(EventSessionCleared, "on request")
 	return nil
 }
 
@@ -296,6 +611,35 @@ func (c *Client) HasSession() bool {
 	return client.Store.ID != nil
 }
 
+// OwnJID returns the JID of the logged-in account without device part, or "" when there is no session.
+func (c *Client) OwnJID() string {
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+	if client == nil || client.Store == nil || client.Store.ID == nil {
+		return ""
+	}
+	return client.Store.ID.ToNonAD().String()
+}
+
+// State reports the current connection state. A websocket without a logged-in
+// session is pairing when no device is linked yet and connecting otherwise.
+func (c *Client) State() ConnectionState {
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+	switch {
+	case client == nil || !client.IsConnected():
+		return StateDisconnected
+	case client.IsLoggedIn():
+		return StateConnected
+	case client.Store == nil || client.Store.ID == nil:
+		return StatePairing
+	default:
+		return StateConnecting
+	}
+}
+
 // IsConnecting returns true if websocket is connected but not yet authenticated (session restoring).
 func (c *Client) IsConnecting() bool {
 	c.mu.RLock()
@@ -307,18 +651,22 @@ func (c *Client) IsConnecting() bool {
 	return client.IsConnected() && !client.IsLoggedIn()
 }
 
-func (c *Client) SendMessage(ctx context.Context, jid string, message string) error {
+func (c *Client) SendMessage(ctx context.Context, jid string, message string) (string, error) {
+	if c.IsReadOnly() {
+		return "", ErrReadOnly
+	}
+
 	c.mu.RLock()
 	client := c.whatsappClient
 	c.mu.RUnlock()
 
 	if client == nil || !client.IsConnected() || !client.IsLoggedIn() {
-		return fmt.Errorf("whatsapp client not connected")
+		return "", ErrNotConnected
 	}
 
 	recipientJID, err := types.ParseJID(jid)
 	if err != nil {
-		return fmt.Errorf("invalid JID format: %w", err)
+		return "", fmt.Errorf("%w: %v", ErrInvalidJID, err)
 	}
 
 	textMessage := &waProto.Message{
@@ -327,11 +675,12 @@ func (c *Client) SendMessage(ctx context.Context, jid string, message string) er
 
 	resp, err := client.SendMessage(ctx, recipientJID, textMessage)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		c.recordEvent(EventSendError, "%s: %v", jid, err)
+		return "", fmt.Errorf("failed to send message: %w", err)
 	}
 
-	log.Printf("Message sent to %s, ID: %s", jid, resp.ID)
-	return nil
+	slog.Debug("WhatsApp message sent", "jid", jid, "message_id", resp.ID)
+	return resp.ID, nil
 }
 
 func (c *Client) GetContacts() ([]Contact, error) {
@@ -351,29 +700,67 @@ func (c *Client) GetContacts() ([]Contact, error) {
 
 	var result []Contact
 	for jid, contactInfo := range contacts {
-		phone := jid.User
-		name := contactInfo.FullName
-		if name == "" && contactInfo.FirstName != "" {
-			name = contactInfo.FirstName
-		}
-		if name == "" && contactInfo.PushName != "" {
-			name = contactInfo.PushName
+		contact := Contact{JID: jid}
+		if jid.Server == types.HiddenUserServer {
+			pn, err := client.Store.LIDs.GetPNForLID(ctx, jid)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve hidden number %s: %w", jid, err)
+			}
+			if !pn.IsEmpty() {
+				// The same person is usually stored under their number as well
+				if _, ok := contacts[pn]; ok {
+					continue
+				}
+				contact.JID = pn
+				contact.LID = jid.String()
+			}
 		}
-		if name == "" {
-			name = phone
+		fillContact(&contact, contactInfo)
+		result = append(result, contact)
+	}
+
+	// Stable order so callers can page through the list
+	sort.Slice(result, func(i, j int) bool {
+		ni, nj := strings.ToLower(result[i].Name), strings.ToLower(result[j].Name)
+		if ni != nj {
+			return ni < nj
 		}
+		return result[i].Phone < result[j].Phone
+	})
 
-		result = append(result, Contact{
-			JID:       jid,
-			Phone:     phone,
-			Name:      name,
-			PushName:  contactInfo.PushName,
-			FirstName: contactInfo.FirstName,
-			FullName:  contactInfo.FullName,
-		})
+	return result, nil
+}
+
+// fillContact sets the type, phone and name fields of a contact whose JID is set.
+// An unresolved LID has no phone; its name falls back to the JID.
+func fillContact(contact *Contact, info types.ContactInfo) {
+	contact.JIDType = JIDTypePhone
+	contact.Phone = contact.JID.User
+	fallback := contact.Phone
+	if contact.JID.Server == types.HiddenUserServer {
+		contact.JIDType = JIDTypeLID
+		contact.Phone = ""
+		fallback = contact.JID.String()
 	}
+	contact.Name = displayName(info, fallback)
+	contact.PushName = info.PushName
+	contact.FirstName = info.FirstName
+	contact.FullName = info.FullName
+}
 
-	return result, nil
+// displayName picks the name shown for a contact: the address book name, then
+// the first name, then the name they set themselves, then the phone number.
+func displayName(info types.ContactInfo, phone string) string {
+	switch {
+	case info.FullName != "":
+		return info.FullName
+	case info.FirstName != "":
+		return info.FirstName
+	case info.PushName != "":
+		return info.PushName
+	default:
+		return phone
+	}
 }
 
 func (c *Client) SearchContacts(query string) ([]Contact, error) {
@@ -429,6 +816,35 @@ func (c *Client) ValidatePhones(phones []string) (map[string]bool, error) {
 	return result, nil
 }
 
+// GetProfilePicture returns the preview-size profile picture of a contact.
+func (c *Client) GetProfilePicture(ctx context.Context, jid string) (*ProfilePicture, error) {
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+
+	if client == nil || !client.IsConnected() || !client.IsLoggedIn() {
+		return nil, fmt.Errorf("whatsapp client not connected")
+	}
+
+	parsed, err := types.ParseJID(jid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JID format: %w", err)
+	}
+
+	info, err := client.GetProfilePictureInfo(ctx, parsed, &whatsmeow.GetProfilePictureParams{Preview: true})
+	if errors.Is(err, whatsmeow.ErrProfilePictureNotSet) || errors.Is(err, whatsmeow.ErrProfilePictureUnauthorized) {
+		return nil, ErrNoProfilePicture
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile picture: %w", err)
+	}
+	if info == nil || info.URL == "" {
+		return nil, ErrNoProfilePicture
+	}
+
+	return &ProfilePicture{ID: info.ID, URL: info.URL}, nil
+}
+
 func (c *Client) FindContactByName(name string) (*Contact, error) {
 	contacts, err := c.SearchContacts(name)
 	if err != nil {
@@ -452,26 +868,77 @@ func (c *Client) FindContactByName(name string) (*Contact, error) {
 }
 
 // FindContactByJID retrieves a contact by their WhatsApp JID. Returns nil if not found.
-func (c *Client) FindContactByJID(jid string) (*Contact, error) {
-	contacts, err := c.GetContacts()
+// It looks up the one contact; to resolve many JIDs, use ContactsByJID.
+func (c *Client) FindContactByJID(ctx context.Context, jid string) (*Contact, error) {
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+
+	if client == nil || !client.IsConnected() || !client.IsLoggedIn() {
+		return nil, fmt.Errorf("whatsapp client not connected")
+	}
+
+	parsed, err := types.ParseJID(jid)
 	if err != nil {
-		return nil, err
+		return nil, nil
 	}
 
-	for _, contact := range contacts {
-		if contact.JID.String() == jid {
-			return &contact, nil
-		}
+	info, err := client.Store.Contacts.GetContact(ctx, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	if !info.Found {
+		return nil, nil
 	}
 
-	return nil, nil
+	contact := &Contact{JID: parsed}
+	fillContact(contact, info)
+	return contact, nil
+}
+
+// ResolveLID returns the phone-number JID whatsmeow has recorded for a hidden
+// user ID (LID), or "" when it has none. Other JIDs are returned unchanged.
+// Mappings are stored locally, so this works while disconnected.
+func (c *Client) ResolveLID(jid string) (string, error) {
+	parsed, err := types.ParseJID(jid)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidJID, err)
+	}
+	if parsed.Server != types.HiddenUserServer {
+		return jid, nil
+	}
+
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+
+	if client == nil || client.Store == nil {
+		return "", fmt.Errorf("whatsapp client not initialized")
+	}
+	// A device that was never paired has no mappings
+	if client.Store.LIDs == nil {
+		return "", nil
+	}
+
+	pn, err := client.Store.LIDs.GetPNForLID(context.Background(), parsed.ToNonAD())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve hidden number: %w", err)
+	}
+	if pn.IsEmpty() {
+		return "", nil
+	}
+	return pn.ToNonAD().String(), nil
 }
 
 func (c *Client) ResolveRecipient(identifier string) (string, error) {
 	identifier = strings.TrimSpace(identifier)
 
+	if strings.Contains(identifier, "@") {
+		return NormalizeJID(identifier)
+	}
+
 	if isPhoneNumber(identifier) {
-		return formatPhoneToJID(identifier), nil
+		return formatPhoneToJID(c.applyCountryCode(identifier)), nil
 	}
 
 	contact, err := c.FindContactByName(identifier)
@@ -482,6 +949,49 @@ func (c *Client) ResolveRecipient(identifier string) (string, error) {
 	return contact.JID.String(), nil
 }
 
+// SetCountryCodeProvider registers a function returning the default country calling code,
+// applied to numbers entered in national format (leading single 0). Called per use so
+// setting changes take effect without a restart.
+func (c *Client) SetCountryCodeProvider(fn func() string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.countryCode = fn
+}
+
+// SetReadOnlyCheck registers a function reporting whether read-only mode is on.
+// While it returns true, SendMessage refuses every message with ErrReadOnly.
+func (c *Client) SetReadOnlyCheck(fn func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readOnly = fn
+}
+
+// IsReadOnly reports whether outgoing messages are currently frozen.
+func (c *Client) IsReadOnly() bool {
+	c.mu.RLock()
+	fn := c.readOnly
+	c.mu.RUnlock()
+	return fn != nil && fn()
+}
+
+// applyCountryCode turns a national number like 0555 123 4567 into 905551234567
+// when a default country code is configured. International numbers are unchanged.
+func (c *Client) applyCountryCode(phone string) string {
+	c.mu.RLock()
+	fn := c.countryCode
+	c.mu.RUnlock()
+	if fn == nil || strings.HasPrefix(phone, "+") {
+		return phone
+	}
+
+	cc := fn()
+	digits := formatPhoneDigits(phone)
+	if cc == "" || !strings.HasPrefix(digits, "0") || strings.HasPrefix(digits, "00") {
+		return phone
+	}
+	return cc + strings.TrimLeft(digits, "0")
+}
+
 func isPhoneNumber(s string) bool {
 	if s == "" {
 		return false
@@ -507,11 +1017,15 @@ func isPhoneNumber(s string) bool {
 }
 
 func formatPhoneToJID(phone string) string {
+	return formatPhoneDigits(phone) + "@s.whatsapp.net"
+}
+
+func formatPhoneDigits(phone string) string {
 	cleaned := ""
 	for _, char := range phone {
 		if char >= '0' && char <= '9' {
 			cleaned += string(char)
 		}
 	}
-	return cleaned + "@s.whatsapp.net"
+	return cleaned
 }
@@ -0,0 +1,52 @@
+package whatsapp
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// TestMessageHandlersWhileDelivering registers message handlers from several
+// goroutines while messages are being delivered; run with -race to check
+// registration against delivery.
+func TestMessageHandlersWhileDelivering(t *testing.T) {
+	c := &Client{}
+	const registrars, perRegistrar = 10, 10
+
+	stop := make(chan struct{})
+	delivered := make(chan struct{})
+	go func() {
+		defer close(delivered)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				c.handleEvent(&events.Message{})
+			}
+		}
+	}()
+
+	var calls atomic.Int64
+	var wg sync.WaitGroup
+	for range registrars {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perRegistrar {
+				c.AddMessageHandler(func(*events.Message) { calls.Add(1) })
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-delivered
+
+	before := calls.Load()
+	c.handleEvent(&events.Message{})
+	if got := calls.Load() - before; got != registrars*perRegistrar {
+		t.Errorf("a message reached %d handlers, want all %d", got, registrars*perRegistrar)
+	}
+}
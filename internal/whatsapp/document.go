@@ -0,0 +1,63 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// SendDocument uploads data and sends it to jid as a document named filename,
+// with caption shown under it. An empty caption sends the document alone.
+func (c *Client) SendDocument(ctx context.Context, jid string, data []byte, filename, mimeType, caption string) (string, error) {
+	if c.IsReadOnly() {
+		return "", ErrReadOnly
+	}
+
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+
+	if client == nil || !client.IsConnected() || !client.IsLoggedIn() {
+		return "", ErrNotConnected
+	}
+
+	recipientJID, err := types.ParseJID(jid)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidJID, err)
+	}
+
+	upload, err := client.Upload(ctx, data, whatsmeow.MediaDocument)
+	if err != nil {
+		c.recordEvent(EventSendError, "%s: upload: %v", jid, err)
+		return "", fmt.Errorf("failed to upload document: %w", err)
+	}
+
+	document := &waProto.DocumentMessage{
+		URL:           proto.String(upload.URL),
+		DirectPath:    proto.String(upload.DirectPath),
+		MediaKey:      upload.MediaKey,
+		FileEncSHA256: upload.FileEncSHA256,
+		FileSHA256:    upload.FileSHA256,
+		FileLength:    proto.Uint64(upload.FileLength),
+		Mimetype:      proto.String(mimeType),
+		FileName:      proto.String(filename),
+		Title:         proto.String(filename),
+	}
+	if caption != "" {
+		document.Caption = proto.String(caption)
+	}
+
+	resp, err := client.SendMessage(ctx, recipientJID, &waProto.Message{DocumentMessage: document})
+	if err != nil {
+		c.recordEvent(EventSendError, "%s: %v", jid, err)
+		return "", fmt.Errorf("failed to send document: %w", err)
+	}
+
+	slog.Debug("WhatsApp document sent", "jid", jid, "file", filename, "message_id", resp.ID)
+	return resp.ID, nil
+}
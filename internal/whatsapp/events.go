@@ -0,0 +1,81 @@
+package whatsapp
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxClientEvents caps the client's event log; older entries are dropped.
+const maxClientEvents = 200
+
+// Kinds of ClientEvent.
+const (
+	EventConnectAttempt     = "connect_attempt"
+	EventConnectFailure     = "connect_failure"
+	EventQRIssued           = "qr_issued"
+	EventQRExhausted        = "qr_exhausted"
+	EventConnected          = "connected"
+	EventDisconnected       = "disconnected" // Followed by whatsmeow's own reconnect attempts unless logged out
+	EventLoggedOut          = "logged_out"
+	EventClientOutdated     = "client_outdated"
+	EventStreamReplaced     = "stream_replaced"
+	EventTemporaryBan       = "temporary_ban"
+	EventKeepaliveTimeout   = "keepalive_timeout"
+	EventKeepaliveReconnect = "keepalive_reconnect"
+	EventSendError          = "send_error"
+	EventSessionCleared     = "session_cleared"
+)
+
+// ClientEvent is one entry of the client's event log: something about pairing,
+// the connection or sending that is worth seeing when debugging remotely.
+type ClientEvent struct {
+	At     time.Time `json:"at"`
+	Kind   string    `json:"kind"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// eventLog is a ring of the last maxClientEvents events.
+type eventLog struct {
+	entries []ClientEvent
+	next    int // Where the next entry goes once entries is full, i.e. the oldest one
+}
+
+func (l *eventLog) add(event ClientEvent) {
+	if len(l.entries) < maxClientEvents {
+		l.entries = append(l.entries, event)
+		return
+	}
+	l.entries[l.next] = event
+	l.next = (l.next + 1) % maxClientEvents
+}
+
+// recordEvent adds an entry to the event log.
+func (c *Client) recordEvent(kind, format string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recordEventLocked(kind, format, args...)
+}
+
+// recordEventLocked is recordEvent for callers holding c.mu.
+func (c *Client) recordEventLocked(kind, format string, args ...any) {
+	c.events.add(ClientEvent{At: time.Now(), Kind: kind, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Events returns the logged events newer than since, newest first; a zero
+// since returns all of them. The log keeps the last 200 and starts empty on
+// every start of the server.
+func (c *Client) Events(since time.Time) []ClientEvent {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	n := len(c.events.entries)
+	events := []ClientEvent{}
+	for i := range n {
+		event := c.events.entries[(c.events.next-1-i+n)%n]
+		if !event.At.After(since) {
+			break
+		}
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,94 @@
+package whatsapp
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// keepaliveTimeouts feeds handleEvent n keepalive timeouts, numbered from first
+// by their error count.
+func keepaliveTimeouts(c *Client, first, n int) {
+	for i := range n {
+		c.handleEvent(&events.KeepAliveTimeout{ErrorCount: first + i})
+	}
+}
+
+// errorCounts returns the error count of each logged keepalive timeout, in the
+// order Events returns them.
+func errorCounts(t *testing.T, logged []ClientEvent) []int {
+	t.Helper()
+	counts := make([]int, len(logged))
+	for i, event := range logged {
+		if event.Kind != EventKeepaliveTimeout {
+			t.Fatalf("event %d is %q, want %q", i, event.Kind, EventKeepaliveTimeout)
+		}
+		if _, err := fmt.Sscanf(event.Detail, "%d errors", &counts[i]); err != nil {
+			t.Fatalf("event %d detail %q: %v", i, event.Detail, err)
+		}
+	}
+	return counts
+}
+
+func TestEventLogWrapsAround(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		added int
+	}{
+		{name: "empty", added: 0},
+		{name: "partly filled", added: 3},
+		{name: "exactly full", added: maxClientEvents},
+		{name: "one evicted", added: maxClientEvents + 1},
+		{name: "wrapped partway", added: maxClientEvents + 57},
+		{name: "wrapped twice", added: 2*maxClientEvents + 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Client{}
+			keepaliveTimeouts(c, 0, tc.added)
+
+			got := errorCounts(t, c.Events(time.Time{}))
+			want := min(tc.added, maxClientEvents)
+			if len(got) != want {
+				t.Fatalf("%d events kept, want %d", len(got), want)
+			}
+			// Newest first, back to the oldest one not evicted
+			for i, count := range got {
+				if wantCount := tc.added - 1 - i; count != wantCount {
+					t.Fatalf("event %d has error count %d, want %d; log is %v", i, count, wantCount, got)
+				}
+			}
+			if cap(c.events.entries) > 2*maxClientEvents {
+				t.Errorf("log grew to a capacity of %d", cap(c.events.entries))
+			}
+		})
+	}
+}
+
+// TestEventLogSinceAcrossWrap asks for the events after a time whose newer
+// events straddle the end of the ring.
+func TestEventLogSinceAcrossWrap(t *testing.T) {
+	c := &Client{}
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	total := maxClientEvents + 10
+	for i := range total {
+		c.events.add(ClientEvent{At: start.Add(time.Duration(i) * time.Second), Kind: EventKeepaliveTimeout, Detail: fmt.Sprintf("%d errors", i)})
+	}
+
+	// The newest 15 sit at the start of the ring (10) and at its end (5)
+	since := start.Add(time.Duration(total-16) * time.Second)
+	got := errorCounts(t, c.Events(since))
+	if len(got) != 15 {
+		t.Fatalf("%d events after since, want 15: %v", len(got), got)
+	}
+	for i, count := range got {
+		if want := total - 1 - i; count != want {
+			t.Errorf("event %d has error count %d, want %d", i, count, want)
+		}
+	}
+
+	if got := c.Events(start.Add(time.Duration(total) * time.Second)); len(got) != 0 {
+		t.Errorf("%d events after the newest, want none", len(got))
+	}
+}
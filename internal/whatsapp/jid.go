@@ -0,0 +1,89 @@
+package whatsapp
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// JID types reported on contacts and group members.
+const (
+	JIDTypePhone = "phone" // Phone-number JID; can be validated and sent to
+	JIDTypeLID   = "lid"   // Hidden user ID with no known phone number
+)
+
+// IsLID reports whether a normalized JID is a hidden user ID (LID) rather than
+// a phone number, e.g. 123456789012345@lid.
+func IsLID(jid string) bool {
+	return strings.HasSuffix(jid, "@"+types.HiddenUserServer)
+}
+
+// InvalidJIDError is returned by NormalizeJID. It matches ErrInvalidJID with errors.Is.
+type InvalidJIDError struct {
+	JID    string
+	Reason string
+}
+
+func (e *InvalidJIDError) Error() string {
+	return fmt.Sprintf("%v %q: %s", ErrInvalidJID, e.JID, e.Reason)
+}
+
+func (e *InvalidJIDError) Is(target error) bool {
+	return target == ErrInvalidJID
+}
+
+// NormalizeJID validates a user, LID or group JID and returns its canonical
+// form: surrounding space trimmed, the server lowercased (c.us becomes
+// s.whatsapp.net) and agent/device suffixes dropped, so 905551234567.0:3@S.WhatsApp.net
+// becomes 905551234567@s.whatsapp.net. Every JID stored or sent to should go
+// through it.
+func NormalizeJID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", &InvalidJIDError{JID: s, Reason: "empty"}
+	}
+
+	at := strings.LastIndexByte(s, '@')
+	if at == -1 {
+		return "", &InvalidJIDError{JID: s, Reason: "no server part, e.g. 905551234567@s.whatsapp.net"}
+	}
+	s = s[:at] + "@" + strings.ToLower(s[at+1:])
+
+	parsed, err := types.ParseJID(s)
+	if err != nil {
+		return "", &InvalidJIDError{JID: s, Reason: err.Error()}
+	}
+	if parsed.Server == types.LegacyUserServer {
+		parsed.Server = types.DefaultUserServer
+	}
+
+	switch parsed.Server {
+	case types.DefaultUserServer, types.HiddenUserServer:
+		if !isDigits(parsed.User) {
+			return "", &InvalidJIDError{JID: s, Reason: "user part must be digits"}
+		}
+	case types.GroupServer:
+		// Older groups are creator-timestamp, e.g. 905551234567-1600000000
+		creator, created, dashed := strings.Cut(parsed.User, "-")
+		if !isDigits(creator) || (dashed && !isDigits(created)) {
+			return "", &InvalidJIDError{JID: s, Reason: "group ID must be digits"}
+		}
+	default:
+		return "", &InvalidJIDError{JID: s, Reason: fmt.Sprintf("unsupported server %q (use s.whatsapp.net, lid or g.us)", parsed.Server)}
+	}
+
+	return parsed.ToNonAD().String(), nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, char := range s {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
+}
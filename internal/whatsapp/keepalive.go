@@ -0,0 +1,176 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	// keepaliveTick is how often the keepalive loop re-reads its settings, so a
+	// changed interval takes effect without waiting out the old one.
+	keepaliveTick = 5 * time.Second
+
+	// keepaliveMaxFailures consecutive failed keepalives drop the connection and
+	// hand it to whatsmeow's reconnect loop.
+	keepaliveMaxFailures = 3
+
+	keepaliveTimeout = 20 * time.Second
+)
+
+// KeepaliveStatus reports the outcome of the periodic presence updates.
+type KeepaliveStatus struct {
+	LastAt      time.Time // Last successful keepalive; zero if none yet
+	LastError   string    // Most recent failure, kept after later successes
+	LastErrorAt time.Time
+	Failures    int // Consecutive failures since the last success
+}
+
+// SetKeepaliveConfig registers a function returning the keepalive interval
+// (0 disables it) and the presence to advertise, "available" or "unavailable".
+// Called on every tick so setting changes apply to the running connection.
+func (c *Client) SetKeepaliveConfig(fn func() (time.Duration, string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keepaliveConfig = fn
+}
+
+// Keepalive returns the state of the keepalive loop.
+func (c *Client) Keepalive() KeepaliveStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.keepalive
+}
+
+// startKeepaliveLocked starts a keepalive loop for a new connection, replacing
+// any previous one. Caller must hold c.mu.
+func (c *Client) startKeepaliveLocked() {
+	c.stopKeepaliveLocked()
+	stop := make(chan struct{})
+	c.stopKeepalive = stop
+	c.keepalive.Failures = 0
+	go c.runKeepalive(stop)
+}
+
+// stopKeepaliveLocked ends the current runKeepalive goroutine. Caller must hold c.mu.
+func (c *Client) stopKeepaliveLocked() {
+	if c.stopKeepalive != nil {
+		close(c.stopKeepalive)
+		c.stopKeepalive = nil
+	}
+}
+
+func (c *Client) runKeepalive(stop chan struct{}) {
+	ticker := time.NewTicker(keepaliveTick)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		c.mu.RLock()
+		config := c.keepaliveConfig
+		c.mu.RUnlock()
+		if config == nil {
+			continue
+		}
+		interval, presence := config()
+		if interval <= 0 || time.Since(last) < interval {
+			continue
+		}
+		last = time.Now()
+
+		err := c.sendKeepalive(presence)
+		if c.recordKeepalive(stop, err) {
+			go c.forceReconnect(stop, err)
+			return
+		}
+	}
+}
+
+func (c *Client) sendKeepalive(presence string) error {
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+	if client == nil {
+		return errors.New("whatsapp client not initialized")
+	}
+
+	state := types.PresenceUnavailable
+	if presence == string(types.PresenceAvailable) {
+		state = types.PresenceAvailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keepaliveTimeout)
+	defer cancel()
+	return client.SendPresence(ctx, state)
+}
+
+// recordKeepalive stores the outcome of one keepalive and reports whether the
+// connection has failed often enough to be reset. Results from a loop that has
+// been stopped in the meantime are dropped.
+func (c *Client) recordKeepalive(stop chan struct{}, err error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopKeepalive != stop {
+		return false
+	}
+
+	if err == nil {
+		c.keepalive.LastAt = time.Now()
+		c.keepalive.Failures = 0
+		return false
+	}
+
+	c.keepalive.LastError = err.Error()
+	c.keepalive.LastErrorAt = time.Now()
+	// Without a push name the server refuses presence updates; the connection itself is fine
+	if errors.Is(err, whatsmeow.ErrNoPushName) {
+		slog.Warn("WhatsApp keepalive skipped: account has no push name yet")
+		return false
+	}
+	c.keepalive.Failures++
+	slog.Warn("WhatsApp keepalive failed", "error", err, "failures", c.keepalive.Failures)
+	return c.keepalive.Failures >= keepaliveMaxFailures
+}
+
+// forceReconnect drops the connection after repeated keepalive failures and
+// starts whatsmeow's reconnect loop, the same way its own websocket keepalive
+// recovers. A Disconnect or ClearSession meanwhile cancels the reconnect.
+func (c *Client) forceReconnect(stop chan struct{}, cause error) {
+	c.connectMu.Lock()
+	defer c.connectMu.Unlock()
+
+	c.mu.Lock()
+	if c.stopKeepalive != stop {
+		c.mu.Unlock()
+		return
+	}
+	c.stopKeepaliveLocked()
+	client := c.whatsappClient
+	statusHandler := c.statusHandler
+	c.recordEventLocked(EventKeepaliveReconnect, "%v", cause)
+	c.mu.Unlock()
+	if client == nil {
+		return
+	}
+
+	slog.Warn("WhatsApp keepalive kept failing, reconnecting", "error", cause)
+	client.Disconnect()
+	if statusHandler != nil {
+		statusHandler(false)
+	}
+
+	//lint:ignore SA1019 whatsmeow exposes no other way to start its reconnect loop
+	internals := client.DangerousInternals()
+	internals.ResetExpectedDisconnect()
+	go internals.AutoReconnect(context.Background())
+}
@@ -0,0 +1,69 @@
+package whatsapp
+
+import "context"
+
+// Sender sends messages from the linked account and reports whether sending is
+// currently possible.
+type Sender interface {
+	IsConnected() bool
+	IsReadOnly() bool
+	OwnJID() string
+	// SendMessage and SendDocument return the WhatsApp ID of the sent message,
+	// which its receipts refer to.
+	SendMessage(ctx context.Context, jid string, message string) (string, error)
+	SendDocument(ctx context.Context, jid string, data []byte, filename, mimeType, caption string) (string, error)
+}
+
+// ContactStore looks up the linked account's contacts and checks numbers
+// against WhatsApp.
+type ContactStore interface {
+	GetContacts() ([]Contact, error)
+	SearchContacts(query string) ([]Contact, error)
+	FindContactByJID(ctx context.Context, jid string) (*Contact, error)
+	ValidatePhones(phones []string) (map[string]bool, error)
+	ResolveRecipient(identifier string) (string, error)
+	ResolveLID(jid string) (string, error)
+	// BlockedJIDs returns the JIDs the linked account has blocked.
+	BlockedJIDs(ctx context.Context) (map[string]bool, error)
+}
+
+// Messenger is the part of Client that the batch worker and the message and
+// contact handlers depend on. Session management (pairing, connecting, clearing)
+// stays on Client. whatsapptest.Fake implements Messenger without a live session.
+type Messenger interface {
+	Sender
+	ContactStore
+}
+
+var _ Messenger = (*Client)(nil)
+
+// SessionState reports the state of the linked session for status displays.
+type SessionState interface {
+	IsConnected() bool
+	IsConnecting() bool
+	HasSession() bool
+	IsReadOnly() bool
+	State() ConnectionState
+}
+
+var _ SessionState = (*Client)(nil)
+
+// ContactsByJID loads the contact list once and indexes it by JID string, and by
+// LID for contacts resolved to a phone number, for callers that look up many
+// contacts at a time. Nil when the list is unavailable,
+// e.g. while disconnected; lookups in a nil map find nothing.
+func ContactsByJID(store ContactStore) map[string]Contact {
+	contacts, err := store.GetContacts()
+	if err != nil {
+		return nil
+	}
+
+	byJID := make(map[string]Contact, len(contacts))
+	for _, contact := range contacts {
+		byJID[contact.JID.String()] = contact
+		if contact.LID != "" {
+			byJID[contact.LID] = contact
+		}
+	}
+	return byJID
+}
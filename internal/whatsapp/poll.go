@@ -0,0 +1,82 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// The number of options a poll may have.
+const (
+	MinPollOptions = 2
+	MaxPollOptions = 12
+)
+
+// ErrInvalidPoll is returned by SendPoll for a poll WhatsApp would not show.
+var ErrInvalidPoll = errors.New("invalid poll")
+
+// ValidatePoll checks a poll before it is sent: a question, and 2 to 12
+// options that are neither empty nor repeated, since votes refer to an option
+// by its text.
+func ValidatePoll(question string, options []string) error {
+	if strings.TrimSpace(question) == "" {
+		return fmt.Errorf("%w: question is required", ErrInvalidPoll)
+	}
+	if len(options) < MinPollOptions || len(options) > MaxPollOptions {
+		return fmt.Errorf("%w: %d options given, need %d to %d", ErrInvalidPoll, len(options), MinPollOptions, MaxPollOptions)
+	}
+	seen := make(map[string]bool, len(options))
+	for i, option := range options {
+		if strings.TrimSpace(option) == "" {
+			return fmt.Errorf("%w: option %d is empty", ErrInvalidPoll, i+1)
+		}
+		if seen[option] {
+			return fmt.Errorf("%w: option %q is repeated", ErrInvalidPoll, option)
+		}
+		seen[option] = true
+	}
+	return nil
+}
+
+// SendPoll sends a poll to jid, a contact or a WhatsApp group. With multiSelect
+// a voter may pick any number of options, otherwise one. Returns the ID of the
+// poll message; votes are not collected.
+func (c *Client) SendPoll(ctx context.Context, jid, question string, options []string, multiSelect bool) (string, error) {
+	if c.IsReadOnly() {
+		return "", ErrReadOnly
+	}
+	if err := ValidatePoll(question, options); err != nil {
+		return "", err
+	}
+
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+
+	if client == nil || !client.IsConnected() || !client.IsLoggedIn() {
+		return "", ErrNotConnected
+	}
+
+	recipientJID, err := types.ParseJID(jid)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidJID, err)
+	}
+
+	// whatsmeow takes how many options may be picked, 0 meaning any number
+	selectable := 1
+	if multiSelect {
+		selectable = 0
+	}
+	resp, err := client.SendMessage(ctx, recipientJID, client.BuildPollCreation(question, options, selectable))
+	if err != nil {
+		c.recordEvent(EventSendError, "%s: poll: %v", jid, err)
+		return "", fmt.Errorf("failed to send poll: %w", err)
+	}
+
+	slog.Debug("WhatsApp poll sent", "jid", jid, "options", len(options), "message_id", resp.ID)
+	return resp.ID, nil
+}
@@ -0,0 +1,119 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"unicode"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// Errors returned by SendReaction.
+var (
+	ErrInvalidReaction       = errors.New("invalid reaction") // The emoji is not a single grapheme
+	ErrTargetMessageRequired = errors.New("target message ID is required")
+)
+
+// Runes that extend the character before them into one grapheme.
+const (
+	zeroWidthJoiner = '\u200d' // Joins two emoji into one, e.g. a woman and a laptop
+	combiningKeycap = '\u20e3' // Turns a digit, # or * into a keycap
+	variationText   = '\ufe0e' // Text presentation of the character before it
+	variationEmoji  = '\ufe0f' // Emoji presentation of the character before it
+	skinToneFirst   = 0x1F3FB  // Skin tone modifiers
+	skinToneLast    = 0x1F3FF
+	regionalFirst   = 0x1F1E6 // Regional indicators; a pair is a flag
+	regionalLast    = 0x1F1FF
+	tagFirst        = 0xE0020 // Tags spelling out subdivision flags, e.g. Scotland's
+	tagLast         = 0xE007F
+)
+
+// ValidateReaction checks that emoji is a single grapheme: one character,
+// optionally extended by variation selectors, skin tones, a keycap, tags or
+// further emoji joined with a zero-width joiner, or a flag made of two
+// regional indicators. An empty emoji, which removes a reaction, is valid.
+func ValidateReaction(emoji string) error {
+	runes := []rune(emoji)
+	if len(runes) == 0 {
+		return nil
+	}
+	if len(runes) == 2 && isRegionalIndicator(runes[0]) && isRegionalIndicator(runes[1]) {
+		return nil
+	}
+	if !unicode.IsGraphic(runes[0]) || unicode.IsSpace(runes[0]) || extendsGrapheme(runes[0]) {
+		return fmt.Errorf("%w: %q does not start with a visible character", ErrInvalidReaction, emoji)
+	}
+	for i := 1; i < len(runes); i++ {
+		switch {
+		case extendsGrapheme(runes[i]):
+		case runes[i] == zeroWidthJoiner && i+1 < len(runes) && unicode.IsGraphic(runes[i+1]):
+			i++ // The joined character belongs to the same grapheme
+		default:
+			return fmt.Errorf("%w: %q is more than one emoji", ErrInvalidReaction, emoji)
+		}
+	}
+	return nil
+}
+
+// extendsGrapheme reports whether r modifies the character before it rather
+// than starting a new one.
+func extendsGrapheme(r rune) bool {
+	switch {
+	case r == variationText, r == variationEmoji, r == combiningKeycap:
+		return true
+	case r >= skinToneFirst && r <= skinToneLast, r >= tagFirst && r <= tagLast:
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= regionalFirst && r <= regionalLast
+}
+
+// SendReaction reacts with emoji to the message targetMessageID in chatJID, a
+// contact or a WhatsApp group. targetSender is the JID of whoever wrote that
+// message, defaulting to chatJID, so it must be given for messages in groups;
+// the linked account's own JID reacts to a message it sent. An empty emoji
+// removes an earlier reaction. Returns the ID of the reaction message.
+func (c *Client) SendReaction(ctx context.Context, chatJID, targetMessageID, targetSender, emoji string) (string, error) {
+	if c.IsReadOnly() {
+		return "", ErrReadOnly
+	}
+	if targetMessageID == "" {
+		return "", ErrTargetMessageRequired
+	}
+	if err := ValidateReaction(emoji); err != nil {
+		return "", err
+	}
+
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+
+	if client == nil || !client.IsConnected() || !client.IsLoggedIn() {
+		return "", ErrNotConnected
+	}
+
+	chat, err := types.ParseJID(chatJID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidJID, err)
+	}
+	sender := chat
+	if targetSender != "" {
+		if sender, err = types.ParseJID(targetSender); err != nil {
+			return "", fmt.Errorf("%w: target sender: %v", ErrInvalidJID, err)
+		}
+	}
+
+	resp, err := client.SendMessage(ctx, chat, client.BuildReaction(chat, sender.ToNonAD(), targetMessageID, emoji))
+	if err != nil {
+		c.recordEvent(EventSendError, "%s: reaction: %v", chatJID, err)
+		return "", fmt.Errorf("failed to send reaction: %w", err)
+	}
+
+	slog.Debug("WhatsApp reaction sent", "jid", chatJID, "target_id", targetMessageID, "emoji", emoji, "message_id", resp.ID)
+	return resp.ID, nil
+}
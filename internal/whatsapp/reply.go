@@ -0,0 +1,69 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrQuotedMessageRequired is returned by SendReply without the ID of the
+// message being replied to.
+var ErrQuotedMessageRequired = errors.New("quoted message ID is required")
+
+// SendReply sends text to jid as a reply to an earlier message, which WhatsApp
+// shows quoted above it. quotedSender is the JID of whoever wrote the quoted
+// message, defaulting to jid; quotedText is its text for the quoted bubble.
+// Returns the ID of the new message.
+func (c *Client) SendReply(ctx context.Context, jid, text, quotedMessageID, quotedSender, quotedText string) (string, error) {
+	if c.IsReadOnly() {
+		return "", ErrReadOnly
+	}
+	if quotedMessageID == "" {
+		return "", ErrQuotedMessageRequired
+	}
+
+	c.mu.RLock()
+	client := c.whatsappClient
+	c.mu.RUnlock()
+
+	if client == nil || !client.IsConnected() || !client.IsLoggedIn() {
+		return "", ErrNotConnected
+	}
+
+	recipientJID, err := types.ParseJID(jid)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidJID, err)
+	}
+	senderJID := recipientJID
+	if quotedSender != "" {
+		if senderJID, err = types.ParseJID(quotedSender); err != nil {
+			return "", fmt.Errorf("%w: quoted sender: %v", ErrInvalidJID, err)
+		}
+	}
+
+	contextInfo := &waProto.ContextInfo{
+		StanzaID:    proto.String(quotedMessageID),
+		Participant: proto.String(senderJID.ToNonAD().String()),
+	}
+	if quotedText != "" {
+		contextInfo.QuotedMessage = &waProto.Message{Conversation: proto.String(quotedText)}
+	}
+
+	resp, err := client.SendMessage(ctx, recipientJID, &waProto.Message{
+		ExtendedTextMessage: &waProto.ExtendedTextMessage{
+			Text:        proto.String(text),
+			ContextInfo: contextInfo,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to send reply: %w", err)
+	}
+
+	slog.Debug("WhatsApp reply sent", "jid", jid, "quoted_id", quotedMessageID, "message_id", resp.ID)
+	return resp.ID, nil
+}
@@ -0,0 +1,133 @@
+package whatsapptest
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultOwnPhone is the number the fake is logged in as unless FRIDAY_FAKE_OWN_PHONE says otherwise.
+const DefaultOwnPhone = "15550000000"
+
+// Config is how FromEnv sets up a fake for running the server without WhatsApp.
+type Config struct {
+	OwnPhone     string
+	Latency      time.Duration     // Every send takes this long
+	FailureRate  float64           // Fraction of sends failed, 0 to 1
+	Seed         int64             // Picks which sends fail
+	ReceiptDelay time.Duration     // Delivery receipts arrive this long after a send
+	Contacts     map[string]string // Phone -> name
+	Unregistered []string          // Phones reported as not on WhatsApp
+	Blocked      []string          // Phones blocked by the linked account
+	PairAfter    time.Duration     // Non-zero to start unpaired, showing a QR code scanned this long after, see SimulatePairing
+}
+
+// ConfigFromEnv reads the FRIDAY_FAKE_* variables:
+//
+//	FRIDAY_FAKE_OWN_PHONE      number of the linked account (default 15550000000)
+//	FRIDAY_FAKE_LATENCY        duration of every send, e.g. 200ms (default 0)
+//	FRIDAY_FAKE_FAILURE_RATE   fraction of sends that fail, 0 to 1 (default 0)
+//	FRIDAY_FAKE_SEED           seed picking the failed sends (default 1)
+//	FRIDAY_FAKE_RECEIPT_DELAY  delay before a sent message is reported delivered (default 500ms)
+//	FRIDAY_FAKE_CONTACTS       address book, phone=Name pairs separated by commas
+//	FRIDAY_FAKE_UNREGISTERED   comma-separated numbers that are not on WhatsApp
+//	FRIDAY_FAKE_BLOCKED        comma-separated numbers the linked account has blocked
+//	FRIDAY_FAKE_PAIR_AFTER     start unpaired and pair this long after showing a QR code, e.g. 10s (default 0, paired)
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		OwnPhone:     DefaultOwnPhone,
+		Seed:         1,
+		ReceiptDelay: 500 * time.Millisecond,
+		Contacts:     make(map[string]string),
+	}
+
+	if v := os.Getenv("FRIDAY_FAKE_OWN_PHONE"); v != "" {
+		config.OwnPhone = strings.TrimPrefix(v, "+")
+	}
+	if v := os.Getenv("FRIDAY_FAKE_LATENCY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return Config{}, fmt.Errorf("invalid FRIDAY_FAKE_LATENCY %q: want a duration such as 200ms", v)
+		}
+		config.Latency = d
+	}
+	if v := os.Getenv("FRIDAY_FAKE_FAILURE_RATE"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return Config{}, fmt.Errorf("invalid FRIDAY_FAKE_FAILURE_RATE %q: want a number from 0 to 1", v)
+		}
+		config.FailureRate = rate
+	}
+	if v := os.Getenv("FRIDAY_FAKE_SEED"); v != "" {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid FRIDAY_FAKE_SEED %q: want an integer", v)
+		}
+		config.Seed = seed
+	}
+	if v := os.Getenv("FRIDAY_FAKE_RECEIPT_DELAY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return Config{}, fmt.Errorf("invalid FRIDAY_FAKE_RECEIPT_DELAY %q: want a duration such as 1s", v)
+		}
+		config.ReceiptDelay = d
+	}
+	if v := os.Getenv("FRIDAY_FAKE_PAIR_AFTER"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return Config{}, fmt.Errorf("invalid FRIDAY_FAKE_PAIR_AFTER %q: want a duration such as 10s", v)
+		}
+		config.PairAfter = d
+	}
+	for _, entry := range strings.Split(os.Getenv("FRIDAY_FAKE_CONTACTS"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		phone, name, ok := strings.Cut(entry, "=")
+		phone = strings.TrimPrefix(strings.TrimSpace(phone), "+")
+		if !ok || phone == "" || strings.Trim(phone, "0123456789") != "" {
+			return Config{}, fmt.Errorf("invalid FRIDAY_FAKE_CONTACTS entry %q: want phone=Name", entry)
+		}
+		config.Contacts[phone] = strings.TrimSpace(name)
+	}
+	config.Unregistered = phoneList(os.Getenv("FRIDAY_FAKE_UNREGISTERED"))
+	config.Blocked = phoneList(os.Getenv("FRIDAY_FAKE_BLOCKED"))
+	return config, nil
+}
+
+// NewFromConfig returns a fake set up as config says, connected unless
+// config.PairAfter is set. Receipts are only delivered once SetReceiptHandler
+// is called, with config.ReceiptDelay.
+func NewFromConfig(config Config) *Fake {
+	f := New(config.OwnPhone + "@s.whatsapp.net")
+	if config.PairAfter > 0 {
+		f.Unpair()
+	}
+	f.SetLatency(config.Latency)
+	if config.FailureRate > 0 {
+		f.SetFailureRate(config.FailureRate, config.Seed)
+	}
+	for phone, name := range config.Contacts {
+		f.AddContact(phone, name)
+	}
+	for _, phone := range config.Unregistered {
+		f.SetRegistered(phone, false)
+	}
+	for _, phone := range config.Blocked {
+		f.SetBlocked(phone, true)
+	}
+	return f
+}
+
+// phoneList splits a comma-separated list of numbers, dropping a leading + and empty entries.
+func phoneList(value string) []string {
+	var phones []string
+	for _, phone := range strings.Split(value, ",") {
+		if phone = strings.TrimPrefix(strings.TrimSpace(phone), "+"); phone != "" {
+			phones = append(phones, phone)
+		}
+	}
+	return phones
+}
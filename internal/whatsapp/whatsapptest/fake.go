@@ -0,0 +1,595 @@
+// Package whatsapptest provides an in-memory whatsapp.Messenger for exercising
+// the batch worker and handlers without a live WhatsApp session.
+package whatsapptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"friday/internal/whatsapp"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNotConnected is returned by the fake's calls while it is disconnected,
+// matching the real client's behaviour.
+var ErrNotConnected = whatsapp.ErrNotConnected
+
+// ErrSimulatedFailure is returned by sends picked to fail by SetFailureRate.
+var ErrSimulatedFailure = errors.New("simulated send failure")
+
+// SentMessage is a message the fake accepted. For documents, Message is the caption.
+type SentMessage struct {
+	ID      string // Message ID returned to the caller: FAKE1, FAKE2, ...
+	JID     string
+	Message string
+	SentAt  time.Time
+
+	FileName string // Empty for text messages
+	MimeType string
+	Size     int
+}
+
+// Fake is a scriptable whatsapp.Messenger. It starts connected, not read-only,
+// with no contacts, and treats every number as registered on WhatsApp.
+// All methods are safe for concurrent use.
+type Fake struct {
+	mu            sync.Mutex
+	connected     bool
+	paired        bool // Has a linked session; false after Unpair until ScanQR
+	pairing       bool // A QR code is showing, see ShowQR
+	qrShown       int  // QR codes shown in the current pairing attempt
+	readOnly      bool
+	readOnlyCheck func() bool
+	ownJID        string
+	latency       time.Duration
+	contacts      []whatsapp.Contact
+	registered    map[string]bool
+	blocked       map[string]bool    // JIDs blocked by the linked account
+	lids          map[string]string  // LID JID -> phone-number JID
+	failures      map[string][]error // queued send errors per JID; "" matches any JID
+	sendErr       error
+	failureRate   float64
+	rng           *rand.Rand // Picks the sends failed by failureRate
+	sent          []SentMessage
+	attempts      int
+	received      int // Incoming messages delivered by ReceiveMessage
+
+	statusHandler   func(connected bool)
+	receiptHandler  func(whatsapp.Receipt)
+	receiptDelay    time.Duration
+	qrHandler       func(whatsapp.QRCode)
+	qrClearHandler  func()
+	messageHandlers []func(*events.Message)
+
+	// OnSend, if set, runs at the start of every send attempt, before latency and
+	// scripted failures. Returning an error fails the send with it. It may call
+	// the fake's setters, e.g. to disconnect mid-batch.
+	OnSend func(jid, message string) error
+}
+
+var (
+	_ whatsapp.Messenger    = (*Fake)(nil)
+	_ whatsapp.SessionState = (*Fake)(nil)
+)
+
+// New returns a connected fake logged in as ownJID.
+func New(ownJID string) *Fake {
+	return &Fake{
+		connected:  true,
+		paired:     true,
+		ownJID:     ownJID,
+		registered: make(map[string]bool),
+		blocked:    make(map[string]bool),
+		lids:       make(map[string]string),
+		failures:   make(map[string][]error),
+	}
+}
+
+// SetConnected connects or disconnects the fake, calling the status handler
+// when the state changes, as the real client does on Connected and Disconnected.
+// Connecting links a session if there was none and ends a pairing attempt,
+// clearing its QR code.
+func (f *Fake) SetConnected(connected bool) {
+	f.mu.Lock()
+	changed := f.connected != connected
+	f.connected = connected
+	var qrClearHandler func()
+	if connected {
+		f.paired = true
+		if f.pairing {
+			f.pairing = false
+			qrClearHandler = f.qrClearHandler
+		}
+	}
+	handler := f.statusHandler
+	f.mu.Unlock()
+
+	if qrClearHandler != nil {
+		qrClearHandler()
+	}
+	if changed && handler != nil {
+		handler(connected)
+	}
+}
+
+// Unpair logs the fake out, as unlinking the device from the phone does: it
+// disconnects and has no session until a QR code is scanned.
+func (f *Fake) Unpair() {
+	f.mu.Lock()
+	f.paired = false
+	f.mu.Unlock()
+	f.SetConnected(false)
+}
+
+// ShowQR starts a pairing attempt, or continues the current one, by handing
+// code to the QR handler as the real client does with each code of an attempt.
+// It does nothing while connected.
+func (f *Fake) ShowQR(code string, expiresIn time.Duration) {
+	f.mu.Lock()
+	if f.connected {
+		f.mu.Unlock()
+		return
+	}
+	if !f.pairing {
+		f.pairing = true
+		f.qrShown = 0
+	}
+	qr := whatsapp.QRCode{Code: code, Index: f.qrShown, ExpiresAt: time.Now().Add(expiresIn)}
+	f.qrShown++
+	handler := f.qrHandler
+	f.mu.Unlock()
+
+	if handler != nil {
+		handler(qr)
+	}
+}
+
+// ScanQR completes pairing: the fake connects with a session, clearing the QR
+// code and calling the status handler, as on the real client's Connected event.
+func (f *Fake) ScanQR() {
+	f.SetConnected(true)
+}
+
+// SimulatePairing unpairs the fake and shows a QR code that is scanned after
+// the given time, for running the pairing flow of the web UI without a phone.
+func (f *Fake) SimulatePairing(after time.Duration) {
+	f.Unpair()
+	f.ShowQR("FAKE-QR-"+f.ownJID, after)
+	time.AfterFunc(after, f.ScanQR)
+}
+
+// SetQRHandler sets the function given each QR code shown, as Client.SetQRHandler.
+func (f *Fake) SetQRHandler(handler func(whatsapp.QRCode)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.qrHandler = handler
+}
+
+// SetQRClearHandler sets the function called when pairing ends and its QR code
+// is no longer valid, as Client.SetQRClearHandler.
+func (f *Fake) SetQRClearHandler(handler func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.qrClearHandler = handler
+}
+
+// AddMessageHandler registers a function given every message ReceiveMessage
+// delivers, as Client.AddMessageHandler does for incoming messages.
+func (f *Fake) AddMessageHandler(handler func(*events.Message)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messageHandlers = append(f.messageHandlers, handler)
+}
+
+// ReceiveMessage delivers a text message from phone to the message handlers,
+// in registration order, and returns its ID: FAKEIN1, FAKEIN2, ...
+func (f *Fake) ReceiveMessage(phone, text string) string {
+	f.mu.Lock()
+	f.received++
+	id := fmt.Sprintf("FAKEIN%d", f.received)
+	handlers := slices.Clone(f.messageHandlers)
+	f.mu.Unlock()
+
+	sender := types.NewJID(strings.TrimPrefix(phone, "+"), types.DefaultUserServer)
+	evt := &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{Chat: sender, Sender: sender},
+			ID:            id,
+			Timestamp:     time.Now(),
+		},
+		Message: &waProto.Message{Conversation: proto.String(text)},
+	}
+	for _, handler := range handlers {
+		handler(evt)
+	}
+	return id
+}
+
+// SetReadOnly turns read-only mode on or off; sends then fail with whatsapp.ErrReadOnly.
+func (f *Fake) SetReadOnly(readOnly bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.readOnly = readOnly
+}
+
+// SetReadOnlyCheck makes read-only mode follow check, as Client.SetReadOnlyCheck
+// does; SetReadOnly still turns it on regardless.
+func (f *Fake) SetReadOnlyCheck(check func() bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.readOnlyCheck = check
+}
+
+// SetStatusHandler sets the function called when SetConnected changes the connection state.
+func (f *Fake) SetStatusHandler(handler func(connected bool)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statusHandler = handler
+}
+
+// SetReceiptHandler sets the function given a delivery receipt for every
+// accepted message, delay after the send.
+func (f *Fake) SetReceiptHandler(handler func(whatsapp.Receipt), delay time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.receiptHandler = handler
+	f.receiptDelay = delay
+}
+
+// SetLatency makes every send take d, or less if its context ends first.
+func (f *Fake) SetLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+}
+
+// FailNext queues errors for the next sends to jid, one per send, in order.
+// An empty jid matches sends to any recipient.
+func (f *Fake) FailNext(jid string, errs ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[jid] = append(f.failures[jid], errs...)
+}
+
+// SetFailureRate fails that fraction of the sends not failed otherwise, with
+// ErrSimulatedFailure. Which ones fail depends only on seed and the order of
+// the sends, so a run can be repeated exactly.
+func (f *Fake) SetFailureRate(rate float64, seed int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failureRate = rate
+	f.rng = rand.New(rand.NewSource(seed))
+}
+
+// FailAll makes every send fail with err until it is called again with nil.
+func (f *Fake) FailAll(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sendErr = err
+}
+
+// AddContact adds a contact to the address book, returning its JID.
+func (f *Fake) AddContact(phone, name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	jid := types.NewJID(phone, types.DefaultUserServer)
+	f.contacts = append(f.contacts, whatsapp.Contact{
+		JID:      jid,
+		JIDType:  whatsapp.JIDTypePhone,
+		Phone:    phone,
+		Name:     name,
+		FullName: name,
+	})
+	return jid.String()
+}
+
+// AddLIDContact adds a contact known only by its hidden user ID, returning its
+// JID, e.g. "123456789012345@lid". See SetLIDMapping to give it a number.
+func (f *Fake) AddLIDContact(lid, name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	jid := types.NewJID(lid, types.HiddenUserServer)
+	f.contacts = append(f.contacts, whatsapp.Contact{
+		JID:      jid,
+		JIDType:  whatsapp.JIDTypeLID,
+		Name:     name,
+		FullName: name,
+	})
+	return jid.String()
+}
+
+// SetLIDMapping records the phone number of a hidden user ID for ResolveLID.
+func (f *Fake) SetLIDMapping(lid, phone string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lids[types.NewJID(lid, types.HiddenUserServer).String()] = types.NewJID(phone, types.DefaultUserServer).String()
+}
+
+// SetRegistered marks a number as on or off WhatsApp for ValidatePhones.
+func (f *Fake) SetRegistered(phone string, registered bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.registered[phone] = registered
+}
+
+// SetBlocked blocks or unblocks a number for BlockedJIDs. Sends to it still succeed.
+func (f *Fake) SetBlocked(phone string, blocked bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	jid := types.NewJID(phone, types.DefaultUserServer).String()
+	if blocked {
+		f.blocked[jid] = true
+	} else {
+		delete(f.blocked, jid)
+	}
+}
+
+// Sent returns the messages accepted so far, oldest first.
+func (f *Fake) Sent() []SentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]SentMessage(nil), f.sent...)
+}
+
+// Attempts returns how many times SendMessage was called, including failures.
+func (f *Fake) Attempts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts
+}
+
+func (f *Fake) IsConnected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connected
+}
+
+func (f *Fake) IsReadOnly() bool {
+	f.mu.Lock()
+	readOnly, check := f.readOnly, f.readOnlyCheck
+	f.mu.Unlock()
+	return readOnly || check != nil && check()
+}
+
+// HasSession reports a linked session, which the fake has from the start and
+// loses only through Unpair.
+func (f *Fake) HasSession() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.paired
+}
+
+// IsConnecting is always false: the fake connects at once.
+func (f *Fake) IsConnecting() bool {
+	return false
+}
+
+// State is connected, pairing while a QR code shown with ShowQR waits to be
+// scanned, or disconnected.
+func (f *Fake) State() whatsapp.ConnectionState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case f.connected:
+		return whatsapp.StateConnected
+	case f.pairing:
+		return whatsapp.StatePairing
+	default:
+		return whatsapp.StateDisconnected
+	}
+}
+
+func (f *Fake) OwnJID() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.connected {
+		return ""
+	}
+	return f.ownJID
+}
+
+// SendMessage checks read-only mode, the OnSend hook, connection, JID format,
+// latency and scripted failures, in that order, then records the message.
+func (f *Fake) SendMessage(ctx context.Context, jid string, message string) (string, error) {
+	return f.send(ctx, SentMessage{JID: jid, Message: message})
+}
+
+// SendDocument goes through the same checks as SendMessage and records the
+// document's name, type and size with the caption.
+func (f *Fake) SendDocument(ctx context.Context, jid string, data []byte, filename, mimeType, caption string) (string, error) {
+	return f.send(ctx, SentMessage{JID: jid, Message: caption, FileName: filename, MimeType: mimeType, Size: len(data)})
+}
+
+func (f *Fake) send(ctx context.Context, msg SentMessage) (string, error) {
+	jid, message := msg.JID, msg.Message
+	f.mu.Lock()
+	f.attempts++
+	onSend := f.OnSend
+	f.mu.Unlock()
+
+	if f.IsReadOnly() {
+		return "", whatsapp.ErrReadOnly
+	}
+	if onSend != nil {
+		if err := onSend(jid, message); err != nil {
+			return "", err
+		}
+	}
+
+	f.mu.Lock()
+	connected, latency := f.connected, f.latency
+	f.mu.Unlock()
+
+	if !connected {
+		return "", ErrNotConnected
+	}
+	if _, err := types.ParseJID(jid); err != nil || !strings.Contains(jid, "@") {
+		return "", fmt.Errorf("%w: %s", whatsapp.ErrInvalidJID, jid)
+	}
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return "", fmt.Errorf("failed to send message: %w", ctx.Err())
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.nextFailureLocked(jid); err != nil {
+		return "", err
+	}
+	msg.ID = fmt.Sprintf("FAKE%d", len(f.sent)+1)
+	msg.SentAt = time.Now()
+	f.sent = append(f.sent, msg)
+
+	if handler := f.receiptHandler; handler != nil {
+		time.AfterFunc(f.receiptDelay, func() {
+			handler(whatsapp.Receipt{MessageIDs: []string{msg.ID}, Timestamp: time.Now()})
+		})
+	}
+	return msg.ID, nil
+}
+
+// nextFailureLocked pops the scripted error for a send to jid, if any.
+func (f *Fake) nextFailureLocked(jid string) error {
+	for _, key := range []string{jid, ""} {
+		if queue := f.failures[key]; len(queue) > 0 {
+			f.failures[key] = queue[1:]
+			return queue[0]
+		}
+	}
+	if f.sendErr != nil {
+		return f.sendErr
+	}
+	if f.rng != nil && f.rng.Float64() < f.failureRate {
+		return ErrSimulatedFailure
+	}
+	return nil
+}
+
+func (f *Fake) GetContacts() ([]whatsapp.Contact, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.connected {
+		return nil, ErrNotConnected
+	}
+	contacts := append([]whatsapp.Contact(nil), f.contacts...)
+	sort.Slice(contacts, func(i, j int) bool {
+		ni, nj := strings.ToLower(contacts[i].Name), strings.ToLower(contacts[j].Name)
+		if ni != nj {
+			return ni < nj
+		}
+		return contacts[i].Phone < contacts[j].Phone
+	})
+	return contacts, nil
+}
+
+func (f *Fake) SearchContacts(query string) ([]whatsapp.Contact, error) {
+	contacts, err := f.GetContacts()
+	if err != nil || query == "" {
+		return contacts, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []whatsapp.Contact
+	for _, contact := range contacts {
+		if strings.Contains(strings.ToLower(contact.Name), query) || strings.Contains(contact.Phone, query) {
+			matches = append(matches, contact)
+		}
+	}
+	return matches, nil
+}
+
+// FindContactByJID returns the contact with the given JID, or nil if there is none.
+func (f *Fake) FindContactByJID(ctx context.Context, jid string) (*whatsapp.Contact, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	contacts, err := f.GetContacts()
+	if err != nil {
+		return nil, err
+	}
+	for _, contact := range contacts {
+		if contact.JID.String() == jid {
+			return &contact, nil
+		}
+	}
+	return nil, nil
+}
+
+// ValidatePhones reports every number as registered unless SetRegistered says otherwise.
+func (f *Fake) ValidatePhones(phones []string) (map[string]bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.connected {
+		return nil, ErrNotConnected
+	}
+	result := make(map[string]bool, len(phones))
+	for _, phone := range phones {
+		registered, known := f.registered[strings.TrimPrefix(phone, "+")]
+		result[phone] = !known || registered
+	}
+	return result, nil
+}
+
+// BlockedJIDs returns the numbers blocked with SetBlocked.
+func (f *Fake) BlockedJIDs(ctx context.Context) (map[string]bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.connected {
+		return nil, ErrNotConnected
+	}
+	blocked := make(map[string]bool, len(f.blocked))
+	for jid := range f.blocked {
+		blocked[jid] = true
+	}
+	return blocked, nil
+}
+
+// ResolveLID returns the number set with SetLIDMapping, "" for other LIDs, and
+// any other JID unchanged.
+func (f *Fake) ResolveLID(jid string) (string, error) {
+	if !whatsapp.IsLID(jid) {
+		return jid, nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lids[jid], nil
+}
+
+// ResolveRecipient maps a phone number to its JID and anything else to the
+// first contact whose name contains it.
+func (f *Fake) ResolveRecipient(identifier string) (string, error) {
+	identifier = strings.TrimSpace(identifier)
+	if strings.Contains(identifier, "@") {
+		return whatsapp.NormalizeJID(identifier)
+	}
+	digits := strings.TrimPrefix(identifier, "+")
+	if digits != "" && strings.Trim(digits, "0123456789") == "" {
+		return types.NewJID(digits, types.DefaultUserServer).String(), nil
+	}
+
+	contacts, err := f.SearchContacts(identifier)
+	if err != nil {
+		return "", err
+	}
+	if len(contacts) == 0 {
+		return "", fmt.Errorf("could not resolve recipient '%s': no contact found with name: %s", identifier, identifier)
+	}
+	return contacts[0].JID.String(), nil
+}
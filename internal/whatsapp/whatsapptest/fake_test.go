@@ -0,0 +1,74 @@
+package whatsapptest
+
+import (
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types/events"
+
+	"friday/internal/whatsapp"
+)
+
+func TestFakePairing(t *testing.T) {
+	f := New("15550000000@s.whatsapp.net")
+	var codes []whatsapp.QRCode
+	var cleared int
+	var statuses []bool
+	f.SetQRHandler(func(qr whatsapp.QRCode) { codes = append(codes, qr) })
+	f.SetQRClearHandler(func() { cleared++ })
+	f.SetStatusHandler(func(connected bool) { statuses = append(statuses, connected) })
+
+	f.Unpair()
+	if f.IsConnected() || f.HasSession() || f.State() != whatsapp.StateDisconnected {
+		t.Fatalf("after Unpair: connected %t, session %t, state %q", f.IsConnected(), f.HasSession(), f.State())
+	}
+
+	f.ShowQR("first", time.Minute)
+	f.ShowQR("second", time.Minute)
+	if f.State() != whatsapp.StatePairing {
+		t.Errorf("state while showing a QR code = %q, want %q", f.State(), whatsapp.StatePairing)
+	}
+	if len(codes) != 2 || codes[0].Code != "first" || codes[1].Index != 1 {
+		t.Errorf("QR handler got %+v, want first and second with indexes 0 and 1", codes)
+	}
+
+	f.ScanQR()
+	if !f.IsConnected() || !f.HasSession() || f.State() != whatsapp.StateConnected {
+		t.Errorf("after ScanQR: connected %t, session %t, state %q", f.IsConnected(), f.HasSession(), f.State())
+	}
+	if cleared != 1 {
+		t.Errorf("QR cleared %d times, want once", cleared)
+	}
+	if len(statuses) != 2 || statuses[0] || !statuses[1] {
+		t.Errorf("status handler got %v, want [false true]", statuses)
+	}
+
+	// A connected fake has nothing to pair
+	f.ShowQR("third", time.Minute)
+	if len(codes) != 2 {
+		t.Errorf("QR shown while connected")
+	}
+}
+
+func TestFakeReceiveMessage(t *testing.T) {
+	f := New("15550000000@s.whatsapp.net")
+	var got []*events.Message
+	f.AddMessageHandler(func(evt *events.Message) { got = append(got, evt) })
+	f.AddMessageHandler(func(evt *events.Message) { got = append(got, evt) })
+
+	id := f.ReceiveMessage("+905550000001", "Hello")
+
+	if len(got) != 2 {
+		t.Fatalf("handlers called %d times, want once each", len(got))
+	}
+	evt := got[0]
+	if evt.Info.ID != id || id != "FAKEIN1" {
+		t.Errorf("message ID = %q, returned %q; want FAKEIN1", evt.Info.ID, id)
+	}
+	if sender := evt.Info.Sender.String(); sender != "905550000001@s.whatsapp.net" {
+		t.Errorf("sender = %q", sender)
+	}
+	if text := evt.Message.GetConversation(); text != "Hello" {
+		t.Errorf("text = %q, want Hello", text)
+	}
+}
@@ -2,70 +2,378 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"friday/internal/batch"
+	"friday/internal/cli"
+	"friday/internal/config"
 	"friday/internal/database"
+	"friday/internal/events"
 	"friday/internal/handlers"
+	"friday/internal/logging"
 	"friday/internal/models"
 	"friday/internal/whatsapp"
+	"friday/internal/whatsapp/whatsapptest"
 )
 
 func main() {
+	// With no arguments, or "serve", run the server; anything else is a CLI
+	// subcommand talking to a running server.
+	if len(os.Args) > 1 && os.Args[1] != "serve" {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+	serve()
+}
+
+func serve() {
+	// FRIDAY_CONFIG_FILE is read first, since it may set the log level
+	configLoader, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := logging.Setup(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if path := configLoader.Path(); path != "" {
+		slog.Info("Configuration file loaded", "path", path)
+	}
+
 	appDB, err := database.New("friday.db")
 	if err != nil {
-		log.Fatalf("Failed to create app database: %v", err)
+		fatal("Failed to create app database", err)
 	}
 	defer appDB.Close()
-	log.Println("Application database initialized: friday.db")
+	slog.Info("Application database initialized", "path", "friday.db")
+
+	orphans, err := appDB.CleanOrphans()
+	if err != nil {
+		fatal("Failed to clean orphaned rows", err)
+	}
+	if orphans != nil && orphans.Changed() {
+		slog.Info("Cleaned orphaned rows", "deleted", orphans.Deleted, "detached", orphans.Detached)
+		if len(orphans.Kept) > 0 {
+			slog.Warn("Batch runs refer to a deleted draft or group and were kept", "runs", orphans.Kept)
+		}
+	}
+	if missing, err := appDB.MissingForeignKeys(); err != nil {
+		slog.Warn("Failed to check foreign keys", "error", err)
+	} else if len(missing) > 0 {
+		slog.Warn("Database schema lacks expected foreign keys; deletes will not cascade there", "columns", missing)
+	}
+
+	normalized, err := models.NormalizeStoredJIDs(appDB, whatsapp.NormalizeJID)
+	if err != nil {
+		fatal("Failed to normalize stored JIDs", err)
+	}
+	if normalized != nil {
+		slog.Info("Normalized stored JIDs", "rewritten", normalized.Rewritten, "merged", normalized.Merged)
+		if len(normalized.Invalid) > 0 {
+			slog.Warn("Stored JIDs could not be normalized and were left unchanged", "count", len(normalized.Invalid), "jids", normalized.Invalid)
+		}
+	}
 
 	whatsappClient, err := whatsapp.NewClient()
 	if err != nil {
-		log.Fatalf("Failed to create WhatsApp client: %v", err)
+		fatal("Failed to create WhatsApp client", err)
+	}
+	defer whatsappClient.Close()
+
+	app, err := newApp(appDB, whatsappClient)
+	if err != nil {
+		fatal("Failed to set up the server", err)
+	}
+
+	server := &http.Server{
+		Addr:         ":8080",
+		Handler:      app.handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	go func() {
+		slog.Info("Starting Friday WhatsApp API server", "addr", server.Addr)
+		slog.Info("Web: / (dashboard) | /drafts | /qr-scan | /groups | /batch-runs | /health")
+		slog.Info("API: /api/whatsapp/{status,connect,send,qr,qr.png}")
+		slog.Info("API: /api/contacts | /api/drafts | /api/groups | /api/batch-runs")
+
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fatal("Server failed to start", err)
+		}
+	}()
+
+	// Graceful shutdown; SIGHUP reloads the configuration instead
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range signals {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		reloadConfig(configLoader, app.settingsHandler, app.batchWorker, app.rateLimiter)
 	}
-	defer whatsappClient.Disconnect()
 
+	slog.Info("Shutting down server...")
+
+	app.shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		fatal("Server forced to shutdown", err)
+	}
+	slog.Info("Server exited")
+}
+
+// app is the server behind the HTTP listener: the handler serving every route
+// and the background loops it relies on.
+type app struct {
+	handler         http.Handler
+	batchWorker     *batch.Worker
+	maintainer      *batch.Maintainer
+	deliveryTracker *batch.DeliveryTracker
+	settingsHandler *handlers.SettingsHandler
+	rateLimiter     *handlers.RateLimiter
+	fake            *whatsapptest.Fake // Nil unless FRIDAY_FAKE_WHATSAPP is set
+}
+
+// newApp wires the repositories, batch worker and handlers to an open database
+// and WhatsApp client, reading the FRIDAY_* variables, and starts the
+// background loops; shutdown stops them.
+func newApp(appDB *database.DB, whatsappClient *whatsapp.Client) (*app, error) {
 	draftRepo := models.NewDraftRepository(appDB)
+	variantRepo := models.NewDraftVariantRepository(appDB)
 	attrRepo := models.NewAttributeRepository(appDB)
+	groupAttrRepo := models.NewGroupAttributeRepository(appDB)
+	noteRepo := models.NewContactNoteRepository(appDB)
+	mergeRepo := models.NewContactMergeRepository(appDB)
+	contactSyncRepo := models.NewContactSyncRepository(appDB)
 	groupRepo := models.NewGroupRepository(appDB)
 	memberRepo := models.NewGroupMemberRepository(appDB)
 	batchRepo := models.NewBatchRunRepository(appDB)
 	batchMsgRepo := models.NewBatchMessageRepository(appDB)
+	batchEventRepo := models.NewBatchEventRepository(appDB)
+	batchRecipientRepo := models.NewBatchRecipientRepository(appDB)
+	validationRepo := models.NewValidationRepository(appDB)
+	quarantineRepo := models.NewQuarantineRepository(appDB)
+	settingsRepo := models.NewSettingsRepository(appDB)
+	idempotencyRepo := models.NewIdempotencyRepository(appDB)
+	reportRepo := models.NewReportRepository(appDB)
+	outboxRepo := models.NewOutboxRepository(appDB)
+	maintenanceRepo := models.NewMaintenanceRepository(appDB)
+	workspaceRepo := models.NewWorkspaceRepository(appDB)
+	tokenRepo := models.NewAPITokenRepository(appDB)
+
+	eventHub := events.NewHub()
+
+	// With FRIDAY_FAKE_WHATSAPP, messages, contacts and the connection status go
+	// to an in-memory fake instead of WhatsApp, so the server can be run end to
+	// end without an account. Connecting, the account and polls, replies and
+	// reactions still use the real, unconnected client.
+	var messenger whatsapp.Messenger = whatsappClient
+	var session whatsapp.SessionState = whatsappClient
+	var fake *whatsapptest.Fake
+	var fakeConfig whatsapptest.Config
+	if v := os.Getenv("FRIDAY_FAKE_WHATSAPP"); v == "1" || v == "true" {
+		var err error
+		fakeConfig, err = whatsapptest.ConfigFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("invalid fake WhatsApp configuration: %w", err)
+		}
+		fake = whatsapptest.NewFromConfig(fakeConfig)
+		messenger, session = fake, fake
+		slog.Warn("Fake WhatsApp enabled: nothing is sent to WhatsApp", "own_jid", fake.OwnJID(), "latency", fakeConfig.Latency, "failure_rate", fakeConfig.FailureRate, "seed", fakeConfig.Seed)
+	}
 
-	batchWorker := batch.NewWorker(batchRepo, batchMsgRepo, memberRepo, draftRepo, attrRepo, whatsappClient)
+	batchWorker := batch.NewWorker(batchRepo, batchMsgRepo, batchEventRepo, memberRepo, draftRepo, attrRepo, groupAttrRepo, validationRepo, quarantineRepo, settingsRepo, outboxRepo, messenger, eventHub)
+	if spec := os.Getenv("FRIDAY_QUIET_HOURS"); spec != "" {
+		quietHours, err := batch.ParseQuietHours(spec, os.Getenv("FRIDAY_TIMEZONE"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid quiet hours configuration: %w", err)
+		}
+		batchWorker.SetQuietHours(quietHours)
+		slog.Info("Batch quiet hours", "window", quietHours.String())
+	}
+	batchWorker.SetNotifier(batch.NewNotifier(settingsRepo, messenger))
+	if dir := os.Getenv("FRIDAY_DOCUMENTS_DIR"); dir != "" {
+		documents, err := batch.NewDocuments(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid documents directory: %w", err)
+		}
+		batchWorker.SetDocuments(documents)
+		slog.Info("Batch documents enabled", "dir", documents.Dir())
+	}
+	whatsappClient.SetCountryCodeProvider(func() string {
+		cc, err := settingsRepo.GetString(models.SettingDefaultCountryCode, "")
+		if err != nil {
+			slog.Warn("Failed to read default country code", "error", err)
+		}
+		return cc
+	})
+	readOnlyCheck := func() bool {
+		readOnly, err := settingsRepo.GetBool(models.SettingReadOnly, false)
+		if err != nil {
+			slog.Warn("Failed to read read-only setting", "error", err)
+		}
+		return readOnly
+	}
+	whatsappClient.SetReadOnlyCheck(readOnlyCheck)
+	if fake != nil {
+		fake.SetReadOnlyCheck(readOnlyCheck)
+	}
+	whatsappClient.SetKeepaliveConfig(func() (time.Duration, string) {
+		seconds, err := settingsRepo.GetInt(models.SettingKeepaliveSeconds, models.DefaultKeepaliveSeconds)
+		if err != nil {
+			slog.Warn("Failed to read keepalive interval", "error", err)
+		}
+		presence, err := settingsRepo.GetString(models.SettingPresence, models.DefaultPresence)
+		if err != nil {
+			slog.Warn("Failed to read presence setting", "error", err)
+		}
+		return time.Duration(seconds) * time.Second, presence
+	})
+	if whatsappClient.IsReadOnly() {
+		slog.Warn("Read-only mode is on: outgoing messages are frozen")
+	}
+	whatsappClient.SetContactUpdateHandler(func(update whatsapp.ContactUpdate) {
+		renamed, err := batchMsgRepo.UpdateContactName(update.JID, update.Name)
+		if err != nil {
+			slog.Error("Failed to update pending messages for renamed contact", "jid", update.JID, "error", err)
+			return
+		}
+		if update.OldName != "" && update.OldName != update.Name {
+			slog.Info("Contact renamed", "jid", update.JID, "old_name", update.OldName, "name", update.Name, "pending_updated", renamed)
+		} else if renamed > 0 {
+			slog.Info("Contact name updated", "jid", update.JID, "name", update.Name, "pending_updated", renamed)
+		}
+	})
 	go batchWorker.Run()
 
+	deliveryTracker := batch.NewDeliveryTracker(batchMsgRepo, settingsRepo)
+	whatsappClient.SetReceiptHandler(deliveryTracker.HandleReceipt)
+	if fake != nil {
+		fake.SetReceiptHandler(deliveryTracker.HandleReceipt, fakeConfig.ReceiptDelay)
+	}
+	go deliveryTracker.Run()
+
+	maintainer := batch.NewMaintainer(appDB, batchRepo, outboxRepo, maintenanceRepo, settingsRepo, batchWorker, os.Getenv("FRIDAY_TIMEZONE"))
+	go maintainer.Run()
+
 	// Initialize handlers
-	whatsappHandler := handlers.NewWhatsAppHandler(whatsappClient)
-	contactHandler := handlers.NewContactHandler(whatsappClient)
-	qrHandler := handlers.NewQRHandler()
+	qrHandler := handlers.NewQRHandler(eventHub)
+	if v := os.Getenv("FRIDAY_TERMINAL_QR"); v == "1" || v == "true" {
+		qrHandler.SetTerminal(os.Stdout)
+	}
+	whatsappHandler := handlers.NewWhatsAppHandler(whatsappClient, messenger, session, qrHandler, outboxRepo, settingsRepo)
+	contactHandler := handlers.NewContactHandler(messenger, attrRepo, groupRepo, memberRepo, batchRepo, mergeRepo, batchMsgRepo, noteRepo, quarantineRepo, contactSyncRepo)
+	avatarHandler := handlers.NewAvatarHandler(whatsappClient)
 	webHandler := handlers.NewWebHandler(draftRepo, attrRepo, whatsappClient)
+	if dir := os.Getenv("FRIDAY_TEMPLATE_DIR"); dir != "" {
+		if err := webHandler.SetTemplateDir(dir); err != nil {
+			return nil, fmt.Errorf("invalid template directory: %w", err)
+		}
+		slog.Info("Web templates are read from disk on every request (dev mode)", "dir", dir)
+	}
 
 	// New handlers for drafts and attributes
-	draftHandler := handlers.NewDraftHandler(draftRepo, attrRepo, whatsappClient)
-	attrHandler := handlers.NewAttributeHandler(attrRepo)
+	draftHandler := handlers.NewDraftHandler(draftRepo, variantRepo, attrRepo, groupAttrRepo, memberRepo, batchRepo, settingsRepo, mergeRepo, outboxRepo, messenger)
+	attrHandler := handlers.NewAttributeHandler(attrRepo, noteRepo)
+	noteHandler := handlers.NewNoteHandler(noteRepo)
+	quarantineHandler := handlers.NewQuarantineHandler(quarantineRepo)
+	outboxHandler := handlers.NewOutboxHandler(outboxRepo)
 
 	// Contact groups and batch messaging handlers
-	groupHandler := handlers.NewGroupHandler(groupRepo, memberRepo, whatsappClient)
-	batchHandler := handlers.NewBatchHandler(batchRepo, batchMsgRepo, groupRepo, memberRepo, draftRepo, batchWorker, whatsappClient)
+	groupHandler := handlers.NewGroupHandler(groupRepo, memberRepo, groupAttrRepo, draftRepo, batchRepo, quarantineRepo, mergeRepo, contactSyncRepo, messenger)
+	batchHandler := handlers.NewBatchHandler(batchRepo, batchMsgRepo, batchEventRepo, batchRecipientRepo, groupRepo, memberRepo, draftRepo, variantRepo, attrRepo, groupAttrRepo, quarantineRepo, settingsRepo, batchWorker, messenger, os.Getenv("FRIDAY_TIMEZONE"))
+	integrationHandler := handlers.NewIntegrationHandler(settingsRepo, draftRepo, groupRepo, batchHandler)
+	reportHandler := handlers.NewReportHandler(reportRepo, settingsRepo, os.Getenv("FRIDAY_TIMEZONE"))
+
+	// Idempotency-Key support for resource-creating POSTs
+	idempotent := handlers.NewIdempotencyHandler(idempotencyRepo).Wrap
+	workspaceHandler := handlers.NewWorkspaceHandler(workspaceRepo, draftRepo, groupRepo)
+
+	// Cross-origin access to /api/; same-origin only unless FRIDAY_CORS_ORIGINS is set
+	corsOrigins := handlers.ParseCORSOrigins(os.Getenv("FRIDAY_CORS_ORIGINS"))
+	cors := handlers.NewCORSHandler(handlers.CORSConfig{
+		AllowedOrigins:   corsOrigins,
+		AllowCredentials: os.Getenv("FRIDAY_CORS_CREDENTIALS") == "true",
+	})
+	if cors.Enabled() {
+		slog.Info("CORS enabled", "origins", strings.Join(corsOrigins, ","))
+	}
+
+	// Per-client limits on expensive reads and all writes
+	rateLimitConfig, err := handlers.ParseRateLimitConfig(os.Getenv("FRIDAY_RATE_LIMIT_RPS"), os.Getenv("FRIDAY_RATE_LIMIT_BURST"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit: %w", err)
+	}
+	rateLimiter := handlers.NewRateLimiter(settingsRepo, rateLimitConfig)
+
+	// Admin settings
+	settingsHandler := handlers.NewSettingsHandler(settingsRepo)
+
+	// Global event stream for live UI updates
+	eventsHandler := handlers.NewEventsHandler(eventHub, session)
+
+	// Maintenance switches
+	adminHandler := handlers.NewAdminHandler(settingsRepo, whatsappClient, eventsHandler, maintainer)
+
+	// API tokens and their scopes; the API is open until the first token is created
+	authHandler := handlers.NewAuthHandler(tokenRepo)
+
+	// Optional features, registered by the handlers that provide them
+	capabilities := handlers.NewCapabilities()
+	authHandler.RegisterCapabilities(capabilities)
+	batchHandler.RegisterCapabilities(capabilities)
+	cors.RegisterCapabilities(capabilities)
+	integrationHandler.RegisterCapabilities(capabilities)
+	whatsappHandler.RegisterCapabilities(capabilities)
 
 	// Wire up QR code callbacks
 	whatsappClient.SetQRHandler(qrHandler.SetQR)
 	whatsappClient.SetQRClearHandler(qrHandler.ClearQR)
+	whatsappClient.SetStatusHandler(eventsHandler.PublishStatus)
+	if fake != nil {
+		fake.SetQRHandler(qrHandler.SetQR)
+		fake.SetQRClearHandler(qrHandler.ClearQR)
+		fake.SetStatusHandler(eventsHandler.PublishStatus)
+		if fakeConfig.PairAfter > 0 {
+			fake.SimulatePairing(fakeConfig.PairAfter)
+		}
+	}
 
 	mux := http.NewServeMux()
+	routes := handlers.NewRouteRegistry(mux)
 
-	// Health check
+	// Health check; ?verbose=true adds the last maintenance pass
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, `{"status": "ok", "service": "friday-whatsapp-api"}`)
+		if r.URL.Query().Get("verbose") != "true" {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"status": "ok", "service": "friday-whatsapp-api", "read_only": %t}`, whatsappClient.IsReadOnly())
+			return
+		}
+
+		lastMaintenance, err := maintenanceRepo.Last()
+		if err != nil {
+			slog.Warn("Failed to get last maintenance run", "error", err)
+		}
+		json.NewEncoder(w).Encode(struct {
+			Status          string                 `json:"status"`
+			Service         string                 `json:"service"`
+			ReadOnly        bool                   `json:"read_only"`
+			LastMaintenance *models.MaintenanceRun `json:"last_maintenance"` // Null before the first pass
+		}{"ok", "friday-whatsapp-api", whatsappClient.IsReadOnly(), lastMaintenance})
 	})
 
 	// Web interface
@@ -73,35 +381,219 @@ func main() {
 	mux.HandleFunc("/", webHandler.HandleLandingPage)
 	mux.HandleFunc("/dashboard", webHandler.HandleDashboard)
 	mux.HandleFunc("/qr-scan", webHandler.HandleQRScanPage)
+	mux.Handle("/static/", handlers.HandleStatic())
+	mux.HandleFunc("/api/", handlers.HandleUnknownAPI) // Any /api/ path without a more specific pattern
+
+	// Route discovery, rendered as the dashboard's API reference
+	routes.HandleFunc("/api/routes", routes.HandleRoutes,
+		handlers.RouteDoc{Method: "GET", Description: "List the documented API routes with request and response shapes", Response: handlers.RoutesResponse{}})
+	routes.HandleFunc("/api/capabilities", capabilities.HandleCapabilities,
+		handlers.RouteDoc{Method: "GET", Description: "Which optional features this deployment has on, with the server version and commit; a request needing one that is off gets 501 naming it", Response: handlers.CapabilitiesResponse{}})
 
 	// WhatsApp API
-	mux.HandleFunc("/api/whatsapp/status", whatsappHandler.HandleStatus)
-	mux.HandleFunc("/api/whatsapp/connect", whatsappHandler.HandleConnect)
-	mux.HandleFunc("/api/whatsapp/disconnect", whatsappHandler.HandleDisconnect)
-	mux.HandleFunc("/api/whatsapp/send", whatsappHandler.HandleSendMessage)
-	mux.HandleFunc("/api/whatsapp/qr", qrHandler.HandleGetQR)
-	mux.HandleFunc("/api/whatsapp/qr.png", qrHandler.HandleQRImage)
+	routes.HandleFunc("/api/whatsapp/status", whatsappHandler.HandleStatus,
+		handlers.RouteDoc{Method: "GET", Description: "Get connection status", Response: handlers.StatusResponse{}})
+	routes.HandleFunc("/api/whatsapp/me", whatsappHandler.HandleMe,
+		handlers.RouteDoc{Method: "GET", Description: "The linked account: JID, phone, push name, platform and pairing time; 404 without a session", Response: handlers.MeResponse{}})
+	routes.HandleFunc("/api/whatsapp/events", whatsappHandler.HandleEvents,
+		handlers.RouteDoc{Method: "GET", Description: "The last 200 pairing, connection and send events of the WhatsApp client, newest first. Query: since=RFC 3339 time", Response: handlers.ClientEventsResponse{}})
+	routes.HandleFunc("/api/whatsapp/blocklist", whatsappHandler.HandleBlocklist,
+		handlers.RouteDoc{Method: "GET", Description: "Contacts the linked account has blocked, cached for five minutes. Query: refresh=true to fetch again", Response: handlers.BlocklistResponse{}})
+	routes.HandleFunc("/api/whatsapp/connect", whatsappHandler.HandleConnect,
+		handlers.RouteDoc{Method: "POST", Description: "Connect or start pairing; returns the current state if already connecting", Response: handlers.ConnectResponse{}})
+	routes.HandleFunc("/api/whatsapp/disconnect", whatsappHandler.HandleDisconnect,
+		handlers.RouteDoc{Method: "POST", Description: "Disconnect and clear the stored session", Response: handlers.DisconnectResponse{}})
+	routes.HandleFunc("/api/whatsapp/send", whatsappHandler.HandleSendMessage,
+		handlers.RouteDoc{Method: "POST", Description: "Send a message to a phone number or JID; with queue_if_disconnected, 202 and an outbox_id while disconnected", Request: handlers.SendMessageRequest{}, Response: handlers.SendMessageResponse{}})
+	routes.HandleFunc("/api/whatsapp/reply", whatsappHandler.HandleReply,
+		handlers.RouteDoc{Method: "POST", Description: "Reply to a message, quoting it; returns the new message's id. The caller supplies the quoted message's id, sender and text", Request: handlers.ReplyRequest{}, Response: handlers.SendMessageResponse{}})
+	routes.HandleFunc("/api/whatsapp/react", whatsappHandler.HandleReaction,
+		handlers.RouteDoc{Method: "POST", Description: "React to a message with a single emoji, or remove a reaction with an empty one; returns the reaction message's id. The caller supplies the target message's id and sender", Request: handlers.ReactionRequest{}, Response: handlers.SendMessageResponse{}})
+	routes.HandleFunc("/api/whatsapp/poll", whatsappHandler.HandlePoll,
+		handlers.RouteDoc{Method: "POST", Description: "Send a poll with 2-12 options to a contact or group; returns the poll message's id. Votes are not collected", Request: handlers.PollRequest{}, Response: handlers.SendMessageResponse{}})
+	routes.HandleFunc("/api/outbox", outboxHandler.HandleOutbox,
+		handlers.RouteDoc{Method: "GET", Description: "Sends queued while disconnected, oldest first. Query: status=queued|sent|failed|expired", Response: handlers.OutboxListResponse{}})
+	routes.HandleFunc("/api/outbox/", outboxHandler.HandleOutboxItem,
+		handlers.RouteDoc{Method: "GET", Path: "/api/outbox/{id}", Description: "Get a queued send", Response: handlers.OutboxItemResponse{}},
+		handlers.RouteDoc{Method: "DELETE", Path: "/api/outbox/{id}", Description: "Delete a queued send so it is never sent", Response: handlers.OutboxItemResponse{}})
+	routes.HandleFunc("/api/whatsapp/qr", qrHandler.HandleGetQR,
+		handlers.RouteDoc{Method: "GET", Description: "Current pairing QR code and attempt. Query: format=base64 adds the image as a base64 PNG, size=128-1024", Response: handlers.QRResponse{}})
+	routes.HandleFunc("/api/whatsapp/qr.png", qrHandler.HandleQRImage,
+		handlers.RouteDoc{Method: "GET", Description: "Current pairing QR code as an image; 404 when there is none. Query: size=128-1024, format=png|svg"})
 
 	// Contact API
-	mux.HandleFunc("/api/contacts", contactHandler.HandleGetContacts)
-	mux.HandleFunc("/api/contacts/search", contactHandler.HandleSearchContacts)
-	mux.HandleFunc("/api/contacts/validate", contactHandler.HandleValidatePhones)
+	routes.HandleFunc("/api/contacts", contactHandler.HandleGetContacts,
+		handlers.RouteDoc{Method: "GET", Description: "List contacts, without blocked ones. Query: q, limit (default 500), offset, include_blocked=true", Response: handlers.ContactListResponse{}})
+	routes.HandleFunc("/api/contacts/search", contactHandler.HandleSearchContacts,
+		handlers.RouteDoc{Method: "GET", Description: "Search contacts by name or phone, without blocked ones. Query: q, attr.{key}={value}, not_in_group={id}, include_blocked=true", Response: handlers.ContactSearchResponse{}})
+	routes.HandleFunc("/api/contacts/validate", contactHandler.HandleValidatePhones,
+		handlers.RouteDoc{Method: "POST", Description: "Check which phone numbers are on WhatsApp", Request: handlers.PhoneValidationRequest{}, Response: handlers.PhoneValidationResponse{}})
+	routes.HandleFunc("/api/contacts/export", contactHandler.HandleExportContacts,
+		handlers.RouteDoc{Method: "GET", Description: "Download contacts with a column per attribute key. Query: format=csv|json, group_id", Response: []handlers.ContactExportRow{}})
+	routes.HandleFunc("/api/contacts/merge", contactHandler.HandleMergeContacts,
+		handlers.RouteDoc{Method: "POST", Description: "Merge a duplicate contact into a primary one, optionally redirecting later sends", Request: handlers.MergeContactsRequest{}, Response: handlers.MergeContactsResponse{}})
+	routes.HandleFunc("/api/contacts/sync", contactHandler.HandleSyncContacts,
+		handlers.RouteDoc{Method: "GET", Description: "The last contact sync; 404 before the first", Response: handlers.ContactSyncResponse{}},
+		handlers.RouteDoc{Method: "POST", Description: "Copy the WhatsApp address book into the local snapshot and record what changed since the last sync; the first sync is a baseline", Response: handlers.ContactSyncResponse{}})
+	routes.HandleFunc("/api/contacts/changes", contactHandler.HandleContactChanges,
+		handlers.RouteDoc{Method: "GET", Description: "Contacts added, removed and renamed between the snapshots of a sync and the latest one. Query: since (sync ID or RFC 3339 time; default before the first sync)", Response: handlers.ContactChangesResponse{}})
+	routes.HandleFunc("/api/contacts/quarantined", quarantineHandler.HandleQuarantined,
+		handlers.RouteDoc{Method: "GET", Description: "Contacts left out of new batches after repeated permanent send failures", Response: handlers.QuarantineListResponse{}})
+	routes.HandleFunc("/api/contacts/suspected-blocked", contactHandler.HandleSuspectedBlocked,
+		handlers.RouteDoc{Method: "GET", Description: "Contacts whose last batch messages all went undelivered, which suggests they blocked this number. Query: min (undelivered in a row, default 2)", Response: handlers.SuspectedBlockedResponse{}})
+
+	// Workspaces API
+	routes.HandleFunc("/api/workspaces", workspaceHandler.HandleWorkspaces,
+		handlers.RouteDoc{Method: "GET", Description: "List workspaces with their draft and group counts", Response: handlers.WorkspaceListResponse{}},
+		handlers.RouteDoc{Method: "POST", Description: "Create a workspace; select it with the X-Workspace header or ?workspace=", Request: handlers.CreateWorkspaceRequest{}, Response: handlers.WorkspaceResponse{}})
 
 	// Draft API
-	mux.HandleFunc("/api/drafts", draftHandler.HandleDrafts)     // GET (list), POST (create)
-	mux.HandleFunc("/api/drafts/", draftHandler.HandleDraft)     // GET/{id}, PUT/{id}, DELETE/{id}, POST/{id}/preview, POST/{id}/send
+	routes.HandleFunc("/api/drafts", idempotent(draftHandler.HandleDrafts),
+		handlers.RouteDoc{Method: "GET", Description: "List drafts", Response: handlers.DraftListResponse{}},
+		handlers.RouteDoc{Method: "POST", Description: "Create a draft", Request: handlers.CreateDraftRequest{}, Response: handlers.DraftResponse{}})
+	routes.HandleFunc("/api/drafts/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/move") {
+			workspaceHandler.HandleMoveDraft(w, r) // /api/drafts/{id}/move
+			return
+		}
+		draftHandler.HandleDraft(w, r)
+	},
+		handlers.RouteDoc{Method: "GET", Path: "/api/drafts/{id}", Description: "Get a draft", Response: handlers.DraftResponse{}},
+		handlers.RouteDoc{Method: "PUT", Path: "/api/drafts/{id}", Description: "Update a draft", Request: handlers.UpdateDraftRequest{}, Response: handlers.DraftResponse{}},
+		handlers.RouteDoc{Method: "DELETE", Path: "/api/drafts/{id}", Description: "Delete a draft", Response: handlers.DraftResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/drafts/{id}/stats", Description: "Batches, sends and success rate of a draft", Response: handlers.DraftStatsResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/drafts/{id}/move", Description: "Move a draft to another workspace; groups left behind stop using it as their default draft", Request: handlers.MoveRequest{}, Response: handlers.DraftResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/drafts/{id}/duplicate", Description: "Copy a draft and its variants into a new draft titled \"<title> (copy)\", numbered when taken", Response: handlers.DraftResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/drafts/{id}/lint", Description: "Check a draft for placeholder problems", Response: handlers.LintResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/drafts/{id}/preview", Description: "Render a draft for a contact; with group_id, as a member of that group", Request: handlers.PreviewRequest{}, Response: handlers.PreviewResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/drafts/{id}/send", Description: "Send a draft to a contact; with group_id, as a member of that group. queue_if_disconnected as for /api/whatsapp/send", Request: handlers.SendWithDraftRequest{}, Response: handlers.SendWithDraftResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/drafts/{id}/variants", Description: "List per-attribute variants", Response: handlers.VariantListResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/drafts/{id}/variants", Description: "Create or replace a variant", Request: handlers.SetVariantRequest{}, Response: handlers.VariantResponse{}},
+		handlers.RouteDoc{Method: "DELETE", Path: "/api/drafts/{id}/variants/{variantId}", Description: "Delete a variant", Response: handlers.VariantResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/drafts/export", Description: "Export all drafts as a JSON bundle", Response: []handlers.DraftBundleEntry{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/drafts/import", Description: "Import a bundle. Query: strategy=skip|overwrite-by-title|duplicate (overwrite is accepted for overwrite-by-title)", Request: []handlers.DraftBundleEntry{}, Response: handlers.DraftImportResponse{}})
 
-	// Contact Attributes API
-	mux.HandleFunc("/api/contacts/", attrHandler.HandleContactAttributes) // /api/contacts/{jid}/attributes
-	mux.HandleFunc("/api/attributes/keys", attrHandler.HandleAttributeKeys)
+	// Contact detail and attributes API
+	routes.HandleFunc("/api/contacts/", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(strings.TrimPrefix(r.URL.Path, "/api/contacts/"), "/") {
+			contactHandler.HandleContact(w, r) // /api/contacts/{jid}
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/avatar") {
+			avatarHandler.HandleAvatar(w, r) // /api/contacts/{jid}/avatar
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/quarantine/clear") {
+			quarantineHandler.HandleClear(w, r) // /api/contacts/{jid}/quarantine/clear
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/notes") {
+			noteHandler.HandleContactNote(w, r) // /api/contacts/{jid}/notes
+			return
+		}
+		attrHandler.HandleContactAttributes(w, r) // /api/contacts/{jid}/attributes
+	},
+		handlers.RouteDoc{Method: "GET", Path: "/api/contacts/{jid}", Description: "Everything known about a contact: WhatsApp info, attributes, note, groups, quarantine and 30-day send counts. Partial while disconnected, see sources", Response: handlers.ContactDetailResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/contacts/{jid}/attributes", Description: "Get a contact's attributes and note", Response: handlers.AttributeResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/contacts/{jid}/attributes", Description: "Set an attribute", Request: handlers.SetAttributeRequest{}, Response: handlers.AttributeResponse{}},
+		handlers.RouteDoc{Method: "DELETE", Path: "/api/contacts/{jid}/attributes/{key}", Description: "Delete an attribute (404 if the contact has no such key)", Response: handlers.AttributeResponse{}},
+		handlers.RouteDoc{Method: "DELETE", Path: "/api/contacts/{jid}/attributes", Description: "Delete every attribute of the contact; requires ?confirm=true", Response: handlers.ClearAttributesResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/contacts/{jid}/attributes/history", Description: "Attribute change history, newest first (?key=, limit, offset)", Response: handlers.AttributeHistoryResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/contacts/{jid}/notes", Description: "Get a contact's private note", Response: handlers.NoteResponse{}},
+		handlers.RouteDoc{Method: "PUT", Path: "/api/contacts/{jid}/notes", Description: "Save a contact's private note; empty content clears it", Request: handlers.SetNoteRequest{}, Response: handlers.NoteResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/contacts/{jid}/quarantine/clear", Description: "Reset a contact's failure counter and lift its quarantine", Response: handlers.QuarantineClearResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/contacts/{jid}/avatar", Description: "Cached profile picture, 204 when there is none"})
+	routes.HandleFunc("/api/attributes/keys", attrHandler.HandleAttributeKeys,
+		handlers.RouteDoc{Method: "GET", Description: "List attribute keys in use", Response: handlers.AttributeKeysResponse{}})
+	routes.HandleFunc("/api/attributes/batch-get", attrHandler.HandleBatchGet,
+		handlers.RouteDoc{Method: "POST", Description: "Attributes of up to 1000 contacts at once, optionally only some keys", Request: handlers.BatchGetAttributesRequest{}, Response: handlers.BatchGetAttributesResponse{}})
 
 	// Contact Groups API
-	mux.HandleFunc("/api/groups", groupHandler.HandleGroups)      // GET (list), POST (create)
-	mux.HandleFunc("/api/groups/", groupHandler.HandleGroup)      // GET/{id}, PUT/{id}, DELETE/{id}, POST/{id}/members
+	routes.HandleFunc("/api/groups", idempotent(groupHandler.HandleGroups),
+		handlers.RouteDoc{Method: "GET", Description: "List groups", Response: handlers.GroupListResponse{}},
+		handlers.RouteDoc{Method: "POST", Description: "Create a group", Request: handlers.CreateGroupRequest{}, Response: handlers.GroupResponse{}})
+	routes.HandleFunc("/api/groups/combine", idempotent(groupHandler.HandleCombine),
+		handlers.RouteDoc{Method: "POST", Description: "Create a group from the union, intersection or difference (first minus the rest) of other groups' members", Request: handlers.CombineGroupsRequest{}, Response: handlers.CombineGroupsResponse{}})
+	routes.HandleFunc("/api/groups/", idempotent(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/send") {
+			batchHandler.HandleGroupSend(w, r) // /api/groups/{id}/send
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/move") && !strings.HasSuffix(r.URL.Path, "/members/move") {
+			workspaceHandler.HandleMoveGroup(w, r) // /api/groups/{id}/move
+			return
+		}
+		groupHandler.HandleGroup(w, r)
+	}),
+		handlers.RouteDoc{Method: "GET", Path: "/api/groups/{id}", Description: "Get a group with its members", Response: handlers.GroupDetailResponse{}},
+		handlers.RouteDoc{Method: "PUT", Path: "/api/groups/{id}", Description: "Rename a group or change its default draft", Request: handlers.UpdateGroupRequest{}, Response: handlers.GroupResponse{}},
+		handlers.RouteDoc{Method: "DELETE", Path: "/api/groups/{id}", Description: "Delete a group", Response: handlers.GroupResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/groups/{id}/members", Description: "List group members in member order", Response: handlers.MembersResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/groups/{id}/members/count", Description: "Number of members in a group, without contact details", Response: handlers.MemberCountResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/groups/{id}/members", Description: "Add members to a group", Request: handlers.AddMembersRequest{}, Response: handlers.MembersResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/groups/{id}/members/move", Description: "Move members to another group in one step; refused while a batch for the group is unfinished", Request: handlers.MoveMembersRequest{}, Response: handlers.MoveMembersResponse{}},
+		handlers.RouteDoc{Method: "PUT", Path: "/api/groups/{id}/members/order", Description: "Put the listed members first, in that order; batches sent in group_order follow it", Request: handlers.ReorderMembersRequest{}, Response: handlers.MembersResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/groups/{id}/members/add-new-since", Description: "Add every contact GET /api/contacts/changes lists as added. Query: since (sync ID or RFC 3339 time)", Response: handlers.MembersResponse{}},
+		handlers.RouteDoc{Method: "DELETE", Path: "/api/groups/{id}/members/{jid}", Description: "Remove a member from a group", Response: handlers.MembersResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/groups/{id}/attributes", Description: "List the group's placeholder defaults", Response: handlers.GroupAttributeResponse{}},
+		handlers.RouteDoc{Method: "PUT", Path: "/api/groups/{id}/attributes", Description: "Set a placeholder default for the group's members; their own attributes win", Request: handlers.SetGroupAttributeRequest{}, Response: handlers.GroupAttributeResponse{}},
+		handlers.RouteDoc{Method: "DELETE", Path: "/api/groups/{id}/attributes/{key}", Description: "Delete a placeholder default", Response: handlers.GroupAttributeResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/groups/{id}/move", Description: "Move a group and its members to another workspace; a default draft not in that workspace is dropped", Request: handlers.MoveRequest{}, Response: handlers.GroupResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/groups/{id}/send", Description: "Queue a batch of the group's default draft; draft_id overrides it", Request: handlers.GroupSendRequest{}, Response: handlers.BatchResponse{}})
 
 	// Batch Runs API
-	mux.HandleFunc("/api/batch-runs", batchHandler.HandleBatches) // GET (list), POST (create)
-	mux.HandleFunc("/api/batch-runs/", batchHandler.HandleBatch)  // GET/{id}, DELETE/{id}, POST/{id}/cancel, GET/{id}/stream
+	routes.HandleFunc("/api/batch-runs", idempotent(batchHandler.HandleBatches),
+		handlers.RouteDoc{Method: "GET", Description: "List batch runs. Query: source={web|api|integration|unknown}", Response: handlers.BatchListResponse{}},
+		handlers.RouteDoc{Method: "POST", Description: "Queue a batch run, or plan it with dry_run. document_attribute sends each recipient the file it names, with the message as caption. A queued batch's queue_position, batches_ahead and estimated_start_at say when it starts", Request: handlers.CreateBatchRequest{}, Response: handlers.BatchResponse{}})
+	routes.HandleFunc("/api/batch-runs/", batchHandler.HandleBatch,
+		handlers.RouteDoc{Method: "GET", Path: "/api/batch-runs/active", Description: "The batches currently being sent, oldest first, and the queue behind them with estimated start times", Response: handlers.ActiveBatchResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/batch-runs/preflight", Description: "Check a draft and group before sending: missing placeholders, numbers known not to be on WhatsApp, opt-outs, quarantined and recently sent members", Request: handlers.PreflightRequest{}, Response: handlers.PreflightResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/batch-runs/{id}", Description: "Get a batch run with its messages", Response: handlers.BatchDetailResponse{}},
+		handlers.RouteDoc{Method: "DELETE", Path: "/api/batch-runs/{id}", Description: "Delete a finished batch run", Response: handlers.BatchResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/batch-runs/{id}/cancel", Description: "Cancel a pending, running or interrupted batch", Response: handlers.BatchResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/batch-runs/{id}/resume", Description: "Queue a batch held as interrupted after a restart (batch.auto_resume off)", Response: handlers.BatchResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/batch-runs/{id}/clone", Description: "Queue a new batch with the same draft and group. Query: validate, dry_run", Response: handlers.BatchResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/batch-runs/{id}/messages", Description: "List a batch's messages", Response: handlers.BatchMessagesResponse{}},
+		handlers.RouteDoc{Method: "POST", Path: "/api/batch-runs/{id}/messages/{messageId}/skip", Description: "Skip a pending message so it is never sent; 409 once it is sending, sent or failed", Response: handlers.BatchResponse{}},
+		handlers.RouteDoc{Method: "PUT", Path: "/api/batch-runs/{id}/messages/{messageId}", Description: "Replace the content of a pending message of a queued, running or interrupted batch; 409 once it is sending, sent, failed or skipped", Request: handlers.EditMessageRequest{}, Response: handlers.BatchMessageResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/batch-runs/{id}/recipients", Description: "The group's members when the batch was created, including ones left out", Response: handlers.BatchRecipientsResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/batch-runs/{id}/events", Description: "Batch lifecycle events. Query: after={seq}", Response: handlers.BatchEventsResponse{}},
+		handlers.RouteDoc{Method: "GET", Path: "/api/batch-runs/{id}/stream", Description: "Live batch progress (server-sent events)"},
+		handlers.RouteDoc{Method: "GET", Path: "/api/batch-runs/{id}/progress", Description: "Batch progress by long polling, for when event streams are blocked. Query: wait (seconds, up to 60), etag; 304 when nothing changed", Response: handlers.BatchProgressResponse{}})
+
+	// Integrations API
+	routes.HandleFunc("/api/integrations/trigger-batch", integrationHandler.HandleTriggerBatch,
+		handlers.RouteDoc{Method: "POST", Description: "Queue a batch from an external system; needs " + handlers.TriggerTimestampHeader + " and " + handlers.TriggerSignatureHeader + " headers", Request: handlers.TriggerBatchRequest{}, Response: handlers.BatchResponse{}})
+
+	// Reports API
+	routes.HandleFunc("/api/reports/weekly", reportHandler.HandleWeekly,
+		handlers.RouteDoc{Method: "GET", Description: "Weekly activity report: batches, sent/failed per day, top errors, new group members. Query: week=2025-W12, format=csv&section=days|errors|groups|batches", Response: handlers.WeeklyReportResponse{}})
+
+	// Settings API
+	routes.HandleFunc("/api/settings", settingsHandler.HandleSettings,
+		handlers.RouteDoc{Method: "GET", Description: "List settings with defaults", Response: handlers.SettingsResponse{}},
+		handlers.RouteDoc{Method: "PUT", Description: "Update settings from a key -> value object", Request: map[string]interface{}{}, Response: handlers.SettingsResponse{}})
+	routes.HandleFunc("/api/settings/audit", settingsHandler.HandleAudit,
+		handlers.RouteDoc{Method: "GET", Description: "Recent setting changes. Query: limit", Response: handlers.SettingsAuditResponse{}})
+	routes.HandleFunc("/api/settings/notifications", settingsHandler.HandleNotifications,
+		handlers.RouteDoc{Method: "GET", Description: "Batch completion notification settings", Response: handlers.NotificationSettingsResponse{}},
+		handlers.RouteDoc{Method: "PUT", Description: "Update notification settings", Request: handlers.NotificationSettings{}, Response: handlers.NotificationSettingsResponse{}})
+
+	// Admin API
+	routes.HandleFunc("/api/admin/read-only", adminHandler.HandleReadOnly,
+		handlers.RouteDoc{Method: "GET", Description: "Whether read-only mode is on", Response: handlers.ReadOnlyResponse{}},
+		handlers.RouteDoc{Method: "POST", Description: "Turn read-only mode on or off; while on, sends and batch creation are refused and batches pause", Request: handlers.ReadOnlyRequest{}, Response: handlers.ReadOnlyResponse{}})
+	routes.HandleFunc("/api/admin/maintenance", adminHandler.HandleMaintenance,
+		handlers.RouteDoc{Method: "POST", Description: "Run the nightly database maintenance now: prune past retention, ANALYZE, VACUUM when fragmented. 409 while a batch is sending", Response: handlers.MaintenanceResponse{}})
+	routes.HandleFunc("/api/admin/tokens", authHandler.HandleTokens,
+		handlers.RouteDoc{Method: "GET", Description: "List API tokens, revoked ones included; tokens themselves are never returned", Response: handlers.TokenListResponse{}},
+		handlers.RouteDoc{Method: "POST", Description: "Create an API token with scopes read, send and/or admin; the token is in secret, shown only here. The first one turns authentication on and needs admin", Request: handlers.CreateTokenRequest{}, Response: handlers.TokenResponse{}})
+	routes.HandleFunc("/api/admin/tokens/", authHandler.HandleToken,
+		handlers.RouteDoc{Method: "DELETE", Path: "/api/admin/tokens/{id}", Description: "Revoke an API token at once. 409 for the last admin token while others remain", Response: handlers.TokenResponse{}})
+
+	// Global event stream (SSE)
+	routes.HandleFunc("/api/events", eventsHandler.HandleEvents,
+		handlers.RouteDoc{Method: "GET", Description: "Live status, batch and QR updates (server-sent events). Query: topics=status,batch,qr"})
 
 	// New web pages
 	mux.HandleFunc("/drafts", webHandler.HandleDraftsPage)
@@ -113,41 +605,80 @@ func main() {
 	mux.HandleFunc("/groups/", webHandler.HandleGroupDetailPage)
 	mux.HandleFunc("/batch-runs", webHandler.HandleBatchRunsPage)
 	mux.HandleFunc("/batch-runs/", webHandler.HandleBatchRunDetailPage)
+	mux.HandleFunc("/reports/weekly", webHandler.HandleWeeklyReportPage)
 
-	server := &http.Server{
-		Addr:         ":8080",
-		Handler:      mux,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
-
-	go func() {
-		log.Printf("Starting Friday WhatsApp API server on %s", server.Addr)
-		log.Printf("Web: / (dashboard) | /drafts | /qr-scan | /groups | /batch-runs | /health")
-		log.Printf("API: /api/whatsapp/{status,connect,send,qr,qr.png}")
-		log.Printf("API: /api/contacts | /api/drafts | /api/groups | /api/batch-runs")
+	return &app{
+		handler:         handlers.WithRequestID(handlers.WithLanguage(cors.Wrap(rateLimiter.Wrap(authHandler.Wrap(workspaceHandler.Wrap(mux)))))),
+		batchWorker:     batchWorker,
+		maintainer:      maintainer,
+		deliveryTracker: deliveryTracker,
+		settingsHandler: settingsHandler,
+		rateLimiter:     rateLimiter,
+		fake:            fake,
+	}, nil
+}
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
-		}
-	}()
+// shutdown stops the background loops, the batch worker first.
+func (a *app) shutdown() {
+	a.batchWorker.Shutdown()
+	a.maintainer.Shutdown()
+	a.deliveryTracker.Shutdown()
+}
 
-	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+// reloadConfig handles SIGHUP: the reloadable variables are re-read from
+// FRIDAY_CONFIG_FILE and applied together, or not at all when one is invalid,
+// and the settings are re-read from the database. Batches keep running.
+func reloadConfig(loader *config.Loader, settingsHandler *handlers.SettingsHandler, batchWorker *batch.Worker, rateLimiter *handlers.RateLimiter) {
+	slog.Info("Reloading configuration", "path", loader.Path())
 
-	log.Println("Shutting down server...")
+	pending, err := loader.Read()
+	if err != nil {
+		slog.Error("Configuration not reloaded", "error", err)
+		return
+	}
+	for _, key := range pending.Ignored {
+		slog.Warn("Configuration change needs a restart to apply", "key", key)
+	}
 
-	// Shutdown batch worker first
-	batchWorker.Shutdown()
+	// Every value is parsed before any is applied
+	level, err := logging.ParseLevel(pending.Values[logging.EnvLevel])
+	if err != nil {
+		slog.Error("Configuration not reloaded", "error", err)
+		return
+	}
+	var quietHours *batch.QuietHours
+	if spec := pending.Values["FRIDAY_QUIET_HOURS"]; spec != "" {
+		if quietHours, err = batch.ParseQuietHours(spec, os.Getenv("FRIDAY_TIMEZONE")); err != nil {
+			slog.Error("Configuration not reloaded", "error", err)
+			return
+		}
+	}
+	rateLimitConfig, err := handlers.ParseRateLimitConfig(pending.Values["FRIDAY_RATE_LIMIT_RPS"], pending.Values["FRIDAY_RATE_LIMIT_BURST"])
+	if err != nil {
+		slog.Error("Configuration not reloaded", "error", err)
+		return
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	if err := loader.Apply(pending); err != nil {
+		slog.Error("Configuration not reloaded", "error", err)
+		return
+	}
+	logging.SetLevel(level)
+	batchWorker.SetQuietHours(quietHours)
+	rateLimiter.SetDefaults(rateLimitConfig)
+	for _, change := range pending.Changes {
+		slog.Info("Configuration reloaded", "key", change.Key, "old", change.Old, "new", change.New)
+	}
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	settingsChanged, err := settingsHandler.Reload()
+	if err != nil {
+		slog.Error("Failed to reload settings", "error", err)
 	}
-	log.Println("Server exited")
+	slog.Info("Configuration reload finished", "variables_changed", len(pending.Changes), "settings_changed", settingsChanged)
+}
+
+// fatal logs err at error level and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,677 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"friday/internal/database"
+	"friday/internal/handlers"
+	"friday/internal/models"
+	"friday/internal/whatsapp"
+)
+
+// newTestApp starts the whole server with FRIDAY_FAKE_WHATSAPP in a fresh
+// working directory, extra variables set from env.
+func newTestApp(t *testing.T, env map[string]string) (*app, *httptest.Server) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Setenv("FRIDAY_FAKE_WHATSAPP", "1")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	appDB, err := database.New("friday.db")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { appDB.Close() })
+	whatsappClient, err := whatsapp.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create WhatsApp client: %v", err)
+	}
+	t.Cleanup(func() { whatsappClient.Close() })
+
+	a, err := newApp(appDB, whatsappClient)
+	if err != nil {
+		t.Fatalf("newApp: %v", err)
+	}
+	t.Cleanup(a.shutdown)
+	// The one-second delay floor still applies; without jitter five messages take seconds
+	a.batchWorker.SetSendJitter(0)
+
+	server := httptest.NewServer(a.handler)
+	t.Cleanup(server.Close)
+	return a, server
+}
+
+// call sends body as JSON and decodes the response into out, failing unless
+// the status is want.
+func call(t *testing.T, server *httptest.Server, method, path string, body any, want int, out any) {
+	t.Helper()
+	callWithHeader(t, server, method, path, nil, body, want, out)
+}
+
+// callWithHeader is call with extra request headers.
+func callWithHeader(t *testing.T, server *httptest.Server, method, path string, header http.Header, body any, want int, out any) {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, server.URL+path, reader)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		data, _ := io.ReadAll(resp.Body)
+		t.Fatalf("%s %s: status %d, want %d: %s", method, path, resp.StatusCode, want, data)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: failed to decode response: %v", method, path, err)
+		}
+	}
+}
+
+// queueBatch creates a draft with content and a group of jids named name,
+// then queues a batch of the draft to the group.
+func queueBatch(t *testing.T, server *httptest.Server, name, content string, jids []string) (*models.MessageDraft, *models.ContactGroup, *models.BatchRun) {
+	t.Helper()
+	var draft handlers.DraftResponse
+	call(t, server, http.MethodPost, "/api/drafts", map[string]string{"title": name, "content": content}, http.StatusCreated, &draft)
+	var group handlers.GroupResponse
+	call(t, server, http.MethodPost, "/api/groups", map[string]string{"name": name}, http.StatusCreated, &group)
+	call(t, server, http.MethodPost, fmt.Sprintf("/api/groups/%d/members", group.Group.ID), map[string][]string{"jids": jids}, http.StatusOK, nil)
+
+	var created handlers.BatchResponse
+	call(t, server, http.MethodPost, "/api/batch-runs", map[string]int64{"draft_id": draft.Draft.ID, "group_id": group.Group.ID}, http.StatusCreated, &created)
+	return draft.Draft, group.Group, created.Batch
+}
+
+// waitForBatch polls a batch until it reaches a final status and returns its detail.
+func waitForBatch(t *testing.T, server *httptest.Server, batchID int64) handlers.BatchDetailResponse {
+	t.Helper()
+	var detail handlers.BatchDetailResponse
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		call(t, server, http.MethodGet, fmt.Sprintf("/api/batch-runs/%d", batchID), nil, http.StatusOK, &detail)
+		if detail.Batch.Status.IsFinal() {
+			return detail
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("batch still %s after 30s: %d sent, %d failed", detail.Batch.Status, detail.Batch.SentCount, detail.Batch.FailedCount)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// TestBatchWithFakeWhatsApp is the end-to-end run from the README: five
+// recipients, of which the fake's seeded failure rate fails two.
+func TestBatchWithFakeWhatsApp(t *testing.T) {
+	a, server := newTestApp(t, map[string]string{
+		"FRIDAY_FAKE_FAILURE_RATE": "0.3",
+		"FRIDAY_FAKE_SEED":         "7",
+	})
+
+	call(t, server, http.MethodPut, "/api/settings", map[string]int{"batch.min_delay_seconds": 1, "batch.max_delay_seconds": 1}, http.StatusOK, nil)
+
+	jids := make([]string, 5)
+	for i := range jids {
+		jids[i] = fmt.Sprintf("90555000000%d@s.whatsapp.net", i+1)
+	}
+	_, _, batch := queueBatch(t, server, "CI", "Hello {{name}}", jids)
+	detail := waitForBatch(t, server, batch.ID)
+
+	if detail.Batch.Status != models.BatchStatusCompleted {
+		t.Fatalf("status = %q, want %q", detail.Batch.Status, models.BatchStatusCompleted)
+	}
+	if detail.Batch.TotalCount != 5 || detail.Batch.SentCount != 3 || detail.Batch.FailedCount != 2 {
+		t.Errorf("total, sent, failed = %d, %d, %d; want 5, 3, 2",
+			detail.Batch.TotalCount, detail.Batch.SentCount, detail.Batch.FailedCount)
+	}
+	if sent := len(a.fake.Sent()); sent != 3 {
+		t.Errorf("fake accepted %d messages, want 3", sent)
+	}
+	if attempts := a.fake.Attempts(); attempts != 5 {
+		t.Errorf("fake got %d send attempts, want 5", attempts)
+	}
+}
+
+// TestWhatsAppEndpointsUseFake checks that status and single sends reach the
+// fake rather than the unconnected real client.
+func TestWhatsAppEndpointsUseFake(t *testing.T) {
+	a, server := newTestApp(t, nil)
+
+	var status handlers.StatusResponse
+	call(t, server, http.MethodGet, "/api/whatsapp/status", nil, http.StatusOK, &status)
+	if !status.Connected || !status.HasSession || status.State != string(whatsapp.StateConnected) {
+		t.Errorf("status = connected %t, session %t, state %q; want the fake's connected session", status.Connected, status.HasSession, status.State)
+	}
+	if status.OwnJID != "15550000000@s.whatsapp.net" {
+		t.Errorf("own JID = %q, want the fake's", status.OwnJID)
+	}
+
+	call(t, server, http.MethodPost, "/api/whatsapp/send", map[string]string{"recipient": "905550000001", "message": "Hi"}, http.StatusOK, nil)
+	sent := a.fake.Sent()
+	if len(sent) != 1 || sent[0].JID != "905550000001@s.whatsapp.net" || sent[0].Message != "Hi" {
+		t.Errorf("fake accepted %+v, want the one message sent", sent)
+	}
+
+	a.fake.SetConnected(false)
+	call(t, server, http.MethodGet, "/api/whatsapp/status", nil, http.StatusOK, &status)
+	if status.Connected || status.State != string(whatsapp.StateDisconnected) {
+		t.Errorf("after disconnecting the fake: connected %t, state %q", status.Connected, status.State)
+	}
+}
+
+// TestDeleteWhileBatchPending deletes the draft and group of a batch that has
+// not finished; both are refused until the batch itself is deleted.
+func TestDeleteWhileBatchPending(t *testing.T) {
+	a, server := newTestApp(t, nil)
+	// Disconnected, the batch waits for WhatsApp and cannot finish under the test
+	a.fake.SetConnected(false)
+
+	draft, group, batch := queueBatch(t, server, "Pending", "Hello", []string{"905550000001@s.whatsapp.net"})
+
+	call(t, server, http.MethodDelete, fmt.Sprintf("/api/drafts/%d", draft.ID), nil, http.StatusConflict, nil)
+	call(t, server, http.MethodDelete, fmt.Sprintf("/api/groups/%d", group.ID), nil, http.StatusConflict, nil)
+
+	var detail handlers.BatchDetailResponse
+	call(t, server, http.MethodGet, fmt.Sprintf("/api/batch-runs/%d", batch.ID), nil, http.StatusOK, &detail)
+	if status := detail.Batch.Status; status != models.BatchStatusQueued && status != models.BatchStatusRunning {
+		t.Fatalf("batch is %s, want it still queued or running", status)
+	}
+	call(t, server, http.MethodGet, fmt.Sprintf("/api/drafts/%d", draft.ID), nil, http.StatusOK, nil)
+	call(t, server, http.MethodGet, fmt.Sprintf("/api/groups/%d", group.ID), nil, http.StatusOK, nil)
+
+	call(t, server, http.MethodPost, fmt.Sprintf("/api/batch-runs/%d/cancel", batch.ID), nil, http.StatusOK, nil)
+	call(t, server, http.MethodDelete, fmt.Sprintf("/api/batch-runs/%d", batch.ID), nil, http.StatusOK, nil)
+	call(t, server, http.MethodDelete, fmt.Sprintf("/api/drafts/%d", draft.ID), nil, http.StatusOK, nil)
+	call(t, server, http.MethodDelete, fmt.Sprintf("/api/groups/%d", group.ID), nil, http.StatusOK, nil)
+}
+
+// TestDraftExportImportSkip imports a bundle just exported with strategy=skip,
+// which must leave every draft as it was.
+func TestDraftExportImportSkip(t *testing.T) {
+	_, server := newTestApp(t, nil)
+	call(t, server, http.MethodPost, "/api/drafts", map[string]string{"title": "Welcome", "content": "Hello {{name}}"}, http.StatusCreated, nil)
+	call(t, server, http.MethodPost, "/api/drafts", map[string]any{
+		"title":      "Reminder",
+		"content":    "See you <<day>>",
+		"delimiters": map[string]string{"open": "<<", "close": ">>"},
+	}, http.StatusCreated, nil)
+
+	var before, after handlers.DraftListResponse
+	call(t, server, http.MethodGet, "/api/drafts", nil, http.StatusOK, &before)
+	var bundle []handlers.DraftBundleEntry
+	call(t, server, http.MethodGet, "/api/drafts/export", nil, http.StatusOK, &bundle)
+	if len(bundle) != 2 {
+		t.Fatalf("exported %d drafts, want 2", len(bundle))
+	}
+
+	var imported handlers.DraftImportResponse
+	call(t, server, http.MethodPost, "/api/drafts/import?strategy=skip", bundle, http.StatusOK, &imported)
+	for _, result := range imported.Results {
+		if result.Status != "skipped" {
+			t.Errorf("%q was %s, want skipped", result.Title, result.Status)
+		}
+	}
+	if len(imported.Results) != len(bundle) {
+		t.Errorf("%d import results, want %d", len(imported.Results), len(bundle))
+	}
+
+	call(t, server, http.MethodGet, "/api/drafts", nil, http.StatusOK, &after)
+	if !reflect.DeepEqual(after.Drafts, before.Drafts) {
+		t.Errorf("drafts changed by a skip import:\nbefore %+v\nafter  %+v", before.Drafts, after.Drafts)
+	}
+}
+
+// TestRateLimit exhausts the configured burst on a limited endpoint through
+// the whole middleware chain.
+func TestRateLimit(t *testing.T) {
+	_, server := newTestApp(t, map[string]string{
+		"FRIDAY_RATE_LIMIT_RPS":   "1",
+		"FRIDAY_RATE_LIMIT_BURST": "2",
+	})
+
+	call(t, server, http.MethodGet, "/api/batch-runs", nil, http.StatusOK, nil)
+	call(t, server, http.MethodGet, "/api/batch-runs", nil, http.StatusOK, nil)
+	resp, err := server.Client().Get(server.URL + "/api/batch-runs")
+	if err != nil {
+		t.Fatalf("GET /api/batch-runs: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("third request: status %d, want 429", resp.StatusCode)
+	}
+	if retry := resp.Header.Get("Retry-After"); retry != "1" {
+		t.Errorf("Retry-After = %q, want 1", retry)
+	}
+
+	// Status polling is never limited
+	for range 5 {
+		call(t, server, http.MethodGet, "/api/whatsapp/status", nil, http.StatusOK, nil)
+	}
+}
+
+// TestSendToSelf sends to the fake's own account, which the send endpoints
+// refuse unless the request sets allow_self. Replies and polls go through the
+// real client only, so the fake cannot stand in for them here.
+func TestSendToSelf(t *testing.T) {
+	a, server := newTestApp(t, nil)
+	const self = "15550000000@s.whatsapp.net"
+	other := a.fake.AddContact("905550000001", "Ayşe")
+
+	var draft handlers.DraftResponse
+	call(t, server, http.MethodPost, "/api/drafts", map[string]string{"title": "Hello", "content": "Hello"}, http.StatusCreated, &draft)
+
+	sends := []struct {
+		path string
+		body map[string]any
+	}{
+		{"/api/whatsapp/send", map[string]any{"recipient": self, "message": "Hi"}},
+		{fmt.Sprintf("/api/drafts/%d/send", draft.Draft.ID), map[string]any{"jid": self}},
+	}
+	for _, send := range sends {
+		var refused handlers.SendMessageResponse
+		call(t, server, http.MethodPost, send.path, send.body, http.StatusConflict, &refused)
+		if refused.Success || refused.Message != "The recipient is the linked account, so the message would go to your own chat; set allow_self to send it anyway" {
+			t.Errorf("%s: response %+v, want recipient_is_self", send.path, refused)
+		}
+	}
+	if attempts := a.fake.Attempts(); attempts != 0 {
+		t.Fatalf("fake got %d send attempts after the refusals, want 0", attempts)
+	}
+
+	for _, send := range sends {
+		send.body["allow_self"] = true
+		call(t, server, http.MethodPost, send.path, send.body, http.StatusOK, nil)
+	}
+	if sent := a.fake.Sent(); len(sent) == 0 || sent[0].JID != self {
+		t.Errorf("fake accepted %+v, want the message to the own account", sent)
+	}
+
+	// Batches leave the linked account out instead, or refuse when it is the only member
+	var group handlers.GroupResponse
+	call(t, server, http.MethodPost, "/api/groups", map[string]string{"name": "Team"}, http.StatusCreated, &group)
+	call(t, server, http.MethodPost, fmt.Sprintf("/api/groups/%d/members", group.Group.ID), map[string][]string{"jids": {self, other}}, http.StatusOK, nil)
+	batch := map[string]any{"draft_id": draft.Draft.ID, "group_id": group.Group.ID, "dry_run": true}
+
+	var withoutSelf, withSelf handlers.BatchResponse
+	call(t, server, http.MethodPost, "/api/batch-runs", batch, http.StatusOK, &withoutSelf)
+	if withoutSelf.Plan == nil || !withoutSelf.Plan.SelfSkipped || withoutSelf.Plan.TotalCount != 1 {
+		t.Errorf("plan = %+v, want the own account skipped and 1 recipient", withoutSelf.Plan)
+	}
+	if want := "Dry run: 1 recipients, the linked account was skipped"; withoutSelf.Message != want {
+		t.Errorf("message = %q, want %q", withoutSelf.Message, want)
+	}
+	batch["allow_self"] = true
+	call(t, server, http.MethodPost, "/api/batch-runs", batch, http.StatusOK, &withSelf)
+	if withSelf.Plan == nil || withSelf.Plan.SelfSkipped || withSelf.Plan.TotalCount != 2 {
+		t.Errorf("plan with allow_self = %+v, want both members", withSelf.Plan)
+	}
+
+	call(t, server, http.MethodDelete, fmt.Sprintf("/api/groups/%d/members/%s", group.Group.ID, other), nil, http.StatusOK, nil)
+	delete(batch, "allow_self")
+	call(t, server, http.MethodPost, "/api/batch-runs", batch, http.StatusConflict, nil)
+	batch["allow_self"] = true
+	call(t, server, http.MethodPost, "/api/batch-runs", batch, http.StatusOK, nil)
+}
+
+// TestUnknownPaths requests paths no route serves: API paths get a JSON 404
+// naming the path, anything else a plain 404 rather than the landing page.
+func TestUnknownPaths(t *testing.T) {
+	_, server := newTestApp(t, nil)
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/api/nope"},
+		{http.MethodGet, "/api/whatsapp/statuss"},
+		{http.MethodGet, "/api/v2/drafts"},
+		{http.MethodPost, "/api/batch-run"},
+		{http.MethodDelete, "/api/"},
+	} {
+		var body handlers.UnknownEndpointResponse
+		call(t, server, req.method, req.path, nil, http.StatusNotFound, &body)
+		if body.Success || body.Path != req.path || body.Message != "Unknown endpoint" {
+			t.Errorf("%s %s: body %+v, want the unknown endpoint error", req.method, req.path, body)
+		}
+	}
+
+	for _, path := range []string{"/nope", "/index.html", "/dashboard/extra", "/favicon.ico"} {
+		resp, err := server.Client().Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s: status %d, want 404", path, resp.StatusCode)
+		}
+		if bytes.Contains(data, []byte("<title>")) {
+			t.Errorf("GET %s: served a page, want a plain 404", path)
+		}
+	}
+
+	resp, err := server.Client().Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("GET /: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /: status %d, want the landing page", resp.StatusCode)
+	}
+}
+
+// TestAbandonedProgressPolls opens many long polls on a batch that does not
+// change and gives up on them; each must unsubscribe from the worker.
+func TestAbandonedProgressPolls(t *testing.T) {
+	a, server := newTestApp(t, nil)
+	// Disconnected, the batch waits and its progress stays the same
+	a.fake.SetConnected(false)
+	_, _, batch := queueBatch(t, server, "Polled", "Hello", []string{"905550000001@s.whatsapp.net"})
+
+	var first handlers.BatchProgressResponse
+	deadline := time.Now().Add(10 * time.Second)
+	for first.Progress == nil || first.Progress.Status != string(models.BatchStatusRunning) {
+		if time.Now().After(deadline) {
+			t.Fatalf("batch did not start: %+v", first.Progress)
+		}
+		call(t, server, http.MethodGet, fmt.Sprintf("/api/batch-runs/%d/progress", batch.ID), nil, http.StatusOK, &first)
+	}
+	path := fmt.Sprintf("%s/api/batch-runs/%d/progress?wait=30&etag=%s", server.URL, batch.ID, url.QueryEscape(first.ETag))
+
+	const polls = 50
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	for range polls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+			if resp, err := server.Client().Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+
+	waitForSubscribers := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for a.batchWorker.SubscriberCount(batch.ID) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("%d subscribers, want %d", a.batchWorker.SubscriberCount(batch.ID), want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitForSubscribers(polls)
+	cancel()
+	wg.Wait()
+	waitForSubscribers(0)
+
+	// A poll that times out unsubscribes too
+	req, _ := http.NewRequest(http.MethodGet, strings.Replace(path, "wait=30", "wait=1", 1), nil)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET progress: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("poll without a change: status %d, want 304", resp.StatusCode)
+	}
+	waitForSubscribers(0)
+}
+
+// TestBatchSendsQueuedContent edits a draft while a batch of it waits; the
+// batch still sends the text it was created with and reports the edit.
+func TestBatchSendsQueuedContent(t *testing.T) {
+	a, server := newTestApp(t, nil)
+	call(t, server, http.MethodPut, "/api/settings", map[string]int{"batch.min_delay_seconds": 1, "batch.max_delay_seconds": 1}, http.StatusOK, nil)
+	// Disconnected, the batch waits for WhatsApp while the draft is edited
+	a.fake.SetConnected(false)
+
+	jids := []string{"905550000001@s.whatsapp.net", "905550000002@s.whatsapp.net"}
+	draft, _, batch := queueBatch(t, server, "Snapshot", "Hello {{name}}, see you at 10", jids)
+	call(t, server, http.MethodPut, fmt.Sprintf("/api/drafts/%d", draft.ID), map[string]string{"title": draft.Title, "content": "Hello {{name}}, see you at 11"}, http.StatusOK, nil)
+
+	var detail handlers.BatchDetailResponse
+	call(t, server, http.MethodGet, fmt.Sprintf("/api/batch-runs/%d", batch.ID), nil, http.StatusOK, &detail)
+	if detail.DraftModified == nil || !*detail.DraftModified {
+		t.Errorf("draft_modified = %v, want true after the edit", detail.DraftModified)
+	}
+
+	a.fake.SetConnected(true)
+	if detail = waitForBatch(t, server, batch.ID); detail.Batch.Status != models.BatchStatusCompleted {
+		t.Fatalf("status = %q, want %q", detail.Batch.Status, models.BatchStatusCompleted)
+	}
+	sent := a.fake.Sent()
+	if len(sent) != len(jids) {
+		t.Fatalf("fake accepted %d messages, want %d", len(sent), len(jids))
+	}
+	for _, message := range sent {
+		if !strings.HasSuffix(message.Message, "see you at 10") {
+			t.Errorf("sent %q to %s, want the content the batch was queued with", message.Message, message.JID)
+		}
+	}
+}
+
+// TestDraftImportLint imports drafts with the same rules creating them has:
+// placeholders that only warn are imported, broken ones are not, and a draft
+// that is skipped is not checked at all.
+func TestDraftImportLint(t *testing.T) {
+	_, server := newTestApp(t, nil)
+	call(t, server, http.MethodPost, "/api/drafts", map[string]string{"title": "Existing", "content": "Hello"}, http.StatusCreated, nil)
+
+	statuses := func(resp handlers.DraftImportResponse) []string {
+		var got []string
+		for _, result := range resp.Results {
+			got = append(got, result.Title+" "+result.Status)
+		}
+		return got
+	}
+
+	var imported handlers.DraftImportResponse
+	call(t, server, http.MethodPost, "/api/drafts/import?strategy=skip", []handlers.DraftBundleEntry{
+		{Title: "Spaced", Content: "Hello {{ name }}"},
+		{Title: "Empty", Content: "Hello {{}}"},
+		{Title: "Dashed", Content: "Hello {{first-name}}"},
+		{Title: "Broken", Content: "Hello {{name"},
+		{Title: "Existing", Content: "Hello {{name"},
+	}, http.StatusOK, &imported)
+	want := []string{"Spaced created", "Empty created", "Dashed created", "Broken invalid", "Existing skipped"}
+	if got := statuses(imported); !reflect.DeepEqual(got, want) {
+		t.Errorf("import results = %q, want %q", got, want)
+	}
+
+	// overwrite is the old name of overwrite-by-title
+	call(t, server, http.MethodPost, "/api/drafts/import?strategy=overwrite", []handlers.DraftBundleEntry{
+		{Title: "Existing", Content: "Hello {{name"},
+		{Title: "Spaced", Content: "Hello {{name}}"},
+	}, http.StatusOK, &imported)
+	want = []string{"Existing invalid", "Spaced overwritten"}
+	if got := statuses(imported); imported.Strategy != "overwrite-by-title" || !reflect.DeepEqual(got, want) {
+		t.Errorf("strategy %q, results %q; want overwrite-by-title, %q", imported.Strategy, got, want)
+	}
+	call(t, server, http.MethodPost, "/api/drafts/import?strategy=overwrite-by-title", []handlers.DraftBundleEntry{}, http.StatusOK, nil)
+
+	var drafts handlers.DraftListResponse
+	call(t, server, http.MethodGet, "/api/drafts", nil, http.StatusOK, &drafts)
+	for _, draft := range drafts.Drafts {
+		if draft.Title == "Existing" && draft.Content != "Hello" {
+			t.Errorf("Existing was changed to %q by an invalid import", draft.Content)
+		}
+	}
+}
+
+// TestVariantLint saves variants with the same rules as their draft: content
+// that only warns is saved with the warnings, broken content is refused.
+func TestVariantLint(t *testing.T) {
+	_, server := newTestApp(t, nil)
+	var draft handlers.DraftResponse
+	call(t, server, http.MethodPost, "/api/drafts", map[string]string{"title": "Welcome", "content": "Hello {{ name }}"}, http.StatusCreated, &draft)
+	path := fmt.Sprintf("/api/drafts/%d/variants", draft.Draft.ID)
+
+	var saved handlers.VariantResponse
+	call(t, server, http.MethodPost, path, map[string]string{"selector_key": "lang", "selector_value": "tr", "content": "Merhaba {{ name }}"}, http.StatusOK, &saved)
+	if len(saved.Warnings) == 0 || saved.Warnings[0].Code != "whitespace_in_placeholder" {
+		t.Errorf("warnings = %+v, want the whitespace warning the draft got", saved.Warnings)
+	}
+
+	var refused handlers.VariantResponse
+	call(t, server, http.MethodPost, path, map[string]string{"selector_key": "lang", "selector_value": "de", "content": "Hallo {{name"}, http.StatusBadRequest, &refused)
+	if refused.Success || len(refused.Warnings) == 0 || refused.Warnings[0].Severity != "error" {
+		t.Errorf("response %+v, want the lint errors", refused)
+	}
+}
+
+// TestBatchMessagesTranslated queues batches in Turkish: the summary and the
+// refusals come from the catalog like every other message.
+func TestBatchMessagesTranslated(t *testing.T) {
+	_, server := newTestApp(t, nil)
+	var draft handlers.DraftResponse
+	call(t, server, http.MethodPost, "/api/drafts", map[string]string{"title": "Welcome", "content": "Hello"}, http.StatusCreated, &draft)
+	var group handlers.GroupResponse
+	call(t, server, http.MethodPost, "/api/groups", map[string]string{"name": "Team"}, http.StatusCreated, &group)
+	call(t, server, http.MethodPost, fmt.Sprintf("/api/groups/%d/members", group.Group.ID), map[string][]string{"jids": {"905550000001@s.whatsapp.net", "905550000002@s.whatsapp.net"}}, http.StatusOK, nil)
+
+	tests := []struct {
+		body        map[string]any
+		wantStatus  int
+		wantMessage string
+	}{
+		{map[string]any{"dry_run": true}, http.StatusOK, "Deneme: 2 alıcı"},
+		{map[string]any{"label": "{{week}}"}, http.StatusBadRequest, "Bilinmeyen etiket yer tutucusu {{week}}; {{date}}, {{group}} veya {{draft}} kullanın"},
+		{map[string]any{}, http.StatusCreated, "Toplu gönderim başladı"},
+	}
+	for _, tt := range tests {
+		tt.body["draft_id"] = draft.Draft.ID
+		tt.body["group_id"] = group.Group.ID
+		var got handlers.BatchResponse
+		callWithHeader(t, server, http.MethodPost, "/api/batch-runs", http.Header{"Accept-Language": {"tr"}}, tt.body, tt.wantStatus, &got)
+		if got.Message != tt.wantMessage {
+			t.Errorf("%v: message = %q, want %q", tt.body, got.Message, tt.wantMessage)
+		}
+	}
+}
+
+// TestBatchClientName labels batches through X-Client-Name and the
+// client_name field: the header wins, and the label is stored with the batch.
+func TestBatchClientName(t *testing.T) {
+	_, server := newTestApp(t, nil)
+	var draft handlers.DraftResponse
+	call(t, server, http.MethodPost, "/api/drafts", map[string]string{"title": "Welcome", "content": "Hello"}, http.StatusCreated, &draft)
+	var group handlers.GroupResponse
+	call(t, server, http.MethodPost, "/api/groups", map[string]string{"name": "Team"}, http.StatusCreated, &group)
+	call(t, server, http.MethodPost, fmt.Sprintf("/api/groups/%d/members", group.Group.ID), map[string][]string{"jids": {"905550000001@s.whatsapp.net"}}, http.StatusOK, nil)
+
+	tests := []struct {
+		header http.Header
+		body   string
+		want   string
+	}{
+		{header: http.Header{handlers.ClientNameHeader: {"crm-sync"}}, want: "crm-sync"},
+		{body: "zapier", want: "zapier"},
+		{header: http.Header{handlers.ClientNameHeader: {"crm-sync"}}, body: "zapier", want: "crm-sync"},
+	}
+	for _, tt := range tests {
+		var created handlers.BatchResponse
+		body := map[string]any{"draft_id": draft.Draft.ID, "group_id": group.Group.ID, "client_name": tt.body}
+		callWithHeader(t, server, http.MethodPost, "/api/batch-runs", tt.header, body, http.StatusCreated, &created)
+
+		var detail handlers.BatchDetailResponse
+		call(t, server, http.MethodGet, fmt.Sprintf("/api/batch-runs/%d", created.Batch.ID), nil, http.StatusOK, &detail)
+		if got := detail.Batch.ClientName; got == nil || *got != tt.want {
+			t.Errorf("header %v, body %q: client name %v, want %q", tt.header, tt.body, got, tt.want)
+		}
+	}
+
+	body := map[string]any{"draft_id": draft.Draft.ID, "group_id": group.Group.ID}
+	callWithHeader(t, server, http.MethodPost, "/api/batch-runs", http.Header{handlers.ClientNameHeader: {strings.Repeat("x", 101)}}, body, http.StatusBadRequest, nil)
+}
+
+// TestBatchHiddenNumbers sends a batch to @lid members: one WhatsApp has given
+// a number for, one it has not, and one whose number is also a member.
+func TestBatchHiddenNumbers(t *testing.T) {
+	a, server := newTestApp(t, nil)
+	call(t, server, http.MethodPut, "/api/settings", map[string]int{"batch.min_delay_seconds": 1, "batch.max_delay_seconds": 1}, http.StatusOK, nil)
+
+	const (
+		resolved   = "111111111111111@lid"
+		unresolved = "222222222222222@lid"
+		duplicate  = "333333333333333@lid"
+		member     = "905550000002@s.whatsapp.net"
+	)
+	a.fake.SetLIDMapping("111111111111111", "905550000001")
+	a.fake.SetLIDMapping("333333333333333", "905550000002")
+
+	var draft handlers.DraftResponse
+	call(t, server, http.MethodPost, "/api/drafts", map[string]string{"title": "Welcome", "content": "Hello"}, http.StatusCreated, &draft)
+	var group handlers.GroupResponse
+	call(t, server, http.MethodPost, "/api/groups", map[string]string{"name": "Hidden"}, http.StatusCreated, &group)
+	call(t, server, http.MethodPost, fmt.Sprintf("/api/groups/%d/members", group.Group.ID), map[string][]string{"jids": {resolved, unresolved, duplicate, member}}, http.StatusOK, nil)
+	batch := map[string]any{"draft_id": draft.Draft.ID, "group_id": group.Group.ID}
+
+	var dryRun handlers.BatchResponse
+	call(t, server, http.MethodPost, "/api/batch-runs?dry_run=true", batch, http.StatusOK, &dryRun)
+	if dryRun.Plan == nil || dryRun.Plan.UnresolvedLIDCount != 1 {
+		t.Fatalf("plan = %+v, want 1 unresolved hidden number", dryRun.Plan)
+	}
+
+	var created handlers.BatchResponse
+	call(t, server, http.MethodPost, "/api/batch-runs", batch, http.StatusCreated, &created)
+	if created.Batch.TotalCount != 3 {
+		t.Errorf("total = %d, want 3 messages: the duplicate number once", created.Batch.TotalCount)
+	}
+	if !strings.Contains(created.Message, "1 hidden numbers without a phone number failed") {
+		t.Errorf("message = %q, want the unresolved number reported", created.Message)
+	}
+
+	detail := waitForBatch(t, server, created.Batch.ID)
+	status := make(map[string]models.BatchMessageStatus, len(detail.Messages))
+	for _, msg := range detail.Messages {
+		status[msg.JID] = msg.Status
+	}
+	want := map[string]models.BatchMessageStatus{
+		"905550000001@s.whatsapp.net": models.MessageStatusSent,
+		unresolved:                    models.MessageStatusFailed,
+		member:                        models.MessageStatusSent,
+	}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("message statuses = %v, want %v", status, want)
+	}
+
+	sent := a.fake.Sent()
+	if len(sent) != 2 {
+		t.Errorf("fake got %d messages, want 2: %+v", len(sent), sent)
+	}
+	for _, msg := range sent {
+		if strings.HasSuffix(msg.JID, "@lid") {
+			t.Errorf("message sent to the hidden number %s instead of its phone number", msg.JID)
+		}
+	}
+}